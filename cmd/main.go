@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"time"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/job"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/rest"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/gateway"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/database"
@@ -31,38 +36,138 @@ func main() {
 	merchantRepo := repository.NewMerchantRepository(db)
 	consumerLimitRepo := repository.NewConsumerLimitRepository(db)
 	loanRepo := repository.NewLoanRepository(db)
+	loanInstallmentRepo := repository.NewLoanInstallmentRepository(db)
+	loanRestructureRepo := repository.NewLoanRestructureRepository(db)
+	loanPenaltyRepo := repository.NewLoanPenaltyRepository(db)
 	transactionRepo := repository.NewTransactionRepository(db)
+	batchRunRepo := repository.NewBatchRunRepository(db)
+	settlementQuoteRepo := repository.NewSettlementQuoteRepository(db)
+	idempotencyKeyRepo := repository.NewIdempotencyKeyRepository(db)
+	virtualAccountRepo := repository.NewVirtualAccountRepository(db)
+	paymentCallbackRepo := repository.NewPaymentCallbackRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	statementRepo := repository.NewStatementRepository(db)
+	receiptRepo := repository.NewReceiptRepository(db)
+	loanProductRepo := repository.NewLoanProductRepository(db)
+	merchantNotificationRepo := repository.NewMerchantNotificationRepository(db)
+
+	// init payment gateway
+	paymentGateway, err := gateway.NewPaymentGateway(config.PaymentGateway)
+	if err != nil {
+		log.Panicf("Failed init payment gateway: %v", err)
+	}
 
 	// init usecase
 	consumerUC := usecase.NewConsumerUsecase(consumerRepo, config.Timeout)
-	merchantUC := usecase.NewMerchantUsecase(merchantRepo, config.Timeout)
-	consumerLimitUC := usecase.NewConsumerLimitUsecase(consumerLimitRepo, config.Timeout)
+	merchantUC := usecase.NewMerchantUsecase(merchantRepo, merchantNotificationRepo, config.Timeout)
+	consumerLimitUC := usecase.NewConsumerLimitUsecase(consumerLimitRepo, loanProductRepo, config.Timeout)
+	loanProductUC := usecase.NewLoanProductUsecase(loanProductRepo, config.Timeout)
 	loanUC := usecase.NewLoanUsecase(
 		loanRepo,
+		loanInstallmentRepo,
+		loanRestructureRepo,
 		consumerLimitRepo,
 		consumerRepo,
 		merchantRepo,
+		loanProductRepo,
+		ledgerRepo,
+		merchantNotificationRepo,
+		config.Loan,
 		config.Timeout,
 	)
 	transactionUC := usecase.NewTransactionUsecase(
 		transactionRepo,
 		loanRepo,
+		loanInstallmentRepo,
+		loanPenaltyRepo,
+		settlementQuoteRepo,
 		consumerLimitRepo,
 		consumerRepo,
+		ledgerRepo,
+		receiptRepo,
+		config.Settlement,
+		config.Payment,
+		config.Timeout,
+	)
+	ledgerUC := usecase.NewLedgerUsecase(ledgerRepo, config.Timeout)
+	paymentUC := usecase.NewPaymentUsecase(
+		virtualAccountRepo,
+		paymentCallbackRepo,
+		loanRepo,
+		consumerRepo,
+		transactionUC,
+		paymentGateway,
+		config.Timeout,
+	)
+	reconciliationUC := usecase.NewReconciliationUsecase(
+		statementRepo,
+		virtualAccountRepo,
+		loanRepo,
+		loanInstallmentRepo,
+		transactionUC,
+		paymentGateway,
+		config.Timeout,
+	)
+	accountStatementUC := usecase.NewAccountStatementUsecase(
+		consumerRepo,
+		loanRepo,
+		loanRestructureRepo,
+		transactionRepo,
 		config.Timeout,
 	)
+	batchUC := usecase.NewBatchUsecase(
+		batchRunRepo,
+		loanRepo,
+		loanInstallmentRepo,
+		consumerLimitRepo,
+		loanPenaltyRepo,
+		config.Penalty,
+		config.Batch.Timeout,
+	)
+
+	// init job
+	endOfDayJob, err := job.NewEndOfDayJob(batchUC, config.Batch.RunAt)
+	if err != nil {
+		log.Panicf("Failed init end of day job: %v", err)
+	}
+
+	// `main eod [-date YYYY-MM-DD]` runs the end-of-day batch once and exits
+	if len(os.Args) > 1 && os.Args[1] == "eod" {
+		runEndOfDay(endOfDayJob, os.Args[2:])
+		return
+	}
+
+	// `main reconcile -format csv|mt940 -file PATH` imports a bank statement and exits
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		runReconcile(reconciliationUC, os.Args[2:])
+		return
+	}
+
+	jobCtx, stopJob := context.WithCancel(context.Background())
+	defer stopJob()
+	if config.Batch.Enabled {
+		go endOfDayJob.Start(jobCtx)
+	}
 
 	// init global middleware
 	e.Use(middleware.LoggerMiddleware())
 	e.Use(middleware.CORSMiddleware())
 
+	// init route middleware
+	idempotency := rest.IdempotencyMiddleware(idempotencyKeyRepo)
+
 	// init handler
 	v1 := e.Group("/api/v1")
 	rest.NewConsumerHandler(v1, consumerUC)
 	rest.NewMerchantHandler(v1, merchantUC)
 	rest.NewConsumerLimitHandler(v1, consumerLimitUC)
-	rest.NewLoanHandler(v1, loanUC)
-	rest.NewTransactionHandler(v1, transactionUC)
+	rest.NewLoanProductHandler(v1, loanProductUC)
+	rest.NewLoanHandler(v1, loanUC, idempotency)
+	rest.NewTransactionHandler(v1, transactionUC, idempotency)
+	rest.NewPaymentHandler(v1, paymentUC, gateway.SimulationAllowed(config.PaymentGateway, config.Env))
+	rest.NewLedgerHandler(v1, ledgerUC)
+	rest.NewReconciliationHandler(v1, reconciliationUC)
+	rest.NewAccountStatementHandler(v1, accountStatementUC)
 
 	e.Logger.Fatal(e.Start(":" + config.Port))
 
@@ -82,3 +187,42 @@ func main() {
 		e.Logger.Fatal(err)
 	}
 }
+
+func runEndOfDay(endOfDayJob *job.EndOfDayJob, args []string) {
+	flags := flag.NewFlagSet("eod", flag.ExitOnError)
+	date := flags.String("date", time.Now().Format("2006-01-02"), "run date, formatted as YYYY-MM-DD")
+	flags.Parse(args)
+
+	runDate, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+	if err != nil {
+		log.Fatalf("Invalid run date: %v", err)
+	}
+
+	if err := endOfDayJob.Run(context.Background(), runDate); err != nil {
+		os.Exit(1)
+	}
+}
+
+func runReconcile(reconciliationUC usecase.ReconciliationUsecase, args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	format := flags.String("format", "csv", "statement format, csv or mt940")
+	file := flags.String("file", "", "path of the statement file")
+	flags.Parse(args)
+
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatalf("Failed read statement: %v", err)
+	}
+
+	result, err := reconciliationUC.ImportStatement(context.Background(), usecase.ImportStatementRequest{
+		FileName: filepath.Base(*file),
+		Format:   *format,
+		Content:  content,
+	})
+	if err != nil {
+		log.Fatalf("Failed import statement: %v", err)
+	}
+
+	log.Printf("Imported statement %d: %d lines, %d duplicate, %d matched, %d unmatched, %d ambiguous, %d ignored",
+		result.ID, result.TotalLines, result.DuplicateLines, result.MatchedLines, result.UnmatchedLines, result.AmbiguousLines, result.IgnoredLines)
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
+)
+
+// BatchConfig controls the end-of-day batch started together with the server. RunAt is
+// the local time of day, formatted as HH:MM, at which the batch runs every day.
+type BatchConfig struct {
+	Enabled bool
+	RunAt   string
+	Timeout time.Duration
+}
+
+func LoadBatchConfig() BatchConfig {
+	timeout, err := time.ParseDuration(utils.GetEnvWithDefault("BATCH_TIMEOUT", "10m"))
+	if err != nil {
+		timeout = 10 * time.Minute
+	}
+
+	return BatchConfig{
+		Enabled: utils.GetEnvWithDefault("BATCH_ENABLED", "true") == "true",
+		RunAt:   utils.GetEnvWithDefault("BATCH_EOD_RUN_AT", "00:05"),
+		Timeout: timeout,
+	}
+}
@@ -8,10 +8,22 @@ import (
 	"github.com/joho/godotenv"
 )
 
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
 type Config struct {
-	DB      DBConfig
-	Port    string
-	Timeout time.Duration
+	Env            string
+	DB             DBConfig
+	Batch          BatchConfig
+	Loan           LoanConfig
+	Penalty        PenaltyConfig
+	Settlement     SettlementConfig
+	Payment        PaymentConfig
+	PaymentGateway PaymentGatewayConfig
+	Port           string
+	Timeout        time.Duration
 }
 
 func NewConfig() *Config {
@@ -23,8 +35,15 @@ func NewConfig() *Config {
 	appTimeout, err := time.ParseDuration(utils.GetEnvWithDefault("APP_TIMEOUT", "30s"))
 
 	return &Config{
-		DB:      LoadDBConfig(),
-		Port:    utils.GetEnvWithDefault("APP_PORT", "8800"),
-		Timeout: appTimeout,
+		Env:            utils.GetEnvWithDefault("APP_ENV", EnvProduction),
+		DB:             LoadDBConfig(),
+		Batch:          LoadBatchConfig(),
+		Loan:           LoadLoanConfig(),
+		Penalty:        LoadPenaltyConfig(),
+		Settlement:     LoadSettlementConfig(),
+		Payment:        LoadPaymentConfig(),
+		PaymentGateway: LoadPaymentGatewayConfig(),
+		Port:           utils.GetEnvWithDefault("APP_PORT", "8800"),
+		Timeout:        appTimeout,
 	}
 }
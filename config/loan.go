@@ -0,0 +1,24 @@
+package config
+
+import (
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
+)
+
+// LoanConfig controls the loan lifecycle. A disbursed loan can still be cancelled during
+// CoolingOffPeriod after its disbursement, as long as nothing was paid on it.
+type LoanConfig struct {
+	CoolingOffPeriod time.Duration
+}
+
+func LoadLoanConfig() LoanConfig {
+	coolingOffPeriod, err := time.ParseDuration(utils.GetEnvWithDefault("LOAN_COOLING_OFF_PERIOD", "336h"))
+	if err != nil {
+		coolingOffPeriod = 14 * 24 * time.Hour
+	}
+
+	return LoanConfig{
+		CoolingOffPeriod: coolingOffPeriod,
+	}
+}
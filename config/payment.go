@@ -0,0 +1,27 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
+)
+
+// PaymentConfig controls how a payment is applied to a loan. AllocationOrder lists the
+// components of an installment ("penalty", "interest" and "principal") in the order they
+// are paid, installments are always paid oldest first.
+type PaymentConfig struct {
+	AllocationOrder []string
+}
+
+func LoadPaymentConfig() PaymentConfig {
+	var order []string
+	for _, component := range strings.Split(utils.GetEnvWithDefault("PAYMENT_ALLOCATION_ORDER", "penalty,interest,principal"), ",") {
+		if component = strings.TrimSpace(component); component != "" {
+			order = append(order, component)
+		}
+	}
+
+	return PaymentConfig{
+		AllocationOrder: order,
+	}
+}
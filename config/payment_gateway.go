@@ -0,0 +1,33 @@
+package config
+
+import (
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
+)
+
+// PaymentGatewayConfig selects the payment gateway issuing virtual accounts and posting
+// payment callbacks. Without a Provider payments by virtual account are disabled, "fake" is
+// a local gateway for testing offline.
+// Callbacks are signed with CallbackSecret and refused once they are older than
+// CallbackTolerance.
+type PaymentGatewayConfig struct {
+	Provider          string
+	BankCode          string
+	CallbackSecret    string
+	CallbackTolerance time.Duration
+}
+
+func LoadPaymentGatewayConfig() PaymentGatewayConfig {
+	callbackTolerance, err := time.ParseDuration(utils.GetEnvWithDefault("PAYMENT_GATEWAY_CALLBACK_TOLERANCE", "5m"))
+	if err != nil {
+		callbackTolerance = 5 * time.Minute
+	}
+
+	return PaymentGatewayConfig{
+		Provider:          utils.GetEnv("PAYMENT_GATEWAY_PROVIDER"),
+		BankCode:          utils.GetEnvWithDefault("PAYMENT_GATEWAY_BANK_CODE", "8808"),
+		CallbackSecret:    utils.GetEnv("PAYMENT_GATEWAY_CALLBACK_SECRET"),
+		CallbackTolerance: callbackTolerance,
+	}
+}
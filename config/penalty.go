@@ -0,0 +1,46 @@
+package config
+
+import (
+	"strconv"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
+)
+
+// PenaltyConfig controls the late payment penalty (denda) charged on overdue installments.
+// Type is either "percentage", charging Rate percent of the overdue installment per day,
+// or "fixed", charging Amount per day. MaxRate caps the total penalty of an installment
+// at a percentage of the installment amount, 0 means uncapped.
+type PenaltyConfig struct {
+	Type    string
+	Rate    float64
+	Amount  money.Money
+	MaxRate float64
+}
+
+func LoadPenaltyConfig() PenaltyConfig {
+	return PenaltyConfig{
+		Type:    utils.GetEnvWithDefault("PENALTY_TYPE", "percentage"),
+		Rate:    parseFloatWithDefault(utils.GetEnv("PENALTY_DAILY_RATE"), 0.1),
+		Amount:  parseMoneyWithDefault(utils.GetEnv("PENALTY_DAILY_AMOUNT"), money.Zero),
+		MaxRate: parseFloatWithDefault(utils.GetEnv("PENALTY_MAX_RATE"), 100),
+	}
+}
+
+func parseFloatWithDefault(value string, defaultValue float64) float64 {
+	out, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+
+	return out
+}
+
+func parseMoneyWithDefault(value string, defaultValue money.Money) money.Money {
+	out, err := money.Parse(value)
+	if err != nil {
+		return defaultValue
+	}
+
+	return out
+}
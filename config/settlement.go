@@ -0,0 +1,26 @@
+package config
+
+import (
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
+)
+
+// SettlementConfig controls early settlement quotes. FeeRate is the settlement fee as a
+// percentage of the remaining principal and QuoteValidity is how long a quote can be paid.
+type SettlementConfig struct {
+	FeeRate       float64
+	QuoteValidity time.Duration
+}
+
+func LoadSettlementConfig() SettlementConfig {
+	quoteValidity, err := time.ParseDuration(utils.GetEnvWithDefault("SETTLEMENT_QUOTE_VALIDITY", "24h"))
+	if err != nil {
+		quoteValidity = 24 * time.Hour
+	}
+
+	return SettlementConfig{
+		FeeRate:       parseFloatWithDefault(utils.GetEnv("SETTLEMENT_FEE_RATE"), 0),
+		QuoteValidity: quoteValidity,
+	}
+}
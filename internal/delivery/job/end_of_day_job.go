@@ -0,0 +1,77 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+)
+
+// EndOfDayJob runs the end-of-day batch once a day at RunAt, the offset from midnight.
+type EndOfDayJob struct {
+	BatchUC usecase.BatchUsecase
+	RunAt   time.Duration
+}
+
+// NewEndOfDayJob creates the job for a run time formatted as HH:MM.
+func NewEndOfDayJob(batchUC usecase.BatchUsecase, runAt string) (*EndOfDayJob, error) {
+	clock, err := time.Parse("15:04", runAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end of day run time %q, expected HH:MM", runAt)
+	}
+
+	return &EndOfDayJob{
+		BatchUC: batchUC,
+		RunAt:   time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute,
+	}, nil
+}
+
+// Start blocks, running the batch every day until ctx is cancelled.
+func (j *EndOfDayJob) Start(ctx context.Context) {
+	for {
+		next := j.NextRun(time.Now())
+		logger.Info(fmt.Sprintf("[EndOfDayJob] next run at %s", next.Format("2006-01-02 15:04:05")))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			j.Run(ctx, time.Now())
+		}
+	}
+}
+
+// Run executes the batch once for runDate and logs its outcome. The run is skipped when
+// runDate was run already, by this instance or another one.
+func (j *EndOfDayJob) Run(ctx context.Context, runDate time.Time) error {
+	result, err := j.BatchUC.RunEndOfDay(ctx, runDate)
+	if errors.Is(err, usecase.ErrBatchAlreadyRun) {
+		logger.Info(fmt.Sprintf("[EndOfDayJob] batch for %s already ran, skipped", runDate.Format("2006-01-02")))
+		return nil
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[EndOfDayJob][Run] while run end of day batch. Err: %v", err))
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("[EndOfDayJob] batch run %d for %s finished, %d of %d loans updated, %d failed",
+		result.ID, result.RunDate, result.UpdatedLoans, result.TotalLoans, result.FailedLoans))
+
+	return nil
+}
+
+// NextRun returns the first run time after now.
+func (j *EndOfDayJob) NextRun(now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := midnight.Add(j.RunAt)
+	if !next.After(now) {
+		next = midnight.AddDate(0, 0, 1).Add(j.RunAt)
+	}
+
+	return next
+}
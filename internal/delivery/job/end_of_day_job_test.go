@@ -0,0 +1,71 @@
+package job_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/job"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewEndOfDayJob(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		endOfDayJob, err := job.NewEndOfDayJob(new(mocks.BatchUsecase), "01:30")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Hour+30*time.Minute, endOfDayJob.RunAt)
+	})
+
+	t.Run("invalid run time", func(t *testing.T) {
+		endOfDayJob, err := job.NewEndOfDayJob(new(mocks.BatchUsecase), "25:00")
+		assert.Error(t, err)
+		assert.Nil(t, endOfDayJob)
+	})
+}
+
+func TestEndOfDayJobNextRun(t *testing.T) {
+	endOfDayJob := &job.EndOfDayJob{RunAt: 5 * time.Minute}
+
+	t.Run("later today", func(t *testing.T) {
+		now := time.Date(2024, 3, 20, 0, 1, 0, 0, time.UTC)
+		assert.Equal(t, time.Date(2024, 3, 20, 0, 5, 0, 0, time.UTC), endOfDayJob.NextRun(now))
+	})
+
+	t.Run("tomorrow once today's run passed", func(t *testing.T) {
+		now := time.Date(2024, 3, 20, 0, 5, 0, 0, time.UTC)
+		assert.Equal(t, time.Date(2024, 3, 21, 0, 5, 0, 0, time.UTC), endOfDayJob.NextRun(now))
+	})
+}
+
+func TestEndOfDayJobRun(t *testing.T) {
+	mockBatchUC := new(mocks.BatchUsecase)
+	endOfDayJob := &job.EndOfDayJob{BatchUC: mockBatchUC}
+	runDate := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		mockBatchUC.On("RunEndOfDay", mock.Anything, runDate).Return(usecase.BatchRunResponse{ID: 1}, nil).Once()
+
+		err := endOfDayJob.Run(context.Background(), runDate)
+		assert.NoError(t, err)
+	})
+
+	t.Run("already run", func(t *testing.T) {
+		mockBatchUC.On("RunEndOfDay", mock.Anything, runDate).Return(usecase.BatchRunResponse{}, usecase.ErrBatchAlreadyRun).Once()
+
+		err := endOfDayJob.Run(context.Background(), runDate)
+		assert.NoError(t, err)
+	})
+
+	t.Run("batch error", func(t *testing.T) {
+		mockBatchUC.On("RunEndOfDay", mock.Anything, runDate).Return(usecase.BatchRunResponse{}, errors.New("db error")).Once()
+
+		err := endOfDayJob.Run(context.Background(), runDate)
+		assert.Error(t, err)
+	})
+
+	mockBatchUC.AssertExpectations(t)
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/response"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+)
+
+type AccountStatementHandler struct {
+	AccountStatementUC usecase.AccountStatementUsecase
+}
+
+// NewAccountStatementHandler will initialize the account statement resources endpoint
+func NewAccountStatementHandler(g *echo.Group, accountStatementUC usecase.AccountStatementUsecase) {
+	handler := &AccountStatementHandler{
+		AccountStatementUC: accountStatementUC,
+	}
+
+	consumerGroup := g.Group("/consumers")
+
+	consumerGroup.GET("/:id/statement", handler.GetStatement)
+}
+
+func (h *AccountStatementHandler) GetStatement(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[AccountStatementHandler][GetStatement] while parse consumer ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid consumer ID")
+	}
+
+	req := usecase.AccountStatementRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[AccountStatementHandler][GetStatement] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	req.ConsumerID = id
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.From, validation.Date("2006-01-02")),
+		validation.Field(&req.To, validation.Date("2006-01-02")),
+		validation.Field(&req.Format, validation.In("json", "csv", "pdf")),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[AccountStatementHandler][GetStatement] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.AccountStatementUC.GetAccountStatement(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	if data.ConsumerID == 0 {
+		return response.ErrorResponseWithMessage(c, http.StatusNotFound, "Consumer not found")
+	}
+
+	var (
+		body        bytes.Buffer
+		contentType string
+	)
+	switch req.Format {
+	case "csv":
+		contentType = "text/csv"
+		err = data.WriteCSV(&body)
+	case "pdf":
+		contentType = "application/pdf"
+		err = data.WritePDF(&body)
+	default:
+		return response.SuccessResponseWithData(c, http.StatusOK, data)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[AccountStatementHandler][GetStatement] while write %s statement, Err: %+v", req.Format, err))
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s-%s.%s", data.ConsumerID, data.From, data.To, req.Format)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	return c.Blob(http.StatusOK, contentType, body.Bytes())
+}
@@ -0,0 +1,119 @@
+package rest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/rest"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAccountStatement(t *testing.T) {
+	e := echo.New()
+	mockAccountStatementUC := new(mocks.AccountStatementUsecase)
+	handler := &rest.AccountStatementHandler{
+		AccountStatementUC: mockAccountStatementUC,
+	}
+
+	statement := usecase.AccountStatementResponse{
+		ConsumerID:     1,
+		FullName:       "John Doe",
+		From:           "2024-01-01",
+		To:             "2024-01-31",
+		OpeningBalance: money.New(1000),
+		Entries:        []usecase.AccountStatementEntry{},
+		ClosingBalance: money.New(1000),
+	}
+
+	newContext := func(target string, id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return c, rec
+	}
+
+	t.Run("json", func(t *testing.T) {
+		c, rec := newContext("/consumers/1/statement?from=2024-01-01&to=2024-01-31", "1")
+
+		mockAccountStatementUC.On("GetAccountStatement", mock.Anything, usecase.AccountStatementRequest{ConsumerID: 1, From: "2024-01-01", To: "2024-01-31"}).Return(statement, nil).Once()
+
+		err := handler.GetStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"opening_balance":1000`)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		c, rec := newContext("/consumers/1/statement?from=2024-01-01&to=2024-01-31&format=csv", "1")
+
+		mockAccountStatementUC.On("GetAccountStatement", mock.Anything, usecase.AccountStatementRequest{ConsumerID: 1, From: "2024-01-01", To: "2024-01-31", Format: "csv"}).Return(statement, nil).Once()
+
+		err := handler.GetStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, `attachment; filename="statement-1-2024-01-01-2024-01-31.csv"`, rec.Header().Get(echo.HeaderContentDisposition))
+		assert.True(t, strings.HasPrefix(rec.Body.String(), "date,type,contract_number"))
+	})
+
+	t.Run("pdf", func(t *testing.T) {
+		c, rec := newContext("/consumers/1/statement?format=pdf", "1")
+
+		mockAccountStatementUC.On("GetAccountStatement", mock.Anything, usecase.AccountStatementRequest{ConsumerID: 1, Format: "pdf"}).Return(statement, nil).Once()
+
+		err := handler.GetStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/pdf", rec.Header().Get(echo.HeaderContentType))
+		assert.True(t, strings.HasPrefix(rec.Body.String(), "%PDF-1.4"))
+	})
+
+	t.Run("invalid consumer id", func(t *testing.T) {
+		c, rec := newContext("/consumers/abc/statement", "abc")
+
+		err := handler.GetStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		c, rec := newContext("/consumers/1/statement?format=xlsx", "1")
+
+		err := handler.GetStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "format")
+	})
+
+	t.Run("consumer not found", func(t *testing.T) {
+		c, rec := newContext("/consumers/9/statement", "9")
+
+		mockAccountStatementUC.On("GetAccountStatement", mock.Anything, usecase.AccountStatementRequest{ConsumerID: 9}).Return(usecase.AccountStatementResponse{}, nil).Once()
+
+		err := handler.GetStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		c, rec := newContext("/consumers/1/statement", "1")
+
+		mockAccountStatementUC.On("GetAccountStatement", mock.Anything, usecase.AccountStatementRequest{ConsumerID: 1}).Return(usecase.AccountStatementResponse{}, errors.New("db down")).Once()
+
+		err := handler.GetStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	mockAccountStatementUC.AssertExpectations(t)
+}
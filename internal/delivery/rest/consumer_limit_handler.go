@@ -76,7 +76,7 @@ func (h *ConsumerLimitHandler) CreateOrUpdate(c echo.Context) error {
 	if err := validation.ValidateStruct(&req,
 		validation.Field(&req.ConsumerID, validation.Required),
 		validation.Field(&req.Tenure, validation.Required),
-		validation.Field(&req.LimitAmount, validation.Required),
+		validation.Field(&req.LimitAmount, amountRequired),
 	); err != nil {
 		logger.Warning(fmt.Sprintf("[ConsumerLimitHandler][CreateOrUpdate] while validate req, Err: %+v", err))
 		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
@@ -11,6 +11,7 @@ import (
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/rest"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -30,7 +31,7 @@ func TestGetByConsumerID(t *testing.T) {
 				ID:          1,
 				ConsumerID:  10,
 				Tenure:      2,
-				LimitAmount: 1000000,
+				LimitAmount: money.New(1000000),
 			},
 		}
 		mockUsecase.On("GetConsumerLimitByConsumerID", mock.Anything, consumerID).Return(expectedData, nil).Once()
@@ -94,7 +95,7 @@ func TestGetByConsumerIDAndTenure(t *testing.T) {
 			ID:          1,
 			ConsumerID:  10,
 			Tenure:      2,
-			LimitAmount: 1000000,
+			LimitAmount: money.New(1000000),
 		}
 		mockUsecase.On("GetLimitByTenureAndConsumerID", mock.Anything, tenure, consumerID).Return(expectedData, nil).Once()
 
@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/middleware"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/response"
+	"github.com/labstack/echo/v4"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyMiddleware deduplicates retried requests carrying an Idempotency-Key header.
+// The first request with a key is processed and its response recorded, a retry with the
+// same key and body gets the recorded response back without being processed again. A key
+// reused with a different body is rejected. Requests failing with a 5xx status release
+// their key, so they can be retried. Requests without the header are processed as usual.
+func IdempotencyMiddleware(repo repository.IdempotencyKeyRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(middleware.HeaderIdempotencyKey)
+			if key == "" {
+				return next(c)
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				return response.ErrorResponseWithMessage(c, http.StatusBadRequest, fmt.Sprintf("%s must be at most %d characters", middleware.HeaderIdempotencyKey, maxIdempotencyKeyLength))
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[IdempotencyMiddleware] while read request body, Err: %+v", err))
+				return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := sha256.Sum256(body)
+			ref := repository.GetIdempotencyKeyRequest{
+				Key:           key,
+				RequestMethod: req.Method,
+				RequestPath:   req.URL.Path,
+			}
+
+			created, err := repo.CreateIdempotencyKey(req.Context(), repository.IdempotencyKey{
+				Key:           ref.Key,
+				RequestMethod: ref.RequestMethod,
+				RequestPath:   ref.RequestPath,
+				RequestHash:   hex.EncodeToString(hash[:]),
+			})
+			if err != nil {
+				return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+			}
+
+			if !created {
+				return replayIdempotentResponse(c, repo, ref, hex.EncodeToString(hash[:]))
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			err = next(c)
+			if err != nil || c.Response().Status >= http.StatusInternalServerError {
+				if deleteErr := repo.DeleteIdempotencyKey(req.Context(), ref); deleteErr != nil {
+					logger.Error(fmt.Sprintf("[IdempotencyMiddleware] while release idempotency key %s, Err: %+v", key, deleteErr))
+				}
+				return err
+			}
+
+			err = repo.SaveIdempotencyKeyResponse(req.Context(), repository.SaveIdempotencyKeyResponseRequest{
+				Key:            ref.Key,
+				RequestMethod:  ref.RequestMethod,
+				RequestPath:    ref.RequestPath,
+				ResponseStatus: c.Response().Status,
+				ResponseBody:   recorder.body.Bytes(),
+			})
+			if err != nil {
+				// the request itself succeeded, a retry will be told it is still being processed
+				logger.Error(fmt.Sprintf("[IdempotencyMiddleware] while save response of idempotency key %s, Err: %+v", key, err))
+			}
+
+			return nil
+		}
+	}
+}
+
+func replayIdempotentResponse(c echo.Context, repo repository.IdempotencyKeyRepository, ref repository.GetIdempotencyKeyRequest, hash string) error {
+	stored, err := repo.GetIdempotencyKey(c.Request().Context(), ref)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	switch {
+	case stored.ID == 0:
+		// released by a failed request in the meantime
+		return response.ErrorResponseWithMessage(c, http.StatusConflict, fmt.Sprintf("request with this %s failed, please retry", middleware.HeaderIdempotencyKey))
+	case stored.RequestHash != hash:
+		return response.ErrorResponseWithMessage(c, http.StatusUnprocessableEntity, fmt.Sprintf("%s was already used for a different request", middleware.HeaderIdempotencyKey))
+	case stored.ResponseStatus == 0:
+		return response.ErrorResponseWithMessage(c, http.StatusConflict, fmt.Sprintf("request with this %s is still being processed", middleware.HeaderIdempotencyKey))
+	}
+
+	c.Response().Header().Set(middleware.HeaderIdempotentReplayed, "true")
+	return c.Blob(stored.ResponseStatus, echo.MIMEApplicationJSONCharsetUTF8, stored.ResponseBody)
+}
+
+// responseRecorder keeps a copy of the response body written through it.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
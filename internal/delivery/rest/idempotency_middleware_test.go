@@ -0,0 +1,197 @@
+package rest_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/rest"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	reqBody := `{"consumer_id": 1, "loan_id": 1, "transaction_type": "installment"}`
+	hash := sha256.Sum256([]byte(reqBody))
+	requestHash := hex.EncodeToString(hash[:])
+	ref := repository.GetIdempotencyKeyRequest{
+		Key:           "key-1",
+		RequestMethod: http.MethodPost,
+		RequestPath:   "/transactions",
+	}
+
+	tests := []struct {
+		name       string
+		key        string
+		handler    echo.HandlerFunc
+		setup      func(repo *mocks.IdempotencyKeyRepository)
+		wantCalled bool
+		wantErr    bool
+		wantCode   int
+		wantBody   string
+		wantReplay bool
+	}{
+		{
+			name:       "no key",
+			setup:      func(repo *mocks.IdempotencyKeyRepository) {},
+			wantCalled: true,
+			wantCode:   http.StatusCreated,
+			wantBody:   `{"id":1}`,
+		},
+		{
+			name:     "key too long",
+			key:      strings.Repeat("k", 256),
+			setup:    func(repo *mocks.IdempotencyKeyRepository) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "first request records the response",
+			key:  ref.Key,
+			setup: func(repo *mocks.IdempotencyKeyRepository) {
+				repo.On("CreateIdempotencyKey", mock.Anything, repository.IdempotencyKey{
+					Key:           ref.Key,
+					RequestMethod: ref.RequestMethod,
+					RequestPath:   ref.RequestPath,
+					RequestHash:   requestHash,
+				}).Return(true, nil).Once()
+				repo.On("SaveIdempotencyKeyResponse", mock.Anything, repository.SaveIdempotencyKeyResponseRequest{
+					Key:            ref.Key,
+					RequestMethod:  ref.RequestMethod,
+					RequestPath:    ref.RequestPath,
+					ResponseStatus: http.StatusCreated,
+					ResponseBody:   []byte(`{"id":1}` + "\n"),
+				}).Return(nil).Once()
+			},
+			wantCalled: true,
+			wantCode:   http.StatusCreated,
+			wantBody:   `{"id":1}`,
+		},
+		{
+			name: "failed request releases the key",
+			key:  ref.Key,
+			handler: func(c echo.Context) error {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"message": "db down"})
+			},
+			setup: func(repo *mocks.IdempotencyKeyRepository) {
+				repo.On("CreateIdempotencyKey", mock.Anything, mock.Anything).Return(true, nil).Once()
+				repo.On("DeleteIdempotencyKey", mock.Anything, ref).Return(nil).Once()
+			},
+			wantCalled: true,
+			wantCode:   http.StatusInternalServerError,
+		},
+		{
+			name: "handler error releases the key",
+			key:  ref.Key,
+			handler: func(c echo.Context) error {
+				return errors.New("unexpected")
+			},
+			setup: func(repo *mocks.IdempotencyKeyRepository) {
+				repo.On("CreateIdempotencyKey", mock.Anything, mock.Anything).Return(true, nil).Once()
+				repo.On("DeleteIdempotencyKey", mock.Anything, ref).Return(nil).Once()
+			},
+			wantCalled: true,
+			wantErr:    true,
+		},
+		{
+			name: "replay returns the recorded response",
+			key:  ref.Key,
+			setup: func(repo *mocks.IdempotencyKeyRepository) {
+				repo.On("CreateIdempotencyKey", mock.Anything, mock.Anything).Return(false, nil).Once()
+				repo.On("GetIdempotencyKey", mock.Anything, ref).Return(repository.IdempotencyKey{
+					ID:             1,
+					RequestHash:    requestHash,
+					ResponseStatus: http.StatusCreated,
+					ResponseBody:   []byte(`{"id":1}`),
+				}, nil).Once()
+			},
+			wantCode:   http.StatusCreated,
+			wantBody:   `{"id":1}`,
+			wantReplay: true,
+		},
+		{
+			name: "key reused with a different body",
+			key:  ref.Key,
+			setup: func(repo *mocks.IdempotencyKeyRepository) {
+				repo.On("CreateIdempotencyKey", mock.Anything, mock.Anything).Return(false, nil).Once()
+				repo.On("GetIdempotencyKey", mock.Anything, ref).Return(repository.IdempotencyKey{
+					ID:             1,
+					RequestHash:    "other",
+					ResponseStatus: http.StatusCreated,
+				}, nil).Once()
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "original request still in progress",
+			key:  ref.Key,
+			setup: func(repo *mocks.IdempotencyKeyRepository) {
+				repo.On("CreateIdempotencyKey", mock.Anything, mock.Anything).Return(false, nil).Once()
+				repo.On("GetIdempotencyKey", mock.Anything, ref).Return(repository.IdempotencyKey{
+					ID:          1,
+					RequestHash: requestHash,
+				}, nil).Once()
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "create key error",
+			key:  ref.Key,
+			setup: func(repo *mocks.IdempotencyKeyRepository) {
+				repo.On("CreateIdempotencyKey", mock.Anything, mock.Anything).Return(false, errors.New("error")).Once()
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.IdempotencyKeyRepository)
+			tt.setup(repo)
+
+			called := false
+			handler := tt.handler
+			if handler == nil {
+				handler = func(c echo.Context) error {
+					return c.JSON(http.StatusCreated, map[string]int{"id": 1})
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.key != "" {
+				req.Header.Set(middleware.HeaderIdempotencyKey, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			err := rest.IdempotencyMiddleware(repo)(func(c echo.Context) error {
+				called = true
+				// the handler still reads the full body
+				body, _ := io.ReadAll(c.Request().Body)
+				assert.Equal(t, reqBody, string(body))
+				return handler(c)
+			})(c)
+
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantCalled, called)
+			if !tt.wantErr {
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+			assert.Equal(t, tt.wantReplay, rec.Header().Get(middleware.HeaderIdempotentReplayed) == "true")
+			repo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/response"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+)
+
+type LedgerHandler struct {
+	LedgerUC usecase.LedgerUsecase
+}
+
+// NewLedgerHandler will initialize the ledger resources endpoint
+func NewLedgerHandler(g *echo.Group, ledgerUC usecase.LedgerUsecase) {
+	handler := &LedgerHandler{
+		LedgerUC: ledgerUC,
+	}
+
+	ledgerGroup := g.Group("/ledger")
+
+	ledgerGroup.GET("/trial-balance", handler.TrialBalance)
+}
+
+func (h *LedgerHandler) TrialBalance(c echo.Context) error {
+	req := usecase.TrialBalanceRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[LedgerHandler][TrialBalance] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.AsOf, validation.Date("2006-01-02")),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[LedgerHandler][TrialBalance] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.LedgerUC.GetTrialBalance(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
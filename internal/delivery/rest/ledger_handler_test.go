@@ -0,0 +1,67 @@
+package rest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/rest"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTrialBalance(t *testing.T) {
+	e := echo.New()
+	mockLedgerUC := new(mocks.LedgerUsecase)
+	handler := &rest.LedgerHandler{
+		LedgerUC: mockLedgerUC,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ledger/trial-balance?as_of=2024-01-31", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockLedgerUC.On("GetTrialBalance", mock.Anything, usecase.TrialBalanceRequest{AsOf: "2024-01-31"}).Return(usecase.TrialBalanceResponse{
+			AsOf:        "2024-01-31",
+			TotalDebit:  money.New(1000),
+			TotalCredit: money.New(1000),
+			Balanced:    true,
+		}, nil).Once()
+
+		err := handler.TrialBalance(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"balanced":true`)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ledger/trial-balance?as_of=31-01-2024", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.TrialBalance(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "as_of")
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ledger/trial-balance", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockLedgerUC.On("GetTrialBalance", mock.Anything, usecase.TrialBalanceRequest{}).Return(usecase.TrialBalanceResponse{}, errors.New("db down")).Once()
+
+		err := handler.TrialBalance(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	mockLedgerUC.AssertExpectations(t)
+}
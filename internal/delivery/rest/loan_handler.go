@@ -1,12 +1,15 @@
 package rest
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/response"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/labstack/echo/v4"
@@ -17,15 +20,24 @@ type LoanHandler struct {
 }
 
 // NewLoanHandler will initialize the loan resources endpoint
-func NewLoanHandler(g *echo.Group, loanUC usecase.LoanUsecase) {
+func NewLoanHandler(g *echo.Group, loanUC usecase.LoanUsecase, idempotency echo.MiddlewareFunc) {
 	handler := &LoanHandler{
 		LoanUC: loanUC,
 	}
 
 	loanGroup := g.Group("/loans")
 
-	loanGroup.POST("", handler.Create)
+	loanGroup.POST("", handler.Create, idempotency)
+	loanGroup.GET("", handler.Fetch)
+	loanGroup.POST("/simulate", handler.Simulate)
 	loanGroup.GET("/:id", handler.GetByID)
+	loanGroup.GET("/:id/schedule", handler.GetSchedule)
+	loanGroup.POST("/:id/approve", handler.Approve)
+	loanGroup.POST("/:id/reject", handler.Reject)
+	loanGroup.POST("/:id/disburse", handler.Disburse)
+	loanGroup.POST("/:id/cancel", handler.Cancel)
+	loanGroup.POST("/:id/restructure", handler.Restructure)
+	loanGroup.GET("/:id/restructures", handler.GetRestructures)
 	loanGroup.GET("/consumer/:consumerId", handler.GetByConsumerID)
 	loanGroup.DELETE("/:id", handler.Delete)
 }
@@ -40,9 +52,11 @@ func (h *LoanHandler) Create(c echo.Context) error {
 	if err := validation.ValidateStruct(&req,
 		validation.Field(&req.ConsumerID, validation.Required),
 		validation.Field(&req.MerchantID, validation.Required),
+		validation.Field(&req.ProductCode, validation.Required),
 		validation.Field(&req.Tenure, validation.Required),
-		validation.Field(&req.LoanAmount, validation.Required),
-		validation.Field(&req.InterestRate, validation.Required),
+		validation.Field(&req.AssetPrice, amountRequired),
+		validation.Field(&req.DownPaymentAmount, amountNotNegative, downPaymentBelow(req.AssetPrice)),
+		validation.Field(&req.FeePayment, validation.In(usecase.FeePaymentUpfront, usecase.FeePaymentFinanced)),
 		validation.Field(&req.AssetName, validation.Required),
 	); err != nil {
 		logger.Warning(fmt.Sprintf("[LoanHandler][Create] while validate request, Err: %+v", err))
@@ -51,12 +65,100 @@ func (h *LoanHandler) Create(c echo.Context) error {
 
 	_, err := h.LoanUC.CreateLoan(c.Request().Context(), req)
 	if err != nil {
+		if errors.Is(err, usecase.ErrLoanProductNotOffered) {
+			return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+		}
+
 		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
 	}
 
 	return response.SuccessResponseWithMessage(c, http.StatusCreated, "Loan created successfully")
 }
 
+// downPaymentBelow checks a down payment is less than the price of the asset, when the
+// price is valid.
+func downPaymentBelow(assetPrice money.Money) validation.Rule {
+	return validation.By(func(value interface{}) error {
+		downPaymentAmount, _ := value.(money.Money)
+		if assetPrice.IsPositive() && !downPaymentAmount.LessThan(assetPrice) {
+			return errors.New("must be less than the asset price")
+		}
+
+		return nil
+	})
+}
+
+func (h *LoanHandler) Simulate(c echo.Context) error {
+	req := usecase.SimulateLoanRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][Simulate] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.ConsumerID, validation.Required),
+		validation.Field(&req.MerchantID, validation.Required),
+		validation.Field(&req.ProductCode, validation.Required),
+		validation.Field(&req.AssetPrice, amountRequired),
+		validation.Field(&req.DownPaymentAmount, amountNotNegative, downPaymentBelow(req.AssetPrice)),
+		validation.Field(&req.FeePayment, validation.In(usecase.FeePaymentUpfront, usecase.FeePaymentFinanced)),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[LoanHandler][Simulate] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.LoanUC.SimulateLoan(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
+
+func (h *LoanHandler) Fetch(c echo.Context) error {
+	req := usecase.FetchLoansRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][Fetch] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.LoanStatus, validLoanStatuses),
+		validation.Field(&req.CreatedFrom, validation.Date("2006-01-02")),
+		validation.Field(&req.CreatedTo, validation.Date("2006-01-02")),
+		validation.Field(&req.DueFrom, validation.Date("2006-01-02")),
+		validation.Field(&req.DueTo, validation.Date("2006-01-02")),
+		validation.Field(&req.MinOutstandingAmount, amountNotNegative),
+		validation.Field(&req.MaxOutstandingAmount, amountNotNegative),
+		validation.Field(&req.SortBy, validation.In("created_at", "due_date", "loan_amount", "outstanding_amount")),
+		validation.Field(&req.SortOrder, validation.In("asc", "desc")),
+		validation.Field(&req.Page, validation.Min(0)),
+		validation.Field(&req.Limit, validation.Min(0), validation.Max(100)),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[LoanHandler][Fetch] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.LoanUC.FetchLoans(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
+
+// validLoanStatuses checks a comma separated list of loan statuses.
+var validLoanStatuses = validation.By(func(value interface{}) error {
+	loanStatuses, _ := value.(string)
+	for _, loanStatus := range usecase.SplitLoanStatuses(loanStatuses) {
+		if !repository.ValidLoanStatus[loanStatus] {
+			return fmt.Errorf("%s is not a valid loan status", loanStatus)
+		}
+	}
+
+	return nil
+})
+
 func (h *LoanHandler) GetByID(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -72,6 +174,21 @@ func (h *LoanHandler) GetByID(c echo.Context) error {
 	return response.SuccessResponseWithData(c, http.StatusOK, data)
 }
 
+func (h *LoanHandler) GetSchedule(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][GetSchedule] while parse loan ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid loan ID")
+	}
+
+	data, err := h.LoanUC.GetLoanSchedule(c.Request().Context(), id)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
+
 func (h *LoanHandler) GetByConsumerID(c echo.Context) error {
 	consumerID, err := strconv.ParseInt(c.Param("consumerId"), 10, 64)
 	if err != nil {
@@ -96,8 +213,167 @@ func (h *LoanHandler) Delete(c echo.Context) error {
 
 	err = h.LoanUC.DeleteLoanByID(c.Request().Context(), id)
 	if err != nil {
+		if errors.Is(err, usecase.ErrLoanNotDeletable) || errors.Is(err, usecase.ErrLoanHasTransactions) {
+			return response.ErrorResponseWithMessage(c, http.StatusConflict, err.Error())
+		}
+
 		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
 	}
 
 	return response.SuccessResponseWithMessage(c, http.StatusOK, "Loan deleted successfully")
 }
+
+func (h *LoanHandler) Approve(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][Approve] while parse loan ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid loan ID")
+	}
+
+	err = h.LoanUC.ApproveLoan(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrLoanStatusConflict) {
+			return response.ErrorResponseWithMessage(c, http.StatusConflict, err.Error())
+		}
+
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithMessage(c, http.StatusOK, "Loan approved successfully")
+}
+
+func (h *LoanHandler) Reject(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][Reject] while parse loan ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid loan ID")
+	}
+
+	req := usecase.LoanStatusRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][Reject] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	req.LoanID = id
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.Reason, validation.Required, validation.Length(1, 255)),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[LoanHandler][Reject] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	err = h.LoanUC.RejectLoan(c.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, repository.ErrLoanStatusConflict) {
+			return response.ErrorResponseWithMessage(c, http.StatusConflict, err.Error())
+		}
+
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithMessage(c, http.StatusOK, "Loan rejected successfully")
+}
+
+func (h *LoanHandler) Disburse(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][Disburse] while parse loan ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid loan ID")
+	}
+
+	err = h.LoanUC.DisburseLoan(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrLoanStatusConflict) {
+			return response.ErrorResponseWithMessage(c, http.StatusConflict, err.Error())
+		}
+
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithMessage(c, http.StatusOK, "Loan disbursed successfully")
+}
+
+func (h *LoanHandler) Cancel(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][Cancel] while parse loan ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid loan ID")
+	}
+
+	req := usecase.LoanStatusRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][Cancel] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	req.LoanID = id
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.Reason, validation.Required, validation.Length(1, 255)),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[LoanHandler][Cancel] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	err = h.LoanUC.CancelLoan(c.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, usecase.ErrLoanNotCancellable) || errors.Is(err, repository.ErrLoanStatusConflict) {
+			return response.ErrorResponseWithMessage(c, http.StatusConflict, err.Error())
+		}
+
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithMessage(c, http.StatusOK, "Loan cancelled successfully")
+}
+
+func (h *LoanHandler) Restructure(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][Restructure] while parse loan ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid loan ID")
+	}
+
+	req := usecase.RestructureLoanRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][Restructure] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	req.LoanID = id
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.RestructureType, validation.Required, validation.In(
+			usecase.RestructureTypeExtendTenure,
+			usecase.RestructureTypeReduceInstallment,
+			usecase.RestructureTypeCapitalizeArrears,
+		)),
+		validation.Field(&req.Tenure, validation.Min(0)),
+		validation.Field(&req.InstallmentAmount, amountNotNegative),
+		validation.Field(&req.Reason, validation.Required, validation.Length(1, 255)),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[LoanHandler][Restructure] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.LoanUC.RestructureLoan(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusCreated, data)
+}
+
+func (h *LoanHandler) GetRestructures(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanHandler][GetRestructures] while parse loan ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid loan ID")
+	}
+
+	data, err := h.LoanUC.GetLoanRestructures(c.Request().Context(), id)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
@@ -2,6 +2,7 @@ package rest_test
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -9,7 +10,9 @@ import (
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/rest"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -23,13 +26,15 @@ func TestCreateLoan(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		reqBody := `{"consumer_id":1,"merchant_id":1,"tenure":12,"loan_amount":1000000,"interest_rate":5.5,"asset_name":"Car"}`
+		reqBody := `{"consumer_id":1,"merchant_id":1,"tenure":12,"asset_price":1000000,"down_payment_amount":200000,"fee_payment":"financed","insured":true,"product_code":"MOTOR","asset_name":"Car"}`
 		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		mockUsecase.On("CreateLoan", mock.Anything, mock.AnythingOfType("usecase.CreateLoanRequest")).Return(usecase.LoanResponse{}, nil).Once()
+		mockUsecase.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req usecase.CreateLoanRequest) bool {
+			return req.AssetPrice == money.New(1000000) && req.DownPaymentAmount == money.New(200000) && req.FeePayment == usecase.FeePaymentFinanced && req.Insured
+		})).Return(usecase.LoanResponse{}, nil).Once()
 
 		err := handler.Create(c)
 		if assert.NoError(t, err) {
@@ -66,8 +71,94 @@ func TestCreateLoan(t *testing.T) {
 		}
 	})
 
+	t.Run("asset price not positive", func(t *testing.T) {
+		reqBody := `{"consumer_id":1,"merchant_id":1,"tenure":12,"asset_price":-1000,"product_code":"MOTOR","asset_name":"Car"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Create(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "asset_price: must be greater than 0")
+		}
+	})
+
+	t.Run("asset price too precise", func(t *testing.T) {
+		reqBody := `{"consumer_id":1,"merchant_id":1,"tenure":12,"asset_price":1000.0001,"product_code":"MOTOR","asset_name":"Car"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Create(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "more than 3 decimal places")
+		}
+	})
+
+	t.Run("down payment not less than asset price", func(t *testing.T) {
+		reqBody := `{"consumer_id":1,"merchant_id":1,"tenure":12,"asset_price":1000000,"down_payment_amount":1000000,"product_code":"MOTOR","asset_name":"Car"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Create(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "down_payment_amount: must be less than the asset price")
+		}
+	})
+
+	t.Run("invalid fee payment", func(t *testing.T) {
+		reqBody := `{"consumer_id":1,"merchant_id":1,"tenure":12,"asset_price":1000000,"fee_payment":"later","product_code":"MOTOR","asset_name":"Car"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Create(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "fee_payment: must be a valid value")
+		}
+	})
+
+	t.Run("missing product code", func(t *testing.T) {
+		reqBody := `{"consumer_id":1,"merchant_id":1,"tenure":12,"asset_price":1000000,"asset_name":"Car"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Create(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "product_code: cannot be blank")
+		}
+	})
+
+	t.Run("loan product not offered", func(t *testing.T) {
+		reqBody := `{"consumer_id":1,"merchant_id":1,"tenure":24,"asset_price":1000000,"product_code":"MOTOR","asset_name":"Car"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockUsecase.On("CreateLoan", mock.Anything, mock.AnythingOfType("usecase.CreateLoanRequest")).Return(usecase.LoanResponse{}, fmt.Errorf("%w: tenure 24 is not offered by MOTOR", usecase.ErrLoanProductNotOffered)).Once()
+
+		err := handler.Create(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "tenure 24 is not offered by MOTOR")
+		}
+	})
+
 	t.Run("internal server error", func(t *testing.T) {
-		reqBody := `{"consumer_id":1,"merchant_id":1,"tenure":12,"loan_amount":1000000,"interest_rate":5.5,"asset_name":"Car"}`
+		reqBody := `{"consumer_id":1,"merchant_id":1,"tenure":12,"asset_price":1000000,"product_code":"MOTOR","asset_name":"Car"}`
 		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -83,6 +174,84 @@ func TestCreateLoan(t *testing.T) {
 	})
 }
 
+func TestSimulateLoan(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(mocks.LoanUsecase)
+	handler := &rest.LoanHandler{
+		LoanUC: mockUsecase,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		reqBody := `{"consumer_id":1,"merchant_id":1,"product_code":"MOTOR","asset_price":1500000,"down_payment_amount":500000,"fee_payment":"financed"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans/simulate", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockUsecase.On("SimulateLoan", mock.Anything, usecase.SimulateLoanRequest{
+			ConsumerID:        1,
+			MerchantID:        1,
+			ProductCode:       "MOTOR",
+			AssetPrice:        money.New(1500000),
+			DownPaymentAmount: money.New(500000),
+			FeePayment:        usecase.FeePaymentFinanced,
+		}).Return(usecase.LoanSimulationResponse{
+			ConsumerID: 1,
+			Tenures:    []usecase.LoanSimulationTenureResponse{{Tenure: 12, Accepted: true}},
+		}, nil).Once()
+
+		err := handler.Simulate(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"accepted":true`)
+		}
+	})
+
+	t.Run("bind error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/simulate", strings.NewReader(`invalid body`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Simulate(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		reqBody := `{"consumer_id":1,"merchant_id":1,"product_code":"MOTOR","asset_price":1500000,"down_payment_amount":1500000}`
+		req := httptest.NewRequest(http.MethodPost, "/loans/simulate", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Simulate(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "down_payment_amount: must be less than the asset price")
+		}
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		reqBody := `{"consumer_id":1,"merchant_id":1,"product_code":"MOTOR","asset_price":1500000}`
+		req := httptest.NewRequest(http.MethodPost, "/loans/simulate", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockUsecase.On("SimulateLoan", mock.Anything, mock.AnythingOfType("usecase.SimulateLoanRequest")).Return(usecase.LoanSimulationResponse{}, errors.New("consumer not found")).Once()
+
+		err := handler.Simulate(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			assert.Contains(t, rec.Body.String(), "consumer not found")
+		}
+	})
+
+	mockUsecase.AssertExpectations(t)
+}
+
 func TestGetLoanByID(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(mocks.LoanUsecase)
@@ -137,6 +306,140 @@ func TestGetLoanByID(t *testing.T) {
 	})
 }
 
+func TestGetLoanSchedule(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(mocks.LoanUsecase)
+	handler := &rest.LoanHandler{
+		LoanUC: mockUsecase,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/loans/1/schedule", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("GetLoanSchedule", mock.Anything, int64(1)).Return(usecase.LoanScheduleResponse{LoanID: 1}, nil).Once()
+
+		err := handler.GetSchedule(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"installments":`)
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/loans/invalid/schedule", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("invalid")
+
+		err := handler.GetSchedule(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Invalid loan ID")
+		}
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/loans/1/schedule", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("GetLoanSchedule", mock.Anything, int64(1)).Return(usecase.LoanScheduleResponse{}, errors.New("some error")).Once()
+
+		err := handler.GetSchedule(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			assert.Contains(t, rec.Body.String(), "some error")
+		}
+	})
+}
+
+func TestFetchLoans(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name     string
+		query    string
+		setup    func(uc *mocks.LoanUsecase)
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:  "success",
+			query: "?loan_status=on_going,late&merchant_id=2&created_from=2024-01-01&created_to=2024-01-31&min_outstanding_amount=100.50&sort_by=due_date&sort_order=asc&page=2&limit=5",
+			setup: func(uc *mocks.LoanUsecase) {
+				uc.On("FetchLoans", mock.Anything, usecase.FetchLoansRequest{
+					LoanStatus:           "on_going,late",
+					MerchantID:           2,
+					CreatedFrom:          "2024-01-01",
+					CreatedTo:            "2024-01-31",
+					MinOutstandingAmount: money.MustParse("100.50"),
+					SortBy:               "due_date",
+					SortOrder:            "asc",
+					Page:                 2,
+					Limit:                5,
+				}).Return(usecase.LoanListResponse{Loans: []usecase.LoanResponse{{ID: 1, ContractNumber: "CN-1"}}, Page: 2, Limit: 5, Total: 6}, nil).Once()
+			},
+			wantCode: http.StatusOK,
+			wantBody: `"total":6`,
+		},
+		{
+			name:     "invalid loan status",
+			query:    "?loan_status=late,overdue",
+			setup:    func(uc *mocks.LoanUsecase) {},
+			wantCode: http.StatusBadRequest,
+			wantBody: "loan_status: overdue is not a valid loan status",
+		},
+		{
+			name:     "invalid filters",
+			query:    "?due_from=01-03-2024&sort_by=asset_name&limit=500",
+			setup:    func(uc *mocks.LoanUsecase) {},
+			wantCode: http.StatusBadRequest,
+			wantBody: "due_from: must be a valid date",
+		},
+		{
+			name:     "negative outstanding amount",
+			query:    "?max_outstanding_amount=-1",
+			setup:    func(uc *mocks.LoanUsecase) {},
+			wantCode: http.StatusBadRequest,
+			wantBody: "max_outstanding_amount: must be no less than 0",
+		},
+		{
+			name: "internal server error",
+			setup: func(uc *mocks.LoanUsecase) {
+				uc.On("FetchLoans", mock.Anything, usecase.FetchLoansRequest{}).Return(usecase.LoanListResponse{}, errors.New("some error")).Once()
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: "some error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(mocks.LoanUsecase)
+			handler := &rest.LoanHandler{LoanUC: mockUsecase}
+			tt.setup(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodGet, "/loans"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Fetch(c)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), tt.wantBody)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetLoanByConsumerID(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(mocks.LoanUsecase)
@@ -228,6 +531,38 @@ func TestDeleteLoan(t *testing.T) {
 		}
 	})
 
+	t.Run("loan has transactions", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/loans/1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("DeleteLoanByID", mock.Anything, int64(1)).Return(fmt.Errorf("%w: loan CN-1 cannot be deleted", usecase.ErrLoanHasTransactions)).Once()
+
+		err := handler.Delete(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.Contains(t, rec.Body.String(), "loan CN-1 cannot be deleted")
+		}
+	})
+
+	t.Run("loan still owed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/loans/1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("DeleteLoanByID", mock.Anything, int64(1)).Return(fmt.Errorf("%w: loan CN-1 is approved, only rejected or cancelled loans are deleted", usecase.ErrLoanNotDeletable)).Once()
+
+		err := handler.Delete(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.Contains(t, rec.Body.String(), "only rejected or cancelled loans are deleted")
+		}
+	})
+
 	t.Run("internal server error", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodDelete, "/loans/1", nil)
 		rec := httptest.NewRecorder()
@@ -244,3 +579,353 @@ func TestDeleteLoan(t *testing.T) {
 		}
 	})
 }
+
+func TestApproveLoan(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(mocks.LoanUsecase)
+	handler := &rest.LoanHandler{
+		LoanUC: mockUsecase,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/approve", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("ApproveLoan", mock.Anything, int64(1)).Return(nil).Once()
+
+		err := handler.Approve(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Loan approved successfully")
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/invalid/approve", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("invalid")
+
+		err := handler.Approve(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Invalid loan ID")
+		}
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/approve", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("ApproveLoan", mock.Anything, int64(1)).Return(errors.New("loan status cannot change from late to approved")).Once()
+
+		err := handler.Approve(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			assert.Contains(t, rec.Body.String(), "loan status cannot change")
+		}
+	})
+}
+
+func TestRejectLoan(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(mocks.LoanUsecase)
+	handler := &rest.LoanHandler{
+		LoanUC: mockUsecase,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/reject", strings.NewReader(`{"reason":"insufficient income"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("RejectLoan", mock.Anything, usecase.LoanStatusRequest{LoanID: 1, Reason: "insufficient income"}).Return(nil).Once()
+
+		err := handler.Reject(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Loan rejected successfully")
+		}
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/reject", strings.NewReader(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		err := handler.Reject(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "reason")
+		}
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/reject", strings.NewReader(`{"reason":"insufficient income"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("RejectLoan", mock.Anything, mock.Anything).Return(errors.New("some error")).Once()
+
+		err := handler.Reject(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			assert.Contains(t, rec.Body.String(), "some error")
+		}
+	})
+}
+
+func TestDisburseLoan(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(mocks.LoanUsecase)
+	handler := &rest.LoanHandler{
+		LoanUC: mockUsecase,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/disburse", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("DisburseLoan", mock.Anything, int64(1)).Return(nil).Once()
+
+		err := handler.Disburse(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Loan disbursed successfully")
+		}
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/disburse", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("DisburseLoan", mock.Anything, int64(1)).Return(errors.New("some error")).Once()
+
+		err := handler.Disburse(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			assert.Contains(t, rec.Body.String(), "some error")
+		}
+	})
+
+	t.Run("status changed meanwhile", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/disburse", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("DisburseLoan", mock.Anything, int64(1)).Return(repository.ErrLoanStatusConflict).Once()
+
+		err := handler.Disburse(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+		}
+	})
+}
+
+func TestCancelLoan(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(mocks.LoanUsecase)
+	handler := &rest.LoanHandler{
+		LoanUC: mockUsecase,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/cancel", strings.NewReader(`{"reason":"changed mind"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("CancelLoan", mock.Anything, usecase.LoanStatusRequest{LoanID: 1, Reason: "changed mind"}).Return(nil).Once()
+
+		err := handler.Cancel(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Loan cancelled successfully")
+		}
+	})
+
+	t.Run("not cancellable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/cancel", strings.NewReader(`{"reason":"changed mind"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("CancelLoan", mock.Anything, usecase.LoanStatusRequest{LoanID: 1, Reason: "changed mind"}).Return(fmt.Errorf("%w: payments were made on it", usecase.ErrLoanNotCancellable)).Once()
+
+		err := handler.Cancel(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.Contains(t, rec.Body.String(), "payments were made on it")
+		}
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/cancel", strings.NewReader(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		err := handler.Cancel(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestRestructureLoan(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(mocks.LoanUsecase)
+	handler := &rest.LoanHandler{
+		LoanUC: mockUsecase,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/restructure", strings.NewReader(`{"restructure_type":"extend_tenure","tenure":12,"reason":"lost job"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("RestructureLoan", mock.Anything, usecase.RestructureLoanRequest{
+			LoanID:          1,
+			RestructureType: "extend_tenure",
+			Tenure:          12,
+			Reason:          "lost job",
+		}).Return(usecase.LoanRestructureResponse{ID: 1, LoanID: 1, Tenure: 12}, nil).Once()
+
+		err := handler.Restructure(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"tenure":12`)
+		}
+	})
+
+	t.Run("invalid restructure type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/restructure", strings.NewReader(`{"restructure_type":"waive","reason":"lost job"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		err := handler.Restructure(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "restructure_type")
+		}
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/restructure", strings.NewReader(`{"restructure_type":"capitalize_arrears"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		err := handler.Restructure(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "reason")
+		}
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/restructure", strings.NewReader(`{"restructure_type":"capitalize_arrears","reason":"lost job"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("RestructureLoan", mock.Anything, mock.Anything).Return(usecase.LoanRestructureResponse{}, errors.New("some error")).Once()
+
+		err := handler.Restructure(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			assert.Contains(t, rec.Body.String(), "some error")
+		}
+	})
+}
+
+func TestGetLoanRestructures(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(mocks.LoanUsecase)
+	handler := &rest.LoanHandler{
+		LoanUC: mockUsecase,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/loans/1/restructures", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("GetLoanRestructures", mock.Anything, int64(1)).Return([]usecase.LoanRestructureResponse{{ID: 1, LoanID: 1}}, nil).Once()
+
+		err := handler.GetRestructures(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("invalid loan id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/loans/abc/restructures", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("abc")
+
+		err := handler.GetRestructures(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/loans/1/restructures", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockUsecase.On("GetLoanRestructures", mock.Anything, int64(1)).Return(nil, errors.New("some error")).Once()
+
+		err := handler.GetRestructures(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		}
+	})
+}
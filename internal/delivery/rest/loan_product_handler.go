@@ -0,0 +1,160 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/response"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+)
+
+type LoanProductHandler struct {
+	LoanProductUC usecase.LoanProductUsecase
+}
+
+// NewLoanProductHandler will initialize the loan product resources endpoint
+func NewLoanProductHandler(g *echo.Group, loanProductUC usecase.LoanProductUsecase) {
+	handler := &LoanProductHandler{
+		LoanProductUC: loanProductUC,
+	}
+
+	loanProductGroup := g.Group("/loan-products")
+
+	loanProductGroup.POST("", handler.Create)
+	loanProductGroup.GET("/:id", handler.GetByID)
+	loanProductGroup.GET("", handler.Fetch)
+	loanProductGroup.PUT("/:id", handler.Update)
+	loanProductGroup.DELETE("/:id", handler.Delete)
+}
+
+func validateLoanProductRequest(req *usecase.LoanProductRequest) error {
+	return validation.ValidateStruct(req,
+		validation.Field(&req.ProductCode, validation.Required, validation.Length(1, 50)),
+		validation.Field(&req.ProductName, validation.Required, validation.Length(1, 255)),
+		validation.Field(&req.InterestMethod, validation.In(
+			usecase.InterestMethodFlat,
+			usecase.InterestMethodFlatMonthly,
+			usecase.InterestMethodAnnuity,
+			usecase.InterestMethodDecliningBalance,
+		)),
+		validation.Field(&req.AdminFeeAmount, amountNotNegative),
+		validation.Field(&req.InsuranceRate, validation.Min(0.0)),
+		validation.Field(&req.MinLoanAmount, amountNotNegative),
+		validation.Field(&req.MaxLoanAmount, amountNotNegative),
+		validation.Field(&req.ValidFrom, validation.Required, validation.Date("2006-01-02")),
+		validation.Field(&req.ValidUntil, validation.Date("2006-01-02")),
+		validation.Field(&req.Tenures, validation.Required),
+	)
+}
+
+func (h *LoanProductHandler) Create(c echo.Context) error {
+	req := usecase.LoanProductRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductHandler][Create] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validateLoanProductRequest(&req); err != nil {
+		logger.Warning(fmt.Sprintf("[LoanProductHandler][Create] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.LoanProductUC.CreateLoanProduct(c.Request().Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidLoanProduct):
+			return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, repository.ErrDuplicateLoanProductCode):
+			return response.ErrorResponseWithMessage(c, http.StatusConflict, err.Error())
+		}
+
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusCreated, data)
+}
+
+func (h *LoanProductHandler) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductHandler][Update] while parse loan product ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid loan product ID")
+	}
+
+	req := usecase.LoanProductRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductHandler][Update] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validateLoanProductRequest(&req); err != nil {
+		logger.Warning(fmt.Sprintf("[LoanProductHandler][Update] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	err = h.LoanProductUC.UpdateLoanProduct(c.Request().Context(), id, req)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidLoanProduct) {
+			return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+		}
+
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithMessage(c, http.StatusOK, "Loan product updated successfully")
+}
+
+func (h *LoanProductHandler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductHandler][Delete] while parse loan product ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid loan product ID")
+	}
+
+	err = h.LoanProductUC.DeleteLoanProduct(c.Request().Context(), id)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithMessage(c, http.StatusOK, "Loan product deleted successfully")
+}
+
+func (h *LoanProductHandler) Fetch(c echo.Context) error {
+	req := usecase.FetchLoanProductsRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductHandler][Fetch] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.LoanProductUC.FetchLoanProducts(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
+
+func (h *LoanProductHandler) GetByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductHandler][GetByID] while parse loan product ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid loan product ID")
+	}
+
+	data, err := h.LoanProductUC.GetLoanProductByID(c.Request().Context(), id)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	if data.ID == 0 {
+		return response.ErrorResponseWithMessage(c, http.StatusNotFound, "Loan product not found")
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
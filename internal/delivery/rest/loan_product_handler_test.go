@@ -0,0 +1,276 @@
+package rest_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/rest"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const loanProductBody = `{"product_code":"MOTOR","product_name":"Motorcycle","interest_method":"annuity","admin_fee_amount":50000,"min_loan_amount":1000000,"max_loan_amount":30000000,"valid_from":"2024-01-01","tenures":[{"tenure":6,"interest_rate":12},{"tenure":12,"interest_rate":14.5}]}`
+
+func TestCreateLoanProduct(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name     string
+		body     string
+		setup    func(uc *mocks.LoanProductUsecase)
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "success",
+			body: loanProductBody,
+			setup: func(uc *mocks.LoanProductUsecase) {
+				uc.On("CreateLoanProduct", mock.Anything, mock.MatchedBy(func(req usecase.LoanProductRequest) bool {
+					return req.ProductCode == "MOTOR" && len(req.Tenures) == 2 && req.Tenures[1].InterestRate == 14.5
+				})).Return(usecase.LoanProductResponse{ID: 1, ProductCode: "MOTOR"}, nil).Once()
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `"product_code":"MOTOR"`,
+		},
+		{
+			name:     "bind error",
+			body:     `invalid body`,
+			setup:    func(uc *mocks.LoanProductUsecase) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "validation error",
+			body:     `{"product_code":"MOTOR","interest_method":"simple","valid_from":"01-01-2024"}`,
+			setup:    func(uc *mocks.LoanProductUsecase) {},
+			wantCode: http.StatusBadRequest,
+			wantBody: "interest_method: must be a valid value",
+		},
+		{
+			name:     "negative admin fee",
+			body:     strings.Replace(loanProductBody, `"admin_fee_amount":50000`, `"admin_fee_amount":-1`, 1),
+			setup:    func(uc *mocks.LoanProductUsecase) {},
+			wantCode: http.StatusBadRequest,
+			wantBody: "admin_fee_amount: must be no less than 0",
+		},
+		{
+			name: "invalid loan product",
+			body: loanProductBody,
+			setup: func(uc *mocks.LoanProductUsecase) {
+				uc.On("CreateLoanProduct", mock.Anything, mock.Anything).Return(usecase.LoanProductResponse{}, fmt.Errorf("%w: tenure 6 is offered twice", usecase.ErrInvalidLoanProduct)).Once()
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: "tenure 6 is offered twice",
+		},
+		{
+			name: "duplicate product code",
+			body: loanProductBody,
+			setup: func(uc *mocks.LoanProductUsecase) {
+				uc.On("CreateLoanProduct", mock.Anything, mock.Anything).Return(usecase.LoanProductResponse{}, repository.ErrDuplicateLoanProductCode).Once()
+			},
+			wantCode: http.StatusConflict,
+			wantBody: repository.ErrDuplicateLoanProductCode.Error(),
+		},
+		{
+			name: "internal server error",
+			body: loanProductBody,
+			setup: func(uc *mocks.LoanProductUsecase) {
+				uc.On("CreateLoanProduct", mock.Anything, mock.Anything).Return(usecase.LoanProductResponse{}, errors.New("some error")).Once()
+			},
+			wantCode: http.StatusInternalServerError,
+			wantBody: "some error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(mocks.LoanProductUsecase)
+			handler := &rest.LoanProductHandler{LoanProductUC: mockUsecase}
+			tt.setup(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodPost, "/loan-products", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.Create(c)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), tt.wantBody)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateLoanProduct(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name     string
+		id       string
+		setup    func(uc *mocks.LoanProductUsecase)
+		wantCode int
+	}{
+		{
+			name: "success",
+			id:   "1",
+			setup: func(uc *mocks.LoanProductUsecase) {
+				uc.On("UpdateLoanProduct", mock.Anything, int64(1), mock.AnythingOfType("usecase.LoanProductRequest")).Return(nil).Once()
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid id",
+			id:       "abc",
+			setup:    func(uc *mocks.LoanProductUsecase) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid loan product",
+			id:   "1",
+			setup: func(uc *mocks.LoanProductUsecase) {
+				uc.On("UpdateLoanProduct", mock.Anything, int64(1), mock.Anything).Return(usecase.ErrInvalidLoanProduct).Once()
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "internal server error",
+			id:   "1",
+			setup: func(uc *mocks.LoanProductUsecase) {
+				uc.On("UpdateLoanProduct", mock.Anything, int64(1), mock.Anything).Return(errors.New("some error")).Once()
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(mocks.LoanProductUsecase)
+			handler := &rest.LoanProductHandler{LoanProductUC: mockUsecase}
+			tt.setup(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodPut, "/loan-products/"+tt.id, strings.NewReader(loanProductBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			err := handler.Update(c)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetLoanProductByID(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name     string
+		id       string
+		setup    func(uc *mocks.LoanProductUsecase)
+		wantCode int
+	}{
+		{
+			name: "success",
+			id:   "1",
+			setup: func(uc *mocks.LoanProductUsecase) {
+				uc.On("GetLoanProductByID", mock.Anything, int64(1)).Return(usecase.LoanProductResponse{ID: 1, ProductCode: "MOTOR"}, nil).Once()
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid id",
+			id:       "abc",
+			setup:    func(uc *mocks.LoanProductUsecase) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			id:   "2",
+			setup: func(uc *mocks.LoanProductUsecase) {
+				uc.On("GetLoanProductByID", mock.Anything, int64(2)).Return(usecase.LoanProductResponse{}, nil).Once()
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "internal server error",
+			id:   "1",
+			setup: func(uc *mocks.LoanProductUsecase) {
+				uc.On("GetLoanProductByID", mock.Anything, int64(1)).Return(usecase.LoanProductResponse{}, errors.New("some error")).Once()
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(mocks.LoanProductUsecase)
+			handler := &rest.LoanProductHandler{LoanProductUC: mockUsecase}
+			tt.setup(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodGet, "/loan-products/"+tt.id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			err := handler.GetByID(c)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantCode, rec.Code)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFetchLoanProducts(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(mocks.LoanProductUsecase)
+	handler := &rest.LoanProductHandler{LoanProductUC: mockUsecase}
+
+	req := httptest.NewRequest(http.MethodGet, "/loan-products?page=2&limit=5", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	mockUsecase.On("FetchLoanProducts", mock.Anything, usecase.FetchLoanProductsRequest{Page: 2, Limit: 5}).Return([]usecase.LoanProductResponse{{ID: 1, ProductCode: "MOTOR"}}, nil).Once()
+
+	err := handler.Fetch(c)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"product_code":"MOTOR"`)
+	}
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestDeleteLoanProduct(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(mocks.LoanProductUsecase)
+	handler := &rest.LoanProductHandler{LoanProductUC: mockUsecase}
+
+	req := httptest.NewRequest(http.MethodDelete, "/loan-products/1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	mockUsecase.On("DeleteLoanProduct", mock.Anything, int64(1)).Return(nil).Once()
+
+	err := handler.Delete(c)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Loan product deleted successfully")
+	}
+	mockUsecase.AssertExpectations(t)
+}
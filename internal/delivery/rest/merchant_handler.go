@@ -29,6 +29,7 @@ func NewMerchantHandler(g *echo.Group, merchantUC usecase.MerchantUsecase) {
 	merchantGroup.GET("", handler.Fetch)
 	merchantGroup.PUT("/:id", handler.Update)
 	merchantGroup.DELETE("/:id", handler.Delete)
+	merchantGroup.GET("/:id/notifications", handler.GetNotifications)
 }
 
 func (h *MerchantHandler) Create(c echo.Context) error {
@@ -127,3 +128,25 @@ func (h *MerchantHandler) GetByID(c echo.Context) error {
 
 	return response.SuccessResponseWithData(c, http.StatusOK, data)
 }
+
+func (h *MerchantHandler) GetNotifications(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[MerchantHandler][GetNotifications] while parse merchant ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid merchant ID")
+	}
+
+	req := usecase.FetchMerchantNotificationsRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[MerchantHandler][GetNotifications] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	req.MerchantID = id
+
+	data, err := h.MerchantUC.GetMerchantNotifications(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
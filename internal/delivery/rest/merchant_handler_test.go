@@ -364,3 +364,58 @@ func TestGetByID(t *testing.T) {
 		}
 	})
 }
+
+func TestGetMerchantNotifications(t *testing.T) {
+	e := echo.New()
+	mockMerchantUC := new(mocks.MerchantUsecase)
+	handler := &rest.MerchantHandler{
+		MerchantUC: mockMerchantUC,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/merchants/1/notifications?page=2&limit=5", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockMerchantUC.On("GetMerchantNotifications", c.Request().Context(), usecase.FetchMerchantNotificationsRequest{MerchantID: 1, Page: 2, Limit: 5}).Return([]usecase.MerchantNotificationResponse{
+			{ID: 1, LoanID: 3, EventType: "loan_cancelled", Message: "Loan CN-3 for Phone was cancelled: changed mind"},
+		}, nil).Once()
+
+		err := handler.GetNotifications(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"event_type":"loan_cancelled"`)
+		}
+	})
+
+	t.Run("bad request - invalid merchant ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/merchants/invalid/notifications", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("invalid")
+
+		err := handler.GetNotifications(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Invalid merchant ID")
+		}
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/merchants/1/notifications", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockMerchantUC.On("GetMerchantNotifications", c.Request().Context(), usecase.FetchMerchantNotificationsRequest{MerchantID: 1}).Return(nil, errors.New("internal error")).Once()
+
+		err := handler.GetNotifications(c)
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/gateway"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/response"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+)
+
+type PaymentHandler struct {
+	PaymentUC usecase.PaymentUsecase
+}
+
+// NewPaymentHandler will initialize the payment resources endpoint. The simulate endpoint
+// books unauthenticated payments, it is only registered when allowSimulation is set.
+func NewPaymentHandler(g *echo.Group, paymentUC usecase.PaymentUsecase, allowSimulation bool) {
+	handler := &PaymentHandler{
+		PaymentUC: paymentUC,
+	}
+
+	paymentGroup := g.Group("/payments")
+
+	paymentGroup.POST("/virtual-accounts", handler.CreateVirtualAccount)
+	paymentGroup.POST("/callback", handler.Callback)
+	if allowSimulation {
+		paymentGroup.POST("/simulate", handler.Simulate)
+	}
+}
+
+func (h *PaymentHandler) CreateVirtualAccount(c echo.Context) error {
+	req := usecase.CreateVirtualAccountRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[PaymentHandler][CreateVirtualAccount] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.LoanID, validation.Required),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[PaymentHandler][CreateVirtualAccount] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.PaymentUC.CreateVirtualAccount(c.Request().Context(), req)
+	if errors.Is(err, gateway.ErrGatewayNotConfigured) {
+		return response.ErrorResponseWithMessage(c, http.StatusServiceUnavailable, err.Error())
+	}
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusCreated, data)
+}
+
+// Callback receives the payment notifications of the gateway. The body is read as sent since
+// the signature covers its exact bytes. Gateways retry a notification until it is answered
+// with 200, so only a callback that may succeed later is answered with a 409 or 500.
+func (h *PaymentHandler) Callback(c echo.Context) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[PaymentHandler][Callback] while read request body, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.PaymentUC.HandleCallback(c.Request().Context(), gateway.CallbackRequest{
+		Body:      body,
+		Signature: c.Request().Header.Get(gateway.HeaderCallbackSignature),
+		Timestamp: c.Request().Header.Get(gateway.HeaderCallbackTimestamp),
+	})
+	if err != nil {
+		return callbackErrorResponse(c, err)
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
+
+func (h *PaymentHandler) Simulate(c echo.Context) error {
+	req := usecase.SimulatePaymentRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[PaymentHandler][Simulate] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.VirtualAccountNumber, validation.Required),
+		validation.Field(&req.Amount, amountRequired),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[PaymentHandler][Simulate] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.PaymentUC.SimulatePayment(c.Request().Context(), req)
+	if err != nil {
+		return callbackErrorResponse(c, err)
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
+
+func callbackErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, gateway.ErrInvalidSignature), errors.Is(err, gateway.ErrCallbackExpired):
+		logger.Warning(fmt.Sprintf("[PaymentHandler] rejected callback, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, gateway.ErrInvalidCallback):
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, usecase.ErrPaymentCallbackInProgress):
+		return response.ErrorResponseWithMessage(c, http.StatusConflict, err.Error())
+	case errors.Is(err, gateway.ErrGatewayNotConfigured):
+		return response.ErrorResponseWithMessage(c, http.StatusServiceUnavailable, err.Error())
+	}
+
+	return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,191 @@
+package rest_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/rest"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/gateway"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateVirtualAccount(t *testing.T) {
+	e := echo.New()
+	mockPaymentUC := new(mocks.PaymentUsecase)
+	handler := &rest.PaymentHandler{
+		PaymentUC: mockPaymentUC,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/payments/virtual-accounts", bytes.NewBufferString(`{"loan_id": 3}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockPaymentUC.On("CreateVirtualAccount", mock.Anything, usecase.CreateVirtualAccountRequest{LoanID: 3}).Return(usecase.VirtualAccountResponse{
+			ID: 5, LoanID: 3, Provider: "fake", BankCode: "8808", AccountNumber: "8808000000000003",
+		}, nil).Once()
+
+		err := handler.CreateVirtualAccount(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"account_number":"8808000000000003"`)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/payments/virtual-accounts", bytes.NewBufferString(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.CreateVirtualAccount(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/payments/virtual-accounts", bytes.NewBufferString(`{"loan_id": 4}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockPaymentUC.On("CreateVirtualAccount", mock.Anything, usecase.CreateVirtualAccountRequest{LoanID: 4}).Return(usecase.VirtualAccountResponse{}, errors.New("loan not found")).Once()
+
+		err := handler.CreateVirtualAccount(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("gateway not configured", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/payments/virtual-accounts", bytes.NewBufferString(`{"loan_id": 5}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockPaymentUC.On("CreateVirtualAccount", mock.Anything, usecase.CreateVirtualAccountRequest{LoanID: 5}).Return(usecase.VirtualAccountResponse{}, gateway.ErrGatewayNotConfigured).Once()
+
+		err := handler.CreateVirtualAccount(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
+
+func TestPaymentCallback(t *testing.T) {
+	e := echo.New()
+	mockPaymentUC := new(mocks.PaymentUsecase)
+	handler := &rest.PaymentHandler{
+		PaymentUC: mockPaymentUC,
+	}
+
+	body := `{"external_id":"ext-1","virtual_account_number":"8808000000000003","amount":500}`
+	callback := gateway.CallbackRequest{Body: []byte(body), Signature: "signature", Timestamp: "1705314600"}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{name: "success", wantCode: http.StatusOK},
+		{name: "invalid signature", err: gateway.ErrInvalidSignature, wantCode: http.StatusUnauthorized},
+		{name: "expired", err: gateway.ErrCallbackExpired, wantCode: http.StatusUnauthorized},
+		{name: "invalid payload", err: fmt.Errorf("%w: virtual account not found", gateway.ErrInvalidCallback), wantCode: http.StatusBadRequest},
+		{name: "in progress", err: usecase.ErrPaymentCallbackInProgress, wantCode: http.StatusConflict},
+		{name: "gateway not configured", err: gateway.ErrGatewayNotConfigured, wantCode: http.StatusServiceUnavailable},
+		{name: "payment not booked", err: errors.New("loan not found"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/payments/callback", bytes.NewBufferString(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(gateway.HeaderCallbackSignature, "signature")
+			req.Header.Set(gateway.HeaderCallbackTimestamp, "1705314600")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			mockPaymentUC.On("HandleCallback", mock.Anything, callback).Return(usecase.PaymentCallbackResponse{ExternalID: "ext-1"}, tt.err).Once()
+
+			err := handler.Callback(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestSimulatePayment(t *testing.T) {
+	e := echo.New()
+	mockPaymentUC := new(mocks.PaymentUsecase)
+	handler := &rest.PaymentHandler{
+		PaymentUC: mockPaymentUC,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/payments/simulate", bytes.NewBufferString(`{"virtual_account_number": "8808000000000003", "amount": 500}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockPaymentUC.On("SimulatePayment", mock.Anything, usecase.SimulatePaymentRequest{VirtualAccountNumber: "8808000000000003", Amount: money.New(500)}).Return(usecase.PaymentCallbackResponse{
+			ExternalID:  "FAKE-1",
+			Transaction: usecase.GetTransactionResponse{ID: 9, Amount: money.New(500)},
+		}, nil).Once()
+
+		err := handler.Simulate(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"external_id":"FAKE-1"`)
+	})
+
+	t.Run("amount required", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/payments/simulate", bytes.NewBufferString(`{"virtual_account_number": "8808000000000003"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Simulate(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("not registered under a real provider", func(t *testing.T) {
+		e := echo.New()
+		mockPaymentUC := new(mocks.PaymentUsecase)
+		cfg := config.PaymentGatewayConfig{Provider: "xendit"}
+		rest.NewPaymentHandler(e.Group("/api/v1"), mockPaymentUC, gateway.SimulationAllowed(cfg, config.EnvDevelopment))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/simulate", bytes.NewBufferString(`{"virtual_account_number": "8808000000000003", "amount": 500}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.NotEqual(t, http.StatusOK, rec.Code)
+		mockPaymentUC.AssertNotCalled(t, "SimulatePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("registered for the fake provider in development", func(t *testing.T) {
+		e := echo.New()
+		mockPaymentUC := new(mocks.PaymentUsecase)
+		cfg := config.PaymentGatewayConfig{Provider: gateway.ProviderFake}
+		rest.NewPaymentHandler(e.Group("/api/v1"), mockPaymentUC, gateway.SimulationAllowed(cfg, config.EnvDevelopment))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/simulate", bytes.NewBufferString(`{"virtual_account_number": "8808000000000003", "amount": 500}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		mockPaymentUC.On("SimulatePayment", mock.Anything, usecase.SimulatePaymentRequest{VirtualAccountNumber: "8808000000000003", Amount: money.New(500)}).Return(usecase.PaymentCallbackResponse{ExternalID: "FAKE-2"}, nil).Once()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockPaymentUC.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/statement"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/response"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+)
+
+type ReconciliationHandler struct {
+	ReconciliationUC usecase.ReconciliationUsecase
+}
+
+// NewReconciliationHandler will initialize the reconciliation resources endpoint
+func NewReconciliationHandler(g *echo.Group, reconciliationUC usecase.ReconciliationUsecase) {
+	handler := &ReconciliationHandler{
+		ReconciliationUC: reconciliationUC,
+	}
+
+	reconciliationGroup := g.Group("/reconciliation")
+
+	reconciliationGroup.POST("/statements", handler.ImportStatement)
+	reconciliationGroup.GET("/lines", handler.FetchLines)
+	reconciliationGroup.POST("/lines/:id/match", handler.MatchLine)
+}
+
+// ImportStatement imports a statement uploaded as the file field of a multipart form, in
+// the format given by the format field.
+func (h *ReconciliationHandler) ImportStatement(c echo.Context) error {
+	req := usecase.ImportStatementRequest{
+		Format: c.FormValue("format"),
+	}
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.Format, validation.Required, validation.In(statement.FormatCSV, statement.FormatMT940)),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[ReconciliationHandler][ImportStatement] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		logger.Warning(fmt.Sprintf("[ReconciliationHandler][ImportStatement] while get statement file, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ReconciliationHandler][ImportStatement] while open statement file, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+	defer file.Close()
+
+	req.FileName = fileHeader.Filename
+	req.Content, err = io.ReadAll(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ReconciliationHandler][ImportStatement] while read statement file, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.ReconciliationUC.ImportStatement(c.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, statement.ErrInvalidStatement) {
+			return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+		}
+
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusCreated, data)
+}
+
+func (h *ReconciliationHandler) FetchLines(c echo.Context) error {
+	req := usecase.FetchStatementLinesRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[ReconciliationHandler][FetchLines] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.Status, validation.In(
+			repository.StatementLineStatusPending,
+			repository.StatementLineStatusMatched,
+			repository.StatementLineStatusUnmatched,
+			repository.StatementLineStatusAmbiguous,
+			repository.StatementLineStatusIgnored,
+		)),
+		validation.Field(&req.Page, validation.Min(0)),
+		validation.Field(&req.Limit, validation.Min(0), validation.Max(100)),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[ReconciliationHandler][FetchLines] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.ReconciliationUC.FetchStatementLines(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
+
+func (h *ReconciliationHandler) MatchLine(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ReconciliationHandler][MatchLine] while parse statement line ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid statement line ID")
+	}
+
+	req := usecase.MatchStatementLineRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[ReconciliationHandler][MatchLine] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	req.ID = id
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.LoanID, validation.Min(0)),
+		validation.Field(&req.TransactionID, validation.Min(0)),
+		validation.Field(&req.MatchedBy, validation.Required, validation.Length(1, 255)),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[ReconciliationHandler][MatchLine] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+	if (req.LoanID == 0) == (req.TransactionID == 0) {
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "either loan_id or transaction_id is required")
+	}
+
+	data, err := h.ReconciliationUC.MatchStatementLine(c.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, usecase.ErrStatementLineNotInReview) {
+			return response.ErrorResponseWithMessage(c, http.StatusConflict, err.Error())
+		}
+
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
@@ -0,0 +1,221 @@
+package rest_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/rest"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/statement"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newStatementRequest(t *testing.T, format string, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if format != "" {
+		assert.NoError(t, writer.WriteField("format", format))
+	}
+	if content != "" {
+		part, err := writer.CreateFormFile("file", "mutation.csv")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/reconciliation/statements", body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestImportStatement(t *testing.T) {
+	e := echo.New()
+	mockReconciliationUC := new(mocks.ReconciliationUsecase)
+	handler := &rest.ReconciliationHandler{
+		ReconciliationUC: mockReconciliationUC,
+	}
+
+	content := "date,description,amount\n2024-01-15,TRF,1000\n"
+
+	t.Run("success", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newStatementRequest(t, "csv", content), rec)
+
+		mockReconciliationUC.On("ImportStatement", mock.Anything, usecase.ImportStatementRequest{
+			FileName: "mutation.csv",
+			Format:   "csv",
+			Content:  []byte(content),
+		}).Return(usecase.ImportStatementResponse{ID: 4, TotalLines: 1, UnmatchedLines: 1}, nil).Once()
+
+		err := handler.ImportStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"unmatched_lines":1`)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newStatementRequest(t, "xlsx", content), rec)
+
+		err := handler.ImportStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "format")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newStatementRequest(t, "csv", ""), rec)
+
+		err := handler.ImportStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "file is required")
+	})
+
+	t.Run("invalid statement", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newStatementRequest(t, "mt940", "garbage"), rec)
+
+		mockReconciliationUC.On("ImportStatement", mock.Anything, mock.Anything).Return(usecase.ImportStatementResponse{}, statement.ErrInvalidStatement).Once()
+
+		err := handler.ImportStatement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	mockReconciliationUC.AssertExpectations(t)
+}
+
+func TestFetchStatementLines(t *testing.T) {
+	e := echo.New()
+	mockReconciliationUC := new(mocks.ReconciliationUsecase)
+	handler := &rest.ReconciliationHandler{
+		ReconciliationUC: mockReconciliationUC,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/reconciliation/lines?import_id=4&status=ambiguous&page=1&limit=20", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockReconciliationUC.On("FetchStatementLines", mock.Anything, usecase.FetchStatementLinesRequest{
+			ImportID: 4,
+			Status:   "ambiguous",
+			Page:     1,
+			Limit:    20,
+		}).Return([]usecase.StatementLineResponse{{ID: 9, Status: "ambiguous", CandidateLoanIDs: []int64{1, 4}}}, nil).Once()
+
+		err := handler.FetchLines(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"candidate_loan_ids":[1,4]`)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/reconciliation/lines?status=open", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.FetchLines(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "status")
+	})
+
+	mockReconciliationUC.AssertExpectations(t)
+}
+
+func TestMatchStatementLine(t *testing.T) {
+	e := echo.New()
+	mockReconciliationUC := new(mocks.ReconciliationUsecase)
+	handler := &rest.ReconciliationHandler{
+		ReconciliationUC: mockReconciliationUC,
+	}
+
+	newContext := func(id string, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/reconciliation/lines/"+id+"/match", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return c, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		c, rec := newContext("9", `{"loan_id":1,"matched_by":"finance"}`)
+
+		mockReconciliationUC.On("MatchStatementLine", mock.Anything, usecase.MatchStatementLineRequest{
+			ID:        9,
+			LoanID:    1,
+			MatchedBy: "finance",
+		}).Return(usecase.StatementLineResponse{ID: 9, Status: "matched", TransactionID: 11}, nil).Once()
+
+		err := handler.MatchLine(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"transaction_id":11`)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		c, rec := newContext("abc", `{"loan_id":1,"matched_by":"finance"}`)
+
+		err := handler.MatchLine(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("both loan and transaction", func(t *testing.T) {
+		c, rec := newContext("9", `{"loan_id":1,"transaction_id":2,"matched_by":"finance"}`)
+
+		err := handler.MatchLine(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "either loan_id or transaction_id is required")
+	})
+
+	t.Run("missing matched_by", func(t *testing.T) {
+		c, rec := newContext("9", `{"loan_id":1}`)
+
+		err := handler.MatchLine(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "matched_by")
+	})
+
+	t.Run("line not in review", func(t *testing.T) {
+		c, rec := newContext("10", `{"transaction_id":2,"matched_by":"finance"}`)
+
+		mockReconciliationUC.On("MatchStatementLine", mock.Anything, usecase.MatchStatementLineRequest{
+			ID:            10,
+			TransactionID: 2,
+			MatchedBy:     "finance",
+		}).Return(usecase.StatementLineResponse{}, usecase.ErrStatementLineNotInReview).Once()
+
+		err := handler.MatchLine(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		c, rec := newContext("11", `{"loan_id":1,"matched_by":"finance"}`)
+
+		mockReconciliationUC.On("MatchStatementLine", mock.Anything, mock.Anything).Return(usecase.StatementLineResponse{}, errors.New("db down")).Once()
+
+		err := handler.MatchLine(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	mockReconciliationUC.AssertExpectations(t)
+}
@@ -1,9 +1,13 @@
 package rest
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/response"
@@ -16,15 +20,20 @@ type TransactionHandler struct {
 }
 
 // NewTransactionHandler will initialize the transaction resources endpoint
-func NewTransactionHandler(g *echo.Group, transactionUC usecase.TransactionUsecase) {
+func NewTransactionHandler(g *echo.Group, transactionUC usecase.TransactionUsecase, idempotency echo.MiddlewareFunc) {
 	handler := &TransactionHandler{
 		TransactionUC: transactionUC,
 	}
 
 	transactionGroup := g.Group("/transactions")
 
-	transactionGroup.POST("", handler.Create)
+	transactionGroup.POST("", handler.Create, idempotency)
+	transactionGroup.GET("", handler.Fetch)
+	transactionGroup.GET("/:id", handler.GetByID)
+	transactionGroup.GET("/:id/receipt", handler.GetReceipt)
 	transactionGroup.GET("/remaining-payment", handler.GetRemainingPayment)
+	transactionGroup.POST("/settlement-quotes", handler.CreateSettlementQuote)
+	transactionGroup.POST("/:id/reverse", handler.Reverse)
 }
 
 func (h *TransactionHandler) Create(c echo.Context) error {
@@ -38,17 +47,124 @@ func (h *TransactionHandler) Create(c echo.Context) error {
 		validation.Field(&req.ConsumerID, validation.Required),
 		validation.Field(&req.LoanID, validation.Required),
 		validation.Field(&req.TramsactionType, validation.Required),
+		validation.Field(&req.Installment, validation.Min(0)),
+		validation.Field(&req.Amount, amountNotNegative),
+		validation.Field(&req.Channel, validation.In(usecase.TransactionChannelCounter, usecase.TransactionChannelBankTransfer, usecase.TransactionChannelVirtualAccount)),
+		validation.Field(&req.ExternalReference, validation.Length(0, 255)),
+		validation.Field(&req.ValueDate, validation.Date("2006-01-02")),
 	); err != nil {
 		logger.Warning(fmt.Sprintf("[TransactionHandler][Create] while validate request, Err: %+v", err))
 		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
 	}
 
-	_, err := h.TransactionUC.CreateTransaction(c.Request().Context(), req)
+	if req.ExternalReference != "" && req.Channel == "" {
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "channel is required for an external reference")
+	}
+
+	data, err := h.TransactionUC.CreateTransaction(c.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateExternalReference) {
+			return response.ErrorResponseWithMessage(c, http.StatusConflict, err.Error())
+		}
+
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusCreated, data)
+}
+
+func (h *TransactionHandler) Fetch(c echo.Context) error {
+	req := usecase.FetchTransactionsRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[TransactionHandler][Fetch] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.TransactionType, validation.In("installment", "full", "partial", "early_settlement", usecase.TransactionTypeReversal)),
+		validation.Field(&req.Channel, validation.In(usecase.TransactionChannelCounter, usecase.TransactionChannelBankTransfer, usecase.TransactionChannelVirtualAccount)),
+		validation.Field(&req.From, validation.Date("2006-01-02")),
+		validation.Field(&req.To, validation.Date("2006-01-02")),
+		validation.Field(&req.ValueFrom, validation.Date("2006-01-02")),
+		validation.Field(&req.ValueTo, validation.Date("2006-01-02")),
+		validation.Field(&req.SortBy, validation.In("created_at", "amount")),
+		validation.Field(&req.SortOrder, validation.In("asc", "desc")),
+		validation.Field(&req.Page, validation.Min(0)),
+		validation.Field(&req.Limit, validation.Min(0), validation.Max(100)),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[TransactionHandler][Fetch] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.TransactionUC.FetchTransactions(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
+
+func (h *TransactionHandler) GetByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[TransactionHandler][GetByID] while parse transaction ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid transaction ID")
+	}
+
+	data, err := h.TransactionUC.GetTransactionByID(c.Request().Context(), id)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusOK, data)
+}
+
+func (h *TransactionHandler) GetReceipt(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[TransactionHandler][GetReceipt] while parse transaction ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid transaction ID")
+	}
+
+	format := c.QueryParam("format")
+	if err := validation.Validate(format, validation.In("json", "html", "pdf")); err != nil {
+		logger.Warning(fmt.Sprintf("[TransactionHandler][GetReceipt] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "format: "+err.Error())
+	}
+
+	data, err := h.TransactionUC.GetTransactionReceipt(c.Request().Context(), id)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	if data.ReceiptNumber == "" {
+		return response.ErrorResponseWithMessage(c, http.StatusNotFound, "Receipt not found")
+	}
+
+	var (
+		body        bytes.Buffer
+		contentType string
+	)
+	switch format {
+	case "html":
+		contentType = echo.MIMETextHTMLCharsetUTF8
+		err = data.WriteHTML(&body)
+	case "pdf":
+		contentType = "application/pdf"
+		err = data.WritePDF(&body)
+	default:
+		return response.SuccessResponseWithData(c, http.StatusOK, data)
+	}
 	if err != nil {
+		logger.Error(fmt.Sprintf("[TransactionHandler][GetReceipt] while write %s receipt, Err: %+v", format, err))
 		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
 	}
 
-	return response.SuccessResponseWithMessage(c, http.StatusCreated, "Transaction created successfully")
+	if format == "pdf" {
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", data.ReceiptNumber+".pdf"))
+	}
+
+	return c.Blob(http.StatusOK, contentType, body.Bytes())
 }
 
 func (h *TransactionHandler) GetRemainingPayment(c echo.Context) error {
@@ -73,3 +189,56 @@ func (h *TransactionHandler) GetRemainingPayment(c echo.Context) error {
 
 	return response.SuccessResponseWithData(c, http.StatusOK, data)
 }
+
+func (h *TransactionHandler) CreateSettlementQuote(c echo.Context) error {
+	req := usecase.SettlementQuoteRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[TransactionHandler][CreateSettlementQuote] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.ConsumerID, validation.Required),
+		validation.Field(&req.LoanID, validation.Required),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[TransactionHandler][CreateSettlementQuote] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.TransactionUC.CreateSettlementQuote(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusCreated, data)
+}
+
+func (h *TransactionHandler) Reverse(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[TransactionHandler][Reverse] while parse transaction ID, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, "Invalid transaction ID")
+	}
+
+	req := usecase.ReverseTransactionRequest{}
+	if err := c.Bind(&req); err != nil {
+		logger.Error(fmt.Sprintf("[TransactionHandler][Reverse] while bind request, Err: %+v", err))
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	req.TransactionID = id
+
+	if err := validation.ValidateStruct(&req,
+		validation.Field(&req.ReversedBy, validation.Required, validation.Length(1, 255)),
+		validation.Field(&req.Reason, validation.Required, validation.Length(1, 255)),
+	); err != nil {
+		logger.Warning(fmt.Sprintf("[TransactionHandler][Reverse] while validate request, Err: %+v", err))
+		return response.ErrorResponseWithMessage(c, http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.TransactionUC.ReverseTransaction(c.Request().Context(), req)
+	if err != nil {
+		return response.ErrorResponseWithMessage(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return response.SuccessResponseWithData(c, http.StatusCreated, data)
+}
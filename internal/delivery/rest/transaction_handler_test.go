@@ -5,11 +5,14 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/delivery/rest"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -23,18 +26,29 @@ func TestCreateTransaction(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		reqBody := `{"consumer_id": 1, "loan_id": 1, "transaction_type": "payment"}`
+		reqBody := `{"consumer_id": 1, "loan_id": 1, "transaction_type": "partial", "amount": 60}`
 		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(reqBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		mockTransactionUC.On("CreateTransaction", mock.Anything, mock.Anything).Return(usecase.GetTransactionResponse{}, nil).Once()
+		mockTransactionUC.On("CreateTransaction", mock.Anything, mock.MatchedBy(func(req usecase.TransactionRequest) bool {
+			return req.Amount == money.New(60)
+		})).Return(usecase.GetTransactionResponse{
+			ID:     1,
+			Amount: money.New(60),
+			Allocations: []usecase.PaymentAllocation{
+				{InstallmentNumber: 2, Component: "interest", Amount: money.New(10)},
+				{InstallmentNumber: 2, Component: "principal", Amount: money.New(50)},
+			},
+			ReceiptNumber: "RCP-202401-000007",
+		}, nil).Once()
 
 		err := handler.Create(c)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusCreated, rec.Code)
-		assert.Contains(t, rec.Body.String(), "Transaction created successfully")
+		assert.Contains(t, rec.Body.String(), `{"installment_number":2,"component":"principal","amount":50}`)
+		assert.Contains(t, rec.Body.String(), `"receipt_number":"RCP-202401-000007"`)
 	})
 
 	t.Run("bind error", func(t *testing.T) {
@@ -60,6 +74,47 @@ func TestCreateTransaction(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
 	})
 
+	t.Run("negative amount", func(t *testing.T) {
+		reqBody := `{"consumer_id": 1, "loan_id": 1, "transaction_type": "partial", "amount": -60}`
+		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Create(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("external reference without channel", func(t *testing.T) {
+		reqBody := `{"consumer_id": 1, "loan_id": 1, "transaction_type": "installment", "external_reference": "TRF-001"}`
+		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Create(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "channel")
+	})
+
+	t.Run("duplicate external reference", func(t *testing.T) {
+		reqBody := `{"consumer_id": 1, "loan_id": 1, "transaction_type": "installment", "channel": "bank_transfer", "external_reference": "TRF-001", "value_date": "2024-01-15"}`
+		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockTransactionUC.On("CreateTransaction", mock.Anything, mock.MatchedBy(func(req usecase.TransactionRequest) bool {
+			return req.Channel == "bank_transfer" && req.ExternalReference == "TRF-001" && req.ValueDate == "2024-01-15"
+		})).Return(usecase.GetTransactionResponse{}, repository.ErrDuplicateExternalReference).Once()
+
+		err := handler.Create(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
 	t.Run("usecase error", func(t *testing.T) {
 		reqBody := `{"consumer_id": 1, "loan_id": 1, "transaction_type": "payment"}`
 		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(reqBody))
@@ -133,3 +188,364 @@ func TestGetRemainingPayment(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
 }
+
+func TestCreateSettlementQuote(t *testing.T) {
+	e := echo.New()
+	mockTransactionUC := new(mocks.TransactionUsecase)
+	handler := &rest.TransactionHandler{
+		TransactionUC: mockTransactionUC,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		reqBody := `{"consumer_id": 1, "loan_id": 1}`
+		req := httptest.NewRequest(http.MethodPost, "/transactions/settlement-quotes", bytes.NewBufferString(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockTransactionUC.On("CreateSettlementQuote", mock.Anything, usecase.SettlementQuoteRequest{ConsumerID: 1, LoanID: 1}).
+			Return(usecase.SettlementQuoteResponse{ID: 1, TotalAmount: money.New(505)}, nil).Once()
+
+		err := handler.CreateSettlementQuote(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"total_amount":505`)
+	})
+
+	t.Run("bind error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/transactions/settlement-quotes", bytes.NewBufferString("invalid body"))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.CreateSettlementQuote(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		reqBody := `{"consumer_id": 1}`
+		req := httptest.NewRequest(http.MethodPost, "/transactions/settlement-quotes", bytes.NewBufferString(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.CreateSettlementQuote(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		reqBody := `{"consumer_id": 1, "loan_id": 1}`
+		req := httptest.NewRequest(http.MethodPost, "/transactions/settlement-quotes", bytes.NewBufferString(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockTransactionUC.On("CreateSettlementQuote", mock.Anything, mock.Anything).Return(usecase.SettlementQuoteResponse{}, errors.New("usecase error")).Once()
+
+		err := handler.CreateSettlementQuote(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestReverseTransaction(t *testing.T) {
+	e := echo.New()
+	mockTransactionUC := new(mocks.TransactionUsecase)
+	handler := &rest.TransactionHandler{
+		TransactionUC: mockTransactionUC,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/transactions/5/reverse", strings.NewReader(`{"reversed_by":"operator","reason":"transfer bounced"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("5")
+
+		mockTransactionUC.On("ReverseTransaction", mock.Anything, usecase.ReverseTransactionRequest{
+			TransactionID: 5,
+			ReversedBy:    "operator",
+			Reason:        "transfer bounced",
+		}).Return(usecase.GetTransactionResponse{ID: 6, ReversalOfTransactionID: 5, Amount: money.New(-60)}, nil).Once()
+
+		err := handler.Reverse(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"reversal_of_transaction_id":5`)
+	})
+
+	t.Run("invalid transaction ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/transactions/abc/reverse", strings.NewReader(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("abc")
+
+		err := handler.Reverse(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/transactions/5/reverse", strings.NewReader(`{"reversed_by":"operator"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("5")
+
+		err := handler.Reverse(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "reason")
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/transactions/5/reverse", strings.NewReader(`{"reversed_by":"operator","reason":"transfer bounced"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("5")
+
+		mockTransactionUC.On("ReverseTransaction", mock.Anything, mock.Anything).Return(usecase.GetTransactionResponse{}, errors.New("transaction already reversed")).Once()
+
+		err := handler.Reverse(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), "transaction already reversed")
+	})
+}
+
+func TestFetchTransactions(t *testing.T) {
+	e := echo.New()
+	mockTransactionUC := new(mocks.TransactionUsecase)
+	handler := &rest.TransactionHandler{
+		TransactionUC: mockTransactionUC,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions?consumer_id=1&transaction_type=partial&channel=bank_transfer&from=2024-01-01&to=2024-01-31&sort_by=amount&sort_order=asc&page=2&limit=5", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockTransactionUC.On("FetchTransactions", mock.Anything, usecase.FetchTransactionsRequest{
+			ConsumerID:      1,
+			TransactionType: "partial",
+			Channel:         "bank_transfer",
+			From:            "2024-01-01",
+			To:              "2024-01-31",
+			SortBy:          "amount",
+			SortOrder:       "asc",
+			Page:            2,
+			Limit:           5,
+		}).Return([]usecase.GetTransactionResponse{{ID: 1, CreatedAt: "2024-01-15 10:30:00"}}, nil).Once()
+
+		err := handler.Fetch(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"created_at":"2024-01-15 10:30:00"`)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions?from=01-01-2024", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Fetch(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "from")
+	})
+
+	t.Run("invalid channel", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions?channel=cash", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Fetch(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "channel")
+	})
+
+	t.Run("invalid sort", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions?sort_by=description", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Fetch(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "sort_by")
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockTransactionUC.On("FetchTransactions", mock.Anything, usecase.FetchTransactionsRequest{}).Return(nil, errors.New("some error")).Once()
+
+		err := handler.Fetch(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestGetTransactionByID(t *testing.T) {
+	e := echo.New()
+	mockTransactionUC := new(mocks.TransactionUsecase)
+	handler := &rest.TransactionHandler{
+		TransactionUC: mockTransactionUC,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		mockTransactionUC.On("GetTransactionByID", mock.Anything, int64(1)).Return(usecase.GetTransactionResponse{
+			ID:          1,
+			Allocations: []usecase.PaymentAllocation{{InstallmentNumber: 1, Component: "principal", Amount: money.New(100)}},
+		}, nil).Once()
+
+		err := handler.GetByID(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `{"installment_number":1,"component":"principal","amount":100}`)
+	})
+
+	t.Run("invalid transaction ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/abc", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("abc")
+
+		err := handler.GetByID(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/2", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("2")
+
+		mockTransactionUC.On("GetTransactionByID", mock.Anything, int64(2)).Return(usecase.GetTransactionResponse{}, errors.New("transaction not found")).Once()
+
+		err := handler.GetByID(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), "transaction not found")
+	})
+}
+
+func TestGetTransactionReceipt(t *testing.T) {
+	e := echo.New()
+	mockTransactionUC := new(mocks.TransactionUsecase)
+	handler := &rest.TransactionHandler{
+		TransactionUC: mockTransactionUC,
+	}
+
+	receipt := usecase.ReceiptResponse{
+		ReceiptNumber:   "RCP-202401-000007",
+		TransactionID:   1,
+		ContractNumber:  "CN-1",
+		Amount:          money.New(110),
+		PrincipalAmount: money.New(100),
+		InterestAmount:  money.New(10),
+	}
+
+	newContext := func(target string, id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return c, rec
+	}
+
+	t.Run("json", func(t *testing.T) {
+		c, rec := newContext("/transactions/1/receipt", "1")
+
+		mockTransactionUC.On("GetTransactionReceipt", mock.Anything, int64(1)).Return(receipt, nil).Once()
+
+		err := handler.GetReceipt(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"receipt_number":"RCP-202401-000007"`)
+	})
+
+	t.Run("html", func(t *testing.T) {
+		c, rec := newContext("/transactions/1/receipt?format=html", "1")
+
+		mockTransactionUC.On("GetTransactionReceipt", mock.Anything, int64(1)).Return(receipt, nil).Once()
+
+		err := handler.GetReceipt(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, echo.MIMETextHTMLCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Body.String(), "<td>RCP-202401-000007</td>")
+	})
+
+	t.Run("pdf", func(t *testing.T) {
+		c, rec := newContext("/transactions/1/receipt?format=pdf", "1")
+
+		mockTransactionUC.On("GetTransactionReceipt", mock.Anything, int64(1)).Return(receipt, nil).Once()
+
+		err := handler.GetReceipt(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/pdf", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, `attachment; filename="RCP-202401-000007.pdf"`, rec.Header().Get(echo.HeaderContentDisposition))
+		assert.True(t, strings.HasPrefix(rec.Body.String(), "%PDF-1.4"))
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		c, rec := newContext("/transactions/1/receipt?format=docx", "1")
+
+		err := handler.GetReceipt(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid transaction ID", func(t *testing.T) {
+		c, rec := newContext("/transactions/abc/receipt", "abc")
+
+		err := handler.GetReceipt(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		c, rec := newContext("/transactions/2/receipt", "2")
+
+		mockTransactionUC.On("GetTransactionReceipt", mock.Anything, int64(2)).Return(usecase.ReceiptResponse{}, nil).Once()
+
+		err := handler.GetReceipt(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		c, rec := newContext("/transactions/1/receipt", "1")
+
+		mockTransactionUC.On("GetTransactionReceipt", mock.Anything, int64(1)).Return(usecase.ReceiptResponse{}, errors.New("db down")).Once()
+
+		err := handler.GetReceipt(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	mockTransactionUC.AssertExpectations(t)
+}
@@ -0,0 +1,30 @@
+package rest
+
+import (
+	"errors"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// money.Money is validated as its decimal string by the built-in rules, so amounts are
+// checked with these instead of validation.Required and validation.Min.
+var (
+	amountRequired = validation.By(func(value interface{}) error {
+		amount, _ := value.(money.Money)
+		if !amount.IsPositive() {
+			return errors.New("must be greater than 0")
+		}
+
+		return nil
+	})
+
+	amountNotNegative = validation.By(func(value interface{}) error {
+		amount, _ := value.(money.Money)
+		if amount.IsNegative() {
+			return errors.New("must be no less than 0")
+		}
+
+		return nil
+	})
+)
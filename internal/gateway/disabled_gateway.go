@@ -0,0 +1,27 @@
+package gateway
+
+import (
+	"context"
+	"time"
+)
+
+// DisabledGateway stands in when no payment gateway provider is configured, so the service
+// still starts. It issues no virtual accounts and accepts no callbacks, both answer
+// ErrGatewayNotConfigured.
+type DisabledGateway struct{}
+
+func NewDisabledGateway() *DisabledGateway {
+	return &DisabledGateway{}
+}
+
+func (g *DisabledGateway) Provider() string {
+	return ""
+}
+
+func (g *DisabledGateway) CreateVirtualAccount(ctx context.Context, req VirtualAccountRequest) (VirtualAccount, error) {
+	return VirtualAccount{}, ErrGatewayNotConfigured
+}
+
+func (g *DisabledGateway) ParseCallback(req CallbackRequest, now time.Time) (Callback, error) {
+	return Callback{}, ErrGatewayNotConfigured
+}
@@ -0,0 +1,126 @@
+package gateway_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/gateway"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPaymentGateway(t *testing.T) {
+	paymentGateway, err := gateway.NewPaymentGateway(config.PaymentGatewayConfig{Provider: "fake"})
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.ProviderFake, paymentGateway.Provider())
+
+	_, err = gateway.NewPaymentGateway(config.PaymentGatewayConfig{Provider: "unknown"})
+	assert.Error(t, err)
+
+	paymentGateway, err = gateway.NewPaymentGateway(config.PaymentGatewayConfig{})
+	assert.NoError(t, err)
+	assert.IsType(t, &gateway.DisabledGateway{}, paymentGateway)
+}
+
+func TestDisabledGateway(t *testing.T) {
+	disabled := gateway.NewDisabledGateway()
+
+	_, err := disabled.CreateVirtualAccount(context.Background(), gateway.VirtualAccountRequest{LoanID: 42})
+	assert.ErrorIs(t, err, gateway.ErrGatewayNotConfigured)
+
+	_, err = disabled.ParseCallback(gateway.CallbackRequest{Body: []byte(`{}`)}, time.Now())
+	assert.ErrorIs(t, err, gateway.ErrGatewayNotConfigured)
+}
+
+func TestSimulationAllowed(t *testing.T) {
+	assert.True(t, gateway.SimulationAllowed(config.PaymentGatewayConfig{Provider: "fake"}, config.EnvDevelopment))
+	assert.False(t, gateway.SimulationAllowed(config.PaymentGatewayConfig{Provider: "fake"}, config.EnvProduction))
+	assert.False(t, gateway.SimulationAllowed(config.PaymentGatewayConfig{Provider: "xendit"}, config.EnvDevelopment))
+	assert.False(t, gateway.SimulationAllowed(config.PaymentGatewayConfig{}, config.EnvDevelopment))
+}
+
+func TestFakeGatewayCreateVirtualAccount(t *testing.T) {
+	fake := gateway.NewFakeGateway(config.PaymentGatewayConfig{BankCode: "8808"})
+
+	account, err := fake.CreateVirtualAccount(context.Background(), gateway.VirtualAccountRequest{LoanID: 42, Name: "John Doe"})
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.VirtualAccount{BankCode: "8808", AccountNumber: "8808000000000042", AccountName: "John Doe"}, account)
+}
+
+func TestFakeGatewayParseCallback(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	fake := gateway.NewFakeGateway(config.PaymentGatewayConfig{CallbackSecret: "secret", CallbackTolerance: 5 * time.Minute})
+
+	simulated, err := fake.SimulatePayment("8808000000000042", money.New(150000), now)
+	assert.NoError(t, err)
+
+	sign := func(body string, sentAt time.Time) gateway.CallbackRequest {
+		timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+		return gateway.CallbackRequest{Body: []byte(body), Signature: gateway.SignCallback("secret", timestamp, []byte(body)), Timestamp: timestamp}
+	}
+
+	tests := []struct {
+		name    string
+		req     gateway.CallbackRequest
+		want    gateway.Callback
+		wantErr error
+	}{
+		{
+			name: "simulated payment",
+			req:  simulated,
+			want: gateway.Callback{VirtualAccountNumber: "8808000000000042", Amount: money.New(150000), PaidAt: now},
+		},
+		{
+			name:    "tampered body",
+			req:     gateway.CallbackRequest{Body: []byte(`{"external_id":"1","virtual_account_number":"8808000000000042","amount":999999}`), Signature: simulated.Signature, Timestamp: simulated.Timestamp},
+			wantErr: gateway.ErrInvalidSignature,
+		},
+		{
+			name:    "signature of another timestamp",
+			req:     gateway.CallbackRequest{Body: simulated.Body, Signature: simulated.Signature, Timestamp: strconv.FormatInt(now.Unix()+1, 10)},
+			wantErr: gateway.ErrInvalidSignature,
+		},
+		{
+			name:    "signature not hex",
+			req:     gateway.CallbackRequest{Body: simulated.Body, Signature: "not-hex", Timestamp: simulated.Timestamp},
+			wantErr: gateway.ErrInvalidSignature,
+		},
+		{
+			name:    "replayed after the tolerance",
+			req:     sign(string(simulated.Body), now.Add(-6*time.Minute)),
+			wantErr: gateway.ErrCallbackExpired,
+		},
+		{
+			name:    "malformed payload",
+			req:     sign(`{"external_id":`, now),
+			wantErr: gateway.ErrInvalidCallback,
+		},
+		{
+			name:    "missing amount",
+			req:     sign(`{"external_id":"1","virtual_account_number":"8808000000000042"}`, now),
+			wantErr: gateway.ErrInvalidCallback,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fake.ParseCallback(tt.req, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, got.ExternalID)
+			assert.Equal(t, tt.want.VirtualAccountNumber, got.VirtualAccountNumber)
+			assert.Equal(t, tt.want.Amount, got.Amount)
+			assert.True(t, tt.want.PaidAt.Equal(got.PaidAt))
+		})
+	}
+
+	_, err = gateway.NewFakeGateway(config.PaymentGatewayConfig{CallbackTolerance: time.Minute}).ParseCallback(simulated, now)
+	assert.EqualError(t, err, "callback secret is not configured")
+}
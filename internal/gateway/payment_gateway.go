@@ -0,0 +1,135 @@
+package gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+const (
+	HeaderCallbackSignature = "X-Callback-Signature"
+	HeaderCallbackTimestamp = "X-Callback-Timestamp"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid callback signature")
+	ErrCallbackExpired  = errors.New("callback timestamp is outside the allowed window")
+	ErrInvalidCallback  = errors.New("invalid callback payload")
+
+	ErrGatewayNotConfigured = errors.New("payment gateway is not configured")
+)
+
+type (
+	VirtualAccountRequest struct {
+		LoanID         int64
+		ConsumerID     int64
+		ContractNumber string
+		Name           string
+	}
+
+	// VirtualAccount is an account number issued by a gateway, transfers to it are paid into
+	// the loan it was issued for.
+	VirtualAccount struct {
+		BankCode      string
+		AccountNumber string
+		AccountName   string
+	}
+
+	// CallbackRequest is a payment notification as received from a gateway.
+	CallbackRequest struct {
+		Body      []byte
+		Signature string
+		Timestamp string
+	}
+
+	// Callback is a verified payment notification. ExternalID identifies the payment at the
+	// gateway and is the same for every retry of the notification.
+	Callback struct {
+		ExternalID           string      `json:"external_id"`
+		VirtualAccountNumber string      `json:"virtual_account_number"`
+		Amount               money.Money `json:"amount"`
+		PaidAt               time.Time   `json:"paid_at"`
+	}
+)
+
+// PaymentGateway is a payment channel issuing virtual accounts and notifying payments made
+// to them through signed callbacks.
+type PaymentGateway interface {
+	Provider() string
+	CreateVirtualAccount(ctx context.Context, req VirtualAccountRequest) (VirtualAccount, error)
+	ParseCallback(req CallbackRequest, now time.Time) (Callback, error)
+}
+
+// NewPaymentGateway returns the gateway of the configured provider, a disabled gateway when
+// there is none.
+func NewPaymentGateway(cfg config.PaymentGatewayConfig) (PaymentGateway, error) {
+	switch cfg.Provider {
+	case "":
+		return NewDisabledGateway(), nil
+	case ProviderFake:
+		return NewFakeGateway(cfg), nil
+	}
+
+	return nil, fmt.Errorf("unknown payment gateway provider %q", cfg.Provider)
+}
+
+// SignCallback signs a callback body sent at timestamp, a unix time in seconds. The
+// signature is the hex encoded HMAC-SHA256 of the timestamp, a dot and the body.
+func SignCallback(secret string, timestamp string, body []byte) string {
+	return hex.EncodeToString(callbackMAC(secret, timestamp, body))
+}
+
+func callbackMAC(secret string, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return mac.Sum(nil)
+}
+
+// VerifyCallback checks that a callback was signed with secret and sent no longer than
+// tolerance before or after now, so a captured callback cannot be replayed later on.
+func VerifyCallback(secret string, tolerance time.Duration, req CallbackRequest, now time.Time) error {
+	if secret == "" {
+		return errors.New("callback secret is not configured")
+	}
+
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil || !hmac.Equal(callbackMAC(secret, req.Timestamp, req.Body), signature) {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	sentAt := time.Unix(unix, 0)
+	if sentAt.Before(now.Add(-tolerance)) || sentAt.After(now.Add(tolerance)) {
+		return ErrCallbackExpired
+	}
+
+	return nil
+}
+
+// PaymentSimulator is implemented by gateways that can produce the callback of a payment
+// without a real transfer, for local development.
+type PaymentSimulator interface {
+	SimulatePayment(virtualAccountNumber string, amount money.Money, now time.Time) (CallbackRequest, error)
+}
+
+// SimulationAllowed tells whether payments may be simulated, only with the fake gateway
+// explicitly configured in a development environment. A simulated payment is signed with the
+// callback secret and booked as a real one.
+func SimulationAllowed(cfg config.PaymentGatewayConfig, env string) bool {
+	return cfg.Provider == ProviderFake && env == config.EnvDevelopment
+}
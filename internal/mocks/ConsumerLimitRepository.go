@@ -7,6 +7,8 @@ import (
 
 	repository "github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	mock "github.com/stretchr/testify/mock"
+
+	sql "database/sql"
 )
 
 // ConsumerLimitRepository is an autogenerated mock type for the ConsumerLimitRepository type
@@ -146,6 +148,34 @@ func (_m *ConsumerLimitRepository) GetLimitByTenureAndConsumerID(ctx context.Con
 	return r0, r1
 }
 
+// LockLimitByTenureAndConsumerID provides a mock function with given fields: ctx, tenure, consumerID, tx
+func (_m *ConsumerLimitRepository) LockLimitByTenureAndConsumerID(ctx context.Context, tenure int16, consumerID int64, tx *sql.Tx) (repository.ConsumerLimit, error) {
+	ret := _m.Called(ctx, tenure, consumerID, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockLimitByTenureAndConsumerID")
+	}
+
+	var r0 repository.ConsumerLimit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int16, int64, *sql.Tx) (repository.ConsumerLimit, error)); ok {
+		return rf(ctx, tenure, consumerID, tx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int16, int64, *sql.Tx) repository.ConsumerLimit); ok {
+		r0 = rf(ctx, tenure, consumerID, tx)
+	} else {
+		r0 = ret.Get(0).(repository.ConsumerLimit)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int16, int64, *sql.Tx) error); ok {
+		r1 = rf(ctx, tenure, consumerID, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateConsumerLimit provides a mock function with given fields: ctx, consumerLimit
 func (_m *ConsumerLimitRepository) UpdateConsumerLimit(ctx context.Context, consumerLimit repository.ConsumerLimit) error {
 	ret := _m.Called(ctx, consumerLimit)
@@ -7,6 +7,10 @@ import (
 
 	repository "github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	mock "github.com/stretchr/testify/mock"
+
+	money "github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+
+	sql "database/sql"
 )
 
 // ConsumerRepository is an autogenerated mock type for the ConsumerRepository type
@@ -14,6 +18,24 @@ type ConsumerRepository struct {
 	mock.Mock
 }
 
+// AddCreditBalance provides a mock function with given fields: ctx, consumerID, amount, tx
+func (_m *ConsumerRepository) AddCreditBalance(ctx context.Context, consumerID int64, amount money.Money, tx *sql.Tx) error {
+	ret := _m.Called(ctx, consumerID, amount, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddCreditBalance")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, money.Money, *sql.Tx) error); ok {
+		r0 = rf(ctx, consumerID, amount, tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateConsumer provides a mock function with given fields: ctx, consumer
 func (_m *ConsumerRepository) CreateConsumer(ctx context.Context, consumer repository.Consumer) (int64, error) {
 	ret := _m.Called(ctx, consumer)
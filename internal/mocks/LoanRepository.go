@@ -16,9 +16,57 @@ type LoanRepository struct {
 	mock.Mock
 }
 
-// CreateLoan provides a mock function with given fields: ctx, loan
-func (_m *LoanRepository) CreateLoan(ctx context.Context, loan repository.Loan) (int64, error) {
-	ret := _m.Called(ctx, loan)
+// BeginTx provides a mock function with given fields: ctx
+func (_m *LoanRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BeginTx")
+	}
+
+	var r0 *sql.Tx
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*sql.Tx, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *sql.Tx); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Tx)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CommitTx provides a mock function with given fields: ctx, tx
+func (_m *LoanRepository) CommitTx(ctx context.Context, tx *sql.Tx) error {
+	ret := _m.Called(ctx, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CommitTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx) error); ok {
+		r0 = rf(ctx, tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateLoan provides a mock function with given fields: ctx, loan, tx
+func (_m *LoanRepository) CreateLoan(ctx context.Context, loan repository.Loan, tx *sql.Tx) (int64, error) {
+	ret := _m.Called(ctx, loan, tx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateLoan")
@@ -26,17 +74,17 @@ func (_m *LoanRepository) CreateLoan(ctx context.Context, loan repository.Loan)
 
 	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, repository.Loan) (int64, error)); ok {
-		return rf(ctx, loan)
+	if rf, ok := ret.Get(0).(func(context.Context, repository.Loan, *sql.Tx) (int64, error)); ok {
+		return rf(ctx, loan, tx)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, repository.Loan) int64); ok {
-		r0 = rf(ctx, loan)
+	if rf, ok := ret.Get(0).(func(context.Context, repository.Loan, *sql.Tx) int64); ok {
+		r0 = rf(ctx, loan, tx)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, repository.Loan) error); ok {
-		r1 = rf(ctx, loan)
+	if rf, ok := ret.Get(1).(func(context.Context, repository.Loan, *sql.Tx) error); ok {
+		r1 = rf(ctx, loan, tx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -44,17 +92,35 @@ func (_m *LoanRepository) CreateLoan(ctx context.Context, loan repository.Loan)
 	return r0, r1
 }
 
-// DeleteLoan provides a mock function with given fields: ctx, loanID
-func (_m *LoanRepository) DeleteLoan(ctx context.Context, loanID int64) error {
-	ret := _m.Called(ctx, loanID)
+// DeleteLoan provides a mock function with given fields: ctx, loanID, tx
+func (_m *LoanRepository) DeleteLoan(ctx context.Context, loanID int64, tx *sql.Tx) error {
+	ret := _m.Called(ctx, loanID, tx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteLoan")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = rf(ctx, loanID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *sql.Tx) error); ok {
+		r0 = rf(ctx, loanID, tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DisburseLoan provides a mock function with given fields: ctx, req, tx
+func (_m *LoanRepository) DisburseLoan(ctx context.Context, req repository.DisburseLoanRequest, tx *sql.Tx) error {
+	ret := _m.Called(ctx, req, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisburseLoan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.DisburseLoanRequest, *sql.Tx) error); ok {
+		r0 = rf(ctx, req, tx)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -62,6 +128,73 @@ func (_m *LoanRepository) DeleteLoan(ctx context.Context, loanID int64) error {
 	return r0
 }
 
+// FetchLoans provides a mock function with given fields: ctx, req
+func (_m *LoanRepository) FetchLoans(ctx context.Context, req repository.FetchLoansRequest) ([]repository.Loan, int64, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchLoans")
+	}
+
+	var r0 []repository.Loan
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.FetchLoansRequest) ([]repository.Loan, int64, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.FetchLoansRequest) []repository.Loan); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.FetchLoansRequest) int64); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, repository.FetchLoansRequest) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetActiveLoans provides a mock function with given fields: ctx
+func (_m *LoanRepository) GetActiveLoans(ctx context.Context) ([]repository.Loan, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActiveLoans")
+	}
+
+	var r0 []repository.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]repository.Loan, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []repository.Loan); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLoanByConsumerID provides a mock function with given fields: ctx, consumerID
 func (_m *LoanRepository) GetLoanByConsumerID(ctx context.Context, consumerID int64) ([]repository.Loan, error) {
 	ret := _m.Called(ctx, consumerID)
@@ -120,6 +253,126 @@ func (_m *LoanRepository) GetLoanByID(ctx context.Context, loanID int64) (reposi
 	return r0, r1
 }
 
+// GetLoanIDByContractNumber provides a mock function with given fields: ctx, contractNumber
+func (_m *LoanRepository) GetLoanIDByContractNumber(ctx context.Context, contractNumber string) (int64, error) {
+	ret := _m.Called(ctx, contractNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoanIDByContractNumber")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, contractNumber)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, contractNumber)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, contractNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HasLoanTransactions provides a mock function with given fields: ctx, loanID, tx
+func (_m *LoanRepository) HasLoanTransactions(ctx context.Context, loanID int64, tx *sql.Tx) (bool, error) {
+	ret := _m.Called(ctx, loanID, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HasLoanTransactions")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *sql.Tx) (bool, error)); ok {
+		return rf(ctx, loanID, tx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *sql.Tx) bool); ok {
+		r0 = rf(ctx, loanID, tx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *sql.Tx) error); ok {
+		r1 = rf(ctx, loanID, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockLoanByID provides a mock function with given fields: ctx, loanID, tx
+func (_m *LoanRepository) LockLoanByID(ctx context.Context, loanID int64, tx *sql.Tx) (bool, error) {
+	ret := _m.Called(ctx, loanID, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockLoanByID")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *sql.Tx) (bool, error)); ok {
+		return rf(ctx, loanID, tx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *sql.Tx) bool); ok {
+		r0 = rf(ctx, loanID, tx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *sql.Tx) error); ok {
+		r1 = rf(ctx, loanID, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestructureLoan provides a mock function with given fields: ctx, req, tx
+func (_m *LoanRepository) RestructureLoan(ctx context.Context, req repository.RestructureLoanRequest, tx *sql.Tx) error {
+	ret := _m.Called(ctx, req, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestructureLoan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.RestructureLoanRequest, *sql.Tx) error); ok {
+		r0 = rf(ctx, req, tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RollbackTx provides a mock function with given fields: ctx, tx
+func (_m *LoanRepository) RollbackTx(ctx context.Context, tx *sql.Tx) error {
+	ret := _m.Called(ctx, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RollbackTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx) error); ok {
+		r0 = rf(ctx, tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateLoan provides a mock function with given fields: ctx, req, tx
 func (_m *LoanRepository) UpdateLoan(ctx context.Context, req repository.UpdateLoanRequest, tx *sql.Tx) error {
 	ret := _m.Called(ctx, req, tx)
@@ -138,6 +391,42 @@ func (_m *LoanRepository) UpdateLoan(ctx context.Context, req repository.UpdateL
 	return r0
 }
 
+// UpdateLoanDaysPastDue provides a mock function with given fields: ctx, req, tx
+func (_m *LoanRepository) UpdateLoanDaysPastDue(ctx context.Context, req repository.UpdateLoanDaysPastDueRequest, tx *sql.Tx) error {
+	ret := _m.Called(ctx, req, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateLoanDaysPastDue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.UpdateLoanDaysPastDueRequest, *sql.Tx) error); ok {
+		r0 = rf(ctx, req, tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateLoanStatus provides a mock function with given fields: ctx, req, tx
+func (_m *LoanRepository) UpdateLoanStatus(ctx context.Context, req repository.UpdateLoanStatusRequest, tx *sql.Tx) error {
+	ret := _m.Called(ctx, req, tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateLoanStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.UpdateLoanStatusRequest, *sql.Tx) error); ok {
+		r0 = rf(ctx, req, tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewLoanRepository creates a new instance of LoanRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewLoanRepository(t interface {
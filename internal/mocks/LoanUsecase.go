@@ -14,6 +14,42 @@ type LoanUsecase struct {
 	mock.Mock
 }
 
+// ApproveLoan provides a mock function with given fields: ctx, loanID
+func (_m *LoanUsecase) ApproveLoan(ctx context.Context, loanID int64) error {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApproveLoan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CancelLoan provides a mock function with given fields: ctx, req
+func (_m *LoanUsecase) CancelLoan(ctx context.Context, req usecase.LoanStatusRequest) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelLoan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.LoanStatusRequest) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateLoan provides a mock function with given fields: ctx, req
 func (_m *LoanUsecase) CreateLoan(ctx context.Context, req usecase.CreateLoanRequest) (usecase.LoanResponse, error) {
 	ret := _m.Called(ctx, req)
@@ -60,6 +96,52 @@ func (_m *LoanUsecase) DeleteLoanByID(ctx context.Context, loanID int64) error {
 	return r0
 }
 
+// DisburseLoan provides a mock function with given fields: ctx, loanID
+func (_m *LoanUsecase) DisburseLoan(ctx context.Context, loanID int64) error {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisburseLoan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FetchLoans provides a mock function with given fields: ctx, req
+func (_m *LoanUsecase) FetchLoans(ctx context.Context, req usecase.FetchLoansRequest) (usecase.LoanListResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchLoans")
+	}
+
+	var r0 usecase.LoanListResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.FetchLoansRequest) (usecase.LoanListResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.FetchLoansRequest) usecase.LoanListResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(usecase.LoanListResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, usecase.FetchLoansRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLoanByConsumerID provides a mock function with given fields: ctx, consumerID
 func (_m *LoanUsecase) GetLoanByConsumerID(ctx context.Context, consumerID int64) ([]usecase.LoanResponse, error) {
 	ret := _m.Called(ctx, consumerID)
@@ -118,6 +200,138 @@ func (_m *LoanUsecase) GetLoanByID(ctx context.Context, loanID int64) (usecase.L
 	return r0, r1
 }
 
+// GetLoanRestructures provides a mock function with given fields: ctx, loanID
+func (_m *LoanUsecase) GetLoanRestructures(ctx context.Context, loanID int64) ([]usecase.LoanRestructureResponse, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoanRestructures")
+	}
+
+	var r0 []usecase.LoanRestructureResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]usecase.LoanRestructureResponse, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []usecase.LoanRestructureResponse); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]usecase.LoanRestructureResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoanSchedule provides a mock function with given fields: ctx, loanID
+func (_m *LoanUsecase) GetLoanSchedule(ctx context.Context, loanID int64) (usecase.LoanScheduleResponse, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoanSchedule")
+	}
+
+	var r0 usecase.LoanScheduleResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (usecase.LoanScheduleResponse, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) usecase.LoanScheduleResponse); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		r0 = ret.Get(0).(usecase.LoanScheduleResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RejectLoan provides a mock function with given fields: ctx, req
+func (_m *LoanUsecase) RejectLoan(ctx context.Context, req usecase.LoanStatusRequest) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RejectLoan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.LoanStatusRequest) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestructureLoan provides a mock function with given fields: ctx, req
+func (_m *LoanUsecase) RestructureLoan(ctx context.Context, req usecase.RestructureLoanRequest) (usecase.LoanRestructureResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestructureLoan")
+	}
+
+	var r0 usecase.LoanRestructureResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.RestructureLoanRequest) (usecase.LoanRestructureResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.RestructureLoanRequest) usecase.LoanRestructureResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(usecase.LoanRestructureResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, usecase.RestructureLoanRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SimulateLoan provides a mock function with given fields: ctx, req
+func (_m *LoanUsecase) SimulateLoan(ctx context.Context, req usecase.SimulateLoanRequest) (usecase.LoanSimulationResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SimulateLoan")
+	}
+
+	var r0 usecase.LoanSimulationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.SimulateLoanRequest) (usecase.LoanSimulationResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.SimulateLoanRequest) usecase.LoanSimulationResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(usecase.LoanSimulationResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, usecase.SimulateLoanRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewLoanUsecase creates a new instance of LoanUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewLoanUsecase(t interface {
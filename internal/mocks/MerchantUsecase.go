@@ -108,6 +108,36 @@ func (_m *MerchantUsecase) GetMerchantByID(ctx context.Context, id int64) (useca
 	return r0, r1
 }
 
+// GetMerchantNotifications provides a mock function with given fields: ctx, req
+func (_m *MerchantUsecase) GetMerchantNotifications(ctx context.Context, req usecase.FetchMerchantNotificationsRequest) ([]usecase.MerchantNotificationResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMerchantNotifications")
+	}
+
+	var r0 []usecase.MerchantNotificationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.FetchMerchantNotificationsRequest) ([]usecase.MerchantNotificationResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.FetchMerchantNotificationsRequest) []usecase.MerchantNotificationResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]usecase.MerchantNotificationResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, usecase.FetchMerchantNotificationsRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateMerchant provides a mock function with given fields: ctx, id, request
 func (_m *MerchantUsecase) UpdateMerchant(ctx context.Context, id int64, request usecase.MerchantRequest) error {
 	ret := _m.Called(ctx, id, request)
@@ -92,6 +92,114 @@ func (_m *TransactionRepository) CreateTransaction(ctx context.Context, tx *sql.
 	return r0, r1
 }
 
+// CreateTransactionAllocations provides a mock function with given fields: ctx, tx, allocations
+func (_m *TransactionRepository) CreateTransactionAllocations(ctx context.Context, tx *sql.Tx, allocations []repository.TransactionAllocation) error {
+	ret := _m.Called(ctx, tx, allocations)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTransactionAllocations")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, []repository.TransactionAllocation) error); ok {
+		r0 = rf(ctx, tx, allocations)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FetchTransactions provides a mock function with given fields: ctx, req
+func (_m *TransactionRepository) FetchTransactions(ctx context.Context, req repository.FetchTransactionsRequest) ([]repository.Transaction, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchTransactions")
+	}
+
+	var r0 []repository.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.FetchTransactionsRequest) ([]repository.Transaction, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.FetchTransactionsRequest) []repository.Transaction); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.FetchTransactionsRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransactionAllocationsByTransactionID provides a mock function with given fields: ctx, transactionID
+func (_m *TransactionRepository) GetTransactionAllocationsByTransactionID(ctx context.Context, transactionID int64) ([]repository.TransactionAllocation, error) {
+	ret := _m.Called(ctx, transactionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransactionAllocationsByTransactionID")
+	}
+
+	var r0 []repository.TransactionAllocation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]repository.TransactionAllocation, error)); ok {
+		return rf(ctx, transactionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []repository.TransactionAllocation); ok {
+		r0 = rf(ctx, transactionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.TransactionAllocation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, transactionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransactionAllocationsByTransactionIDs provides a mock function with given fields: ctx, transactionIDs
+func (_m *TransactionRepository) GetTransactionAllocationsByTransactionIDs(ctx context.Context, transactionIDs []int64) ([]repository.TransactionAllocation, error) {
+	ret := _m.Called(ctx, transactionIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransactionAllocationsByTransactionIDs")
+	}
+
+	var r0 []repository.TransactionAllocation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]repository.TransactionAllocation, error)); ok {
+		return rf(ctx, transactionIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []repository.TransactionAllocation); ok {
+		r0 = rf(ctx, transactionIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.TransactionAllocation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, transactionIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTransactionByID provides a mock function with given fields: ctx, id
 func (_m *TransactionRepository) GetTransactionByID(ctx context.Context, id int64) (repository.Transaction, error) {
 	ret := _m.Called(ctx, id)
@@ -180,6 +288,34 @@ func (_m *TransactionRepository) GetTransactionsByLoanID(ctx context.Context, lo
 	return r0, r1
 }
 
+// MarkTransactionReversed provides a mock function with given fields: ctx, tx, id
+func (_m *TransactionRepository) MarkTransactionReversed(ctx context.Context, tx *sql.Tx, id int64) (bool, error) {
+	ret := _m.Called(ctx, tx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkTransactionReversed")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, int64) (bool, error)); ok {
+		return rf(ctx, tx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, int64) bool); ok {
+		r0 = rf(ctx, tx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, int64) error); ok {
+		r1 = rf(ctx, tx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RollbackTx provides a mock function with given fields: ctx, tx
 func (_m *TransactionRepository) RollbackTx(ctx context.Context, tx *sql.Tx) error {
 	ret := _m.Called(ctx, tx)
@@ -14,6 +14,34 @@ type TransactionUsecase struct {
 	mock.Mock
 }
 
+// CreateSettlementQuote provides a mock function with given fields: ctx, req
+func (_m *TransactionUsecase) CreateSettlementQuote(ctx context.Context, req usecase.SettlementQuoteRequest) (usecase.SettlementQuoteResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSettlementQuote")
+	}
+
+	var r0 usecase.SettlementQuoteResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.SettlementQuoteRequest) (usecase.SettlementQuoteResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.SettlementQuoteRequest) usecase.SettlementQuoteResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(usecase.SettlementQuoteResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, usecase.SettlementQuoteRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateTransaction provides a mock function with given fields: ctx, req
 func (_m *TransactionUsecase) CreateTransaction(ctx context.Context, req usecase.TransactionRequest) (usecase.GetTransactionResponse, error) {
 	ret := _m.Called(ctx, req)
@@ -42,6 +70,36 @@ func (_m *TransactionUsecase) CreateTransaction(ctx context.Context, req usecase
 	return r0, r1
 }
 
+// FetchTransactions provides a mock function with given fields: ctx, req
+func (_m *TransactionUsecase) FetchTransactions(ctx context.Context, req usecase.FetchTransactionsRequest) ([]usecase.GetTransactionResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchTransactions")
+	}
+
+	var r0 []usecase.GetTransactionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.FetchTransactionsRequest) ([]usecase.GetTransactionResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.FetchTransactionsRequest) []usecase.GetTransactionResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]usecase.GetTransactionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, usecase.FetchTransactionsRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRemainingPayment provides a mock function with given fields: ctx, req
 func (_m *TransactionUsecase) GetRemainingPayment(ctx context.Context, req usecase.TransactionRequest) (usecase.RemainingPaymentResponse, error) {
 	ret := _m.Called(ctx, req)
@@ -70,6 +128,90 @@ func (_m *TransactionUsecase) GetRemainingPayment(ctx context.Context, req useca
 	return r0, r1
 }
 
+// GetTransactionByID provides a mock function with given fields: ctx, id
+func (_m *TransactionUsecase) GetTransactionByID(ctx context.Context, id int64) (usecase.GetTransactionResponse, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransactionByID")
+	}
+
+	var r0 usecase.GetTransactionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (usecase.GetTransactionResponse, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) usecase.GetTransactionResponse); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(usecase.GetTransactionResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransactionReceipt provides a mock function with given fields: ctx, id
+func (_m *TransactionUsecase) GetTransactionReceipt(ctx context.Context, id int64) (usecase.ReceiptResponse, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransactionReceipt")
+	}
+
+	var r0 usecase.ReceiptResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (usecase.ReceiptResponse, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) usecase.ReceiptResponse); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(usecase.ReceiptResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReverseTransaction provides a mock function with given fields: ctx, req
+func (_m *TransactionUsecase) ReverseTransaction(ctx context.Context, req usecase.ReverseTransactionRequest) (usecase.GetTransactionResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReverseTransaction")
+	}
+
+	var r0 usecase.GetTransactionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.ReverseTransactionRequest) (usecase.GetTransactionResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, usecase.ReverseTransactionRequest) usecase.GetTransactionResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(usecase.GetTransactionResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, usecase.ReverseTransactionRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewTransactionUsecase creates a new instance of TransactionUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewTransactionUsecase(t interface {
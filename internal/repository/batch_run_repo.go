@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/go-sql-driver/mysql"
+)
+
+type BatchRunRepository interface {
+	CreateBatchRun(ctx context.Context, run BatchRun) (int64, error)
+	FinishBatchRun(ctx context.Context, run BatchRun) error
+	CreateBatchRunItem(ctx context.Context, item BatchRunItem, tx *sql.Tx) error
+}
+
+type batchRunRepository struct {
+	db *sql.DB
+}
+
+func NewBatchRunRepository(db *sql.DB) BatchRunRepository {
+	return &batchRunRepository{db: db}
+}
+
+type (
+	BatchRun struct {
+		ID           int64
+		JobName      string
+		RunDate      time.Time
+		BatchStatus  string
+		TotalLoans   int32
+		UpdatedLoans int32
+		FailedLoans  int32
+		ErrorMessage string
+	}
+
+	// BatchRunItem logs a loan the batch updated, or failed to update with ErrorMessage, in
+	// which case the loan status and days past due are left as they were.
+	BatchRunItem struct {
+		ID                  int64
+		BatchRunID          int64
+		LoanID              int64
+		ItemStatus          string
+		PreviousLoanStatus  string
+		LoanStatus          string
+		PreviousDaysPastDue int32
+		DaysPastDue         int32
+		ErrorMessage        string
+	}
+)
+
+const (
+	BatchRunItemUpdated = "updated"
+	BatchRunItemFailed  = "failed"
+)
+
+// ErrBatchRunAlreadyClaimed is returned when a batch run is created for a job and run date
+// another run was created for already.
+var ErrBatchRunAlreadyClaimed = errors.New("batch run already claimed for the run date")
+
+var (
+	ValidBatchStatus = map[string]bool{
+		"running": true,
+		"success": true,
+		"failed":  true,
+	}
+)
+
+// CreateBatchRun claims the run of a job for its run date, a job runs once a day however many
+// instances try to run it.
+func (r *batchRunRepository) CreateBatchRun(ctx context.Context, run BatchRun) (id int64, err error) {
+	query := `
+		INSERT INTO batch_runs (
+			job_name,
+			run_date,
+			batch_status,
+			started_at,
+			created_at
+		) VALUES (?, ?, ?, NOW(), NOW())
+	`
+
+	result, err := r.db.ExecContext(ctx, query, run.JobName, run.RunDate, run.BatchStatus)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return id, ErrBatchRunAlreadyClaimed
+		}
+
+		logger.Error(fmt.Sprintf("[batchRunRepository][CreateBatchRun] while exec query. Err: %v", err))
+		return id, err
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[batchRunRepository][CreateBatchRun] while get last insert id. Err: %v", err))
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *batchRunRepository) FinishBatchRun(ctx context.Context, run BatchRun) (err error) {
+	query := `
+		UPDATE batch_runs
+		SET
+			batch_status = ?,
+			total_loans = ?,
+			updated_loans = ?,
+			failed_loans = ?,
+			error_message = ?,
+			finished_at = NOW(),
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND batch_run_id = ?
+	`
+
+	var errorMessage *string
+	if run.ErrorMessage != "" {
+		errorMessage = &run.ErrorMessage
+	}
+
+	_, err = r.db.ExecContext(ctx, query, run.BatchStatus, run.TotalLoans, run.UpdatedLoans, run.FailedLoans, errorMessage, run.ID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[batchRunRepository][FinishBatchRun] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *batchRunRepository) CreateBatchRunItem(ctx context.Context, item BatchRunItem, tx *sql.Tx) (err error) {
+	query := `
+		INSERT INTO batch_run_items (
+			batch_run_id,
+			loan_id,
+			item_status,
+			previous_loan_status,
+			loan_status,
+			previous_days_past_due,
+			days_past_due,
+			error_message,
+			created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW())
+	`
+
+	var errorMessage *string
+	if item.ErrorMessage != "" {
+		errorMessage = &item.ErrorMessage
+	}
+
+	args := []interface{}{
+		item.BatchRunID,
+		item.LoanID,
+		item.ItemStatus,
+		item.PreviousLoanStatus,
+		item.LoanStatus,
+		item.PreviousDaysPastDue,
+		item.DaysPastDue,
+		errorMessage,
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[batchRunRepository][CreateBatchRunItem] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateBatchRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewBatchRunRepository(db)
+	now := time.Now()
+	run := repository.BatchRun{JobName: "end_of_day", RunDate: now, BatchStatus: "running"}
+
+	tests := []struct {
+		name      string
+		want      int64
+		wantErr   bool
+		wantErrIs error
+		mock      func()
+	}{
+		{
+			name:    "success",
+			want:    1,
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO batch_runs").
+					WithArgs("end_of_day", now, "running").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "exec error",
+			want:    0,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO batch_runs").
+					WithArgs("end_of_day", now, "running").
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+		{
+			name:      "already claimed",
+			want:      0,
+			wantErr:   true,
+			wantErrIs: repository.ErrBatchRunAlreadyClaimed,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO batch_runs").
+					WithArgs("end_of_day", now, "running").
+					WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateBatchRun(context.Background(), run)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFinishBatchRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewBatchRunRepository(db)
+
+	tests := []struct {
+		name    string
+		run     repository.BatchRun
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "success",
+			run:     repository.BatchRun{ID: 1, BatchStatus: "success", TotalLoans: 10, UpdatedLoans: 2},
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("UPDATE batch_runs").
+					WithArgs("success", 10, 2, 0, nil, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "failed run keeps error message",
+			run:     repository.BatchRun{ID: 2, BatchStatus: "failed", TotalLoans: 3, ErrorMessage: "timeout"},
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("UPDATE batch_runs").
+					WithArgs("failed", 3, 0, 0, "timeout", 2).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "run with failed loans",
+			run:     repository.BatchRun{ID: 4, BatchStatus: "success", TotalLoans: 5, UpdatedLoans: 2, FailedLoans: 1},
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("UPDATE batch_runs").
+					WithArgs("success", 5, 2, 1, nil, 4).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "exec error",
+			run:     repository.BatchRun{ID: 3, BatchStatus: "success"},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("UPDATE batch_runs").
+					WithArgs("success", 0, 0, 0, nil, 3).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.FinishBatchRun(context.Background(), tt.run)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCreateBatchRunItem(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewBatchRunRepository(db)
+	item := repository.BatchRunItem{
+		BatchRunID:          1,
+		LoanID:              1,
+		ItemStatus:          repository.BatchRunItemUpdated,
+		PreviousLoanStatus:  "on_going",
+		LoanStatus:          "late",
+		PreviousDaysPastDue: 0,
+		DaysPastDue:         3,
+	}
+
+	tests := []struct {
+		name    string
+		item    repository.BatchRunItem
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "success",
+			item:    item,
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO batch_run_items").
+					WithArgs(1, 1, "updated", "on_going", "late", 0, 3, nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "failed item keeps error message",
+			item: repository.BatchRunItem{
+				BatchRunID:          1,
+				LoanID:              2,
+				ItemStatus:          repository.BatchRunItemFailed,
+				PreviousLoanStatus:  "late",
+				LoanStatus:          "late",
+				PreviousDaysPastDue: 4,
+				DaysPastDue:         4,
+				ErrorMessage:        "consumer limit not found",
+			},
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO batch_run_items").
+					WithArgs(1, 2, "failed", "late", "late", 4, 4, "consumer limit not found").
+					WillReturnResult(sqlmock.NewResult(2, 1))
+			},
+		},
+		{
+			name:    "exec error",
+			item:    item,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO batch_run_items").
+					WithArgs(1, 1, "updated", "on_going", "late", 0, 3, nil).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.CreateBatchRunItem(context.Background(), tt.item, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 )
 
 type ConsumerLimitRepository interface {
@@ -15,6 +16,7 @@ type ConsumerLimitRepository interface {
 	DeleteConsumerLimit(ctx context.Context, consumerLimitID int64) (err error)
 	GetConsumerLimitByConsumerID(ctx context.Context, consumerID int64) (result []ConsumerLimit, err error)
 	GetConsumerLimitByID(ctx context.Context, consumerLimitID int64) (result ConsumerLimit, err error)
+	LockLimitByTenureAndConsumerID(ctx context.Context, tenure int16, consumerID int64, tx *sql.Tx) (result ConsumerLimit, err error)
 }
 
 type consumerLimitRepository struct {
@@ -30,23 +32,14 @@ type (
 		ID          int64
 		ConsumerID  int64
 		Tenure      int16
-		LimitAmount float64
+		LimitAmount money.Money
 	}
 
 	ConsumerLimitScanner struct {
 		ID          sql.NullInt64
 		ConsumerID  sql.NullInt64
 		Tenure      sql.NullInt16
-		LimitAmount sql.NullFloat64
-	}
-)
-
-var (
-	ValidConsumerLimitTenure = map[int16]bool{
-		1: true,
-		2: true,
-		3: true,
-		6: true,
+		LimitAmount money.NullMoney
 	}
 )
 
@@ -60,7 +53,7 @@ func (r *consumerLimitRepository) GetLimitByTenureAndConsumerID(ctx context.Cont
 		FROM consumer_limits
 		WHERE deleted_at IS NULL
 		AND consumer_id = ?
-		AND CAST(tenure AS CHAR) = ?
+		AND tenure = ?
 		LIMIT 1
 	`
 
@@ -84,7 +77,7 @@ func (r *consumerLimitRepository) GetLimitByTenureAndConsumerID(ctx context.Cont
 		ID:          consumerLimitScanner.ID.Int64,
 		ConsumerID:  consumerLimitScanner.ConsumerID.Int64,
 		Tenure:      consumerLimitScanner.Tenure.Int16,
-		LimitAmount: consumerLimitScanner.LimitAmount.Float64,
+		LimitAmount: consumerLimitScanner.LimitAmount.Money,
 	}
 
 	return result, nil
@@ -97,7 +90,7 @@ func (r *consumerLimitRepository) CreateConsumerLimit(ctx context.Context, consu
 			tenure,
 			limit_amount,
 			created_at
-		) VALUES (?, CAST(? AS CHAR), ?, NOW())
+		) VALUES (?, ?, ?, NOW())
 	`
 
 	res, err := r.db.ExecContext(ctx, query,
@@ -189,7 +182,7 @@ func (r *consumerLimitRepository) GetConsumerLimitByConsumerID(ctx context.Conte
 			ID:          consumerLimitScanner.ID.Int64,
 			ConsumerID:  consumerLimitScanner.ConsumerID.Int64,
 			Tenure:      consumerLimitScanner.Tenure.Int16,
-			LimitAmount: consumerLimitScanner.LimitAmount.Float64,
+			LimitAmount: consumerLimitScanner.LimitAmount.Money,
 		}
 
 		consumerLimits = append(consumerLimits, consumerLimit)
@@ -231,7 +224,57 @@ func (r *consumerLimitRepository) GetConsumerLimitByID(ctx context.Context, cons
 		ID:          consumerLimitScanner.ID.Int64,
 		ConsumerID:  consumerLimitScanner.ConsumerID.Int64,
 		Tenure:      consumerLimitScanner.Tenure.Int16,
-		LimitAmount: consumerLimitScanner.LimitAmount.Float64,
+		LimitAmount: consumerLimitScanner.LimitAmount.Money,
+	}
+
+	return result, nil
+}
+
+// LockLimitByTenureAndConsumerID reads a consumer limit with a row lock that is held until
+// tx ends, so loans drawing on the same limit are booked one at a time.
+func (r *consumerLimitRepository) LockLimitByTenureAndConsumerID(ctx context.Context, tenure int16, consumerID int64, tx *sql.Tx) (result ConsumerLimit, err error) {
+	query := `
+		SELECT
+			consumer_limit_id,
+			consumer_id,
+			tenure,
+			limit_amount
+		FROM consumer_limits
+		WHERE deleted_at IS NULL
+		AND consumer_id = ?
+		AND tenure = ?
+		LIMIT 1
+		FOR UPDATE
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, consumerID, tenure)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, consumerID, tenure)
+	}
+
+	var consumerLimitScanner ConsumerLimitScanner
+	err = row.Scan(
+		&consumerLimitScanner.ID,
+		&consumerLimitScanner.ConsumerID,
+		&consumerLimitScanner.Tenure,
+		&consumerLimitScanner.LimitAmount,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return result, nil
+		}
+
+		logger.Error(fmt.Sprintf("[consumerLimitRepository][LockLimitByTenureAndConsumerID] while scan query row. Err: %v", err))
+		return result, err
+	}
+
+	result = ConsumerLimit{
+		ID:          consumerLimitScanner.ID.Int64,
+		ConsumerID:  consumerLimitScanner.ConsumerID.Int64,
+		Tenure:      consumerLimitScanner.Tenure.Int16,
+		LimitAmount: consumerLimitScanner.LimitAmount.Money,
 	}
 
 	return result, nil
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 )
@@ -42,7 +43,7 @@ func TestGetLimitByTenureAndConsumerID(t *testing.T) {
 				ID:          1,
 				ConsumerID:  123,
 				Tenure:      1,
-				LimitAmount: 1000.0,
+				LimitAmount: money.New(1000),
 			},
 			wantErr: false,
 		},
@@ -108,11 +109,11 @@ func TestCreateConsumerLimit(t *testing.T) {
 			consumerLimit: repository.ConsumerLimit{
 				ConsumerID:  123,
 				Tenure:      1,
-				LimitAmount: 1000.0,
+				LimitAmount: money.New(1000),
 			},
 			mock: func() {
 				mock.ExpectExec(query).
-					WithArgs(123, 1, 1000.0).
+					WithArgs(123, 1, money.New(1000)).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			wantID:  1,
@@ -123,11 +124,11 @@ func TestCreateConsumerLimit(t *testing.T) {
 			consumerLimit: repository.ConsumerLimit{
 				ConsumerID:  123,
 				Tenure:      1,
-				LimitAmount: 1000.0,
+				LimitAmount: money.New(1000),
 			},
 			mock: func() {
 				mock.ExpectExec(query).
-					WithArgs(123, 1, 1000.0).
+					WithArgs(123, 1, money.New(1000)).
 					WillReturnError(sql.ErrConnDone)
 			},
 			wantID:  0,
@@ -169,11 +170,11 @@ func TestUpdateConsumerLimit(t *testing.T) {
 			name: "success",
 			consumerLimit: repository.ConsumerLimit{
 				ID:          1,
-				LimitAmount: 2000.0,
+				LimitAmount: money.New(2000),
 			},
 			mock: func() {
 				mock.ExpectExec(query).
-					WithArgs(2000.0, 1).
+					WithArgs(money.New(2000), 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			wantErr: false,
@@ -182,11 +183,11 @@ func TestUpdateConsumerLimit(t *testing.T) {
 			name: "update error",
 			consumerLimit: repository.ConsumerLimit{
 				ID:          1,
-				LimitAmount: 2000.0,
+				LimitAmount: money.New(2000),
 			},
 			mock: func() {
 				mock.ExpectExec(query).
-					WithArgs(2000.0, 1).
+					WithArgs(money.New(2000), 1).
 					WillReturnError(sql.ErrConnDone)
 			},
 			wantErr: true,
@@ -290,13 +291,13 @@ func TestGetConsumerLimitByConsumerID(t *testing.T) {
 					ID:          1,
 					ConsumerID:  123,
 					Tenure:      1,
-					LimitAmount: 1000.0,
+					LimitAmount: money.New(1000),
 				},
 				{
 					ID:          2,
 					ConsumerID:  123,
 					Tenure:      2,
-					LimitAmount: 2000.0,
+					LimitAmount: money.New(2000),
 				},
 			},
 			wantErr: false,
@@ -369,7 +370,7 @@ func TestGetConsumerLimitByID(t *testing.T) {
 				ID:          1,
 				ConsumerID:  123,
 				Tenure:      1,
-				LimitAmount: 1000.0,
+				LimitAmount: money.New(1000),
 			},
 			wantErr: false,
 		},
@@ -410,3 +411,70 @@ func TestGetConsumerLimitByID(t *testing.T) {
 		})
 	}
 }
+
+func TestLockLimitByTenureAndConsumerID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewConsumerLimitRepository(db)
+	query := "SELECT (.+) FROM consumer_limits WHERE deleted_at IS NULL AND consumer_id = \\? AND tenure = \\? LIMIT 1 FOR UPDATE"
+
+	mock.ExpectBegin()
+	trx, _ := db.Begin()
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    repository.ConsumerLimit
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"consumer_limit_id", "consumer_id", "tenure", "limit_amount"}).
+					AddRow(1, 123, 6, "1000.000")
+				mock.ExpectQuery(query).WithArgs(123, 6).WillReturnRows(rows)
+			},
+			want: repository.ConsumerLimit{
+				ID:          1,
+				ConsumerID:  123,
+				Tenure:      6,
+				LimitAmount: money.New(1000),
+			},
+			wantErr: false,
+		},
+		{
+			name: "no rows",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(123, 6).WillReturnError(sql.ErrNoRows)
+			},
+			want:    repository.ConsumerLimit{},
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(123, 6).WillReturnError(sql.ErrConnDone)
+			},
+			want:    repository.ConsumerLimit{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.LockLimitByTenureAndConsumerID(context.Background(), 6, 123, trx)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
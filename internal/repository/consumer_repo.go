@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 )
 
 type ConsumerRepository interface {
@@ -16,6 +17,7 @@ type ConsumerRepository interface {
 	FetchConsumer(ctx context.Context, req FetchConsumerRequest) (data []Consumer, err error)
 	UpdateConsumer(ctx context.Context, consumer Consumer) (err error)
 	DeleteConsumer(ctx context.Context, id int64) (err error)
+	AddCreditBalance(ctx context.Context, consumerID int64, amount money.Money, tx *sql.Tx) (err error)
 }
 
 type consumerRepo struct {
@@ -33,30 +35,32 @@ type (
 	}
 
 	Consumer struct {
-		ID           int64
-		FullName     string
-		LegalName    string
-		PlaceOfBirth string
-		DOB          string
-		Salary       float64
-		NIK          string
-		KTPImageURL  string
-		SelfieURL    string
-		CreatedAt    time.Time
+		ID            int64
+		FullName      string
+		LegalName     string
+		PlaceOfBirth  string
+		DOB           string
+		Salary        money.Money
+		CreditBalance money.Money
+		NIK           string
+		KTPImageURL   string
+		SelfieURL     string
+		CreatedAt     time.Time
 	}
 )
 
 type ConsumerScanner struct {
-	ID           sql.NullInt64
-	FullName     sql.NullString
-	LegalName    sql.NullString
-	PlaceOfBirth sql.NullString
-	DOB          sql.NullString
-	Salary       sql.NullFloat64
-	NIK          sql.NullString
-	KTPImageURL  sql.NullString
-	SelfieURL    sql.NullString
-	CreatedAt    sql.NullTime
+	ID            sql.NullInt64
+	FullName      sql.NullString
+	LegalName     sql.NullString
+	PlaceOfBirth  sql.NullString
+	DOB           sql.NullString
+	Salary        money.NullMoney
+	CreditBalance money.NullMoney
+	NIK           sql.NullString
+	KTPImageURL   sql.NullString
+	SelfieURL     sql.NullString
+	CreatedAt     sql.NullTime
 }
 
 func (r *consumerRepo) CreateConsumer(ctx context.Context, consumer Consumer) (id int64, err error) {
@@ -160,6 +164,7 @@ func (r *consumerRepo) GetConsumerByID(ctx context.Context, id int64) (data Cons
 			place_of_birth,
 			dob,
 			salary,
+			credit_balance,
 			nik,
 			ktp_image_url,
 			selfie_image_url,
@@ -179,6 +184,7 @@ func (r *consumerRepo) GetConsumerByID(ctx context.Context, id int64) (data Cons
 		&consumerScanner.PlaceOfBirth,
 		&consumerScanner.DOB,
 		&consumerScanner.Salary,
+		&consumerScanner.CreditBalance,
 		&consumerScanner.NIK,
 		&consumerScanner.KTPImageURL,
 		&consumerScanner.SelfieURL,
@@ -193,16 +199,17 @@ func (r *consumerRepo) GetConsumerByID(ctx context.Context, id int64) (data Cons
 	}
 
 	data = Consumer{
-		ID:           consumerScanner.ID.Int64,
-		FullName:     consumerScanner.FullName.String,
-		LegalName:    consumerScanner.LegalName.String,
-		PlaceOfBirth: consumerScanner.PlaceOfBirth.String,
-		DOB:          consumerScanner.DOB.String,
-		Salary:       consumerScanner.Salary.Float64,
-		NIK:          consumerScanner.NIK.String,
-		KTPImageURL:  consumerScanner.KTPImageURL.String,
-		SelfieURL:    consumerScanner.SelfieURL.String,
-		CreatedAt:    consumerScanner.CreatedAt.Time,
+		ID:            consumerScanner.ID.Int64,
+		FullName:      consumerScanner.FullName.String,
+		LegalName:     consumerScanner.LegalName.String,
+		PlaceOfBirth:  consumerScanner.PlaceOfBirth.String,
+		DOB:           consumerScanner.DOB.String,
+		Salary:        consumerScanner.Salary.Money,
+		CreditBalance: consumerScanner.CreditBalance.Money,
+		NIK:           consumerScanner.NIK.String,
+		KTPImageURL:   consumerScanner.KTPImageURL.String,
+		SelfieURL:     consumerScanner.SelfieURL.String,
+		CreatedAt:     consumerScanner.CreatedAt.Time,
 	}
 
 	return data, nil
@@ -217,6 +224,7 @@ func (r *consumerRepo) FetchConsumer(ctx context.Context, req FetchConsumerReque
 			place_of_birth,
 			dob,
 			salary,
+			credit_balance,
 			nik,
 			ktp_image_url,
 			selfie_image_url,
@@ -241,6 +249,7 @@ func (r *consumerRepo) FetchConsumer(ctx context.Context, req FetchConsumerReque
 			&consumerScanner.PlaceOfBirth,
 			&consumerScanner.DOB,
 			&consumerScanner.Salary,
+			&consumerScanner.CreditBalance,
 			&consumerScanner.NIK,
 			&consumerScanner.KTPImageURL,
 			&consumerScanner.SelfieURL,
@@ -251,18 +260,44 @@ func (r *consumerRepo) FetchConsumer(ctx context.Context, req FetchConsumerReque
 		}
 
 		data = append(data, Consumer{
-			ID:           consumerScanner.ID.Int64,
-			FullName:     consumerScanner.FullName.String,
-			LegalName:    consumerScanner.LegalName.String,
-			PlaceOfBirth: consumerScanner.PlaceOfBirth.String,
-			DOB:          consumerScanner.DOB.String,
-			Salary:       consumerScanner.Salary.Float64,
-			NIK:          consumerScanner.NIK.String,
-			KTPImageURL:  consumerScanner.KTPImageURL.String,
-			SelfieURL:    consumerScanner.SelfieURL.String,
-			CreatedAt:    consumerScanner.CreatedAt.Time,
+			ID:            consumerScanner.ID.Int64,
+			FullName:      consumerScanner.FullName.String,
+			LegalName:     consumerScanner.LegalName.String,
+			PlaceOfBirth:  consumerScanner.PlaceOfBirth.String,
+			DOB:           consumerScanner.DOB.String,
+			Salary:        consumerScanner.Salary.Money,
+			CreditBalance: consumerScanner.CreditBalance.Money,
+			NIK:           consumerScanner.NIK.String,
+			KTPImageURL:   consumerScanner.KTPImageURL.String,
+			SelfieURL:     consumerScanner.SelfieURL.String,
+			CreatedAt:     consumerScanner.CreatedAt.Time,
 		})
 	}
 
 	return data, nil
 }
+
+// AddCreditBalance adds amount to the credit balance of a consumer, e.g. the part of a
+// payment left over once the loan is paid off.
+func (r *consumerRepo) AddCreditBalance(ctx context.Context, consumerID int64, amount money.Money, tx *sql.Tx) (err error) {
+	query := `
+		UPDATE consumers
+		SET
+			credit_balance = credit_balance + ?,
+			updated_at = NOW()
+		WHERE deleted_at is null
+		AND consumer_id = ?
+	`
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, amount, consumerID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, amount, consumerID)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[consumerRepo][AddCreditBalance] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
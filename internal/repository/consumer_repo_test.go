@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 )
@@ -26,7 +27,7 @@ func TestCreateConsumer(t *testing.T) {
 			LegalName:    "Johnathan Doe",
 			PlaceOfBirth: "New York",
 			DOB:          "1990-01-01",
-			Salary:       50000,
+			Salary:       money.New(50000),
 			NIK:          "1234567890",
 			KTPImageURL:  "http://example.com/ktp.jpg",
 			SelfieURL:    "http://example.com/selfie.jpg",
@@ -57,7 +58,7 @@ func TestCreateConsumer(t *testing.T) {
 			LegalName:    "Johnathan Doe",
 			PlaceOfBirth: "New York",
 			DOB:          "1990-01-01",
-			Salary:       50000,
+			Salary:       money.New(50000),
 			NIK:          "1234567890",
 			KTPImageURL:  "http://example.com/ktp.jpg",
 			SelfieURL:    "http://example.com/selfie.jpg",
@@ -95,12 +96,12 @@ func TestGetConsumerByID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		consumerID := int64(1)
 		rows := sqlmock.NewRows([]string{
-			"consumer_id", "full_name", "legal_name", "place_of_birth", "dob", "salary", "nik", "ktp_image_url", "selfie_image_url", "created_at",
+			"consumer_id", "full_name", "legal_name", "place_of_birth", "dob", "salary", "credit_balance", "nik", "ktp_image_url", "selfie_image_url", "created_at",
 		}).AddRow(
-			consumerID, "John Doe", "Johnathan Doe", "New York", "1990-01-01", 50000, "1234567890", "http://example.com/ktp.jpg", "http://example.com/selfie.jpg", time.Now(),
+			consumerID, "John Doe", "Johnathan Doe", "New York", "1990-01-01", 50000, "25000.000", "1234567890", "http://example.com/ktp.jpg", "http://example.com/selfie.jpg", time.Now(),
 		)
 
-		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null AND consumer_id = ?").
+		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, credit_balance, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null AND consumer_id = ?").
 			WithArgs(consumerID).
 			WillReturnRows(rows)
 
@@ -111,7 +112,8 @@ func TestGetConsumerByID(t *testing.T) {
 		assert.Equal(t, "Johnathan Doe", consumer.LegalName)
 		assert.Equal(t, "New York", consumer.PlaceOfBirth)
 		assert.Equal(t, "1990-01-01", consumer.DOB)
-		assert.Equal(t, 50000.0, consumer.Salary)
+		assert.Equal(t, money.New(50000), consumer.Salary)
+		assert.Equal(t, money.New(25000), consumer.CreditBalance)
 		assert.Equal(t, "1234567890", consumer.NIK)
 		assert.Equal(t, "http://example.com/ktp.jpg", consumer.KTPImageURL)
 		assert.Equal(t, "http://example.com/selfie.jpg", consumer.SelfieURL)
@@ -120,7 +122,7 @@ func TestGetConsumerByID(t *testing.T) {
 	t.Run("not found", func(t *testing.T) {
 		consumerID := int64(2)
 
-		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null AND consumer_id = ?").
+		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, credit_balance, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null AND consumer_id = ?").
 			WithArgs(consumerID).
 			WillReturnError(sql.ErrNoRows)
 
@@ -132,7 +134,7 @@ func TestGetConsumerByID(t *testing.T) {
 	t.Run("query error", func(t *testing.T) {
 		consumerID := int64(3)
 
-		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null AND consumer_id = ?").
+		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, credit_balance, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null AND consumer_id = ?").
 			WithArgs(consumerID).
 			WillReturnError(sql.ErrConnDone)
 
@@ -158,7 +160,7 @@ func TestUpdateConsumer(t *testing.T) {
 			LegalName:    "Johnathan Doe",
 			PlaceOfBirth: "New York",
 			DOB:          "1990-01-01",
-			Salary:       50000,
+			Salary:       money.New(50000),
 			NIK:          "1234567890",
 			KTPImageURL:  "http://example.com/ktp.jpg",
 			SelfieURL:    "http://example.com/selfie.jpg",
@@ -189,7 +191,7 @@ func TestUpdateConsumer(t *testing.T) {
 			LegalName:    "Johnathan Doe",
 			PlaceOfBirth: "New York",
 			DOB:          "1990-01-01",
-			Salary:       50000,
+			Salary:       money.New(50000),
 			NIK:          "1234567890",
 			KTPImageURL:  "http://example.com/ktp.jpg",
 			SelfieURL:    "http://example.com/selfie.jpg",
@@ -246,6 +248,37 @@ func TestDeleteConsumer(t *testing.T) {
 	})
 }
 
+func TestAddCreditBalance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewConsumerRepository(db)
+
+	mock.ExpectBegin()
+	trx, _ := db.Begin()
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec("UPDATE consumers SET credit_balance = credit_balance \\+ \\?").
+			WithArgs(money.New(500), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.AddCreditBalance(context.Background(), 1, money.New(500), trx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mock.ExpectExec("UPDATE consumers SET credit_balance = credit_balance \\+ \\?").
+			WithArgs(money.New(500), int64(1)).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.AddCreditBalance(context.Background(), 1, money.New(500), trx)
+		assert.Error(t, err)
+	})
+}
+
 func TestFetchConsumer(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -262,14 +295,14 @@ func TestFetchConsumer(t *testing.T) {
 		}
 
 		rows := sqlmock.NewRows([]string{
-			"consumer_id", "full_name", "legal_name", "place_of_birth", "dob", "salary", "nik", "ktp_image_url", "selfie_image_url", "created_at",
+			"consumer_id", "full_name", "legal_name", "place_of_birth", "dob", "salary", "credit_balance", "nik", "ktp_image_url", "selfie_image_url", "created_at",
 		}).AddRow(
-			1, "John Doe", "Johnathan Doe", "New York", "1990-01-01", 50000, "1234567890", "http://example.com/ktp.jpg", "http://example.com/selfie.jpg", time.Now(),
+			1, "John Doe", "Johnathan Doe", "New York", "1990-01-01", 50000, "25000.000", "1234567890", "http://example.com/ktp.jpg", "http://example.com/selfie.jpg", time.Now(),
 		).AddRow(
-			2, "Jane Doe", "Janet Doe", "Los Angeles", "1992-02-02", 60000, "0987654321", "http://example.com/ktp2.jpg", "http://example.com/selfie2.jpg", time.Now(),
+			2, "Jane Doe", "Janet Doe", "Los Angeles", "1992-02-02", 60000, nil, "0987654321", "http://example.com/ktp2.jpg", "http://example.com/selfie2.jpg", time.Now(),
 		)
 
-		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null LIMIT \\? OFFSET \\?").
+		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, credit_balance, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null LIMIT \\? OFFSET \\?").
 			WithArgs(req.Limit, req.Offset).
 			WillReturnRows(rows)
 
@@ -281,7 +314,7 @@ func TestFetchConsumer(t *testing.T) {
 		assert.Equal(t, "Johnathan Doe", consumers[0].LegalName)
 		assert.Equal(t, "New York", consumers[0].PlaceOfBirth)
 		assert.Equal(t, "1990-01-01", consumers[0].DOB)
-		assert.Equal(t, 50000.0, consumers[0].Salary)
+		assert.Equal(t, money.New(50000), consumers[0].Salary)
 		assert.Equal(t, "1234567890", consumers[0].NIK)
 		assert.Equal(t, "http://example.com/ktp.jpg", consumers[0].KTPImageURL)
 		assert.Equal(t, "http://example.com/selfie.jpg", consumers[0].SelfieURL)
@@ -291,7 +324,8 @@ func TestFetchConsumer(t *testing.T) {
 		assert.Equal(t, "Janet Doe", consumers[1].LegalName)
 		assert.Equal(t, "Los Angeles", consumers[1].PlaceOfBirth)
 		assert.Equal(t, "1992-02-02", consumers[1].DOB)
-		assert.Equal(t, 60000.0, consumers[1].Salary)
+		assert.Equal(t, money.New(60000), consumers[1].Salary)
+		assert.Equal(t, money.Zero, consumers[1].CreditBalance)
 		assert.Equal(t, "0987654321", consumers[1].NIK)
 		assert.Equal(t, "http://example.com/ktp2.jpg", consumers[1].KTPImageURL)
 		assert.Equal(t, "http://example.com/selfie2.jpg", consumers[1].SelfieURL)
@@ -303,7 +337,7 @@ func TestFetchConsumer(t *testing.T) {
 			Offset: 0,
 		}
 
-		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null LIMIT \\? OFFSET \\?").
+		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, credit_balance, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null LIMIT \\? OFFSET \\?").
 			WithArgs(req.Limit, req.Offset).
 			WillReturnRows(sqlmock.NewRows(nil))
 
@@ -318,7 +352,7 @@ func TestFetchConsumer(t *testing.T) {
 			Offset: 0,
 		}
 
-		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null LIMIT \\? OFFSET \\?").
+		mock.ExpectQuery("SELECT consumer_id, full_name, legal_name, place_of_birth, dob, salary, credit_balance, nik, ktp_image_url, selfie_image_url, created_at FROM consumers WHERE deleted_at is null LIMIT \\? OFFSET \\?").
 			WithArgs(req.Limit, req.Offset).
 			WillReturnError(sql.ErrConnDone)
 
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+)
+
+type IdempotencyKeyRepository interface {
+	CreateIdempotencyKey(ctx context.Context, key IdempotencyKey) (bool, error)
+	GetIdempotencyKey(ctx context.Context, req GetIdempotencyKeyRequest) (IdempotencyKey, error)
+	SaveIdempotencyKeyResponse(ctx context.Context, req SaveIdempotencyKeyResponseRequest) error
+	DeleteIdempotencyKey(ctx context.Context, req GetIdempotencyKeyRequest) error
+}
+
+type idempotencyKeyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyKeyRepository(db *sql.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{db: db}
+}
+
+type (
+	// IdempotencyKey is a request made with an Idempotency-Key header. ResponseStatus is 0
+	// while the request is still being processed.
+	IdempotencyKey struct {
+		ID             int64
+		Key            string
+		RequestMethod  string
+		RequestPath    string
+		RequestHash    string
+		ResponseStatus int
+		ResponseBody   []byte
+		CreatedAt      time.Time
+	}
+
+	IdempotencyKeyScanner struct {
+		ID             sql.NullInt64
+		Key            sql.NullString
+		RequestMethod  sql.NullString
+		RequestPath    sql.NullString
+		RequestHash    sql.NullString
+		ResponseStatus sql.NullInt32
+		ResponseBody   sql.NullString
+		CreatedAt      sql.NullTime
+	}
+
+	GetIdempotencyKeyRequest struct {
+		Key           string
+		RequestMethod string
+		RequestPath   string
+	}
+
+	SaveIdempotencyKeyResponseRequest struct {
+		Key            string
+		RequestMethod  string
+		RequestPath    string
+		ResponseStatus int
+		ResponseBody   []byte
+	}
+)
+
+// CreateIdempotencyKey claims an idempotency key for a request. It reports false when the
+// key was already used for the same method and path.
+func (r *idempotencyKeyRepository) CreateIdempotencyKey(ctx context.Context, key IdempotencyKey) (created bool, err error) {
+	query := `
+		INSERT INTO idempotency_keys (
+			idempotency_key,
+			request_method,
+			request_path,
+			request_hash,
+			created_at
+		) VALUES (?, ?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE idempotency_key = idempotency_key
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		key.Key,
+		key.RequestMethod,
+		key.RequestPath,
+		key.RequestHash,
+	)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[idempotencyKeyRepository][CreateIdempotencyKey] while exec query. Err: %v", err))
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[idempotencyKeyRepository][CreateIdempotencyKey] while get rows affected. Err: %v", err))
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *idempotencyKeyRepository) GetIdempotencyKey(ctx context.Context, req GetIdempotencyKeyRequest) (result IdempotencyKey, err error) {
+	query := `
+		SELECT
+			idempotency_key_id,
+			idempotency_key,
+			request_method,
+			request_path,
+			request_hash,
+			response_status,
+			response_body,
+			created_at
+		FROM idempotency_keys
+		WHERE request_method = ?
+		AND request_path = ?
+		AND idempotency_key = ?
+	`
+
+	var scanner IdempotencyKeyScanner
+	err = r.db.QueryRowContext(ctx, query, req.RequestMethod, req.RequestPath, req.Key).Scan(
+		&scanner.ID,
+		&scanner.Key,
+		&scanner.RequestMethod,
+		&scanner.RequestPath,
+		&scanner.RequestHash,
+		&scanner.ResponseStatus,
+		&scanner.ResponseBody,
+		&scanner.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return result, nil
+		}
+
+		logger.Error(fmt.Sprintf("[idempotencyKeyRepository][GetIdempotencyKey] while scan query row. Err: %v", err))
+		return result, err
+	}
+
+	result = IdempotencyKey{
+		ID:             scanner.ID.Int64,
+		Key:            scanner.Key.String,
+		RequestMethod:  scanner.RequestMethod.String,
+		RequestPath:    scanner.RequestPath.String,
+		RequestHash:    scanner.RequestHash.String,
+		ResponseStatus: int(scanner.ResponseStatus.Int32),
+		CreatedAt:      scanner.CreatedAt.Time,
+	}
+	if scanner.ResponseBody.Valid {
+		result.ResponseBody = []byte(scanner.ResponseBody.String)
+	}
+
+	return result, nil
+}
+
+// SaveIdempotencyKeyResponse records the response of a request, it is replayed when the
+// request is retried with the same key.
+func (r *idempotencyKeyRepository) SaveIdempotencyKeyResponse(ctx context.Context, req SaveIdempotencyKeyResponseRequest) (err error) {
+	query := `
+		UPDATE idempotency_keys
+		SET
+			response_status = ?,
+			response_body = ?,
+			updated_at = NOW()
+		WHERE request_method = ?
+		AND request_path = ?
+		AND idempotency_key = ?
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		req.ResponseStatus,
+		string(req.ResponseBody),
+		req.RequestMethod,
+		req.RequestPath,
+		req.Key,
+	)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[idempotencyKeyRepository][SaveIdempotencyKeyResponse] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey releases a key whose request failed, so it can be retried.
+func (r *idempotencyKeyRepository) DeleteIdempotencyKey(ctx context.Context, req GetIdempotencyKeyRequest) (err error) {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE request_method = ?
+		AND request_path = ?
+		AND idempotency_key = ?
+	`
+
+	_, err = r.db.ExecContext(ctx, query, req.RequestMethod, req.RequestPath, req.Key)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[idempotencyKeyRepository][DeleteIdempotencyKey] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
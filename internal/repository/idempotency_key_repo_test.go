@@ -0,0 +1,225 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewIdempotencyKeyRepository(db)
+	key := repository.IdempotencyKey{Key: "key-1", RequestMethod: "POST", RequestPath: "/api/v1/loans", RequestHash: "hash"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "created",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WithArgs("key-1", "POST", "/api/v1/loans", "hash").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "already used",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WithArgs("key-1", "POST", "/api/v1/loans", "hash").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "exec error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WithArgs("key-1", "POST", "/api/v1/loans", "hash").
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    false,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateIdempotencyKey(context.Background(), key)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetIdempotencyKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewIdempotencyKeyRepository(db)
+	req := repository.GetIdempotencyKeyRequest{Key: "key-1", RequestMethod: "POST", RequestPath: "/api/v1/loans"}
+	query := "SELECT (.+) FROM idempotency_keys WHERE request_method = \\? AND request_path = \\? AND idempotency_key = \\?"
+	createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	columns := []string{"idempotency_key_id", "idempotency_key", "request_method", "request_path", "request_hash", "response_status", "response_body", "created_at"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    repository.IdempotencyKey
+		wantErr bool
+	}{
+		{
+			name: "with response",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).AddRow(1, "key-1", "POST", "/api/v1/loans", "hash", 201, `{"code":201}`, createdAt)
+				mock.ExpectQuery(query).WithArgs("POST", "/api/v1/loans", "key-1").WillReturnRows(rows)
+			},
+			want: repository.IdempotencyKey{
+				ID:             1,
+				Key:            "key-1",
+				RequestMethod:  "POST",
+				RequestPath:    "/api/v1/loans",
+				RequestHash:    "hash",
+				ResponseStatus: 201,
+				ResponseBody:   []byte(`{"code":201}`),
+				CreatedAt:      createdAt,
+			},
+			wantErr: false,
+		},
+		{
+			name: "still processing",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).AddRow(1, "key-1", "POST", "/api/v1/loans", "hash", nil, nil, createdAt)
+				mock.ExpectQuery(query).WithArgs("POST", "/api/v1/loans", "key-1").WillReturnRows(rows)
+			},
+			want: repository.IdempotencyKey{
+				ID:            1,
+				Key:           "key-1",
+				RequestMethod: "POST",
+				RequestPath:   "/api/v1/loans",
+				RequestHash:   "hash",
+				CreatedAt:     createdAt,
+			},
+			wantErr: false,
+		},
+		{
+			name: "no rows",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs("POST", "/api/v1/loans", "key-1").WillReturnError(sql.ErrNoRows)
+			},
+			want:    repository.IdempotencyKey{},
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs("POST", "/api/v1/loans", "key-1").WillReturnError(sql.ErrConnDone)
+			},
+			want:    repository.IdempotencyKey{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetIdempotencyKey(context.Background(), req)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSaveIdempotencyKeyResponse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewIdempotencyKeyRepository(db)
+	req := repository.SaveIdempotencyKeyResponseRequest{
+		Key:            "key-1",
+		RequestMethod:  "POST",
+		RequestPath:    "/api/v1/loans",
+		ResponseStatus: 201,
+		ResponseBody:   []byte(`{"code":201}`),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec("UPDATE idempotency_keys").
+			WithArgs(201, `{"code":201}`, "POST", "/api/v1/loans", "key-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SaveIdempotencyKeyResponse(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mock.ExpectExec("UPDATE idempotency_keys").
+			WithArgs(201, `{"code":201}`, "POST", "/api/v1/loans", "key-1").
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.SaveIdempotencyKeyResponse(context.Background(), req)
+		assert.Error(t, err)
+	})
+}
+
+func TestDeleteIdempotencyKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewIdempotencyKeyRepository(db)
+	req := repository.GetIdempotencyKeyRequest{Key: "key-1", RequestMethod: "POST", RequestPath: "/api/v1/loans"}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM idempotency_keys").
+			WithArgs("POST", "/api/v1/loans", "key-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.DeleteIdempotencyKey(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM idempotency_keys").
+			WithArgs("POST", "/api/v1/loans", "key-1").
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.DeleteIdempotencyKey(context.Background(), req)
+		assert.Error(t, err)
+	})
+}
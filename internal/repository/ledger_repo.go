@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+type LedgerRepository interface {
+	CreateJournalEntry(ctx context.Context, entry JournalEntry, tx *sql.Tx) (int64, error)
+	GetTrialBalance(ctx context.Context, asOf time.Time) ([]TrialBalanceAccount, error)
+}
+
+type ledgerRepository struct {
+	db *sql.DB
+}
+
+func NewLedgerRepository(db *sql.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+type (
+	// JournalEntry is a balanced set of journal lines posted for the loan, restructure or
+	// transaction it references.
+	JournalEntry struct {
+		ID            int64
+		ReferenceType string
+		ReferenceID   int64
+		Description   string
+		PostedAt      time.Time
+		Lines         []JournalLine
+	}
+
+	JournalLine struct {
+		AccountCode  string
+		DebitAmount  money.Money
+		CreditAmount money.Money
+	}
+
+	// TrialBalanceAccount is the total debit and credit posted to an account.
+	TrialBalanceAccount struct {
+		AccountCode  string
+		AccountName  string
+		AccountType  string
+		DebitAmount  money.Money
+		CreditAmount money.Money
+	}
+
+	TrialBalanceAccountScanner struct {
+		AccountCode  sql.NullString
+		AccountName  sql.NullString
+		AccountType  sql.NullString
+		DebitAmount  money.NullMoney
+		CreditAmount money.NullMoney
+	}
+)
+
+func (r *ledgerRepository) CreateJournalEntry(ctx context.Context, entry JournalEntry, tx *sql.Tx) (id int64, err error) {
+	query := `
+		INSERT INTO journal_entries (
+			reference_type,
+			reference_id,
+			description,
+			posted_at,
+			created_at
+		) VALUES (?, ?, ?, ?, NOW())
+	`
+
+	args := []interface{}{
+		entry.ReferenceType,
+		entry.ReferenceID,
+		entry.Description,
+		entry.PostedAt,
+	}
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ledgerRepository][CreateJournalEntry] while exec query. Err: %v", err))
+		return 0, err
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ledgerRepository][CreateJournalEntry] while get last insert id. Err: %v", err))
+		return 0, err
+	}
+
+	if len(entry.Lines) == 0 {
+		return id, nil
+	}
+
+	placeholders := make([]string, 0, len(entry.Lines))
+	args = make([]interface{}, 0, len(entry.Lines)*4)
+	for _, line := range entry.Lines {
+		placeholders = append(placeholders, "(?, ?, ?, ?, NOW())")
+		args = append(args,
+			id,
+			line.AccountCode,
+			line.DebitAmount,
+			line.CreditAmount,
+		)
+	}
+
+	query = `
+		INSERT INTO journal_lines (
+			journal_entry_id,
+			account_code,
+			debit_amount,
+			credit_amount,
+			created_at
+		) VALUES ` + strings.Join(placeholders, ", ")
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ledgerRepository][CreateJournalEntry] while exec query lines. Err: %v", err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetTrialBalance returns the totals of every account of the chart of accounts over the
+// journal entries posted before asOf, an account without entries has zero totals.
+func (r *ledgerRepository) GetTrialBalance(ctx context.Context, asOf time.Time) (results []TrialBalanceAccount, err error) {
+	query := `
+		SELECT
+			la.account_code,
+			la.account_name,
+			la.account_type,
+			COALESCE(SUM(jl.debit_amount), 0),
+			COALESCE(SUM(jl.credit_amount), 0)
+		FROM ledger_accounts la
+		LEFT JOIN (
+			journal_lines jl
+			JOIN journal_entries je ON je.journal_entry_id = jl.journal_entry_id AND je.posted_at < ?
+		) ON jl.account_code = la.account_code
+		WHERE la.deleted_at IS NULL
+		GROUP BY la.account_code, la.account_name, la.account_type
+		ORDER BY la.account_code
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, asOf)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ledgerRepository][GetTrialBalance] while query. Err: %v", err))
+		return results, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var scanner TrialBalanceAccountScanner
+		err = rows.Scan(
+			&scanner.AccountCode,
+			&scanner.AccountName,
+			&scanner.AccountType,
+			&scanner.DebitAmount,
+			&scanner.CreditAmount,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[ledgerRepository][GetTrialBalance] while scan query row. Err: %v", err))
+			return results, err
+		}
+
+		results = append(results, TrialBalanceAccount{
+			AccountCode:  scanner.AccountCode.String,
+			AccountName:  scanner.AccountName.String,
+			AccountType:  scanner.AccountType.String,
+			DebitAmount:  scanner.DebitAmount.Money,
+			CreditAmount: scanner.CreditAmount.Money,
+		})
+	}
+
+	return results, nil
+}
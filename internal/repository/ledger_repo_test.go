@@ -0,0 +1,123 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateJournalEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLedgerRepository(db)
+	postedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	entry := repository.JournalEntry{
+		ReferenceType: "loan",
+		ReferenceID:   1,
+		Description:   "Loan XYZ-1 booked",
+		PostedAt:      postedAt,
+		Lines: []repository.JournalLine{
+			{AccountCode: "1200", DebitAmount: money.New(1000)},
+			{AccountCode: "2100", CreditAmount: money.New(1000)},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO journal_entries").
+					WithArgs("loan", 1, "Loan XYZ-1 booked", postedAt).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectExec("INSERT INTO journal_lines (.+) VALUES \\(\\?, \\?, \\?, \\?, NOW\\(\\)\\), \\(\\?, \\?, \\?, \\?, NOW\\(\\)\\)").
+					WithArgs(3, "1200", money.New(1000), money.Zero, 3, "2100", money.Zero, money.New(1000)).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name: "error inserting lines",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO journal_entries").
+					WithArgs("loan", 1, "Loan XYZ-1 booked", postedAt).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectExec("INSERT INTO journal_lines").
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "error inserting entry",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO journal_entries").
+					WithArgs("loan", 1, "Loan XYZ-1 booked", postedAt).
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateJournalEntry(context.Background(), entry, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetTrialBalance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLedgerRepository(db)
+	asOf := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"account_code", "account_name", "account_type", "debit_amount", "credit_amount"}
+
+	mock.ExpectQuery("SELECT (.+) FROM ledger_accounts la LEFT JOIN (.+) je.posted_at < \\? (.+) GROUP BY").
+		WithArgs(asOf).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("1100", "Cash", "asset", "0.000", "1000.000").
+			AddRow("1200", "Loan Receivable", "asset", "1000.000", "0.000"))
+	got, err := repo.GetTrialBalance(context.Background(), asOf)
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.TrialBalanceAccount{
+		{AccountCode: "1100", AccountName: "Cash", AccountType: "asset", DebitAmount: money.Zero, CreditAmount: money.New(1000)},
+		{AccountCode: "1200", AccountName: "Loan Receivable", AccountType: "asset", DebitAmount: money.New(1000), CreditAmount: money.Zero},
+	}, got)
+
+	mock.ExpectQuery("SELECT (.+) FROM ledger_accounts").
+		WithArgs(asOf).
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.GetTrialBalance(context.Background(), asOf)
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,380 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+type LoanInstallmentRepository interface {
+	CreateLoanInstallments(ctx context.Context, installments []LoanInstallment, tx *sql.Tx) error
+	GetLoanInstallmentsByLoanID(ctx context.Context, loanID int64) ([]LoanInstallment, error)
+	UpdateLoanInstallment(ctx context.Context, req UpdateLoanInstallmentRequest, tx *sql.Tx) error
+	RescheduleLoanInstallments(ctx context.Context, loanID int64, startDate time.Time, tx *sql.Tx) error
+	CloseLoanInstallments(ctx context.Context, installmentIDs []int64, tx *sql.Tx) error
+	GetLoanIDsByNextDueAmount(ctx context.Context, amount money.Money, loanStatuses []string, limit int) ([]int64, error)
+}
+
+type loanInstallmentRepository struct {
+	db *sql.DB
+}
+
+func NewLoanInstallmentRepository(db *sql.DB) LoanInstallmentRepository {
+	return &loanInstallmentRepository{db: db}
+}
+
+type (
+	// UpdateLoanInstallmentRequest sets what was paid of an installment. InterestAmount is
+	// the interest charged, lowered by InterestRebateAmount when the loan is settled early.
+	UpdateLoanInstallmentRequest struct {
+		ID                   int64
+		InterestAmount       money.Money
+		PaidPrincipalAmount  money.Money
+		PaidInterestAmount   money.Money
+		InterestRebateAmount money.Money
+		InstallmentStatus    string
+		PaidAt               *time.Time
+	}
+
+	LoanInstallment struct {
+		ID                   int64
+		LoanID               int64
+		LoanRestructureID    int64
+		InstallmentNumber    int32
+		DueDate              time.Time
+		PrincipalAmount      money.Money
+		InterestAmount       money.Money
+		PaidPrincipalAmount  money.Money
+		PaidInterestAmount   money.Money
+		InterestRebateAmount money.Money
+		InstallmentStatus    string
+		PaidAt               time.Time
+	}
+
+	LoanInstallmentScanner struct {
+		ID                   sql.NullInt64
+		LoanID               sql.NullInt64
+		LoanRestructureID    sql.NullInt64
+		InstallmentNumber    sql.NullInt32
+		DueDate              sql.NullTime
+		PrincipalAmount      money.NullMoney
+		InterestAmount       money.NullMoney
+		PaidPrincipalAmount  money.NullMoney
+		PaidInterestAmount   money.NullMoney
+		InterestRebateAmount money.NullMoney
+		InstallmentStatus    sql.NullString
+		PaidAt               sql.NullTime
+	}
+)
+
+var (
+	ValidLoanInstallmentStatus = map[string]bool{
+		"unpaid":       true,
+		"partial":      true,
+		"paid":         true,
+		"restructured": true,
+	}
+)
+
+func (r *loanInstallmentRepository) CreateLoanInstallments(ctx context.Context, installments []LoanInstallment, tx *sql.Tx) (err error) {
+	if len(installments) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(installments))
+	args := make([]interface{}, 0, len(installments)*10)
+	for _, installment := range installments {
+		status := installment.InstallmentStatus
+		if status == "" {
+			status = "unpaid"
+		}
+
+		var paidAt *time.Time
+		if !installment.PaidAt.IsZero() {
+			installmentPaidAt := installment.PaidAt
+			paidAt = &installmentPaidAt
+		}
+
+		var loanRestructureID *int64
+		if installment.LoanRestructureID != 0 {
+			installmentLoanRestructureID := installment.LoanRestructureID
+			loanRestructureID = &installmentLoanRestructureID
+		}
+
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())")
+		args = append(args,
+			installment.LoanID,
+			loanRestructureID,
+			installment.InstallmentNumber,
+			installment.DueDate,
+			installment.PrincipalAmount,
+			installment.InterestAmount,
+			installment.PaidPrincipalAmount,
+			installment.PaidInterestAmount,
+			installment.InterestRebateAmount,
+			status,
+			paidAt,
+		)
+	}
+
+	query := `
+		INSERT INTO loan_installments (
+			loan_id,
+			loan_restructure_id,
+			installment_number,
+			due_date,
+			principal_amount,
+			interest_amount,
+			paid_principal_amount,
+			paid_interest_amount,
+			interest_rebate_amount,
+			installment_status,
+			paid_at,
+			created_at
+		) VALUES ` + strings.Join(placeholders, ", ")
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanInstallmentRepository][CreateLoanInstallments] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *loanInstallmentRepository) GetLoanInstallmentsByLoanID(ctx context.Context, loanID int64) (result []LoanInstallment, err error) {
+	query := `
+		SELECT
+			loan_installment_id,
+			loan_id,
+			loan_restructure_id,
+			installment_number,
+			due_date,
+			principal_amount,
+			interest_amount,
+			paid_principal_amount,
+			paid_interest_amount,
+			interest_rebate_amount,
+			installment_status,
+			paid_at
+		FROM loan_installments
+		WHERE deleted_at IS NULL
+		AND loan_id = ?
+		ORDER BY installment_number ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, loanID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanInstallmentRepository][GetLoanInstallmentsByLoanID] while query. Err: %v", err))
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var scanner LoanInstallmentScanner
+		err = rows.Scan(
+			&scanner.ID,
+			&scanner.LoanID,
+			&scanner.LoanRestructureID,
+			&scanner.InstallmentNumber,
+			&scanner.DueDate,
+			&scanner.PrincipalAmount,
+			&scanner.InterestAmount,
+			&scanner.PaidPrincipalAmount,
+			&scanner.PaidInterestAmount,
+			&scanner.InterestRebateAmount,
+			&scanner.InstallmentStatus,
+			&scanner.PaidAt,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[loanInstallmentRepository][GetLoanInstallmentsByLoanID] while scan query row. Err: %v", err))
+			return result, err
+		}
+
+		result = append(result, LoanInstallment{
+			ID:                   scanner.ID.Int64,
+			LoanID:               scanner.LoanID.Int64,
+			LoanRestructureID:    scanner.LoanRestructureID.Int64,
+			InstallmentNumber:    scanner.InstallmentNumber.Int32,
+			DueDate:              scanner.DueDate.Time,
+			PrincipalAmount:      scanner.PrincipalAmount.Money,
+			InterestAmount:       scanner.InterestAmount.Money,
+			PaidPrincipalAmount:  scanner.PaidPrincipalAmount.Money,
+			PaidInterestAmount:   scanner.PaidInterestAmount.Money,
+			InterestRebateAmount: scanner.InterestRebateAmount.Money,
+			InstallmentStatus:    scanner.InstallmentStatus.String,
+			PaidAt:               scanner.PaidAt.Time,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *loanInstallmentRepository) UpdateLoanInstallment(ctx context.Context, req UpdateLoanInstallmentRequest, tx *sql.Tx) (err error) {
+	query := `
+		UPDATE loan_installments
+		SET
+			interest_amount = ?,
+			paid_principal_amount = ?,
+			paid_interest_amount = ?,
+			interest_rebate_amount = ?,
+			installment_status = ?,
+			paid_at = ?,
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND loan_installment_id = ?
+	`
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query,
+			req.InterestAmount,
+			req.PaidPrincipalAmount,
+			req.PaidInterestAmount,
+			req.InterestRebateAmount,
+			req.InstallmentStatus,
+			req.PaidAt,
+			req.ID,
+		)
+	} else {
+		_, err = r.db.ExecContext(ctx, query,
+			req.InterestAmount,
+			req.PaidPrincipalAmount,
+			req.PaidInterestAmount,
+			req.InterestRebateAmount,
+			req.InstallmentStatus,
+			req.PaidAt,
+			req.ID,
+		)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanInstallmentRepository][UpdateLoanInstallment] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+// RescheduleLoanInstallments moves the due dates of a loan's installments so the n-th
+// installment falls due n months after startDate.
+func (r *loanInstallmentRepository) RescheduleLoanInstallments(ctx context.Context, loanID int64, startDate time.Time, tx *sql.Tx) (err error) {
+	query := `
+		UPDATE loan_installments
+		SET
+			due_date = DATE_ADD(DATE(?), INTERVAL installment_number MONTH),
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND loan_id = ?
+	`
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, startDate, loanID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, startDate, loanID)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanInstallmentRepository][RescheduleLoanInstallments] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+// CloseLoanInstallments marks installments replaced by a restructured schedule. Closed
+// installments keep what was paid on them but are no longer due.
+func (r *loanInstallmentRepository) CloseLoanInstallments(ctx context.Context, installmentIDs []int64, tx *sql.Tx) (err error) {
+	if len(installmentIDs) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(installmentIDs))
+	args := make([]interface{}, 0, len(installmentIDs))
+	for _, installmentID := range installmentIDs {
+		placeholders = append(placeholders, "?")
+		args = append(args, installmentID)
+	}
+
+	query := `
+		UPDATE loan_installments
+		SET
+			installment_status = 'restructured',
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND installment_status <> 'paid'
+		AND loan_installment_id IN (` + strings.Join(placeholders, ", ") + `)`
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanInstallmentRepository][CloseLoanInstallments] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+// GetLoanIDsByNextDueAmount returns, up to limit, the loans in one of loanStatuses whose
+// earliest open installment has amount left to pay.
+func (r *loanInstallmentRepository) GetLoanIDsByNextDueAmount(ctx context.Context, amount money.Money, loanStatuses []string, limit int) (result []int64, err error) {
+	if len(loanStatuses) == 0 {
+		return result, nil
+	}
+
+	placeholders := make([]string, 0, len(loanStatuses))
+	args := make([]interface{}, 0, len(loanStatuses)+2)
+	for _, loanStatus := range loanStatuses {
+		placeholders = append(placeholders, "?")
+		args = append(args, loanStatus)
+	}
+	args = append(args, amount, limit)
+
+	query := `
+		SELECT li.loan_id
+		FROM loan_installments li
+		JOIN loans l ON l.loan_id = li.loan_id
+		WHERE li.deleted_at IS NULL
+		AND l.deleted_at IS NULL
+		AND l.loan_status IN (` + strings.Join(placeholders, ", ") + `)
+		AND li.installment_number = (
+			SELECT MIN(next.installment_number)
+			FROM loan_installments next
+			WHERE next.deleted_at IS NULL
+			AND next.loan_id = li.loan_id
+			AND next.installment_status IN ('unpaid', 'partial')
+		)
+		AND li.principal_amount + li.interest_amount - li.paid_principal_amount - li.paid_interest_amount = ?
+		ORDER BY li.loan_id ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanInstallmentRepository][GetLoanIDsByNextDueAmount] while query. Err: %v", err))
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var loanID int64
+		err = rows.Scan(&loanID)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[loanInstallmentRepository][GetLoanIDsByNextDueAmount] while scan query row. Err: %v", err))
+			return result, err
+		}
+
+		result = append(result, loanID)
+	}
+
+	return result, nil
+}
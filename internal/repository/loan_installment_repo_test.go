@@ -0,0 +1,399 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateLoanInstallments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	repo := repository.NewLoanInstallmentRepository(db)
+
+	installments := []repository.LoanInstallment{
+		{LoanID: 1, InstallmentNumber: 1, DueDate: now, PrincipalAmount: money.New(500), InterestAmount: money.New(20), PaidPrincipalAmount: money.New(500), PaidInterestAmount: money.New(20), InterestRebateAmount: money.New(5), InstallmentStatus: "paid", PaidAt: now},
+		{LoanID: 1, LoanRestructureID: 3, InstallmentNumber: 2, DueDate: now, PrincipalAmount: money.New(500), InterestAmount: money.New(25)},
+	}
+
+	tests := []struct {
+		name         string
+		installments []repository.LoanInstallment
+		wantErr      bool
+		mock         func()
+	}{
+		{
+			name:         "success",
+			installments: installments,
+			wantErr:      false,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_installments").
+					WithArgs(1, nil, 1, sqlmock.AnyArg(), money.New(500), money.New(20), money.New(500), money.New(20), money.New(5), "paid", sqlmock.AnyArg(), 1, 3, 2, sqlmock.AnyArg(), money.New(500), money.New(25), money.Zero, money.Zero, money.Zero, "unpaid", nil).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+			},
+		},
+		{
+			name:         "empty installments",
+			installments: nil,
+			wantErr:      false,
+			mock:         func() {},
+		},
+		{
+			name:         "exec error",
+			installments: installments,
+			wantErr:      true,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_installments").
+					WithArgs(1, nil, 1, sqlmock.AnyArg(), money.New(500), money.New(20), money.New(500), money.New(20), money.New(5), "paid", sqlmock.AnyArg(), 1, 3, 2, sqlmock.AnyArg(), money.New(500), money.New(25), money.Zero, money.Zero, money.Zero, "unpaid", nil).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.CreateLoanInstallments(context.Background(), tt.installments, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetLoanInstallmentsByLoanID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanInstallmentRepository(db)
+	now := time.Now()
+
+	columns := []string{
+		"loan_installment_id", "loan_id", "loan_restructure_id", "installment_number", "due_date", "principal_amount", "interest_amount",
+		"paid_principal_amount", "paid_interest_amount", "interest_rebate_amount", "installment_status", "paid_at",
+	}
+
+	tests := []struct {
+		name    string
+		loanID  int64
+		want    []repository.LoanInstallment
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:   "success",
+			loanID: 1,
+			want: []repository.LoanInstallment{
+				{
+					ID:                   1,
+					LoanID:               1,
+					InstallmentNumber:    1,
+					DueDate:              now,
+					PrincipalAmount:      money.New(500),
+					InterestAmount:       money.New(20),
+					PaidPrincipalAmount:  money.New(500),
+					PaidInterestAmount:   money.New(20),
+					InterestRebateAmount: money.New(5),
+					InstallmentStatus:    "paid",
+					PaidAt:               now,
+				},
+				{
+					ID:                2,
+					LoanID:            1,
+					LoanRestructureID: 3,
+					InstallmentNumber: 2,
+					DueDate:           now,
+					PrincipalAmount:   money.New(500),
+					InterestAmount:    money.New(25),
+					InstallmentStatus: "unpaid",
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 1, nil, 1, now, 500.0, 20.0, 500.0, 20.0, 5.0, "paid", now).
+					AddRow(2, 1, 3, 2, now, 500.0, 25.0, 0.0, 0.0, 0.0, "unpaid", nil)
+				mock.ExpectQuery("SELECT (.+) FROM loan_installments WHERE deleted_at IS NULL AND loan_id = ?").
+					WithArgs(1).WillReturnRows(rows)
+			},
+		},
+		{
+			name:    "query error",
+			loanID:  2,
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM loan_installments WHERE deleted_at IS NULL AND loan_id = ?").
+					WithArgs(2).WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetLoanInstallmentsByLoanID(context.Background(), tt.loanID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUpdateLoanInstallment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanInstallmentRepository(db)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		req     repository.UpdateLoanInstallmentRequest
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "success",
+			req: repository.UpdateLoanInstallmentRequest{
+				ID:                   1,
+				InterestAmount:       money.New(20),
+				PaidPrincipalAmount:  money.New(500),
+				PaidInterestAmount:   money.New(20),
+				InterestRebateAmount: money.New(5),
+				InstallmentStatus:    "paid",
+				PaidAt:               &now,
+			},
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("UPDATE loan_installments").
+					WithArgs(money.New(20), money.New(500), money.New(20), money.New(5), "paid", sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "exec error",
+			req: repository.UpdateLoanInstallmentRequest{
+				ID:                  2,
+				PaidPrincipalAmount: money.New(500),
+				PaidInterestAmount:  money.New(25),
+				InstallmentStatus:   "paid",
+				PaidAt:              &now,
+			},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("UPDATE loan_installments").
+					WithArgs(money.Zero, money.New(500), money.New(25), money.Zero, "paid", sqlmock.AnyArg(), 2).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.UpdateLoanInstallment(context.Background(), tt.req, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRescheduleLoanInstallments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanInstallmentRepository(db)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		loanID  int64
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "success",
+			loanID:  1,
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("UPDATE loan_installments SET due_date = DATE_ADD").
+					WithArgs(now, 1).
+					WillReturnResult(sqlmock.NewResult(1, 6))
+			},
+		},
+		{
+			name:    "exec error",
+			loanID:  2,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("UPDATE loan_installments SET due_date = DATE_ADD").
+					WithArgs(now, 2).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.RescheduleLoanInstallments(context.Background(), tt.loanID, now, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCloseLoanInstallments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanInstallmentRepository(db)
+
+	tests := []struct {
+		name           string
+		installmentIDs []int64
+		wantErr        bool
+		mock           func()
+	}{
+		{
+			name:           "success",
+			installmentIDs: []int64{2, 3},
+			wantErr:        false,
+			mock: func() {
+				mock.ExpectExec("UPDATE loan_installments SET installment_status = 'restructured'").
+					WithArgs(2, 3).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+		},
+		{
+			name:           "empty installments",
+			installmentIDs: nil,
+			wantErr:        false,
+			mock:           func() {},
+		},
+		{
+			name:           "exec error",
+			installmentIDs: []int64{4},
+			wantErr:        true,
+			mock: func() {
+				mock.ExpectExec("UPDATE loan_installments SET installment_status = 'restructured'").
+					WithArgs(4).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.CloseLoanInstallments(context.Background(), tt.installmentIDs, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetLoanIDsByNextDueAmount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanInstallmentRepository(db)
+	statuses := []string{"disbursed", "on_going", "late"}
+	query := "SELECT li.loan_id FROM loan_installments li JOIN loans l (.+) AND l.loan_status IN \\(\\?, \\?, \\?\\) (.+) LIMIT \\?"
+
+	tests := []struct {
+		name     string
+		statuses []string
+		mock     func()
+		want     []int64
+		wantErr  bool
+	}{
+		{
+			name:     "success",
+			statuses: statuses,
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs("disbursed", "on_going", "late", money.New(150000), 5).
+					WillReturnRows(sqlmock.NewRows([]string{"loan_id"}).AddRow(1).AddRow(4))
+			},
+			want:    []int64{1, 4},
+			wantErr: false,
+		},
+		{
+			name:     "no statuses",
+			statuses: nil,
+			mock:     func() {},
+			want:     nil,
+			wantErr:  false,
+		},
+		{
+			name:     "query error",
+			statuses: statuses,
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs("disbursed", "on_going", "late", money.New(150000), 5).
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetLoanIDsByNextDueAmount(context.Background(), money.New(150000), tt.statuses, 5)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+type LoanPenaltyRepository interface {
+	GetLoanPenaltiesByLoanID(ctx context.Context, loanID int64) ([]LoanPenalty, error)
+	UpsertLoanPenalty(ctx context.Context, penalty LoanPenalty, tx *sql.Tx) error
+}
+
+type loanPenaltyRepository struct {
+	db *sql.DB
+}
+
+func NewLoanPenaltyRepository(db *sql.DB) LoanPenaltyRepository {
+	return &loanPenaltyRepository{db: db}
+}
+
+type (
+	LoanPenalty struct {
+		ID                int64
+		LoanID            int64
+		InstallmentNumber int32
+		OverdueDays       int32
+		PenaltyAmount     money.Money
+		PaidPenaltyAmount money.Money
+		AccruedUntil      time.Time
+	}
+
+	LoanPenaltyScanner struct {
+		ID                sql.NullInt64
+		LoanID            sql.NullInt64
+		InstallmentNumber sql.NullInt32
+		OverdueDays       sql.NullInt32
+		PenaltyAmount     money.NullMoney
+		PaidPenaltyAmount money.NullMoney
+		AccruedUntil      sql.NullTime
+	}
+)
+
+func (r *loanPenaltyRepository) GetLoanPenaltiesByLoanID(ctx context.Context, loanID int64) (result []LoanPenalty, err error) {
+	query := `
+		SELECT
+			loan_penalty_id,
+			loan_id,
+			installment_number,
+			overdue_days,
+			penalty_amount,
+			paid_penalty_amount,
+			accrued_until
+		FROM loan_penalties
+		WHERE deleted_at IS NULL
+		AND loan_id = ?
+		ORDER BY installment_number ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, loanID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanPenaltyRepository][GetLoanPenaltiesByLoanID] while query. Err: %v", err))
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var scanner LoanPenaltyScanner
+		err = rows.Scan(
+			&scanner.ID,
+			&scanner.LoanID,
+			&scanner.InstallmentNumber,
+			&scanner.OverdueDays,
+			&scanner.PenaltyAmount,
+			&scanner.PaidPenaltyAmount,
+			&scanner.AccruedUntil,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[loanPenaltyRepository][GetLoanPenaltiesByLoanID] while scan query row. Err: %v", err))
+			return result, err
+		}
+
+		result = append(result, LoanPenalty{
+			ID:                scanner.ID.Int64,
+			LoanID:            scanner.LoanID.Int64,
+			InstallmentNumber: scanner.InstallmentNumber.Int32,
+			OverdueDays:       scanner.OverdueDays.Int32,
+			PenaltyAmount:     scanner.PenaltyAmount.Money,
+			PaidPenaltyAmount: scanner.PaidPenaltyAmount.Money,
+			AccruedUntil:      scanner.AccruedUntil.Time,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *loanPenaltyRepository) UpsertLoanPenalty(ctx context.Context, penalty LoanPenalty, tx *sql.Tx) (err error) {
+	query := `
+		INSERT INTO loan_penalties (
+			loan_id,
+			installment_number,
+			overdue_days,
+			penalty_amount,
+			paid_penalty_amount,
+			accrued_until,
+			created_at
+		) VALUES (?, ?, ?, ?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE
+			overdue_days = VALUES(overdue_days),
+			penalty_amount = VALUES(penalty_amount),
+			paid_penalty_amount = VALUES(paid_penalty_amount),
+			accrued_until = VALUES(accrued_until),
+			updated_at = NOW()
+	`
+
+	args := []interface{}{
+		penalty.LoanID,
+		penalty.InstallmentNumber,
+		penalty.OverdueDays,
+		penalty.PenaltyAmount,
+		penalty.PaidPenaltyAmount,
+		penalty.AccruedUntil,
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanPenaltyRepository][UpsertLoanPenalty] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLoanPenaltiesByLoanID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanPenaltyRepository(db)
+	now := time.Now()
+
+	columns := []string{
+		"loan_penalty_id", "loan_id", "installment_number", "overdue_days", "penalty_amount", "paid_penalty_amount", "accrued_until",
+	}
+
+	tests := []struct {
+		name    string
+		loanID  int64
+		want    []repository.LoanPenalty
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:   "success",
+			loanID: 1,
+			want: []repository.LoanPenalty{
+				{ID: 1, LoanID: 1, InstallmentNumber: 1, OverdueDays: 3, PenaltyAmount: money.New(30), PaidPenaltyAmount: money.New(30), AccruedUntil: now},
+				{ID: 2, LoanID: 1, InstallmentNumber: 2, OverdueDays: 1, PenaltyAmount: money.New(10), AccruedUntil: now},
+			},
+			wantErr: false,
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 1, 1, 3, 30.0, 30.0, now).
+					AddRow(2, 1, 2, 1, 10.0, 0.0, now)
+				mock.ExpectQuery("SELECT (.+) FROM loan_penalties WHERE deleted_at IS NULL AND loan_id = ?").
+					WithArgs(1).WillReturnRows(rows)
+			},
+		},
+		{
+			name:    "query error",
+			loanID:  2,
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM loan_penalties WHERE deleted_at IS NULL AND loan_id = ?").
+					WithArgs(2).WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetLoanPenaltiesByLoanID(context.Background(), tt.loanID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUpsertLoanPenalty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanPenaltyRepository(db)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		penalty repository.LoanPenalty
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "success",
+			penalty: repository.LoanPenalty{LoanID: 1, InstallmentNumber: 1, OverdueDays: 3, PenaltyAmount: money.New(30), AccruedUntil: now},
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_penalties (.+) ON DUPLICATE KEY UPDATE").
+					WithArgs(1, 1, 3, money.New(30), money.Zero, now).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "exec error",
+			penalty: repository.LoanPenalty{LoanID: 2, InstallmentNumber: 1, OverdueDays: 3, PenaltyAmount: money.New(30), AccruedUntil: now},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_penalties (.+) ON DUPLICATE KEY UPDATE").
+					WithArgs(2, 1, 3, money.New(30), money.Zero, now).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.UpsertLoanPenalty(context.Background(), tt.penalty, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,516 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/go-sql-driver/mysql"
+)
+
+type LoanProductRepository interface {
+	BeginTx(ctx context.Context) (*sql.Tx, error)
+	CommitTx(ctx context.Context, tx *sql.Tx) error
+	RollbackTx(ctx context.Context, tx *sql.Tx) error
+	CreateLoanProduct(ctx context.Context, product LoanProduct, tx *sql.Tx) (id int64, err error)
+	UpdateLoanProduct(ctx context.Context, product LoanProduct, tx *sql.Tx) (err error)
+	DeleteLoanProduct(ctx context.Context, id int64) (err error)
+	GetLoanProductByID(ctx context.Context, id int64) (result LoanProduct, err error)
+	GetLoanProductByCode(ctx context.Context, productCode string) (result LoanProduct, err error)
+	FetchLoanProducts(ctx context.Context, req FetchLoanProductsRequest) (results []LoanProduct, err error)
+	CreateLoanProductTenures(ctx context.Context, tenures []LoanProductTenure, tx *sql.Tx) (err error)
+	DeleteLoanProductTenures(ctx context.Context, productID int64, tx *sql.Tx) (err error)
+	GetLoanProductTenures(ctx context.Context, productIDs []int64) (results []LoanProductTenure, err error)
+	GetOfferedTenures(ctx context.Context) (results []int16, err error)
+}
+
+type loanProductRepository struct {
+	db *sql.DB
+}
+
+func NewLoanProductRepository(db *sql.DB) LoanProductRepository {
+	return &loanProductRepository{db: db}
+}
+
+// ErrDuplicateLoanProductCode is returned when a loan product is created with the code of
+// another product.
+var ErrDuplicateLoanProductCode = errors.New("loan product code already exists")
+
+type (
+	FetchLoanProductsRequest struct {
+		Limit  int
+		Offset int
+	}
+
+	// LoanProduct defines the terms a loan is granted on. InsuranceRate is the credit insurance
+	// premium in percent of the financed amount, a zero rate offers no insurance. A zero
+	// MaxLoanAmount has no maximum and a zero ValidUntil keeps the product offered indefinitely.
+	LoanProduct struct {
+		ID             int64
+		ProductCode    string
+		ProductName    string
+		InterestMethod string
+		AdminFeeAmount money.Money
+		InsuranceRate  float64
+		MinLoanAmount  money.Money
+		MaxLoanAmount  money.Money
+		ValidFrom      time.Time
+		ValidUntil     time.Time
+		CreatedAt      time.Time
+	}
+
+	LoanProductScanner struct {
+		ID             sql.NullInt64
+		ProductCode    sql.NullString
+		ProductName    sql.NullString
+		InterestMethod sql.NullString
+		AdminFeeAmount money.NullMoney
+		InsuranceRate  sql.NullFloat64
+		MinLoanAmount  money.NullMoney
+		MaxLoanAmount  money.NullMoney
+		ValidFrom      sql.NullTime
+		ValidUntil     sql.NullTime
+		CreatedAt      sql.NullTime
+	}
+
+	// LoanProductTenure is a tenure offered by a loan product with its yearly interest rate.
+	LoanProductTenure struct {
+		ID            int64
+		LoanProductID int64
+		Tenure        int16
+		InterestRate  float64
+	}
+
+	LoanProductTenureScanner struct {
+		ID            sql.NullInt64
+		LoanProductID sql.NullInt64
+		Tenure        sql.NullInt16
+		InterestRate  sql.NullFloat64
+	}
+)
+
+func (r *loanProductRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][BeginTx] while begin sql transaction. Err: %v", err))
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (r *loanProductRepository) CommitTx(ctx context.Context, tx *sql.Tx) error {
+	err := tx.Commit()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][CommitTx] while commit sql transaction. Err: %v", err))
+		return err
+	}
+	return nil
+}
+
+func (r *loanProductRepository) RollbackTx(ctx context.Context, tx *sql.Tx) error {
+	err := tx.Rollback()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][RollbackTx] while rollback sql transaction. Err: %v", err))
+		return err
+	}
+	return nil
+}
+
+// loanProductArgs returns the nullable columns of a product, the maximum amount and the end
+// of validity being left NULL when unset.
+func loanProductArgs(product LoanProduct) (maxLoanAmount *money.Money, validUntil *string) {
+	if !product.MaxLoanAmount.IsZero() {
+		maxLoanAmount = &product.MaxLoanAmount
+	}
+	if !product.ValidUntil.IsZero() {
+		date := product.ValidUntil.Format("2006-01-02")
+		validUntil = &date
+	}
+	return maxLoanAmount, validUntil
+}
+
+func (r *loanProductRepository) CreateLoanProduct(ctx context.Context, product LoanProduct, tx *sql.Tx) (id int64, err error) {
+	query := `
+		INSERT INTO loan_products (
+			product_code,
+			product_name,
+			interest_method,
+			admin_fee_amount,
+			insurance_rate,
+			min_loan_amount,
+			max_loan_amount,
+			valid_from,
+			valid_until,
+			created_at,
+			updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+
+	maxLoanAmount, validUntil := loanProductArgs(product)
+	args := []interface{}{
+		product.ProductCode,
+		product.ProductName,
+		product.InterestMethod,
+		product.AdminFeeAmount,
+		product.InsuranceRate,
+		product.MinLoanAmount,
+		maxLoanAmount,
+		product.ValidFrom.Format("2006-01-02"),
+		validUntil,
+	}
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return id, ErrDuplicateLoanProductCode
+		}
+
+		logger.Error(fmt.Sprintf("[loanProductRepository][CreateLoanProduct] while exec query. Err: %v", err))
+		return id, err
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][CreateLoanProduct] while get last insert id. Err: %v", err))
+		return id, err
+	}
+
+	return id, nil
+}
+
+// UpdateLoanProduct updates the terms of a product. The product code is kept, loans refer to
+// the product by its code.
+func (r *loanProductRepository) UpdateLoanProduct(ctx context.Context, product LoanProduct, tx *sql.Tx) (err error) {
+	query := `
+		UPDATE loan_products
+		SET
+			product_name = ?,
+			interest_method = ?,
+			admin_fee_amount = ?,
+			insurance_rate = ?,
+			min_loan_amount = ?,
+			max_loan_amount = ?,
+			valid_from = ?,
+			valid_until = ?,
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND loan_product_id = ?
+	`
+
+	maxLoanAmount, validUntil := loanProductArgs(product)
+	args := []interface{}{
+		product.ProductName,
+		product.InterestMethod,
+		product.AdminFeeAmount,
+		product.InsuranceRate,
+		product.MinLoanAmount,
+		maxLoanAmount,
+		product.ValidFrom.Format("2006-01-02"),
+		validUntil,
+		product.ID,
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][UpdateLoanProduct] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *loanProductRepository) DeleteLoanProduct(ctx context.Context, id int64) (err error) {
+	query := `
+		UPDATE loan_products
+		SET
+			deleted_at = NOW()
+		WHERE loan_product_id = ?
+	`
+
+	_, err = r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][DeleteLoanProduct] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *loanProductRepository) GetLoanProductByID(ctx context.Context, id int64) (result LoanProduct, err error) {
+	return r.getLoanProduct(ctx, "GetLoanProductByID", "loan_product_id = ?", id)
+}
+
+func (r *loanProductRepository) GetLoanProductByCode(ctx context.Context, productCode string) (result LoanProduct, err error) {
+	return r.getLoanProduct(ctx, "GetLoanProductByCode", "product_code = ?", productCode)
+}
+
+func (r *loanProductRepository) getLoanProduct(ctx context.Context, method string, condition string, arg interface{}) (result LoanProduct, err error) {
+	query := `
+		SELECT
+			loan_product_id,
+			product_code,
+			product_name,
+			interest_method,
+			admin_fee_amount,
+			insurance_rate,
+			min_loan_amount,
+			max_loan_amount,
+			valid_from,
+			valid_until,
+			created_at
+		FROM loan_products
+		WHERE deleted_at IS NULL
+		AND ` + condition + `
+		LIMIT 1
+	`
+
+	var productScanner LoanProductScanner
+	err = r.db.QueryRowContext(ctx, query, arg).Scan(
+		&productScanner.ID,
+		&productScanner.ProductCode,
+		&productScanner.ProductName,
+		&productScanner.InterestMethod,
+		&productScanner.AdminFeeAmount,
+		&productScanner.InsuranceRate,
+		&productScanner.MinLoanAmount,
+		&productScanner.MaxLoanAmount,
+		&productScanner.ValidFrom,
+		&productScanner.ValidUntil,
+		&productScanner.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return result, nil
+		}
+
+		logger.Error(fmt.Sprintf("[loanProductRepository][%s] while scan query row. Err: %v", method, err))
+		return result, err
+	}
+
+	return productScanner.toLoanProduct(), nil
+}
+
+func (r *loanProductRepository) FetchLoanProducts(ctx context.Context, req FetchLoanProductsRequest) (results []LoanProduct, err error) {
+	query := `
+		SELECT
+			loan_product_id,
+			product_code,
+			product_name,
+			interest_method,
+			admin_fee_amount,
+			insurance_rate,
+			min_loan_amount,
+			max_loan_amount,
+			valid_from,
+			valid_until,
+			created_at
+		FROM loan_products
+		WHERE deleted_at IS NULL
+		ORDER BY loan_product_id
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, req.Limit, req.Offset)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][FetchLoanProducts] while query. Err: %v", err))
+		return results, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productScanner LoanProductScanner
+		err = rows.Scan(
+			&productScanner.ID,
+			&productScanner.ProductCode,
+			&productScanner.ProductName,
+			&productScanner.InterestMethod,
+			&productScanner.AdminFeeAmount,
+			&productScanner.InsuranceRate,
+			&productScanner.MinLoanAmount,
+			&productScanner.MaxLoanAmount,
+			&productScanner.ValidFrom,
+			&productScanner.ValidUntil,
+			&productScanner.CreatedAt,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[loanProductRepository][FetchLoanProducts] while scan query row. Err: %v", err))
+			return results, err
+		}
+
+		results = append(results, productScanner.toLoanProduct())
+	}
+
+	return results, nil
+}
+
+func (s LoanProductScanner) toLoanProduct() LoanProduct {
+	return LoanProduct{
+		ID:             s.ID.Int64,
+		ProductCode:    s.ProductCode.String,
+		ProductName:    s.ProductName.String,
+		InterestMethod: s.InterestMethod.String,
+		AdminFeeAmount: s.AdminFeeAmount.Money,
+		InsuranceRate:  s.InsuranceRate.Float64,
+		MinLoanAmount:  s.MinLoanAmount.Money,
+		MaxLoanAmount:  s.MaxLoanAmount.Money,
+		ValidFrom:      s.ValidFrom.Time,
+		ValidUntil:     s.ValidUntil.Time,
+		CreatedAt:      s.CreatedAt.Time,
+	}
+}
+
+func (r *loanProductRepository) CreateLoanProductTenures(ctx context.Context, tenures []LoanProductTenure, tx *sql.Tx) (err error) {
+	if len(tenures) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(tenures))
+	args := make([]interface{}, 0, len(tenures)*3)
+	for _, tenure := range tenures {
+		placeholders = append(placeholders, "(?, ?, ?, NOW())")
+		args = append(args, tenure.LoanProductID, tenure.Tenure, tenure.InterestRate)
+	}
+
+	query := `
+		INSERT INTO loan_product_tenures (
+			loan_product_id,
+			tenure,
+			interest_rate,
+			created_at
+		) VALUES ` + strings.Join(placeholders, ", ")
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][CreateLoanProductTenures] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteLoanProductTenures removes the tenures of a product before they are replaced.
+func (r *loanProductRepository) DeleteLoanProductTenures(ctx context.Context, productID int64, tx *sql.Tx) (err error) {
+	query := `
+		DELETE FROM loan_product_tenures
+		WHERE loan_product_id = ?
+	`
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, productID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, productID)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][DeleteLoanProductTenures] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *loanProductRepository) GetLoanProductTenures(ctx context.Context, productIDs []int64) (results []LoanProductTenure, err error) {
+	if len(productIDs) == 0 {
+		return results, nil
+	}
+
+	placeholders := make([]string, 0, len(productIDs))
+	args := make([]interface{}, 0, len(productIDs))
+	for _, productID := range productIDs {
+		placeholders = append(placeholders, "?")
+		args = append(args, productID)
+	}
+
+	query := `
+		SELECT
+			loan_product_tenure_id,
+			loan_product_id,
+			tenure,
+			interest_rate
+		FROM loan_product_tenures
+		WHERE loan_product_id IN (` + strings.Join(placeholders, ", ") + `)
+		ORDER BY loan_product_id, tenure
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][GetLoanProductTenures] while query. Err: %v", err))
+		return results, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tenureScanner LoanProductTenureScanner
+		err = rows.Scan(
+			&tenureScanner.ID,
+			&tenureScanner.LoanProductID,
+			&tenureScanner.Tenure,
+			&tenureScanner.InterestRate,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[loanProductRepository][GetLoanProductTenures] while scan query row. Err: %v", err))
+			return results, err
+		}
+
+		results = append(results, LoanProductTenure{
+			ID:            tenureScanner.ID.Int64,
+			LoanProductID: tenureScanner.LoanProductID.Int64,
+			Tenure:        tenureScanner.Tenure.Int16,
+			InterestRate:  tenureScanner.InterestRate.Float64,
+		})
+	}
+
+	return results, nil
+}
+
+// GetOfferedTenures returns the tenures offered by the products that are not expired, the
+// tenures consumer limits can be set for.
+func (r *loanProductRepository) GetOfferedTenures(ctx context.Context) (results []int16, err error) {
+	query := `
+		SELECT DISTINCT
+			t.tenure
+		FROM loan_product_tenures t
+		JOIN loan_products p ON p.loan_product_id = t.loan_product_id
+		WHERE p.deleted_at IS NULL
+		AND (p.valid_until IS NULL OR p.valid_until >= CURDATE())
+		ORDER BY t.tenure
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanProductRepository][GetOfferedTenures] while query. Err: %v", err))
+		return results, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tenure sql.NullInt16
+		err = rows.Scan(&tenure)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[loanProductRepository][GetOfferedTenures] while scan query row. Err: %v", err))
+			return results, err
+		}
+
+		results = append(results, tenure.Int16)
+	}
+
+	return results, nil
+}
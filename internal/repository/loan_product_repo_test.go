@@ -0,0 +1,540 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+var loanProductColumns = []string{
+	"loan_product_id", "product_code", "product_name", "interest_method", "admin_fee_amount",
+	"insurance_rate", "min_loan_amount", "max_loan_amount", "valid_from", "valid_until", "created_at",
+}
+
+func TestCreateLoanProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanProductRepository(db)
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	trx, _ := db.Begin()
+
+	tests := []struct {
+		name      string
+		input     repository.LoanProduct
+		mock      func()
+		want      int64
+		wantErr   bool
+		wantErrIs error
+	}{
+		{
+			name: "success",
+			input: repository.LoanProduct{
+				ProductCode:    "MOTOR",
+				ProductName:    "Motorcycle",
+				InterestMethod: "flat",
+				AdminFeeAmount: money.New(50000),
+				InsuranceRate:  1.5,
+				MinLoanAmount:  money.New(1000000),
+				MaxLoanAmount:  money.New(30000000),
+				ValidFrom:      validFrom,
+				ValidUntil:     time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+			},
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_products").
+					WithArgs("MOTOR", "Motorcycle", "flat", money.New(50000), 1.5, money.New(1000000), money.New(30000000), "2024-01-01", "2024-12-31").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "success without maximum amount and end of validity",
+			input: repository.LoanProduct{
+				ProductCode:    "CAR",
+				ProductName:    "Car",
+				InterestMethod: "annuity",
+				ValidFrom:      validFrom,
+			},
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_products").
+					WithArgs("CAR", "Car", "annuity", money.Zero, 0.0, money.Zero, nil, "2024-01-01", nil).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name: "duplicate product code",
+			input: repository.LoanProduct{
+				ProductCode:    "MOTOR",
+				ProductName:    "Motorcycle",
+				InterestMethod: "flat",
+				ValidFrom:      validFrom,
+			},
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_products").
+					WithArgs("MOTOR", "Motorcycle", "flat", money.Zero, 0.0, money.Zero, nil, "2024-01-01", nil).
+					WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'MOTOR' for key 'product_code'"})
+			},
+			want:      0,
+			wantErr:   true,
+			wantErrIs: repository.ErrDuplicateLoanProductCode,
+		},
+		{
+			name: "exec error",
+			input: repository.LoanProduct{
+				ProductCode:    "MOTOR",
+				ProductName:    "Motorcycle",
+				InterestMethod: "flat",
+				ValidFrom:      validFrom,
+			},
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_products").
+					WithArgs("MOTOR", "Motorcycle", "flat", money.Zero, 0.0, money.Zero, nil, "2024-01-01", nil).
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateLoanProduct(context.Background(), tt.input, trx)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateLoanProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanProductRepository(db)
+	product := repository.LoanProduct{
+		ID:             1,
+		ProductName:    "Motorcycle",
+		InterestMethod: "flat",
+		AdminFeeAmount: money.New(50000),
+		MinLoanAmount:  money.New(1000000),
+		ValidFrom:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectExec("UPDATE loan_products SET (.+) WHERE deleted_at IS NULL AND loan_product_id = \\?").
+					WithArgs("Motorcycle", "flat", money.New(50000), 0.0, money.New(1000000), nil, "2024-01-01", nil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "exec error",
+			mock: func() {
+				mock.ExpectExec("UPDATE loan_products").
+					WithArgs("Motorcycle", "flat", money.New(50000), 0.0, money.New(1000000), nil, "2024-01-01", nil, 1).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.UpdateLoanProduct(context.Background(), product, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteLoanProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanProductRepository(db)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectExec("UPDATE loan_products SET deleted_at = NOW\\(\\) WHERE loan_product_id = \\?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "exec error",
+			mock: func() {
+				mock.ExpectExec("UPDATE loan_products").
+					WithArgs(1).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.DeleteLoanProduct(context.Background(), 1)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetLoanProductByCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanProductRepository(db)
+	query := "SELECT (.+) FROM loan_products WHERE deleted_at IS NULL AND product_code = \\? LIMIT 1"
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    repository.LoanProduct
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				rows := sqlmock.NewRows(loanProductColumns).
+					AddRow(1, "MOTOR", "Motorcycle", "flat", "50000.000", 1.5, "1000000.000", nil, validFrom, nil, createdAt)
+				mock.ExpectQuery(query).WithArgs("MOTOR").WillReturnRows(rows)
+			},
+			want: repository.LoanProduct{
+				ID:             1,
+				ProductCode:    "MOTOR",
+				ProductName:    "Motorcycle",
+				InterestMethod: "flat",
+				AdminFeeAmount: money.New(50000),
+				InsuranceRate:  1.5,
+				MinLoanAmount:  money.New(1000000),
+				ValidFrom:      validFrom,
+				CreatedAt:      createdAt,
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs("MOTOR").WillReturnError(sql.ErrNoRows)
+			},
+			want:    repository.LoanProduct{},
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs("MOTOR").WillReturnError(sql.ErrConnDone)
+			},
+			want:    repository.LoanProduct{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetLoanProductByCode(context.Background(), "MOTOR")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFetchLoanProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanProductRepository(db)
+	query := "SELECT (.+) FROM loan_products WHERE deleted_at IS NULL ORDER BY loan_product_id LIMIT \\? OFFSET \\?"
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	validUntil := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []repository.LoanProduct
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				rows := sqlmock.NewRows(loanProductColumns).
+					AddRow(1, "MOTOR", "Motorcycle", "flat", "50000.000", 0.0, "0.000", "30000000.000", validFrom, validUntil, validFrom)
+				mock.ExpectQuery(query).WithArgs(10, 0).WillReturnRows(rows)
+			},
+			want: []repository.LoanProduct{
+				{
+					ID:             1,
+					ProductCode:    "MOTOR",
+					ProductName:    "Motorcycle",
+					InterestMethod: "flat",
+					AdminFeeAmount: money.New(50000),
+					MinLoanAmount:  money.Zero,
+					MaxLoanAmount:  money.New(30000000),
+					ValidFrom:      validFrom,
+					ValidUntil:     validUntil,
+					CreatedAt:      validFrom,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(10, 0).WillReturnError(sql.ErrConnDone)
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.FetchLoanProducts(context.Background(), repository.FetchLoanProductsRequest{Limit: 10, Offset: 0})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCreateLoanProductTenures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanProductRepository(db)
+	tenures := []repository.LoanProductTenure{
+		{LoanProductID: 1, Tenure: 6, InterestRate: 12},
+		{LoanProductID: 1, Tenure: 12, InterestRate: 14.5},
+	}
+
+	mock.ExpectBegin()
+	trx, _ := db.Begin()
+
+	tests := []struct {
+		name    string
+		input   []repository.LoanProductTenure
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:  "success",
+			input: tenures,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_product_tenures (.+) VALUES \\(\\?, \\?, \\?, NOW\\(\\)\\), \\(\\?, \\?, \\?, NOW\\(\\)\\)").
+					WithArgs(1, 6, 12.0, 1, 12, 14.5).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+			},
+			wantErr: false,
+		},
+		{
+			name:    "no tenure",
+			input:   nil,
+			mock:    func() {},
+			wantErr: false,
+		},
+		{
+			name:  "exec error",
+			input: tenures,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_product_tenures").
+					WithArgs(1, 6, 12.0, 1, 12, 14.5).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.CreateLoanProductTenures(context.Background(), tt.input, trx)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetLoanProductTenures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanProductRepository(db)
+	query := "SELECT (.+) FROM loan_product_tenures WHERE loan_product_id IN \\(\\?, \\?\\) ORDER BY loan_product_id, tenure"
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []repository.LoanProductTenure
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"loan_product_tenure_id", "loan_product_id", "tenure", "interest_rate"}).
+					AddRow(1, 1, 6, 12.0).
+					AddRow(3, 2, 12, 14.5)
+				mock.ExpectQuery(query).WithArgs(1, 2).WillReturnRows(rows)
+			},
+			want: []repository.LoanProductTenure{
+				{ID: 1, LoanProductID: 1, Tenure: 6, InterestRate: 12},
+				{ID: 3, LoanProductID: 2, Tenure: 12, InterestRate: 14.5},
+			},
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(1, 2).WillReturnError(sql.ErrConnDone)
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetLoanProductTenures(context.Background(), []int64{1, 2})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetOfferedTenures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanProductRepository(db)
+	query := "SELECT DISTINCT t.tenure FROM loan_product_tenures t JOIN loan_products p (.+) WHERE p.deleted_at IS NULL"
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []int16
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"tenure"}).AddRow(6).AddRow(12).AddRow(24)
+				mock.ExpectQuery(query).WillReturnRows(rows)
+			},
+			want:    []int16{6, 12, 24},
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WillReturnError(sql.ErrConnDone)
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetOfferedTenures(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
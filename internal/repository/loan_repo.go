@@ -3,20 +3,39 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 )
 
 type LoanRepository interface {
-	CreateLoan(ctx context.Context, loan Loan) (int64, error)
+	BeginTx(ctx context.Context) (*sql.Tx, error)
+	CommitTx(ctx context.Context, tx *sql.Tx) error
+	RollbackTx(ctx context.Context, tx *sql.Tx) error
+	CreateLoan(ctx context.Context, loan Loan, tx *sql.Tx) (int64, error)
 	UpdateLoan(ctx context.Context, req UpdateLoanRequest, tx *sql.Tx) error
 	GetLoanByID(ctx context.Context, loanID int64) (Loan, error)
-	DeleteLoan(ctx context.Context, loanID int64) error
+	GetLoanIDByContractNumber(ctx context.Context, contractNumber string) (int64, error)
+	LockLoanByID(ctx context.Context, loanID int64, tx *sql.Tx) (bool, error)
+	HasLoanTransactions(ctx context.Context, loanID int64, tx *sql.Tx) (bool, error)
+	DeleteLoan(ctx context.Context, loanID int64, tx *sql.Tx) error
 	GetLoanByConsumerID(ctx context.Context, consumerID int64) ([]Loan, error)
+	FetchLoans(ctx context.Context, req FetchLoansRequest) ([]Loan, int64, error)
+	GetActiveLoans(ctx context.Context) ([]Loan, error)
+	UpdateLoanDaysPastDue(ctx context.Context, req UpdateLoanDaysPastDueRequest, tx *sql.Tx) error
+	UpdateLoanStatus(ctx context.Context, req UpdateLoanStatusRequest, tx *sql.Tx) error
+	DisburseLoan(ctx context.Context, req DisburseLoanRequest, tx *sql.Tx) error
+	RestructureLoan(ctx context.Context, req RestructureLoanRequest, tx *sql.Tx) error
 }
 
+// ErrLoanStatusConflict is returned when the status of a loan is changed from a status the
+// loan no longer has, because another change got to it first.
+var ErrLoanStatusConflict = errors.New("loan status was changed by another request")
+
 type loanRepository struct {
 	db *sql.DB
 }
@@ -25,13 +44,97 @@ func NewLoanRepository(db *sql.DB) LoanRepository {
 	return &loanRepository{db: db}
 }
 
+func (r *loanRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][BeginTx] while begin sql transaction. Err: %v", err))
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (r *loanRepository) CommitTx(ctx context.Context, tx *sql.Tx) error {
+	err := tx.Commit()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][CommitTx] while commit sql transaction. Err: %v", err))
+		return err
+	}
+	return nil
+}
+
+func (r *loanRepository) RollbackTx(ctx context.Context, tx *sql.Tx) error {
+	err := tx.Rollback()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][RollbackTx] while rollback sql transaction. Err: %v", err))
+		return err
+	}
+	return nil
+}
+
 type (
+	// UpdateLoanRequest sets what was paid of a loan. InterestAmount is the interest
+	// charged, lowered by the rebate of an early settlement.
 	UpdateLoanRequest struct {
 		ID                 int64
-		PaidLoanAmount     float64
-		PaidInterestAmount float64
+		InterestAmount     money.Money
+		PaidLoanAmount     money.Money
+		PaidInterestAmount money.Money
 		LoanStatus         string
-		Installment        int32
+	}
+
+	// UpdateLoanDaysPastDueRequest moves a loan from FromStatus and FromDaysPastDue, what
+	// the days past due were computed from, to LoanStatus and DaysPastDue.
+	UpdateLoanDaysPastDueRequest struct {
+		ID              int64
+		FromStatus      string
+		FromDaysPastDue int32
+		LoanStatus      string
+		DaysPastDue     int32
+	}
+
+	// UpdateLoanStatusRequest moves a loan from FromStatus to LoanStatus.
+	UpdateLoanStatusRequest struct {
+		ID           int64
+		FromStatus   string
+		LoanStatus   string
+		StatusReason string
+	}
+
+	DisburseLoanRequest struct {
+		ID          int64
+		DisbursedAt time.Time
+		Tenure      int16
+	}
+
+	RestructureLoanRequest struct {
+		ID              int64
+		ConsumerLimitID int64
+		LoanAmount      money.Money
+		InterestAmount  money.Money
+		LoanStatus      string
+		DaysPastDue     int32
+		DueDate         time.Time
+	}
+
+	// FetchLoansRequest filters loans on the fields that are set. The From bounds are
+	// inclusive and the To bounds exclusive, the outstanding amount bounds are both inclusive.
+	// SortBy is created_at, due_date, loan_amount or outstanding_amount, SortOrder asc or
+	// desc, and they default to the newest loan first.
+	FetchLoansRequest struct {
+		LoanStatuses         []string
+		MerchantID           int64
+		ConsumerID           int64
+		ContractNumber       string
+		CreatedFrom          time.Time
+		CreatedTo            time.Time
+		DueFrom              time.Time
+		DueTo                time.Time
+		MinOutstandingAmount money.NullMoney
+		MaxOutstandingAmount money.NullMoney
+		SortBy               string
+		SortOrder            string
+		Limit                int
+		Offset               int
 	}
 
 	Loan struct {
@@ -39,15 +142,25 @@ type (
 		ConsumerLimitID    int64
 		ConsumerID         int64
 		MerchantID         int64
-		LoanAmount         float64
-		PaidLoanAmount     float64
+		LoanProductID      int64
+		AssetPrice         money.Money
+		DownPaymentAmount  money.Money
+		LoanAmount         money.Money
+		PaidLoanAmount     money.Money
 		ContractNumber     string
 		InterestRate       float64
-		InterestAmount     float64
-		PaidInterestAmount float64
+		InterestMethod     string
+		InterestAmount     money.Money
+		PaidInterestAmount money.Money
+		AdminFeeAmount     money.Money
+		InsuranceAmount    money.Money
+		FeePayment         string
 		LoanStatus         string
+		StatusReason       string
+		DisbursedAt        time.Time
 		DueDate            time.Time
 		Installment        int32
+		DaysPastDue        int32
 		AssetName          string
 		CreatedAt          time.Time
 		UpdatedAt          time.Time
@@ -58,56 +171,107 @@ type (
 		ConsumerLimitID    sql.NullInt64
 		ConsumerID         sql.NullInt64
 		MerchantID         sql.NullInt64
-		LoanAmount         sql.NullFloat64
-		PaidLoanAmount     sql.NullFloat64
+		LoanProductID      sql.NullInt64
+		AssetPrice         money.NullMoney
+		DownPaymentAmount  money.NullMoney
+		LoanAmount         money.NullMoney
+		PaidLoanAmount     money.NullMoney
 		ContractNumber     sql.NullString
 		InterestRate       sql.NullFloat64
-		InterestAmount     sql.NullFloat64
-		PaidInterestAmount sql.NullFloat64
+		InterestMethod     sql.NullString
+		InterestAmount     money.NullMoney
+		PaidInterestAmount money.NullMoney
+		AdminFeeAmount     money.NullMoney
+		InsuranceAmount    money.NullMoney
+		FeePayment         sql.NullString
 		LoanStatus         sql.NullString
+		StatusReason       sql.NullString
+		DisbursedAt        sql.NullTime
 		DueDate            sql.NullTime
 		Installment        sql.NullInt32
+		DaysPastDue        sql.NullInt32
 		AssetName          sql.NullString
 		CreatedAt          sql.NullTime
 		UpdatedAt          sql.NullTime
 	}
 )
 
+// loanSortColumns maps the sort keys accepted by FetchLoans to their columns.
+var loanSortColumns = map[string]string{
+	"created_at":         "created_at",
+	"due_date":           "due_date",
+	"loan_amount":        "loan_amount",
+	"outstanding_amount": "outstanding_amount",
+}
+
 var (
 	ValidLoanStatus = map[string]bool{
-		"on_going": true,
-		"finish":   true,
-		"late":     true,
+		"pending_review": true,
+		"approved":       true,
+		"rejected":       true,
+		"disbursed":      true,
+		"cancelled":      true,
+		"on_going":       true,
+		"finish":         true,
+		"late":           true,
 	}
 )
 
-func (r *loanRepository) CreateLoan(ctx context.Context, loan Loan) (id int64, err error) {
+func (r *loanRepository) CreateLoan(ctx context.Context, loan Loan, tx *sql.Tx) (id int64, err error) {
 	query := `
 		INSERT INTO loans (
 			consumer_limit_id,
 			consumer_id,
 			merchant_id,
+			loan_product_id,
+			asset_price,
+			down_payment_amount,
 			loan_amount,
 			contract_number,
 			interest_rate,
+			interest_method,
 			interest_amount,
+			admin_fee_amount,
+			insurance_amount,
+			fee_payment,
+			loan_status,
 			due_date,
 			asset_name,
 			created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	var loanProductID *int64
+	if loan.LoanProductID != 0 {
+		loanProductID = &loan.LoanProductID
+	}
+
+	args := []interface{}{
 		loan.ConsumerLimitID,
 		loan.ConsumerID,
 		loan.MerchantID,
+		loanProductID,
+		loan.AssetPrice,
+		loan.DownPaymentAmount,
 		loan.LoanAmount,
 		loan.ContractNumber,
 		loan.InterestRate,
+		loan.InterestMethod,
 		loan.InterestAmount,
+		loan.AdminFeeAmount,
+		loan.InsuranceAmount,
+		loan.FeePayment,
+		loan.LoanStatus,
 		loan.DueDate,
 		loan.AssetName,
-	)
+	}
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, args...)
+	}
 	if err != nil {
 		logger.Error(fmt.Sprintf("[loanRepository][CreateLoan] while exec query. Err: %v", err))
 		return id, err
@@ -126,10 +290,17 @@ func (r *loanRepository) UpdateLoan(ctx context.Context, req UpdateLoanRequest,
 	query := `
 		UPDATE loans
 		SET
+			interest_amount = ?,
 			paid_loan_amount = ?,
 			paid_interest_amount = ?,
 			loan_status = ?,
-			installment = ?,
+			installment = (
+				SELECT COUNT(*)
+				FROM loan_installments
+				WHERE deleted_at IS NULL
+				AND loan_id = ?
+				AND installment_status = 'paid'
+			),
 			updated_at = NOW()
 		WHERE deleted_at IS NULL
 		AND loan_id = ?
@@ -137,18 +308,20 @@ func (r *loanRepository) UpdateLoan(ctx context.Context, req UpdateLoanRequest,
 
 	if tx != nil {
 		_, err = tx.ExecContext(ctx, query,
+			req.InterestAmount,
 			req.PaidLoanAmount,
 			req.PaidInterestAmount,
 			req.LoanStatus,
-			req.Installment,
+			req.ID,
 			req.ID,
 		)
 	} else {
 		_, err = r.db.ExecContext(ctx, query,
+			req.InterestAmount,
 			req.PaidLoanAmount,
 			req.PaidInterestAmount,
 			req.LoanStatus,
-			req.Installment,
+			req.ID,
 			req.ID,
 		)
 	}
@@ -167,15 +340,25 @@ func (r *loanRepository) GetLoanByID(ctx context.Context, loanID int64) (result
 			consumer_limit_id,
 			consumer_id,
 			merchant_id,
+			loan_product_id,
+			asset_price,
+			down_payment_amount,
 			loan_amount,
 			paid_loan_amount,
 			contract_number,
 			interest_rate,
+			interest_method,
 			interest_amount,
 			paid_interest_amount,
+			admin_fee_amount,
+			insurance_amount,
+			fee_payment,
 			loan_status,
+			status_reason,
+			disbursed_at,
 			due_date,
 			installment,
+			days_past_due,
 			asset_name,
 			created_at,
 			updated_at
@@ -192,15 +375,25 @@ func (r *loanRepository) GetLoanByID(ctx context.Context, loanID int64) (result
 		&loanScanner.ConsumerLimitID,
 		&loanScanner.ConsumerID,
 		&loanScanner.MerchantID,
+		&loanScanner.LoanProductID,
+		&loanScanner.AssetPrice,
+		&loanScanner.DownPaymentAmount,
 		&loanScanner.LoanAmount,
 		&loanScanner.PaidLoanAmount,
 		&loanScanner.ContractNumber,
 		&loanScanner.InterestRate,
+		&loanScanner.InterestMethod,
 		&loanScanner.InterestAmount,
 		&loanScanner.PaidInterestAmount,
+		&loanScanner.AdminFeeAmount,
+		&loanScanner.InsuranceAmount,
+		&loanScanner.FeePayment,
 		&loanScanner.LoanStatus,
+		&loanScanner.StatusReason,
+		&loanScanner.DisbursedAt,
 		&loanScanner.DueDate,
 		&loanScanner.Installment,
+		&loanScanner.DaysPastDue,
 		&loanScanner.AssetName,
 		&loanScanner.CreatedAt,
 		&loanScanner.UpdatedAt,
@@ -219,15 +412,25 @@ func (r *loanRepository) GetLoanByID(ctx context.Context, loanID int64) (result
 		ConsumerLimitID:    loanScanner.ConsumerLimitID.Int64,
 		ConsumerID:         loanScanner.ConsumerID.Int64,
 		MerchantID:         loanScanner.MerchantID.Int64,
-		LoanAmount:         loanScanner.LoanAmount.Float64,
-		PaidLoanAmount:     loanScanner.PaidLoanAmount.Float64,
+		LoanProductID:      loanScanner.LoanProductID.Int64,
+		AssetPrice:         loanScanner.AssetPrice.Money,
+		DownPaymentAmount:  loanScanner.DownPaymentAmount.Money,
+		LoanAmount:         loanScanner.LoanAmount.Money,
+		PaidLoanAmount:     loanScanner.PaidLoanAmount.Money,
 		ContractNumber:     loanScanner.ContractNumber.String,
 		InterestRate:       loanScanner.InterestRate.Float64,
-		InterestAmount:     loanScanner.InterestAmount.Float64,
-		PaidInterestAmount: loanScanner.PaidInterestAmount.Float64,
+		InterestMethod:     loanScanner.InterestMethod.String,
+		InterestAmount:     loanScanner.InterestAmount.Money,
+		PaidInterestAmount: loanScanner.PaidInterestAmount.Money,
+		AdminFeeAmount:     loanScanner.AdminFeeAmount.Money,
+		InsuranceAmount:    loanScanner.InsuranceAmount.Money,
+		FeePayment:         loanScanner.FeePayment.String,
 		LoanStatus:         loanScanner.LoanStatus.String,
+		StatusReason:       loanScanner.StatusReason.String,
+		DisbursedAt:        loanScanner.DisbursedAt.Time,
 		DueDate:            loanScanner.DueDate.Time,
 		Installment:        loanScanner.Installment.Int32,
+		DaysPastDue:        loanScanner.DaysPastDue.Int32,
 		AssetName:          loanScanner.AssetName.String,
 		CreatedAt:          loanScanner.CreatedAt.Time,
 		UpdatedAt:          loanScanner.UpdatedAt.Time,
@@ -236,7 +439,89 @@ func (r *loanRepository) GetLoanByID(ctx context.Context, loanID int64) (result
 	return result, nil
 }
 
-func (r *loanRepository) DeleteLoan(ctx context.Context, loanID int64) (err error) {
+// GetLoanIDByContractNumber returns the ID of the loan with a contract number, or 0 when
+// there is none.
+func (r *loanRepository) GetLoanIDByContractNumber(ctx context.Context, contractNumber string) (loanID int64, err error) {
+	query := `
+		SELECT loan_id
+		FROM loans
+		WHERE deleted_at IS NULL
+		AND contract_number = ?
+	`
+
+	err = r.db.QueryRowContext(ctx, query, contractNumber).Scan(&loanID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+
+		logger.Error(fmt.Sprintf("[loanRepository][GetLoanIDByContractNumber] while scan query row. Err: %v", err))
+		return 0, err
+	}
+
+	return loanID, nil
+}
+
+// LockLoanByID takes a row lock on a loan that is held until tx ends, so payments of the
+// same loan are booked one at a time. It reports whether the loan exists.
+func (r *loanRepository) LockLoanByID(ctx context.Context, loanID int64, tx *sql.Tx) (found bool, err error) {
+	query := `
+		SELECT loan_id
+		FROM loans
+		WHERE deleted_at IS NULL
+		AND loan_id = ?
+		FOR UPDATE
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, loanID)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, loanID)
+	}
+
+	var id int64
+	err = row.Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		logger.Error(fmt.Sprintf("[loanRepository][LockLoanByID] while scan query row. Err: %v", err))
+		return false, err
+	}
+
+	return true, nil
+}
+
+// HasLoanTransactions reports whether any transaction was booked on a loan, reversed ones
+// included.
+func (r *loanRepository) HasLoanTransactions(ctx context.Context, loanID int64, tx *sql.Tx) (exists bool, err error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM transactions
+			WHERE loan_id = ?
+		)
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, loanID)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, loanID)
+	}
+
+	err = row.Scan(&exists)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][HasLoanTransactions] while scan query row. Err: %v", err))
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *loanRepository) DeleteLoan(ctx context.Context, loanID int64, tx *sql.Tx) (err error) {
 	query := `
 		UPDATE loans
 		SET
@@ -244,7 +529,11 @@ func (r *loanRepository) DeleteLoan(ctx context.Context, loanID int64) (err erro
 		WHERE loan_id = ?
 	`
 
-	_, err = r.db.ExecContext(ctx, query, loanID)
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, loanID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, loanID)
+	}
 	if err != nil {
 		logger.Error(fmt.Sprintf("[loanRepository][DeleteLoan] while exec query. Err: %v", err))
 		return err
@@ -260,15 +549,25 @@ func (r *loanRepository) GetLoanByConsumerID(ctx context.Context, consumerID int
 			consumer_limit_id,
 			consumer_id,
 			merchant_id,
+			loan_product_id,
+			asset_price,
+			down_payment_amount,
 			loan_amount,
 			paid_loan_amount,
 			contract_number,
 			interest_rate,
+			interest_method,
 			interest_amount,
 			paid_interest_amount,
+			admin_fee_amount,
+			insurance_amount,
+			fee_payment,
 			loan_status,
+			status_reason,
+			disbursed_at,
 			due_date,
 			installment,
+			days_past_due,
 			asset_name,
 			created_at,
 			updated_at
@@ -291,15 +590,25 @@ func (r *loanRepository) GetLoanByConsumerID(ctx context.Context, consumerID int
 			&loanScanner.ConsumerLimitID,
 			&loanScanner.ConsumerID,
 			&loanScanner.MerchantID,
+			&loanScanner.LoanProductID,
+			&loanScanner.AssetPrice,
+			&loanScanner.DownPaymentAmount,
 			&loanScanner.LoanAmount,
 			&loanScanner.PaidLoanAmount,
 			&loanScanner.ContractNumber,
 			&loanScanner.InterestRate,
+			&loanScanner.InterestMethod,
 			&loanScanner.InterestAmount,
 			&loanScanner.PaidInterestAmount,
+			&loanScanner.AdminFeeAmount,
+			&loanScanner.InsuranceAmount,
+			&loanScanner.FeePayment,
 			&loanScanner.LoanStatus,
+			&loanScanner.StatusReason,
+			&loanScanner.DisbursedAt,
 			&loanScanner.DueDate,
 			&loanScanner.Installment,
+			&loanScanner.DaysPastDue,
 			&loanScanner.AssetName,
 			&loanScanner.CreatedAt,
 			&loanScanner.UpdatedAt,
@@ -314,15 +623,25 @@ func (r *loanRepository) GetLoanByConsumerID(ctx context.Context, consumerID int
 			ConsumerLimitID:    loanScanner.ConsumerLimitID.Int64,
 			ConsumerID:         loanScanner.ConsumerID.Int64,
 			MerchantID:         loanScanner.MerchantID.Int64,
-			LoanAmount:         loanScanner.LoanAmount.Float64,
-			PaidLoanAmount:     loanScanner.PaidLoanAmount.Float64,
+			LoanProductID:      loanScanner.LoanProductID.Int64,
+			AssetPrice:         loanScanner.AssetPrice.Money,
+			DownPaymentAmount:  loanScanner.DownPaymentAmount.Money,
+			LoanAmount:         loanScanner.LoanAmount.Money,
+			PaidLoanAmount:     loanScanner.PaidLoanAmount.Money,
 			ContractNumber:     loanScanner.ContractNumber.String,
 			InterestRate:       loanScanner.InterestRate.Float64,
-			InterestAmount:     loanScanner.InterestAmount.Float64,
-			PaidInterestAmount: loanScanner.PaidInterestAmount.Float64,
+			InterestMethod:     loanScanner.InterestMethod.String,
+			InterestAmount:     loanScanner.InterestAmount.Money,
+			PaidInterestAmount: loanScanner.PaidInterestAmount.Money,
+			AdminFeeAmount:     loanScanner.AdminFeeAmount.Money,
+			InsuranceAmount:    loanScanner.InsuranceAmount.Money,
+			FeePayment:         loanScanner.FeePayment.String,
 			LoanStatus:         loanScanner.LoanStatus.String,
+			StatusReason:       loanScanner.StatusReason.String,
+			DisbursedAt:        loanScanner.DisbursedAt.Time,
 			DueDate:            loanScanner.DueDate.Time,
 			Installment:        loanScanner.Installment.Int32,
+			DaysPastDue:        loanScanner.DaysPastDue.Int32,
 			AssetName:          loanScanner.AssetName.String,
 			CreatedAt:          loanScanner.CreatedAt.Time,
 			UpdatedAt:          loanScanner.UpdatedAt.Time,
@@ -333,3 +652,472 @@ func (r *loanRepository) GetLoanByConsumerID(ctx context.Context, consumerID int
 
 	return result, nil
 }
+
+// FetchLoans returns a page of the loans matching req along with the number of loans
+// matching it over all pages.
+func (r *loanRepository) FetchLoans(ctx context.Context, req FetchLoansRequest) (results []Loan, total int64, err error) {
+	conditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+	if len(req.LoanStatuses) > 0 {
+		conditions = append(conditions, "loan_status IN (?"+strings.Repeat(", ?", len(req.LoanStatuses)-1)+")")
+		for _, loanStatus := range req.LoanStatuses {
+			args = append(args, loanStatus)
+		}
+	}
+	if req.MerchantID != 0 {
+		conditions = append(conditions, "merchant_id = ?")
+		args = append(args, req.MerchantID)
+	}
+	if req.ConsumerID != 0 {
+		conditions = append(conditions, "consumer_id = ?")
+		args = append(args, req.ConsumerID)
+	}
+	if req.ContractNumber != "" {
+		conditions = append(conditions, "contract_number = ?")
+		args = append(args, req.ContractNumber)
+	}
+	if !req.CreatedFrom.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, req.CreatedFrom)
+	}
+	if !req.CreatedTo.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, req.CreatedTo)
+	}
+	if !req.DueFrom.IsZero() {
+		conditions = append(conditions, "due_date >= ?")
+		args = append(args, req.DueFrom)
+	}
+	if !req.DueTo.IsZero() {
+		conditions = append(conditions, "due_date < ?")
+		args = append(args, req.DueTo)
+	}
+	if req.MinOutstandingAmount.Valid {
+		conditions = append(conditions, "outstanding_amount >= ?")
+		args = append(args, req.MinOutstandingAmount.Money)
+	}
+	if req.MaxOutstandingAmount.Valid {
+		conditions = append(conditions, "outstanding_amount <= ?")
+		args = append(args, req.MaxOutstandingAmount.Money)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	query := `
+		SELECT COUNT(*)
+		FROM loans
+		WHERE ` + where
+
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(&total)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][FetchLoans] while scan count query row. Err: %v", err))
+		return results, total, err
+	}
+	if total == 0 {
+		return results, total, nil
+	}
+
+	sortColumn, ok := loanSortColumns[req.SortBy]
+	if !ok {
+		sortColumn = "created_at"
+	}
+	sortOrder := "DESC"
+	if strings.ToLower(req.SortOrder) == "asc" {
+		sortOrder = "ASC"
+	}
+
+	query = `
+		SELECT
+			loan_id,
+			consumer_limit_id,
+			consumer_id,
+			merchant_id,
+			loan_product_id,
+			asset_price,
+			down_payment_amount,
+			loan_amount,
+			paid_loan_amount,
+			contract_number,
+			interest_rate,
+			interest_method,
+			interest_amount,
+			paid_interest_amount,
+			admin_fee_amount,
+			insurance_amount,
+			fee_payment,
+			loan_status,
+			status_reason,
+			disbursed_at,
+			due_date,
+			installment,
+			days_past_due,
+			asset_name,
+			created_at,
+			updated_at
+		FROM loans
+		WHERE ` + where + `
+		ORDER BY ` + sortColumn + ` ` + sortOrder + `, loan_id ` + sortOrder + `
+		LIMIT ? OFFSET ?
+	`
+	args = append(args, req.Limit, req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][FetchLoans] while query. Err: %v", err))
+		return results, total, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var loanScanner LoanScanner
+		err = rows.Scan(
+			&loanScanner.ID,
+			&loanScanner.ConsumerLimitID,
+			&loanScanner.ConsumerID,
+			&loanScanner.MerchantID,
+			&loanScanner.LoanProductID,
+			&loanScanner.AssetPrice,
+			&loanScanner.DownPaymentAmount,
+			&loanScanner.LoanAmount,
+			&loanScanner.PaidLoanAmount,
+			&loanScanner.ContractNumber,
+			&loanScanner.InterestRate,
+			&loanScanner.InterestMethod,
+			&loanScanner.InterestAmount,
+			&loanScanner.PaidInterestAmount,
+			&loanScanner.AdminFeeAmount,
+			&loanScanner.InsuranceAmount,
+			&loanScanner.FeePayment,
+			&loanScanner.LoanStatus,
+			&loanScanner.StatusReason,
+			&loanScanner.DisbursedAt,
+			&loanScanner.DueDate,
+			&loanScanner.Installment,
+			&loanScanner.DaysPastDue,
+			&loanScanner.AssetName,
+			&loanScanner.CreatedAt,
+			&loanScanner.UpdatedAt,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[loanRepository][FetchLoans] while scan query row. Err: %v", err))
+			return results, total, err
+		}
+
+		results = append(results, Loan{
+			ID:                 loanScanner.ID.Int64,
+			ConsumerLimitID:    loanScanner.ConsumerLimitID.Int64,
+			ConsumerID:         loanScanner.ConsumerID.Int64,
+			MerchantID:         loanScanner.MerchantID.Int64,
+			LoanProductID:      loanScanner.LoanProductID.Int64,
+			AssetPrice:         loanScanner.AssetPrice.Money,
+			DownPaymentAmount:  loanScanner.DownPaymentAmount.Money,
+			LoanAmount:         loanScanner.LoanAmount.Money,
+			PaidLoanAmount:     loanScanner.PaidLoanAmount.Money,
+			ContractNumber:     loanScanner.ContractNumber.String,
+			InterestRate:       loanScanner.InterestRate.Float64,
+			InterestMethod:     loanScanner.InterestMethod.String,
+			InterestAmount:     loanScanner.InterestAmount.Money,
+			PaidInterestAmount: loanScanner.PaidInterestAmount.Money,
+			AdminFeeAmount:     loanScanner.AdminFeeAmount.Money,
+			InsuranceAmount:    loanScanner.InsuranceAmount.Money,
+			FeePayment:         loanScanner.FeePayment.String,
+			LoanStatus:         loanScanner.LoanStatus.String,
+			StatusReason:       loanScanner.StatusReason.String,
+			DisbursedAt:        loanScanner.DisbursedAt.Time,
+			DueDate:            loanScanner.DueDate.Time,
+			Installment:        loanScanner.Installment.Int32,
+			DaysPastDue:        loanScanner.DaysPastDue.Int32,
+			AssetName:          loanScanner.AssetName.String,
+			CreatedAt:          loanScanner.CreatedAt.Time,
+			UpdatedAt:          loanScanner.UpdatedAt.Time,
+		})
+	}
+
+	return results, total, nil
+}
+
+func (r *loanRepository) GetActiveLoans(ctx context.Context) (result []Loan, err error) {
+	query := `
+		SELECT
+			loan_id,
+			consumer_limit_id,
+			consumer_id,
+			merchant_id,
+			loan_product_id,
+			asset_price,
+			down_payment_amount,
+			loan_amount,
+			paid_loan_amount,
+			contract_number,
+			interest_rate,
+			interest_method,
+			interest_amount,
+			paid_interest_amount,
+			admin_fee_amount,
+			insurance_amount,
+			fee_payment,
+			loan_status,
+			status_reason,
+			disbursed_at,
+			due_date,
+			installment,
+			days_past_due,
+			asset_name,
+			created_at,
+			updated_at
+		FROM loans
+		WHERE deleted_at IS NULL
+		AND loan_status IN ('disbursed', 'on_going', 'late')
+		ORDER BY loan_id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][GetActiveLoans] while query. Err: %v", err))
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var loanScanner LoanScanner
+		err = rows.Scan(
+			&loanScanner.ID,
+			&loanScanner.ConsumerLimitID,
+			&loanScanner.ConsumerID,
+			&loanScanner.MerchantID,
+			&loanScanner.LoanProductID,
+			&loanScanner.AssetPrice,
+			&loanScanner.DownPaymentAmount,
+			&loanScanner.LoanAmount,
+			&loanScanner.PaidLoanAmount,
+			&loanScanner.ContractNumber,
+			&loanScanner.InterestRate,
+			&loanScanner.InterestMethod,
+			&loanScanner.InterestAmount,
+			&loanScanner.PaidInterestAmount,
+			&loanScanner.AdminFeeAmount,
+			&loanScanner.InsuranceAmount,
+			&loanScanner.FeePayment,
+			&loanScanner.LoanStatus,
+			&loanScanner.StatusReason,
+			&loanScanner.DisbursedAt,
+			&loanScanner.DueDate,
+			&loanScanner.Installment,
+			&loanScanner.DaysPastDue,
+			&loanScanner.AssetName,
+			&loanScanner.CreatedAt,
+			&loanScanner.UpdatedAt,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[loanRepository][GetActiveLoans] while scan query row. Err: %v", err))
+			return result, err
+		}
+
+		loan := Loan{
+			ID:                 loanScanner.ID.Int64,
+			ConsumerLimitID:    loanScanner.ConsumerLimitID.Int64,
+			ConsumerID:         loanScanner.ConsumerID.Int64,
+			MerchantID:         loanScanner.MerchantID.Int64,
+			LoanProductID:      loanScanner.LoanProductID.Int64,
+			AssetPrice:         loanScanner.AssetPrice.Money,
+			DownPaymentAmount:  loanScanner.DownPaymentAmount.Money,
+			LoanAmount:         loanScanner.LoanAmount.Money,
+			PaidLoanAmount:     loanScanner.PaidLoanAmount.Money,
+			ContractNumber:     loanScanner.ContractNumber.String,
+			InterestRate:       loanScanner.InterestRate.Float64,
+			InterestMethod:     loanScanner.InterestMethod.String,
+			InterestAmount:     loanScanner.InterestAmount.Money,
+			PaidInterestAmount: loanScanner.PaidInterestAmount.Money,
+			AdminFeeAmount:     loanScanner.AdminFeeAmount.Money,
+			InsuranceAmount:    loanScanner.InsuranceAmount.Money,
+			FeePayment:         loanScanner.FeePayment.String,
+			LoanStatus:         loanScanner.LoanStatus.String,
+			StatusReason:       loanScanner.StatusReason.String,
+			DisbursedAt:        loanScanner.DisbursedAt.Time,
+			DueDate:            loanScanner.DueDate.Time,
+			Installment:        loanScanner.Installment.Int32,
+			DaysPastDue:        loanScanner.DaysPastDue.Int32,
+			AssetName:          loanScanner.AssetName.String,
+			CreatedAt:          loanScanner.CreatedAt.Time,
+			UpdatedAt:          loanScanner.UpdatedAt.Time,
+		}
+
+		result = append(result, loan)
+	}
+
+	return result, nil
+}
+
+// UpdateLoanDaysPastDue sets the days past due of a loan and its status, as long as the
+// loan is still as the days past due were computed from, it returns ErrLoanStatusConflict
+// otherwise.
+func (r *loanRepository) UpdateLoanDaysPastDue(ctx context.Context, req UpdateLoanDaysPastDueRequest, tx *sql.Tx) (err error) {
+	query := `
+		UPDATE loans
+		SET
+			loan_status = ?,
+			days_past_due = ?,
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND loan_id = ?
+		AND loan_status = ?
+		AND days_past_due = ?
+	`
+
+	args := []interface{}{
+		req.LoanStatus,
+		req.DaysPastDue,
+		req.ID,
+		req.FromStatus,
+		req.FromDaysPastDue,
+	}
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][UpdateLoanDaysPastDue] while exec query. Err: %v", err))
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][UpdateLoanDaysPastDue] while get rows affected. Err: %v", err))
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrLoanStatusConflict
+	}
+
+	return nil
+}
+
+// UpdateLoanStatus changes the status of a loan as long as it still has the status the
+// change was decided on, it returns ErrLoanStatusConflict otherwise.
+func (r *loanRepository) UpdateLoanStatus(ctx context.Context, req UpdateLoanStatusRequest, tx *sql.Tx) (err error) {
+	query := `
+		UPDATE loans
+		SET
+			loan_status = ?,
+			status_reason = ?,
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND loan_id = ?
+		AND loan_status = ?
+	`
+
+	var statusReason *string
+	if req.StatusReason != "" {
+		statusReason = &req.StatusReason
+	}
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, req.LoanStatus, statusReason, req.ID, req.FromStatus)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, req.LoanStatus, statusReason, req.ID, req.FromStatus)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][UpdateLoanStatus] while exec query. Err: %v", err))
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][UpdateLoanStatus] while get rows affected. Err: %v", err))
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrLoanStatusConflict
+	}
+
+	return nil
+}
+
+// DisburseLoan marks an approved loan disbursed, it returns ErrLoanStatusConflict when the
+// loan is no longer approved.
+func (r *loanRepository) DisburseLoan(ctx context.Context, req DisburseLoanRequest, tx *sql.Tx) (err error) {
+	query := `
+		UPDATE loans
+		SET
+			loan_status = 'disbursed',
+			disbursed_at = ?,
+			due_date = DATE_ADD(DATE(?), INTERVAL ? MONTH),
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND loan_id = ?
+		AND loan_status = 'approved'
+	`
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, req.DisbursedAt, req.DisbursedAt, req.Tenure, req.ID)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, req.DisbursedAt, req.DisbursedAt, req.Tenure, req.ID)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][DisburseLoan] while exec query. Err: %v", err))
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][DisburseLoan] while get rows affected. Err: %v", err))
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrLoanStatusConflict
+	}
+
+	return nil
+}
+
+// RestructureLoan moves a loan onto its restructured terms. The number of paid installments
+// is recounted since the schedule changed.
+func (r *loanRepository) RestructureLoan(ctx context.Context, req RestructureLoanRequest, tx *sql.Tx) (err error) {
+	query := `
+		UPDATE loans
+		SET
+			consumer_limit_id = ?,
+			loan_amount = ?,
+			interest_amount = ?,
+			loan_status = ?,
+			days_past_due = ?,
+			due_date = ?,
+			installment = (
+				SELECT COUNT(*)
+				FROM loan_installments
+				WHERE deleted_at IS NULL
+				AND loan_id = ?
+				AND installment_status = 'paid'
+			),
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND loan_id = ?
+	`
+
+	args := []interface{}{
+		req.ConsumerLimitID,
+		req.LoanAmount,
+		req.InterestAmount,
+		req.LoanStatus,
+		req.DaysPastDue,
+		req.DueDate,
+		req.ID,
+		req.ID,
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRepository][RestructureLoan] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 )
@@ -31,63 +32,87 @@ func TestCreateLoan(t *testing.T) {
 		{
 			name: "success",
 			loan: repository.Loan{
-				ConsumerLimitID: 1,
-				ConsumerID:      1,
-				MerchantID:      1,
-				LoanAmount:      1000.0,
-				ContractNumber:  "12345",
-				InterestRate:    5.0,
-				InterestAmount:  50.0,
-				DueDate:         now,
-				AssetName:       "Car",
+				ConsumerLimitID:   1,
+				ConsumerID:        1,
+				MerchantID:        1,
+				LoanProductID:     3,
+				AssetPrice:        money.New(1200),
+				DownPaymentAmount: money.New(200),
+				LoanAmount:        money.New(1000),
+				ContractNumber:    "12345",
+				InterestRate:      5.0,
+				InterestMethod:    "flat",
+				InterestAmount:    money.New(50),
+				AdminFeeAmount:    money.New(5),
+				InsuranceAmount:   money.New(15),
+				FeePayment:        "upfront",
+				LoanStatus:        "pending_review",
+				DueDate:           now,
+				AssetName:         "Car",
 			},
 			wantID:  1,
 			wantErr: false,
 			mock: func() {
 				mock.ExpectExec("INSERT INTO loans").
-					WithArgs(1, 1, 1, 1000.0, "12345", 5.0, 50.0, sqlmock.AnyArg(), "Car").
+					WithArgs(1, 1, 1, 3, money.New(1200), money.New(200), money.New(1000), "12345", 5.0, "flat", money.New(50), money.New(5), money.New(15), "upfront", "pending_review", sqlmock.AnyArg(), "Car").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
 		{
 			name: "exec error",
 			loan: repository.Loan{
-				ConsumerLimitID: 1,
-				ConsumerID:      1,
-				MerchantID:      1,
-				LoanAmount:      1000.0,
-				ContractNumber:  "12345",
-				InterestRate:    5.0,
-				InterestAmount:  50.0,
-				DueDate:         now,
-				AssetName:       "Car",
+				ConsumerLimitID:   1,
+				ConsumerID:        1,
+				MerchantID:        1,
+				LoanProductID:     3,
+				AssetPrice:        money.New(1200),
+				DownPaymentAmount: money.New(200),
+				LoanAmount:        money.New(1000),
+				ContractNumber:    "12345",
+				InterestRate:      5.0,
+				InterestMethod:    "flat",
+				InterestAmount:    money.New(50),
+				AdminFeeAmount:    money.New(5),
+				InsuranceAmount:   money.New(15),
+				FeePayment:        "upfront",
+				LoanStatus:        "pending_review",
+				DueDate:           now,
+				AssetName:         "Car",
 			},
 			wantID:  0,
 			wantErr: true,
 			mock: func() {
 				mock.ExpectExec("INSERT INTO loans").
-					WithArgs(1, 1, 1, 1000.0, "12345", 5.0, 50.0, sqlmock.AnyArg(), "Car").
+					WithArgs(1, 1, 1, 3, money.New(1200), money.New(200), money.New(1000), "12345", 5.0, "flat", money.New(50), money.New(5), money.New(15), "upfront", "pending_review", sqlmock.AnyArg(), "Car").
 					WillReturnError(sql.ErrConnDone)
 			},
 		},
 		{
 			name: "last insert id error",
 			loan: repository.Loan{
-				ConsumerLimitID: 1,
-				ConsumerID:      1,
-				MerchantID:      1,
-				LoanAmount:      1000.0,
-				ContractNumber:  "12345",
-				InterestRate:    5.0,
-				InterestAmount:  50.0,
-				DueDate:         now,
-				AssetName:       "Car",
+				ConsumerLimitID:   1,
+				ConsumerID:        1,
+				MerchantID:        1,
+				LoanProductID:     3,
+				AssetPrice:        money.New(1200),
+				DownPaymentAmount: money.New(200),
+				LoanAmount:        money.New(1000),
+				ContractNumber:    "12345",
+				InterestRate:      5.0,
+				InterestMethod:    "flat",
+				InterestAmount:    money.New(50),
+				AdminFeeAmount:    money.New(5),
+				InsuranceAmount:   money.New(15),
+				FeePayment:        "upfront",
+				LoanStatus:        "pending_review",
+				DueDate:           now,
+				AssetName:         "Car",
 			},
 			wantID:  0,
 			wantErr: true,
 			mock: func() {
 				mock.ExpectExec("INSERT INTO loans").
-					WithArgs(1, 1, 1, 1000.0, "12345", 5.0, 50.0, sqlmock.AnyArg(), "Car").
+					WithArgs(1, 1, 1, 3, money.New(1200), money.New(200), money.New(1000), "12345", 5.0, "flat", money.New(50), money.New(5), money.New(15), "upfront", "pending_review", sqlmock.AnyArg(), "Car").
 					WillReturnResult(sqlmock.NewErrorResult(sql.ErrNoRows))
 			},
 		},
@@ -96,7 +121,7 @@ func TestCreateLoan(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
-			id, err := repo.CreateLoan(context.Background(), tt.loan)
+			id, err := repo.CreateLoan(context.Background(), tt.loan, nil)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -130,16 +155,16 @@ func TestUpdateLoan(t *testing.T) {
 			name: "success with transaction",
 			req: repository.UpdateLoanRequest{
 				ID:                 1,
-				PaidLoanAmount:     500.0,
-				PaidInterestAmount: 25.0,
+				InterestAmount:     money.New(30),
+				PaidLoanAmount:     money.New(500),
+				PaidInterestAmount: money.New(25),
 				LoanStatus:         "on_going",
-				Installment:        5,
 			},
 			tx:      trx,
 			wantErr: false,
 			mock: func() {
 				mock.ExpectExec("UPDATE loans").
-					WithArgs(500.0, 25.0, "on_going", 5, 1).
+					WithArgs(money.New(30), money.New(500), money.New(25), "on_going", 1, 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
@@ -147,16 +172,16 @@ func TestUpdateLoan(t *testing.T) {
 			name: "success without transaction",
 			req: repository.UpdateLoanRequest{
 				ID:                 1,
-				PaidLoanAmount:     500.0,
-				PaidInterestAmount: 25.0,
+				InterestAmount:     money.New(30),
+				PaidLoanAmount:     money.New(500),
+				PaidInterestAmount: money.New(25),
 				LoanStatus:         "on_going",
-				Installment:        5,
 			},
 			tx:      nil,
 			wantErr: false,
 			mock: func() {
 				mock.ExpectExec("UPDATE loans").
-					WithArgs(500.0, 25.0, "on_going", 5, 1).
+					WithArgs(money.New(30), money.New(500), money.New(25), "on_going", 1, 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
@@ -164,17 +189,17 @@ func TestUpdateLoan(t *testing.T) {
 			name: "exec error with transaction",
 			req: repository.UpdateLoanRequest{
 				ID:                 1,
-				PaidLoanAmount:     500.0,
-				PaidInterestAmount: 25.0,
+				InterestAmount:     money.New(30),
+				PaidLoanAmount:     money.New(500),
+				PaidInterestAmount: money.New(25),
 				LoanStatus:         "on_going",
-				Installment:        5,
 			},
 			tx:      trx,
 			wantErr: true,
 			mock: func() {
 				mock.ExpectBegin()
 				mock.ExpectExec("UPDATE loans").
-					WithArgs(500.0, 25.0, "on_going", 5, 1).
+					WithArgs(money.New(30), money.New(500), money.New(25), "on_going", 1, 1).
 					WillReturnError(sql.ErrConnDone)
 				mock.ExpectRollback()
 			},
@@ -183,16 +208,16 @@ func TestUpdateLoan(t *testing.T) {
 			name: "exec error without transaction",
 			req: repository.UpdateLoanRequest{
 				ID:                 1,
-				PaidLoanAmount:     500.0,
-				PaidInterestAmount: 25.0,
+				InterestAmount:     money.New(30),
+				PaidLoanAmount:     money.New(500),
+				PaidInterestAmount: money.New(25),
 				LoanStatus:         "on_going",
-				Installment:        5,
 			},
 			tx:      nil,
 			wantErr: true,
 			mock: func() {
 				mock.ExpectExec("UPDATE loans").
-					WithArgs(500.0, 25.0, "on_going", 5, 1).
+					WithArgs(money.New(30), money.New(500), money.New(25), "on_going", 1, 1).
 					WillReturnError(sql.ErrConnDone)
 			},
 		},
@@ -236,12 +261,19 @@ func TestGetLoanByID(t *testing.T) {
 				ConsumerLimitID:    1,
 				ConsumerID:         1,
 				MerchantID:         1,
-				LoanAmount:         1000.0,
-				PaidLoanAmount:     500.0,
+				LoanProductID:      2,
+				AssetPrice:         money.New(1200),
+				DownPaymentAmount:  money.New(200),
+				LoanAmount:         money.New(1000),
+				PaidLoanAmount:     money.New(500),
 				ContractNumber:     "12345",
 				InterestRate:       5.0,
-				InterestAmount:     50.0,
-				PaidInterestAmount: 25.0,
+				InterestMethod:     "flat",
+				InterestAmount:     money.New(50),
+				PaidInterestAmount: money.New(25),
+				AdminFeeAmount:     money.New(10),
+				InsuranceAmount:    money.New(15),
+				FeePayment:         "financed",
 				LoanStatus:         "on_going",
 				DueDate:            now,
 				Installment:        5,
@@ -252,12 +284,12 @@ func TestGetLoanByID(t *testing.T) {
 			wantErr: false,
 			mock: func() {
 				rows := sqlmock.NewRows([]string{
-					"loan_id", "consumer_limit_id", "consumer_id", "merchant_id", "loan_amount", "paid_loan_amount",
-					"contract_number", "interest_rate", "interest_amount", "paid_interest_amount", "loan_status",
-					"due_date", "installment", "asset_name", "created_at", "updated_at",
+					"loan_id", "consumer_limit_id", "consumer_id", "merchant_id", "loan_product_id", "asset_price", "down_payment_amount", "loan_amount", "paid_loan_amount",
+					"contract_number", "interest_rate", "interest_method", "interest_amount", "paid_interest_amount", "admin_fee_amount", "insurance_amount", "fee_payment", "loan_status",
+					"status_reason", "disbursed_at", "due_date", "installment", "days_past_due", "asset_name", "created_at", "updated_at",
 				}).AddRow(
-					1, 1, 1, 1, 1000.0, 500.0, "12345", 5.0, 50.0, 25.0, "on_going",
-					now, 5, "Car", now, now,
+					1, 1, 1, 1, 2, 1200.0, 200.0, 1000.0, 500.0, "12345", 5.0, "flat", 50.0, 25.0, 10.0, 15.0, "financed", "on_going",
+					nil, nil, now, 5, 0, "Car", now, now,
 				)
 				mock.ExpectQuery("SELECT (.+) FROM loans WHERE deleted_at IS NULL AND loan_id = ?").
 					WithArgs(1).WillReturnRows(rows)
@@ -299,6 +331,180 @@ func TestGetLoanByID(t *testing.T) {
 	}
 }
 
+func TestGetLoanIDByContractNumber(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRepository(db)
+	query := "SELECT loan_id FROM loans WHERE deleted_at IS NULL AND contract_number = \\?"
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs("1-ABCDEFGHIJ-2").WillReturnRows(sqlmock.NewRows([]string{"loan_id"}).AddRow(5))
+			},
+			want:    5,
+			wantErr: false,
+		},
+		{
+			name: "no rows",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs("1-ABCDEFGHIJ-2").WillReturnError(sql.ErrNoRows)
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs("1-ABCDEFGHIJ-2").WillReturnError(sql.ErrConnDone)
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetLoanIDByContractNumber(context.Background(), "1-ABCDEFGHIJ-2")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLockLoanByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRepository(db)
+	query := "SELECT loan_id FROM loans WHERE deleted_at IS NULL AND loan_id = \\? FOR UPDATE"
+
+	mock.ExpectBegin()
+	trx, _ := db.Begin()
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"loan_id"}).AddRow(1))
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "no rows",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(1).WillReturnError(sql.ErrNoRows)
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(1).WillReturnError(sql.ErrConnDone)
+			},
+			want:    false,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.LockLoanByID(context.Background(), 1, trx)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestHasLoanTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRepository(db)
+	query := "SELECT EXISTS \\( SELECT 1 FROM transactions WHERE loan_id = \\? \\)"
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "has transactions",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "no transactions",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(1).WillReturnError(sql.ErrConnDone)
+			},
+			want:    false,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.HasLoanTransactions(context.Background(), 1, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestDeleteLoan(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -339,7 +545,7 @@ func TestDeleteLoan(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
-			err := repo.DeleteLoan(context.Background(), tt.loanID)
+			err := repo.DeleteLoan(context.Background(), tt.loanID, nil)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -375,12 +581,19 @@ func TestGetLoanByConsumerID(t *testing.T) {
 					ConsumerLimitID:    1,
 					ConsumerID:         1,
 					MerchantID:         1,
-					LoanAmount:         1000.0,
-					PaidLoanAmount:     500.0,
+					LoanProductID:      2,
+					AssetPrice:         money.New(1200),
+					DownPaymentAmount:  money.New(200),
+					LoanAmount:         money.New(1000),
+					PaidLoanAmount:     money.New(500),
 					ContractNumber:     "12345",
 					InterestRate:       5.0,
-					InterestAmount:     50.0,
-					PaidInterestAmount: 25.0,
+					InterestMethod:     "flat",
+					InterestAmount:     money.New(50),
+					PaidInterestAmount: money.New(25),
+					AdminFeeAmount:     money.New(10),
+					InsuranceAmount:    money.New(15),
+					FeePayment:         "financed",
 					LoanStatus:         "on_going",
 					DueDate:            now,
 					Installment:        5,
@@ -392,12 +605,12 @@ func TestGetLoanByConsumerID(t *testing.T) {
 			wantErr: false,
 			mock: func() {
 				rows := sqlmock.NewRows([]string{
-					"loan_id", "consumer_limit_id", "consumer_id", "merchant_id", "loan_amount", "paid_loan_amount",
-					"contract_number", "interest_rate", "interest_amount", "paid_interest_amount", "loan_status",
-					"due_date", "installment", "asset_name", "created_at", "updated_at",
+					"loan_id", "consumer_limit_id", "consumer_id", "merchant_id", "loan_product_id", "asset_price", "down_payment_amount", "loan_amount", "paid_loan_amount",
+					"contract_number", "interest_rate", "interest_method", "interest_amount", "paid_interest_amount", "admin_fee_amount", "insurance_amount", "fee_payment", "loan_status",
+					"status_reason", "disbursed_at", "due_date", "installment", "days_past_due", "asset_name", "created_at", "updated_at",
 				}).AddRow(
-					1, 1, 1, 1, 1000.0, 500.0, "12345", 5.0, 50.0, 25.0, "on_going",
-					now, 5, "Car", now, now,
+					1, 1, 1, 1, 2, 1200.0, 200.0, 1000.0, 500.0, "12345", 5.0, "flat", 50.0, 25.0, 10.0, 15.0, "financed", "on_going",
+					nil, nil, now, 5, 0, "Car", now, now,
 				)
 				mock.ExpectQuery("SELECT (.+) FROM loans WHERE deleted_at IS NULL AND consumer_id = ?").
 					WithArgs(1).WillReturnRows(rows)
@@ -438,3 +651,475 @@ func TestGetLoanByConsumerID(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchLoans(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRepository(db)
+	columns := []string{
+		"loan_id", "consumer_limit_id", "consumer_id", "merchant_id", "loan_product_id", "asset_price", "down_payment_amount", "loan_amount", "paid_loan_amount",
+		"contract_number", "interest_rate", "interest_method", "interest_amount", "paid_interest_amount", "admin_fee_amount", "insurance_amount", "fee_payment", "loan_status",
+		"status_reason", "disbursed_at", "due_date", "installment", "days_past_due", "asset_name", "created_at", "updated_at",
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		req       repository.FetchLoansRequest
+		want      []repository.Loan
+		wantTotal int64
+		wantErr   bool
+		mock      func()
+	}{
+		{
+			name: "all filters sorted by outstanding amount",
+			req: repository.FetchLoansRequest{
+				LoanStatuses:         []string{"on_going", "late"},
+				MerchantID:           2,
+				ConsumerID:           1,
+				ContractNumber:       "12345",
+				CreatedFrom:          from,
+				CreatedTo:            to,
+				DueFrom:              from,
+				DueTo:                to,
+				MinOutstandingAmount: money.NullMoney{Money: money.New(100), Valid: true},
+				MaxOutstandingAmount: money.NullMoney{Money: money.New(5000), Valid: true},
+				SortBy:               "outstanding_amount",
+				SortOrder:            "asc",
+				Limit:                10,
+				Offset:               20,
+			},
+			want: []repository.Loan{
+				{
+					ID:                 1,
+					ConsumerLimitID:    1,
+					ConsumerID:         1,
+					MerchantID:         2,
+					LoanAmount:         money.New(1000),
+					PaidLoanAmount:     money.New(500),
+					ContractNumber:     "12345",
+					InterestRate:       5.0,
+					InterestMethod:     "flat",
+					InterestAmount:     money.New(50),
+					PaidInterestAmount: money.New(25),
+					LoanStatus:         "late",
+					DueDate:            from,
+					AssetName:          "Car",
+					CreatedAt:          from,
+					UpdatedAt:          from,
+				},
+			},
+			wantTotal: 21,
+			mock: func() {
+				conditions := "WHERE deleted_at IS NULL AND loan_status IN \\(\\?, \\?\\) AND merchant_id = \\? AND consumer_id = \\? AND contract_number = \\? AND created_at >= \\? AND created_at < \\? AND due_date >= \\? AND due_date < \\? AND outstanding_amount >= \\? AND outstanding_amount <= \\?"
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM loans "+conditions).
+					WithArgs("on_going", "late", 2, 1, "12345", from, to, from, to, money.New(100), money.New(5000)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+				mock.ExpectQuery("FROM loans "+conditions+" ORDER BY outstanding_amount ASC, loan_id ASC LIMIT \\? OFFSET \\?").
+					WithArgs("on_going", "late", 2, 1, "12345", from, to, from, to, money.New(100), money.New(5000), 10, 20).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(
+						1, 1, 1, 2, nil, nil, nil, 1000.0, 500.0, "12345", 5.0, "flat", 50.0, 25.0, nil, nil, nil, "late",
+						nil, nil, from, 0, 0, "Car", from, from,
+					))
+			},
+		},
+		{
+			name:      "newest first by default",
+			req:       repository.FetchLoansRequest{SortBy: "asset_name; DROP TABLE loans", Limit: 10},
+			want:      nil,
+			wantTotal: 1,
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM loans WHERE deleted_at IS NULL").
+					WithArgs().
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery("FROM loans WHERE deleted_at IS NULL ORDER BY created_at DESC, loan_id DESC LIMIT \\? OFFSET \\?").
+					WithArgs(10, 0).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			name:      "no loans",
+			req:       repository.FetchLoansRequest{ConsumerID: 3, Limit: 10},
+			want:      nil,
+			wantTotal: 0,
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM loans WHERE deleted_at IS NULL AND consumer_id = \\?").
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+		},
+		{
+			name:    "count error",
+			req:     repository.FetchLoansRequest{Limit: 10},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM loans").WillReturnError(sql.ErrConnDone)
+			},
+		},
+		{
+			name:      "query error",
+			req:       repository.FetchLoansRequest{Limit: 10},
+			wantTotal: 1,
+			wantErr:   true,
+			mock: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM loans").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery("FROM loans WHERE deleted_at IS NULL ORDER BY").WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, total, err := repo.FetchLoans(context.Background(), tt.req)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantTotal, total)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetActiveLoans(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRepository(db)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		want    []repository.Loan
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "success",
+			want: []repository.Loan{
+				{
+					ID:                 1,
+					ConsumerLimitID:    1,
+					ConsumerID:         1,
+					MerchantID:         1,
+					LoanProductID:      2,
+					AssetPrice:         money.New(1200),
+					DownPaymentAmount:  money.New(200),
+					LoanAmount:         money.New(1000),
+					PaidLoanAmount:     money.New(500),
+					ContractNumber:     "12345",
+					InterestRate:       5.0,
+					InterestMethod:     "flat",
+					InterestAmount:     money.New(50),
+					PaidInterestAmount: money.New(25),
+					AdminFeeAmount:     money.New(10),
+					InsuranceAmount:    money.New(15),
+					FeePayment:         "financed",
+					LoanStatus:         "late",
+					DisbursedAt:        now,
+					DueDate:            now,
+					Installment:        5,
+					DaysPastDue:        3,
+					AssetName:          "Car",
+					CreatedAt:          now,
+					UpdatedAt:          now,
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				rows := sqlmock.NewRows([]string{
+					"loan_id", "consumer_limit_id", "consumer_id", "merchant_id", "loan_product_id", "asset_price", "down_payment_amount", "loan_amount", "paid_loan_amount",
+					"contract_number", "interest_rate", "interest_method", "interest_amount", "paid_interest_amount", "admin_fee_amount", "insurance_amount", "fee_payment", "loan_status",
+					"status_reason", "disbursed_at", "due_date", "installment", "days_past_due", "asset_name", "created_at", "updated_at",
+				}).AddRow(
+					1, 1, 1, 1, 2, 1200.0, 200.0, 1000.0, 500.0, "12345", 5.0, "flat", 50.0, 25.0, 10.0, 15.0, "financed", "late",
+					nil, now, now, 5, 3, "Car", now, now,
+				)
+				mock.ExpectQuery("SELECT (.+) FROM loans WHERE deleted_at IS NULL AND loan_status IN").
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name:    "query error",
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM loans WHERE deleted_at IS NULL AND loan_status IN").
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetActiveLoans(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUpdateLoanDaysPastDue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRepository(db)
+
+	tests := []struct {
+		name    string
+		req     repository.UpdateLoanDaysPastDueRequest
+		wantErr error
+		mock    func()
+	}{
+		{
+			name:    "success",
+			req:     repository.UpdateLoanDaysPastDueRequest{ID: 1, FromStatus: "on_going", LoanStatus: "late", DaysPastDue: 3},
+			wantErr: nil,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans").
+					WithArgs("late", 3, 1, "on_going", 0).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "loan changed meanwhile",
+			req:     repository.UpdateLoanDaysPastDueRequest{ID: 3, FromStatus: "on_going", LoanStatus: "late", DaysPastDue: 5},
+			wantErr: repository.ErrLoanStatusConflict,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans (.+) AND loan_status = \\? AND days_past_due = \\?").
+					WithArgs("late", 5, 3, "on_going", 0).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:    "exec error",
+			req:     repository.UpdateLoanDaysPastDueRequest{ID: 2, FromStatus: "late", FromDaysPastDue: 4, LoanStatus: "on_going", DaysPastDue: 0},
+			wantErr: sql.ErrConnDone,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans").
+					WithArgs("on_going", 0, 2, "late", 4).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.UpdateLoanDaysPastDue(context.Background(), tt.req, nil)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateLoanStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRepository(db)
+
+	tests := []struct {
+		name    string
+		req     repository.UpdateLoanStatusRequest
+		wantErr error
+		mock    func()
+	}{
+		{
+			name:    "success",
+			req:     repository.UpdateLoanStatusRequest{ID: 1, FromStatus: "review", LoanStatus: "approved"},
+			wantErr: nil,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans").
+					WithArgs("approved", nil, 1, "review").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "success with reason",
+			req:     repository.UpdateLoanStatusRequest{ID: 2, FromStatus: "review", LoanStatus: "rejected", StatusReason: "insufficient income"},
+			wantErr: nil,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans").
+					WithArgs("rejected", "insufficient income", 2, "review").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "status changed meanwhile",
+			req:     repository.UpdateLoanStatusRequest{ID: 4, FromStatus: "review", LoanStatus: "approved"},
+			wantErr: repository.ErrLoanStatusConflict,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans (.+) AND loan_status = \\?").
+					WithArgs("approved", nil, 4, "review").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:    "exec error",
+			req:     repository.UpdateLoanStatusRequest{ID: 3, FromStatus: "review", LoanStatus: "approved"},
+			wantErr: sql.ErrConnDone,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans").
+					WithArgs("approved", nil, 3, "review").
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.UpdateLoanStatus(context.Background(), tt.req, nil)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDisburseLoan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRepository(db)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		req     repository.DisburseLoanRequest
+		wantErr error
+		mock    func()
+	}{
+		{
+			name:    "success",
+			req:     repository.DisburseLoanRequest{ID: 1, DisbursedAt: now, Tenure: 6},
+			wantErr: nil,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans SET loan_status = 'disbursed'").
+					WithArgs(now, now, 6, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "loan no longer approved",
+			req:     repository.DisburseLoanRequest{ID: 3, DisbursedAt: now, Tenure: 6},
+			wantErr: repository.ErrLoanStatusConflict,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans SET loan_status = 'disbursed'(.+) AND loan_status = 'approved'").
+					WithArgs(now, now, 6, 3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:    "exec error",
+			req:     repository.DisburseLoanRequest{ID: 2, DisbursedAt: now, Tenure: 6},
+			wantErr: sql.ErrConnDone,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans SET loan_status = 'disbursed'").
+					WithArgs(now, now, 6, 2).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.DisburseLoan(context.Background(), tt.req, nil)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRestructureLoan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRepository(db)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		req     repository.RestructureLoanRequest
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "success",
+			req: repository.RestructureLoanRequest{
+				ID:              1,
+				ConsumerLimitID: 2,
+				LoanAmount:      money.New(1100),
+				InterestAmount:  money.New(150),
+				LoanStatus:      "on_going",
+				DueDate:         now,
+			},
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans SET consumer_limit_id = ?").
+					WithArgs(2, money.New(1100), money.New(150), "on_going", 0, now, 1, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "exec error",
+			req: repository.RestructureLoanRequest{
+				ID:              2,
+				ConsumerLimitID: 2,
+				LoanAmount:      money.New(1100),
+				InterestAmount:  money.New(150),
+				LoanStatus:      "late",
+				DaysPastDue:     3,
+				DueDate:         now,
+			},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("UPDATE loans SET consumer_limit_id = ?").
+					WithArgs(2, money.New(1100), money.New(150), "late", 3, now, 2, 2).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.RestructureLoan(context.Background(), tt.req, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
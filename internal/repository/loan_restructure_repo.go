@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+type LoanRestructureRepository interface {
+	CreateLoanRestructure(ctx context.Context, restructure LoanRestructure, tx *sql.Tx) (int64, error)
+	GetLoanRestructuresByLoanID(ctx context.Context, loanID int64) ([]LoanRestructure, error)
+}
+
+type loanRestructureRepository struct {
+	db *sql.DB
+}
+
+func NewLoanRestructureRepository(db *sql.DB) LoanRestructureRepository {
+	return &loanRestructureRepository{db: db}
+}
+
+type (
+	LoanRestructure struct {
+		ID                          int64
+		LoanID                      int64
+		ContractNumber              string
+		RestructureType             string
+		PreviousConsumerLimitID     int64
+		ConsumerLimitID             int64
+		PreviousTenure              int16
+		Tenure                      int16
+		PreviousInstallmentAmount   money.Money
+		InstallmentAmount           money.Money
+		RestructuredPrincipalAmount money.Money
+		CapitalizedAmount           money.Money
+		PreviousLoanAmount          money.Money
+		LoanAmount                  money.Money
+		PreviousInterestAmount      money.Money
+		InterestAmount              money.Money
+		PreviousDueDate             time.Time
+		DueDate                     time.Time
+		Reason                      string
+		CreatedAt                   time.Time
+	}
+
+	LoanRestructureScanner struct {
+		ID                          sql.NullInt64
+		LoanID                      sql.NullInt64
+		ContractNumber              sql.NullString
+		RestructureType             sql.NullString
+		PreviousConsumerLimitID     sql.NullInt64
+		ConsumerLimitID             sql.NullInt64
+		PreviousTenure              sql.NullInt16
+		Tenure                      sql.NullInt16
+		PreviousInstallmentAmount   money.NullMoney
+		InstallmentAmount           money.NullMoney
+		RestructuredPrincipalAmount money.NullMoney
+		CapitalizedAmount           money.NullMoney
+		PreviousLoanAmount          money.NullMoney
+		LoanAmount                  money.NullMoney
+		PreviousInterestAmount      money.NullMoney
+		InterestAmount              money.NullMoney
+		PreviousDueDate             sql.NullTime
+		DueDate                     sql.NullTime
+		Reason                      sql.NullString
+		CreatedAt                   sql.NullTime
+	}
+)
+
+var (
+	ValidRestructureType = map[string]bool{
+		"extend_tenure":      true,
+		"reduce_installment": true,
+		"capitalize_arrears": true,
+	}
+)
+
+func (r *loanRestructureRepository) CreateLoanRestructure(ctx context.Context, restructure LoanRestructure, tx *sql.Tx) (id int64, err error) {
+	query := `
+		INSERT INTO loan_restructures (
+			loan_id,
+			contract_number,
+			restructure_type,
+			previous_consumer_limit_id,
+			consumer_limit_id,
+			previous_tenure,
+			tenure,
+			previous_installment_amount,
+			installment_amount,
+			restructured_principal_amount,
+			capitalized_amount,
+			previous_loan_amount,
+			loan_amount,
+			previous_interest_amount,
+			interest_amount,
+			previous_due_date,
+			due_date,
+			reason,
+			created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())
+	`
+
+	args := []interface{}{
+		restructure.LoanID,
+		restructure.ContractNumber,
+		restructure.RestructureType,
+		restructure.PreviousConsumerLimitID,
+		restructure.ConsumerLimitID,
+		restructure.PreviousTenure,
+		restructure.Tenure,
+		restructure.PreviousInstallmentAmount,
+		restructure.InstallmentAmount,
+		restructure.RestructuredPrincipalAmount,
+		restructure.CapitalizedAmount,
+		restructure.PreviousLoanAmount,
+		restructure.LoanAmount,
+		restructure.PreviousInterestAmount,
+		restructure.InterestAmount,
+		restructure.PreviousDueDate,
+		restructure.DueDate,
+		restructure.Reason,
+	}
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRestructureRepository][CreateLoanRestructure] while exec query. Err: %v", err))
+		return id, err
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRestructureRepository][CreateLoanRestructure] while get last insert id. Err: %v", err))
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *loanRestructureRepository) GetLoanRestructuresByLoanID(ctx context.Context, loanID int64) (result []LoanRestructure, err error) {
+	query := `
+		SELECT
+			loan_restructure_id,
+			loan_id,
+			contract_number,
+			restructure_type,
+			previous_consumer_limit_id,
+			consumer_limit_id,
+			previous_tenure,
+			tenure,
+			previous_installment_amount,
+			installment_amount,
+			restructured_principal_amount,
+			capitalized_amount,
+			previous_loan_amount,
+			loan_amount,
+			previous_interest_amount,
+			interest_amount,
+			previous_due_date,
+			due_date,
+			reason,
+			created_at
+		FROM loan_restructures
+		WHERE deleted_at IS NULL
+		AND loan_id = ?
+		ORDER BY loan_restructure_id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, loanID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[loanRestructureRepository][GetLoanRestructuresByLoanID] while query. Err: %v", err))
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var scanner LoanRestructureScanner
+		err = rows.Scan(
+			&scanner.ID,
+			&scanner.LoanID,
+			&scanner.ContractNumber,
+			&scanner.RestructureType,
+			&scanner.PreviousConsumerLimitID,
+			&scanner.ConsumerLimitID,
+			&scanner.PreviousTenure,
+			&scanner.Tenure,
+			&scanner.PreviousInstallmentAmount,
+			&scanner.InstallmentAmount,
+			&scanner.RestructuredPrincipalAmount,
+			&scanner.CapitalizedAmount,
+			&scanner.PreviousLoanAmount,
+			&scanner.LoanAmount,
+			&scanner.PreviousInterestAmount,
+			&scanner.InterestAmount,
+			&scanner.PreviousDueDate,
+			&scanner.DueDate,
+			&scanner.Reason,
+			&scanner.CreatedAt,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[loanRestructureRepository][GetLoanRestructuresByLoanID] while scan query row. Err: %v", err))
+			return result, err
+		}
+
+		result = append(result, LoanRestructure{
+			ID:                          scanner.ID.Int64,
+			LoanID:                      scanner.LoanID.Int64,
+			ContractNumber:              scanner.ContractNumber.String,
+			RestructureType:             scanner.RestructureType.String,
+			PreviousConsumerLimitID:     scanner.PreviousConsumerLimitID.Int64,
+			ConsumerLimitID:             scanner.ConsumerLimitID.Int64,
+			PreviousTenure:              scanner.PreviousTenure.Int16,
+			Tenure:                      scanner.Tenure.Int16,
+			PreviousInstallmentAmount:   scanner.PreviousInstallmentAmount.Money,
+			InstallmentAmount:           scanner.InstallmentAmount.Money,
+			RestructuredPrincipalAmount: scanner.RestructuredPrincipalAmount.Money,
+			CapitalizedAmount:           scanner.CapitalizedAmount.Money,
+			PreviousLoanAmount:          scanner.PreviousLoanAmount.Money,
+			LoanAmount:                  scanner.LoanAmount.Money,
+			PreviousInterestAmount:      scanner.PreviousInterestAmount.Money,
+			InterestAmount:              scanner.InterestAmount.Money,
+			PreviousDueDate:             scanner.PreviousDueDate.Time,
+			DueDate:                     scanner.DueDate.Time,
+			Reason:                      scanner.Reason.String,
+			CreatedAt:                   scanner.CreatedAt.Time,
+		})
+	}
+
+	return result, nil
+}
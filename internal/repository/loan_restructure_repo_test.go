@@ -0,0 +1,170 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateLoanRestructure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRestructureRepository(db)
+	now := time.Now()
+	restructure := repository.LoanRestructure{
+		LoanID:                      1,
+		ContractNumber:              "123",
+		RestructureType:             "extend_tenure",
+		PreviousConsumerLimitID:     1,
+		ConsumerLimitID:             2,
+		PreviousTenure:              6,
+		Tenure:                      12,
+		PreviousInstallmentAmount:   money.New(110),
+		InstallmentAmount:           money.New(55),
+		RestructuredPrincipalAmount: money.New(500),
+		PreviousLoanAmount:          money.New(600),
+		LoanAmount:                  money.New(600),
+		PreviousInterestAmount:      money.New(60),
+		InterestAmount:              money.New(70),
+		PreviousDueDate:             now,
+		DueDate:                     now,
+		Reason:                      "lost job",
+	}
+
+	tests := []struct {
+		name    string
+		want    int64
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "success",
+			want:    1,
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_restructures").
+					WithArgs(1, "123", "extend_tenure", 1, 2, 6, 12, money.New(110), money.New(55), money.New(500), money.Zero, money.New(600), money.New(600), money.New(60), money.New(70), now, now, "lost job").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "exec error",
+			want:    0,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO loan_restructures").
+					WithArgs(1, "123", "extend_tenure", 1, 2, 6, 12, money.New(110), money.New(55), money.New(500), money.Zero, money.New(600), money.New(600), money.New(60), money.New(70), now, now, "lost job").
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateLoanRestructure(context.Background(), restructure, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetLoanRestructuresByLoanID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewLoanRestructureRepository(db)
+	now := time.Now()
+
+	columns := []string{
+		"loan_restructure_id", "loan_id", "contract_number", "restructure_type", "previous_consumer_limit_id",
+		"consumer_limit_id", "previous_tenure", "tenure", "previous_installment_amount", "installment_amount",
+		"restructured_principal_amount", "capitalized_amount", "previous_loan_amount", "loan_amount",
+		"previous_interest_amount", "interest_amount", "previous_due_date", "due_date", "reason", "created_at",
+	}
+
+	tests := []struct {
+		name    string
+		loanID  int64
+		want    []repository.LoanRestructure
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:   "success",
+			loanID: 1,
+			want: []repository.LoanRestructure{
+				{
+					ID:                          1,
+					LoanID:                      1,
+					ContractNumber:              "123",
+					RestructureType:             "capitalize_arrears",
+					PreviousConsumerLimitID:     1,
+					ConsumerLimitID:             1,
+					PreviousTenure:              6,
+					Tenure:                      6,
+					PreviousInstallmentAmount:   money.New(110),
+					InstallmentAmount:           money.New(115),
+					RestructuredPrincipalAmount: money.New(520),
+					CapitalizedAmount:           money.New(20),
+					PreviousLoanAmount:          money.New(600),
+					LoanAmount:                  money.New(620),
+					PreviousInterestAmount:      money.New(60),
+					InterestAmount:              money.New(75),
+					PreviousDueDate:             now,
+					DueDate:                     now,
+					Reason:                      "lost job",
+					CreatedAt:                   now,
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 1, "123", "capitalize_arrears", 1, 1, 6, 6, 110.0, 115.0, 520.0, 20.0, 600.0, 620.0, 60.0, 75.0, now, now, "lost job", now)
+				mock.ExpectQuery("SELECT (.+) FROM loan_restructures WHERE deleted_at IS NULL AND loan_id = ?").
+					WithArgs(1).WillReturnRows(rows)
+			},
+		},
+		{
+			name:    "query error",
+			loanID:  2,
+			want:    nil,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM loan_restructures WHERE deleted_at IS NULL AND loan_id = ?").
+					WithArgs(2).WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetLoanRestructuresByLoanID(context.Background(), tt.loanID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+const MerchantNotificationLoanCancelled = "loan_cancelled"
+
+type MerchantNotificationRepository interface {
+	CreateMerchantNotification(ctx context.Context, notification MerchantNotification, tx *sql.Tx) (int64, error)
+	GetMerchantNotificationsByMerchantID(ctx context.Context, req FetchMerchantNotificationsRequest) ([]MerchantNotification, error)
+}
+
+type merchantNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewMerchantNotificationRepository(db *sql.DB) MerchantNotificationRepository {
+	return &merchantNotificationRepository{db: db}
+}
+
+type (
+	// MerchantNotification tells a merchant about a change to one of the loans financing its
+	// sales. RefundAmount is what the merchant has to pay back, when it was already paid for
+	// a loan that no longer stands.
+	MerchantNotification struct {
+		ID           int64
+		MerchantID   int64
+		LoanID       int64
+		EventType    string
+		Message      string
+		RefundAmount money.Money
+		CreatedAt    time.Time
+	}
+
+	MerchantNotificationScanner struct {
+		ID           sql.NullInt64
+		MerchantID   sql.NullInt64
+		LoanID       sql.NullInt64
+		EventType    sql.NullString
+		Message      sql.NullString
+		RefundAmount money.NullMoney
+		CreatedAt    sql.NullTime
+	}
+
+	FetchMerchantNotificationsRequest struct {
+		MerchantID int64
+		Limit      int
+		Offset     int
+	}
+)
+
+func (r *merchantNotificationRepository) CreateMerchantNotification(ctx context.Context, notification MerchantNotification, tx *sql.Tx) (id int64, err error) {
+	query := `
+		INSERT INTO merchant_notifications (
+			merchant_id,
+			loan_id,
+			event_type,
+			message,
+			refund_amount,
+			created_at
+		) VALUES (?, ?, ?, ?, ?, NOW())
+	`
+
+	args := []interface{}{
+		notification.MerchantID,
+		notification.LoanID,
+		notification.EventType,
+		notification.Message,
+		notification.RefundAmount,
+	}
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[merchantNotificationRepository][CreateMerchantNotification] while exec query. Err: %v", err))
+		return id, err
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[merchantNotificationRepository][CreateMerchantNotification] while get last insert id. Err: %v", err))
+		return id, err
+	}
+
+	return id, nil
+}
+
+// GetMerchantNotificationsByMerchantID returns the notifications of a merchant, latest first.
+func (r *merchantNotificationRepository) GetMerchantNotificationsByMerchantID(ctx context.Context, req FetchMerchantNotificationsRequest) (results []MerchantNotification, err error) {
+	query := `
+		SELECT
+			merchant_notification_id,
+			merchant_id,
+			loan_id,
+			event_type,
+			message,
+			refund_amount,
+			created_at
+		FROM merchant_notifications
+		WHERE merchant_id = ?
+		ORDER BY created_at DESC, merchant_notification_id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, req.MerchantID, req.Limit, req.Offset)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[merchantNotificationRepository][GetMerchantNotificationsByMerchantID] while query. Err: %v", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var notificationScanner MerchantNotificationScanner
+		err = rows.Scan(
+			&notificationScanner.ID,
+			&notificationScanner.MerchantID,
+			&notificationScanner.LoanID,
+			&notificationScanner.EventType,
+			&notificationScanner.Message,
+			&notificationScanner.RefundAmount,
+			&notificationScanner.CreatedAt,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[merchantNotificationRepository][GetMerchantNotificationsByMerchantID] while scan query row. Err: %v", err))
+			return nil, err
+		}
+
+		results = append(results, MerchantNotification{
+			ID:           notificationScanner.ID.Int64,
+			MerchantID:   notificationScanner.MerchantID.Int64,
+			LoanID:       notificationScanner.LoanID.Int64,
+			EventType:    notificationScanner.EventType.String,
+			Message:      notificationScanner.Message.String,
+			RefundAmount: notificationScanner.RefundAmount.Money,
+			CreatedAt:    notificationScanner.CreatedAt.Time,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,137 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateMerchantNotification(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewMerchantNotificationRepository(db)
+	notification := repository.MerchantNotification{
+		MerchantID:   2,
+		LoanID:       1,
+		EventType:    repository.MerchantNotificationLoanCancelled,
+		Message:      "Loan CN-1 was cancelled: changed mind",
+		RefundAmount: money.New(1000),
+	}
+
+	mock.ExpectBegin()
+	trx, _ := db.Begin()
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO merchant_notifications").
+					WithArgs(int64(2), int64(1), repository.MerchantNotificationLoanCancelled, notification.Message, money.New(1000)).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+			},
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name: "exec error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO merchant_notifications").
+					WithArgs(int64(2), int64(1), repository.MerchantNotificationLoanCancelled, notification.Message, money.New(1000)).
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateMerchantNotification(context.Background(), notification, trx)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetMerchantNotificationsByMerchantID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewMerchantNotificationRepository(db)
+	query := "SELECT (.+) FROM merchant_notifications WHERE merchant_id = \\? ORDER BY created_at DESC, merchant_notification_id DESC LIMIT \\? OFFSET \\?"
+	createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	columns := []string{"merchant_notification_id", "merchant_id", "loan_id", "event_type", "message", "refund_amount", "created_at"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []repository.MerchantNotification
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(2, 10, 0).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(3, 2, 1, repository.MerchantNotificationLoanCancelled, "Loan CN-1 was cancelled", "1000.000", createdAt))
+			},
+			want: []repository.MerchantNotification{
+				{
+					ID:           3,
+					MerchantID:   2,
+					LoanID:       1,
+					EventType:    repository.MerchantNotificationLoanCancelled,
+					Message:      "Loan CN-1 was cancelled",
+					RefundAmount: money.New(1000),
+					CreatedAt:    createdAt,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(2, 10, 0).WillReturnError(sql.ErrConnDone)
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetMerchantNotificationsByMerchantID(context.Background(), repository.FetchMerchantNotificationsRequest{MerchantID: 2, Limit: 10, Offset: 0})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+const (
+	PaymentCallbackStatusReceived  = "received"
+	PaymentCallbackStatusProcessed = "processed"
+	PaymentCallbackStatusFailed    = "failed"
+)
+
+type PaymentCallbackRepository interface {
+	CreatePaymentCallback(ctx context.Context, callback PaymentCallback) (bool, error)
+	GetPaymentCallbackByExternalID(ctx context.Context, provider string, externalID string) (PaymentCallback, error)
+	ClaimFailedPaymentCallback(ctx context.Context, id int64) (bool, error)
+	UpdatePaymentCallback(ctx context.Context, req UpdatePaymentCallbackRequest) error
+}
+
+type paymentCallbackRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentCallbackRepository(db *sql.DB) PaymentCallbackRepository {
+	return &paymentCallbackRepository{db: db}
+}
+
+type (
+	// PaymentCallback is a verified payment notification of a gateway. It stays received while
+	// it is being booked, and is processed once its transaction is created.
+	PaymentCallback struct {
+		ID               int64
+		Provider         string
+		ExternalID       string
+		VirtualAccountID int64
+		Amount           money.Money
+		PaidAt           time.Time
+		Payload          string
+		CallbackStatus   string
+		TransactionID    int64
+		FailureReason    string
+		CreatedAt        time.Time
+	}
+
+	PaymentCallbackScanner struct {
+		ID               sql.NullInt64
+		Provider         sql.NullString
+		ExternalID       sql.NullString
+		VirtualAccountID sql.NullInt64
+		Amount           money.NullMoney
+		PaidAt           sql.NullTime
+		Payload          sql.NullString
+		CallbackStatus   sql.NullString
+		TransactionID    sql.NullInt64
+		FailureReason    sql.NullString
+		CreatedAt        sql.NullTime
+	}
+
+	UpdatePaymentCallbackRequest struct {
+		ID             int64
+		CallbackStatus string
+		TransactionID  int64
+		FailureReason  string
+	}
+)
+
+// CreatePaymentCallback records a callback as received. It reports false when the gateway
+// already notified the same payment.
+func (r *paymentCallbackRepository) CreatePaymentCallback(ctx context.Context, callback PaymentCallback) (created bool, err error) {
+	query := `
+		INSERT INTO payment_callbacks (
+			provider,
+			external_id,
+			virtual_account_id,
+			amount,
+			paid_at,
+			payload,
+			callback_status,
+			created_at,
+			updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+		ON DUPLICATE KEY UPDATE external_id = external_id
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		callback.Provider,
+		callback.ExternalID,
+		callback.VirtualAccountID,
+		callback.Amount,
+		callback.PaidAt,
+		callback.Payload,
+		PaymentCallbackStatusReceived,
+	)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[paymentCallbackRepository][CreatePaymentCallback] while exec query. Err: %v", err))
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[paymentCallbackRepository][CreatePaymentCallback] while get rows affected. Err: %v", err))
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *paymentCallbackRepository) GetPaymentCallbackByExternalID(ctx context.Context, provider string, externalID string) (result PaymentCallback, err error) {
+	query := `
+		SELECT
+			payment_callback_id,
+			provider,
+			external_id,
+			virtual_account_id,
+			amount,
+			paid_at,
+			payload,
+			callback_status,
+			transaction_id,
+			failure_reason,
+			created_at
+		FROM payment_callbacks
+		WHERE provider = ?
+		AND external_id = ?
+	`
+
+	var scanner PaymentCallbackScanner
+	err = r.db.QueryRowContext(ctx, query, provider, externalID).Scan(
+		&scanner.ID,
+		&scanner.Provider,
+		&scanner.ExternalID,
+		&scanner.VirtualAccountID,
+		&scanner.Amount,
+		&scanner.PaidAt,
+		&scanner.Payload,
+		&scanner.CallbackStatus,
+		&scanner.TransactionID,
+		&scanner.FailureReason,
+		&scanner.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return result, nil
+		}
+
+		logger.Error(fmt.Sprintf("[paymentCallbackRepository][GetPaymentCallbackByExternalID] while scan query row. Err: %v", err))
+		return result, err
+	}
+
+	result = PaymentCallback{
+		ID:               scanner.ID.Int64,
+		Provider:         scanner.Provider.String,
+		ExternalID:       scanner.ExternalID.String,
+		VirtualAccountID: scanner.VirtualAccountID.Int64,
+		Amount:           scanner.Amount.Money,
+		PaidAt:           scanner.PaidAt.Time,
+		Payload:          scanner.Payload.String,
+		CallbackStatus:   scanner.CallbackStatus.String,
+		TransactionID:    scanner.TransactionID.Int64,
+		FailureReason:    scanner.FailureReason.String,
+		CreatedAt:        scanner.CreatedAt.Time,
+	}
+
+	return result, nil
+}
+
+// ClaimFailedPaymentCallback sets a failed callback back to received so a retry of the
+// notification can book it again. It reports false when the callback is not failed, which
+// happens when another retry claimed it first.
+func (r *paymentCallbackRepository) ClaimFailedPaymentCallback(ctx context.Context, id int64) (claimed bool, err error) {
+	query := `
+		UPDATE payment_callbacks
+		SET
+			callback_status = ?,
+			failure_reason = NULL,
+			updated_at = NOW()
+		WHERE payment_callback_id = ?
+		AND callback_status = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, PaymentCallbackStatusReceived, id, PaymentCallbackStatusFailed)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[paymentCallbackRepository][ClaimFailedPaymentCallback] while exec query. Err: %v", err))
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[paymentCallbackRepository][ClaimFailedPaymentCallback] while get rows affected. Err: %v", err))
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *paymentCallbackRepository) UpdatePaymentCallback(ctx context.Context, req UpdatePaymentCallbackRequest) (err error) {
+	query := `
+		UPDATE payment_callbacks
+		SET
+			callback_status = ?,
+			transaction_id = ?,
+			failure_reason = ?,
+			updated_at = NOW()
+		WHERE payment_callback_id = ?
+	`
+
+	var transactionID *int64
+	if req.TransactionID != 0 {
+		transactionID = &req.TransactionID
+	}
+
+	var failureReason *string
+	if req.FailureReason != "" {
+		failureReason = &req.FailureReason
+	}
+
+	_, err = r.db.ExecContext(ctx, query, req.CallbackStatus, transactionID, failureReason, req.ID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[paymentCallbackRepository][UpdatePaymentCallback] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
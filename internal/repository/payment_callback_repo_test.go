@@ -0,0 +1,166 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePaymentCallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPaymentCallbackRepository(db)
+	paidAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	callback := repository.PaymentCallback{Provider: "fake", ExternalID: "ext-1", VirtualAccountID: 3, Amount: money.New(1000), PaidAt: paidAt, Payload: "{}"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "created",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO payment_callbacks").
+					WithArgs("fake", "ext-1", 3, money.New(1000), paidAt, "{}", "received").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "already notified",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO payment_callbacks").
+					WithArgs("fake", "ext-1", 3, money.New(1000), paidAt, "{}", "received").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "exec error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO payment_callbacks").
+					WithArgs("fake", "ext-1", 3, money.New(1000), paidAt, "{}", "received").
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    false,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreatePaymentCallback(context.Background(), callback)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetPaymentCallbackByExternalID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPaymentCallbackRepository(db)
+	paidAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	columns := []string{"payment_callback_id", "provider", "external_id", "virtual_account_id", "amount", "paid_at", "payload", "callback_status", "transaction_id", "failure_reason", "created_at"}
+
+	mock.ExpectQuery("SELECT (.+) FROM payment_callbacks WHERE provider = \\? AND external_id = \\?").
+		WithArgs("fake", "ext-1").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "fake", "ext-1", 3, "1000.000", paidAt, "{}", "processed", 7, nil, paidAt))
+	got, err := repo.GetPaymentCallbackByExternalID(context.Background(), "fake", "ext-1")
+	assert.NoError(t, err)
+	assert.Equal(t, repository.PaymentCallback{
+		ID:               1,
+		Provider:         "fake",
+		ExternalID:       "ext-1",
+		VirtualAccountID: 3,
+		Amount:           money.New(1000),
+		PaidAt:           paidAt,
+		Payload:          "{}",
+		CallbackStatus:   "processed",
+		TransactionID:    7,
+		CreatedAt:        paidAt,
+	}, got)
+
+	mock.ExpectQuery("SELECT (.+) FROM payment_callbacks").
+		WithArgs("fake", "ext-2").
+		WillReturnError(sql.ErrNoRows)
+	got, err = repo.GetPaymentCallbackByExternalID(context.Background(), "fake", "ext-2")
+	assert.NoError(t, err)
+	assert.Equal(t, repository.PaymentCallback{}, got)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimFailedPaymentCallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPaymentCallbackRepository(db)
+
+	mock.ExpectExec("UPDATE payment_callbacks SET (.+) WHERE payment_callback_id = \\? AND callback_status = \\?").
+		WithArgs("received", 1, "failed").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	claimed, err := repo.ClaimFailedPaymentCallback(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	mock.ExpectExec("UPDATE payment_callbacks").
+		WithArgs("received", 1, "failed").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	claimed, err = repo.ClaimFailedPaymentCallback(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePaymentCallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPaymentCallbackRepository(db)
+
+	mock.ExpectExec("UPDATE payment_callbacks").
+		WithArgs("processed", 7, nil, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err = repo.UpdatePaymentCallback(context.Background(), repository.UpdatePaymentCallbackRequest{ID: 1, CallbackStatus: "processed", TransactionID: 7})
+	assert.NoError(t, err)
+
+	mock.ExpectExec("UPDATE payment_callbacks").
+		WithArgs("failed", nil, "loan not found", 1).
+		WillReturnError(sql.ErrConnDone)
+	err = repo.UpdatePaymentCallback(context.Background(), repository.UpdatePaymentCallbackRequest{ID: 1, CallbackStatus: "failed", FailureReason: "loan not found"})
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+type ReceiptRepository interface {
+	NextReceiptSequence(ctx context.Context, period string, tx *sql.Tx) (int64, error)
+	CreateReceipt(ctx context.Context, receipt Receipt, tx *sql.Tx) (int64, error)
+	GetReceiptByTransactionID(ctx context.Context, transactionID int64) (Receipt, error)
+}
+
+type receiptRepository struct {
+	db *sql.DB
+}
+
+func NewReceiptRepository(db *sql.DB) ReceiptRepository {
+	return &receiptRepository{db: db}
+}
+
+type (
+	// Receipt is issued for a payment, with what was left to pay on the loan once the
+	// payment was booked.
+	Receipt struct {
+		ID                       int64
+		ReceiptNumber            string
+		TransactionID            int64
+		LoanID                   int64
+		RemainingPrincipalAmount money.Money
+		RemainingInterestAmount  money.Money
+		IssuedAt                 time.Time
+	}
+
+	ReceiptScanner struct {
+		ID                       sql.NullInt64
+		ReceiptNumber            sql.NullString
+		TransactionID            sql.NullInt64
+		LoanID                   sql.NullInt64
+		RemainingPrincipalAmount money.NullMoney
+		RemainingInterestAmount  money.NullMoney
+		IssuedAt                 sql.NullTime
+	}
+)
+
+// NextReceiptSequence takes the next number of the receipts of a period within tx. The
+// sequence row stays locked until tx ends and a rolled back number is taken again, so the
+// numbers of a period have no gap.
+func (r *receiptRepository) NextReceiptSequence(ctx context.Context, period string, tx *sql.Tx) (number int64, err error) {
+	query := `
+		INSERT INTO receipt_sequences (
+			period,
+			last_number,
+			created_at,
+			updated_at
+		) VALUES (?, 1, NOW(), NOW())
+		ON DUPLICATE KEY UPDATE
+			last_number = last_number + 1,
+			updated_at = NOW()
+	`
+
+	_, err = tx.ExecContext(ctx, query, period)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[receiptRepository][NextReceiptSequence] while exec query. Err: %v", err))
+		return number, err
+	}
+
+	query = `
+		SELECT
+			last_number
+		FROM receipt_sequences
+		WHERE period = ?
+	`
+
+	err = tx.QueryRowContext(ctx, query, period).Scan(&number)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[receiptRepository][NextReceiptSequence] while scan query row. Err: %v", err))
+		return number, err
+	}
+
+	return number, nil
+}
+
+func (r *receiptRepository) CreateReceipt(ctx context.Context, receipt Receipt, tx *sql.Tx) (id int64, err error) {
+	query := `
+		INSERT INTO receipts (
+			receipt_number,
+			transaction_id,
+			loan_id,
+			remaining_principal_amount,
+			remaining_interest_amount,
+			issued_at,
+			created_at
+		) VALUES (?, ?, ?, ?, ?, ?, NOW())
+	`
+
+	args := []interface{}{
+		receipt.ReceiptNumber,
+		receipt.TransactionID,
+		receipt.LoanID,
+		receipt.RemainingPrincipalAmount,
+		receipt.RemainingInterestAmount,
+		receipt.IssuedAt,
+	}
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[receiptRepository][CreateReceipt] while exec query. Err: %v", err))
+		return id, err
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[receiptRepository][CreateReceipt] while get last insert id. Err: %v", err))
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *receiptRepository) GetReceiptByTransactionID(ctx context.Context, transactionID int64) (result Receipt, err error) {
+	query := `
+		SELECT
+			receipt_id,
+			receipt_number,
+			transaction_id,
+			loan_id,
+			remaining_principal_amount,
+			remaining_interest_amount,
+			issued_at
+		FROM receipts
+		WHERE transaction_id = ?
+	`
+
+	var receiptScanner ReceiptScanner
+	err = r.db.QueryRowContext(ctx, query, transactionID).Scan(
+		&receiptScanner.ID,
+		&receiptScanner.ReceiptNumber,
+		&receiptScanner.TransactionID,
+		&receiptScanner.LoanID,
+		&receiptScanner.RemainingPrincipalAmount,
+		&receiptScanner.RemainingInterestAmount,
+		&receiptScanner.IssuedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return result, nil
+		}
+
+		logger.Error(fmt.Sprintf("[receiptRepository][GetReceiptByTransactionID] while scan query row. Err: %v", err))
+		return result, err
+	}
+
+	result = Receipt{
+		ID:                       receiptScanner.ID.Int64,
+		ReceiptNumber:            receiptScanner.ReceiptNumber.String,
+		TransactionID:            receiptScanner.TransactionID.Int64,
+		LoanID:                   receiptScanner.LoanID.Int64,
+		RemainingPrincipalAmount: receiptScanner.RemainingPrincipalAmount.Money,
+		RemainingInterestAmount:  receiptScanner.RemainingInterestAmount.Money,
+		IssuedAt:                 receiptScanner.IssuedAt.Time,
+	}
+
+	return result, nil
+}
@@ -0,0 +1,210 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextReceiptSequence(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewReceiptRepository(db)
+	query := "SELECT last_number FROM receipt_sequences WHERE period = \\?"
+
+	mock.ExpectBegin()
+	trx, _ := db.Begin()
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO receipt_sequences (.+) ON DUPLICATE KEY UPDATE").
+					WithArgs("202401").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectQuery(query).WithArgs("202401").WillReturnRows(sqlmock.NewRows([]string{"last_number"}).AddRow(7))
+			},
+			want:    7,
+			wantErr: false,
+		},
+		{
+			name: "exec error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO receipt_sequences").
+					WithArgs("202401").
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO receipt_sequences").
+					WithArgs("202401").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(query).WithArgs("202401").WillReturnError(sql.ErrConnDone)
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.NextReceiptSequence(context.Background(), "202401", trx)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCreateReceipt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewReceiptRepository(db)
+	issuedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	receipt := repository.Receipt{
+		ReceiptNumber:            "RCP-202401-000007",
+		TransactionID:            2,
+		LoanID:                   1,
+		RemainingPrincipalAmount: money.New(900),
+		RemainingInterestAmount:  money.New(90),
+		IssuedAt:                 issuedAt,
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO receipts").
+					WithArgs("RCP-202401-000007", 2, 1, money.New(900), money.New(90), issuedAt).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+			},
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name: "exec error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO receipts").
+					WithArgs("RCP-202401-000007", 2, 1, money.New(900), money.New(90), issuedAt).
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateReceipt(context.Background(), receipt, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetReceiptByTransactionID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewReceiptRepository(db)
+	query := "SELECT (.+) FROM receipts WHERE transaction_id = \\?"
+	issuedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    repository.Receipt
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"receipt_id", "receipt_number", "transaction_id", "loan_id", "remaining_principal_amount", "remaining_interest_amount", "issued_at"}).
+					AddRow(3, "RCP-202401-000007", 2, 1, "900.000", "90.000", issuedAt)
+				mock.ExpectQuery(query).WithArgs(2).WillReturnRows(rows)
+			},
+			want: repository.Receipt{
+				ID:                       3,
+				ReceiptNumber:            "RCP-202401-000007",
+				TransactionID:            2,
+				LoanID:                   1,
+				RemainingPrincipalAmount: money.New(900),
+				RemainingInterestAmount:  money.New(90),
+				IssuedAt:                 issuedAt,
+			},
+			wantErr: false,
+		},
+		{
+			name: "no rows",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(2).WillReturnError(sql.ErrNoRows)
+			},
+			want:    repository.Receipt{},
+			wantErr: false,
+		},
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs(2).WillReturnError(sql.ErrConnDone)
+			},
+			want:    repository.Receipt{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetReceiptByTransactionID(context.Background(), 2)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+type SettlementQuoteRepository interface {
+	CreateSettlementQuote(ctx context.Context, quote SettlementQuote) (int64, error)
+	GetSettlementQuoteByID(ctx context.Context, quoteID int64) (SettlementQuote, error)
+	UseSettlementQuote(ctx context.Context, quoteID int64, transactionID int64, tx *sql.Tx) (bool, error)
+}
+
+type settlementQuoteRepository struct {
+	db *sql.DB
+}
+
+func NewSettlementQuoteRepository(db *sql.DB) SettlementQuoteRepository {
+	return &settlementQuoteRepository{db: db}
+}
+
+type (
+	SettlementQuote struct {
+		ID                       int64
+		LoanID                   int64
+		ConsumerID               int64
+		RemainingPrincipalAmount money.Money
+		EarnedInterestAmount     money.Money
+		InterestRebateAmount     money.Money
+		PenaltyAmount            money.Money
+		SettlementFeeAmount      money.Money
+		TotalAmount              money.Money
+		QuotedAt                 time.Time
+		ExpiredAt                time.Time
+		TransactionID            int64
+		UsedAt                   time.Time
+	}
+
+	SettlementQuoteScanner struct {
+		ID                       sql.NullInt64
+		LoanID                   sql.NullInt64
+		ConsumerID               sql.NullInt64
+		RemainingPrincipalAmount money.NullMoney
+		EarnedInterestAmount     money.NullMoney
+		InterestRebateAmount     money.NullMoney
+		PenaltyAmount            money.NullMoney
+		SettlementFeeAmount      money.NullMoney
+		TotalAmount              money.NullMoney
+		QuotedAt                 sql.NullTime
+		ExpiredAt                sql.NullTime
+		TransactionID            sql.NullInt64
+		UsedAt                   sql.NullTime
+	}
+)
+
+func (r *settlementQuoteRepository) CreateSettlementQuote(ctx context.Context, quote SettlementQuote) (id int64, err error) {
+	query := `
+		INSERT INTO settlement_quotes (
+			loan_id,
+			consumer_id,
+			remaining_principal_amount,
+			earned_interest_amount,
+			interest_rebate_amount,
+			penalty_amount,
+			settlement_fee_amount,
+			total_amount,
+			quoted_at,
+			expired_at,
+			created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		quote.LoanID,
+		quote.ConsumerID,
+		quote.RemainingPrincipalAmount,
+		quote.EarnedInterestAmount,
+		quote.InterestRebateAmount,
+		quote.PenaltyAmount,
+		quote.SettlementFeeAmount,
+		quote.TotalAmount,
+		quote.QuotedAt,
+		quote.ExpiredAt,
+	)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[settlementQuoteRepository][CreateSettlementQuote] while exec query. Err: %v", err))
+		return id, err
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[settlementQuoteRepository][CreateSettlementQuote] while get last insert id. Err: %v", err))
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *settlementQuoteRepository) GetSettlementQuoteByID(ctx context.Context, quoteID int64) (result SettlementQuote, err error) {
+	query := `
+		SELECT
+			settlement_quote_id,
+			loan_id,
+			consumer_id,
+			remaining_principal_amount,
+			earned_interest_amount,
+			interest_rebate_amount,
+			penalty_amount,
+			settlement_fee_amount,
+			total_amount,
+			quoted_at,
+			expired_at,
+			transaction_id,
+			used_at
+		FROM settlement_quotes
+		WHERE deleted_at IS NULL
+		AND settlement_quote_id = ?
+	`
+
+	var scanner SettlementQuoteScanner
+	err = r.db.QueryRowContext(ctx, query, quoteID).Scan(
+		&scanner.ID,
+		&scanner.LoanID,
+		&scanner.ConsumerID,
+		&scanner.RemainingPrincipalAmount,
+		&scanner.EarnedInterestAmount,
+		&scanner.InterestRebateAmount,
+		&scanner.PenaltyAmount,
+		&scanner.SettlementFeeAmount,
+		&scanner.TotalAmount,
+		&scanner.QuotedAt,
+		&scanner.ExpiredAt,
+		&scanner.TransactionID,
+		&scanner.UsedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return result, nil
+		}
+
+		logger.Error(fmt.Sprintf("[settlementQuoteRepository][GetSettlementQuoteByID] while scan query row. Err: %v", err))
+		return result, err
+	}
+
+	result = SettlementQuote{
+		ID:                       scanner.ID.Int64,
+		LoanID:                   scanner.LoanID.Int64,
+		ConsumerID:               scanner.ConsumerID.Int64,
+		RemainingPrincipalAmount: scanner.RemainingPrincipalAmount.Money,
+		EarnedInterestAmount:     scanner.EarnedInterestAmount.Money,
+		InterestRebateAmount:     scanner.InterestRebateAmount.Money,
+		PenaltyAmount:            scanner.PenaltyAmount.Money,
+		SettlementFeeAmount:      scanner.SettlementFeeAmount.Money,
+		TotalAmount:              scanner.TotalAmount.Money,
+		QuotedAt:                 scanner.QuotedAt.Time,
+		ExpiredAt:                scanner.ExpiredAt.Time,
+		TransactionID:            scanner.TransactionID.Int64,
+		UsedAt:                   scanner.UsedAt.Time,
+	}
+
+	return result, nil
+}
+
+// UseSettlementQuote links a quote to the transaction that paid it. It reports false when
+// the quote was already used, so a quote can only ever be paid once.
+func (r *settlementQuoteRepository) UseSettlementQuote(ctx context.Context, quoteID int64, transactionID int64, tx *sql.Tx) (used bool, err error) {
+	query := `
+		UPDATE settlement_quotes
+		SET
+			transaction_id = ?,
+			used_at = NOW(),
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND settlement_quote_id = ?
+		AND used_at IS NULL
+	`
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, transactionID, quoteID)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, transactionID, quoteID)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[settlementQuoteRepository][UseSettlementQuote] while exec query. Err: %v", err))
+		return used, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[settlementQuoteRepository][UseSettlementQuote] while get rows affected. Err: %v", err))
+		return used, err
+	}
+
+	return affected > 0, nil
+}
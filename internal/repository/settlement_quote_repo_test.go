@@ -0,0 +1,225 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSettlementQuote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSettlementQuoteRepository(db)
+	now := time.Now()
+	quote := repository.SettlementQuote{
+		LoanID:                   1,
+		ConsumerID:               1,
+		RemainingPrincipalAmount: money.New(500),
+		EarnedInterestAmount:     money.New(10),
+		InterestRebateAmount:     money.New(40),
+		SettlementFeeAmount:      money.New(5),
+		TotalAmount:              money.New(515),
+		QuotedAt:                 now,
+		ExpiredAt:                now.Add(time.Hour),
+	}
+
+	tests := []struct {
+		name    string
+		want    int64
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "success",
+			want:    1,
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO settlement_quotes").
+					WithArgs(1, 1, money.New(500), money.New(10), money.New(40), money.Zero, money.New(5), money.New(515), now, now.Add(time.Hour)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "exec error",
+			want:    0,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO settlement_quotes").
+					WithArgs(1, 1, money.New(500), money.New(10), money.New(40), money.Zero, money.New(5), money.New(515), now, now.Add(time.Hour)).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateSettlementQuote(context.Background(), quote)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetSettlementQuoteByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSettlementQuoteRepository(db)
+	now := time.Now()
+
+	columns := []string{
+		"settlement_quote_id", "loan_id", "consumer_id", "remaining_principal_amount", "earned_interest_amount",
+		"interest_rebate_amount", "penalty_amount", "settlement_fee_amount", "total_amount", "quoted_at",
+		"expired_at", "transaction_id", "used_at",
+	}
+
+	tests := []struct {
+		name    string
+		quoteID int64
+		want    repository.SettlementQuote
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "success",
+			quoteID: 1,
+			want: repository.SettlementQuote{
+				ID:                       1,
+				LoanID:                   1,
+				ConsumerID:               1,
+				RemainingPrincipalAmount: money.New(500),
+				EarnedInterestAmount:     money.New(10),
+				InterestRebateAmount:     money.New(40),
+				SettlementFeeAmount:      money.New(5),
+				TotalAmount:              money.New(515),
+				QuotedAt:                 now,
+				ExpiredAt:                now,
+			},
+			wantErr: false,
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 1, 1, 500.0, 10.0, 40.0, 0.0, 5.0, 515.0, now, now, nil, nil)
+				mock.ExpectQuery("SELECT (.+) FROM settlement_quotes WHERE deleted_at IS NULL AND settlement_quote_id = ?").
+					WithArgs(1).WillReturnRows(rows)
+			},
+		},
+		{
+			name:    "not found",
+			quoteID: 2,
+			want:    repository.SettlementQuote{},
+			wantErr: false,
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM settlement_quotes WHERE deleted_at IS NULL AND settlement_quote_id = ?").
+					WithArgs(2).WillReturnError(sql.ErrNoRows)
+			},
+		},
+		{
+			name:    "query error",
+			quoteID: 3,
+			want:    repository.SettlementQuote{},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM settlement_quotes WHERE deleted_at IS NULL AND settlement_quote_id = ?").
+					WithArgs(3).WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.GetSettlementQuoteByID(context.Background(), tt.quoteID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUseSettlementQuote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSettlementQuoteRepository(db)
+
+	tests := []struct {
+		name    string
+		quoteID int64
+		want    bool
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name:    "success",
+			quoteID: 1,
+			want:    true,
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("UPDATE settlement_quotes").
+					WithArgs(10, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "already used",
+			quoteID: 2,
+			want:    false,
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("UPDATE settlement_quotes").
+					WithArgs(10, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:    "exec error",
+			quoteID: 3,
+			want:    false,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("UPDATE settlement_quotes").
+					WithArgs(10, 3).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.UseSettlementQuote(context.Background(), tt.quoteID, 10, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,570 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+const (
+	StatementLineStatusPending   = "pending"
+	StatementLineStatusMatched   = "matched"
+	StatementLineStatusUnmatched = "unmatched"
+	StatementLineStatusAmbiguous = "ambiguous"
+	StatementLineStatusIgnored   = "ignored"
+)
+
+type StatementRepository interface {
+	CreateStatementImport(ctx context.Context, statementImport StatementImport) (int64, error)
+	CreateStatementLine(ctx context.Context, line StatementLine) (int64, error)
+	GetStatementLineByID(ctx context.Context, id int64) (StatementLine, error)
+	GetStatementLineByTransactionID(ctx context.Context, transactionID int64) (StatementLine, error)
+	FetchStatementLines(ctx context.Context, req FetchStatementLinesRequest) ([]StatementLine, error)
+	ClaimStatementLine(ctx context.Context, id int64) (bool, error)
+	UpdateStatementLine(ctx context.Context, req UpdateStatementLineRequest) error
+	GetUnreconciledCallbackTransactionID(ctx context.Context, virtualAccountID int64, amount money.Money, from time.Time, to time.Time) (int64, error)
+}
+
+type statementRepository struct {
+	db *sql.DB
+}
+
+func NewStatementRepository(db *sql.DB) StatementRepository {
+	return &statementRepository{db: db}
+}
+
+type (
+	StatementImport struct {
+		ID              int64
+		FileName        string
+		StatementFormat string
+		CreatedAt       time.Time
+	}
+
+	// StatementLine is a line of an imported bank statement. It is pending while its payment
+	// is being booked, matched once it is reconciled with a transaction, and unmatched or
+	// ambiguous while it waits for a manual match. CandidateLoanIDs lists, separated by
+	// commas, the loans an ambiguous line could be a payment of.
+	StatementLine struct {
+		ID                int64
+		StatementImportID int64
+		LineNumber        int32
+		ValueDate         time.Time
+		Amount            money.Money
+		Description       string
+		Reference         string
+		Fingerprint       string
+		LineStatus        string
+		MatchMethod       string
+		LoanID            int64
+		TransactionID     int64
+		CandidateLoanIDs  string
+		Note              string
+		MatchedBy         string
+		MatchedAt         time.Time
+		CreatedAt         time.Time
+	}
+
+	StatementLineScanner struct {
+		ID                sql.NullInt64
+		StatementImportID sql.NullInt64
+		LineNumber        sql.NullInt32
+		ValueDate         sql.NullTime
+		Amount            money.NullMoney
+		Description       sql.NullString
+		Reference         sql.NullString
+		Fingerprint       sql.NullString
+		LineStatus        sql.NullString
+		MatchMethod       sql.NullString
+		LoanID            sql.NullInt64
+		TransactionID     sql.NullInt64
+		CandidateLoanIDs  sql.NullString
+		Note              sql.NullString
+		MatchedBy         sql.NullString
+		MatchedAt         sql.NullTime
+		CreatedAt         sql.NullTime
+	}
+
+	FetchStatementLinesRequest struct {
+		StatementImportID int64
+		LineStatuses      []string
+		Limit             int
+		Offset            int
+	}
+
+	UpdateStatementLineRequest struct {
+		ID               int64
+		LineStatus       string
+		MatchMethod      string
+		LoanID           int64
+		TransactionID    int64
+		CandidateLoanIDs string
+		Note             string
+		MatchedBy        string
+	}
+)
+
+func (r *statementRepository) CreateStatementImport(ctx context.Context, statementImport StatementImport) (id int64, err error) {
+	query := `
+		INSERT INTO statement_imports (
+			file_name,
+			statement_format,
+			created_at,
+			updated_at
+		) VALUES (?, ?, NOW(), NOW())
+	`
+
+	result, err := r.db.ExecContext(ctx, query, statementImport.FileName, statementImport.StatementFormat)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[statementRepository][CreateStatementImport] while exec query. Err: %v", err))
+		return 0, err
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[statementRepository][CreateStatementImport] while get last insert id. Err: %v", err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// CreateStatementLine stores a statement line. It returns 0 when a line with the same
+// fingerprint was imported before.
+func (r *statementRepository) CreateStatementLine(ctx context.Context, line StatementLine) (id int64, err error) {
+	query := `
+		INSERT INTO statement_lines (
+			statement_import_id,
+			line_number,
+			value_date,
+			amount,
+			description,
+			reference,
+			fingerprint,
+			line_status,
+			note,
+			created_at,
+			updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+		ON DUPLICATE KEY UPDATE fingerprint = fingerprint
+	`
+
+	var note *string
+	if line.Note != "" {
+		note = &line.Note
+	}
+
+	result, err := r.db.ExecContext(ctx, query,
+		line.StatementImportID,
+		line.LineNumber,
+		line.ValueDate,
+		line.Amount,
+		line.Description,
+		line.Reference,
+		line.Fingerprint,
+		line.LineStatus,
+		note,
+	)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[statementRepository][CreateStatementLine] while exec query. Err: %v", err))
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[statementRepository][CreateStatementLine] while get rows affected. Err: %v", err))
+		return 0, err
+	}
+	if rowsAffected != 1 {
+		return 0, nil
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[statementRepository][CreateStatementLine] while get last insert id. Err: %v", err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *statementRepository) GetStatementLineByID(ctx context.Context, id int64) (result StatementLine, err error) {
+	query := `
+		SELECT
+			statement_line_id,
+			statement_import_id,
+			line_number,
+			value_date,
+			amount,
+			description,
+			reference,
+			fingerprint,
+			line_status,
+			match_method,
+			loan_id,
+			transaction_id,
+			candidate_loan_ids,
+			note,
+			matched_by,
+			matched_at,
+			created_at
+		FROM statement_lines
+		WHERE statement_line_id = ?
+	`
+
+	var scanner StatementLineScanner
+	err = r.db.QueryRowContext(ctx, query, id).Scan(
+		&scanner.ID,
+		&scanner.StatementImportID,
+		&scanner.LineNumber,
+		&scanner.ValueDate,
+		&scanner.Amount,
+		&scanner.Description,
+		&scanner.Reference,
+		&scanner.Fingerprint,
+		&scanner.LineStatus,
+		&scanner.MatchMethod,
+		&scanner.LoanID,
+		&scanner.TransactionID,
+		&scanner.CandidateLoanIDs,
+		&scanner.Note,
+		&scanner.MatchedBy,
+		&scanner.MatchedAt,
+		&scanner.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return result, nil
+		}
+
+		logger.Error(fmt.Sprintf("[statementRepository][GetStatementLineByID] while scan query row. Err: %v", err))
+		return result, err
+	}
+
+	result = StatementLine{
+		ID:                scanner.ID.Int64,
+		StatementImportID: scanner.StatementImportID.Int64,
+		LineNumber:        scanner.LineNumber.Int32,
+		ValueDate:         scanner.ValueDate.Time,
+		Amount:            scanner.Amount.Money,
+		Description:       scanner.Description.String,
+		Reference:         scanner.Reference.String,
+		Fingerprint:       scanner.Fingerprint.String,
+		LineStatus:        scanner.LineStatus.String,
+		MatchMethod:       scanner.MatchMethod.String,
+		LoanID:            scanner.LoanID.Int64,
+		TransactionID:     scanner.TransactionID.Int64,
+		CandidateLoanIDs:  scanner.CandidateLoanIDs.String,
+		Note:              scanner.Note.String,
+		MatchedBy:         scanner.MatchedBy.String,
+		MatchedAt:         scanner.MatchedAt.Time,
+		CreatedAt:         scanner.CreatedAt.Time,
+	}
+
+	return result, nil
+}
+
+func (r *statementRepository) GetStatementLineByTransactionID(ctx context.Context, transactionID int64) (result StatementLine, err error) {
+	query := `
+		SELECT
+			statement_line_id,
+			statement_import_id,
+			line_number,
+			value_date,
+			amount,
+			description,
+			reference,
+			fingerprint,
+			line_status,
+			match_method,
+			loan_id,
+			transaction_id,
+			candidate_loan_ids,
+			note,
+			matched_by,
+			matched_at,
+			created_at
+		FROM statement_lines
+		WHERE transaction_id = ?
+	`
+
+	var scanner StatementLineScanner
+	err = r.db.QueryRowContext(ctx, query, transactionID).Scan(
+		&scanner.ID,
+		&scanner.StatementImportID,
+		&scanner.LineNumber,
+		&scanner.ValueDate,
+		&scanner.Amount,
+		&scanner.Description,
+		&scanner.Reference,
+		&scanner.Fingerprint,
+		&scanner.LineStatus,
+		&scanner.MatchMethod,
+		&scanner.LoanID,
+		&scanner.TransactionID,
+		&scanner.CandidateLoanIDs,
+		&scanner.Note,
+		&scanner.MatchedBy,
+		&scanner.MatchedAt,
+		&scanner.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return result, nil
+		}
+
+		logger.Error(fmt.Sprintf("[statementRepository][GetStatementLineByTransactionID] while scan query row. Err: %v", err))
+		return result, err
+	}
+
+	result = StatementLine{
+		ID:                scanner.ID.Int64,
+		StatementImportID: scanner.StatementImportID.Int64,
+		LineNumber:        scanner.LineNumber.Int32,
+		ValueDate:         scanner.ValueDate.Time,
+		Amount:            scanner.Amount.Money,
+		Description:       scanner.Description.String,
+		Reference:         scanner.Reference.String,
+		Fingerprint:       scanner.Fingerprint.String,
+		LineStatus:        scanner.LineStatus.String,
+		MatchMethod:       scanner.MatchMethod.String,
+		LoanID:            scanner.LoanID.Int64,
+		TransactionID:     scanner.TransactionID.Int64,
+		CandidateLoanIDs:  scanner.CandidateLoanIDs.String,
+		Note:              scanner.Note.String,
+		MatchedBy:         scanner.MatchedBy.String,
+		MatchedAt:         scanner.MatchedAt.Time,
+		CreatedAt:         scanner.CreatedAt.Time,
+	}
+
+	return result, nil
+}
+
+func (r *statementRepository) FetchStatementLines(ctx context.Context, req FetchStatementLinesRequest) (results []StatementLine, err error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	if req.StatementImportID != 0 {
+		conditions = append(conditions, "statement_import_id = ?")
+		args = append(args, req.StatementImportID)
+	}
+	if len(req.LineStatuses) > 0 {
+		placeholders := make([]string, 0, len(req.LineStatuses))
+		for _, status := range req.LineStatuses {
+			placeholders = append(placeholders, "?")
+			args = append(args, status)
+		}
+		conditions = append(conditions, "line_status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	query := `
+		SELECT
+			statement_line_id,
+			statement_import_id,
+			line_number,
+			value_date,
+			amount,
+			description,
+			reference,
+			fingerprint,
+			line_status,
+			match_method,
+			loan_id,
+			transaction_id,
+			candidate_loan_ids,
+			note,
+			matched_by,
+			matched_at,
+			created_at
+		FROM statement_lines
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY statement_line_id ASC
+		LIMIT ? OFFSET ?
+	`
+	args = append(args, req.Limit, req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[statementRepository][FetchStatementLines] while query. Err: %v", err))
+		return results, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var scanner StatementLineScanner
+		err = rows.Scan(
+			&scanner.ID,
+			&scanner.StatementImportID,
+			&scanner.LineNumber,
+			&scanner.ValueDate,
+			&scanner.Amount,
+			&scanner.Description,
+			&scanner.Reference,
+			&scanner.Fingerprint,
+			&scanner.LineStatus,
+			&scanner.MatchMethod,
+			&scanner.LoanID,
+			&scanner.TransactionID,
+			&scanner.CandidateLoanIDs,
+			&scanner.Note,
+			&scanner.MatchedBy,
+			&scanner.MatchedAt,
+			&scanner.CreatedAt,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[statementRepository][FetchStatementLines] while scan query row. Err: %v", err))
+			return results, err
+		}
+
+		results = append(results, StatementLine{
+			ID:                scanner.ID.Int64,
+			StatementImportID: scanner.StatementImportID.Int64,
+			LineNumber:        scanner.LineNumber.Int32,
+			ValueDate:         scanner.ValueDate.Time,
+			Amount:            scanner.Amount.Money,
+			Description:       scanner.Description.String,
+			Reference:         scanner.Reference.String,
+			Fingerprint:       scanner.Fingerprint.String,
+			LineStatus:        scanner.LineStatus.String,
+			MatchMethod:       scanner.MatchMethod.String,
+			LoanID:            scanner.LoanID.Int64,
+			TransactionID:     scanner.TransactionID.Int64,
+			CandidateLoanIDs:  scanner.CandidateLoanIDs.String,
+			Note:              scanner.Note.String,
+			MatchedBy:         scanner.MatchedBy.String,
+			MatchedAt:         scanner.MatchedAt.Time,
+			CreatedAt:         scanner.CreatedAt.Time,
+		})
+	}
+
+	return results, nil
+}
+
+// ClaimStatementLine sets an unmatched or ambiguous line to pending so it can be matched
+// manually. It reports false when the line is not waiting for a match, which happens when
+// another request claimed it first.
+func (r *statementRepository) ClaimStatementLine(ctx context.Context, id int64) (claimed bool, err error) {
+	query := `
+		UPDATE statement_lines
+		SET
+			line_status = ?,
+			updated_at = NOW()
+		WHERE statement_line_id = ?
+		AND line_status IN (?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, StatementLineStatusPending, id, StatementLineStatusUnmatched, StatementLineStatusAmbiguous)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[statementRepository][ClaimStatementLine] while exec query. Err: %v", err))
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[statementRepository][ClaimStatementLine] while get rows affected. Err: %v", err))
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *statementRepository) UpdateStatementLine(ctx context.Context, req UpdateStatementLineRequest) (err error) {
+	query := `
+		UPDATE statement_lines
+		SET
+			line_status = ?,
+			match_method = ?,
+			loan_id = ?,
+			transaction_id = ?,
+			candidate_loan_ids = ?,
+			note = ?,
+			matched_by = ?,
+			matched_at = CASE WHEN line_status = ? THEN NOW() ELSE NULL END,
+			updated_at = NOW()
+		WHERE statement_line_id = ?
+	`
+
+	var matchMethod *string
+	if req.MatchMethod != "" {
+		matchMethod = &req.MatchMethod
+	}
+
+	var loanID *int64
+	if req.LoanID != 0 {
+		loanID = &req.LoanID
+	}
+
+	var transactionID *int64
+	if req.TransactionID != 0 {
+		transactionID = &req.TransactionID
+	}
+
+	var candidateLoanIDs *string
+	if req.CandidateLoanIDs != "" {
+		candidateLoanIDs = &req.CandidateLoanIDs
+	}
+
+	var note *string
+	if req.Note != "" {
+		note = &req.Note
+	}
+
+	var matchedBy *string
+	if req.MatchedBy != "" {
+		matchedBy = &req.MatchedBy
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
+		req.LineStatus,
+		matchMethod,
+		loanID,
+		transactionID,
+		candidateLoanIDs,
+		note,
+		matchedBy,
+		StatementLineStatusMatched,
+		req.ID,
+	)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[statementRepository][UpdateStatementLine] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+// GetUnreconciledCallbackTransactionID returns the transaction booked by the earliest
+// processed callback of a virtual account for amount, paid between from and to, that is
+// not reconciled with a statement line yet. It returns 0 when there is none.
+func (r *statementRepository) GetUnreconciledCallbackTransactionID(ctx context.Context, virtualAccountID int64, amount money.Money, from time.Time, to time.Time) (transactionID int64, err error) {
+	query := `
+		SELECT pc.transaction_id
+		FROM payment_callbacks pc
+		LEFT JOIN statement_lines sl ON sl.transaction_id = pc.transaction_id
+		WHERE pc.virtual_account_id = ?
+		AND pc.callback_status = ?
+		AND pc.amount = ?
+		AND pc.paid_at >= ?
+		AND pc.paid_at < ?
+		AND sl.statement_line_id IS NULL
+		ORDER BY pc.paid_at ASC, pc.payment_callback_id ASC
+		LIMIT 1
+	`
+
+	var scanner sql.NullInt64
+	err = r.db.QueryRowContext(ctx, query, virtualAccountID, PaymentCallbackStatusProcessed, amount, from, to).Scan(&scanner)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+
+		logger.Error(fmt.Sprintf("[statementRepository][GetUnreconciledCallbackTransactionID] while scan query row. Err: %v", err))
+		return 0, err
+	}
+
+	return scanner.Int64, nil
+}
@@ -0,0 +1,290 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var statementLineColumns = []string{
+	"statement_line_id", "statement_import_id", "line_number", "value_date", "amount", "description", "reference", "fingerprint",
+	"line_status", "match_method", "loan_id", "transaction_id", "candidate_loan_ids", "note", "matched_by", "matched_at", "created_at",
+}
+
+func TestCreateStatementImport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewStatementRepository(db)
+
+	mock.ExpectExec("INSERT INTO statement_imports").
+		WithArgs("mutation.csv", "csv").
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	id, err := repo.CreateStatementImport(context.Background(), repository.StatementImport{FileName: "mutation.csv", StatementFormat: "csv"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), id)
+
+	mock.ExpectExec("INSERT INTO statement_imports").
+		WithArgs("mutation.csv", "csv").
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.CreateStatementImport(context.Background(), repository.StatementImport{FileName: "mutation.csv", StatementFormat: "csv"})
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStatementLine(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewStatementRepository(db)
+	valueDate := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	line := repository.StatementLine{
+		StatementImportID: 4,
+		LineNumber:        1,
+		ValueDate:         valueDate,
+		Amount:            money.New(150000),
+		Description:       "TRF 8808000000000042",
+		Reference:         "FT001",
+		Fingerprint:       "abc",
+		LineStatus:        "pending",
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "created",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO statement_lines (.+) ON DUPLICATE KEY UPDATE").
+					WithArgs(4, 1, valueDate, money.New(150000), "TRF 8808000000000042", "FT001", "abc", "pending", nil).
+					WillReturnResult(sqlmock.NewResult(9, 1))
+			},
+			want:    9,
+			wantErr: false,
+		},
+		{
+			name: "imported before",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO statement_lines").
+					WithArgs(4, 1, valueDate, money.New(150000), "TRF 8808000000000042", "FT001", "abc", "pending", nil).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name: "exec error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO statement_lines").
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateStatementLine(context.Background(), line)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetStatementLineByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewStatementRepository(db)
+	valueDate := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT (.+) FROM statement_lines WHERE statement_line_id = \\?").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows(statementLineColumns).
+			AddRow(9, 4, 1, valueDate, "150000.000", "TRF", "FT001", "abc", "ambiguous", nil, nil, nil, "1,2", "more than one loan has the amount due", nil, nil, createdAt))
+	got, err := repo.GetStatementLineByID(context.Background(), 9)
+	assert.NoError(t, err)
+	assert.Equal(t, repository.StatementLine{
+		ID:                9,
+		StatementImportID: 4,
+		LineNumber:        1,
+		ValueDate:         valueDate,
+		Amount:            money.New(150000),
+		Description:       "TRF",
+		Reference:         "FT001",
+		Fingerprint:       "abc",
+		LineStatus:        "ambiguous",
+		CandidateLoanIDs:  "1,2",
+		Note:              "more than one loan has the amount due",
+		CreatedAt:         createdAt,
+	}, got)
+
+	mock.ExpectQuery("SELECT (.+) FROM statement_lines WHERE statement_line_id = \\?").
+		WithArgs(10).
+		WillReturnError(sql.ErrNoRows)
+	got, err = repo.GetStatementLineByID(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, repository.StatementLine{}, got)
+
+	mock.ExpectQuery("SELECT (.+) FROM statement_lines WHERE transaction_id = \\?").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(statementLineColumns).
+			AddRow(9, 4, 1, valueDate, "150000.000", "TRF", "FT001", "abc", "matched", "manual", 1, 7, nil, nil, "finance", createdAt, createdAt))
+	got, err = repo.GetStatementLineByTransactionID(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), got.TransactionID)
+	assert.Equal(t, "finance", got.MatchedBy)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchStatementLines(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewStatementRepository(db)
+	valueDate := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT (.+) FROM statement_lines WHERE 1 = 1 AND statement_import_id = \\? AND line_status IN \\(\\?, \\?\\) ORDER BY statement_line_id ASC LIMIT \\? OFFSET \\?").
+		WithArgs(4, "unmatched", "ambiguous", 10, 0).
+		WillReturnRows(sqlmock.NewRows(statementLineColumns).
+			AddRow(9, 4, 1, valueDate, "150000.000", "TRF", "", "abc", "unmatched", nil, nil, nil, nil, "no loan matches the line", nil, nil, valueDate).
+			AddRow(10, 4, 2, valueDate, "200000.000", "TRF", "", "def", "ambiguous", nil, nil, nil, "1,2", nil, nil, nil, valueDate))
+	got, err := repo.FetchStatementLines(context.Background(), repository.FetchStatementLinesRequest{
+		StatementImportID: 4,
+		LineStatuses:      []string{"unmatched", "ambiguous"},
+		Limit:             10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "no loan matches the line", got[0].Note)
+	assert.Equal(t, "1,2", got[1].CandidateLoanIDs)
+
+	mock.ExpectQuery("SELECT (.+) FROM statement_lines WHERE 1 = 1 ORDER BY").
+		WithArgs(10, 0).
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.FetchStatementLines(context.Background(), repository.FetchStatementLinesRequest{Limit: 10})
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimStatementLine(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewStatementRepository(db)
+
+	mock.ExpectExec("UPDATE statement_lines SET (.+) WHERE statement_line_id = \\? AND line_status IN \\(\\?, \\?\\)").
+		WithArgs("pending", 9, "unmatched", "ambiguous").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	claimed, err := repo.ClaimStatementLine(context.Background(), 9)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	mock.ExpectExec("UPDATE statement_lines").
+		WithArgs("pending", 9, "unmatched", "ambiguous").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	claimed, err = repo.ClaimStatementLine(context.Background(), 9)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateStatementLine(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewStatementRepository(db)
+
+	mock.ExpectExec("UPDATE statement_lines SET (.+) WHERE statement_line_id = \\?").
+		WithArgs("matched", "manual", 1, 7, nil, nil, "finance", "matched", 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err = repo.UpdateStatementLine(context.Background(), repository.UpdateStatementLineRequest{
+		ID:            9,
+		LineStatus:    "matched",
+		MatchMethod:   "manual",
+		LoanID:        1,
+		TransactionID: 7,
+		MatchedBy:     "finance",
+	})
+	assert.NoError(t, err)
+
+	mock.ExpectExec("UPDATE statement_lines").
+		WithArgs("ambiguous", nil, nil, nil, "1,2", "more than one contract number", nil, "matched", 9).
+		WillReturnError(sql.ErrConnDone)
+	err = repo.UpdateStatementLine(context.Background(), repository.UpdateStatementLineRequest{
+		ID:               9,
+		LineStatus:       "ambiguous",
+		CandidateLoanIDs: "1,2",
+		Note:             "more than one contract number",
+	})
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUnreconciledCallbackTransactionID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewStatementRepository(db)
+	from := time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT pc.transaction_id FROM payment_callbacks pc LEFT JOIN statement_lines sl (.+) AND sl.statement_line_id IS NULL").
+		WithArgs(3, "processed", money.New(150000), from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id"}).AddRow(7))
+	got, err := repo.GetUnreconciledCallbackTransactionID(context.Background(), 3, money.New(150000), from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), got)
+
+	mock.ExpectQuery("SELECT pc.transaction_id FROM payment_callbacks").
+		WithArgs(3, "processed", money.New(150000), from, to).
+		WillReturnError(sql.ErrNoRows)
+	got, err = repo.GetUnreconciledCallbackTransactionID(context.Background(), 3, money.New(150000), from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), got)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,10 +3,14 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/go-sql-driver/mysql"
 )
 
 type TransactionRepository interface {
@@ -17,6 +21,11 @@ type TransactionRepository interface {
 	GetTransactionByID(ctx context.Context, id int64) (result Transaction, err error)
 	GetTransactionsByConsumerID(ctx context.Context, consumerID int64) (results []Transaction, err error)
 	GetTransactionsByLoanID(ctx context.Context, loanID int64) (results []Transaction, err error)
+	FetchTransactions(ctx context.Context, req FetchTransactionsRequest) (results []Transaction, err error)
+	CreateTransactionAllocations(ctx context.Context, tx *sql.Tx, allocations []TransactionAllocation) (err error)
+	GetTransactionAllocationsByTransactionID(ctx context.Context, transactionID int64) (results []TransactionAllocation, err error)
+	GetTransactionAllocationsByTransactionIDs(ctx context.Context, transactionIDs []int64) (results []TransactionAllocation, err error)
+	MarkTransactionReversed(ctx context.Context, tx *sql.Tx, id int64) (marked bool, err error)
 }
 
 type transactionRepository struct {
@@ -54,23 +63,92 @@ func (r *transactionRepository) RollbackTx(ctx context.Context, tx *sql.Tx) erro
 	return nil
 }
 
+// ErrDuplicateExternalReference is returned when a transaction is created with the external
+// reference of another transaction of the same channel.
+var ErrDuplicateExternalReference = errors.New("external reference already booked on the channel")
+
+// mysqlErrDuplicateEntry is the MySQL error number of a unique key violation.
+const mysqlErrDuplicateEntry = 1062
+
+// transactionSortColumns maps the sort keys accepted by FetchTransactions to their columns.
+var transactionSortColumns = map[string]string{
+	"created_at": "created_at",
+	"amount":     "amount",
+}
+
 type (
+	// FetchTransactionsRequest filters transactions on the fields that are set. From and To
+	// bound the booking time, ValueDateFrom and ValueDateTo the value date, the start of each
+	// range inclusive and its end exclusive. SortBy is created_at or amount, SortOrder asc or
+	// desc, and they default to the newest transaction first.
+	FetchTransactionsRequest struct {
+		ConsumerID        int64
+		LoanID            int64
+		TransactionType   string
+		Channel           string
+		ExternalReference string
+		From              time.Time
+		To                time.Time
+		ValueDateFrom     time.Time
+		ValueDateTo       time.Time
+		SortBy            string
+		SortOrder         string
+		Limit             int
+		Offset            int
+	}
+
+	// Transaction is a payment booked on a loan. A reversal is booked as a transaction of the
+	// negated amount pointing at the transaction it reverses, which gets ReversedAt set.
 	Transaction struct {
-		ID          int64
-		ConsumerID  int64
-		LoanID      int64
-		Amount      float64
-		Description string
-		CreatedAt   time.Time
+		ID                      int64
+		ConsumerID              int64
+		LoanID                  int64
+		ReversalOfTransactionID int64
+		Amount                  money.Money
+		Description             string
+		TransactionType         string
+		Channel                 string
+		ExternalReference       string
+		ValueDate               time.Time
+		ReversedBy              string
+		ReversalReason          string
+		ReversedAt              time.Time
+		CreatedAt               time.Time
+	}
+
+	// TransactionAllocation is the part of a transaction applied to one component of a loan
+	// installment, to a settlement fee or to the consumer's credit balance.
+	TransactionAllocation struct {
+		ID                int64
+		TransactionID     int64
+		InstallmentNumber int32
+		Component         string
+		Amount            money.Money
 	}
 
 	TransactionScanner struct {
-		ID          sql.NullInt64
-		ConsumerID  sql.NullInt64
-		LoanID      sql.NullInt64
-		Amount      sql.NullFloat64
-		Description sql.NullString
-		CreatedAt   sql.NullTime
+		ID                      sql.NullInt64
+		ConsumerID              sql.NullInt64
+		LoanID                  sql.NullInt64
+		ReversalOfTransactionID sql.NullInt64
+		Amount                  money.NullMoney
+		Description             sql.NullString
+		TransactionType         sql.NullString
+		Channel                 sql.NullString
+		ExternalReference       sql.NullString
+		ValueDate               sql.NullTime
+		ReversedBy              sql.NullString
+		ReversalReason          sql.NullString
+		ReversedAt              sql.NullTime
+		CreatedAt               sql.NullTime
+	}
+
+	TransactionAllocationScanner struct {
+		ID                sql.NullInt64
+		TransactionID     sql.NullInt64
+		InstallmentNumber sql.NullInt32
+		Component         sql.NullString
+		Amount            money.NullMoney
 	}
 )
 
@@ -79,20 +157,64 @@ func (r *transactionRepository) CreateTransaction(ctx context.Context, tx *sql.T
 		INSERT INTO transactions (
 			consumer_id,
 			loan_id,
+			reversal_of_transaction_id,
 			amount,
 			description,
+			transaction_type,
+			channel,
+			external_reference,
+			value_date,
+			reversed_by,
+			reversal_reason,
 			created_at
 		)
-		VALUES (?, ?, ?, ?, now())
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, now())
 	`
 
+	var reversalOfTransactionID *int64
+	var reversedBy, reversalReason *string
+	if transaction.ReversalOfTransactionID != 0 {
+		reversalOfTransactionID = &transaction.ReversalOfTransactionID
+		reversedBy = &transaction.ReversedBy
+		reversalReason = &transaction.ReversalReason
+	}
+
+	var transactionType, channel, externalReference *string
+	if transaction.TransactionType != "" {
+		transactionType = &transaction.TransactionType
+	}
+	if transaction.Channel != "" {
+		channel = &transaction.Channel
+	}
+	if transaction.ExternalReference != "" {
+		externalReference = &transaction.ExternalReference
+	}
+
+	var valueDate *string
+	if !transaction.ValueDate.IsZero() {
+		date := transaction.ValueDate.Format("2006-01-02")
+		valueDate = &date
+	}
+
 	result, err := tx.ExecContext(ctx, query,
 		transaction.ConsumerID,
 		transaction.LoanID,
+		reversalOfTransactionID,
 		transaction.Amount,
 		transaction.Description,
+		transactionType,
+		channel,
+		externalReference,
+		valueDate,
+		reversedBy,
+		reversalReason,
 	)
 	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if externalReference != nil && errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return id, ErrDuplicateExternalReference
+		}
+
 		logger.Error(fmt.Sprintf("[transactionRepository][CreateTransaction] while exec query. Err: %v", err))
 		return id, err
 	}
@@ -112,8 +234,16 @@ func (r *transactionRepository) GetTransactionByID(ctx context.Context, id int64
 			transaction_id,
 			consumer_id,
 			loan_id,
+			reversal_of_transaction_id,
 			amount,
 			description,
+			transaction_type,
+			channel,
+			external_reference,
+			value_date,
+			reversed_by,
+			reversal_reason,
+			reversed_at,
 			created_at
 		FROM transactions
 		WHERE deleted_at IS NULL
@@ -125,8 +255,16 @@ func (r *transactionRepository) GetTransactionByID(ctx context.Context, id int64
 		&transactionScanner.ID,
 		&transactionScanner.ConsumerID,
 		&transactionScanner.LoanID,
+		&transactionScanner.ReversalOfTransactionID,
 		&transactionScanner.Amount,
 		&transactionScanner.Description,
+		&transactionScanner.TransactionType,
+		&transactionScanner.Channel,
+		&transactionScanner.ExternalReference,
+		&transactionScanner.ValueDate,
+		&transactionScanner.ReversedBy,
+		&transactionScanner.ReversalReason,
+		&transactionScanner.ReversedAt,
 		&transactionScanner.CreatedAt,
 	)
 	if err != nil {
@@ -139,12 +277,20 @@ func (r *transactionRepository) GetTransactionByID(ctx context.Context, id int64
 	}
 
 	result = Transaction{
-		ID:          transactionScanner.ID.Int64,
-		ConsumerID:  transactionScanner.ConsumerID.Int64,
-		LoanID:      transactionScanner.LoanID.Int64,
-		Amount:      transactionScanner.Amount.Float64,
-		Description: transactionScanner.Description.String,
-		CreatedAt:   transactionScanner.CreatedAt.Time,
+		ID:                      transactionScanner.ID.Int64,
+		ConsumerID:              transactionScanner.ConsumerID.Int64,
+		LoanID:                  transactionScanner.LoanID.Int64,
+		ReversalOfTransactionID: transactionScanner.ReversalOfTransactionID.Int64,
+		Amount:                  transactionScanner.Amount.Money,
+		Description:             transactionScanner.Description.String,
+		TransactionType:         transactionScanner.TransactionType.String,
+		Channel:                 transactionScanner.Channel.String,
+		ExternalReference:       transactionScanner.ExternalReference.String,
+		ValueDate:               transactionScanner.ValueDate.Time,
+		ReversedBy:              transactionScanner.ReversedBy.String,
+		ReversalReason:          transactionScanner.ReversalReason.String,
+		ReversedAt:              transactionScanner.ReversedAt.Time,
+		CreatedAt:               transactionScanner.CreatedAt.Time,
 	}
 
 	return result, nil
@@ -156,8 +302,16 @@ func (r *transactionRepository) GetTransactionsByConsumerID(ctx context.Context,
 			transaction_id,
 			consumer_id,
 			loan_id,
+			reversal_of_transaction_id,
 			amount,
 			description,
+			transaction_type,
+			channel,
+			external_reference,
+			value_date,
+			reversed_by,
+			reversal_reason,
+			reversed_at,
 			created_at
 		FROM transactions
 		WHERE deleted_at IS NULL
@@ -177,8 +331,16 @@ func (r *transactionRepository) GetTransactionsByConsumerID(ctx context.Context,
 			&transactionScanner.ID,
 			&transactionScanner.ConsumerID,
 			&transactionScanner.LoanID,
+			&transactionScanner.ReversalOfTransactionID,
 			&transactionScanner.Amount,
 			&transactionScanner.Description,
+			&transactionScanner.TransactionType,
+			&transactionScanner.Channel,
+			&transactionScanner.ExternalReference,
+			&transactionScanner.ValueDate,
+			&transactionScanner.ReversedBy,
+			&transactionScanner.ReversalReason,
+			&transactionScanner.ReversedAt,
 			&transactionScanner.CreatedAt,
 		)
 		if err != nil {
@@ -187,12 +349,20 @@ func (r *transactionRepository) GetTransactionsByConsumerID(ctx context.Context,
 		}
 
 		result := Transaction{
-			ID:          transactionScanner.ID.Int64,
-			ConsumerID:  transactionScanner.ConsumerID.Int64,
-			LoanID:      transactionScanner.LoanID.Int64,
-			Amount:      transactionScanner.Amount.Float64,
-			Description: transactionScanner.Description.String,
-			CreatedAt:   transactionScanner.CreatedAt.Time,
+			ID:                      transactionScanner.ID.Int64,
+			ConsumerID:              transactionScanner.ConsumerID.Int64,
+			LoanID:                  transactionScanner.LoanID.Int64,
+			ReversalOfTransactionID: transactionScanner.ReversalOfTransactionID.Int64,
+			Amount:                  transactionScanner.Amount.Money,
+			Description:             transactionScanner.Description.String,
+			TransactionType:         transactionScanner.TransactionType.String,
+			Channel:                 transactionScanner.Channel.String,
+			ExternalReference:       transactionScanner.ExternalReference.String,
+			ValueDate:               transactionScanner.ValueDate.Time,
+			ReversedBy:              transactionScanner.ReversedBy.String,
+			ReversalReason:          transactionScanner.ReversalReason.String,
+			ReversedAt:              transactionScanner.ReversedAt.Time,
+			CreatedAt:               transactionScanner.CreatedAt.Time,
 		}
 
 		results = append(results, result)
@@ -207,8 +377,16 @@ func (r *transactionRepository) GetTransactionsByLoanID(ctx context.Context, loa
 			transaction_id,
 			consumer_id,
 			loan_id,
+			reversal_of_transaction_id,
 			amount,
 			description,
+			transaction_type,
+			channel,
+			external_reference,
+			value_date,
+			reversed_by,
+			reversal_reason,
+			reversed_at,
 			created_at
 		FROM transactions
 		WHERE deleted_at IS NULL
@@ -228,8 +406,16 @@ func (r *transactionRepository) GetTransactionsByLoanID(ctx context.Context, loa
 			&transactionScanner.ID,
 			&transactionScanner.ConsumerID,
 			&transactionScanner.LoanID,
+			&transactionScanner.ReversalOfTransactionID,
 			&transactionScanner.Amount,
 			&transactionScanner.Description,
+			&transactionScanner.TransactionType,
+			&transactionScanner.Channel,
+			&transactionScanner.ExternalReference,
+			&transactionScanner.ValueDate,
+			&transactionScanner.ReversedBy,
+			&transactionScanner.ReversalReason,
+			&transactionScanner.ReversedAt,
 			&transactionScanner.CreatedAt,
 		)
 		if err != nil {
@@ -238,12 +424,20 @@ func (r *transactionRepository) GetTransactionsByLoanID(ctx context.Context, loa
 		}
 
 		result := Transaction{
-			ID:          transactionScanner.ID.Int64,
-			ConsumerID:  transactionScanner.ConsumerID.Int64,
-			LoanID:      transactionScanner.LoanID.Int64,
-			Amount:      transactionScanner.Amount.Float64,
-			Description: transactionScanner.Description.String,
-			CreatedAt:   transactionScanner.CreatedAt.Time,
+			ID:                      transactionScanner.ID.Int64,
+			ConsumerID:              transactionScanner.ConsumerID.Int64,
+			LoanID:                  transactionScanner.LoanID.Int64,
+			ReversalOfTransactionID: transactionScanner.ReversalOfTransactionID.Int64,
+			Amount:                  transactionScanner.Amount.Money,
+			Description:             transactionScanner.Description.String,
+			TransactionType:         transactionScanner.TransactionType.String,
+			Channel:                 transactionScanner.Channel.String,
+			ExternalReference:       transactionScanner.ExternalReference.String,
+			ValueDate:               transactionScanner.ValueDate.Time,
+			ReversedBy:              transactionScanner.ReversedBy.String,
+			ReversalReason:          transactionScanner.ReversalReason.String,
+			ReversedAt:              transactionScanner.ReversedAt.Time,
+			CreatedAt:               transactionScanner.CreatedAt.Time,
 		}
 
 		results = append(results, result)
@@ -251,3 +445,261 @@ func (r *transactionRepository) GetTransactionsByLoanID(ctx context.Context, loa
 
 	return results, nil
 }
+
+func (r *transactionRepository) FetchTransactions(ctx context.Context, req FetchTransactionsRequest) (results []Transaction, err error) {
+	conditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+	if req.ConsumerID != 0 {
+		conditions = append(conditions, "consumer_id = ?")
+		args = append(args, req.ConsumerID)
+	}
+	if req.LoanID != 0 {
+		conditions = append(conditions, "loan_id = ?")
+		args = append(args, req.LoanID)
+	}
+	if req.TransactionType != "" {
+		conditions = append(conditions, "transaction_type = ?")
+		args = append(args, req.TransactionType)
+	}
+	if req.Channel != "" {
+		conditions = append(conditions, "channel = ?")
+		args = append(args, req.Channel)
+	}
+	if req.ExternalReference != "" {
+		conditions = append(conditions, "external_reference = ?")
+		args = append(args, req.ExternalReference)
+	}
+	if !req.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, req.From)
+	}
+	if !req.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, req.To)
+	}
+	if !req.ValueDateFrom.IsZero() {
+		conditions = append(conditions, "value_date >= ?")
+		args = append(args, req.ValueDateFrom)
+	}
+	if !req.ValueDateTo.IsZero() {
+		conditions = append(conditions, "value_date < ?")
+		args = append(args, req.ValueDateTo)
+	}
+
+	sortColumn, ok := transactionSortColumns[req.SortBy]
+	if !ok {
+		sortColumn = "created_at"
+	}
+	sortOrder := "DESC"
+	if strings.ToLower(req.SortOrder) == "asc" {
+		sortOrder = "ASC"
+	}
+
+	query := `
+		SELECT
+			transaction_id,
+			consumer_id,
+			loan_id,
+			reversal_of_transaction_id,
+			amount,
+			description,
+			transaction_type,
+			channel,
+			external_reference,
+			value_date,
+			reversed_by,
+			reversal_reason,
+			reversed_at,
+			created_at
+		FROM transactions
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + sortColumn + ` ` + sortOrder + `, transaction_id ` + sortOrder + `
+		LIMIT ? OFFSET ?
+	`
+	args = append(args, req.Limit, req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[transactionRepository][FetchTransactions] while query. Err: %v", err))
+		return results, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transactionScanner TransactionScanner
+		err = rows.Scan(
+			&transactionScanner.ID,
+			&transactionScanner.ConsumerID,
+			&transactionScanner.LoanID,
+			&transactionScanner.ReversalOfTransactionID,
+			&transactionScanner.Amount,
+			&transactionScanner.Description,
+			&transactionScanner.TransactionType,
+			&transactionScanner.Channel,
+			&transactionScanner.ExternalReference,
+			&transactionScanner.ValueDate,
+			&transactionScanner.ReversedBy,
+			&transactionScanner.ReversalReason,
+			&transactionScanner.ReversedAt,
+			&transactionScanner.CreatedAt,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[transactionRepository][FetchTransactions] while scan query row. Err: %v", err))
+			return results, err
+		}
+
+		result := Transaction{
+			ID:                      transactionScanner.ID.Int64,
+			ConsumerID:              transactionScanner.ConsumerID.Int64,
+			LoanID:                  transactionScanner.LoanID.Int64,
+			ReversalOfTransactionID: transactionScanner.ReversalOfTransactionID.Int64,
+			Amount:                  transactionScanner.Amount.Money,
+			Description:             transactionScanner.Description.String,
+			TransactionType:         transactionScanner.TransactionType.String,
+			Channel:                 transactionScanner.Channel.String,
+			ExternalReference:       transactionScanner.ExternalReference.String,
+			ValueDate:               transactionScanner.ValueDate.Time,
+			ReversedBy:              transactionScanner.ReversedBy.String,
+			ReversalReason:          transactionScanner.ReversalReason.String,
+			ReversedAt:              transactionScanner.ReversedAt.Time,
+			CreatedAt:               transactionScanner.CreatedAt.Time,
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (r *transactionRepository) CreateTransactionAllocations(ctx context.Context, tx *sql.Tx, allocations []TransactionAllocation) (err error) {
+	if len(allocations) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(allocations))
+	args := make([]interface{}, 0, len(allocations)*4)
+	for _, allocation := range allocations {
+		var installmentNumber *int32
+		if allocation.InstallmentNumber != 0 {
+			allocationInstallmentNumber := allocation.InstallmentNumber
+			installmentNumber = &allocationInstallmentNumber
+		}
+
+		placeholders = append(placeholders, "(?, ?, ?, ?, NOW())")
+		args = append(args,
+			allocation.TransactionID,
+			installmentNumber,
+			allocation.Component,
+			allocation.Amount,
+		)
+	}
+
+	query := `
+		INSERT INTO transaction_allocations (
+			transaction_id,
+			installment_number,
+			component,
+			amount,
+			created_at
+		) VALUES ` + strings.Join(placeholders, ", ")
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[transactionRepository][CreateTransactionAllocations] while exec query. Err: %v", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) GetTransactionAllocationsByTransactionID(ctx context.Context, transactionID int64) (results []TransactionAllocation, err error) {
+	return r.GetTransactionAllocationsByTransactionIDs(ctx, []int64{transactionID})
+}
+
+// GetTransactionAllocationsByTransactionIDs returns the allocations of several transactions
+// at once, ordered by transaction and then in the order they were allocated.
+func (r *transactionRepository) GetTransactionAllocationsByTransactionIDs(ctx context.Context, transactionIDs []int64) (results []TransactionAllocation, err error) {
+	if len(transactionIDs) == 0 {
+		return results, nil
+	}
+
+	placeholders := make([]string, 0, len(transactionIDs))
+	args := make([]interface{}, 0, len(transactionIDs))
+	for _, transactionID := range transactionIDs {
+		placeholders = append(placeholders, "?")
+		args = append(args, transactionID)
+	}
+
+	query := `
+		SELECT
+			transaction_allocation_id,
+			transaction_id,
+			installment_number,
+			component,
+			amount
+		FROM transaction_allocations
+		WHERE deleted_at IS NULL
+		AND transaction_id IN (` + strings.Join(placeholders, ", ") + `)
+		ORDER BY transaction_id, transaction_allocation_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[transactionRepository][GetTransactionAllocationsByTransactionIDs] while query. Err: %v", err))
+		return results, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var allocationScanner TransactionAllocationScanner
+		err = rows.Scan(
+			&allocationScanner.ID,
+			&allocationScanner.TransactionID,
+			&allocationScanner.InstallmentNumber,
+			&allocationScanner.Component,
+			&allocationScanner.Amount,
+		)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[transactionRepository][GetTransactionAllocationsByTransactionIDs] while scan query row. Err: %v", err))
+			return results, err
+		}
+
+		results = append(results, TransactionAllocation{
+			ID:                allocationScanner.ID.Int64,
+			TransactionID:     allocationScanner.TransactionID.Int64,
+			InstallmentNumber: allocationScanner.InstallmentNumber.Int32,
+			Component:         allocationScanner.Component.String,
+			Amount:            allocationScanner.Amount.Money,
+		})
+	}
+
+	return results, nil
+}
+
+// MarkTransactionReversed sets reversed_at of a transaction. It reports false when the
+// transaction does not exist or was already reversed.
+func (r *transactionRepository) MarkTransactionReversed(ctx context.Context, tx *sql.Tx, id int64) (marked bool, err error) {
+	query := `
+		UPDATE transactions
+		SET
+			reversed_at = NOW(),
+			updated_at = NOW()
+		WHERE deleted_at IS NULL
+		AND reversed_at IS NULL
+		AND transaction_id = ?
+	`
+
+	result, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[transactionRepository][MarkTransactionReversed] while exec query. Err: %v", err))
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[transactionRepository][MarkTransactionReversed] while get rows affected. Err: %v", err))
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,12 +26,13 @@ func TestCreateTransaction(t *testing.T) {
 	repo := repository.NewTransactionRepository(db)
 
 	tests := []struct {
-		name    string
-		tx      *sql.Tx
-		input   repository.Transaction
-		wantID  int64
-		wantErr bool
-		mock    func()
+		name      string
+		tx        *sql.Tx
+		input     repository.Transaction
+		wantID    int64
+		wantErr   bool
+		wantErrIs error
+		mock      func()
 	}{
 		{
 			name: "success with transaction",
@@ -37,48 +40,93 @@ func TestCreateTransaction(t *testing.T) {
 			input: repository.Transaction{
 				ConsumerID:  1,
 				LoanID:      1,
-				Amount:      1000.0,
+				Amount:      money.New(1000),
 				Description: "Test transaction",
 			},
 			wantID:  1,
 			wantErr: false,
 			mock: func() {
 				mock.ExpectExec("INSERT INTO transactions").
-					WithArgs(1, 1, 1000.0, "Test transaction").
+					WithArgs(1, 1, nil, money.New(1000), "Test transaction", nil, nil, nil, nil, nil, nil).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
+		{
+			name: "reversal",
+			tx:   trx,
+			input: repository.Transaction{
+				ConsumerID:              1,
+				LoanID:                  1,
+				ReversalOfTransactionID: 1,
+				Amount:                  money.New(-1000),
+				Description:             "Reversal of transaction 1",
+				TransactionType:         "reversal",
+				Channel:                 "counter",
+				ValueDate:               time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local),
+				ReversedBy:              "operator",
+				ReversalReason:          "bounced transfer",
+			},
+			wantID:  2,
+			wantErr: false,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO transactions").
+					WithArgs(1, 1, 1, money.New(-1000), "Reversal of transaction 1", "reversal", "counter", nil, "2024-01-02", "operator", "bounced transfer").
+					WillReturnResult(sqlmock.NewResult(2, 1))
+			},
+		},
 		{
 			name: "exec error with transaction",
 			tx:   trx,
 			input: repository.Transaction{
 				ConsumerID:  1,
 				LoanID:      1,
-				Amount:      1000.0,
+				Amount:      money.New(1000),
 				Description: "Test transaction",
 			},
 			wantID:  0,
 			wantErr: true,
 			mock: func() {
 				mock.ExpectExec("INSERT INTO transactions").
-					WithArgs(1, 1, 1000.0, "Test transaction").
+					WithArgs(1, 1, nil, money.New(1000), "Test transaction", nil, nil, nil, nil, nil, nil).
 					WillReturnError(sql.ErrConnDone)
 			},
 		},
+		{
+			name: "duplicate external reference",
+			tx:   trx,
+			input: repository.Transaction{
+				ConsumerID:        1,
+				LoanID:            1,
+				Amount:            money.New(1000),
+				Description:       "Test transaction",
+				TransactionType:   "installment",
+				Channel:           "bank_transfer",
+				ExternalReference: "TRF-001",
+				ValueDate:         time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local),
+			},
+			wantID:    0,
+			wantErr:   true,
+			wantErrIs: repository.ErrDuplicateExternalReference,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO transactions").
+					WithArgs(1, 1, nil, money.New(1000), "Test transaction", "installment", "bank_transfer", "TRF-001", "2024-01-02", nil, nil).
+					WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'bank_transfer-TRF-001' for key 'channel'"})
+			},
+		},
 		{
 			name: "last insert id error",
 			tx:   trx,
 			input: repository.Transaction{
 				ConsumerID:  1,
 				LoanID:      1,
-				Amount:      1000.0,
+				Amount:      money.New(1000),
 				Description: "Test transaction",
 			},
 			wantID:  0,
 			wantErr: true,
 			mock: func() {
 				mock.ExpectExec("INSERT INTO transactions").
-					WithArgs(1, 1, 1000.0, "Test transaction").
+					WithArgs(1, 1, nil, money.New(1000), "Test transaction", nil, nil, nil, nil, nil, nil).
 					WillReturnResult(sqlmock.NewErrorResult(sql.ErrConnDone))
 			},
 		},
@@ -90,6 +138,9 @@ func TestCreateTransaction(t *testing.T) {
 			id, err := repo.CreateTransaction(context.Background(), tt.tx, tt.input)
 			if tt.wantErr {
 				assert.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.wantID, id)
@@ -98,6 +149,361 @@ func TestCreateTransaction(t *testing.T) {
 	}
 }
 
+func TestCreateTransactionAllocations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	trx, _ := db.Begin()
+
+	repo := repository.NewTransactionRepository(db)
+	allocations := []repository.TransactionAllocation{
+		{TransactionID: 1, InstallmentNumber: 2, Component: "interest", Amount: money.New(10)},
+		{TransactionID: 1, Component: "credit", Amount: money.New(5)},
+	}
+
+	tests := []struct {
+		name        string
+		allocations []repository.TransactionAllocation
+		wantErr     bool
+		mock        func()
+	}{
+		{
+			name:        "success",
+			allocations: allocations,
+			wantErr:     false,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO transaction_allocations").
+					WithArgs(1, 2, "interest", money.New(10), 1, nil, "credit", money.New(5)).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+			},
+		},
+		{
+			name:        "no allocations",
+			allocations: nil,
+			wantErr:     false,
+			mock:        func() {},
+		},
+		{
+			name:        "exec error",
+			allocations: allocations,
+			wantErr:     true,
+			mock: func() {
+				mock.ExpectExec("INSERT INTO transaction_allocations").
+					WithArgs(1, 2, "interest", money.New(10), 1, nil, "credit", money.New(5)).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := repo.CreateTransactionAllocations(context.Background(), trx, tt.allocations)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetTransactionAllocationsByTransactionID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewTransactionRepository(db)
+	columns := []string{"transaction_allocation_id", "transaction_id", "installment_number", "component", "amount"}
+
+	tests := []struct {
+		name          string
+		transactionID int64
+		want          []repository.TransactionAllocation
+		wantErr       bool
+		mock          func()
+	}{
+		{
+			name:          "success",
+			transactionID: 1,
+			want: []repository.TransactionAllocation{
+				{ID: 1, TransactionID: 1, InstallmentNumber: 2, Component: "interest", Amount: money.New(10)},
+				{ID: 2, TransactionID: 1, Component: "credit", Amount: money.New(5)},
+			},
+			wantErr: false,
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 1, 2, "interest", "10.000").
+					AddRow(2, 1, nil, "credit", "5.000")
+				mock.ExpectQuery("SELECT transaction_allocation_id, transaction_id, installment_number, component, amount FROM transaction_allocations WHERE deleted_at IS NULL AND transaction_id IN \\(\\?\\)").
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name:          "query error",
+			transactionID: 2,
+			wantErr:       true,
+			mock: func() {
+				mock.ExpectQuery("SELECT transaction_allocation_id, transaction_id, installment_number, component, amount FROM transaction_allocations").
+					WithArgs(2).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+		{
+			name:          "scan error",
+			transactionID: 3,
+			wantErr:       true,
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow("invalid", 3, 1, "principal", "10.000")
+				mock.ExpectQuery("SELECT transaction_allocation_id, transaction_id, installment_number, component, amount FROM transaction_allocations").
+					WithArgs(3).
+					WillReturnRows(rows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			results, err := repo.GetTransactionAllocationsByTransactionID(context.Background(), tt.transactionID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, results)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetTransactionAllocationsByTransactionIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewTransactionRepository(db)
+
+	t.Run("no transactions", func(t *testing.T) {
+		results, err := repo.GetTransactionAllocationsByTransactionIDs(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"transaction_allocation_id", "transaction_id", "installment_number", "component", "amount"}).
+			AddRow(1, 1, 2, "principal", "10.000").
+			AddRow(2, 3, 2, "principal", "-10.000")
+		mock.ExpectQuery("FROM transaction_allocations WHERE deleted_at IS NULL AND transaction_id IN \\(\\?, \\?\\) ORDER BY transaction_id, transaction_allocation_id").
+			WithArgs(1, 3).
+			WillReturnRows(rows)
+
+		results, err := repo.GetTransactionAllocationsByTransactionIDs(context.Background(), []int64{1, 3})
+		assert.NoError(t, err)
+		assert.Equal(t, []repository.TransactionAllocation{
+			{ID: 1, TransactionID: 1, InstallmentNumber: 2, Component: "principal", Amount: money.New(10)},
+			{ID: 2, TransactionID: 3, InstallmentNumber: 2, Component: "principal", Amount: money.New(-10)},
+		}, results)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFetchTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewTransactionRepository(db)
+	columns := []string{"transaction_id", "consumer_id", "loan_id", "reversal_of_transaction_id", "amount", "description", "transaction_type", "channel", "external_reference", "value_date", "reversed_by", "reversal_reason", "reversed_at", "created_at"}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		req     repository.FetchTransactionsRequest
+		want    []repository.Transaction
+		wantErr bool
+		mock    func()
+	}{
+		{
+			name: "all filters sorted by amount",
+			req: repository.FetchTransactionsRequest{
+				ConsumerID:        1,
+				LoanID:            2,
+				TransactionType:   "reversal",
+				Channel:           "bank_transfer",
+				ExternalReference: "TRF-001",
+				From:              from,
+				To:                to,
+				SortBy:            "amount",
+				SortOrder:         "asc",
+				Limit:             10,
+				Offset:            20,
+			},
+			want: []repository.Transaction{
+				{ID: 4, ConsumerID: 1, LoanID: 2, ReversalOfTransactionID: 3, Amount: money.New(-100), Description: "Reversal", TransactionType: "reversal", Channel: "bank_transfer", ExternalReference: "TRF-001", ValueDate: from, ReversedBy: "operator", ReversalReason: "bounced", CreatedAt: from},
+				{ID: 3, ConsumerID: 1, LoanID: 2, Amount: money.New(100), Description: "Payment", ReversedAt: from, CreatedAt: from},
+			},
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(4, 1, 2, 3, "-100.000", "Reversal", "reversal", "bank_transfer", "TRF-001", from, "operator", "bounced", nil, from).
+					AddRow(3, 1, 2, nil, "100.000", "Payment", nil, nil, nil, nil, nil, nil, from, from)
+				mock.ExpectQuery("FROM transactions WHERE deleted_at IS NULL AND consumer_id = \\? AND loan_id = \\? AND transaction_type = \\? AND channel = \\? AND external_reference = \\? AND created_at >= \\? AND created_at < \\? ORDER BY amount ASC, transaction_id ASC LIMIT \\? OFFSET \\?").
+					WithArgs(1, 2, "reversal", "bank_transfer", "TRF-001", from, to, 10, 20).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name: "value date range",
+			req:  repository.FetchTransactionsRequest{LoanID: 2, ValueDateFrom: from, ValueDateTo: to, Limit: 10},
+			want: []repository.Transaction{
+				{ID: 3, ConsumerID: 1, LoanID: 2, Amount: money.New(100), Description: "Payment", TransactionType: "installment", Channel: "bank_transfer", ExternalReference: "TRF-002", ValueDate: from, CreatedAt: to},
+			},
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(3, 1, 2, nil, "100.000", "Payment", "installment", "bank_transfer", "TRF-002", from, nil, nil, nil, to)
+				mock.ExpectQuery("FROM transactions WHERE deleted_at IS NULL AND loan_id = \\? AND value_date >= \\? AND value_date < \\? ORDER BY created_at DESC, transaction_id DESC LIMIT \\? OFFSET \\?").
+					WithArgs(2, from, to, 10, 0).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name: "newest first by default",
+			req:  repository.FetchTransactionsRequest{SortBy: "description; DROP TABLE transactions", Limit: 10},
+			mock: func() {
+				mock.ExpectQuery("FROM transactions WHERE deleted_at IS NULL ORDER BY created_at DESC, transaction_id DESC LIMIT \\? OFFSET \\?").
+					WithArgs(10, 0).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			name:    "query error",
+			req:     repository.FetchTransactionsRequest{LoanID: 2, Limit: 10},
+			wantErr: true,
+			mock: func() {
+				mock.ExpectQuery("FROM transactions WHERE deleted_at IS NULL AND loan_id = \\?").
+					WithArgs(2, 10, 0).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+		{
+			name:    "scan error",
+			req:     repository.FetchTransactionsRequest{LoanID: 2, Limit: 10},
+			wantErr: true,
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow("invalid", 1, 2, nil, "100.000", "Payment", nil, nil, nil, nil, nil, nil, nil, from)
+				mock.ExpectQuery("FROM transactions WHERE deleted_at IS NULL AND loan_id = \\?").
+					WithArgs(2, 10, 0).
+					WillReturnRows(rows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			results, err := repo.FetchTransactions(context.Background(), tt.req)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, results)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMarkTransactionReversed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	trx, _ := db.Begin()
+
+	repo := repository.NewTransactionRepository(db)
+
+	tests := []struct {
+		name       string
+		id         int64
+		wantMarked bool
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:       "success",
+			id:         1,
+			wantMarked: true,
+			mock: func() {
+				mock.ExpectExec("UPDATE transactions SET reversed_at = NOW\\(\\), updated_at = NOW\\(\\) WHERE deleted_at IS NULL AND reversed_at IS NULL AND transaction_id = ?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:       "already reversed",
+			id:         2,
+			wantMarked: false,
+			mock: func() {
+				mock.ExpectExec("UPDATE transactions SET reversed_at").
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:    "exec error",
+			id:      3,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("UPDATE transactions SET reversed_at").
+					WithArgs(3).
+					WillReturnError(sql.ErrConnDone)
+			},
+		},
+		{
+			name:    "rows affected error",
+			id:      4,
+			wantErr: true,
+			mock: func() {
+				mock.ExpectExec("UPDATE transactions SET reversed_at").
+					WithArgs(4).
+					WillReturnResult(sqlmock.NewErrorResult(sql.ErrConnDone))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			marked, err := repo.MarkTransactionReversed(context.Background(), trx, tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantMarked, marked)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestGetTransactionByID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -121,15 +527,15 @@ func TestGetTransactionByID(t *testing.T) {
 				ID:          1,
 				ConsumerID:  1,
 				LoanID:      1,
-				Amount:      1000.0,
+				Amount:      money.New(1000),
 				Description: "Test transaction",
 				CreatedAt:   time.Now(),
 			},
 			wantErr: false,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"transaction_id", "consumer_id", "loan_id", "amount", "description", "created_at"}).
-					AddRow(1, 1, 1, 1000.0, "Test transaction", time.Now())
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND transaction_id = ?").
+				rows := sqlmock.NewRows([]string{"transaction_id", "consumer_id", "loan_id", "reversal_of_transaction_id", "amount", "description", "transaction_type", "channel", "external_reference", "value_date", "reversed_by", "reversal_reason", "reversed_at", "created_at"}).
+					AddRow(1, 1, 1, nil, 1000.0, "Test transaction", nil, nil, nil, nil, nil, nil, nil, time.Now())
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND transaction_id = ?").
 					WithArgs(1).
 					WillReturnRows(rows)
 			},
@@ -140,7 +546,7 @@ func TestGetTransactionByID(t *testing.T) {
 			want:    repository.Transaction{},
 			wantErr: false,
 			mock: func() {
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND transaction_id = ?").
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND transaction_id = ?").
 					WithArgs(2).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -151,7 +557,7 @@ func TestGetTransactionByID(t *testing.T) {
 			want:    repository.Transaction{},
 			wantErr: true,
 			mock: func() {
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND transaction_id = ?").
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND transaction_id = ?").
 					WithArgs(3).
 					WillReturnError(sql.ErrConnDone)
 			},
@@ -202,7 +608,7 @@ func TestGetTransactionsByConsumerID(t *testing.T) {
 					ID:          1,
 					ConsumerID:  1,
 					LoanID:      1,
-					Amount:      1000.0,
+					Amount:      money.New(1000),
 					Description: "Test transaction 1",
 					CreatedAt:   time.Now(),
 				},
@@ -210,17 +616,17 @@ func TestGetTransactionsByConsumerID(t *testing.T) {
 					ID:          2,
 					ConsumerID:  1,
 					LoanID:      2,
-					Amount:      2000.0,
+					Amount:      money.New(2000),
 					Description: "Test transaction 2",
 					CreatedAt:   time.Now(),
 				},
 			},
 			wantErr: false,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"transaction_id", "consumer_id", "loan_id", "amount", "description", "created_at"}).
-					AddRow(1, 1, 1, 1000.0, "Test transaction 1", time.Now()).
-					AddRow(2, 1, 2, 2000.0, "Test transaction 2", time.Now())
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND consumer_id = ?").
+				rows := sqlmock.NewRows([]string{"transaction_id", "consumer_id", "loan_id", "reversal_of_transaction_id", "amount", "description", "transaction_type", "channel", "external_reference", "value_date", "reversed_by", "reversal_reason", "reversed_at", "created_at"}).
+					AddRow(1, 1, 1, nil, 1000.0, "Test transaction 1", nil, nil, nil, nil, nil, nil, nil, time.Now()).
+					AddRow(2, 1, 2, nil, 2000.0, "Test transaction 2", nil, nil, nil, nil, nil, nil, nil, time.Now())
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND consumer_id = ?").
 					WithArgs(1).
 					WillReturnRows(rows)
 			},
@@ -231,7 +637,7 @@ func TestGetTransactionsByConsumerID(t *testing.T) {
 			want:       []repository.Transaction{},
 			wantErr:    false,
 			mock: func() {
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND consumer_id = ?").
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND consumer_id = ?").
 					WithArgs(2).
 					WillReturnRows(sqlmock.NewRows(nil))
 			},
@@ -242,7 +648,7 @@ func TestGetTransactionsByConsumerID(t *testing.T) {
 			want:       []repository.Transaction{},
 			wantErr:    true,
 			mock: func() {
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND consumer_id = ?").
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND consumer_id = ?").
 					WithArgs(3).
 					WillReturnError(sql.ErrConnDone)
 			},
@@ -253,9 +659,9 @@ func TestGetTransactionsByConsumerID(t *testing.T) {
 			want:       []repository.Transaction{},
 			wantErr:    true,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"transaction_id", "consumer_id", "loan_id", "amount", "description", "created_at"}).
-					AddRow("invalid", 4, 1, 1000.0, "Test transaction", time.Now())
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND consumer_id = ?").
+				rows := sqlmock.NewRows([]string{"transaction_id", "consumer_id", "loan_id", "reversal_of_transaction_id", "amount", "description", "transaction_type", "channel", "external_reference", "value_date", "reversed_by", "reversal_reason", "reversed_at", "created_at"}).
+					AddRow("invalid", 4, 1, nil, 1000.0, "Test transaction", nil, nil, nil, nil, nil, nil, nil, time.Now())
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND consumer_id = ?").
 					WithArgs(4).
 					WillReturnRows(rows)
 			},
@@ -308,7 +714,7 @@ func TestGetTransactionsByLoanID(t *testing.T) {
 					ID:          1,
 					ConsumerID:  1,
 					LoanID:      1,
-					Amount:      1000.0,
+					Amount:      money.New(1000),
 					Description: "Test transaction 1",
 					CreatedAt:   time.Now(),
 				},
@@ -316,17 +722,17 @@ func TestGetTransactionsByLoanID(t *testing.T) {
 					ID:          2,
 					ConsumerID:  2,
 					LoanID:      1,
-					Amount:      2000.0,
+					Amount:      money.New(2000),
 					Description: "Test transaction 2",
 					CreatedAt:   time.Now(),
 				},
 			},
 			wantErr: false,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"transaction_id", "consumer_id", "loan_id", "amount", "description", "created_at"}).
-					AddRow(1, 1, 1, 1000.0, "Test transaction 1", time.Now()).
-					AddRow(2, 2, 1, 2000.0, "Test transaction 2", time.Now())
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND loan_id = ?").
+				rows := sqlmock.NewRows([]string{"transaction_id", "consumer_id", "loan_id", "reversal_of_transaction_id", "amount", "description", "transaction_type", "channel", "external_reference", "value_date", "reversed_by", "reversal_reason", "reversed_at", "created_at"}).
+					AddRow(1, 1, 1, nil, 1000.0, "Test transaction 1", nil, nil, nil, nil, nil, nil, nil, time.Now()).
+					AddRow(2, 2, 1, nil, 2000.0, "Test transaction 2", nil, nil, nil, nil, nil, nil, nil, time.Now())
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND loan_id = ?").
 					WithArgs(1).
 					WillReturnRows(rows)
 			},
@@ -337,7 +743,7 @@ func TestGetTransactionsByLoanID(t *testing.T) {
 			want:    []repository.Transaction{},
 			wantErr: false,
 			mock: func() {
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND loan_id = ?").
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND loan_id = ?").
 					WithArgs(2).
 					WillReturnRows(sqlmock.NewRows(nil))
 			},
@@ -348,7 +754,7 @@ func TestGetTransactionsByLoanID(t *testing.T) {
 			want:    []repository.Transaction{},
 			wantErr: true,
 			mock: func() {
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND loan_id = ?").
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND loan_id = ?").
 					WithArgs(3).
 					WillReturnError(sql.ErrConnDone)
 			},
@@ -359,9 +765,9 @@ func TestGetTransactionsByLoanID(t *testing.T) {
 			want:    []repository.Transaction{},
 			wantErr: true,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"transaction_id", "consumer_id", "loan_id", "amount", "description", "created_at"}).
-					AddRow("invalid", 4, 1, 1000.0, "Test transaction", time.Now())
-				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, amount, description, created_at FROM transactions WHERE deleted_at IS NULL AND loan_id = ?").
+				rows := sqlmock.NewRows([]string{"transaction_id", "consumer_id", "loan_id", "reversal_of_transaction_id", "amount", "description", "transaction_type", "channel", "external_reference", "value_date", "reversed_by", "reversal_reason", "reversed_at", "created_at"}).
+					AddRow("invalid", 4, 1, nil, 1000.0, "Test transaction", nil, nil, nil, nil, nil, nil, nil, time.Now())
+				mock.ExpectQuery("SELECT transaction_id, consumer_id, loan_id, reversal_of_transaction_id, amount, description, transaction_type, channel, external_reference, value_date, reversed_by, reversal_reason, reversed_at, created_at FROM transactions WHERE deleted_at IS NULL AND loan_id = ?").
 					WithArgs(4).
 					WillReturnRows(rows)
 			},
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+)
+
+type VirtualAccountRepository interface {
+	CreateVirtualAccount(ctx context.Context, account VirtualAccount) (int64, error)
+	GetVirtualAccountByLoanID(ctx context.Context, provider string, loanID int64) (VirtualAccount, error)
+	GetVirtualAccountByNumber(ctx context.Context, provider string, accountNumber string) (VirtualAccount, error)
+}
+
+type virtualAccountRepository struct {
+	db *sql.DB
+}
+
+func NewVirtualAccountRepository(db *sql.DB) VirtualAccountRepository {
+	return &virtualAccountRepository{db: db}
+}
+
+type (
+	// VirtualAccount is an account number issued by a payment gateway for a loan, transfers
+	// to it are booked as payments of the loan.
+	VirtualAccount struct {
+		ID            int64
+		LoanID        int64
+		ConsumerID    int64
+		Provider      string
+		BankCode      string
+		AccountNumber string
+		AccountName   string
+		CreatedAt     time.Time
+	}
+
+	VirtualAccountScanner struct {
+		ID            sql.NullInt64
+		LoanID        sql.NullInt64
+		ConsumerID    sql.NullInt64
+		Provider      sql.NullString
+		BankCode      sql.NullString
+		AccountNumber sql.NullString
+		AccountName   sql.NullString
+		CreatedAt     sql.NullTime
+	}
+)
+
+func (r *virtualAccountRepository) CreateVirtualAccount(ctx context.Context, account VirtualAccount) (id int64, err error) {
+	query := `
+		INSERT INTO virtual_accounts (
+			loan_id,
+			consumer_id,
+			provider,
+			bank_code,
+			account_number,
+			account_name,
+			created_at,
+			updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		account.LoanID,
+		account.ConsumerID,
+		account.Provider,
+		account.BankCode,
+		account.AccountNumber,
+		account.AccountName,
+	)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[virtualAccountRepository][CreateVirtualAccount] while exec query. Err: %v", err))
+		return 0, err
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		logger.Error(fmt.Sprintf("[virtualAccountRepository][CreateVirtualAccount] while get last insert id. Err: %v", err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *virtualAccountRepository) GetVirtualAccountByLoanID(ctx context.Context, provider string, loanID int64) (result VirtualAccount, err error) {
+	query := `
+		SELECT
+			virtual_account_id,
+			loan_id,
+			consumer_id,
+			provider,
+			bank_code,
+			account_number,
+			account_name,
+			created_at
+		FROM virtual_accounts
+		WHERE deleted_at IS NULL
+		AND provider = ?
+		AND loan_id = ?
+	`
+
+	result, err = r.getVirtualAccount(ctx, query, provider, loanID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[virtualAccountRepository][GetVirtualAccountByLoanID] while scan query row. Err: %v", err))
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *virtualAccountRepository) GetVirtualAccountByNumber(ctx context.Context, provider string, accountNumber string) (result VirtualAccount, err error) {
+	query := `
+		SELECT
+			virtual_account_id,
+			loan_id,
+			consumer_id,
+			provider,
+			bank_code,
+			account_number,
+			account_name,
+			created_at
+		FROM virtual_accounts
+		WHERE deleted_at IS NULL
+		AND provider = ?
+		AND account_number = ?
+	`
+
+	result, err = r.getVirtualAccount(ctx, query, provider, accountNumber)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[virtualAccountRepository][GetVirtualAccountByNumber] while scan query row. Err: %v", err))
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *virtualAccountRepository) getVirtualAccount(ctx context.Context, query string, args ...interface{}) (result VirtualAccount, err error) {
+	var scanner VirtualAccountScanner
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(
+		&scanner.ID,
+		&scanner.LoanID,
+		&scanner.ConsumerID,
+		&scanner.Provider,
+		&scanner.BankCode,
+		&scanner.AccountNumber,
+		&scanner.AccountName,
+		&scanner.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return result, nil
+		}
+		return result, err
+	}
+
+	result = VirtualAccount{
+		ID:            scanner.ID.Int64,
+		LoanID:        scanner.LoanID.Int64,
+		ConsumerID:    scanner.ConsumerID.Int64,
+		Provider:      scanner.Provider.String,
+		BankCode:      scanner.BankCode.String,
+		AccountNumber: scanner.AccountNumber.String,
+		AccountName:   scanner.AccountName.String,
+		CreatedAt:     scanner.CreatedAt.Time,
+	}
+
+	return result, nil
+}
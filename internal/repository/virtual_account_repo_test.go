@@ -0,0 +1,107 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateVirtualAccount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewVirtualAccountRepository(db)
+	account := repository.VirtualAccount{LoanID: 1, ConsumerID: 2, Provider: "fake", BankCode: "8808", AccountNumber: "8808000000000001", AccountName: "John Doe"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO virtual_accounts").
+					WithArgs(1, 2, "fake", "8808", "8808000000000001", "John Doe").
+					WillReturnResult(sqlmock.NewResult(3, 1))
+			},
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name: "exec error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO virtual_accounts").
+					WithArgs(1, 2, "fake", "8808", "8808000000000001", "John Doe").
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateVirtualAccount(context.Background(), account)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetVirtualAccount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewVirtualAccountRepository(db)
+	createdAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	columns := []string{"virtual_account_id", "loan_id", "consumer_id", "provider", "bank_code", "account_number", "account_name", "created_at"}
+	want := repository.VirtualAccount{ID: 3, LoanID: 1, ConsumerID: 2, Provider: "fake", BankCode: "8808", AccountNumber: "8808000000000001", AccountName: "John Doe", CreatedAt: createdAt}
+
+	mock.ExpectQuery("SELECT (.+) FROM virtual_accounts WHERE deleted_at IS NULL AND provider = \\? AND loan_id = \\?").
+		WithArgs("fake", 1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 1, 2, "fake", "8808", "8808000000000001", "John Doe", createdAt))
+	got, err := repo.GetVirtualAccountByLoanID(context.Background(), "fake", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	mock.ExpectQuery("SELECT (.+) FROM virtual_accounts WHERE deleted_at IS NULL AND provider = \\? AND account_number = \\?").
+		WithArgs("fake", "8808000000000001").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 1, 2, "fake", "8808", "8808000000000001", "John Doe", createdAt))
+	got, err = repo.GetVirtualAccountByNumber(context.Background(), "fake", "8808000000000001")
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	mock.ExpectQuery("SELECT (.+) FROM virtual_accounts").
+		WithArgs("fake", "unknown").
+		WillReturnError(sql.ErrNoRows)
+	got, err = repo.GetVirtualAccountByNumber(context.Background(), "fake", "unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, repository.VirtualAccount{}, got)
+
+	mock.ExpectQuery("SELECT (.+) FROM virtual_accounts").
+		WithArgs("fake", 2).
+		WillReturnError(sql.ErrConnDone)
+	_, err = repo.GetVirtualAccountByLoanID(context.Background(), "fake", 2)
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,110 @@
+package statement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+// ParseCSV reads a statement exported as CSV. The first row names the columns, in any
+// order and case: date formatted as YYYY-MM-DD, description, an optional reference, and
+// either a signed amount or separate credit and debit columns.
+func ParseCSV(r io.Reader) ([]Line, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidStatement)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	_, hasAmount := columns["amount"]
+	_, hasCredit := columns["credit"]
+	_, hasDebit := columns["debit"]
+	if _, ok := columns["date"]; !ok {
+		return nil, fmt.Errorf("%w: missing date column", ErrInvalidStatement)
+	}
+	if _, ok := columns["description"]; !ok {
+		return nil, fmt.Errorf("%w: missing description column", ErrInvalidStatement)
+	}
+	if !hasAmount && !(hasCredit && hasDebit) {
+		return nil, fmt.Errorf("%w: missing amount column, or credit and debit columns", ErrInvalidStatement)
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	lines := []Line{}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		valueDate, err := time.ParseInLocation("2006-01-02", field(record, "date"), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: date must be formatted as YYYY-MM-DD", ErrInvalidStatement, row)
+		}
+
+		var amount money.Money
+		if hasAmount {
+			amount, err = money.Parse(field(record, "amount"))
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d: invalid amount", ErrInvalidStatement, row)
+			}
+		} else {
+			credit, err := parseOptionalAmount(field(record, "credit"))
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d: invalid credit", ErrInvalidStatement, row)
+			}
+			debit, err := parseOptionalAmount(field(record, "debit"))
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d: invalid debit", ErrInvalidStatement, row)
+			}
+			amount = credit.Sub(debit)
+		}
+
+		lines = append(lines, Line{
+			Number:      len(lines) + 1,
+			ValueDate:   valueDate,
+			Amount:      amount,
+			Description: field(record, "description"),
+			Reference:   field(record, "reference"),
+		})
+	}
+
+	return lines, nil
+}
+
+func parseOptionalAmount(value string) (money.Money, error) {
+	if value == "" {
+		return money.Zero, nil
+	}
+
+	return money.Parse(value)
+}
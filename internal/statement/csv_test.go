@@ -0,0 +1,86 @@
+package statement_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/statement"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []statement.Line
+		wantErr string
+	}{
+		{
+			name: "signed amount",
+			input: "Date,Description,Reference,Amount\n" +
+				"2024-01-15,TRF 8808000000000042 JOHN DOE,FT001,150000.00\n" +
+				"\n" +
+				"2024-01-15,\"ADMIN FEE, JANUARY\",,-6500\n",
+			want: []statement.Line{
+				{Number: 1, ValueDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local), Amount: money.New(150000), Description: "TRF 8808000000000042 JOHN DOE", Reference: "FT001"},
+				{Number: 2, ValueDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local), Amount: money.New(-6500), Description: "ADMIN FEE, JANUARY"},
+			},
+		},
+		{
+			name: "credit and debit columns",
+			input: "\ufeffdescription,debit,credit,date\n" +
+				"PAYMENT 1-ABCDEFGHIJ-2,,200000,2024-01-16\n" +
+				"TRANSFER OUT,50000,,2024-01-16\n",
+			want: []statement.Line{
+				{Number: 1, ValueDate: time.Date(2024, 1, 16, 0, 0, 0, 0, time.Local), Amount: money.New(200000), Description: "PAYMENT 1-ABCDEFGHIJ-2"},
+				{Number: 2, ValueDate: time.Date(2024, 1, 16, 0, 0, 0, 0, time.Local), Amount: money.New(-50000), Description: "TRANSFER OUT"},
+			},
+		},
+		{
+			name:    "empty file",
+			input:   "",
+			wantErr: "invalid bank statement: missing header row",
+		},
+		{
+			name:    "missing amount column",
+			input:   "date,description,credit\n",
+			wantErr: "invalid bank statement: missing amount column, or credit and debit columns",
+		},
+		{
+			name:    "invalid date",
+			input:   "date,description,amount\n15/01/2024,TRF,1000\n",
+			wantErr: "invalid bank statement: row 2: date must be formatted as YYYY-MM-DD",
+		},
+		{
+			name:    "invalid amount",
+			input:   "date,description,amount\n2024-01-15,TRF,1.000.000\n",
+			wantErr: "invalid bank statement: row 2: invalid amount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := statement.ParseCSV(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.True(t, errors.Is(err, statement.ErrInvalidStatement))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	_, err := statement.Parse("xlsx", strings.NewReader(""))
+	assert.EqualError(t, err, `unknown bank statement format "xlsx"`)
+
+	got, err := statement.Parse(statement.FormatCSV, strings.NewReader("date,description,amount\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,157 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+var mt940Tag = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+// ParseMT940 reads a SWIFT MT940 customer statement. Every :61: statement line is a line,
+// described by the :86: information following it.
+func ParseMT940(r io.Reader) ([]Line, error) {
+	fields := []mt940Field{}
+	open := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if match := mt940Tag.FindStringSubmatch(text); match != nil {
+			fields = append(fields, mt940Field{tag: match[1], value: match[2]})
+			open = true
+			continue
+		}
+
+		// the block headers and the "-" closing a message end the field before them
+		if text == "" || strings.HasPrefix(text, "-") || strings.HasPrefix(text, "{") {
+			open = false
+			continue
+		}
+		if open {
+			fields[len(fields)-1].value += "\n" + text
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+
+	lines := []Line{}
+	described := true
+	for _, field := range fields {
+		switch field.tag {
+		case "61":
+			line, err := parseMT940StatementLine(field.value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: statement line %d: %v", ErrInvalidStatement, len(lines)+1, err)
+			}
+
+			line.Number = len(lines) + 1
+			lines = append(lines, line)
+			described = false
+		case "86":
+			if !described {
+				lines[len(lines)-1].Description = strings.Join(strings.Fields(field.value), " ")
+				described = true
+			}
+		default:
+			described = true
+		}
+	}
+
+	return lines, nil
+}
+
+// parseMT940StatementLine reads the first line of a :61: field: the value date as YYMMDD,
+// an optional MMDD entry date, the debit/credit mark, an optional funds code, the amount
+// with a decimal comma, the transaction type and the references of the account owner and
+// of the bank separated by "//".
+func parseMT940StatementLine(value string) (line Line, err error) {
+	rest := strings.SplitN(value, "\n", 2)[0]
+	if len(rest) < 6 {
+		return line, fmt.Errorf("missing value date")
+	}
+
+	line.ValueDate, err = time.ParseInLocation("060102", rest[:6], time.Local)
+	if err != nil {
+		return line, fmt.Errorf("invalid value date")
+	}
+	rest = rest[6:]
+
+	if len(rest) >= 4 && isDigits(rest[:4]) {
+		rest = rest[4:]
+	}
+
+	debit := false
+	switch {
+	case strings.HasPrefix(rest, "RC"):
+		// reversal of a credit
+		debit = true
+		rest = rest[2:]
+	case strings.HasPrefix(rest, "RD"):
+		rest = rest[2:]
+	case strings.HasPrefix(rest, "C"):
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "D"):
+		debit = true
+		rest = rest[1:]
+	default:
+		return line, fmt.Errorf("invalid debit/credit mark")
+	}
+
+	if rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+		rest = rest[1:]
+	}
+
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return (r < '0' || r > '9') && r != ','
+	})
+	if end == -1 {
+		end = len(rest)
+	}
+
+	line.Amount, err = money.Parse(strings.TrimSuffix(strings.Replace(rest[:end], ",", ".", 1), "."))
+	if err != nil {
+		return line, fmt.Errorf("invalid amount")
+	}
+	if debit {
+		line.Amount = money.Zero.Sub(line.Amount)
+	}
+	rest = rest[end:]
+
+	if len(rest) < 4 {
+		return line, fmt.Errorf("missing transaction type")
+	}
+	rest = rest[4:]
+
+	reference, bankReference := rest, ""
+	if i := strings.Index(rest, "//"); i >= 0 {
+		reference, bankReference = rest[:i], rest[i+2:]
+	}
+	if reference == "" || reference == "NONREF" {
+		reference = bankReference
+	}
+	line.Reference = strings.TrimSpace(reference)
+
+	return line, nil
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
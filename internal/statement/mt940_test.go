@@ -0,0 +1,72 @@
+package statement_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/statement"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMT940(t *testing.T) {
+	input := strings.Join([]string{
+		"{1:F01BANKIDJAXXXX0000000000}{2:I940XYZMIDJAXXXXN}{4:",
+		":20:STMT240115",
+		":25:1234567890",
+		":28C:15/1",
+		":60F:C240114IDR1000000,00",
+		":61:2401150115C150000,00NTRFFT001//BANKREF1",
+		":86:TRF VA 8808000000000042",
+		"JOHN DOE",
+		":61:240115DR6500,NCHGNONREF//BANKREF2",
+		":86:ADMIN FEE",
+		":61:240116RC1000,50NTRFNONREF//BANKREF3",
+		":62F:C240116IDR1142499,50",
+		":86:CLOSING BALANCE",
+		"-}",
+	}, "\r\n")
+
+	got, err := statement.ParseMT940(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, []statement.Line{
+		{Number: 1, ValueDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local), Amount: money.New(150000), Description: "TRF VA 8808000000000042 JOHN DOE", Reference: "FT001"},
+		{Number: 2, ValueDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local), Amount: money.New(-6500), Description: "ADMIN FEE", Reference: "BANKREF2"},
+		{Number: 3, ValueDate: time.Date(2024, 1, 16, 0, 0, 0, 0, time.Local), Amount: money.MustParse("-1000.5"), Reference: "BANKREF3"},
+	}, got)
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "invalid value date",
+			input:   ":61:241315C1000,NTRFNONREF",
+			wantErr: "invalid bank statement: statement line 1: invalid value date",
+		},
+		{
+			name:    "invalid mark",
+			input:   ":61:240115X1000,NTRFNONREF",
+			wantErr: "invalid bank statement: statement line 1: invalid debit/credit mark",
+		},
+		{
+			name:    "missing amount",
+			input:   ":61:240115CNTRFNONREF",
+			wantErr: "invalid bank statement: statement line 1: invalid amount",
+		},
+		{
+			name:    "missing transaction type",
+			input:   ":61:240115C1000,",
+			wantErr: "invalid bank statement: statement line 1: missing transaction type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := statement.ParseMT940(strings.NewReader(tt.input))
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Package statement reads the account mutations of bank statement files.
+package statement
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatMT940 = "mt940"
+)
+
+var ErrInvalidStatement = errors.New("invalid bank statement")
+
+// Line is an account mutation of a statement. Amount is positive for money received and
+// negative for money paid out. Number is the position of the line in the statement,
+// starting at 1.
+type Line struct {
+	Number      int
+	ValueDate   time.Time
+	Amount      money.Money
+	Description string
+	Reference   string
+}
+
+// Parse reads the lines of a statement in the given format.
+func Parse(format string, r io.Reader) ([]Line, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatMT940:
+		return ParseMT940(r)
+	}
+
+	return nil, fmt.Errorf("unknown bank statement format %q", format)
+}
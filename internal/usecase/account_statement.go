@@ -0,0 +1,435 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/pdf"
+)
+
+const (
+	AccountStatementEntryDisbursement   = "disbursement"
+	AccountStatementEntryCapitalization = "capitalization"
+	AccountStatementEntryRepayment      = "repayment"
+	AccountStatementEntryReversal       = "reversal"
+)
+
+var (
+	// accountStatementEntryOrder orders the entries booked at the same time, a loan is
+	// disbursed before anything is paid on it.
+	accountStatementEntryOrder = map[string]int{
+		AccountStatementEntryDisbursement:   0,
+		AccountStatementEntryCapitalization: 1,
+		AccountStatementEntryRepayment:      2,
+		AccountStatementEntryReversal:       3,
+	}
+
+	// statementComponentOrder is the order components of a payment are described in.
+	statementComponentOrder = []string{
+		AllocationComponentPenalty,
+		AllocationComponentInterest,
+		AllocationComponentPrincipal,
+		AllocationComponentSettlementFee,
+		AllocationComponentCredit,
+	}
+
+	// loanStatusDisbursed lists the statuses of a loan whose amount was paid out.
+	loanStatusDisbursed = map[string]bool{
+		LoanStatusDisbursed: true,
+		LoanStatusOnGoing:   true,
+		LoanStatusLate:      true,
+		LoanStatusFinish:    true,
+	}
+)
+
+type AccountStatementUsecase interface {
+	GetAccountStatement(ctx context.Context, req AccountStatementRequest) (response AccountStatementResponse, err error)
+}
+
+type accountStatementUsecase struct {
+	consumerRepo        repository.ConsumerRepository
+	loanRepo            repository.LoanRepository
+	loanRestructureRepo repository.LoanRestructureRepository
+	transactionRepo     repository.TransactionRepository
+	ctxTimeout          time.Duration
+}
+
+type (
+	// AccountStatementRequest asks for the statement of a consumer between From and To, both
+	// included and formatted as YYYY-MM-DD. It starts with the first loan when From is empty
+	// and ends today when To is empty. Format is how the statement is rendered, it does not
+	// change its content.
+	AccountStatementRequest struct {
+		ConsumerID int64  `json:"-"`
+		From       string `json:"from" query:"from"`
+		To         string `json:"to" query:"to"`
+		Format     string `json:"format" query:"format"`
+	}
+
+	// AccountStatementEntry is a change of the principal owed by the consumer. Amount is what
+	// was paid for repayments and reversals, only its principal part is credited.
+	AccountStatementEntry struct {
+		Date           string      `json:"date"`
+		Type           string      `json:"type"`
+		LoanID         int64       `json:"loan_id"`
+		TransactionID  int64       `json:"transaction_id,omitempty"`
+		ContractNumber string      `json:"contract_number"`
+		Description    string      `json:"description"`
+		Amount         money.Money `json:"amount"`
+		Debit          money.Money `json:"debit"`
+		Credit         money.Money `json:"credit"`
+		Balance        money.Money `json:"balance"`
+	}
+
+	// AccountStatementResponse lists the entries of a period along with the principal owed
+	// by the consumer before and after it.
+	AccountStatementResponse struct {
+		ConsumerID     int64                   `json:"consumer_id"`
+		FullName       string                  `json:"full_name"`
+		From           string                  `json:"from"`
+		To             string                  `json:"to"`
+		OpeningBalance money.Money             `json:"opening_balance"`
+		Entries        []AccountStatementEntry `json:"entries"`
+		TotalDebit     money.Money             `json:"total_debit"`
+		TotalCredit    money.Money             `json:"total_credit"`
+		ClosingBalance money.Money             `json:"closing_balance"`
+	}
+)
+
+type accountStatementEvent struct {
+	at    time.Time
+	id    int64
+	entry AccountStatementEntry
+}
+
+func NewAccountStatementUsecase(
+	consumerRepo repository.ConsumerRepository,
+	loanRepo repository.LoanRepository,
+	loanRestructureRepo repository.LoanRestructureRepository,
+	transactionRepo repository.TransactionRepository,
+	timeout time.Duration,
+) AccountStatementUsecase {
+	return &accountStatementUsecase{
+		consumerRepo:        consumerRepo,
+		loanRepo:            loanRepo,
+		loanRestructureRepo: loanRestructureRepo,
+		transactionRepo:     transactionRepo,
+		ctxTimeout:          timeout,
+	}
+}
+
+// GetAccountStatement returns the statement of a consumer, or an empty response when the
+// consumer does not exist.
+func (uc *accountStatementUsecase) GetAccountStatement(ctx context.Context, req AccountStatementRequest) (response AccountStatementResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	var from time.Time
+	if req.From != "" {
+		from, err = time.ParseInLocation("2006-01-02", req.From, time.Local)
+		if err != nil {
+			return response, errors.New("from must be formatted as YYYY-MM-DD")
+		}
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if req.To != "" {
+		to, err = time.ParseInLocation("2006-01-02", req.To, time.Local)
+		if err != nil {
+			return response, errors.New("to must be formatted as YYYY-MM-DD")
+		}
+	}
+
+	if from.After(to) {
+		return response, errors.New("from must not be after to")
+	}
+
+	consumer, err := uc.consumerRepo.GetConsumerByID(ctx, req.ConsumerID)
+	if err != nil {
+		return response, err
+	}
+	if consumer.ID == 0 {
+		return response, nil
+	}
+
+	events, err := uc.accountStatementEvents(ctx, consumer.ID)
+	if err != nil {
+		return response, err
+	}
+
+	response = AccountStatementResponse{
+		ConsumerID: consumer.ID,
+		FullName:   consumer.FullName,
+		From:       req.From,
+		To:         to.Format("2006-01-02"),
+		Entries:    []AccountStatementEntry{},
+	}
+
+	// the whole last day is included
+	end := to.AddDate(0, 0, 1)
+	balance := money.Zero
+	for _, event := range events {
+		if !event.at.Before(end) {
+			break
+		}
+
+		balance = balance.Add(event.entry.Debit).Sub(event.entry.Credit)
+		if event.at.Before(from) {
+			response.OpeningBalance = balance
+			continue
+		}
+
+		event.entry.Balance = balance
+		response.Entries = append(response.Entries, event.entry)
+		response.TotalDebit = response.TotalDebit.Add(event.entry.Debit)
+		response.TotalCredit = response.TotalCredit.Add(event.entry.Credit)
+	}
+	response.ClosingBalance = balance
+
+	if response.From == "" && len(response.Entries) > 0 {
+		response.From = response.Entries[0].Date[:len("2006-01-02")]
+	}
+
+	return response, nil
+}
+
+// accountStatementEvents returns every change of the principal owed by a consumer, oldest
+// first: the disbursement of their loans, the interest capitalized by restructures and the
+// principal of their payments.
+func (uc *accountStatementUsecase) accountStatementEvents(ctx context.Context, consumerID int64) ([]accountStatementEvent, error) {
+	loans, err := uc.loanRepo.GetLoanByConsumerID(ctx, consumerID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[AccountStatementUsecase][GetAccountStatement] while get loan by consumer ID, Err: %+v", err))
+		return nil, err
+	}
+
+	var events []accountStatementEvent
+	contractNumbers := make(map[int64]string, len(loans))
+	for _, loan := range loans {
+		contractNumbers[loan.ID] = loan.ContractNumber
+
+		disbursedAt := loan.DisbursedAt
+		if disbursedAt.IsZero() {
+			if !loanStatusDisbursed[loan.LoanStatus] {
+				continue
+			}
+			// disbursed before the disbursement time was recorded
+			disbursedAt = loan.CreatedAt
+		}
+
+		restructures, err := uc.loanRestructureRepo.GetLoanRestructuresByLoanID(ctx, loan.ID)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[AccountStatementUsecase][GetAccountStatement] while get loan restructures, Err: %+v", err))
+			return nil, err
+		}
+
+		// the loan amount grows with the interest capitalized by each restructure
+		disbursedAmount := loan.LoanAmount
+		if len(restructures) > 0 {
+			disbursedAmount = restructures[0].PreviousLoanAmount
+		}
+
+		description := fmt.Sprintf("Loan %s disbursed", loan.ContractNumber)
+		if loan.AssetName != "" {
+			description = fmt.Sprintf("Loan %s disbursed for %s", loan.ContractNumber, loan.AssetName)
+		}
+		events = append(events, accountStatementEvent{
+			at: disbursedAt,
+			id: loan.ID,
+			entry: AccountStatementEntry{
+				Date:           formatOptionalTime(disbursedAt),
+				Type:           AccountStatementEntryDisbursement,
+				LoanID:         loan.ID,
+				ContractNumber: loan.ContractNumber,
+				Description:    description,
+				Amount:         disbursedAmount,
+				Debit:          disbursedAmount,
+			},
+		})
+
+		for _, restructure := range restructures {
+			capitalizedInterest := restructure.LoanAmount.Sub(restructure.PreviousLoanAmount)
+			if capitalizedInterest.IsZero() {
+				continue
+			}
+
+			events = append(events, accountStatementEvent{
+				at: restructure.CreatedAt,
+				id: restructure.ID,
+				entry: AccountStatementEntry{
+					Date:           formatOptionalTime(restructure.CreatedAt),
+					Type:           AccountStatementEntryCapitalization,
+					LoanID:         loan.ID,
+					ContractNumber: loan.ContractNumber,
+					Description:    fmt.Sprintf("Interest capitalized by restructure of loan %s", loan.ContractNumber),
+					Amount:         capitalizedInterest,
+					Debit:          capitalizedInterest,
+				},
+			})
+		}
+	}
+
+	transactions, err := uc.transactionRepo.GetTransactionsByConsumerID(ctx, consumerID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[AccountStatementUsecase][GetAccountStatement] while get transactions by consumer ID, Err: %+v", err))
+		return nil, err
+	}
+
+	if len(transactions) > 0 {
+		transactionIDs := make([]int64, 0, len(transactions))
+		for _, transaction := range transactions {
+			transactionIDs = append(transactionIDs, transaction.ID)
+		}
+
+		allocations, err := uc.transactionRepo.GetTransactionAllocationsByTransactionIDs(ctx, transactionIDs)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[AccountStatementUsecase][GetAccountStatement] while get transaction allocations, Err: %+v", err))
+			return nil, err
+		}
+
+		components := make(map[int64]map[string]money.Money, len(transactions))
+		for _, allocation := range allocations {
+			if components[allocation.TransactionID] == nil {
+				components[allocation.TransactionID] = make(map[string]money.Money)
+			}
+			components[allocation.TransactionID][allocation.Component] = components[allocation.TransactionID][allocation.Component].Add(allocation.Amount)
+		}
+
+		for _, transaction := range transactions {
+			events = append(events, transactionStatementEvent(transaction, contractNumbers[transaction.LoanID], components[transaction.ID]))
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		if events[i].entry.Type != events[j].entry.Type {
+			return accountStatementEntryOrder[events[i].entry.Type] < accountStatementEntryOrder[events[j].entry.Type]
+		}
+
+		return events[i].id < events[j].id
+	})
+
+	return events, nil
+}
+
+// transactionStatementEvent credits the principal paid by a transaction, a reversal debits
+// it back. Transactions booked before payments were broken down have no components and are
+// taken as principal entirely.
+func transactionStatementEvent(transaction repository.Transaction, contractNumber string, components map[string]money.Money) accountStatementEvent {
+	entry := AccountStatementEntry{
+		Date:           formatOptionalTime(transaction.CreatedAt),
+		Type:           AccountStatementEntryRepayment,
+		LoanID:         transaction.LoanID,
+		TransactionID:  transaction.ID,
+		ContractNumber: contractNumber,
+		Description:    transaction.Description,
+		Amount:         transaction.Amount,
+	}
+	if transaction.ReversalOfTransactionID != 0 {
+		entry.Type = AccountStatementEntryReversal
+	}
+
+	principal := transaction.Amount
+	if components != nil {
+		principal = components[AllocationComponentPrincipal]
+
+		var breakdown []string
+		for _, component := range statementComponentOrder {
+			if amount, ok := components[component]; ok && !amount.IsZero() {
+				breakdown = append(breakdown, fmt.Sprintf("%s %s", strings.ReplaceAll(component, "_", " "), amount))
+			}
+		}
+		if len(breakdown) > 0 {
+			entry.Description = fmt.Sprintf("%s (%s)", entry.Description, strings.Join(breakdown, ", "))
+		}
+	}
+
+	if principal.IsNegative() {
+		entry.Debit = money.Zero.Sub(principal)
+	} else {
+		entry.Credit = principal
+	}
+
+	return accountStatementEvent{at: transaction.CreatedAt, id: transaction.ID, entry: entry}
+}
+
+// WriteCSV writes the statement as CSV, the opening and closing balances are the first and
+// last rows.
+func (s AccountStatementResponse) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	rows := [][]string{
+		{"date", "type", "contract_number", "description", "amount", "debit", "credit", "balance"},
+		{s.From, "opening_balance", "", "Opening balance", "", "", "", s.OpeningBalance.String()},
+	}
+	for _, entry := range s.Entries {
+		rows = append(rows, []string{
+			entry.Date,
+			entry.Type,
+			entry.ContractNumber,
+			entry.Description,
+			entry.Amount.String(),
+			entry.Debit.String(),
+			entry.Credit.String(),
+			entry.Balance.String(),
+		})
+	}
+	rows = append(rows, []string{s.To, "closing_balance", "", "Closing balance", "", s.TotalDebit.String(), s.TotalCredit.String(), s.ClosingBalance.String()})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+// WritePDF writes the statement as a PDF document, each entry is followed by its
+// description on a line of its own.
+func (s AccountStatementResponse) WritePDF(w io.Writer) error {
+	var doc pdf.Document
+
+	row := func(date, entryType, contractNumber, debit, credit, balance string) {
+		doc.Line(fmt.Sprintf("%-19s %-14s %-16s %13s %13s %14s", date, entryType, truncateString(contractNumber, 16), debit, credit, balance))
+	}
+	rule := strings.Repeat("-", pdf.LineWidth)
+
+	doc.Line("ACCOUNT STATEMENT")
+	doc.Line("")
+	doc.Line(fmt.Sprintf("Consumer : %s (ID %d)", s.FullName, s.ConsumerID))
+	doc.Line(fmt.Sprintf("Period   : %s - %s", s.From, s.To))
+	doc.Line("")
+	row("Date", "Type", "Contract", "Debit", "Credit", "Balance")
+	doc.Line(rule)
+	row(s.From, "Opening", "", "", "", s.OpeningBalance.String())
+	for _, entry := range s.Entries {
+		debit, credit := "", ""
+		if !entry.Debit.IsZero() {
+			debit = entry.Debit.String()
+		}
+		if !entry.Credit.IsZero() {
+			credit = entry.Credit.String()
+		}
+
+		row(entry.Date, entry.Type, entry.ContractNumber, debit, credit, entry.Balance.String())
+		if entry.Description != "" {
+			doc.Line("    " + entry.Description)
+		}
+	}
+	doc.Line(rule)
+	row(s.To, "Closing", "", s.TotalDebit.String(), s.TotalCredit.String(), s.ClosingBalance.String())
+
+	_, err := doc.WriteTo(w)
+	return err
+}
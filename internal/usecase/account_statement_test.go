@@ -0,0 +1,188 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAccountStatement(t *testing.T) {
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
+	mockTransactionRepo := new(mocks.TransactionRepository)
+	uc := usecase.NewAccountStatementUsecase(mockConsumerRepo, mockLoanRepo, mockLoanRestructureRepo, mockTransactionRepo, time.Second*2)
+
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, 1, d, hour, 0, 0, 0, time.Local)
+	}
+
+	setupHistory := func() {
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1, FullName: "John Doe"}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, int64(1)).Return([]repository.Loan{
+			{ID: 1, ContractNumber: "CN-1", LoanAmount: money.New(1100), LoanStatus: "on_going", AssetName: "Laptop", DisbursedAt: day(2, 9), CreatedAt: day(1, 9)},
+			{ID: 2, ContractNumber: "CN-2", LoanAmount: money.New(500), LoanStatus: "pending_review", CreatedAt: day(3, 9)},
+			// disbursed before the disbursement time was recorded
+			{ID: 3, ContractNumber: "CN-3", LoanAmount: money.New(200), LoanStatus: "finish", CreatedAt: day(1, 8)},
+		}, nil).Once()
+		mockLoanRestructureRepo.On("GetLoanRestructuresByLoanID", mock.Anything, int64(1)).Return([]repository.LoanRestructure{
+			{ID: 1, LoanID: 1, PreviousLoanAmount: money.New(1000), LoanAmount: money.New(1100), CreatedAt: day(20, 9)},
+		}, nil).Once()
+		mockLoanRestructureRepo.On("GetLoanRestructuresByLoanID", mock.Anything, int64(3)).Return([]repository.LoanRestructure{}, nil).Once()
+		mockTransactionRepo.On("GetTransactionsByConsumerID", mock.Anything, int64(1)).Return([]repository.Transaction{
+			{ID: 4, LoanID: 1, ReversalOfTransactionID: 3, Amount: money.New(-130), Description: "Reversal of transaction 3", CreatedAt: day(16, 9)},
+			{ID: 3, LoanID: 1, Amount: money.New(130), Description: "Payment for loan CN-1", CreatedAt: day(15, 9)},
+			{ID: 2, LoanID: 1, Amount: money.New(100), Description: "Payment for loan CN-1", CreatedAt: day(10, 9)},
+			{ID: 1, LoanID: 3, Amount: money.New(200), Description: "Payment for loan CN-3", CreatedAt: day(1, 10)},
+		}, nil).Once()
+		mockTransactionRepo.On("GetTransactionAllocationsByTransactionIDs", mock.Anything, []int64{4, 3, 2, 1}).Return([]repository.TransactionAllocation{
+			{TransactionID: 2, InstallmentNumber: 1, Component: "interest", Amount: money.New(20)},
+			{TransactionID: 2, InstallmentNumber: 1, Component: "principal", Amount: money.New(80)},
+			{TransactionID: 3, InstallmentNumber: 2, Component: "principal", Amount: money.New(100)},
+			{TransactionID: 3, Component: "credit", Amount: money.New(30)},
+			{TransactionID: 4, InstallmentNumber: 2, Component: "principal", Amount: money.New(-100)},
+			{TransactionID: 4, Component: "credit", Amount: money.New(-30)},
+		}, nil).Once()
+	}
+
+	tests := []struct {
+		name    string
+		req     usecase.AccountStatementRequest
+		setup   func()
+		want    usecase.AccountStatementResponse
+		wantErr string
+	}{
+		{
+			name:    "invalid from",
+			req:     usecase.AccountStatementRequest{ConsumerID: 1, From: "01-01-2024"},
+			setup:   func() {},
+			wantErr: "from must be formatted as YYYY-MM-DD",
+		},
+		{
+			name:    "from after to",
+			req:     usecase.AccountStatementRequest{ConsumerID: 1, From: "2024-02-01", To: "2024-01-31"},
+			setup:   func() {},
+			wantErr: "from must not be after to",
+		},
+		{
+			name: "consumer not found",
+			req:  usecase.AccountStatementRequest{ConsumerID: 9, To: "2024-01-31"},
+			setup: func() {
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(9)).Return(repository.Consumer{}, nil).Once()
+			},
+			want: usecase.AccountStatementResponse{},
+		},
+		{
+			name:  "whole history",
+			req:   usecase.AccountStatementRequest{ConsumerID: 1, To: "2024-01-31"},
+			setup: setupHistory,
+			want: usecase.AccountStatementResponse{
+				ConsumerID: 1,
+				FullName:   "John Doe",
+				From:       "2024-01-01",
+				To:         "2024-01-31",
+				Entries: []usecase.AccountStatementEntry{
+					{Date: "2024-01-01 08:00:00", Type: "disbursement", LoanID: 3, ContractNumber: "CN-3", Description: "Loan CN-3 disbursed", Amount: money.New(200), Debit: money.New(200), Balance: money.New(200)},
+					{Date: "2024-01-01 10:00:00", Type: "repayment", LoanID: 3, TransactionID: 1, ContractNumber: "CN-3", Description: "Payment for loan CN-3", Amount: money.New(200), Credit: money.New(200), Balance: money.Zero},
+					{Date: "2024-01-02 09:00:00", Type: "disbursement", LoanID: 1, ContractNumber: "CN-1", Description: "Loan CN-1 disbursed for Laptop", Amount: money.New(1000), Debit: money.New(1000), Balance: money.New(1000)},
+					{Date: "2024-01-10 09:00:00", Type: "repayment", LoanID: 1, TransactionID: 2, ContractNumber: "CN-1", Description: "Payment for loan CN-1 (interest 20, principal 80)", Amount: money.New(100), Credit: money.New(80), Balance: money.New(920)},
+					{Date: "2024-01-15 09:00:00", Type: "repayment", LoanID: 1, TransactionID: 3, ContractNumber: "CN-1", Description: "Payment for loan CN-1 (principal 100, credit 30)", Amount: money.New(130), Credit: money.New(100), Balance: money.New(820)},
+					{Date: "2024-01-16 09:00:00", Type: "reversal", LoanID: 1, TransactionID: 4, ContractNumber: "CN-1", Description: "Reversal of transaction 3 (principal -100, credit -30)", Amount: money.New(-130), Debit: money.New(100), Balance: money.New(920)},
+					{Date: "2024-01-20 09:00:00", Type: "capitalization", LoanID: 1, ContractNumber: "CN-1", Description: "Interest capitalized by restructure of loan CN-1", Amount: money.New(100), Debit: money.New(100), Balance: money.New(1020)},
+				},
+				TotalDebit:     money.New(1400),
+				TotalCredit:    money.New(380),
+				ClosingBalance: money.New(1020),
+			},
+		},
+		{
+			name:  "period folds earlier entries into the opening balance",
+			req:   usecase.AccountStatementRequest{ConsumerID: 1, From: "2024-01-10", To: "2024-01-15"},
+			setup: setupHistory,
+			want: usecase.AccountStatementResponse{
+				ConsumerID:     1,
+				FullName:       "John Doe",
+				From:           "2024-01-10",
+				To:             "2024-01-15",
+				OpeningBalance: money.New(1000),
+				Entries: []usecase.AccountStatementEntry{
+					{Date: "2024-01-10 09:00:00", Type: "repayment", LoanID: 1, TransactionID: 2, ContractNumber: "CN-1", Description: "Payment for loan CN-1 (interest 20, principal 80)", Amount: money.New(100), Credit: money.New(80), Balance: money.New(920)},
+					{Date: "2024-01-15 09:00:00", Type: "repayment", LoanID: 1, TransactionID: 3, ContractNumber: "CN-1", Description: "Payment for loan CN-1 (principal 100, credit 30)", Amount: money.New(130), Credit: money.New(100), Balance: money.New(820)},
+				},
+				TotalCredit:    money.New(180),
+				ClosingBalance: money.New(820),
+			},
+		},
+		{
+			name: "repository error",
+			req:  usecase.AccountStatementRequest{ConsumerID: 1},
+			setup: func() {
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, int64(1)).Return(nil, errors.New("db down")).Once()
+			},
+			wantErr: "db down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, err := uc.GetAccountStatement(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	mockConsumerRepo.AssertExpectations(t)
+	mockLoanRepo.AssertExpectations(t)
+	mockLoanRestructureRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestAccountStatementWrite(t *testing.T) {
+	statement := usecase.AccountStatementResponse{
+		ConsumerID:     1,
+		FullName:       "John Doe",
+		From:           "2024-01-10",
+		To:             "2024-01-15",
+		OpeningBalance: money.New(1000),
+		Entries: []usecase.AccountStatementEntry{
+			{Date: "2024-01-10 09:00:00", Type: "repayment", LoanID: 1, TransactionID: 2, ContractNumber: "CN-1", Description: "Payment for loan CN-1, (interest 20, principal 80)", Amount: money.New(100), Credit: money.New(80), Balance: money.New(920)},
+		},
+		TotalCredit:    money.New(80),
+		ClosingBalance: money.New(920),
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, statement.WriteCSV(&buf))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"date", "type", "contract_number", "description", "amount", "debit", "credit", "balance"},
+		{"2024-01-10", "opening_balance", "", "Opening balance", "", "", "", "1000"},
+		{"2024-01-10 09:00:00", "repayment", "CN-1", "Payment for loan CN-1, (interest 20, principal 80)", "100", "0", "80", "920"},
+		{"2024-01-15", "closing_balance", "", "Closing balance", "", "0", "80", "920"},
+	}, rows)
+
+	buf.Reset()
+	assert.NoError(t, statement.WritePDF(&buf))
+	assert.True(t, strings.HasPrefix(buf.String(), "%PDF-1.4"))
+	assert.Contains(t, buf.String(), "(Consumer : John Doe \\(ID 1\\)) Tj")
+	assert.Contains(t, buf.String(), "(    Payment for loan CN-1, \\(interest 20, principal 80\\)) Tj")
+}
@@ -0,0 +1,365 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+)
+
+const (
+	JobEndOfDay = "end_of_day"
+)
+
+// ErrBatchAlreadyRun is returned when the batch of a run date was claimed by another run,
+// possibly of another instance of the service.
+var ErrBatchAlreadyRun = errors.New("batch already ran for the run date")
+
+type BatchUsecase interface {
+	RunEndOfDay(ctx context.Context, runDate time.Time) (response BatchRunResponse, err error)
+}
+
+type batchUsecase struct {
+	batchRunRepo        repository.BatchRunRepository
+	loanRepo            repository.LoanRepository
+	loanInstallmentRepo repository.LoanInstallmentRepository
+	consumerLimitRepo   repository.ConsumerLimitRepository
+	loanPenaltyRepo     repository.LoanPenaltyRepository
+	penaltyCalculator   PenaltyCalculator
+	ctxTimeout          time.Duration
+}
+
+type (
+	BatchRunResponse struct {
+		ID           int64                  `json:"id"`
+		JobName      string                 `json:"job_name"`
+		RunDate      string                 `json:"run_date"`
+		BatchStatus  string                 `json:"batch_status"`
+		TotalLoans   int32                  `json:"total_loans"`
+		UpdatedLoans int32                  `json:"updated_loans"`
+		FailedLoans  int32                  `json:"failed_loans"`
+		Items        []BatchRunItemResponse `json:"items"`
+	}
+
+	BatchRunItemResponse struct {
+		LoanID              int64  `json:"loan_id"`
+		ItemStatus          string `json:"item_status"`
+		PreviousLoanStatus  string `json:"previous_loan_status"`
+		LoanStatus          string `json:"loan_status"`
+		PreviousDaysPastDue int32  `json:"previous_days_past_due"`
+		DaysPastDue         int32  `json:"days_past_due"`
+		ErrorMessage        string `json:"error_message,omitempty"`
+	}
+)
+
+func NewBatchUsecase(
+	batchRunRepo repository.BatchRunRepository,
+	loanRepo repository.LoanRepository,
+	loanInstallmentRepo repository.LoanInstallmentRepository,
+	consumerLimitRepo repository.ConsumerLimitRepository,
+	loanPenaltyRepo repository.LoanPenaltyRepository,
+	penaltyConfig config.PenaltyConfig,
+	timeout time.Duration,
+) BatchUsecase {
+	return &batchUsecase{
+		batchRunRepo:        batchRunRepo,
+		loanRepo:            loanRepo,
+		loanInstallmentRepo: loanInstallmentRepo,
+		consumerLimitRepo:   consumerLimitRepo,
+		loanPenaltyRepo:     loanPenaltyRepo,
+		penaltyCalculator:   NewPenaltyCalculator(penaltyConfig),
+		ctxTimeout:          timeout,
+	}
+}
+
+// RunEndOfDay recomputes the days past due of every disbursed loan as of runDate, moving
+// overdue loans to late and late loans that caught up back to on_going, and accrues the
+// penalties of their overdue installments up to runDate. Every loan that changed is
+// recorded on the batch run log, as is every loan that failed to update; a loan failing
+// does not stop the batch. The batch runs once per run date, ErrBatchAlreadyRun is returned
+// without touching any loan when the run date was claimed by another run.
+func (uc *batchUsecase) RunEndOfDay(ctx context.Context, runDate time.Time) (response BatchRunResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	run := repository.BatchRun{
+		JobName:     JobEndOfDay,
+		RunDate:     runDate,
+		BatchStatus: "running",
+	}
+
+	run.ID, err = uc.batchRunRepo.CreateBatchRun(ctx, run)
+	if errors.Is(err, repository.ErrBatchRunAlreadyClaimed) {
+		return response, ErrBatchAlreadyRun
+	}
+	if err != nil {
+		return response, err
+	}
+
+	response.ID = run.ID
+	response.JobName = run.JobName
+	response.RunDate = runDate.Format("2006-01-02")
+
+	items, totalLoans, err := uc.updateDaysPastDue(ctx, run.ID, runDate)
+	run.TotalLoans = totalLoans
+	for _, item := range items {
+		if item.ItemStatus == repository.BatchRunItemFailed {
+			run.FailedLoans++
+		} else {
+			run.UpdatedLoans++
+		}
+	}
+	run.BatchStatus = "success"
+	if err != nil {
+		run.BatchStatus = "failed"
+		run.ErrorMessage = err.Error()
+	}
+
+	// the run log is closed even when the job ran out of time, so it gets its own context
+	finishCtx, finishCancel := context.WithTimeout(context.Background(), uc.ctxTimeout)
+	defer finishCancel()
+
+	finishErr := uc.batchRunRepo.FinishBatchRun(finishCtx, run)
+	if err != nil {
+		return response, err
+	}
+	if finishErr != nil {
+		return response, finishErr
+	}
+
+	response.BatchStatus = run.BatchStatus
+	response.TotalLoans = run.TotalLoans
+	response.UpdatedLoans = run.UpdatedLoans
+	response.FailedLoans = run.FailedLoans
+	response.Items = items
+
+	return response, nil
+}
+
+// updateDaysPastDue updates the active loans one at a time. A loan that fails to update is
+// logged as a failed item and the batch goes on with the next one, unless the batch ran out
+// of time.
+func (uc *batchUsecase) updateDaysPastDue(ctx context.Context, batchRunID int64, runDate time.Time) (items []BatchRunItemResponse, totalLoans int32, err error) {
+	loans, err := uc.loanRepo.GetActiveLoans(ctx)
+	if err != nil {
+		return items, totalLoans, err
+	}
+
+	for _, activeLoan := range loans {
+		totalLoans++
+
+		item, updated, err := uc.updateLoanDaysPastDue(ctx, batchRunID, activeLoan.ID, runDate)
+		if err != nil {
+			if ctx.Err() != nil {
+				return items, totalLoans, err
+			}
+
+			logger.Error(fmt.Sprintf("[BatchUsecase][updateDaysPastDue] while update loan %d, Err: %+v", activeLoan.ID, err))
+			item = repository.BatchRunItem{
+				BatchRunID:          batchRunID,
+				LoanID:              activeLoan.ID,
+				ItemStatus:          repository.BatchRunItemFailed,
+				PreviousLoanStatus:  activeLoan.LoanStatus,
+				LoanStatus:          activeLoan.LoanStatus,
+				PreviousDaysPastDue: activeLoan.DaysPastDue,
+				DaysPastDue:         activeLoan.DaysPastDue,
+				ErrorMessage:        err.Error(),
+			}
+
+			err = uc.batchRunRepo.CreateBatchRunItem(ctx, item, nil)
+			if err != nil {
+				return items, totalLoans, err
+			}
+		} else if !updated {
+			continue
+		}
+
+		items = append(items, BatchRunItemResponse{
+			LoanID:              item.LoanID,
+			ItemStatus:          item.ItemStatus,
+			PreviousLoanStatus:  item.PreviousLoanStatus,
+			LoanStatus:          item.LoanStatus,
+			PreviousDaysPastDue: item.PreviousDaysPastDue,
+			DaysPastDue:         item.DaysPastDue,
+			ErrorMessage:        item.ErrorMessage,
+		})
+	}
+
+	return items, totalLoans, nil
+}
+
+// updateLoanDaysPastDue recomputes the days past due of a loan as of runDate and stores the
+// penalties its overdue installments accrued until then. The loan is locked and read again,
+// so a payment booked since the active loans were listed is taken into account and a loan
+// repaid meanwhile is left alone. It reports whether the status or days past due of the
+// loan were updated.
+func (uc *batchUsecase) updateLoanDaysPastDue(ctx context.Context, batchRunID int64, loanID int64, runDate time.Time) (item repository.BatchRunItem, updated bool, err error) {
+	tx, err := uc.loanRepo.BeginTx(ctx)
+	if err != nil {
+		return item, false, err
+	}
+
+	found, err := uc.loanRepo.LockLoanByID(ctx, loanID, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return item, false, err
+	}
+	if !found {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return item, false, nil
+	}
+
+	loan, err := uc.loanRepo.GetLoanByID(ctx, loanID)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return item, false, err
+	}
+	if !loanStatusActive[loan.LoanStatus] {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return item, false, nil
+	}
+
+	schedule, err := uc.loanSchedule(ctx, loan)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return item, false, err
+	}
+
+	daysPastDue := calculateDaysPastDue(schedule, runDate)
+	loanStatus := loan.LoanStatus
+	if daysPastDue > 0 {
+		loanStatus = LoanStatusLate
+	} else if loan.LoanStatus == LoanStatusLate {
+		loanStatus = LoanStatusOnGoing
+	}
+
+	storedPenalties, err := uc.loanPenaltyRepo.GetLoanPenaltiesByLoanID(ctx, loan.ID)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return item, false, err
+	}
+	penalties := accruedPenalties(uc.penaltyCalculator.Accrue(schedule, storedPenalties, runDate), storedPenalties)
+
+	updated = loanStatus != loan.LoanStatus || daysPastDue != loan.DaysPastDue
+	if !updated && len(penalties) == 0 {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return item, false, nil
+	}
+
+	for _, penalty := range penalties {
+		err = uc.loanPenaltyRepo.UpsertLoanPenalty(ctx, penalty, tx)
+		if err != nil {
+			uc.loanRepo.RollbackTx(ctx, tx)
+			return item, false, err
+		}
+	}
+
+	if updated {
+		item, err = uc.saveDaysPastDue(ctx, batchRunID, loan, loanStatus, daysPastDue, tx)
+		if err != nil {
+			uc.loanRepo.RollbackTx(ctx, tx)
+			return item, false, err
+		}
+	}
+
+	err = uc.loanRepo.CommitTx(ctx, tx)
+	if err != nil {
+		return item, false, err
+	}
+
+	return item, updated, nil
+}
+
+// saveDaysPastDue sets the status and days past due of a loan and records the change on
+// the batch run log.
+func (uc *batchUsecase) saveDaysPastDue(ctx context.Context, batchRunID int64, loan repository.Loan, loanStatus string, daysPastDue int32, tx *sql.Tx) (item repository.BatchRunItem, err error) {
+	err = uc.loanRepo.UpdateLoanDaysPastDue(ctx, repository.UpdateLoanDaysPastDueRequest{
+		ID:              loan.ID,
+		FromStatus:      loan.LoanStatus,
+		FromDaysPastDue: loan.DaysPastDue,
+		LoanStatus:      loanStatus,
+		DaysPastDue:     daysPastDue,
+	}, tx)
+	if err != nil {
+		return item, err
+	}
+
+	item = repository.BatchRunItem{
+		BatchRunID:          batchRunID,
+		LoanID:              loan.ID,
+		ItemStatus:          repository.BatchRunItemUpdated,
+		PreviousLoanStatus:  loan.LoanStatus,
+		LoanStatus:          loanStatus,
+		PreviousDaysPastDue: loan.DaysPastDue,
+		DaysPastDue:         daysPastDue,
+	}
+
+	err = uc.batchRunRepo.CreateBatchRunItem(ctx, item, tx)
+	if err != nil {
+		return item, err
+	}
+
+	return item, nil
+}
+
+// accruedPenalties returns the penalties of accrued that grew from their stored amount.
+func accruedPenalties(accrued []repository.LoanPenalty, stored []repository.LoanPenalty) (penalties []repository.LoanPenalty) {
+	storedByInstallment := make(map[int32]repository.LoanPenalty, len(stored))
+	for _, penalty := range stored {
+		storedByInstallment[penalty.InstallmentNumber] = penalty
+	}
+
+	for _, penalty := range accrued {
+		if penalty.PenaltyAmount.GreaterThan(storedByInstallment[penalty.InstallmentNumber].PenaltyAmount) {
+			penalties = append(penalties, penalty)
+		}
+	}
+
+	return penalties
+}
+
+// loanSchedule returns the installments of a loan, rebuilt from the loan for legacy loans
+// without schedule rows.
+func (uc *batchUsecase) loanSchedule(ctx context.Context, loan repository.Loan) (schedule []repository.LoanInstallment, err error) {
+	schedule, err = uc.loanInstallmentRepo.GetLoanInstallmentsByLoanID(ctx, loan.ID)
+	if err != nil {
+		return schedule, err
+	}
+	if len(schedule) > 0 {
+		return schedule, nil
+	}
+
+	consumerLimit, err := uc.consumerLimitRepo.GetConsumerLimitByID(ctx, loan.ConsumerLimitID)
+	if err != nil {
+		return schedule, err
+	}
+	if consumerLimit.ID == 0 {
+		return schedule, errors.New("consumer limit not found")
+	}
+
+	return rebuildInstallmentSchedule(loan, consumerLimit.Tenure)
+}
+
+// calculateDaysPastDue returns the days between the due date of the oldest unpaid
+// installment and asOf, or 0 when nothing is overdue.
+func calculateDaysPastDue(schedule []repository.LoanInstallment, asOf time.Time) int32 {
+	for _, installment := range schedule {
+		if isInstallmentClosed(installment.InstallmentStatus) {
+			continue
+		}
+
+		daysPastDue := daysBetween(installment.DueDate, asOf)
+		if daysPastDue < 0 {
+			return 0
+		}
+
+		return daysPastDue
+	}
+
+	return 0
+}
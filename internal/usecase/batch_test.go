@@ -0,0 +1,234 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunEndOfDay(t *testing.T) {
+	mockBatchRunRepo := new(mocks.BatchRunRepository)
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+	mockLoanPenaltyRepo := new(mocks.LoanPenaltyRepository)
+	penaltyConfig := config.PenaltyConfig{Type: usecase.PenaltyTypePercentage, Rate: 0.1, MaxRate: 10}
+
+	uc := usecase.NewBatchUsecase(mockBatchRunRepo, mockLoanRepo, mockLoanInstallmentRepo, mockConsumerLimitRepo, mockLoanPenaltyRepo, penaltyConfig, time.Second*2)
+	runDate := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		setup   func()
+		want    usecase.BatchRunResponse
+		wantErr bool
+	}{
+		{
+			name: "create batch run error",
+			setup: func() {
+				mockBatchRunRepo.On("CreateBatchRun", mock.Anything, mock.Anything).Return(int64(0), errors.New("db error")).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name: "run date claimed by another run",
+			setup: func() {
+				mockBatchRunRepo.On("CreateBatchRun", mock.Anything, mock.Anything).Return(int64(0), repository.ErrBatchRunAlreadyClaimed).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name: "get active loans error marks the run failed",
+			setup: func() {
+				mockBatchRunRepo.On("CreateBatchRun", mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockLoanRepo.On("GetActiveLoans", mock.Anything).Return(nil, errors.New("db error")).Once()
+				mockBatchRunRepo.On("FinishBatchRun", mock.Anything, mock.MatchedBy(func(run repository.BatchRun) bool {
+					return run.ID == 1 && run.BatchStatus == "failed" && run.ErrorMessage == "db error"
+				})).Return(nil).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			setup: func() {
+				mockBatchRunRepo.On("CreateBatchRun", mock.Anything, mock.MatchedBy(func(run repository.BatchRun) bool {
+					return run.JobName == usecase.JobEndOfDay && run.BatchStatus == "running"
+				})).Return(int64(1), nil).Once()
+				mockLoanRepo.On("GetActiveLoans", mock.Anything).Return([]repository.Loan{
+					{ID: 1, ConsumerLimitID: 1, LoanStatus: "on_going"},
+					{ID: 2, ConsumerLimitID: 2, LoanStatus: "on_going"},
+					{ID: 3, ConsumerLimitID: 3, LoanStatus: "late", DaysPastDue: 4},
+					{ID: 4, ConsumerLimitID: 4, LoanStatus: "on_going"},
+					{ID: 5, ConsumerLimitID: 5, LoanStatus: "late", DaysPastDue: 2},
+					{ID: 6, ConsumerLimitID: 6, LoanStatus: "late", DaysPastDue: 3},
+				}, nil).Once()
+
+				// overdue since ten days, its installment accrues 0.1% a day
+				mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerLimitID: 1, LoanStatus: "on_going"}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, LoanID: 1, InstallmentNumber: 1, DueDate: runDate.AddDate(0, 0, -40), InstallmentStatus: "paid"},
+					{ID: 2, LoanID: 1, InstallmentNumber: 2, DueDate: runDate.AddDate(0, 0, -10), PrincipalAmount: money.New(900), InterestAmount: money.New(100), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return(nil, nil).Once()
+				mockLoanPenaltyRepo.On("UpsertLoanPenalty", mock.Anything, repository.LoanPenalty{
+					LoanID:            1,
+					InstallmentNumber: 2,
+					OverdueDays:       10,
+					PenaltyAmount:     money.New(10),
+					AccruedUntil:      runDate,
+				}, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateLoanDaysPastDue", mock.Anything, repository.UpdateLoanDaysPastDueRequest{
+					ID:          1,
+					FromStatus:  "on_going",
+					LoanStatus:  "late",
+					DaysPastDue: 10,
+				}, mock.Anything).Return(nil).Once()
+				mockBatchRunRepo.On("CreateBatchRunItem", mock.Anything, repository.BatchRunItem{
+					BatchRunID:         1,
+					LoanID:             1,
+					ItemStatus:         repository.BatchRunItemUpdated,
+					PreviousLoanStatus: "on_going",
+					LoanStatus:         "late",
+					DaysPastDue:        10,
+				}, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+				// legacy loan without schedule rows, first installment not due yet
+				mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(2), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(2)).Return(repository.Loan{ID: 2, ConsumerLimitID: 2, LoanAmount: money.New(3000), InterestRate: 10, LoanStatus: "on_going", CreatedAt: runDate.AddDate(0, 0, -10)}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(2)).Return([]repository.LoanInstallment{}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(2)).Return(repository.ConsumerLimit{ID: 2, Tenure: 3}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(2)).Return(nil, nil).Once()
+				mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+				// caught up with its installments, the penalty it paid stays as it is
+				mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(3), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(3)).Return(repository.Loan{ID: 3, ConsumerLimitID: 3, LoanStatus: "late", DaysPastDue: 4}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(3)).Return([]repository.LoanInstallment{
+					{ID: 3, LoanID: 3, InstallmentNumber: 1, DueDate: runDate.AddDate(0, 0, -5), InstallmentStatus: "paid"},
+					{ID: 4, LoanID: 3, InstallmentNumber: 2, DueDate: runDate.AddDate(0, 0, 25), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(3)).Return([]repository.LoanPenalty{
+					{ID: 1, LoanID: 3, InstallmentNumber: 1, OverdueDays: 4, PenaltyAmount: money.New(4), PaidPenaltyAmount: money.New(4), AccruedUntil: runDate.AddDate(0, 0, -1)},
+				}, nil).Once()
+				mockLoanRepo.On("UpdateLoanDaysPastDue", mock.Anything, repository.UpdateLoanDaysPastDueRequest{
+					ID:              3,
+					FromStatus:      "late",
+					FromDaysPastDue: 4,
+					LoanStatus:      "on_going",
+					DaysPastDue:     0,
+				}, mock.Anything).Return(nil).Once()
+				mockBatchRunRepo.On("CreateBatchRunItem", mock.Anything, repository.BatchRunItem{
+					BatchRunID:          1,
+					LoanID:              3,
+					ItemStatus:          repository.BatchRunItemUpdated,
+					PreviousLoanStatus:  "late",
+					LoanStatus:          "on_going",
+					PreviousDaysPastDue: 4,
+				}, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+				// repaid by a payment since the active loans were listed, it stays finished
+				mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(4), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(4)).Return(repository.Loan{ID: 4, ConsumerLimitID: 4, LoanStatus: "finish"}, nil).Once()
+				mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+				// its schedule cannot be read, it is logged as failed and the batch goes on
+				mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(5), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(5)).Return(repository.Loan{ID: 5, ConsumerLimitID: 5, LoanStatus: "late", DaysPastDue: 2}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(5)).Return(nil, errors.New("db error")).Once()
+				mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+				mockBatchRunRepo.On("CreateBatchRunItem", mock.Anything, repository.BatchRunItem{
+					BatchRunID:          1,
+					LoanID:              5,
+					ItemStatus:          repository.BatchRunItemFailed,
+					PreviousLoanStatus:  "late",
+					LoanStatus:          "late",
+					PreviousDaysPastDue: 2,
+					DaysPastDue:         2,
+					ErrorMessage:        "db error",
+				}, mock.Anything).Return(nil).Once()
+
+				// its days past due are already up to date, only its penalty accrues
+				mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(6), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(6)).Return(repository.Loan{ID: 6, ConsumerLimitID: 6, LoanStatus: "late", DaysPastDue: 3}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(6)).Return([]repository.LoanInstallment{
+					{ID: 5, LoanID: 6, InstallmentNumber: 1, DueDate: runDate.AddDate(0, 0, -3), PrincipalAmount: money.New(1000), InstallmentStatus: "partial", PaidPrincipalAmount: money.New(500)},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(6)).Return([]repository.LoanPenalty{
+					{ID: 2, LoanID: 6, InstallmentNumber: 1, OverdueDays: 2, PenaltyAmount: money.New(1), PaidPenaltyAmount: money.New(1), AccruedUntil: runDate.AddDate(0, 0, -1)},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("UpsertLoanPenalty", mock.Anything, repository.LoanPenalty{
+					ID:                2,
+					LoanID:            6,
+					InstallmentNumber: 1,
+					OverdueDays:       3,
+					PenaltyAmount:     money.New(2),
+					PaidPenaltyAmount: money.New(1),
+					AccruedUntil:      runDate,
+				}, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+				mockBatchRunRepo.On("FinishBatchRun", mock.Anything, repository.BatchRun{
+					ID:           1,
+					JobName:      usecase.JobEndOfDay,
+					RunDate:      runDate,
+					BatchStatus:  "success",
+					TotalLoans:   6,
+					UpdatedLoans: 2,
+					FailedLoans:  1,
+				}).Return(nil).Once()
+			},
+			want: usecase.BatchRunResponse{
+				ID:           1,
+				JobName:      usecase.JobEndOfDay,
+				RunDate:      "2024-03-20",
+				BatchStatus:  "success",
+				TotalLoans:   6,
+				UpdatedLoans: 2,
+				FailedLoans:  1,
+				Items: []usecase.BatchRunItemResponse{
+					{LoanID: 1, ItemStatus: "updated", PreviousLoanStatus: "on_going", LoanStatus: "late", DaysPastDue: 10},
+					{LoanID: 3, ItemStatus: "updated", PreviousLoanStatus: "late", LoanStatus: "on_going", PreviousDaysPastDue: 4},
+					{LoanID: 5, ItemStatus: "failed", PreviousLoanStatus: "late", LoanStatus: "late", PreviousDaysPastDue: 2, DaysPastDue: 2, ErrorMessage: "db error"},
+				},
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, err := uc.RunEndOfDay(context.Background(), runDate)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	mockBatchRunRepo.AssertExpectations(t)
+	mockLoanRepo.AssertExpectations(t)
+	mockLoanInstallmentRepo.AssertExpectations(t)
+	mockConsumerLimitRepo.AssertExpectations(t)
+	mockLoanPenaltyRepo.AssertExpectations(t)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
 )
 
@@ -23,27 +24,28 @@ type consumerUsecase struct {
 
 type (
 	GetConsumerResponse struct {
-		ID           int64     `json:"id"`
-		FullName     string    `json:"full_name"`
-		LegalName    string    `json:"legal_name"`
-		PlaceOfBirth string    `json:"place_of_birth"`
-		DOB          string    `json:"dob"`
-		Salary       float64   `json:"salary"`
-		NIK          string    `json:"nik"`
-		KTPImageURL  string    `json:"ktp_image_url"`
-		SelfieURL    string    `json:"selfie_url"`
-		CreatedAt    time.Time `json:"created_at"`
+		ID            int64       `json:"id"`
+		FullName      string      `json:"full_name"`
+		LegalName     string      `json:"legal_name"`
+		PlaceOfBirth  string      `json:"place_of_birth"`
+		DOB           string      `json:"dob"`
+		Salary        money.Money `json:"salary"`
+		CreditBalance money.Money `json:"credit_balance"`
+		NIK           string      `json:"nik"`
+		KTPImageURL   string      `json:"ktp_image_url"`
+		SelfieURL     string      `json:"selfie_url"`
+		CreatedAt     time.Time   `json:"created_at"`
 	}
 
 	ConsumerRequest struct {
-		FullName     string  `json:"full_name"`
-		LegalName    string  `json:"legal_name"`
-		PlaceOfBirth string  `json:"place_of_birth"`
-		DOB          string  `json:"dob"`
-		Salary       float64 `json:"salary"`
-		NIK          string  `json:"nik"`
-		KTPImageURL  string  `json:"ktp_image_url"`
-		SelfieURL    string  `json:"selfie_url"`
+		FullName     string      `json:"full_name"`
+		LegalName    string      `json:"legal_name"`
+		PlaceOfBirth string      `json:"place_of_birth"`
+		DOB          string      `json:"dob"`
+		Salary       money.Money `json:"salary"`
+		NIK          string      `json:"nik"`
+		KTPImageURL  string      `json:"ktp_image_url"`
+		SelfieURL    string      `json:"selfie_url"`
 	}
 
 	FetchConsumerRequest struct {
@@ -72,16 +74,17 @@ func (u *consumerUsecase) GetConsumerByID(ctx context.Context, id int64) (respon
 	}
 
 	response = GetConsumerResponse{
-		ID:           consumerData.ID,
-		FullName:     consumerData.FullName,
-		LegalName:    consumerData.LegalName,
-		PlaceOfBirth: consumerData.PlaceOfBirth,
-		DOB:          consumerData.DOB,
-		Salary:       consumerData.Salary,
-		NIK:          consumerData.NIK,
-		KTPImageURL:  consumerData.KTPImageURL,
-		SelfieURL:    consumerData.SelfieURL,
-		CreatedAt:    consumerData.CreatedAt,
+		ID:            consumerData.ID,
+		FullName:      consumerData.FullName,
+		LegalName:     consumerData.LegalName,
+		PlaceOfBirth:  consumerData.PlaceOfBirth,
+		DOB:           consumerData.DOB,
+		Salary:        consumerData.Salary,
+		CreditBalance: consumerData.CreditBalance,
+		NIK:           consumerData.NIK,
+		KTPImageURL:   consumerData.KTPImageURL,
+		SelfieURL:     consumerData.SelfieURL,
+		CreatedAt:     consumerData.CreatedAt,
 	}
 
 	return response, nil
@@ -137,16 +140,17 @@ func (u *consumerUsecase) FetchConsumer(ctx context.Context, req FetchConsumerRe
 
 	for _, consumer := range consumerData {
 		response = append(response, GetConsumerResponse{
-			ID:           consumer.ID,
-			FullName:     consumer.FullName,
-			LegalName:    consumer.LegalName,
-			PlaceOfBirth: consumer.PlaceOfBirth,
-			DOB:          consumer.DOB,
-			Salary:       consumer.Salary,
-			NIK:          consumer.NIK,
-			KTPImageURL:  consumer.KTPImageURL,
-			SelfieURL:    consumer.SelfieURL,
-			CreatedAt:    consumer.CreatedAt,
+			ID:            consumer.ID,
+			FullName:      consumer.FullName,
+			LegalName:     consumer.LegalName,
+			PlaceOfBirth:  consumer.PlaceOfBirth,
+			DOB:           consumer.DOB,
+			Salary:        consumer.Salary,
+			CreditBalance: consumer.CreditBalance,
+			NIK:           consumer.NIK,
+			KTPImageURL:   consumer.KTPImageURL,
+			SelfieURL:     consumer.SelfieURL,
+			CreatedAt:     consumer.CreatedAt,
 		})
 	}
 
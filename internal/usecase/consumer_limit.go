@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 )
 
 type ConsumerLimitUsecase interface {
@@ -17,30 +18,33 @@ type ConsumerLimitUsecase interface {
 
 type consumerLimitUsecase struct {
 	consumerLimitRepo repository.ConsumerLimitRepository
+	loanProductRepo   repository.LoanProductRepository
 	ctxTimeout        time.Duration
 }
 
 type (
 	GetConsumerLimitResponse struct {
-		ID          int64   `json:"id"`
-		ConsumerID  int64   `json:"consumer_id"`
-		Tenure      int16   `json:"tenure"`
-		LimitAmount float64 `json:"limit_amount"`
+		ID          int64       `json:"id"`
+		ConsumerID  int64       `json:"consumer_id"`
+		Tenure      int16       `json:"tenure"`
+		LimitAmount money.Money `json:"limit_amount"`
 	}
 
 	ConsumerLimitRequest struct {
-		ConsumerID  int64   `json:"consumer_id"`
-		Tenure      int16   `json:"tenure"`
-		LimitAmount float64 `json:"limit_amount"`
+		ConsumerID  int64       `json:"consumer_id"`
+		Tenure      int16       `json:"tenure"`
+		LimitAmount money.Money `json:"limit_amount"`
 	}
 )
 
 func NewConsumerLimitUsecase(
 	consumerLimitRepo repository.ConsumerLimitRepository,
+	loanProductRepo repository.LoanProductRepository,
 	timeout time.Duration,
 ) ConsumerLimitUsecase {
 	return &consumerLimitUsecase{
 		consumerLimitRepo: consumerLimitRepo,
+		loanProductRepo:   loanProductRepo,
 		ctxTimeout:        timeout,
 	}
 }
@@ -68,8 +72,20 @@ func (uc *consumerLimitUsecase) CreateOrUpdateConsumerLimit(ctx context.Context,
 	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
 	defer cancel()
 
-	_, ok := repository.ValidConsumerLimitTenure[request.Tenure]
-	if !ok {
+	// limits are only granted for the tenures a loan product offers
+	tenures, err := uc.loanProductRepo.GetOfferedTenures(ctx)
+	if err != nil {
+		return err
+	}
+
+	offered := false
+	for _, tenure := range tenures {
+		if tenure == request.Tenure {
+			offered = true
+			break
+		}
+	}
+	if !offered {
 		return errors.New("invalid tenure")
 	}
 
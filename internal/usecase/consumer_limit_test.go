@@ -9,20 +9,21 @@ import (
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 func TestGetLimitByTenureAndConsumerID(t *testing.T) {
 	mockRepo := new(mocks.ConsumerLimitRepository)
-	uc := usecase.NewConsumerLimitUsecase(mockRepo, time.Second*2)
+	uc := usecase.NewConsumerLimitUsecase(mockRepo, new(mocks.LoanProductRepository), time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		mockRepo.On("GetLimitByTenureAndConsumerID", mock.Anything, int16(2), int64(1)).Return(repository.ConsumerLimit{
 			ID:          1,
 			ConsumerID:  1,
 			Tenure:      2,
-			LimitAmount: 1000.0,
+			LimitAmount: money.New(1000),
 		}, nil).Once()
 
 		ctx := context.Background()
@@ -32,7 +33,7 @@ func TestGetLimitByTenureAndConsumerID(t *testing.T) {
 		assert.Equal(t, int64(1), response.ID)
 		assert.Equal(t, int64(1), response.ConsumerID)
 		assert.Equal(t, int16(2), response.Tenure)
-		assert.Equal(t, 1000.0, response.LimitAmount)
+		assert.Equal(t, money.New(1000), response.LimitAmount)
 
 		mockRepo.AssertExpectations(t)
 	})
@@ -52,7 +53,10 @@ func TestGetLimitByTenureAndConsumerID(t *testing.T) {
 
 func TestCreateOrUpdateConsumerLimit(t *testing.T) {
 	mockRepo := new(mocks.ConsumerLimitRepository)
-	uc := usecase.NewConsumerLimitUsecase(mockRepo, time.Second*2)
+	mockLoanProductRepo := new(mocks.LoanProductRepository)
+	uc := usecase.NewConsumerLimitUsecase(mockRepo, mockLoanProductRepo, time.Second*2)
+
+	mockLoanProductRepo.On("GetOfferedTenures", mock.Anything).Return([]int16{2, 6, 12}, nil)
 
 	t.Run("success create", func(t *testing.T) {
 		mockRepo.On("GetLimitByTenureAndConsumerID", mock.Anything, int16(2), int64(1)).Return(repository.ConsumerLimit{}, nil).Once()
@@ -62,7 +66,7 @@ func TestCreateOrUpdateConsumerLimit(t *testing.T) {
 		err := uc.CreateOrUpdateConsumerLimit(ctx, usecase.ConsumerLimitRequest{
 			ConsumerID:  1,
 			Tenure:      2,
-			LimitAmount: 1000.0,
+			LimitAmount: money.New(1000),
 		})
 
 		assert.NoError(t, err)
@@ -74,7 +78,7 @@ func TestCreateOrUpdateConsumerLimit(t *testing.T) {
 			ID:          1,
 			ConsumerID:  1,
 			Tenure:      2,
-			LimitAmount: 500.0,
+			LimitAmount: money.New(500),
 		}, nil).Once()
 		mockRepo.On("UpdateConsumerLimit", mock.Anything, mock.Anything).Return(nil).Once()
 
@@ -82,7 +86,7 @@ func TestCreateOrUpdateConsumerLimit(t *testing.T) {
 		err := uc.CreateOrUpdateConsumerLimit(ctx, usecase.ConsumerLimitRequest{
 			ConsumerID:  1,
 			Tenure:      2,
-			LimitAmount: 1000.0,
+			LimitAmount: money.New(1000),
 		})
 
 		assert.NoError(t, err)
@@ -94,13 +98,30 @@ func TestCreateOrUpdateConsumerLimit(t *testing.T) {
 		err := uc.CreateOrUpdateConsumerLimit(ctx, usecase.ConsumerLimitRequest{
 			ConsumerID:  1,
 			Tenure:      99,
-			LimitAmount: 1000.0,
+			LimitAmount: money.New(1000),
 		})
 
 		assert.Error(t, err)
 		assert.Equal(t, "invalid tenure", err.Error())
 	})
 
+	t.Run("repository error on GetOfferedTenures", func(t *testing.T) {
+		mockLoanProductRepo := new(mocks.LoanProductRepository)
+		uc := usecase.NewConsumerLimitUsecase(mockRepo, mockLoanProductRepo, time.Second*2)
+		mockLoanProductRepo.On("GetOfferedTenures", mock.Anything).Return(nil, errors.New("some error")).Once()
+
+		ctx := context.Background()
+		err := uc.CreateOrUpdateConsumerLimit(ctx, usecase.ConsumerLimitRequest{
+			ConsumerID:  1,
+			Tenure:      2,
+			LimitAmount: money.New(1000),
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, "some error", err.Error())
+		mockLoanProductRepo.AssertExpectations(t)
+	})
+
 	t.Run("repository error on GetLimitByTenureAndConsumerID", func(t *testing.T) {
 		mockRepo.On("GetLimitByTenureAndConsumerID", mock.Anything, int16(2), int64(1)).Return(repository.ConsumerLimit{}, errors.New("some error")).Once()
 
@@ -108,7 +129,7 @@ func TestCreateOrUpdateConsumerLimit(t *testing.T) {
 		err := uc.CreateOrUpdateConsumerLimit(ctx, usecase.ConsumerLimitRequest{
 			ConsumerID:  1,
 			Tenure:      2,
-			LimitAmount: 1000.0,
+			LimitAmount: money.New(1000),
 		})
 
 		assert.Error(t, err)
@@ -124,7 +145,7 @@ func TestCreateOrUpdateConsumerLimit(t *testing.T) {
 		err := uc.CreateOrUpdateConsumerLimit(ctx, usecase.ConsumerLimitRequest{
 			ConsumerID:  1,
 			Tenure:      2,
-			LimitAmount: 1000.0,
+			LimitAmount: money.New(1000),
 		})
 
 		assert.Error(t, err)
@@ -137,7 +158,7 @@ func TestCreateOrUpdateConsumerLimit(t *testing.T) {
 			ID:          1,
 			ConsumerID:  1,
 			Tenure:      2,
-			LimitAmount: 500.0,
+			LimitAmount: money.New(500),
 		}, nil).Once()
 		mockRepo.On("UpdateConsumerLimit", mock.Anything, mock.Anything).Return(errors.New("some error")).Once()
 
@@ -145,7 +166,7 @@ func TestCreateOrUpdateConsumerLimit(t *testing.T) {
 		err := uc.CreateOrUpdateConsumerLimit(ctx, usecase.ConsumerLimitRequest{
 			ConsumerID:  1,
 			Tenure:      2,
-			LimitAmount: 1000.0,
+			LimitAmount: money.New(1000),
 		})
 
 		assert.Error(t, err)
@@ -156,7 +177,7 @@ func TestCreateOrUpdateConsumerLimit(t *testing.T) {
 
 func TestDeleteConsumerLimit(t *testing.T) {
 	mockRepo := new(mocks.ConsumerLimitRepository)
-	uc := usecase.NewConsumerLimitUsecase(mockRepo, time.Second*2)
+	uc := usecase.NewConsumerLimitUsecase(mockRepo, new(mocks.LoanProductRepository), time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		mockRepo.On("DeleteConsumerLimit", mock.Anything, int64(1)).Return(nil).Once()
@@ -182,7 +203,7 @@ func TestDeleteConsumerLimit(t *testing.T) {
 
 func TestGetConsumerLimitByConsumerID(t *testing.T) {
 	mockRepo := new(mocks.ConsumerLimitRepository)
-	uc := usecase.NewConsumerLimitUsecase(mockRepo, time.Second*2)
+	uc := usecase.NewConsumerLimitUsecase(mockRepo, new(mocks.LoanProductRepository), time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		mockRepo.On("GetConsumerLimitByConsumerID", mock.Anything, int64(1)).Return([]repository.ConsumerLimit{
@@ -190,13 +211,13 @@ func TestGetConsumerLimitByConsumerID(t *testing.T) {
 				ID:          1,
 				ConsumerID:  1,
 				Tenure:      2,
-				LimitAmount: 1000.0,
+				LimitAmount: money.New(1000),
 			},
 			{
 				ID:          2,
 				ConsumerID:  1,
 				Tenure:      3,
-				LimitAmount: 2000.0,
+				LimitAmount: money.New(2000),
 			},
 		}, nil).Once()
 
@@ -208,11 +229,11 @@ func TestGetConsumerLimitByConsumerID(t *testing.T) {
 		assert.Equal(t, int64(1), response[0].ID)
 		assert.Equal(t, int64(1), response[0].ConsumerID)
 		assert.Equal(t, int16(2), response[0].Tenure)
-		assert.Equal(t, 1000.0, response[0].LimitAmount)
+		assert.Equal(t, money.New(1000), response[0].LimitAmount)
 		assert.Equal(t, int64(2), response[1].ID)
 		assert.Equal(t, int64(1), response[1].ConsumerID)
 		assert.Equal(t, int16(3), response[1].Tenure)
-		assert.Equal(t, 2000.0, response[1].LimitAmount)
+		assert.Equal(t, money.New(2000), response[1].LimitAmount)
 
 		mockRepo.AssertExpectations(t)
 	})
@@ -9,6 +9,7 @@ import (
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	uc "github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -24,7 +25,7 @@ func TestGetConsumerByID(t *testing.T) {
 			LegalName:    "Johnathan Doe",
 			PlaceOfBirth: "New York",
 			DOB:          "1990-01-01",
-			Salary:       50000,
+			Salary:       money.New(50000),
 			NIK:          "1234567890",
 			KTPImageURL:  "http://example.com/ktp.jpg",
 			SelfieURL:    "http://example.com/selfie.jpg",
@@ -72,7 +73,7 @@ func TestCreateConsumer(t *testing.T) {
 			LegalName:    "Jane Doe",
 			PlaceOfBirth: "Los Angeles",
 			DOB:          "1992-02-02",
-			Salary:       60000,
+			Salary:       money.New(60000),
 			NIK:          "0987654321",
 			KTPImageURL:  "http://example.com/ktp.jpg",
 			SelfieURL:    "http://example.com/selfie.jpg",
@@ -101,7 +102,7 @@ func TestFetchConsumer(t *testing.T) {
 				LegalName:    "Johnathan Doe",
 				PlaceOfBirth: "New York",
 				DOB:          "1990-01-01",
-				Salary:       50000,
+				Salary:       money.New(50000),
 				NIK:          "1234567890",
 				KTPImageURL:  "http://example.com/ktp.jpg",
 				SelfieURL:    "http://example.com/selfie.jpg",
@@ -113,7 +114,7 @@ func TestFetchConsumer(t *testing.T) {
 				LegalName:    "Jane Doe",
 				PlaceOfBirth: "Los Angeles",
 				DOB:          "1992-02-02",
-				Salary:       60000,
+				Salary:       money.New(60000),
 				NIK:          "0987654321",
 				KTPImageURL:  "http://example.com/ktp.jpg",
 				SelfieURL:    "http://example.com/selfie.jpg",
@@ -156,7 +157,7 @@ func TestUpdateConsumer(t *testing.T) {
 			LegalName:    "Jane Doe",
 			PlaceOfBirth: "Los Angeles",
 			DOB:          "1992-02-02",
-			Salary:       60000,
+			Salary:       money.New(60000),
 			NIK:          "0987654321",
 			KTPImageURL:  "http://example.com/ktp.jpg",
 			SelfieURL:    "http://example.com/selfie.jpg",
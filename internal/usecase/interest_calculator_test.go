@@ -0,0 +1,105 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInterestCalculator(t *testing.T) {
+	t.Run("default to flat", func(t *testing.T) {
+		calculator, err := usecase.NewInterestCalculator("")
+		assert.NoError(t, err)
+		assert.NotNil(t, calculator)
+	})
+
+	t.Run("invalid method", func(t *testing.T) {
+		calculator, err := usecase.NewInterestCalculator("invalid")
+		assert.Error(t, err)
+		assert.Nil(t, calculator)
+	})
+}
+
+func TestInterestCalculatorCalculate(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		loanAmount    money.Money
+		interestRate  float64
+		tenure        int16
+		wantInterest  money.Money
+		wantFirst     usecase.InstallmentAmount
+		wantLast      usecase.InstallmentAmount
+		wantEqualPays bool
+	}{
+		{
+			name:         "flat charges the rate once over the loan",
+			method:       usecase.InterestMethodFlat,
+			loanAmount:   money.New(1000000),
+			interestRate: 10,
+			tenure:       3,
+			wantInterest: money.New(100000),
+			wantFirst:    usecase.InstallmentAmount{Principal: money.New(333333), Interest: money.New(33333)},
+			wantLast:     usecase.InstallmentAmount{Principal: money.New(333334), Interest: money.New(33334)},
+		},
+		{
+			name:         "flat monthly charges the rate every month",
+			method:       usecase.InterestMethodFlatMonthly,
+			loanAmount:   money.New(1200000),
+			interestRate: 2,
+			tenure:       6,
+			wantInterest: money.New(144000),
+			wantFirst:    usecase.InstallmentAmount{Principal: money.New(200000), Interest: money.New(24000)},
+			wantLast:     usecase.InstallmentAmount{Principal: money.New(200000), Interest: money.New(24000)},
+		},
+		{
+			name:         "declining balance charges interest on the outstanding principal",
+			method:       usecase.InterestMethodDecliningBalance,
+			loanAmount:   money.New(1200000),
+			interestRate: 2,
+			tenure:       6,
+			wantInterest: money.New(84000),
+			wantFirst:    usecase.InstallmentAmount{Principal: money.New(200000), Interest: money.New(24000)},
+			wantLast:     usecase.InstallmentAmount{Principal: money.New(200000), Interest: money.New(4000)},
+		},
+		{
+			name:          "annuity keeps the installment constant",
+			method:        usecase.InterestMethodAnnuity,
+			loanAmount:    money.New(1200000),
+			interestRate:  2,
+			tenure:        6,
+			wantInterest:  money.New(85386),
+			wantFirst:     usecase.InstallmentAmount{Principal: money.New(190231), Interest: money.New(24000)},
+			wantLast:      usecase.InstallmentAmount{Principal: money.New(210030), Interest: money.New(4201)},
+			wantEqualPays: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator, err := usecase.NewInterestCalculator(tt.method)
+			assert.NoError(t, err)
+
+			amounts := calculator.Calculate(tt.loanAmount, tt.interestRate, tt.tenure)
+			assert.Len(t, amounts, int(tt.tenure))
+			assert.Equal(t, tt.wantFirst, amounts[0])
+			assert.Equal(t, tt.wantLast, amounts[len(amounts)-1])
+			assert.Equal(t, tt.wantInterest, usecase.TotalInterest(amounts))
+
+			var totalPrincipal money.Money
+			for _, amount := range amounts {
+				totalPrincipal = totalPrincipal.Add(amount.Principal)
+			}
+			assert.Equal(t, tt.loanAmount, totalPrincipal)
+
+			if tt.wantEqualPays {
+				payment := amounts[0].Principal.Add(amounts[0].Interest)
+				for _, amount := range amounts[:len(amounts)-1] {
+					assert.Equal(t, payment, amount.Principal.Add(amount.Interest))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+// The chart of accounts, seeded by the ledger_accounts migration.
+const (
+	LedgerAccountCash                = "1100"
+	LedgerAccountLoanReceivable      = "1200"
+	LedgerAccountMerchantPayable     = "2100"
+	LedgerAccountConsumerCredit      = "2200"
+	LedgerAccountInsurancePayable    = "2300"
+	LedgerAccountInterestIncome      = "4100"
+	LedgerAccountPenaltyIncome       = "4200"
+	LedgerAccountSettlementFeeIncome = "4300"
+	LedgerAccountAdminFeeIncome      = "4400"
+)
+
+const (
+	JournalReferenceLoan             = "loan"
+	JournalReferenceLoanDisbursement = "loan_disbursement"
+	JournalReferenceLoanRelease      = "loan_release"
+	JournalReferenceLoanRestructure  = "loan_restructure"
+	JournalReferenceTransaction      = "transaction"
+)
+
+var (
+	// allocationLedgerAccount is the account credited with each component of a payment.
+	allocationLedgerAccount = map[string]string{
+		AllocationComponentPenalty:       LedgerAccountPenaltyIncome,
+		AllocationComponentInterest:      LedgerAccountInterestIncome,
+		AllocationComponentPrincipal:     LedgerAccountLoanReceivable,
+		AllocationComponentSettlementFee: LedgerAccountSettlementFeeIncome,
+		AllocationComponentCredit:        LedgerAccountConsumerCredit,
+	}
+
+	// ledgerAccountDebitNormal lists the account types whose balance is debit minus credit,
+	// the balance of the others is credit minus debit.
+	ledgerAccountDebitNormal = map[string]bool{
+		"asset":   true,
+		"expense": true,
+	}
+)
+
+type LedgerUsecase interface {
+	GetTrialBalance(ctx context.Context, req TrialBalanceRequest) (response TrialBalanceResponse, err error)
+}
+
+type ledgerUsecase struct {
+	ledgerRepo repository.LedgerRepository
+	ctxTimeout time.Duration
+}
+
+type (
+	// TrialBalanceRequest asks for the trial balance at the end of AsOf, a date formatted as
+	// YYYY-MM-DD. It is the trial balance as of now when AsOf is empty.
+	TrialBalanceRequest struct {
+		AsOf string `json:"as_of" query:"as_of"`
+	}
+
+	TrialBalanceAccountResponse struct {
+		AccountCode  string      `json:"account_code"`
+		AccountName  string      `json:"account_name"`
+		AccountType  string      `json:"account_type"`
+		DebitAmount  money.Money `json:"debit_amount"`
+		CreditAmount money.Money `json:"credit_amount"`
+		Balance      money.Money `json:"balance"`
+	}
+
+	TrialBalanceResponse struct {
+		AsOf        string                        `json:"as_of"`
+		Accounts    []TrialBalanceAccountResponse `json:"accounts"`
+		TotalDebit  money.Money                   `json:"total_debit"`
+		TotalCredit money.Money                   `json:"total_credit"`
+		Balanced    bool                          `json:"balanced"`
+	}
+)
+
+func NewLedgerUsecase(
+	ledgerRepo repository.LedgerRepository,
+	timeout time.Duration,
+) LedgerUsecase {
+	return &ledgerUsecase{
+		ledgerRepo: ledgerRepo,
+		ctxTimeout: timeout,
+	}
+}
+
+func (uc *ledgerUsecase) GetTrialBalance(ctx context.Context, req TrialBalanceRequest) (response TrialBalanceResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	asOf := time.Now()
+	if req.AsOf != "" {
+		date, err := time.ParseInLocation("2006-01-02", req.AsOf, time.Local)
+		if err != nil {
+			return response, errors.New("as_of must be formatted as YYYY-MM-DD")
+		}
+		// the whole day is included
+		asOf = date.AddDate(0, 0, 1)
+	}
+
+	accounts, err := uc.ledgerRepo.GetTrialBalance(ctx, asOf)
+	if err != nil {
+		return response, err
+	}
+
+	response.AsOf = asOf.Format("2006-01-02 15:04:05")
+	response.Accounts = make([]TrialBalanceAccountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		balance := account.CreditAmount.Sub(account.DebitAmount)
+		if ledgerAccountDebitNormal[account.AccountType] {
+			balance = account.DebitAmount.Sub(account.CreditAmount)
+		}
+
+		response.Accounts = append(response.Accounts, TrialBalanceAccountResponse{
+			AccountCode:  account.AccountCode,
+			AccountName:  account.AccountName,
+			AccountType:  account.AccountType,
+			DebitAmount:  account.DebitAmount,
+			CreditAmount: account.CreditAmount,
+			Balance:      balance,
+		})
+		response.TotalDebit = response.TotalDebit.Add(account.DebitAmount)
+		response.TotalCredit = response.TotalCredit.Add(account.CreditAmount)
+	}
+	response.Balanced = response.TotalDebit == response.TotalCredit
+
+	return response, nil
+}
+
+// postJournalEntry posts an entry within tx, along with the change it records. Lines of the
+// same account and side are merged and zero lines left out, an entry that does not balance
+// is refused so the change is rolled back.
+func postJournalEntry(ctx context.Context, ledgerRepo repository.LedgerRepository, entry repository.JournalEntry, tx *sql.Tx) error {
+	var (
+		lines       []repository.JournalLine
+		totalDebit  money.Money
+		totalCredit money.Money
+	)
+
+	for _, line := range entry.Lines {
+		totalDebit = totalDebit.Add(line.DebitAmount)
+		totalCredit = totalCredit.Add(line.CreditAmount)
+
+		merged := false
+		for i := range lines {
+			if lines[i].AccountCode == line.AccountCode && lines[i].DebitAmount.IsZero() == line.DebitAmount.IsZero() {
+				lines[i].DebitAmount = lines[i].DebitAmount.Add(line.DebitAmount)
+				lines[i].CreditAmount = lines[i].CreditAmount.Add(line.CreditAmount)
+				merged = true
+				break
+			}
+		}
+		if !merged && (line.DebitAmount.IsPositive() || line.CreditAmount.IsPositive()) {
+			lines = append(lines, line)
+		}
+	}
+
+	if totalDebit != totalCredit {
+		return fmt.Errorf("journal entry of %s %d is not balanced: debit %s, credit %s", entry.ReferenceType, entry.ReferenceID, totalDebit, totalCredit)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	entry.Lines = lines
+	_, err := ledgerRepo.CreateJournalEntry(ctx, entry, tx)
+	return err
+}
+
+// debitLine debits an account, a negative amount is credited instead, as when a payment is
+// reversed.
+func debitLine(accountCode string, amount money.Money) repository.JournalLine {
+	if amount.IsNegative() {
+		return repository.JournalLine{AccountCode: accountCode, CreditAmount: money.Zero.Sub(amount)}
+	}
+
+	return repository.JournalLine{AccountCode: accountCode, DebitAmount: amount}
+}
+
+// creditLine credits an account, a negative amount is debited instead.
+func creditLine(accountCode string, amount money.Money) repository.JournalLine {
+	if amount.IsNegative() {
+		return repository.JournalLine{AccountCode: accountCode, DebitAmount: money.Zero.Sub(amount)}
+	}
+
+	return repository.JournalLine{AccountCode: accountCode, CreditAmount: amount}
+}
+
+// loanBookingLines records the amount lent as owed by the consumer. Until disbursed, the
+// asset financed is owed to the merchant, while financed fees are earned for the admin fee
+// and owed to the insurer for the premium.
+func loanBookingLines(loan repository.Loan) []repository.JournalLine {
+	lines := []repository.JournalLine{
+		debitLine(LedgerAccountLoanReceivable, loan.LoanAmount),
+		creditLine(LedgerAccountMerchantPayable, loan.LoanAmount.Sub(financedFeeAmount(loan))),
+	}
+
+	if loan.FeePayment == FeePaymentFinanced {
+		lines = append(lines,
+			creditLine(LedgerAccountAdminFeeIncome, loan.AdminFeeAmount),
+			creditLine(LedgerAccountInsurancePayable, loan.InsuranceAmount),
+		)
+	}
+
+	return lines
+}
+
+// loanReleaseLines reverses the booking of a loan that is rejected or cancelled. Once the
+// loan was disbursed the merchant payable is left in debit, by the amount the merchant has
+// to refund.
+func loanReleaseLines(loan repository.Loan) []repository.JournalLine {
+	lines := []repository.JournalLine{
+		debitLine(LedgerAccountMerchantPayable, loan.LoanAmount.Sub(financedFeeAmount(loan))),
+	}
+
+	if loan.FeePayment == FeePaymentFinanced {
+		lines = append(lines,
+			debitLine(LedgerAccountAdminFeeIncome, loan.AdminFeeAmount),
+			debitLine(LedgerAccountInsurancePayable, loan.InsuranceAmount),
+		)
+	}
+
+	return append(lines, creditLine(LedgerAccountLoanReceivable, loan.LoanAmount))
+}
+
+// paymentJournalEntry records the cash received by a transaction against the accounts of
+// the components it was allocated to. The amounts of a reversal are negative and so post
+// the opposite entry.
+func paymentJournalEntry(transactionID int64, description string, amount money.Money, allocations []PaymentAllocation, postedAt time.Time) repository.JournalEntry {
+	entry := repository.JournalEntry{
+		ReferenceType: JournalReferenceTransaction,
+		ReferenceID:   transactionID,
+		Description:   description,
+		PostedAt:      postedAt,
+		Lines:         []repository.JournalLine{debitLine(LedgerAccountCash, amount)},
+	}
+
+	for _, allocation := range allocations {
+		entry.Lines = append(entry.Lines, creditLine(allocationLedgerAccount[allocation.Component], allocation.Amount))
+	}
+
+	return entry
+}
@@ -0,0 +1,82 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTrialBalance(t *testing.T) {
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	uc := usecase.NewLedgerUsecase(mockLedgerRepo, time.Second*2)
+
+	tests := []struct {
+		name    string
+		req     usecase.TrialBalanceRequest
+		setup   func()
+		want    usecase.TrialBalanceResponse
+		wantErr string
+	}{
+		{
+			name:    "invalid as_of",
+			req:     usecase.TrialBalanceRequest{AsOf: "31-01-2024"},
+			setup:   func() {},
+			wantErr: "as_of must be formatted as YYYY-MM-DD",
+		},
+		{
+			name: "balances by the normal side of each account",
+			req:  usecase.TrialBalanceRequest{AsOf: "2024-01-31"},
+			setup: func() {
+				mockLedgerRepo.On("GetTrialBalance", mock.Anything, time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)).Return([]repository.TrialBalanceAccount{
+					{AccountCode: "1100", AccountName: "Cash", AccountType: "asset", DebitAmount: money.New(200), CreditAmount: money.New(1000)},
+					{AccountCode: "1200", AccountName: "Loan Receivable", AccountType: "asset", DebitAmount: money.New(1000), CreditAmount: money.New(150)},
+					{AccountCode: "2100", AccountName: "Merchant Payable", AccountType: "liability", DebitAmount: money.New(1000), CreditAmount: money.New(1000)},
+					{AccountCode: "4100", AccountName: "Interest Income", AccountType: "income", CreditAmount: money.New(50)},
+				}, nil).Once()
+			},
+			want: usecase.TrialBalanceResponse{
+				AsOf: "2024-02-01 00:00:00",
+				Accounts: []usecase.TrialBalanceAccountResponse{
+					{AccountCode: "1100", AccountName: "Cash", AccountType: "asset", DebitAmount: money.New(200), CreditAmount: money.New(1000), Balance: money.New(-800)},
+					{AccountCode: "1200", AccountName: "Loan Receivable", AccountType: "asset", DebitAmount: money.New(1000), CreditAmount: money.New(150), Balance: money.New(850)},
+					{AccountCode: "2100", AccountName: "Merchant Payable", AccountType: "liability", DebitAmount: money.New(1000), CreditAmount: money.New(1000), Balance: money.Zero},
+					{AccountCode: "4100", AccountName: "Interest Income", AccountType: "income", CreditAmount: money.New(50), Balance: money.New(50)},
+				},
+				TotalDebit:  money.New(2200),
+				TotalCredit: money.New(2200),
+				Balanced:    true,
+			},
+		},
+		{
+			name: "repository error",
+			req:  usecase.TrialBalanceRequest{AsOf: "2024-01-31"},
+			setup: func() {
+				mockLedgerRepo.On("GetTrialBalance", mock.Anything, mock.Anything).Return(nil, errors.New("db down")).Once()
+			},
+			wantErr: "db down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, err := uc.GetTrialBalance(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	mockLedgerRepo.AssertExpectations(t)
+}
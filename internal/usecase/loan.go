@@ -2,71 +2,182 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
 )
 
+// ErrLoanNotCancellable is returned when a loan is cancelled after it was disbursed, past the
+// cooling-off period or once something was paid on it.
+var ErrLoanNotCancellable = errors.New("loan cannot be cancelled")
+
+// ErrLoanHasTransactions is returned when a loan with transactions is deleted, they would be
+// left pointing at a hidden loan.
+var ErrLoanHasTransactions = errors.New("loan has transactions")
+
+// ErrLoanNotDeletable is returned when a loan that is neither rejected nor cancelled is
+// deleted, it is still owed or still holds the consumer limit.
+var ErrLoanNotDeletable = errors.New("loan cannot be deleted")
+
 type LoanUsecase interface {
 	CreateLoan(ctx context.Context, req CreateLoanRequest) (response LoanResponse, err error)
+	SimulateLoan(ctx context.Context, req SimulateLoanRequest) (response LoanSimulationResponse, err error)
 	GetLoanByID(ctx context.Context, loanID int64) (response LoanResponse, err error)
 	GetLoanByConsumerID(ctx context.Context, consumerID int64) (response []LoanResponse, err error)
+	FetchLoans(ctx context.Context, req FetchLoansRequest) (response LoanListResponse, err error)
 	DeleteLoanByID(ctx context.Context, loanID int64) (err error)
+	GetLoanSchedule(ctx context.Context, loanID int64) (response LoanScheduleResponse, err error)
+	ApproveLoan(ctx context.Context, loanID int64) (err error)
+	RejectLoan(ctx context.Context, req LoanStatusRequest) (err error)
+	DisburseLoan(ctx context.Context, loanID int64) (err error)
+	CancelLoan(ctx context.Context, req LoanStatusRequest) (err error)
+	RestructureLoan(ctx context.Context, req RestructureLoanRequest) (response LoanRestructureResponse, err error)
+	GetLoanRestructures(ctx context.Context, loanID int64) (response []LoanRestructureResponse, err error)
 }
 
 type loanUsecase struct {
-	loanRepo          repository.LoanRepository
-	consumerLimitRepo repository.ConsumerLimitRepository
-	consumerRepo      repository.ConsumerRepository
-	merchantRepo      repository.MerchantRepository
-	ctxTimeout        time.Duration
+	loanRepo            repository.LoanRepository
+	loanInstallmentRepo repository.LoanInstallmentRepository
+	loanRestructureRepo repository.LoanRestructureRepository
+	consumerLimitRepo   repository.ConsumerLimitRepository
+	consumerRepo        repository.ConsumerRepository
+	merchantRepo        repository.MerchantRepository
+	loanProductRepo     repository.LoanProductRepository
+	ledgerRepo          repository.LedgerRepository
+	notificationRepo    repository.MerchantNotificationRepository
+	loanConfig          config.LoanConfig
+	ctxTimeout          time.Duration
 }
 
+// How the fees of a loan are paid: upfront along with the down payment, or financed and
+// repaid with the installments.
+const (
+	FeePaymentUpfront  = "upfront"
+	FeePaymentFinanced = "financed"
+)
+
 type (
+	// CreateLoanRequest applies for a loan of a loan product, the interest rate, the interest
+	// method and the fees being the ones of the product. The asset price less the down
+	// payment is financed, along with the fees when FeePayment is financed. Insured adds the
+	// credit insurance of the product.
 	CreateLoanRequest struct {
-		ConsumerID   int64   `json:"consumer_id"`
-		MerchantID   int64   `json:"merchant_id"`
-		Tenure       int16   `json:"tenure"`
-		LoanAmount   float64 `json:"loan_amount"`
-		InterestRate float64 `json:"interest_rate"`
-		AssetName    string  `json:"asset_name"`
+		ConsumerID        int64       `json:"consumer_id"`
+		MerchantID        int64       `json:"merchant_id"`
+		ProductCode       string      `json:"product_code"`
+		Tenure            int16       `json:"tenure"`
+		AssetPrice        money.Money `json:"asset_price"`
+		DownPaymentAmount money.Money `json:"down_payment_amount"`
+		FeePayment        string      `json:"fee_payment"`
+		Insured           bool        `json:"insured"`
+		AssetName         string      `json:"asset_name"`
+	}
+
+	// loanQuote is the price of a loan application: the amount lent with its financed fees,
+	// the insurance premium and the installments on the terms of the product.
+	loanQuote struct {
+		Terms           loanProductTerms
+		LoanAmount      money.Money
+		InsuranceAmount money.Money
+		InterestAmount  money.Money
+		Installments    []InstallmentAmount
+	}
+
+	LoanStatusRequest struct {
+		LoanID int64  `json:"-"`
+		Reason string `json:"reason"`
 	}
 
 	LoanResponse struct {
-		ID              int64   `json:"id"`
-		ConsumerID      int64   `json:"consumer_id"`
-		MerchantID      int64   `json:"merchant_id"`
-		ConsumerLimitID int64   `json:"consumer_limit_id"`
-		LoanAmount      float64 `json:"loan_amount"`
-		ContractNumber  string  `json:"contract_number"`
-		InterestRate    float64 `json:"interest_rate"`
-		InterestAmount  float64 `json:"interest_amount"`
-		LoanStatus      string  `json:"loan_status"`
-		DueDate         string  `json:"due_date"`
-		Installment     int32   `json:"installment"`
-		AssetName       string  `json:"asset_name"`
-		CreatedAt       string  `json:"created_at,omitempty"`
-		UpdatedAt       string  `json:"updated_at,omitempty"`
+		ID                int64       `json:"id"`
+		ConsumerID        int64       `json:"consumer_id"`
+		MerchantID        int64       `json:"merchant_id"`
+		LoanProductID     int64       `json:"loan_product_id,omitempty"`
+		ConsumerLimitID   int64       `json:"consumer_limit_id"`
+		AssetPrice        money.Money `json:"asset_price"`
+		DownPaymentAmount money.Money `json:"down_payment_amount"`
+		LoanAmount        money.Money `json:"loan_amount"`
+		ContractNumber    string      `json:"contract_number"`
+		InterestRate      float64     `json:"interest_rate"`
+		InterestMethod    string      `json:"interest_method"`
+		InterestAmount    money.Money `json:"interest_amount"`
+		OutstandingAmount money.Money `json:"outstanding_amount"`
+		AdminFeeAmount    money.Money `json:"admin_fee_amount"`
+		InsuranceAmount   money.Money `json:"insurance_amount"`
+		FeePayment        string      `json:"fee_payment"`
+		LoanStatus        string      `json:"loan_status"`
+		StatusReason      string      `json:"status_reason,omitempty"`
+		DisbursedAt       string      `json:"disbursed_at,omitempty"`
+		DueDate           string      `json:"due_date"`
+		Installment       int32       `json:"installment"`
+		DaysPastDue       int32       `json:"days_past_due"`
+		AssetName         string      `json:"asset_name"`
+		CreatedAt         string      `json:"created_at,omitempty"`
+		UpdatedAt         string      `json:"updated_at,omitempty"`
+	}
+
+	LoanInstallmentResponse struct {
+		InstallmentNumber   int32       `json:"installment_number"`
+		DueDate             string      `json:"due_date"`
+		PrincipalAmount     money.Money `json:"principal_amount"`
+		InterestAmount      money.Money `json:"interest_amount"`
+		TotalAmount         money.Money `json:"total_amount"`
+		PaidPrincipalAmount money.Money `json:"paid_principal_amount"`
+		PaidInterestAmount  money.Money `json:"paid_interest_amount"`
+		InstallmentStatus   string      `json:"installment_status"`
+		PaidAt              string      `json:"paid_at,omitempty"`
+	}
+
+	// LoanScheduleResponse lists the installments of a loan along with what was paid before
+	// the first one: UpfrontAmount is the down payment and the fees not financed. Financed
+	// fees are part of the loan amount and so are repaid with the installments.
+	LoanScheduleResponse struct {
+		LoanID            int64                     `json:"loan_id"`
+		ContractNumber    string                    `json:"contract_number"`
+		Tenure            int16                     `json:"tenure"`
+		AssetPrice        money.Money               `json:"asset_price"`
+		DownPaymentAmount money.Money               `json:"down_payment_amount"`
+		LoanAmount        money.Money               `json:"loan_amount"`
+		AdminFeeAmount    money.Money               `json:"admin_fee_amount"`
+		InsuranceAmount   money.Money               `json:"insurance_amount"`
+		FeePayment        string                    `json:"fee_payment"`
+		UpfrontAmount     money.Money               `json:"upfront_amount"`
+		Installments      []LoanInstallmentResponse `json:"installments"`
 	}
 )
 
 func NewLoanUsecase(
 	loanRepo repository.LoanRepository,
+	loanInstallmentRepo repository.LoanInstallmentRepository,
+	loanRestructureRepo repository.LoanRestructureRepository,
 	consumerLimitRepo repository.ConsumerLimitRepository,
 	consumerRepo repository.ConsumerRepository,
 	merchantRepo repository.MerchantRepository,
+	loanProductRepo repository.LoanProductRepository,
+	ledgerRepo repository.LedgerRepository,
+	notificationRepo repository.MerchantNotificationRepository,
+	loanConfig config.LoanConfig,
 	timeout time.Duration,
 ) LoanUsecase {
 	return &loanUsecase{
-		loanRepo:          loanRepo,
-		consumerLimitRepo: consumerLimitRepo,
-		consumerRepo:      consumerRepo,
-		merchantRepo:      merchantRepo,
-		ctxTimeout:        timeout,
+		loanRepo:            loanRepo,
+		loanInstallmentRepo: loanInstallmentRepo,
+		loanRestructureRepo: loanRestructureRepo,
+		consumerLimitRepo:   consumerLimitRepo,
+		consumerRepo:        consumerRepo,
+		merchantRepo:        merchantRepo,
+		loanProductRepo:     loanProductRepo,
+		ledgerRepo:          ledgerRepo,
+		notificationRepo:    notificationRepo,
+		loanConfig:          loanConfig,
+		ctxTimeout:          timeout,
 	}
 }
 
@@ -74,102 +185,198 @@ func (uc *loanUsecase) CreateLoan(ctx context.Context, req CreateLoanRequest) (r
 	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
 	defer cancel()
 
-	consumer, err := uc.consumerRepo.GetConsumerByID(ctx, req.ConsumerID)
+	if req.FeePayment == "" {
+		req.FeePayment = FeePaymentUpfront
+	}
+
+	now := time.Now()
+	quote, err := uc.quoteLoan(ctx, req, now)
 	if err != nil {
-		logger.Error(fmt.Sprintf("[LoanUsecase][CreateLoan] while get consumer by ID, Err: %+v", err))
 		return response, err
 	}
-	if consumer.ID == 0 {
-		return response, errors.New("consumer not found")
-	}
 
-	merchant, err := uc.merchantRepo.GetMerchantByID(ctx, req.MerchantID)
+	err = uc.checkLoanApplicant(ctx, req.ConsumerID, req.MerchantID)
 	if err != nil {
-		logger.Error(fmt.Sprintf("[LoanUsecase][CreateLoan] while get merchant by ID, Err: %+v", err))
 		return response, err
 	}
-	if merchant.ID == 0 {
-		return response, errors.New("merchant not found")
+
+	tx, err := uc.loanRepo.BeginTx(ctx)
+	if err != nil {
+		return response, err
 	}
 
-	consumerLimit, err := uc.consumerLimitRepo.GetLimitByTenureAndConsumerID(ctx, req.Tenure, req.ConsumerID)
+	// the limit row stays locked until the loan is committed, so concurrent applications
+	// against the same limit are checked one after the other. The loans are read after the
+	// lock is taken and therefore include every loan committed against the limit before.
+	consumerLimit, err := uc.consumerLimitRepo.LockLimitByTenureAndConsumerID(ctx, req.Tenure, req.ConsumerID, tx)
 	if err != nil {
-		logger.Error(fmt.Sprintf("[LoanUsecase][CreateLoan] while get consumer limit by tenure and consumer ID, Err: %+v", err))
+		logger.Error(fmt.Sprintf("[LoanUsecase][CreateLoan] while lock consumer limit by tenure and consumer ID, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
 		return response, err
 	}
 	if consumerLimit.ID == 0 {
+		uc.loanRepo.RollbackTx(ctx, tx)
 		return response, errors.New("consumer limit not found")
 	}
 
 	loans, err := uc.loanRepo.GetLoanByConsumerID(ctx, req.ConsumerID)
 	if err != nil {
 		logger.Error(fmt.Sprintf("[LoanUsecase][CreateLoan] while get loan by consumer ID, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
 		return response, err
 	}
 
-	if len(loans) > 0 {
-		var (
-			totalLoanAmount     float64
-			totalPaidLoanAmount float64
-		)
-
-		for _, loan := range loans {
-			if loan.LoanStatus != "finish" && loan.ConsumerLimitID == consumerLimit.ID {
-				totalLoanAmount += loan.LoanAmount
-				totalPaidLoanAmount += loan.PaidLoanAmount
-			} else {
-				continue
-			}
-		}
-
-		remainingLimit := consumerLimit.LimitAmount - (totalLoanAmount - totalPaidLoanAmount)
-		if req.LoanAmount > remainingLimit {
-			errMsg := fmt.Sprintf("remaining limit: %.2f", remainingLimit)
-			return response, errors.New(errMsg)
-		}
+	remainingLimit := consumerLimit.LimitAmount.Sub(usedLimitAmount(loans, consumerLimit.ID, 0))
+	if quote.LoanAmount.GreaterThan(remainingLimit) {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		errMsg := fmt.Sprintf("remaining limit: %s", remainingLimit)
+		return response, errors.New(errMsg)
 	}
 
-	dueDate := time.Now().AddDate(0, int(req.Tenure), 0)
+	dueDate := now.AddDate(0, int(req.Tenure), 0)
 	contractNumber := fmt.Sprintf("%d-%s-%d", req.ConsumerID, utils.GenerateUniqueString(10), req.MerchantID)
-	interestAmount := req.LoanAmount * req.InterestRate / 100
-	loanStatus := "on_going"
+	loanStatus := LoanStatusPendingReview
 
 	loan := repository.Loan{
-		ConsumerID:      req.ConsumerID,
-		MerchantID:      req.MerchantID,
-		ConsumerLimitID: consumerLimit.ID,
-		LoanAmount:      req.LoanAmount,
-		InterestRate:    req.InterestRate,
-		InterestAmount:  interestAmount,
-		LoanStatus:      loanStatus,
-		DueDate:         dueDate,
-		ContractNumber:  contractNumber,
-		AssetName:       req.AssetName,
+		ConsumerID:        req.ConsumerID,
+		MerchantID:        req.MerchantID,
+		LoanProductID:     quote.Terms.Product.ID,
+		ConsumerLimitID:   consumerLimit.ID,
+		AssetPrice:        req.AssetPrice,
+		DownPaymentAmount: req.DownPaymentAmount,
+		LoanAmount:        quote.LoanAmount,
+		InterestRate:      quote.Terms.InterestRate,
+		InterestMethod:    quote.Terms.InterestMethod,
+		InterestAmount:    quote.InterestAmount,
+		AdminFeeAmount:    quote.Terms.AdminFeeAmount,
+		InsuranceAmount:   quote.InsuranceAmount,
+		FeePayment:        req.FeePayment,
+		LoanStatus:        loanStatus,
+		DueDate:           dueDate,
+		ContractNumber:    contractNumber,
+		AssetName:         req.AssetName,
 	}
 
-	loanID, err := uc.loanRepo.CreateLoan(ctx, loan)
+	loanID, err := uc.loanRepo.CreateLoan(ctx, loan, tx)
 	if err != nil {
 		logger.Error(fmt.Sprintf("[LoanUsecase][CreateLoan] while create loan, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	installments := buildInstallmentSchedule(loanID, quote.Installments, now)
+	err = uc.loanInstallmentRepo.CreateLoanInstallments(ctx, installments, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][CreateLoan] while create loan installments, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	err = postJournalEntry(ctx, uc.ledgerRepo, repository.JournalEntry{
+		ReferenceType: JournalReferenceLoan,
+		ReferenceID:   loanID,
+		Description:   fmt.Sprintf("Loan %s booked", contractNumber),
+		PostedAt:      now,
+		Lines:         loanBookingLines(loan),
+	}, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][CreateLoan] while post journal entry, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	err = uc.loanRepo.CommitTx(ctx, tx)
+	if err != nil {
 		return response, err
 	}
 
 	response = LoanResponse{
-		ID:              loanID,
-		ConsumerID:      req.ConsumerID,
-		MerchantID:      req.MerchantID,
-		ConsumerLimitID: consumerLimit.ID,
-		LoanAmount:      req.LoanAmount,
-		ContractNumber:  contractNumber,
-		InterestRate:    req.InterestRate,
-		InterestAmount:  interestAmount,
-		LoanStatus:      loanStatus,
-		DueDate:         dueDate.Format("2006-01-02"),
-		AssetName:       req.AssetName,
+		ID:                loanID,
+		ConsumerID:        req.ConsumerID,
+		MerchantID:        req.MerchantID,
+		LoanProductID:     quote.Terms.Product.ID,
+		ConsumerLimitID:   consumerLimit.ID,
+		AssetPrice:        req.AssetPrice,
+		DownPaymentAmount: req.DownPaymentAmount,
+		LoanAmount:        quote.LoanAmount,
+		ContractNumber:    contractNumber,
+		InterestRate:      quote.Terms.InterestRate,
+		InterestMethod:    quote.Terms.InterestMethod,
+		InterestAmount:    quote.InterestAmount,
+		OutstandingAmount: quote.LoanAmount.Add(quote.InterestAmount),
+		AdminFeeAmount:    quote.Terms.AdminFeeAmount,
+		InsuranceAmount:   quote.InsuranceAmount,
+		FeePayment:        req.FeePayment,
+		LoanStatus:        loanStatus,
+		DueDate:           dueDate.Format("2006-01-02"),
+		AssetName:         req.AssetName,
 	}
 
 	return response, nil
 }
 
+// quoteLoan prices a loan application at asOf on the terms of its product without booking
+// it. The product lends on the asset price less the down payment, its fees are added on
+// top when financed.
+func (uc *loanUsecase) quoteLoan(ctx context.Context, req CreateLoanRequest, asOf time.Time) (quote loanQuote, err error) {
+	if req.DownPaymentAmount.IsNegative() || !req.DownPaymentAmount.LessThan(req.AssetPrice) {
+		return quote, errors.New("down payment must be less than the asset price")
+	}
+
+	financedAssetAmount := req.AssetPrice.Sub(req.DownPaymentAmount)
+	quote.Terms, err = getLoanProductTerms(ctx, uc.loanProductRepo, req.ProductCode, req.Tenure, financedAssetAmount, asOf)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("[LoanUsecase][quoteLoan] while get loan product terms, Err: %+v", err))
+		return quote, err
+	}
+
+	if req.Insured {
+		if quote.Terms.InsuranceRate == 0 {
+			return quote, fmt.Errorf("%w: credit insurance is not offered by %s", ErrLoanProductNotOffered, req.ProductCode)
+		}
+		quote.InsuranceAmount = financedAssetAmount.Percent(quote.Terms.InsuranceRate)
+	}
+
+	quote.LoanAmount = financedAssetAmount
+	if req.FeePayment == FeePaymentFinanced {
+		quote.LoanAmount = quote.LoanAmount.Add(quote.Terms.AdminFeeAmount).Add(quote.InsuranceAmount)
+	}
+
+	calculator, err := NewInterestCalculator(quote.Terms.InterestMethod)
+	if err != nil {
+		return quote, err
+	}
+
+	quote.Installments = calculator.Calculate(quote.LoanAmount, quote.Terms.InterestRate, req.Tenure)
+	quote.InterestAmount = TotalInterest(quote.Installments)
+
+	return quote, nil
+}
+
+// checkLoanApplicant checks that the consumer applying and the merchant selling the asset
+// exist.
+func (uc *loanUsecase) checkLoanApplicant(ctx context.Context, consumerID int64, merchantID int64) error {
+	consumer, err := uc.consumerRepo.GetConsumerByID(ctx, consumerID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][checkLoanApplicant] while get consumer by ID, Err: %+v", err))
+		return err
+	}
+	if consumer.ID == 0 {
+		return errors.New("consumer not found")
+	}
+
+	merchant, err := uc.merchantRepo.GetMerchantByID(ctx, merchantID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][checkLoanApplicant] while get merchant by ID, Err: %+v", err))
+		return err
+	}
+	if merchant.ID == 0 {
+		return errors.New("merchant not found")
+	}
+
+	return nil
+}
+
 func (uc *loanUsecase) GetLoanByID(ctx context.Context, loanID int64) (response LoanResponse, err error) {
 	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
 	defer cancel()
@@ -182,22 +389,7 @@ func (uc *loanUsecase) GetLoanByID(ctx context.Context, loanID int64) (response
 		return response, errors.New("loan not found")
 	}
 
-	response = LoanResponse{
-		ID:              loan.ID,
-		ConsumerID:      loan.ConsumerID,
-		MerchantID:      loan.MerchantID,
-		ConsumerLimitID: loan.ConsumerLimitID,
-		LoanAmount:      loan.LoanAmount,
-		ContractNumber:  loan.ContractNumber,
-		InterestRate:    loan.InterestRate,
-		InterestAmount:  loan.InterestAmount,
-		LoanStatus:      loan.LoanStatus,
-		DueDate:         loan.DueDate.Format("2006-01-02"),
-		Installment:     loan.Installment,
-		AssetName:       loan.AssetName,
-		CreatedAt:       loan.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:       loan.UpdatedAt.Format("2006-01-02 15:04:05"),
-	}
+	response = toLoanResponse(loan)
 
 	return response, nil
 }
@@ -212,43 +404,491 @@ func (uc *loanUsecase) GetLoanByConsumerID(ctx context.Context, consumerID int64
 	}
 
 	for _, loan := range loans {
-		response = append(response, LoanResponse{
-			ID:              loan.ID,
-			ConsumerID:      loan.ConsumerID,
-			MerchantID:      loan.MerchantID,
-			ConsumerLimitID: loan.ConsumerLimitID,
-			LoanAmount:      loan.LoanAmount,
-			ContractNumber:  loan.ContractNumber,
-			InterestRate:    loan.InterestRate,
-			InterestAmount:  loan.InterestAmount,
-			LoanStatus:      loan.LoanStatus,
-			DueDate:         loan.DueDate.Format("2006-01-02"),
-			Installment:     loan.Installment,
-			AssetName:       loan.AssetName,
-			CreatedAt:       loan.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:       loan.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		response = append(response, toLoanResponse(loan))
 	}
 
 	return response, nil
 }
 
+// DeleteLoanByID deletes a rejected or cancelled loan. Other loans are cancelled instead, so
+// the limit, the ledger and the merchant are settled. The loan stays locked until it is
+// deleted, so nothing is booked on it in between.
 func (uc *loanUsecase) DeleteLoanByID(ctx context.Context, loanID int64) (err error) {
 	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
 	defer cancel()
 
-	loan, err := uc.loanRepo.GetLoanByID(ctx, loanID)
+	tx, err := uc.loanRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	loan, err := uc.lockLoan(ctx, loanID, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	if loan.LoanStatus != LoanStatusRejected && loan.LoanStatus != LoanStatusCancelled {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return fmt.Errorf("%w: loan %s is %s, only rejected or cancelled loans are deleted", ErrLoanNotDeletable, loan.ContractNumber, loan.LoanStatus)
+	}
+
+	hasTransactions, err := uc.loanRepo.HasLoanTransactions(ctx, loanID, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][DeleteLoanByID] while check loan transactions, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+	if hasTransactions {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return fmt.Errorf("%w: loan %s cannot be deleted", ErrLoanHasTransactions, loan.ContractNumber)
+	}
+
+	err = uc.loanRepo.DeleteLoan(ctx, loanID, tx)
 	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
 		return err
 	}
+
+	return uc.loanRepo.CommitTx(ctx, tx)
+}
+
+func (uc *loanUsecase) GetLoanSchedule(ctx context.Context, loanID int64) (response LoanScheduleResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	loan, err := uc.loanRepo.GetLoanByID(ctx, loanID)
+	if err != nil {
+		return response, err
+	}
 	if loan.ID == 0 {
-		return errors.New("loan not found")
+		return response, errors.New("loan not found")
 	}
 
-	err = uc.loanRepo.DeleteLoan(ctx, loanID)
+	installments, err := uc.loanInstallmentRepo.GetLoanInstallmentsByLoanID(ctx, loanID)
 	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][GetLoanSchedule] while get loan installments, Err: %+v", err))
+		return response, err
+	}
+
+	response = LoanScheduleResponse{
+		LoanID:            loan.ID,
+		ContractNumber:    loan.ContractNumber,
+		AssetPrice:        loan.AssetPrice,
+		DownPaymentAmount: loan.DownPaymentAmount,
+		LoanAmount:        loan.LoanAmount,
+		AdminFeeAmount:    loan.AdminFeeAmount,
+		InsuranceAmount:   loan.InsuranceAmount,
+		FeePayment:        loan.FeePayment,
+		UpfrontAmount:     upfrontAmount(loan.DownPaymentAmount, loan.AdminFeeAmount.Add(loan.InsuranceAmount), loan.FeePayment),
+		Installments:      make([]LoanInstallmentResponse, 0, len(installments)),
+	}
+
+	for _, installment := range installments {
+		// restructured installments stay in the schedule as history
+		if installment.InstallmentStatus != "restructured" {
+			response.Tenure++
+		}
+
+		var paidAt string
+		if !installment.PaidAt.IsZero() {
+			paidAt = installment.PaidAt.Format("2006-01-02 15:04:05")
+		}
+
+		response.Installments = append(response.Installments, LoanInstallmentResponse{
+			InstallmentNumber:   installment.InstallmentNumber,
+			DueDate:             installment.DueDate.Format("2006-01-02"),
+			PrincipalAmount:     installment.PrincipalAmount,
+			InterestAmount:      installment.InterestAmount,
+			TotalAmount:         installment.PrincipalAmount.Add(installment.InterestAmount),
+			PaidPrincipalAmount: installment.PaidPrincipalAmount,
+			PaidInterestAmount:  installment.PaidInterestAmount,
+			InstallmentStatus:   installment.InstallmentStatus,
+			PaidAt:              paidAt,
+		})
+	}
+
+	return response, nil
+}
+
+func (uc *loanUsecase) ApproveLoan(ctx context.Context, loanID int64) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	return uc.updateLoanStatus(ctx, loanID, LoanStatusApproved, "")
+}
+
+func (uc *loanUsecase) RejectLoan(ctx context.Context, req LoanStatusRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	return uc.updateLoanStatus(ctx, req.LoanID, LoanStatusRejected, req.Reason)
+}
+
+// CancelLoan cancels a loan for a reason, releasing the limit it held. A loan is cancelled
+// before it is disbursed, or during the cooling-off period after its disbursement as long
+// as nothing was paid on it, in which case the merchant has to refund what it was paid.
+// The merchant is notified of the cancellation either way.
+func (uc *loanUsecase) CancelLoan(ctx context.Context, req LoanStatusRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	tx, err := uc.loanRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the loan stays locked until the cancellation is committed, so no payment is booked on
+	// it in between
+	loan, err := uc.lockLoan(ctx, req.LoanID, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
 		return err
 	}
 
+	now := time.Now()
+	err = uc.checkLoanCancellable(ctx, loan, now, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	err = uc.loanRepo.UpdateLoanStatus(ctx, repository.UpdateLoanStatusRequest{
+		ID:           loan.ID,
+		FromStatus:   loan.LoanStatus,
+		LoanStatus:   LoanStatusCancelled,
+		StatusReason: req.Reason,
+	}, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	err = postJournalEntry(ctx, uc.ledgerRepo, repository.JournalEntry{
+		ReferenceType: JournalReferenceLoanRelease,
+		ReferenceID:   loan.ID,
+		Description:   fmt.Sprintf("Loan %s cancelled", loan.ContractNumber),
+		PostedAt:      now,
+		Lines:         loanReleaseLines(loan),
+	}, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][CancelLoan] while post journal entry, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	notification := repository.MerchantNotification{
+		MerchantID: loan.MerchantID,
+		LoanID:     loan.ID,
+		EventType:  repository.MerchantNotificationLoanCancelled,
+		Message:    fmt.Sprintf("Loan %s for %s was cancelled: %s", loan.ContractNumber, loan.AssetName, req.Reason),
+	}
+	if loan.LoanStatus == LoanStatusDisbursed {
+		notification.RefundAmount = loan.LoanAmount.Sub(financedFeeAmount(loan))
+	}
+
+	_, err = uc.notificationRepo.CreateMerchantNotification(ctx, notification, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][CancelLoan] while create merchant notification, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	return uc.loanRepo.CommitTx(ctx, tx)
+}
+
+// checkLoanCancellable returns ErrLoanNotCancellable when a loan may no longer be cancelled.
+func (uc *loanUsecase) checkLoanCancellable(ctx context.Context, loan repository.Loan, now time.Time, tx *sql.Tx) error {
+	err := ValidateLoanStatusTransition(loan.LoanStatus, LoanStatusCancelled)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrLoanNotCancellable, err)
+	}
+
+	if loan.LoanStatus == LoanStatusDisbursed && now.Sub(loan.DisbursedAt) > uc.loanConfig.CoolingOffPeriod {
+		return fmt.Errorf("%w: the cooling-off period ended on %s", ErrLoanNotCancellable, loan.DisbursedAt.Add(uc.loanConfig.CoolingOffPeriod).Format("2006-01-02 15:04:05"))
+	}
+
+	hasTransactions, err := uc.loanRepo.HasLoanTransactions(ctx, loan.ID, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][checkLoanCancellable] while check loan transactions, Err: %+v", err))
+		return err
+	}
+	if hasTransactions {
+		return fmt.Errorf("%w: payments were made on it", ErrLoanNotCancellable)
+	}
+
 	return nil
 }
+
+// DisburseLoan releases an approved loan. Its installments are due from the disbursement
+// date onwards rather than from the application date.
+func (uc *loanUsecase) DisburseLoan(ctx context.Context, loanID int64) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	tx, err := uc.loanRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the loan stays locked until the disbursement is committed, so it is not cancelled or
+	// rejected in between
+	loan, err := uc.lockLoan(ctx, loanID, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	err = ValidateLoanStatusTransition(loan.LoanStatus, LoanStatusDisbursed)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	consumerLimit, err := uc.consumerLimitRepo.GetConsumerLimitByID(ctx, loan.ConsumerLimitID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][DisburseLoan] while get consumer limit by ID, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+	if consumerLimit.ID == 0 {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return errors.New("consumer limit not found")
+	}
+
+	now := time.Now()
+	err = uc.loanRepo.DisburseLoan(ctx, repository.DisburseLoanRequest{
+		ID:          loan.ID,
+		DisbursedAt: now,
+		Tenure:      consumerLimit.Tenure,
+	}, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	err = uc.loanInstallmentRepo.RescheduleLoanInstallments(ctx, loan.ID, now, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][DisburseLoan] while reschedule loan installments, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	// the merchant is paid the asset financed, the financed fees are not theirs
+	merchantAmount := loan.LoanAmount.Sub(financedFeeAmount(loan))
+	err = postJournalEntry(ctx, uc.ledgerRepo, repository.JournalEntry{
+		ReferenceType: JournalReferenceLoanDisbursement,
+		ReferenceID:   loan.ID,
+		Description:   fmt.Sprintf("Loan %s disbursed", loan.ContractNumber),
+		PostedAt:      now,
+		Lines: []repository.JournalLine{
+			debitLine(LedgerAccountMerchantPayable, merchantAmount),
+			creditLine(LedgerAccountCash, merchantAmount),
+		},
+	}, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][DisburseLoan] while post journal entry, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	return uc.loanRepo.CommitTx(ctx, tx)
+}
+
+// updateLoanStatus approves or rejects a loan. The loan is locked while its status is
+// checked and changed, so the decision is not taken on a loan cancelled or disbursed
+// meanwhile.
+func (uc *loanUsecase) updateLoanStatus(ctx context.Context, loanID int64, loanStatus string, reason string) (err error) {
+	tx, err := uc.loanRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	loan, err := uc.lockLoan(ctx, loanID, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	err = ValidateLoanStatusTransition(loan.LoanStatus, loanStatus)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	err = uc.loanRepo.UpdateLoanStatus(ctx, repository.UpdateLoanStatusRequest{
+		ID:           loan.ID,
+		FromStatus:   loan.LoanStatus,
+		LoanStatus:   loanStatus,
+		StatusReason: reason,
+	}, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	if loanStatus != LoanStatusRejected {
+		return uc.loanRepo.CommitTx(ctx, tx)
+	}
+
+	// a rejected loan is no longer owed, by the consumer nor to the merchant, and its
+	// financed fees are not earned
+	err = postJournalEntry(ctx, uc.ledgerRepo, repository.JournalEntry{
+		ReferenceType: JournalReferenceLoanRelease,
+		ReferenceID:   loan.ID,
+		Description:   fmt.Sprintf("Loan %s %s", loan.ContractNumber, loanStatus),
+		PostedAt:      time.Now(),
+		Lines:         loanReleaseLines(loan),
+	}, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][updateLoanStatus] while post journal entry, Err: %+v", err))
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	return uc.loanRepo.CommitTx(ctx, tx)
+}
+
+// lockLoan locks a loan until tx ends and returns it as it stands under the lock.
+func (uc *loanUsecase) lockLoan(ctx context.Context, loanID int64, tx *sql.Tx) (loan repository.Loan, err error) {
+	found, err := uc.loanRepo.LockLoanByID(ctx, loanID, tx)
+	if err != nil {
+		return loan, err
+	}
+	if !found {
+		return loan, errors.New("loan not found")
+	}
+
+	return uc.loanRepo.GetLoanByID(ctx, loanID)
+}
+
+// toLoanResponse turns a stored loan into its response. The outstanding amount is the
+// principal and the interest that are left to repay.
+func toLoanResponse(loan repository.Loan) LoanResponse {
+	return LoanResponse{
+		ID:                loan.ID,
+		ConsumerID:        loan.ConsumerID,
+		MerchantID:        loan.MerchantID,
+		LoanProductID:     loan.LoanProductID,
+		ConsumerLimitID:   loan.ConsumerLimitID,
+		AssetPrice:        loan.AssetPrice,
+		DownPaymentAmount: loan.DownPaymentAmount,
+		LoanAmount:        loan.LoanAmount,
+		ContractNumber:    loan.ContractNumber,
+		InterestRate:      loan.InterestRate,
+		InterestMethod:    loan.InterestMethod,
+		InterestAmount:    loan.InterestAmount,
+		OutstandingAmount: loan.LoanAmount.Add(loan.InterestAmount).Sub(loan.PaidLoanAmount).Sub(loan.PaidInterestAmount),
+		AdminFeeAmount:    loan.AdminFeeAmount,
+		InsuranceAmount:   loan.InsuranceAmount,
+		FeePayment:        loan.FeePayment,
+		LoanStatus:        loan.LoanStatus,
+		StatusReason:      loan.StatusReason,
+		DisbursedAt:       formatOptionalTime(loan.DisbursedAt),
+		DueDate:           loan.DueDate.Format("2006-01-02"),
+		Installment:       loan.Installment,
+		DaysPastDue:       loan.DaysPastDue,
+		AssetName:         loan.AssetName,
+		CreatedAt:         loan.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:         loan.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// usedLimitAmount returns the outstanding amount of the loans taken from a consumer limit,
+// leaving out the loan exceptLoanID. Loans under review or approved but not disbursed yet
+// hold the limit as well, so a consumer cannot over-apply while an application is being
+// reviewed.
+func usedLimitAmount(loans []repository.Loan, consumerLimitID int64, exceptLoanID int64) money.Money {
+	var (
+		totalLoanAmount     money.Money
+		totalPaidLoanAmount money.Money
+	)
+
+	for _, loan := range loans {
+		if loanStatusHoldingLimit[loan.LoanStatus] && loan.ConsumerLimitID == consumerLimitID && loan.ID != exceptLoanID {
+			totalLoanAmount = totalLoanAmount.Add(loan.LoanAmount)
+			totalPaidLoanAmount = totalPaidLoanAmount.Add(loan.PaidLoanAmount)
+		}
+	}
+
+	return totalLoanAmount.Sub(totalPaidLoanAmount)
+}
+
+// financedFeeAmount returns the fees of a loan that are part of its loan amount.
+func financedFeeAmount(loan repository.Loan) money.Money {
+	if loan.FeePayment != FeePaymentFinanced {
+		return money.Zero
+	}
+
+	return loan.AdminFeeAmount.Add(loan.InsuranceAmount)
+}
+
+// upfrontAmount returns what is paid before the first installment: the down payment, and
+// the fees unless they are financed.
+func upfrontAmount(downPaymentAmount money.Money, feeAmount money.Money, feePayment string) money.Money {
+	if feePayment == FeePaymentFinanced {
+		return downPaymentAmount
+	}
+
+	return downPaymentAmount.Add(feeAmount)
+}
+
+// isInstallmentClosed reports whether an installment is no longer due, either because it
+// is paid or because it was replaced by a restructured schedule.
+func isInstallmentClosed(status string) bool {
+	return status == "paid" || status == "restructured"
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func formatOptionalDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format("2006-01-02")
+}
+
+// buildInstallmentSchedule turns calculated installment amounts into schedule rows,
+// one installment per month starting a month after startDate.
+func buildInstallmentSchedule(loanID int64, amounts []InstallmentAmount, startDate time.Time) []repository.LoanInstallment {
+	installments := make([]repository.LoanInstallment, 0, len(amounts))
+	for i, amount := range amounts {
+		installments = append(installments, repository.LoanInstallment{
+			LoanID:            loanID,
+			InstallmentNumber: int32(i + 1),
+			DueDate:           startDate.AddDate(0, i+1, 0),
+			PrincipalAmount:   amount.Principal,
+			InterestAmount:    amount.Interest,
+			InstallmentStatus: "unpaid",
+		})
+	}
+
+	return installments
+}
+
+// rebuildInstallmentSchedule recreates the schedule of a loan booked before schedules were
+// persisted from its terms and stored interest method. The first loan.Installment
+// installments are marked as paid.
+func rebuildInstallmentSchedule(loan repository.Loan, tenure int16) ([]repository.LoanInstallment, error) {
+	calculator, err := NewInterestCalculator(loan.InterestMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := calculator.Calculate(loan.LoanAmount, loan.InterestRate, tenure)
+	schedule := buildInstallmentSchedule(loan.ID, amounts, loan.CreatedAt)
+	for i := 0; i < len(schedule) && i < int(loan.Installment); i++ {
+		schedule[i].PaidPrincipalAmount = schedule[i].PrincipalAmount
+		schedule[i].PaidInterestAmount = schedule[i].InterestAmount
+		schedule[i].InstallmentStatus = "paid"
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
+)
+
+type (
+	// FetchLoansRequest filters the loans. LoanStatus lists statuses separated by commas. The
+	// created and due date bounds are dates formatted as YYYY-MM-DD and, like the outstanding
+	// amount bounds, inclusive; a zero outstanding amount bound is not applied. SortBy is
+	// created_at, due_date, loan_amount or outstanding_amount and SortOrder asc or desc, the
+	// newest loan comes first by default.
+	FetchLoansRequest struct {
+		LoanStatus           string      `json:"loan_status" query:"loan_status"`
+		MerchantID           int64       `json:"merchant_id" query:"merchant_id"`
+		ConsumerID           int64       `json:"consumer_id" query:"consumer_id"`
+		ContractNumber       string      `json:"contract_number" query:"contract_number"`
+		CreatedFrom          string      `json:"created_from" query:"created_from"`
+		CreatedTo            string      `json:"created_to" query:"created_to"`
+		DueFrom              string      `json:"due_from" query:"due_from"`
+		DueTo                string      `json:"due_to" query:"due_to"`
+		MinOutstandingAmount money.Money `json:"min_outstanding_amount" query:"min_outstanding_amount"`
+		MaxOutstandingAmount money.Money `json:"max_outstanding_amount" query:"max_outstanding_amount"`
+		SortBy               string      `json:"sort_by" query:"sort_by"`
+		SortOrder            string      `json:"sort_order" query:"sort_order"`
+		Page                 int         `json:"page" query:"page"`
+		Limit                int         `json:"limit" query:"limit"`
+	}
+
+	// LoanListResponse is a page of loans, Total counts the loans matching the filters over
+	// all pages.
+	LoanListResponse struct {
+		Loans []LoanResponse `json:"loans"`
+		Page  int            `json:"page"`
+		Limit int            `json:"limit"`
+		Total int64          `json:"total"`
+	}
+)
+
+func (uc *loanUsecase) FetchLoans(ctx context.Context, req FetchLoansRequest) (response LoanListResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	limit, offset := utils.ParsePagination(req.Page, req.Limit)
+	filter := repository.FetchLoansRequest{
+		LoanStatuses:   SplitLoanStatuses(req.LoanStatus),
+		MerchantID:     req.MerchantID,
+		ConsumerID:     req.ConsumerID,
+		ContractNumber: req.ContractNumber,
+		SortBy:         req.SortBy,
+		SortOrder:      req.SortOrder,
+		Limit:          limit,
+		Offset:         offset,
+	}
+
+	for _, loanStatus := range filter.LoanStatuses {
+		if !repository.ValidLoanStatus[loanStatus] {
+			return response, errors.New("loan_status must be a valid value")
+		}
+	}
+
+	filter.CreatedFrom, filter.CreatedTo, err = parseDateRange(req.CreatedFrom, req.CreatedTo)
+	if err != nil {
+		return response, errors.New("created_from and created_to must be formatted as YYYY-MM-DD")
+	}
+	filter.DueFrom, filter.DueTo, err = parseDateRange(req.DueFrom, req.DueTo)
+	if err != nil {
+		return response, errors.New("due_from and due_to must be formatted as YYYY-MM-DD")
+	}
+
+	if !req.MinOutstandingAmount.IsZero() {
+		filter.MinOutstandingAmount = money.NullMoney{Money: req.MinOutstandingAmount, Valid: true}
+	}
+	if !req.MaxOutstandingAmount.IsZero() {
+		filter.MaxOutstandingAmount = money.NullMoney{Money: req.MaxOutstandingAmount, Valid: true}
+	}
+
+	loans, total, err := uc.loanRepo.FetchLoans(ctx, filter)
+	if err != nil {
+		return response, err
+	}
+
+	response = LoanListResponse{
+		Loans: make([]LoanResponse, 0, len(loans)),
+		Page:  offset/limit + 1,
+		Limit: limit,
+		Total: total,
+	}
+	for _, loan := range loans {
+		response.Loans = append(response.Loans, toLoanResponse(loan))
+	}
+
+	return response, nil
+}
+
+// SplitLoanStatuses returns the statuses of a comma separated list, blank entries left out.
+func SplitLoanStatuses(value string) []string {
+	var loanStatuses []string
+	for _, loanStatus := range strings.Split(value, ",") {
+		if loanStatus = strings.TrimSpace(loanStatus); loanStatus != "" {
+			loanStatuses = append(loanStatuses, loanStatus)
+		}
+	}
+
+	return loanStatuses
+}
+
+// parseDateRange parses the inclusive dates from and to, formatted as YYYY-MM-DD, into a
+// range whose end is exclusive. A blank date leaves its bound unset.
+func parseDateRange(from string, to string) (start time.Time, end time.Time, err error) {
+	if from != "" {
+		start, err = time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return start, end, err
+		}
+	}
+	if to != "" {
+		end, err = time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return start, end, err
+		}
+		// the whole last day is included
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return start, end, nil
+}
@@ -0,0 +1,128 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFetchLoans(t *testing.T) {
+	mockLoanRepo := new(mocks.LoanRepository)
+
+	uc := usecase.NewLoanUsecase(mockLoanRepo, new(mocks.LoanInstallmentRepository), new(mocks.LoanRestructureRepository), new(mocks.ConsumerLimitRepository), new(mocks.ConsumerRepository), new(mocks.MerchantRepository), new(mocks.LoanProductRepository), new(mocks.LedgerRepository), new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
+
+	t.Run("success", func(t *testing.T) {
+		dueDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+		createdAt := time.Date(2024, 1, 15, 9, 30, 0, 0, time.Local)
+
+		mockLoanRepo.On("FetchLoans", mock.Anything, repository.FetchLoansRequest{
+			LoanStatuses:         []string{usecase.LoanStatusOnGoing, usecase.LoanStatusLate},
+			MerchantID:           2,
+			ConsumerID:           1,
+			ContractNumber:       "CN-1",
+			CreatedFrom:          time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+			CreatedTo:            time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local),
+			DueFrom:              time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local),
+			DueTo:                time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local),
+			MinOutstandingAmount: money.NullMoney{Money: money.New(100), Valid: true},
+			SortBy:               "outstanding_amount",
+			SortOrder:            "asc",
+			Limit:                5,
+			Offset:               5,
+		}).Return([]repository.Loan{
+			{
+				ID:                 1,
+				ConsumerID:         1,
+				MerchantID:         2,
+				LoanAmount:         money.New(1000),
+				PaidLoanAmount:     money.New(400),
+				InterestAmount:     money.New(100),
+				PaidInterestAmount: money.New(40),
+				ContractNumber:     "CN-1",
+				LoanStatus:         usecase.LoanStatusLate,
+				DueDate:            dueDate,
+				CreatedAt:          createdAt,
+				UpdatedAt:          createdAt,
+			},
+		}, int64(6), nil).Once()
+
+		resp, err := uc.FetchLoans(context.Background(), usecase.FetchLoansRequest{
+			LoanStatus:           "on_going, late",
+			MerchantID:           2,
+			ConsumerID:           1,
+			ContractNumber:       "CN-1",
+			CreatedFrom:          "2024-01-01",
+			CreatedTo:            "2024-01-31",
+			DueFrom:              "2024-03-01",
+			DueTo:                "2024-03-31",
+			MinOutstandingAmount: money.New(100),
+			SortBy:               "outstanding_amount",
+			SortOrder:            "asc",
+			Page:                 2,
+			Limit:                5,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, usecase.LoanListResponse{
+			Loans: []usecase.LoanResponse{
+				{
+					ID:                1,
+					ConsumerID:        1,
+					MerchantID:        2,
+					LoanAmount:        money.New(1000),
+					ContractNumber:    "CN-1",
+					InterestAmount:    money.New(100),
+					OutstandingAmount: money.New(660),
+					LoanStatus:        usecase.LoanStatusLate,
+					DueDate:           "2024-03-01",
+					CreatedAt:         "2024-01-15 09:30:00",
+					UpdatedAt:         "2024-01-15 09:30:00",
+				},
+			},
+			Page:  2,
+			Limit: 5,
+			Total: 6,
+		}, resp)
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("no loans", func(t *testing.T) {
+		mockLoanRepo.On("FetchLoans", mock.Anything, repository.FetchLoansRequest{Limit: 10}).Return(nil, int64(0), nil).Once()
+
+		resp, err := uc.FetchLoans(context.Background(), usecase.FetchLoansRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, usecase.LoanListResponse{Loans: []usecase.LoanResponse{}, Page: 1, Limit: 10}, resp)
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid loan status", func(t *testing.T) {
+		resp, err := uc.FetchLoans(context.Background(), usecase.FetchLoansRequest{LoanStatus: "late,overdue"})
+		assert.Error(t, err)
+		assert.Equal(t, "loan_status must be a valid value", err.Error())
+		assert.Equal(t, usecase.LoanListResponse{}, resp)
+	})
+
+	t.Run("invalid due date", func(t *testing.T) {
+		resp, err := uc.FetchLoans(context.Background(), usecase.FetchLoansRequest{DueTo: "31-03-2024"})
+		assert.Error(t, err)
+		assert.Equal(t, "due_from and due_to must be formatted as YYYY-MM-DD", err.Error())
+		assert.Equal(t, usecase.LoanListResponse{}, resp)
+	})
+
+	t.Run("error fetching loans", func(t *testing.T) {
+		expectedErr := errors.New("unexpected error")
+		mockLoanRepo.On("FetchLoans", mock.Anything, repository.FetchLoansRequest{MerchantID: 9, Limit: 10}).Return(nil, int64(0), expectedErr).Once()
+
+		resp, err := uc.FetchLoans(context.Background(), usecase.FetchLoansRequest{MerchantID: 9})
+		assert.Equal(t, expectedErr, err)
+		assert.Equal(t, usecase.LoanListResponse{}, resp)
+	})
+}
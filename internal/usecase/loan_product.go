@@ -0,0 +1,403 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
+)
+
+// ErrInvalidLoanProduct is returned when the terms of a loan product contradict each other.
+var ErrInvalidLoanProduct = errors.New("invalid loan product")
+
+// ErrLoanProductNotOffered is returned when a loan is applied for on terms its product does
+// not offer.
+var ErrLoanProductNotOffered = errors.New("loan product not offered")
+
+type LoanProductUsecase interface {
+	CreateLoanProduct(ctx context.Context, request LoanProductRequest) (response LoanProductResponse, err error)
+	UpdateLoanProduct(ctx context.Context, id int64, request LoanProductRequest) (err error)
+	DeleteLoanProduct(ctx context.Context, id int64) (err error)
+	GetLoanProductByID(ctx context.Context, id int64) (response LoanProductResponse, err error)
+	FetchLoanProducts(ctx context.Context, req FetchLoanProductsRequest) (response []LoanProductResponse, err error)
+}
+
+type loanProductUsecase struct {
+	loanProductRepo repository.LoanProductRepository
+	ctxTimeout      time.Duration
+}
+
+type (
+	LoanProductTenureRequest struct {
+		Tenure       int16   `json:"tenure"`
+		InterestRate float64 `json:"interest_rate"`
+	}
+
+	LoanProductRequest struct {
+		ProductCode    string                     `json:"product_code"`
+		ProductName    string                     `json:"product_name"`
+		InterestMethod string                     `json:"interest_method"`
+		AdminFeeAmount money.Money                `json:"admin_fee_amount"`
+		InsuranceRate  float64                    `json:"insurance_rate"`
+		MinLoanAmount  money.Money                `json:"min_loan_amount"`
+		MaxLoanAmount  money.Money                `json:"max_loan_amount"`
+		ValidFrom      string                     `json:"valid_from"`
+		ValidUntil     string                     `json:"valid_until"`
+		Tenures        []LoanProductTenureRequest `json:"tenures"`
+	}
+
+	FetchLoanProductsRequest struct {
+		Page  int `json:"page" query:"page"`
+		Limit int `json:"limit" query:"limit"`
+	}
+
+	LoanProductTenureResponse struct {
+		Tenure       int16   `json:"tenure"`
+		InterestRate float64 `json:"interest_rate"`
+	}
+
+	LoanProductResponse struct {
+		ID             int64                       `json:"id"`
+		ProductCode    string                      `json:"product_code"`
+		ProductName    string                      `json:"product_name"`
+		InterestMethod string                      `json:"interest_method"`
+		AdminFeeAmount money.Money                 `json:"admin_fee_amount"`
+		InsuranceRate  float64                     `json:"insurance_rate"`
+		MinLoanAmount  money.Money                 `json:"min_loan_amount"`
+		MaxLoanAmount  money.Money                 `json:"max_loan_amount"`
+		ValidFrom      string                      `json:"valid_from"`
+		ValidUntil     string                      `json:"valid_until,omitempty"`
+		Tenures        []LoanProductTenureResponse `json:"tenures"`
+		CreatedAt      string                      `json:"created_at,omitempty"`
+	}
+
+	// loanProductTerms are the terms a loan is granted on by its product.
+	loanProductTerms struct {
+		Product        repository.LoanProduct
+		InterestRate   float64
+		InterestMethod string
+		AdminFeeAmount money.Money
+		InsuranceRate  float64
+	}
+)
+
+func NewLoanProductUsecase(
+	loanProductRepo repository.LoanProductRepository,
+	timeout time.Duration,
+) LoanProductUsecase {
+	return &loanProductUsecase{
+		loanProductRepo: loanProductRepo,
+		ctxTimeout:      timeout,
+	}
+}
+
+// toLoanProduct checks the terms of the request against each other and returns the product
+// and its tenures.
+func (request LoanProductRequest) toLoanProduct() (product repository.LoanProduct, tenures []repository.LoanProductTenure, err error) {
+	if request.InterestMethod == "" {
+		request.InterestMethod = InterestMethodFlat
+	}
+	if _, err = NewInterestCalculator(request.InterestMethod); err != nil {
+		return product, nil, fmt.Errorf("%w: %v", ErrInvalidLoanProduct, err)
+	}
+
+	product = repository.LoanProduct{
+		ProductCode:    request.ProductCode,
+		ProductName:    request.ProductName,
+		InterestMethod: request.InterestMethod,
+		AdminFeeAmount: request.AdminFeeAmount,
+		InsuranceRate:  request.InsuranceRate,
+		MinLoanAmount:  request.MinLoanAmount,
+		MaxLoanAmount:  request.MaxLoanAmount,
+	}
+
+	if product.InsuranceRate < 0 {
+		return product, nil, fmt.Errorf("%w: insurance_rate is negative", ErrInvalidLoanProduct)
+	}
+
+	product.ValidFrom, err = time.ParseInLocation("2006-01-02", request.ValidFrom, time.Local)
+	if err != nil {
+		return product, nil, fmt.Errorf("%w: invalid valid_from", ErrInvalidLoanProduct)
+	}
+	if request.ValidUntil != "" {
+		product.ValidUntil, err = time.ParseInLocation("2006-01-02", request.ValidUntil, time.Local)
+		if err != nil {
+			return product, nil, fmt.Errorf("%w: invalid valid_until", ErrInvalidLoanProduct)
+		}
+		if product.ValidUntil.Before(product.ValidFrom) {
+			return product, nil, fmt.Errorf("%w: valid_until is before valid_from", ErrInvalidLoanProduct)
+		}
+	}
+
+	if !product.MaxLoanAmount.IsZero() && product.MaxLoanAmount.LessThan(product.MinLoanAmount) {
+		return product, nil, fmt.Errorf("%w: max_loan_amount is less than min_loan_amount", ErrInvalidLoanProduct)
+	}
+
+	if len(request.Tenures) == 0 {
+		return product, nil, fmt.Errorf("%w: no tenure offered", ErrInvalidLoanProduct)
+	}
+
+	offered := make(map[int16]bool, len(request.Tenures))
+	for _, tenure := range request.Tenures {
+		if tenure.Tenure <= 0 {
+			return product, nil, fmt.Errorf("%w: tenure %d is not positive", ErrInvalidLoanProduct, tenure.Tenure)
+		}
+		if tenure.InterestRate < 0 {
+			return product, nil, fmt.Errorf("%w: interest rate of tenure %d is negative", ErrInvalidLoanProduct, tenure.Tenure)
+		}
+		if offered[tenure.Tenure] {
+			return product, nil, fmt.Errorf("%w: tenure %d is offered twice", ErrInvalidLoanProduct, tenure.Tenure)
+		}
+		offered[tenure.Tenure] = true
+
+		tenures = append(tenures, repository.LoanProductTenure{
+			Tenure:       tenure.Tenure,
+			InterestRate: tenure.InterestRate,
+		})
+	}
+
+	return product, tenures, nil
+}
+
+func (uc *loanProductUsecase) CreateLoanProduct(ctx context.Context, request LoanProductRequest) (response LoanProductResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	product, tenures, err := request.toLoanProduct()
+	if err != nil {
+		return response, err
+	}
+
+	tx, err := uc.loanProductRepo.BeginTx(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	product.ID, err = uc.loanProductRepo.CreateLoanProduct(ctx, product, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductUsecase][CreateLoanProduct] while create loan product, Err: %+v", err))
+		uc.loanProductRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	for i := range tenures {
+		tenures[i].LoanProductID = product.ID
+	}
+
+	err = uc.loanProductRepo.CreateLoanProductTenures(ctx, tenures, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductUsecase][CreateLoanProduct] while create loan product tenures, Err: %+v", err))
+		uc.loanProductRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	err = uc.loanProductRepo.CommitTx(ctx, tx)
+	if err != nil {
+		return response, err
+	}
+
+	return toLoanProductResponse(product, tenures), nil
+}
+
+// UpdateLoanProduct replaces the terms and the tenures of a product. Loans already granted
+// keep the terms they were granted on.
+func (uc *loanProductUsecase) UpdateLoanProduct(ctx context.Context, id int64, request LoanProductRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	product, tenures, err := request.toLoanProduct()
+	if err != nil {
+		return err
+	}
+
+	current, err := uc.loanProductRepo.GetLoanProductByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current.ID == 0 {
+		return errors.New("loan product not found")
+	}
+
+	product.ID = id
+	for i := range tenures {
+		tenures[i].LoanProductID = id
+	}
+
+	tx, err := uc.loanProductRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = uc.loanProductRepo.UpdateLoanProduct(ctx, product, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductUsecase][UpdateLoanProduct] while update loan product, Err: %+v", err))
+		uc.loanProductRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	err = uc.loanProductRepo.DeleteLoanProductTenures(ctx, id, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductUsecase][UpdateLoanProduct] while delete loan product tenures, Err: %+v", err))
+		uc.loanProductRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	err = uc.loanProductRepo.CreateLoanProductTenures(ctx, tenures, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanProductUsecase][UpdateLoanProduct] while create loan product tenures, Err: %+v", err))
+		uc.loanProductRepo.RollbackTx(ctx, tx)
+		return err
+	}
+
+	return uc.loanProductRepo.CommitTx(ctx, tx)
+}
+
+func (uc *loanProductUsecase) DeleteLoanProduct(ctx context.Context, id int64) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	err = uc.loanProductRepo.DeleteLoanProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetLoanProductByID returns an empty response when the product does not exist.
+func (uc *loanProductUsecase) GetLoanProductByID(ctx context.Context, id int64) (response LoanProductResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	product, err := uc.loanProductRepo.GetLoanProductByID(ctx, id)
+	if err != nil {
+		return response, err
+	}
+	if product.ID == 0 {
+		return response, nil
+	}
+
+	tenures, err := uc.loanProductRepo.GetLoanProductTenures(ctx, []int64{product.ID})
+	if err != nil {
+		return response, err
+	}
+
+	return toLoanProductResponse(product, tenures), nil
+}
+
+func (uc *loanProductUsecase) FetchLoanProducts(ctx context.Context, req FetchLoanProductsRequest) (response []LoanProductResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	limit, offset := utils.ParsePagination(req.Page, req.Limit)
+
+	products, err := uc.loanProductRepo.FetchLoanProducts(ctx, repository.FetchLoanProductsRequest{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return response, err
+	}
+
+	productIDs := make([]int64, 0, len(products))
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
+	}
+
+	tenures, err := uc.loanProductRepo.GetLoanProductTenures(ctx, productIDs)
+	if err != nil {
+		return response, err
+	}
+
+	tenuresByProduct := make(map[int64][]repository.LoanProductTenure, len(products))
+	for _, tenure := range tenures {
+		tenuresByProduct[tenure.LoanProductID] = append(tenuresByProduct[tenure.LoanProductID], tenure)
+	}
+
+	for _, product := range products {
+		response = append(response, toLoanProductResponse(product, tenuresByProduct[product.ID]))
+	}
+
+	return response, nil
+}
+
+func toLoanProductResponse(product repository.LoanProduct, tenures []repository.LoanProductTenure) LoanProductResponse {
+	response := LoanProductResponse{
+		ID:             product.ID,
+		ProductCode:    product.ProductCode,
+		ProductName:    product.ProductName,
+		InterestMethod: product.InterestMethod,
+		AdminFeeAmount: product.AdminFeeAmount,
+		InsuranceRate:  product.InsuranceRate,
+		MinLoanAmount:  product.MinLoanAmount,
+		MaxLoanAmount:  product.MaxLoanAmount,
+		ValidFrom:      product.ValidFrom.Format("2006-01-02"),
+		ValidUntil:     formatOptionalDate(product.ValidUntil),
+		Tenures:        []LoanProductTenureResponse{},
+		CreatedAt:      formatOptionalTime(product.CreatedAt),
+	}
+
+	for _, tenure := range tenures {
+		response.Tenures = append(response.Tenures, LoanProductTenureResponse{
+			Tenure:       tenure.Tenure,
+			InterestRate: tenure.InterestRate,
+		})
+	}
+
+	return response
+}
+
+// getLoanProductTerms returns the terms a loan of amount over tenure is granted on by the
+// product of productCode at asOf. The product must be valid at asOf, offer the tenure and
+// lend the amount.
+func getLoanProductTerms(ctx context.Context, loanProductRepo repository.LoanProductRepository, productCode string, tenure int16, amount money.Money, asOf time.Time) (terms loanProductTerms, err error) {
+	product, err := loanProductRepo.GetLoanProductByCode(ctx, productCode)
+	if err != nil {
+		return terms, err
+	}
+	if product.ID == 0 {
+		return terms, fmt.Errorf("%w: loan product %s not found", ErrLoanProductNotOffered, productCode)
+	}
+
+	// dates are compared as written, the product dates having no time of day
+	asOfDate := asOf.Format("2006-01-02")
+	validFrom := product.ValidFrom.Format("2006-01-02")
+	if asOfDate < validFrom {
+		return terms, fmt.Errorf("%w: loan product %s is offered from %s", ErrLoanProductNotOffered, productCode, validFrom)
+	}
+	validUntil := formatOptionalDate(product.ValidUntil)
+	if validUntil != "" && asOfDate > validUntil {
+		return terms, fmt.Errorf("%w: loan product %s was offered until %s", ErrLoanProductNotOffered, productCode, validUntil)
+	}
+
+	if amount.LessThan(product.MinLoanAmount) {
+		return terms, fmt.Errorf("%w: minimum loan amount of %s is %s", ErrLoanProductNotOffered, productCode, product.MinLoanAmount)
+	}
+	if !product.MaxLoanAmount.IsZero() && amount.GreaterThan(product.MaxLoanAmount) {
+		return terms, fmt.Errorf("%w: maximum loan amount of %s is %s", ErrLoanProductNotOffered, productCode, product.MaxLoanAmount)
+	}
+
+	tenures, err := loanProductRepo.GetLoanProductTenures(ctx, []int64{product.ID})
+	if err != nil {
+		return terms, err
+	}
+
+	for _, productTenure := range tenures {
+		if productTenure.Tenure == tenure {
+			return loanProductTerms{
+				Product:        product,
+				InterestRate:   productTenure.InterestRate,
+				InterestMethod: product.InterestMethod,
+				AdminFeeAmount: product.AdminFeeAmount,
+				InsuranceRate:  product.InsuranceRate,
+			}, nil
+		}
+	}
+
+	return terms, fmt.Errorf("%w: tenure %d is not offered by %s", ErrLoanProductNotOffered, tenure, productCode)
+}
@@ -0,0 +1,338 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func loanProductRequest() usecase.LoanProductRequest {
+	return usecase.LoanProductRequest{
+		ProductCode:    "MOTOR",
+		ProductName:    "Motorcycle",
+		InterestMethod: usecase.InterestMethodAnnuity,
+		AdminFeeAmount: money.New(50000),
+		InsuranceRate:  1.5,
+		MinLoanAmount:  money.New(1000000),
+		MaxLoanAmount:  money.New(30000000),
+		ValidFrom:      "2024-01-01",
+		Tenures: []usecase.LoanProductTenureRequest{
+			{Tenure: 6, InterestRate: 12},
+			{Tenure: 12, InterestRate: 14.5},
+		},
+	}
+}
+
+func TestCreateLoanProduct(t *testing.T) {
+	var mockLoanProductRepo *mocks.LoanProductRepository
+	errDB := errors.New("db error")
+
+	tests := []struct {
+		name    string
+		request func() usecase.LoanProductRequest
+		setup   func()
+		want    usecase.LoanProductResponse
+		wantErr error
+	}{
+		{
+			name:    "success",
+			request: loanProductRequest,
+			setup: func() {
+				mockLoanProductRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanProductRepo.On("CreateLoanProduct", mock.Anything, mock.MatchedBy(func(product repository.LoanProduct) bool {
+					return product.ProductCode == "MOTOR" && product.InterestMethod == usecase.InterestMethodAnnuity && product.InsuranceRate == 1.5 &&
+						product.ValidFrom.Format("2006-01-02") == "2024-01-01" && product.ValidUntil.IsZero()
+				}), mock.Anything).Return(int64(1), nil).Once()
+				mockLoanProductRepo.On("CreateLoanProductTenures", mock.Anything, []repository.LoanProductTenure{
+					{LoanProductID: 1, Tenure: 6, InterestRate: 12},
+					{LoanProductID: 1, Tenure: 12, InterestRate: 14.5},
+				}, mock.Anything).Return(nil).Once()
+				mockLoanProductRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			want: usecase.LoanProductResponse{
+				ID:             1,
+				ProductCode:    "MOTOR",
+				ProductName:    "Motorcycle",
+				InterestMethod: usecase.InterestMethodAnnuity,
+				AdminFeeAmount: money.New(50000),
+				InsuranceRate:  1.5,
+				MinLoanAmount:  money.New(1000000),
+				MaxLoanAmount:  money.New(30000000),
+				ValidFrom:      "2024-01-01",
+				Tenures: []usecase.LoanProductTenureResponse{
+					{Tenure: 6, InterestRate: 12},
+					{Tenure: 12, InterestRate: 14.5},
+				},
+			},
+		},
+		{
+			name: "invalid interest method",
+			request: func() usecase.LoanProductRequest {
+				request := loanProductRequest()
+				request.InterestMethod = "invalid"
+				return request
+			},
+			setup:   func() {},
+			wantErr: usecase.ErrInvalidLoanProduct,
+		},
+		{
+			name: "tenure offered twice",
+			request: func() usecase.LoanProductRequest {
+				request := loanProductRequest()
+				request.Tenures = append(request.Tenures, usecase.LoanProductTenureRequest{Tenure: 6, InterestRate: 10})
+				return request
+			},
+			setup:   func() {},
+			wantErr: usecase.ErrInvalidLoanProduct,
+		},
+		{
+			name: "negative insurance rate",
+			request: func() usecase.LoanProductRequest {
+				request := loanProductRequest()
+				request.InsuranceRate = -1
+				return request
+			},
+			setup:   func() {},
+			wantErr: usecase.ErrInvalidLoanProduct,
+		},
+		{
+			name: "maximum amount below minimum amount",
+			request: func() usecase.LoanProductRequest {
+				request := loanProductRequest()
+				request.MaxLoanAmount = money.New(500000)
+				return request
+			},
+			setup:   func() {},
+			wantErr: usecase.ErrInvalidLoanProduct,
+		},
+		{
+			name: "valid until before valid from",
+			request: func() usecase.LoanProductRequest {
+				request := loanProductRequest()
+				request.ValidUntil = "2023-12-31"
+				return request
+			},
+			setup:   func() {},
+			wantErr: usecase.ErrInvalidLoanProduct,
+		},
+		{
+			name:    "duplicate product code",
+			request: loanProductRequest,
+			setup: func() {
+				mockLoanProductRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanProductRepo.On("CreateLoanProduct", mock.Anything, mock.Anything, mock.Anything).Return(int64(0), repository.ErrDuplicateLoanProductCode).Once()
+				mockLoanProductRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantErr: repository.ErrDuplicateLoanProductCode,
+		},
+		{
+			name:    "error creating tenures",
+			request: loanProductRequest,
+			setup: func() {
+				mockLoanProductRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanProductRepo.On("CreateLoanProduct", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockLoanProductRepo.On("CreateLoanProductTenures", mock.Anything, mock.Anything, mock.Anything).Return(errDB).Once()
+				mockLoanProductRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLoanProductRepo = new(mocks.LoanProductRepository)
+			uc := usecase.NewLoanProductUsecase(mockLoanProductRepo, time.Second*2)
+			tt.setup()
+
+			got, err := uc.CreateLoanProduct(context.Background(), tt.request())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			mockLoanProductRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateLoanProduct(t *testing.T) {
+	var mockLoanProductRepo *mocks.LoanProductRepository
+
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func() {
+				mockLoanProductRepo.On("GetLoanProductByID", mock.Anything, int64(1)).Return(repository.LoanProduct{ID: 1, ProductCode: "MOTOR"}, nil).Once()
+				mockLoanProductRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanProductRepo.On("UpdateLoanProduct", mock.Anything, mock.MatchedBy(func(product repository.LoanProduct) bool {
+					return product.ID == 1 && product.ProductName == "Motorcycle"
+				}), mock.Anything).Return(nil).Once()
+				mockLoanProductRepo.On("DeleteLoanProductTenures", mock.Anything, int64(1), mock.Anything).Return(nil).Once()
+				mockLoanProductRepo.On("CreateLoanProductTenures", mock.Anything, []repository.LoanProductTenure{
+					{LoanProductID: 1, Tenure: 6, InterestRate: 12},
+					{LoanProductID: 1, Tenure: 12, InterestRate: 14.5},
+				}, mock.Anything).Return(nil).Once()
+				mockLoanProductRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "loan product not found",
+			setup: func() {
+				mockLoanProductRepo.On("GetLoanProductByID", mock.Anything, int64(1)).Return(repository.LoanProduct{}, nil).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name: "error deleting tenures",
+			setup: func() {
+				mockLoanProductRepo.On("GetLoanProductByID", mock.Anything, int64(1)).Return(repository.LoanProduct{ID: 1, ProductCode: "MOTOR"}, nil).Once()
+				mockLoanProductRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanProductRepo.On("UpdateLoanProduct", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLoanProductRepo.On("DeleteLoanProductTenures", mock.Anything, int64(1), mock.Anything).Return(errors.New("db error")).Once()
+				mockLoanProductRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLoanProductRepo = new(mocks.LoanProductRepository)
+			uc := usecase.NewLoanProductUsecase(mockLoanProductRepo, time.Second*2)
+			tt.setup()
+
+			err := uc.UpdateLoanProduct(context.Background(), 1, loanProductRequest())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockLoanProductRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetLoanProductByID(t *testing.T) {
+	var mockLoanProductRepo *mocks.LoanProductRepository
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		setup   func()
+		want    usecase.LoanProductResponse
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func() {
+				mockLoanProductRepo.On("GetLoanProductByID", mock.Anything, int64(1)).Return(repository.LoanProduct{
+					ID:             1,
+					ProductCode:    "MOTOR",
+					ProductName:    "Motorcycle",
+					InterestMethod: usecase.InterestMethodFlat,
+					ValidFrom:      validFrom,
+					ValidUntil:     time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+				}, nil).Once()
+				mockLoanProductRepo.On("GetLoanProductTenures", mock.Anything, []int64{1}).Return([]repository.LoanProductTenure{
+					{ID: 1, LoanProductID: 1, Tenure: 6, InterestRate: 12},
+				}, nil).Once()
+			},
+			want: usecase.LoanProductResponse{
+				ID:             1,
+				ProductCode:    "MOTOR",
+				ProductName:    "Motorcycle",
+				InterestMethod: usecase.InterestMethodFlat,
+				ValidFrom:      "2024-01-01",
+				ValidUntil:     "2024-12-31",
+				Tenures:        []usecase.LoanProductTenureResponse{{Tenure: 6, InterestRate: 12}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			setup: func() {
+				mockLoanProductRepo.On("GetLoanProductByID", mock.Anything, int64(1)).Return(repository.LoanProduct{}, nil).Once()
+			},
+			want:    usecase.LoanProductResponse{},
+			wantErr: false,
+		},
+		{
+			name: "repository error",
+			setup: func() {
+				mockLoanProductRepo.On("GetLoanProductByID", mock.Anything, int64(1)).Return(repository.LoanProduct{}, errors.New("db error")).Once()
+			},
+			want:    usecase.LoanProductResponse{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLoanProductRepo = new(mocks.LoanProductRepository)
+			uc := usecase.NewLoanProductUsecase(mockLoanProductRepo, time.Second*2)
+			tt.setup()
+
+			got, err := uc.GetLoanProductByID(context.Background(), 1)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			mockLoanProductRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFetchLoanProducts(t *testing.T) {
+	mockLoanProductRepo := new(mocks.LoanProductRepository)
+	uc := usecase.NewLoanProductUsecase(mockLoanProductRepo, time.Second*2)
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockLoanProductRepo.On("FetchLoanProducts", mock.Anything, repository.FetchLoanProductsRequest{Limit: 10, Offset: 10}).Return([]repository.LoanProduct{
+		{ID: 1, ProductCode: "MOTOR", InterestMethod: usecase.InterestMethodFlat, ValidFrom: validFrom},
+		{ID: 2, ProductCode: "CAR", InterestMethod: usecase.InterestMethodAnnuity, ValidFrom: validFrom},
+	}, nil).Once()
+	mockLoanProductRepo.On("GetLoanProductTenures", mock.Anything, []int64{1, 2}).Return([]repository.LoanProductTenure{
+		{ID: 1, LoanProductID: 1, Tenure: 6, InterestRate: 12},
+		{ID: 2, LoanProductID: 2, Tenure: 12, InterestRate: 10},
+		{ID: 3, LoanProductID: 2, Tenure: 24, InterestRate: 11},
+	}, nil).Once()
+
+	got, err := uc.FetchLoanProducts(context.Background(), usecase.FetchLoanProductsRequest{Page: 2, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []usecase.LoanProductResponse{
+		{
+			ID:             1,
+			ProductCode:    "MOTOR",
+			InterestMethod: usecase.InterestMethodFlat,
+			ValidFrom:      "2024-01-01",
+			Tenures:        []usecase.LoanProductTenureResponse{{Tenure: 6, InterestRate: 12}},
+		},
+		{
+			ID:             2,
+			ProductCode:    "CAR",
+			InterestMethod: usecase.InterestMethodAnnuity,
+			ValidFrom:      "2024-01-01",
+			Tenures: []usecase.LoanProductTenureResponse{
+				{Tenure: 12, InterestRate: 10},
+				{Tenure: 24, InterestRate: 11},
+			},
+		},
+	}, got)
+	mockLoanProductRepo.AssertExpectations(t)
+}
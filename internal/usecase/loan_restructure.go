@@ -0,0 +1,452 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+const (
+	RestructureTypeExtendTenure      = "extend_tenure"
+	RestructureTypeReduceInstallment = "reduce_installment"
+	RestructureTypeCapitalizeArrears = "capitalize_arrears"
+)
+
+var (
+	// loanStatusRestructurable lists the statuses of loans that are being repaid and so
+	// can be restructured.
+	loanStatusRestructurable = map[string]bool{
+		LoanStatusDisbursed: true,
+		LoanStatusOnGoing:   true,
+		LoanStatusLate:      true,
+	}
+)
+
+type (
+	RestructureLoanRequest struct {
+		LoanID            int64       `json:"-"`
+		RestructureType   string      `json:"restructure_type"`
+		Tenure            int16       `json:"tenure"`
+		InstallmentAmount money.Money `json:"installment_amount"`
+		Reason            string      `json:"reason"`
+	}
+
+	LoanRestructureResponse struct {
+		ID                          int64       `json:"id"`
+		LoanID                      int64       `json:"loan_id"`
+		ContractNumber              string      `json:"contract_number"`
+		RestructureType             string      `json:"restructure_type"`
+		PreviousTenure              int16       `json:"previous_tenure"`
+		Tenure                      int16       `json:"tenure"`
+		PreviousInstallmentAmount   money.Money `json:"previous_installment_amount"`
+		InstallmentAmount           money.Money `json:"installment_amount"`
+		RestructuredPrincipalAmount money.Money `json:"restructured_principal_amount"`
+		CapitalizedAmount           money.Money `json:"capitalized_amount"`
+		PreviousLoanAmount          money.Money `json:"previous_loan_amount"`
+		LoanAmount                  money.Money `json:"loan_amount"`
+		PreviousInterestAmount      money.Money `json:"previous_interest_amount"`
+		InterestAmount              money.Money `json:"interest_amount"`
+		PreviousDueDate             string      `json:"previous_due_date"`
+		DueDate                     string      `json:"due_date"`
+		Reason                      string      `json:"reason"`
+		CreatedAt                   string      `json:"created_at,omitempty"`
+	}
+)
+
+// RestructureLoan closes the remaining installments of a loan and replaces them with a new
+// schedule under the same contract number. extend_tenure and reduce_installment reschedule
+// the installments not yet due and leave arrears payable as they are, capitalize_arrears
+// also folds the overdue installments into the new principal. The new tenure is always one
+// of the consumer's limit tenures.
+func (uc *loanUsecase) RestructureLoan(ctx context.Context, req RestructureLoanRequest) (response LoanRestructureResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	if !repository.ValidRestructureType[req.RestructureType] {
+		return response, errors.New("restructure_type must be extend_tenure, reduce_installment or capitalize_arrears")
+	}
+
+	tx, err := uc.loanRepo.BeginTx(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	// the loan row stays locked until the restructure is committed, like payments lock it,
+	// so no payment is allocated against the schedule being replaced. The schedule is read
+	// after the lock is taken.
+	loan, err := uc.lockLoan(ctx, req.LoanID, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	restructure, err := uc.restructureLoan(ctx, req, loan, tx)
+	if err != nil {
+		uc.loanRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	err = uc.loanRepo.CommitTx(ctx, tx)
+	if err != nil {
+		return response, err
+	}
+
+	return newLoanRestructureResponse(restructure), nil
+}
+
+// restructureLoan restructures a loan locked in tx, see RestructureLoan.
+func (uc *loanUsecase) restructureLoan(ctx context.Context, req RestructureLoanRequest, loan repository.Loan, tx *sql.Tx) (restructure repository.LoanRestructure, err error) {
+	if !loanStatusRestructurable[loan.LoanStatus] {
+		return restructure, fmt.Errorf("loan with status %s cannot be restructured", loan.LoanStatus)
+	}
+
+	currentLimit, err := uc.consumerLimitRepo.GetConsumerLimitByID(ctx, loan.ConsumerLimitID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][RestructureLoan] while get consumer limit by ID, Err: %+v", err))
+		return restructure, err
+	}
+	if currentLimit.ID == 0 {
+		return restructure, errors.New("consumer limit not found")
+	}
+
+	schedule, err := uc.loanInstallmentRepo.GetLoanInstallmentsByLoanID(ctx, loan.ID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][RestructureLoan] while get loan installments, Err: %+v", err))
+		return restructure, err
+	}
+
+	var unsavedSchedule []repository.LoanInstallment
+	if len(schedule) == 0 {
+		// loans booked before schedules were persisted have no rows yet
+		schedule, err = rebuildInstallmentSchedule(loan, currentLimit.Tenure)
+		if err != nil {
+			return restructure, err
+		}
+		unsavedSchedule = schedule
+	}
+
+	now := time.Now()
+	var (
+		closed                    []repository.LoanInstallment
+		closedIDs                 []int64
+		lastInstallmentNumber     int32
+		principalAmount           money.Money
+		closedInterestAmount      money.Money
+		capitalizedAmount         money.Money
+		previousInstallmentAmount money.Money
+	)
+
+	for i, installment := range schedule {
+		if installment.InstallmentNumber > lastInstallmentNumber {
+			lastInstallmentNumber = installment.InstallmentNumber
+		}
+
+		if isInstallmentClosed(installment.InstallmentStatus) {
+			continue
+		}
+
+		outstandingPrincipal := installment.PrincipalAmount.Sub(installment.PaidPrincipalAmount)
+		outstandingInterest := installment.InterestAmount.Sub(installment.PaidInterestAmount)
+		if daysBetween(installment.DueDate, now) > 0 {
+			if req.RestructureType != RestructureTypeCapitalizeArrears {
+				continue
+			}
+			capitalizedAmount = capitalizedAmount.Add(outstandingPrincipal).Add(outstandingInterest)
+			principalAmount = principalAmount.Add(outstandingInterest)
+		}
+
+		previousInstallmentAmount = money.Max(previousInstallmentAmount, installment.PrincipalAmount.Add(installment.InterestAmount))
+		principalAmount = principalAmount.Add(outstandingPrincipal)
+		closedInterestAmount = closedInterestAmount.Add(outstandingInterest)
+		closed = append(closed, installment)
+		closedIDs = append(closedIDs, installment.ID)
+		schedule[i].InstallmentStatus = "restructured"
+	}
+
+	if len(closed) == 0 {
+		return restructure, errors.New("loan has no installment left to restructure")
+	}
+
+	if req.RestructureType == RestructureTypeCapitalizeArrears && !capitalizedAmount.IsPositive() {
+		return restructure, errors.New("loan has no arrears to capitalize")
+	}
+
+	calculator, err := NewInterestCalculator(loan.InterestMethod)
+	if err != nil {
+		return restructure, err
+	}
+
+	consumerLimit, amounts, err := uc.restructureTerms(ctx, req, loan, currentLimit, calculator, principalAmount, previousInstallmentAmount, len(closed))
+	if err != nil {
+		return restructure, err
+	}
+
+	// the limit row is locked after the loan, as loan applications lock it, so restructures
+	// and applications against the same limit are checked one after the other. The loans
+	// are read after the lock is taken.
+	consumerLimit, err = uc.consumerLimitRepo.LockLimitByTenureAndConsumerID(ctx, consumerLimit.Tenure, loan.ConsumerID, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][RestructureLoan] while lock consumer limit by tenure and consumer ID, Err: %+v", err))
+		return restructure, err
+	}
+	if consumerLimit.ID == 0 {
+		return restructure, errors.New("consumer limit not found")
+	}
+
+	loans, err := uc.loanRepo.GetLoanByConsumerID(ctx, loan.ConsumerID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][RestructureLoan] while get loan by consumer ID, Err: %+v", err))
+		return restructure, err
+	}
+
+	// capitalized interest adds to the loan amount, rescheduled principal is lent again
+	loanAmount := loan.LoanAmount.Add(principalAmount).Sub(sumOutstandingPrincipal(closed))
+	remainingLimit := consumerLimit.LimitAmount.Sub(usedLimitAmount(loans, consumerLimit.ID, loan.ID))
+	if loanAmount.Sub(loan.PaidLoanAmount).GreaterThan(remainingLimit) {
+		errMsg := fmt.Sprintf("remaining limit: %s", remainingLimit)
+		return restructure, errors.New(errMsg)
+	}
+
+	installments := buildInstallmentSchedule(loan.ID, amounts, now)
+	for i := range installments {
+		installments[i].InstallmentNumber += lastInstallmentNumber
+	}
+
+	loanStatus := loan.LoanStatus
+	daysPastDue := loan.DaysPastDue
+	if req.RestructureType == RestructureTypeCapitalizeArrears {
+		// the arrears are part of the new schedule, nothing is overdue anymore
+		daysPastDue = 0
+		if loanStatus == LoanStatusLate {
+			loanStatus = LoanStatusOnGoing
+		}
+	}
+
+	restructure = repository.LoanRestructure{
+		LoanID:                      loan.ID,
+		ContractNumber:              loan.ContractNumber,
+		RestructureType:             req.RestructureType,
+		PreviousConsumerLimitID:     currentLimit.ID,
+		ConsumerLimitID:             consumerLimit.ID,
+		PreviousTenure:              currentLimit.Tenure,
+		Tenure:                      consumerLimit.Tenure,
+		PreviousInstallmentAmount:   previousInstallmentAmount,
+		InstallmentAmount:           maxInstallmentAmount(amounts),
+		RestructuredPrincipalAmount: principalAmount,
+		CapitalizedAmount:           capitalizedAmount,
+		PreviousLoanAmount:          loan.LoanAmount,
+		LoanAmount:                  loanAmount,
+		PreviousInterestAmount:      loan.InterestAmount,
+		InterestAmount:              loan.InterestAmount.Sub(closedInterestAmount).Add(TotalInterest(amounts)),
+		PreviousDueDate:             loan.DueDate,
+		DueDate:                     installments[len(installments)-1].DueDate,
+		Reason:                      req.Reason,
+	}
+
+	if len(unsavedSchedule) > 0 {
+		err = uc.loanInstallmentRepo.CreateLoanInstallments(ctx, unsavedSchedule, tx)
+	} else {
+		err = uc.loanInstallmentRepo.CloseLoanInstallments(ctx, closedIDs, tx)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][RestructureLoan] while close loan installments, Err: %+v", err))
+		return restructure, err
+	}
+
+	restructureID, err := uc.loanRestructureRepo.CreateLoanRestructure(ctx, restructure, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][RestructureLoan] while create loan restructure, Err: %+v", err))
+		return restructure, err
+	}
+
+	for i := range installments {
+		installments[i].LoanRestructureID = restructureID
+	}
+
+	err = uc.loanInstallmentRepo.CreateLoanInstallments(ctx, installments, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][RestructureLoan] while create loan installments, Err: %+v", err))
+		return restructure, err
+	}
+
+	err = uc.loanRepo.RestructureLoan(ctx, repository.RestructureLoanRequest{
+		ID:              loan.ID,
+		ConsumerLimitID: consumerLimit.ID,
+		LoanAmount:      restructure.LoanAmount,
+		InterestAmount:  restructure.InterestAmount,
+		LoanStatus:      loanStatus,
+		DaysPastDue:     daysPastDue,
+		DueDate:         restructure.DueDate,
+	}, tx)
+	if err != nil {
+		return restructure, err
+	}
+
+	// capitalized interest is earned and becomes principal owed by the consumer
+	capitalizedInterest := restructure.LoanAmount.Sub(restructure.PreviousLoanAmount)
+	err = postJournalEntry(ctx, uc.ledgerRepo, repository.JournalEntry{
+		ReferenceType: JournalReferenceLoanRestructure,
+		ReferenceID:   restructureID,
+		Description:   fmt.Sprintf("Interest capitalized by restructure of loan %s", loan.ContractNumber),
+		PostedAt:      now,
+		Lines: []repository.JournalLine{
+			debitLine(LedgerAccountLoanReceivable, capitalizedInterest),
+			creditLine(LedgerAccountInterestIncome, capitalizedInterest),
+		},
+	}, tx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][RestructureLoan] while post journal entry, Err: %+v", err))
+		return restructure, err
+	}
+
+	restructure.ID = restructureID
+	return restructure, nil
+}
+
+func (uc *loanUsecase) GetLoanRestructures(ctx context.Context, loanID int64) (response []LoanRestructureResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	loan, err := uc.loanRepo.GetLoanByID(ctx, loanID)
+	if err != nil {
+		return response, err
+	}
+	if loan.ID == 0 {
+		return response, errors.New("loan not found")
+	}
+
+	restructures, err := uc.loanRestructureRepo.GetLoanRestructuresByLoanID(ctx, loanID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][GetLoanRestructures] while get loan restructures, Err: %+v", err))
+		return response, err
+	}
+
+	response = make([]LoanRestructureResponse, 0, len(restructures))
+	for _, restructure := range restructures {
+		response = append(response, newLoanRestructureResponse(restructure))
+	}
+
+	return response, nil
+}
+
+// restructureTerms picks the consumer limit, and with it the tenure, of a restructured loan
+// and calculates its new installments.
+func (uc *loanUsecase) restructureTerms(
+	ctx context.Context,
+	req RestructureLoanRequest,
+	loan repository.Loan,
+	currentLimit repository.ConsumerLimit,
+	calculator InterestCalculator,
+	principalAmount money.Money,
+	previousInstallmentAmount money.Money,
+	remainingInstallments int,
+) (consumerLimit repository.ConsumerLimit, amounts []InstallmentAmount, err error) {
+	switch req.RestructureType {
+	case RestructureTypeReduceInstallment:
+		if !req.InstallmentAmount.IsPositive() {
+			return consumerLimit, amounts, errors.New("installment_amount is required for reduce_installment")
+		}
+
+		if !req.InstallmentAmount.LessThan(previousInstallmentAmount) {
+			return consumerLimit, amounts, fmt.Errorf("installment_amount must be lower than the current installment of %s", previousInstallmentAmount)
+		}
+
+		limits, err := uc.consumerLimitRepo.GetConsumerLimitByConsumerID(ctx, loan.ConsumerID)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[LoanUsecase][RestructureLoan] while get consumer limit by consumer ID, Err: %+v", err))
+			return consumerLimit, amounts, err
+		}
+
+		// the shortest tenure that brings the installment down keeps the interest lowest
+		sort.Slice(limits, func(i, j int) bool {
+			return limits[i].Tenure < limits[j].Tenure
+		})
+
+		for _, limit := range limits {
+			if limit.Tenure <= 0 {
+				continue
+			}
+
+			amounts = calculator.Calculate(principalAmount, loan.InterestRate, limit.Tenure)
+			if !maxInstallmentAmount(amounts).GreaterThan(req.InstallmentAmount) {
+				return limit, amounts, nil
+			}
+		}
+
+		return consumerLimit, nil, fmt.Errorf("no consumer limit tenure brings the installment down to %s", req.InstallmentAmount)
+	case RestructureTypeExtendTenure:
+		if req.Tenure <= 0 {
+			return consumerLimit, amounts, errors.New("tenure is required for extend_tenure")
+		}
+
+		if int(req.Tenure) <= remainingInstallments {
+			return consumerLimit, amounts, fmt.Errorf("tenure must be longer than the %d remaining installments", remainingInstallments)
+		}
+	}
+
+	consumerLimit = currentLimit
+	if req.Tenure > 0 && req.Tenure != currentLimit.Tenure {
+		consumerLimit, err = uc.consumerLimitRepo.GetLimitByTenureAndConsumerID(ctx, req.Tenure, loan.ConsumerID)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[LoanUsecase][RestructureLoan] while get consumer limit by tenure and consumer ID, Err: %+v", err))
+			return consumerLimit, amounts, err
+		}
+		if consumerLimit.ID == 0 {
+			return consumerLimit, amounts, fmt.Errorf("consumer limit not found for tenure %d", req.Tenure)
+		}
+	}
+
+	amounts = calculator.Calculate(principalAmount, loan.InterestRate, consumerLimit.Tenure)
+	return consumerLimit, amounts, nil
+}
+
+func newLoanRestructureResponse(restructure repository.LoanRestructure) LoanRestructureResponse {
+	return LoanRestructureResponse{
+		ID:                          restructure.ID,
+		LoanID:                      restructure.LoanID,
+		ContractNumber:              restructure.ContractNumber,
+		RestructureType:             restructure.RestructureType,
+		PreviousTenure:              restructure.PreviousTenure,
+		Tenure:                      restructure.Tenure,
+		PreviousInstallmentAmount:   restructure.PreviousInstallmentAmount,
+		InstallmentAmount:           restructure.InstallmentAmount,
+		RestructuredPrincipalAmount: restructure.RestructuredPrincipalAmount,
+		CapitalizedAmount:           restructure.CapitalizedAmount,
+		PreviousLoanAmount:          restructure.PreviousLoanAmount,
+		LoanAmount:                  restructure.LoanAmount,
+		PreviousInterestAmount:      restructure.PreviousInterestAmount,
+		InterestAmount:              restructure.InterestAmount,
+		PreviousDueDate:             restructure.PreviousDueDate.Format("2006-01-02"),
+		DueDate:                     restructure.DueDate.Format("2006-01-02"),
+		Reason:                      restructure.Reason,
+		CreatedAt:                   formatOptionalTime(restructure.CreatedAt),
+	}
+}
+
+// maxInstallmentAmount returns the largest installment of a schedule, which is the first
+// one for declining balance loans and the last one, which absorbs the rounding remainder,
+// for the others.
+func maxInstallmentAmount(amounts []InstallmentAmount) money.Money {
+	var largest money.Money
+	for _, amount := range amounts {
+		largest = money.Max(largest, amount.Principal.Add(amount.Interest))
+	}
+
+	return largest
+}
+
+func sumOutstandingPrincipal(installments []repository.LoanInstallment) money.Money {
+	var total money.Money
+	for _, installment := range installments {
+		total = total.Add(installment.PrincipalAmount.Sub(installment.PaidPrincipalAmount))
+	}
+
+	return total
+}
@@ -0,0 +1,303 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRestructureLoan(t *testing.T) {
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, new(mocks.LoanProductRepository), mockLedgerRepo, new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
+
+	now := time.Now()
+	loan := repository.Loan{
+		ID:                 1,
+		ConsumerID:         1,
+		ConsumerLimitID:    1,
+		ContractNumber:     "123",
+		LoanAmount:         money.New(300),
+		PaidLoanAmount:     money.New(100),
+		InterestRate:       10,
+		InterestMethod:     usecase.InterestMethodFlat,
+		InterestAmount:     money.New(30),
+		PaidInterestAmount: money.New(10),
+		LoanStatus:         usecase.LoanStatusOnGoing,
+		DueDate:            now.AddDate(0, 0, 40),
+	}
+	schedule := func(secondDueDate time.Time) []repository.LoanInstallment {
+		return []repository.LoanInstallment{
+			{ID: 1, LoanID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), DueDate: now.AddDate(0, 0, -20), InstallmentStatus: "paid"},
+			{ID: 2, LoanID: 1, InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: secondDueDate, InstallmentStatus: "unpaid"},
+			{ID: 3, LoanID: 1, InstallmentNumber: 3, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: now.AddDate(0, 0, 40), InstallmentStatus: "unpaid"},
+		}
+	}
+
+	t.Run("invalid restructure type", func(t *testing.T) {
+		_, err := uc.RestructureLoan(context.Background(), usecase.RestructureLoanRequest{LoanID: 1, RestructureType: "waive"})
+		assert.Error(t, err)
+	})
+
+	t.Run("loan not being repaid", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, LoanStatus: usecase.LoanStatusFinish}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := uc.RestructureLoan(context.Background(), usecase.RestructureLoanRequest{LoanID: 1, RestructureType: usecase.RestructureTypeExtendTenure, Tenure: 6})
+		assert.Error(t, err)
+		assert.Equal(t, "loan with status finish cannot be restructured", err.Error())
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("extend tenure not longer than remaining installments", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(loan, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(1000)}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return(schedule(now.AddDate(0, 0, 10)), nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := uc.RestructureLoan(context.Background(), usecase.RestructureLoanRequest{LoanID: 1, RestructureType: usecase.RestructureTypeExtendTenure, Tenure: 2})
+		assert.Error(t, err)
+		assert.Equal(t, "tenure must be longer than the 2 remaining installments", err.Error())
+	})
+
+	t.Run("extend tenure without consumer limit for tenure", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(loan, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(1000)}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return(schedule(now.AddDate(0, 0, 10)), nil).Once()
+		mockConsumerLimitRepo.On("GetLimitByTenureAndConsumerID", mock.Anything, int16(9), int64(1)).Return(repository.ConsumerLimit{}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := uc.RestructureLoan(context.Background(), usecase.RestructureLoanRequest{LoanID: 1, RestructureType: usecase.RestructureTypeExtendTenure, Tenure: 9})
+		assert.Error(t, err)
+		assert.Equal(t, "consumer limit not found for tenure 9", err.Error())
+	})
+
+	t.Run("extend tenure", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(loan, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(1000)}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return(schedule(now.AddDate(0, 0, 10)), nil).Once()
+		mockConsumerLimitRepo.On("GetLimitByTenureAndConsumerID", mock.Anything, int16(6), int64(1)).Return(repository.ConsumerLimit{ID: 2, ConsumerID: 1, Tenure: 6, LimitAmount: money.New(1000)}, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, int16(6), int64(1), mock.Anything).Return(repository.ConsumerLimit{ID: 2, ConsumerID: 1, Tenure: 6, LimitAmount: money.New(1000)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, int64(1)).Return([]repository.Loan{loan}, nil).Once()
+		mockLoanInstallmentRepo.On("CloseLoanInstallments", mock.Anything, []int64{2, 3}, mock.Anything).Return(nil).Once()
+		mockLoanRestructureRepo.On("CreateLoanRestructure", mock.Anything, mock.MatchedBy(func(restructure repository.LoanRestructure) bool {
+			return restructure.ContractNumber == "123" &&
+				restructure.PreviousConsumerLimitID == 1 && restructure.ConsumerLimitID == 2 &&
+				restructure.PreviousTenure == 3 && restructure.Tenure == 6 &&
+				restructure.PreviousInstallmentAmount == money.New(110) && restructure.Reason == "lost job"
+		}), mock.Anything).Return(int64(7), nil).Once()
+		mockLoanInstallmentRepo.On("CreateLoanInstallments", mock.Anything, mock.MatchedBy(func(installments []repository.LoanInstallment) bool {
+			return len(installments) == 6 &&
+				installments[0].InstallmentNumber == 4 && installments[5].InstallmentNumber == 9 &&
+				installments[0].LoanRestructureID == 7 && installments[0].InstallmentStatus == "unpaid"
+		}), mock.Anything).Return(nil).Once()
+		mockLoanRepo.On("RestructureLoan", mock.Anything, mock.MatchedBy(func(req repository.RestructureLoanRequest) bool {
+			return req.ID == 1 && req.ConsumerLimitID == 2 && req.LoanAmount == money.New(300) && req.InterestAmount == money.New(30) &&
+				req.LoanStatus == usecase.LoanStatusOnGoing
+		}), mock.Anything).Return(nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := uc.RestructureLoan(context.Background(), usecase.RestructureLoanRequest{
+			LoanID:          1,
+			RestructureType: usecase.RestructureTypeExtendTenure,
+			Tenure:          6,
+			Reason:          "lost job",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), resp.ID)
+		assert.Equal(t, int16(6), resp.Tenure)
+		assert.Equal(t, money.New(200), resp.RestructuredPrincipalAmount)
+		assert.Equal(t, money.New(40), resp.InstallmentAmount)
+		assert.Equal(t, money.New(300), resp.LoanAmount)
+		mockLoanRepo.AssertExpectations(t)
+		mockLoanInstallmentRepo.AssertExpectations(t)
+		mockLoanRestructureRepo.AssertExpectations(t)
+		mockConsumerLimitRepo.AssertExpectations(t)
+	})
+
+	t.Run("reduce installment picks the shortest tenure that fits", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(loan, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(1000)}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return(schedule(now.AddDate(0, 0, 10)), nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByConsumerID", mock.Anything, int64(1)).Return([]repository.ConsumerLimit{
+			{ID: 3, ConsumerID: 1, Tenure: 12, LimitAmount: money.New(1000)},
+			{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(1000)},
+			{ID: 2, ConsumerID: 1, Tenure: 6, LimitAmount: money.New(1000)},
+		}, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, int16(6), int64(1), mock.Anything).Return(repository.ConsumerLimit{ID: 2, ConsumerID: 1, Tenure: 6, LimitAmount: money.New(1000)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, int64(1)).Return([]repository.Loan{loan}, nil).Once()
+		mockLoanInstallmentRepo.On("CloseLoanInstallments", mock.Anything, []int64{2, 3}, mock.Anything).Return(nil).Once()
+		mockLoanRestructureRepo.On("CreateLoanRestructure", mock.Anything, mock.Anything, mock.Anything).Return(int64(8), nil).Once()
+		mockLoanInstallmentRepo.On("CreateLoanInstallments", mock.Anything, mock.MatchedBy(func(installments []repository.LoanInstallment) bool {
+			return len(installments) == 6
+		}), mock.Anything).Return(nil).Once()
+		mockLoanRepo.On("RestructureLoan", mock.Anything, mock.MatchedBy(func(req repository.RestructureLoanRequest) bool {
+			return req.ConsumerLimitID == 2
+		}), mock.Anything).Return(nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := uc.RestructureLoan(context.Background(), usecase.RestructureLoanRequest{
+			LoanID:            1,
+			RestructureType:   usecase.RestructureTypeReduceInstallment,
+			InstallmentAmount: money.New(50),
+			Reason:            "lost job",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int16(6), resp.Tenure)
+		assert.Equal(t, money.New(110), resp.PreviousInstallmentAmount)
+		assert.Equal(t, money.New(40), resp.InstallmentAmount)
+		mockLoanRepo.AssertExpectations(t)
+		mockConsumerLimitRepo.AssertExpectations(t)
+	})
+
+	t.Run("reduce installment not lower than current installment", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(loan, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(1000)}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return(schedule(now.AddDate(0, 0, 10)), nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := uc.RestructureLoan(context.Background(), usecase.RestructureLoanRequest{
+			LoanID:            1,
+			RestructureType:   usecase.RestructureTypeReduceInstallment,
+			InstallmentAmount: money.New(120),
+		})
+		assert.Error(t, err)
+		assert.Equal(t, "installment_amount must be lower than the current installment of 110", err.Error())
+	})
+
+	t.Run("capitalize arrears", func(t *testing.T) {
+		lateLoan := loan
+		lateLoan.LoanStatus = usecase.LoanStatusLate
+		lateLoan.DaysPastDue = 5
+
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(lateLoan, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(1000)}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return(schedule(now.AddDate(0, 0, -5)), nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, int16(3), int64(1), mock.Anything).Return(repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(1000)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, int64(1)).Return([]repository.Loan{lateLoan}, nil).Once()
+		mockLoanInstallmentRepo.On("CloseLoanInstallments", mock.Anything, []int64{2, 3}, mock.Anything).Return(nil).Once()
+		mockLoanRestructureRepo.On("CreateLoanRestructure", mock.Anything, mock.Anything, mock.Anything).Return(int64(9), nil).Once()
+		mockLoanInstallmentRepo.On("CreateLoanInstallments", mock.Anything, mock.MatchedBy(func(installments []repository.LoanInstallment) bool {
+			return len(installments) == 3 && installments[0].PrincipalAmount == money.New(70)
+		}), mock.Anything).Return(nil).Once()
+		mockLoanRepo.On("RestructureLoan", mock.Anything, mock.MatchedBy(func(req repository.RestructureLoanRequest) bool {
+			return req.ConsumerLimitID == 1 && req.LoanAmount == money.New(310) && req.InterestAmount == money.New(31) &&
+				req.LoanStatus == usecase.LoanStatusOnGoing && req.DaysPastDue == 0
+		}), mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceType == usecase.JournalReferenceLoanRestructure && entry.ReferenceID == 9 && assert.ObjectsAreEqual([]repository.JournalLine{
+				{AccountCode: usecase.LedgerAccountLoanReceivable, DebitAmount: money.New(10)},
+				{AccountCode: usecase.LedgerAccountInterestIncome, CreditAmount: money.New(10)},
+			}, entry.Lines)
+		}), mock.Anything).Return(int64(1), nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := uc.RestructureLoan(context.Background(), usecase.RestructureLoanRequest{
+			LoanID:          1,
+			RestructureType: usecase.RestructureTypeCapitalizeArrears,
+			Reason:          "lost job",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, money.New(110), resp.CapitalizedAmount)
+		assert.Equal(t, money.New(210), resp.RestructuredPrincipalAmount)
+		assert.Equal(t, money.New(310), resp.LoanAmount)
+		assert.Equal(t, money.New(31), resp.InterestAmount)
+		mockLoanRepo.AssertExpectations(t)
+		mockLoanInstallmentRepo.AssertExpectations(t)
+	})
+
+	t.Run("capitalize arrears without arrears", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(loan, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(1000)}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return(schedule(now.AddDate(0, 0, 10)), nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := uc.RestructureLoan(context.Background(), usecase.RestructureLoanRequest{LoanID: 1, RestructureType: usecase.RestructureTypeCapitalizeArrears})
+		assert.Error(t, err)
+		assert.Equal(t, "loan has no arrears to capitalize", err.Error())
+	})
+
+	t.Run("capitalize arrears above remaining limit", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(loan, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(500)}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return(schedule(now.AddDate(0, 0, -5)), nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, int16(3), int64(1), mock.Anything).Return(repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 3, LimitAmount: money.New(500)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, int64(1)).Return([]repository.Loan{
+			loan,
+			{ID: 2, ConsumerLimitID: 1, LoanAmount: money.New(300), LoanStatus: usecase.LoanStatusOnGoing},
+		}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := uc.RestructureLoan(context.Background(), usecase.RestructureLoanRequest{LoanID: 1, RestructureType: usecase.RestructureTypeCapitalizeArrears})
+		assert.Error(t, err)
+		assert.Equal(t, "remaining limit: 200", err.Error())
+		mockLoanRepo.AssertExpectations(t)
+		mockConsumerLimitRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetLoanRestructures(t *testing.T) {
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, new(mocks.LoanProductRepository), mockLedgerRepo, new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
+
+	t.Run("success", func(t *testing.T) {
+		dueDate := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1}, nil).Once()
+		mockLoanRestructureRepo.On("GetLoanRestructuresByLoanID", mock.Anything, int64(1)).Return([]repository.LoanRestructure{
+			{ID: 1, LoanID: 1, ContractNumber: "123", RestructureType: usecase.RestructureTypeExtendTenure, PreviousTenure: 3, Tenure: 6, DueDate: dueDate},
+		}, nil).Once()
+
+		resp, err := uc.GetLoanRestructures(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, resp, 1)
+		assert.Equal(t, "2024-09-01", resp[0].DueDate)
+		assert.Equal(t, int16(6), resp[0].Tenure)
+	})
+
+	t.Run("loan not found", func(t *testing.T) {
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(2)).Return(repository.Loan{}, nil).Once()
+
+		_, err := uc.GetLoanRestructures(context.Background(), 2)
+		assert.Error(t, err)
+		assert.Equal(t, "loan not found", err.Error())
+	})
+}
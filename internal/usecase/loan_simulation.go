@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+type (
+	// SimulateLoanRequest is a loan application priced for every tenure the consumer has a
+	// limit for.
+	SimulateLoanRequest struct {
+		ConsumerID        int64       `json:"consumer_id"`
+		MerchantID        int64       `json:"merchant_id"`
+		ProductCode       string      `json:"product_code"`
+		AssetPrice        money.Money `json:"asset_price"`
+		DownPaymentAmount money.Money `json:"down_payment_amount"`
+		FeePayment        string      `json:"fee_payment"`
+		Insured           bool        `json:"insured"`
+	}
+
+	// LoanSimulationTenureResponse is the loan a consumer would get over a tenure.
+	// InstallmentAmount is the first installment, the last one absorbs the rounding and
+	// declining balance installments decrease.
+	// RemainingLimit is what would be left of the limit after the loan, negative when the
+	// loan exceeds it. A loan not accepted has the reason it would be refused.
+	LoanSimulationTenureResponse struct {
+		Tenure            int16       `json:"tenure"`
+		InterestRate      float64     `json:"interest_rate"`
+		InterestMethod    string      `json:"interest_method"`
+		LoanAmount        money.Money `json:"loan_amount"`
+		InstallmentAmount money.Money `json:"installment_amount"`
+		InterestAmount    money.Money `json:"interest_amount"`
+		AdminFeeAmount    money.Money `json:"admin_fee_amount"`
+		InsuranceAmount   money.Money `json:"insurance_amount"`
+		UpfrontAmount     money.Money `json:"upfront_amount"`
+		AvailableLimit    money.Money `json:"available_limit"`
+		RemainingLimit    money.Money `json:"remaining_limit"`
+		Accepted          bool        `json:"accepted"`
+		Reason            string      `json:"reason,omitempty"`
+	}
+
+	LoanSimulationResponse struct {
+		ConsumerID        int64                          `json:"consumer_id"`
+		ProductCode       string                         `json:"product_code"`
+		AssetPrice        money.Money                    `json:"asset_price"`
+		DownPaymentAmount money.Money                    `json:"down_payment_amount"`
+		FeePayment        string                         `json:"fee_payment"`
+		Tenures           []LoanSimulationTenureResponse `json:"tenures"`
+	}
+)
+
+// SimulateLoan prices an application the way CreateLoan would for each tenure the consumer
+// has a limit for, without booking anything. A tenure the product does not offer or the
+// limit does not cover is returned as not accepted.
+func (uc *loanUsecase) SimulateLoan(ctx context.Context, req SimulateLoanRequest) (response LoanSimulationResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	if req.FeePayment == "" {
+		req.FeePayment = FeePaymentUpfront
+	}
+	if req.DownPaymentAmount.IsNegative() || !req.DownPaymentAmount.LessThan(req.AssetPrice) {
+		return response, errors.New("down payment must be less than the asset price")
+	}
+
+	err = uc.checkLoanApplicant(ctx, req.ConsumerID, req.MerchantID)
+	if err != nil {
+		return response, err
+	}
+
+	consumerLimits, err := uc.consumerLimitRepo.GetConsumerLimitByConsumerID(ctx, req.ConsumerID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][SimulateLoan] while get consumer limit by consumer ID, Err: %+v", err))
+		return response, err
+	}
+	sort.Slice(consumerLimits, func(i, j int) bool {
+		return consumerLimits[i].Tenure < consumerLimits[j].Tenure
+	})
+
+	loans, err := uc.loanRepo.GetLoanByConsumerID(ctx, req.ConsumerID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[LoanUsecase][SimulateLoan] while get loan by consumer ID, Err: %+v", err))
+		return response, err
+	}
+
+	response = LoanSimulationResponse{
+		ConsumerID:        req.ConsumerID,
+		ProductCode:       req.ProductCode,
+		AssetPrice:        req.AssetPrice,
+		DownPaymentAmount: req.DownPaymentAmount,
+		FeePayment:        req.FeePayment,
+		Tenures:           make([]LoanSimulationTenureResponse, 0, len(consumerLimits)),
+	}
+
+	now := time.Now()
+	for _, consumerLimit := range consumerLimits {
+		simulation := LoanSimulationTenureResponse{
+			Tenure:         consumerLimit.Tenure,
+			AvailableLimit: consumerLimit.LimitAmount.Sub(usedLimitAmount(loans, consumerLimit.ID, 0)),
+		}
+
+		quote, err := uc.quoteLoan(ctx, CreateLoanRequest{
+			ConsumerID:        req.ConsumerID,
+			MerchantID:        req.MerchantID,
+			ProductCode:       req.ProductCode,
+			Tenure:            consumerLimit.Tenure,
+			AssetPrice:        req.AssetPrice,
+			DownPaymentAmount: req.DownPaymentAmount,
+			FeePayment:        req.FeePayment,
+			Insured:           req.Insured,
+		}, now)
+		if err != nil {
+			if !errors.Is(err, ErrLoanProductNotOffered) {
+				return LoanSimulationResponse{}, err
+			}
+
+			simulation.RemainingLimit = simulation.AvailableLimit
+			simulation.Reason = err.Error()
+			response.Tenures = append(response.Tenures, simulation)
+			continue
+		}
+
+		simulation.InterestRate = quote.Terms.InterestRate
+		simulation.InterestMethod = quote.Terms.InterestMethod
+		simulation.LoanAmount = quote.LoanAmount
+		simulation.InterestAmount = quote.InterestAmount
+		simulation.AdminFeeAmount = quote.Terms.AdminFeeAmount
+		simulation.InsuranceAmount = quote.InsuranceAmount
+		simulation.UpfrontAmount = upfrontAmount(req.DownPaymentAmount, quote.Terms.AdminFeeAmount.Add(quote.InsuranceAmount), req.FeePayment)
+		if len(quote.Installments) > 0 {
+			simulation.InstallmentAmount = quote.Installments[0].Principal.Add(quote.Installments[0].Interest)
+		}
+
+		simulation.RemainingLimit = simulation.AvailableLimit.Sub(quote.LoanAmount)
+		simulation.Accepted = !simulation.RemainingLimit.IsNegative()
+		if !simulation.Accepted {
+			simulation.Reason = fmt.Sprintf("remaining limit: %s", simulation.AvailableLimit)
+		}
+
+		response.Tenures = append(response.Tenures, simulation)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,184 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSimulateLoan(t *testing.T) {
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+	mockLoanProductRepo := new(mocks.LoanProductRepository)
+
+	uc := usecase.NewLoanUsecase(mockLoanRepo, new(mocks.LoanInstallmentRepository), new(mocks.LoanRestructureRepository), mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, mockLoanProductRepo, new(mocks.LedgerRepository), new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
+
+	mockLoanProductRepo.On("GetLoanProductByCode", mock.Anything, "MOTOR").Return(repository.LoanProduct{
+		ID:             3,
+		ProductCode:    "MOTOR",
+		InterestMethod: usecase.InterestMethodFlat,
+		AdminFeeAmount: money.New(10),
+		InsuranceRate:  2,
+		MinLoanAmount:  money.New(100),
+		MaxLoanAmount:  money.New(10000),
+		ValidFrom:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, nil)
+	mockLoanProductRepo.On("GetLoanProductTenures", mock.Anything, []int64{3}).Return([]repository.LoanProductTenure{
+		{LoanProductID: 3, Tenure: 6, InterestRate: 4},
+		{LoanProductID: 3, Tenure: 12, InterestRate: 5},
+	}, nil)
+
+	req := usecase.SimulateLoanRequest{
+		ConsumerID:        1,
+		MerchantID:        1,
+		ProductCode:       "MOTOR",
+		AssetPrice:        money.New(1500),
+		DownPaymentAmount: money.New(500),
+		FeePayment:        usecase.FeePaymentFinanced,
+		Insured:           true,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, int64(1)).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByConsumerID", mock.Anything, int64(1)).Return([]repository.ConsumerLimit{
+			{ID: 1, ConsumerID: 1, Tenure: 12, LimitAmount: money.New(5000)},
+			{ID: 2, ConsumerID: 1, Tenure: 6, LimitAmount: money.New(1000)},
+			{ID: 3, ConsumerID: 1, Tenure: 24, LimitAmount: money.New(9000)},
+		}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, int64(1)).Return([]repository.Loan{
+			{ID: 1, ConsumerLimitID: 2, LoanAmount: money.New(600), LoanStatus: usecase.LoanStatusPendingReview},
+		}, nil).Once()
+
+		resp, err := uc.SimulateLoan(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, usecase.LoanSimulationResponse{
+			ConsumerID:        1,
+			ProductCode:       "MOTOR",
+			AssetPrice:        money.New(1500),
+			DownPaymentAmount: money.New(500),
+			FeePayment:        usecase.FeePaymentFinanced,
+			Tenures: []usecase.LoanSimulationTenureResponse{
+				{
+					Tenure:            6,
+					InterestRate:      4,
+					InterestMethod:    usecase.InterestMethodFlat,
+					LoanAmount:        money.New(1030),
+					InstallmentAmount: money.New(177),
+					InterestAmount:    money.New(41),
+					AdminFeeAmount:    money.New(10),
+					InsuranceAmount:   money.New(20),
+					UpfrontAmount:     money.New(500),
+					AvailableLimit:    money.New(400),
+					RemainingLimit:    money.New(-630),
+					Reason:            "remaining limit: 400",
+				},
+				{
+					Tenure:            12,
+					InterestRate:      5,
+					InterestMethod:    usecase.InterestMethodFlat,
+					LoanAmount:        money.New(1030),
+					InstallmentAmount: money.New(89),
+					InterestAmount:    money.New(52),
+					AdminFeeAmount:    money.New(10),
+					InsuranceAmount:   money.New(20),
+					UpfrontAmount:     money.New(500),
+					AvailableLimit:    money.New(5000),
+					RemainingLimit:    money.New(3970),
+					Accepted:          true,
+				},
+				{
+					Tenure:         24,
+					AvailableLimit: money.New(9000),
+					RemainingLimit: money.New(9000),
+					Reason:         "loan product not offered: tenure 24 is not offered by MOTOR",
+				},
+			},
+		}, resp)
+		mockConsumerRepo.AssertExpectations(t)
+		mockMerchantRepo.AssertExpectations(t)
+		mockConsumerLimitRepo.AssertExpectations(t)
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("fees paid upfront", func(t *testing.T) {
+		upfrontReq := req
+		upfrontReq.FeePayment = ""
+
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, int64(1)).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByConsumerID", mock.Anything, int64(1)).Return([]repository.ConsumerLimit{
+			{ID: 1, ConsumerID: 1, Tenure: 12, LimitAmount: money.New(1000)},
+		}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, int64(1)).Return([]repository.Loan{}, nil).Once()
+
+		resp, err := uc.SimulateLoan(context.Background(), upfrontReq)
+		assert.NoError(t, err)
+		assert.Equal(t, usecase.FeePaymentUpfront, resp.FeePayment)
+		if assert.Len(t, resp.Tenures, 1) {
+			assert.Equal(t, money.New(1000), resp.Tenures[0].LoanAmount)
+			assert.Equal(t, money.New(530), resp.Tenures[0].UpfrontAmount)
+			assert.Equal(t, money.Zero, resp.Tenures[0].RemainingLimit)
+			assert.True(t, resp.Tenures[0].Accepted)
+		}
+	})
+
+	t.Run("no consumer limit", func(t *testing.T) {
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, int64(1)).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByConsumerID", mock.Anything, int64(1)).Return([]repository.ConsumerLimit{}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, int64(1)).Return([]repository.Loan{}, nil).Once()
+
+		resp, err := uc.SimulateLoan(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Tenures)
+	})
+
+	t.Run("down payment not less than the asset price", func(t *testing.T) {
+		invalidReq := req
+		invalidReq.DownPaymentAmount = money.New(1500)
+
+		resp, err := uc.SimulateLoan(context.Background(), invalidReq)
+		assert.Error(t, err)
+		assert.Equal(t, "down payment must be less than the asset price", err.Error())
+		assert.Equal(t, usecase.LoanSimulationResponse{}, resp)
+	})
+
+	t.Run("consumer not found", func(t *testing.T) {
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{}, nil).Once()
+
+		resp, err := uc.SimulateLoan(context.Background(), req)
+		assert.Error(t, err)
+		assert.Equal(t, "consumer not found", err.Error())
+		assert.Equal(t, usecase.LoanSimulationResponse{}, resp)
+	})
+
+	t.Run("error fetching loan product", func(t *testing.T) {
+		expectedErr := errors.New("unexpected error")
+		errorReq := req
+		errorReq.ProductCode = "BROKEN"
+
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, int64(1)).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByConsumerID", mock.Anything, int64(1)).Return([]repository.ConsumerLimit{
+			{ID: 1, ConsumerID: 1, Tenure: 12, LimitAmount: money.New(5000)},
+		}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, int64(1)).Return([]repository.Loan{}, nil).Once()
+		mockLoanProductRepo.On("GetLoanProductByCode", mock.Anything, "BROKEN").Return(repository.LoanProduct{}, expectedErr).Once()
+
+		resp, err := uc.SimulateLoan(context.Background(), errorReq)
+		assert.Equal(t, expectedErr, err)
+		assert.Equal(t, usecase.LoanSimulationResponse{}, resp)
+	})
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"fmt"
+)
+
+const (
+	LoanStatusPendingReview = "pending_review"
+	LoanStatusApproved      = "approved"
+	LoanStatusRejected      = "rejected"
+	LoanStatusDisbursed     = "disbursed"
+	LoanStatusCancelled     = "cancelled"
+	LoanStatusOnGoing       = "on_going"
+	LoanStatusFinish        = "finish"
+	LoanStatusLate          = "late"
+)
+
+var (
+	// loanStatusTransitions lists the statuses a loan may move to from each status.
+	// A loan is reviewed, then disbursed, then repaid; rejected, cancelled and
+	// finish are final. A disbursed loan is only cancelled during its cooling-off
+	// period, see CancelLoan.
+	loanStatusTransitions = map[string]map[string]bool{
+		LoanStatusPendingReview: {
+			LoanStatusApproved:  true,
+			LoanStatusRejected:  true,
+			LoanStatusCancelled: true,
+		},
+		LoanStatusApproved: {
+			LoanStatusDisbursed: true,
+			LoanStatusCancelled: true,
+		},
+		LoanStatusDisbursed: {
+			LoanStatusOnGoing:   true,
+			LoanStatusLate:      true,
+			LoanStatusFinish:    true,
+			LoanStatusCancelled: true,
+		},
+		LoanStatusOnGoing: {
+			LoanStatusLate:   true,
+			LoanStatusFinish: true,
+		},
+		LoanStatusLate: {
+			LoanStatusOnGoing: true,
+			LoanStatusFinish:  true,
+		},
+	}
+
+	// loanStatusHoldingLimit lists the statuses whose outstanding amount is taken from
+	// the consumer limit, including loans that are still under review.
+	loanStatusHoldingLimit = map[string]bool{
+		LoanStatusPendingReview: true,
+		LoanStatusApproved:      true,
+		LoanStatusDisbursed:     true,
+		LoanStatusOnGoing:       true,
+		LoanStatusLate:          true,
+	}
+
+	// loanStatusActive lists the statuses of the loans being repaid, whose days past due
+	// are kept up to date by the end-of-day batch.
+	loanStatusActive = map[string]bool{
+		LoanStatusDisbursed: true,
+		LoanStatusOnGoing:   true,
+		LoanStatusLate:      true,
+	}
+)
+
+// ValidateLoanStatusTransition returns an error when a loan may not move from one status to another.
+func ValidateLoanStatusTransition(from string, to string) error {
+	if !loanStatusTransitions[from][to] {
+		return fmt.Errorf("loan status cannot change from %s to %s", from, to)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLoanStatusTransition(t *testing.T) {
+	tests := []struct {
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{from: usecase.LoanStatusPendingReview, to: usecase.LoanStatusApproved, wantErr: false},
+		{from: usecase.LoanStatusPendingReview, to: usecase.LoanStatusRejected, wantErr: false},
+		{from: usecase.LoanStatusPendingReview, to: usecase.LoanStatusCancelled, wantErr: false},
+		{from: usecase.LoanStatusPendingReview, to: usecase.LoanStatusDisbursed, wantErr: true},
+		{from: usecase.LoanStatusApproved, to: usecase.LoanStatusDisbursed, wantErr: false},
+		{from: usecase.LoanStatusApproved, to: usecase.LoanStatusRejected, wantErr: true},
+		{from: usecase.LoanStatusDisbursed, to: usecase.LoanStatusOnGoing, wantErr: false},
+		{from: usecase.LoanStatusDisbursed, to: usecase.LoanStatusCancelled, wantErr: false},
+		{from: usecase.LoanStatusOnGoing, to: usecase.LoanStatusCancelled, wantErr: true},
+		{from: usecase.LoanStatusOnGoing, to: usecase.LoanStatusLate, wantErr: false},
+		{from: usecase.LoanStatusLate, to: usecase.LoanStatusOnGoing, wantErr: false},
+		{from: usecase.LoanStatusLate, to: usecase.LoanStatusFinish, wantErr: false},
+		{from: usecase.LoanStatusRejected, to: usecase.LoanStatusApproved, wantErr: true},
+		{from: usecase.LoanStatusCancelled, to: usecase.LoanStatusPendingReview, wantErr: true},
+		{from: usecase.LoanStatusFinish, to: usecase.LoanStatusOnGoing, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			err := usecase.ValidateLoanStatusTransition(tt.from, tt.to)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
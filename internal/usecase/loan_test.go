@@ -2,58 +2,436 @@ package usecase_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 func TestCreateLoan(t *testing.T) {
 	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
 	mockConsumerRepo := new(mocks.ConsumerRepository)
 	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
 	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+	mockLoanProductRepo := new(mocks.LoanProductRepository)
 
-	uc := usecase.NewLoanUsecase(mockLoanRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, time.Second*2)
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, mockLoanProductRepo, mockLedgerRepo, new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
+
+	mockLoanProductRepo.On("GetLoanProductByCode", mock.Anything, "MOTOR").Return(repository.LoanProduct{
+		ID:             3,
+		ProductCode:    "MOTOR",
+		InterestMethod: usecase.InterestMethodFlat,
+		AdminFeeAmount: money.New(10),
+		InsuranceRate:  2,
+		MinLoanAmount:  money.New(100),
+		MaxLoanAmount:  money.New(10000),
+		ValidFrom:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, nil)
+	mockLoanProductRepo.On("GetLoanProductTenures", mock.Anything, []int64{3}).Return([]repository.LoanProductTenure{
+		{LoanProductID: 3, Tenure: 6, InterestRate: 4},
+		{LoanProductID: 3, Tenure: 12, InterestRate: 5},
+	}, nil)
 
 	t.Run("success", func(t *testing.T) {
 		req := usecase.CreateLoanRequest{
-			ConsumerID:   1,
-			MerchantID:   1,
-			Tenure:       12,
-			LoanAmount:   1000,
-			InterestRate: 5,
-			AssetName:    "Car",
+			ConsumerID:  1,
+			MerchantID:  1,
+			Tenure:      12,
+			AssetPrice:  money.New(1000),
+			ProductCode: "MOTOR",
+			AssetName:   "Car",
 		}
 
 		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{ID: 1}, nil).Once()
 		mockMerchantRepo.On("GetMerchantByID", mock.Anything, req.MerchantID).Return(repository.Merchant{ID: 1}, nil).Once()
-		mockConsumerLimitRepo.On("GetLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID).Return(repository.ConsumerLimit{ID: 1, LimitAmount: 5000}, nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID, mock.Anything).Return(repository.ConsumerLimit{ID: 1, LimitAmount: money.New(5000)}, nil).Once()
 		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, req.ConsumerID).Return([]repository.Loan{}, nil).Once()
-		mockLoanRepo.On("CreateLoan", mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+		mockLoanRepo.On("CreateLoan", mock.Anything, mock.MatchedBy(func(loan repository.Loan) bool {
+			return loan.LoanProductID == 3 && loan.InterestRate == 5 && loan.InterestMethod == usecase.InterestMethodFlat && loan.AdminFeeAmount == money.New(10)
+		}), mock.Anything).Return(int64(1), nil).Once()
+		mockLoanInstallmentRepo.On("CreateLoanInstallments", mock.Anything, mock.MatchedBy(func(installments []repository.LoanInstallment) bool {
+			return len(installments) == int(req.Tenure)
+		}), mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceType == usecase.JournalReferenceLoan && entry.ReferenceID == 1 && assert.ObjectsAreEqual([]repository.JournalLine{
+				{AccountCode: usecase.LedgerAccountLoanReceivable, DebitAmount: money.New(1000)},
+				{AccountCode: usecase.LedgerAccountMerchantPayable, CreditAmount: money.New(1000)},
+			}, entry.Lines)
+		}), mock.Anything).Return(int64(1), nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
 
 		resp, err := uc.CreateLoan(context.Background(), req)
 		assert.NoError(t, err)
 		assert.Equal(t, int64(1), resp.ID)
+		assert.Equal(t, int64(3), resp.LoanProductID)
+		assert.Equal(t, 5.0, resp.InterestRate)
+		assert.Equal(t, usecase.InterestMethodFlat, resp.InterestMethod)
+		assert.Equal(t, money.New(50), resp.InterestAmount)
+		assert.Equal(t, money.New(10), resp.AdminFeeAmount)
+		assert.Equal(t, usecase.LoanStatusPendingReview, resp.LoanStatus)
 		mockConsumerRepo.AssertExpectations(t)
 		mockMerchantRepo.AssertExpectations(t)
 		mockConsumerLimitRepo.AssertExpectations(t)
 		mockLoanRepo.AssertExpectations(t)
+		mockLoanInstallmentRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("remaining limit counts loans under review", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:  1,
+			MerchantID:  1,
+			Tenure:      12,
+			AssetPrice:  money.New(2000),
+			ProductCode: "MOTOR",
+			AssetName:   "Car",
+		}
+
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, req.MerchantID).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID, mock.Anything).Return(repository.ConsumerLimit{ID: 1, LimitAmount: money.New(5000)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, req.ConsumerID).Return([]repository.Loan{
+			{ID: 1, ConsumerLimitID: 1, LoanAmount: money.New(2000), LoanStatus: usecase.LoanStatusPendingReview},
+			{ID: 2, ConsumerLimitID: 1, LoanAmount: money.New(1500), LoanStatus: usecase.LoanStatusApproved},
+			{ID: 3, ConsumerLimitID: 1, LoanAmount: money.New(3000), LoanStatus: usecase.LoanStatusRejected},
+			{ID: 4, ConsumerLimitID: 1, LoanAmount: money.New(3000), LoanStatus: usecase.LoanStatusCancelled},
+		}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := uc.CreateLoan(context.Background(), req)
+		assert.Error(t, err)
+		assert.Equal(t, "remaining limit: 1500", err.Error())
+		assert.Equal(t, usecase.LoanResponse{}, resp)
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("loan amount above limit", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:  1,
+			MerchantID:  1,
+			Tenure:      12,
+			AssetPrice:  money.New(6000),
+			ProductCode: "MOTOR",
+			AssetName:   "Car",
+		}
+
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, req.MerchantID).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID, mock.Anything).Return(repository.ConsumerLimit{ID: 1, LimitAmount: money.New(5000)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, req.ConsumerID).Return([]repository.Loan{}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := uc.CreateLoan(context.Background(), req)
+		assert.Error(t, err)
+		assert.Equal(t, "remaining limit: 5000", err.Error())
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("down payment with financed fees", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:        1,
+			MerchantID:        1,
+			Tenure:            12,
+			AssetPrice:        money.New(1500),
+			DownPaymentAmount: money.New(500),
+			FeePayment:        usecase.FeePaymentFinanced,
+			Insured:           true,
+			ProductCode:       "MOTOR",
+			AssetName:         "Car",
+		}
+
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, req.MerchantID).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID, mock.Anything).Return(repository.ConsumerLimit{ID: 1, LimitAmount: money.New(5000)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, req.ConsumerID).Return([]repository.Loan{}, nil).Once()
+		mockLoanRepo.On("CreateLoan", mock.Anything, mock.MatchedBy(func(loan repository.Loan) bool {
+			return loan.AssetPrice == money.New(1500) && loan.DownPaymentAmount == money.New(500) && loan.LoanAmount == money.New(1030) &&
+				loan.AdminFeeAmount == money.New(10) && loan.InsuranceAmount == money.New(20) && loan.FeePayment == usecase.FeePaymentFinanced
+		}), mock.Anything).Return(int64(2), nil).Once()
+		mockLoanInstallmentRepo.On("CreateLoanInstallments", mock.Anything, mock.MatchedBy(func(installments []repository.LoanInstallment) bool {
+			var principal money.Money
+			for _, installment := range installments {
+				principal = principal.Add(installment.PrincipalAmount)
+			}
+			return principal == money.New(1030)
+		}), mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceType == usecase.JournalReferenceLoan && entry.ReferenceID == 2 && assert.ObjectsAreEqual([]repository.JournalLine{
+				{AccountCode: usecase.LedgerAccountLoanReceivable, DebitAmount: money.New(1030)},
+				{AccountCode: usecase.LedgerAccountMerchantPayable, CreditAmount: money.New(1000)},
+				{AccountCode: usecase.LedgerAccountAdminFeeIncome, CreditAmount: money.New(10)},
+				{AccountCode: usecase.LedgerAccountInsurancePayable, CreditAmount: money.New(20)},
+			}, entry.Lines)
+		}), mock.Anything).Return(int64(2), nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := uc.CreateLoan(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, money.New(1030), resp.LoanAmount)
+		assert.Equal(t, money.New(20), resp.InsuranceAmount)
+		assert.Equal(t, usecase.FeePaymentFinanced, resp.FeePayment)
+		mockLoanRepo.AssertExpectations(t)
+		mockLoanInstallmentRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("down payment with fees paid upfront", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:        1,
+			MerchantID:        1,
+			Tenure:            12,
+			AssetPrice:        money.New(1500),
+			DownPaymentAmount: money.New(500),
+			Insured:           true,
+			ProductCode:       "MOTOR",
+			AssetName:         "Car",
+		}
+
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, req.MerchantID).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID, mock.Anything).Return(repository.ConsumerLimit{ID: 1, LimitAmount: money.New(5000)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, req.ConsumerID).Return([]repository.Loan{}, nil).Once()
+		mockLoanRepo.On("CreateLoan", mock.Anything, mock.MatchedBy(func(loan repository.Loan) bool {
+			return loan.LoanAmount == money.New(1000) && loan.InsuranceAmount == money.New(20) && loan.FeePayment == usecase.FeePaymentUpfront
+		}), mock.Anything).Return(int64(3), nil).Once()
+		mockLoanInstallmentRepo.On("CreateLoanInstallments", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceID == 3 && assert.ObjectsAreEqual([]repository.JournalLine{
+				{AccountCode: usecase.LedgerAccountLoanReceivable, DebitAmount: money.New(1000)},
+				{AccountCode: usecase.LedgerAccountMerchantPayable, CreditAmount: money.New(1000)},
+			}, entry.Lines)
+		}), mock.Anything).Return(int64(3), nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := uc.CreateLoan(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, money.New(1000), resp.LoanAmount)
+		assert.Equal(t, usecase.FeePaymentUpfront, resp.FeePayment)
+		mockLoanRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("financed fees count against the limit", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:        1,
+			MerchantID:        1,
+			Tenure:            12,
+			AssetPrice:        money.New(1500),
+			DownPaymentAmount: money.New(500),
+			FeePayment:        usecase.FeePaymentFinanced,
+			ProductCode:       "MOTOR",
+			AssetName:         "Car",
+		}
+
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, req.MerchantID).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID, mock.Anything).Return(repository.ConsumerLimit{ID: 1, LimitAmount: money.New(1005)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, req.ConsumerID).Return([]repository.Loan{}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := uc.CreateLoan(context.Background(), req)
+		assert.Error(t, err)
+		assert.Equal(t, "remaining limit: 1005", err.Error())
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("down payment not less than the asset price", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:        1,
+			MerchantID:        1,
+			Tenure:            12,
+			AssetPrice:        money.New(1000),
+			DownPaymentAmount: money.New(1000),
+			ProductCode:       "MOTOR",
+			AssetName:         "Car",
+		}
+
+		resp, err := uc.CreateLoan(context.Background(), req)
+		assert.Error(t, err)
+		assert.Equal(t, "down payment must be less than the asset price", err.Error())
+		assert.Equal(t, usecase.LoanResponse{}, resp)
+	})
+
+	t.Run("credit insurance not offered by the loan product", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:  1,
+			MerchantID:  1,
+			Tenure:      12,
+			AssetPrice:  money.New(1000),
+			Insured:     true,
+			ProductCode: "UNINSURED",
+			AssetName:   "Car",
+		}
+
+		mockLoanProductRepo.On("GetLoanProductByCode", mock.Anything, "UNINSURED").Return(repository.LoanProduct{
+			ID:             5,
+			ProductCode:    "UNINSURED",
+			InterestMethod: usecase.InterestMethodFlat,
+			ValidFrom:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}, nil).Once()
+		mockLoanProductRepo.On("GetLoanProductTenures", mock.Anything, []int64{5}).Return([]repository.LoanProductTenure{
+			{LoanProductID: 5, Tenure: 12, InterestRate: 5},
+		}, nil).Once()
+
+		resp, err := uc.CreateLoan(context.Background(), req)
+		assert.ErrorIs(t, err, usecase.ErrLoanProductNotOffered)
+		assert.Equal(t, "loan product not offered: credit insurance is not offered by UNINSURED", err.Error())
+		assert.Equal(t, usecase.LoanResponse{}, resp)
+	})
+
+	t.Run("error creating installments", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:  1,
+			MerchantID:  1,
+			Tenure:      12,
+			AssetPrice:  money.New(1000),
+			ProductCode: "MOTOR",
+			AssetName:   "Car",
+		}
+		expectedErr := errors.New("unexpected error")
+
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, req.MerchantID).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID, mock.Anything).Return(repository.ConsumerLimit{ID: 1, LimitAmount: money.New(5000)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, req.ConsumerID).Return([]repository.Loan{}, nil).Once()
+		mockLoanRepo.On("CreateLoan", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+		mockLoanInstallmentRepo.On("CreateLoanInstallments", mock.Anything, mock.Anything, mock.Anything).Return(expectedErr).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := uc.CreateLoan(context.Background(), req)
+		assert.Error(t, err)
+		assert.Equal(t, expectedErr, err)
+		assert.Equal(t, usecase.LoanResponse{}, resp)
+		mockLoanRepo.AssertExpectations(t)
+		mockLoanInstallmentRepo.AssertExpectations(t)
+	})
+
+	t.Run("error posting journal entry", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:  1,
+			MerchantID:  1,
+			Tenure:      12,
+			AssetPrice:  money.New(1000),
+			ProductCode: "MOTOR",
+			AssetName:   "Car",
+		}
+		expectedErr := errors.New("unexpected error")
+
+		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{ID: 1}, nil).Once()
+		mockMerchantRepo.On("GetMerchantByID", mock.Anything, req.MerchantID).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID, mock.Anything).Return(repository.ConsumerLimit{ID: 1, LimitAmount: money.New(5000)}, nil).Once()
+		mockLoanRepo.On("GetLoanByConsumerID", mock.Anything, req.ConsumerID).Return([]repository.Loan{}, nil).Once()
+		mockLoanRepo.On("CreateLoan", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+		mockLoanInstallmentRepo.On("CreateLoanInstallments", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(int64(0), expectedErr).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := uc.CreateLoan(context.Background(), req)
+		assert.Equal(t, expectedErr, err)
+		mockLoanRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("loan product not found", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:  1,
+			MerchantID:  1,
+			ProductCode: "UNKNOWN",
+			Tenure:      12,
+			AssetPrice:  money.New(1000),
+			AssetName:   "Car",
+		}
+
+		mockLoanProductRepo.On("GetLoanProductByCode", mock.Anything, "UNKNOWN").Return(repository.LoanProduct{}, nil).Once()
+
+		resp, err := uc.CreateLoan(context.Background(), req)
+		assert.ErrorIs(t, err, usecase.ErrLoanProductNotOffered)
+		assert.Equal(t, usecase.LoanResponse{}, resp)
+	})
+
+	t.Run("tenure not offered by the loan product", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:  1,
+			MerchantID:  1,
+			ProductCode: "MOTOR",
+			Tenure:      24,
+			AssetPrice:  money.New(1000),
+			AssetName:   "Car",
+		}
+
+		resp, err := uc.CreateLoan(context.Background(), req)
+		assert.ErrorIs(t, err, usecase.ErrLoanProductNotOffered)
+		assert.Equal(t, "loan product not offered: tenure 24 is not offered by MOTOR", err.Error())
+		assert.Equal(t, usecase.LoanResponse{}, resp)
+	})
+
+	t.Run("loan amount above the loan product maximum", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:  1,
+			MerchantID:  1,
+			ProductCode: "MOTOR",
+			Tenure:      12,
+			AssetPrice:  money.New(20000),
+			AssetName:   "Car",
+		}
+
+		resp, err := uc.CreateLoan(context.Background(), req)
+		assert.ErrorIs(t, err, usecase.ErrLoanProductNotOffered)
+		assert.Equal(t, usecase.LoanResponse{}, resp)
+	})
+
+	t.Run("loan product no longer offered", func(t *testing.T) {
+		req := usecase.CreateLoanRequest{
+			ConsumerID:  1,
+			MerchantID:  1,
+			ProductCode: "EXPIRED",
+			Tenure:      12,
+			AssetPrice:  money.New(1000),
+			AssetName:   "Car",
+		}
+
+		mockLoanProductRepo.On("GetLoanProductByCode", mock.Anything, "EXPIRED").Return(repository.LoanProduct{
+			ID:          4,
+			ProductCode: "EXPIRED",
+			ValidFrom:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			ValidUntil:  time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+		}, nil).Once()
+
+		resp, err := uc.CreateLoan(context.Background(), req)
+		assert.ErrorIs(t, err, usecase.ErrLoanProductNotOffered)
+		assert.Equal(t, "loan product not offered: loan product EXPIRED was offered until 2023-12-31", err.Error())
+		assert.Equal(t, usecase.LoanResponse{}, resp)
 	})
 
 	t.Run("consumer not found", func(t *testing.T) {
 		req := usecase.CreateLoanRequest{
-			ConsumerID:   1,
-			MerchantID:   1,
-			Tenure:       12,
-			LoanAmount:   1000,
-			InterestRate: 5,
-			AssetName:    "Car",
+			ConsumerID:  1,
+			MerchantID:  1,
+			Tenure:      12,
+			AssetPrice:  money.New(1000),
+			ProductCode: "MOTOR",
+			AssetName:   "Car",
 		}
 
 		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{}, nil).Once()
@@ -67,12 +445,12 @@ func TestCreateLoan(t *testing.T) {
 
 	t.Run("merchant not found", func(t *testing.T) {
 		req := usecase.CreateLoanRequest{
-			ConsumerID:   1,
-			MerchantID:   1,
-			Tenure:       12,
-			LoanAmount:   1000,
-			InterestRate: 5,
-			AssetName:    "Car",
+			ConsumerID:  1,
+			MerchantID:  1,
+			Tenure:      12,
+			AssetPrice:  money.New(1000),
+			ProductCode: "MOTOR",
+			AssetName:   "Car",
 		}
 
 		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{ID: 1}, nil).Once()
@@ -88,17 +466,19 @@ func TestCreateLoan(t *testing.T) {
 
 	t.Run("consumer limit not found", func(t *testing.T) {
 		req := usecase.CreateLoanRequest{
-			ConsumerID:   1,
-			MerchantID:   1,
-			Tenure:       12,
-			LoanAmount:   1000,
-			InterestRate: 5,
-			AssetName:    "Car",
+			ConsumerID:  1,
+			MerchantID:  1,
+			Tenure:      12,
+			AssetPrice:  money.New(1000),
+			ProductCode: "MOTOR",
+			AssetName:   "Car",
 		}
 
 		mockConsumerRepo.On("GetConsumerByID", mock.Anything, req.ConsumerID).Return(repository.Consumer{ID: 1}, nil).Once()
 		mockMerchantRepo.On("GetMerchantByID", mock.Anything, req.MerchantID).Return(repository.Merchant{ID: 1}, nil).Once()
-		mockConsumerLimitRepo.On("GetLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID).Return(repository.ConsumerLimit{}, nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockConsumerLimitRepo.On("LockLimitByTenureAndConsumerID", mock.Anything, req.Tenure, req.ConsumerID, mock.Anything).Return(repository.ConsumerLimit{}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
 
 		resp, err := uc.CreateLoan(context.Background(), req)
 		assert.Error(t, err)
@@ -107,16 +487,173 @@ func TestCreateLoan(t *testing.T) {
 		mockConsumerRepo.AssertExpectations(t)
 		mockMerchantRepo.AssertExpectations(t)
 		mockConsumerLimitRepo.AssertExpectations(t)
+		mockLoanRepo.AssertExpectations(t)
 	})
 }
 
+// limitStore stands in for the database in TestCreateLoanConcurrently. A locked consumer
+// limit stays locked until the transaction holding it ends and loans only become visible
+// to other transactions once committed, as with InnoDB row locks.
+type limitStore struct {
+	limitLock sync.Mutex
+	mu        sync.Mutex
+	holder    *sql.Tx
+	lastID    int64
+	loans     []repository.Loan
+	pending   map[*sql.Tx][]repository.Loan
+}
+
+func (s *limitStore) release(tx *sql.Tx, commit bool) {
+	s.mu.Lock()
+	if commit {
+		s.loans = append(s.loans, s.pending[tx]...)
+	}
+	delete(s.pending, tx)
+	locked := s.holder == tx
+	if locked {
+		s.holder = nil
+	}
+	s.mu.Unlock()
+
+	if locked {
+		s.limitLock.Unlock()
+	}
+}
+
+type lockingLoanRepository struct {
+	*mocks.LoanRepository
+	store *limitStore
+}
+
+func (r lockingLoanRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return new(sql.Tx), nil
+}
+
+func (r lockingLoanRepository) CommitTx(ctx context.Context, tx *sql.Tx) error {
+	r.store.release(tx, true)
+	return nil
+}
+
+func (r lockingLoanRepository) RollbackTx(ctx context.Context, tx *sql.Tx) error {
+	r.store.release(tx, false)
+	return nil
+}
+
+func (r lockingLoanRepository) GetLoanByConsumerID(ctx context.Context, consumerID int64) ([]repository.Loan, error) {
+	r.store.mu.Lock()
+	loans := append([]repository.Loan(nil), r.store.loans...)
+	r.store.mu.Unlock()
+
+	// widen the window between the limit check and the insert
+	time.Sleep(time.Millisecond)
+	return loans, nil
+}
+
+func (r lockingLoanRepository) CreateLoan(ctx context.Context, loan repository.Loan, tx *sql.Tx) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.lastID++
+	loan.ID = r.store.lastID
+	r.store.pending[tx] = append(r.store.pending[tx], loan)
+	return loan.ID, nil
+}
+
+type lockingConsumerLimitRepository struct {
+	*mocks.ConsumerLimitRepository
+	store *limitStore
+	limit repository.ConsumerLimit
+}
+
+func (r lockingConsumerLimitRepository) LockLimitByTenureAndConsumerID(ctx context.Context, tenure int16, consumerID int64, tx *sql.Tx) (repository.ConsumerLimit, error) {
+	r.store.limitLock.Lock()
+	r.store.mu.Lock()
+	r.store.holder = tx
+	r.store.mu.Unlock()
+
+	return r.limit, nil
+}
+
+func TestCreateLoanConcurrently(t *testing.T) {
+	store := &limitStore{pending: map[*sql.Tx][]repository.Loan{}}
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil)
+	mockMerchantRepo.On("GetMerchantByID", mock.Anything, int64(1)).Return(repository.Merchant{ID: 1}, nil)
+	mockLoanInstallmentRepo.On("CreateLoanInstallments", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil)
+	mockLoanProductRepo := new(mocks.LoanProductRepository)
+	mockLoanProductRepo.On("GetLoanProductByCode", mock.Anything, "PHONE").Return(repository.LoanProduct{ID: 1, ProductCode: "PHONE"}, nil)
+	mockLoanProductRepo.On("GetLoanProductTenures", mock.Anything, []int64{1}).Return([]repository.LoanProductTenure{{LoanProductID: 1, Tenure: 6, InterestRate: 5}}, nil)
+
+	uc := usecase.NewLoanUsecase(
+		lockingLoanRepository{LoanRepository: new(mocks.LoanRepository), store: store},
+		mockLoanInstallmentRepo,
+		new(mocks.LoanRestructureRepository),
+		lockingConsumerLimitRepository{
+			ConsumerLimitRepository: new(mocks.ConsumerLimitRepository),
+			store:                   store,
+			limit:                   repository.ConsumerLimit{ID: 1, ConsumerID: 1, Tenure: 6, LimitAmount: money.New(1000)},
+		},
+		mockConsumerRepo,
+		mockMerchantRepo,
+		mockLoanProductRepo,
+		mockLedgerRepo,
+		new(mocks.MerchantNotificationRepository),
+		config.LoanConfig{},
+		time.Second*2,
+	)
+
+	// a limit of 1,000 fits three loans of 300, the other applications must be refused
+	const applications = 10
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		refused   int
+	)
+	for i := 0; i < applications; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := uc.CreateLoan(context.Background(), usecase.CreateLoanRequest{
+				ConsumerID:  1,
+				MerchantID:  1,
+				ProductCode: "PHONE",
+				Tenure:      6,
+				AssetPrice:  money.New(300),
+				AssetName:   "Phone",
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				succeeded++
+			} else if assert.Equal(t, "remaining limit: 100", err.Error()) {
+				refused++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 3, succeeded)
+	assert.Equal(t, applications-3, refused)
+	assert.Len(t, store.loans, 3)
+	assert.Empty(t, store.pending)
+}
+
 func TestGetLoanByID(t *testing.T) {
 	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
 	mockConsumerRepo := new(mocks.ConsumerRepository)
 	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
 	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
 
-	uc := usecase.NewLoanUsecase(mockLoanRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, time.Second*2)
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, new(mocks.LoanProductRepository), mockLedgerRepo, new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		loanID := int64(1)
@@ -125,10 +662,10 @@ func TestGetLoanByID(t *testing.T) {
 			ConsumerID:      1,
 			MerchantID:      1,
 			ConsumerLimitID: 1,
-			LoanAmount:      1000,
+			LoanAmount:      money.New(1000),
 			ContractNumber:  "123-abc-456",
 			InterestRate:    5,
-			InterestAmount:  50,
+			InterestAmount:  money.New(50),
 			LoanStatus:      "on_going",
 			DueDate:         time.Now(),
 			Installment:     12,
@@ -186,11 +723,14 @@ func TestGetLoanByID(t *testing.T) {
 
 func TestGetLoanByConsumerID(t *testing.T) {
 	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
 	mockConsumerRepo := new(mocks.ConsumerRepository)
 	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
 	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
 
-	uc := usecase.NewLoanUsecase(mockLoanRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, time.Second*2)
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, new(mocks.LoanProductRepository), mockLedgerRepo, new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		consumerID := int64(1)
@@ -200,10 +740,10 @@ func TestGetLoanByConsumerID(t *testing.T) {
 				ConsumerID:      consumerID,
 				MerchantID:      1,
 				ConsumerLimitID: 1,
-				LoanAmount:      1000,
+				LoanAmount:      money.New(1000),
 				ContractNumber:  "123-abc-456",
 				InterestRate:    5,
-				InterestAmount:  50,
+				InterestAmount:  money.New(50),
 				LoanStatus:      "on_going",
 				DueDate:         time.Now(),
 				Installment:     12,
@@ -262,17 +802,24 @@ func TestGetLoanByConsumerID(t *testing.T) {
 
 func TestDeleteLoanByID(t *testing.T) {
 	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
 	mockConsumerRepo := new(mocks.ConsumerRepository)
 	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
 	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
 
-	uc := usecase.NewLoanUsecase(mockLoanRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, time.Second*2)
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, new(mocks.LoanProductRepository), mockLedgerRepo, new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		loanID := int64(1)
 
-		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{ID: loanID}, nil).Once()
-		mockLoanRepo.On("DeleteLoan", mock.Anything, loanID).Return(nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, loanID, mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{ID: loanID, LoanStatus: usecase.LoanStatusCancelled}, nil).Once()
+		mockLoanRepo.On("HasLoanTransactions", mock.Anything, loanID, mock.Anything).Return(false, nil).Once()
+		mockLoanRepo.On("DeleteLoan", mock.Anything, loanID, mock.Anything).Return(nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
 
 		err := uc.DeleteLoanByID(context.Background(), loanID)
 		assert.NoError(t, err)
@@ -282,7 +829,9 @@ func TestDeleteLoanByID(t *testing.T) {
 	t.Run("loan not found", func(t *testing.T) {
 		loanID := int64(1)
 
-		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{}, nil).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, loanID, mock.Anything).Return(false, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
 
 		err := uc.DeleteLoanByID(context.Background(), loanID)
 		assert.Error(t, err)
@@ -294,7 +843,10 @@ func TestDeleteLoanByID(t *testing.T) {
 		loanID := int64(1)
 		expectedErr := errors.New("unexpected error")
 
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, loanID, mock.Anything).Return(true, nil).Once()
 		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{}, expectedErr).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
 
 		err := uc.DeleteLoanByID(context.Background(), loanID)
 		assert.Error(t, err)
@@ -302,12 +854,47 @@ func TestDeleteLoanByID(t *testing.T) {
 		mockLoanRepo.AssertExpectations(t)
 	})
 
+	t.Run("loan still owed", func(t *testing.T) {
+		for _, loanStatus := range []string{usecase.LoanStatusPendingReview, usecase.LoanStatusApproved, usecase.LoanStatusDisbursed, usecase.LoanStatusOnGoing, usecase.LoanStatusFinish} {
+			loanID := int64(1)
+
+			mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+			mockLoanRepo.On("LockLoanByID", mock.Anything, loanID, mock.Anything).Return(true, nil).Once()
+			mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{ID: loanID, ContractNumber: "CN-1", LoanStatus: loanStatus}, nil).Once()
+			mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+			err := uc.DeleteLoanByID(context.Background(), loanID)
+			assert.ErrorIs(t, err, usecase.ErrLoanNotDeletable)
+			assert.Equal(t, fmt.Sprintf("loan cannot be deleted: loan CN-1 is %s, only rejected or cancelled loans are deleted", loanStatus), err.Error())
+		}
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("loan has transactions", func(t *testing.T) {
+		loanID := int64(1)
+
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, loanID, mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{ID: loanID, ContractNumber: "CN-1", LoanStatus: usecase.LoanStatusRejected}, nil).Once()
+		mockLoanRepo.On("HasLoanTransactions", mock.Anything, loanID, mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.DeleteLoanByID(context.Background(), loanID)
+		assert.ErrorIs(t, err, usecase.ErrLoanHasTransactions)
+		assert.Equal(t, "loan has transactions: loan CN-1 cannot be deleted", err.Error())
+		mockLoanRepo.AssertExpectations(t)
+	})
+
 	t.Run("error deleting loan", func(t *testing.T) {
 		loanID := int64(1)
 		expectedErr := errors.New("unexpected error")
 
-		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{ID: loanID}, nil).Once()
-		mockLoanRepo.On("DeleteLoan", mock.Anything, loanID).Return(expectedErr).Once()
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, loanID, mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{ID: loanID, LoanStatus: usecase.LoanStatusRejected}, nil).Once()
+		mockLoanRepo.On("HasLoanTransactions", mock.Anything, loanID, mock.Anything).Return(false, nil).Once()
+		mockLoanRepo.On("DeleteLoan", mock.Anything, loanID, mock.Anything).Return(expectedErr).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
 
 		err := uc.DeleteLoanByID(context.Background(), loanID)
 		assert.Error(t, err)
@@ -315,3 +902,529 @@ func TestDeleteLoanByID(t *testing.T) {
 		mockLoanRepo.AssertExpectations(t)
 	})
 }
+
+func TestGetLoanSchedule(t *testing.T) {
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, new(mocks.LoanProductRepository), mockLedgerRepo, new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
+
+	t.Run("success", func(t *testing.T) {
+		loanID := int64(1)
+		dueDate := time.Now().AddDate(0, 1, 0)
+		paidAt := time.Now()
+		installments := []repository.LoanInstallment{
+			{
+				ID:                  1,
+				LoanID:              loanID,
+				InstallmentNumber:   1,
+				DueDate:             dueDate,
+				PrincipalAmount:     money.New(500),
+				InterestAmount:      money.New(25),
+				PaidPrincipalAmount: money.New(500),
+				PaidInterestAmount:  money.New(25),
+				InstallmentStatus:   "paid",
+				PaidAt:              paidAt,
+			},
+			{
+				ID:                2,
+				LoanID:            loanID,
+				InstallmentNumber: 2,
+				DueDate:           dueDate.AddDate(0, 1, 0),
+				PrincipalAmount:   money.New(500),
+				InterestAmount:    money.New(25),
+				InstallmentStatus: "unpaid",
+			},
+		}
+
+		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{ID: loanID, ContractNumber: "123-abc-456"}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, loanID).Return(installments, nil).Once()
+
+		resp, err := uc.GetLoanSchedule(context.Background(), loanID)
+		assert.NoError(t, err)
+		assert.Equal(t, loanID, resp.LoanID)
+		assert.Equal(t, "123-abc-456", resp.ContractNumber)
+		assert.Equal(t, int16(2), resp.Tenure)
+		assert.Len(t, resp.Installments, 2)
+		assert.Equal(t, money.New(525), resp.Installments[0].TotalAmount)
+		assert.Equal(t, paidAt.Format("2006-01-02 15:04:05"), resp.Installments[0].PaidAt)
+		assert.Equal(t, "unpaid", resp.Installments[1].InstallmentStatus)
+		assert.Empty(t, resp.Installments[1].PaidAt)
+		mockLoanRepo.AssertExpectations(t)
+		mockLoanInstallmentRepo.AssertExpectations(t)
+	})
+
+	t.Run("fees paid upfront", func(t *testing.T) {
+		loanID := int64(2)
+
+		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{
+			ID:                loanID,
+			AssetPrice:        money.New(1500),
+			DownPaymentAmount: money.New(500),
+			LoanAmount:        money.New(1000),
+			AdminFeeAmount:    money.New(10),
+			InsuranceAmount:   money.New(20),
+			FeePayment:        usecase.FeePaymentUpfront,
+		}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, loanID).Return([]repository.LoanInstallment{}, nil).Once()
+
+		resp, err := uc.GetLoanSchedule(context.Background(), loanID)
+		assert.NoError(t, err)
+		assert.Equal(t, money.New(1000), resp.LoanAmount)
+		assert.Equal(t, money.New(530), resp.UpfrontAmount)
+	})
+
+	t.Run("fees financed", func(t *testing.T) {
+		loanID := int64(3)
+
+		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{
+			ID:                loanID,
+			AssetPrice:        money.New(1500),
+			DownPaymentAmount: money.New(500),
+			LoanAmount:        money.New(1030),
+			AdminFeeAmount:    money.New(10),
+			InsuranceAmount:   money.New(20),
+			FeePayment:        usecase.FeePaymentFinanced,
+		}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, loanID).Return([]repository.LoanInstallment{}, nil).Once()
+
+		resp, err := uc.GetLoanSchedule(context.Background(), loanID)
+		assert.NoError(t, err)
+		assert.Equal(t, money.New(1030), resp.LoanAmount)
+		assert.Equal(t, money.New(500), resp.UpfrontAmount)
+	})
+
+	t.Run("loan not found", func(t *testing.T) {
+		loanID := int64(1)
+
+		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{}, nil).Once()
+
+		resp, err := uc.GetLoanSchedule(context.Background(), loanID)
+		assert.Error(t, err)
+		assert.Equal(t, "loan not found", err.Error())
+		assert.Equal(t, usecase.LoanScheduleResponse{}, resp)
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("error fetching installments", func(t *testing.T) {
+		loanID := int64(1)
+		expectedErr := errors.New("unexpected error")
+
+		mockLoanRepo.On("GetLoanByID", mock.Anything, loanID).Return(repository.Loan{ID: loanID}, nil).Once()
+		mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, loanID).Return(nil, expectedErr).Once()
+
+		_, err := uc.GetLoanSchedule(context.Background(), loanID)
+		assert.Error(t, err)
+		assert.Equal(t, expectedErr, err)
+		mockLoanRepo.AssertExpectations(t)
+		mockLoanInstallmentRepo.AssertExpectations(t)
+	})
+}
+
+func TestApproveLoan(t *testing.T) {
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, new(mocks.LoanProductRepository), mockLedgerRepo, new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
+
+	t.Run("success", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, LoanStatus: usecase.LoanStatusPendingReview}, nil).Once()
+		mockLoanRepo.On("UpdateLoanStatus", mock.Anything, repository.UpdateLoanStatusRequest{
+			ID:         1,
+			FromStatus: usecase.LoanStatusPendingReview,
+			LoanStatus: usecase.LoanStatusApproved,
+		}, mock.Anything).Return(nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.ApproveLoan(context.Background(), 1)
+		assert.NoError(t, err)
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("loan not found", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(false, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.ApproveLoan(context.Background(), 1)
+		assert.Error(t, err)
+		assert.Equal(t, "loan not found", err.Error())
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, LoanStatus: usecase.LoanStatusRejected}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.ApproveLoan(context.Background(), 1)
+		assert.Error(t, err)
+		assert.Equal(t, "loan status cannot change from rejected to approved", err.Error())
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("status changed meanwhile", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, LoanStatus: usecase.LoanStatusPendingReview}, nil).Once()
+		mockLoanRepo.On("UpdateLoanStatus", mock.Anything, mock.Anything, mock.Anything).Return(repository.ErrLoanStatusConflict).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.ApproveLoan(context.Background(), 1)
+		assert.ErrorIs(t, err, repository.ErrLoanStatusConflict)
+		mockLoanRepo.AssertExpectations(t)
+	})
+}
+
+func TestRejectLoan(t *testing.T) {
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, new(mocks.LoanProductRepository), mockLedgerRepo, new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
+
+	t.Run("success", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ContractNumber: "XYZ-1", LoanAmount: money.New(1000), LoanStatus: usecase.LoanStatusPendingReview}, nil).Once()
+		mockLoanRepo.On("UpdateLoanStatus", mock.Anything, repository.UpdateLoanStatusRequest{
+			ID:           1,
+			FromStatus:   usecase.LoanStatusPendingReview,
+			LoanStatus:   usecase.LoanStatusRejected,
+			StatusReason: "insufficient income",
+		}, mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceType == usecase.JournalReferenceLoanRelease && entry.Description == "Loan XYZ-1 rejected" && assert.ObjectsAreEqual([]repository.JournalLine{
+				{AccountCode: usecase.LedgerAccountMerchantPayable, DebitAmount: money.New(1000)},
+				{AccountCode: usecase.LedgerAccountLoanReceivable, CreditAmount: money.New(1000)},
+			}, entry.Lines)
+		}), mock.Anything).Return(int64(1), nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.RejectLoan(context.Background(), usecase.LoanStatusRequest{LoanID: 1, Reason: "insufficient income"})
+		assert.NoError(t, err)
+		mockLoanRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("already approved", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, LoanStatus: usecase.LoanStatusApproved}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.RejectLoan(context.Background(), usecase.LoanStatusRequest{LoanID: 1, Reason: "insufficient income"})
+		assert.Error(t, err)
+		mockLoanRepo.AssertExpectations(t)
+	})
+}
+
+func TestDisburseLoan(t *testing.T) {
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, new(mocks.LoanProductRepository), mockLedgerRepo, new(mocks.MerchantNotificationRepository), config.LoanConfig{}, time.Second*2)
+
+	t.Run("success", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerLimitID: 1, LoanAmount: money.New(1000), LoanStatus: usecase.LoanStatusApproved}, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 6}, nil).Once()
+		mockLoanRepo.On("DisburseLoan", mock.Anything, mock.MatchedBy(func(req repository.DisburseLoanRequest) bool {
+			return req.ID == 1 && req.Tenure == 6 && !req.DisbursedAt.IsZero()
+		}), mock.Anything).Return(nil).Once()
+		mockLoanInstallmentRepo.On("RescheduleLoanInstallments", mock.Anything, int64(1), mock.Anything, mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceType == usecase.JournalReferenceLoanDisbursement && entry.ReferenceID == 1 && assert.ObjectsAreEqual([]repository.JournalLine{
+				{AccountCode: usecase.LedgerAccountMerchantPayable, DebitAmount: money.New(1000)},
+				{AccountCode: usecase.LedgerAccountCash, CreditAmount: money.New(1000)},
+			}, entry.Lines)
+		}), mock.Anything).Return(int64(1), nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.DisburseLoan(context.Background(), 1)
+		assert.NoError(t, err)
+		mockLoanRepo.AssertExpectations(t)
+		mockLoanInstallmentRepo.AssertExpectations(t)
+		mockConsumerLimitRepo.AssertExpectations(t)
+	})
+
+	t.Run("merchant is not paid the financed fees", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(2), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(2)).Return(repository.Loan{
+			ID:              2,
+			ConsumerLimitID: 1,
+			LoanAmount:      money.New(1030),
+			AdminFeeAmount:  money.New(10),
+			InsuranceAmount: money.New(20),
+			FeePayment:      usecase.FeePaymentFinanced,
+			LoanStatus:      usecase.LoanStatusApproved,
+		}, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 6}, nil).Once()
+		mockLoanRepo.On("DisburseLoan", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		mockLoanInstallmentRepo.On("RescheduleLoanInstallments", mock.Anything, int64(2), mock.Anything, mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceID == 2 && assert.ObjectsAreEqual([]repository.JournalLine{
+				{AccountCode: usecase.LedgerAccountMerchantPayable, DebitAmount: money.New(1000)},
+				{AccountCode: usecase.LedgerAccountCash, CreditAmount: money.New(1000)},
+			}, entry.Lines)
+		}), mock.Anything).Return(int64(2), nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.DisburseLoan(context.Background(), 2)
+		assert.NoError(t, err)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("not approved yet", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, LoanStatus: usecase.LoanStatusPendingReview}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.DisburseLoan(context.Background(), 1)
+		assert.Error(t, err)
+		assert.Equal(t, "loan status cannot change from pending_review to disbursed", err.Error())
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("error rescheduling installments", func(t *testing.T) {
+		expectedErr := errors.New("unexpected error")
+
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerLimitID: 1, LoanStatus: usecase.LoanStatusApproved}, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 6}, nil).Once()
+		mockLoanRepo.On("DisburseLoan", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		mockLoanInstallmentRepo.On("RescheduleLoanInstallments", mock.Anything, int64(1), mock.Anything, mock.Anything).Return(expectedErr).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.DisburseLoan(context.Background(), 1)
+		assert.Equal(t, expectedErr, err)
+		mockLoanRepo.AssertExpectations(t)
+		mockLoanInstallmentRepo.AssertExpectations(t)
+	})
+
+	t.Run("cancelled meanwhile", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerLimitID: 1, LoanStatus: usecase.LoanStatusApproved}, nil).Once()
+		mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 6}, nil).Once()
+		mockLoanRepo.On("DisburseLoan", mock.Anything, mock.Anything, mock.Anything).Return(repository.ErrLoanStatusConflict).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.DisburseLoan(context.Background(), 1)
+		assert.ErrorIs(t, err, repository.ErrLoanStatusConflict)
+		mockLoanRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+}
+
+func TestCancelLoan(t *testing.T) {
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanRestructureRepo := new(mocks.LoanRestructureRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+	mockNotificationRepo := new(mocks.MerchantNotificationRepository)
+
+	loanConfig := config.LoanConfig{CoolingOffPeriod: 14 * 24 * time.Hour}
+	uc := usecase.NewLoanUsecase(mockLoanRepo, mockLoanInstallmentRepo, mockLoanRestructureRepo, mockConsumerLimitRepo, mockConsumerRepo, mockMerchantRepo, new(mocks.LoanProductRepository), mockLedgerRepo, mockNotificationRepo, loanConfig, time.Second*2)
+
+	t.Run("success", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+			ID:             1,
+			MerchantID:     3,
+			LoanAmount:     money.New(1000),
+			ContractNumber: "CN-1",
+			AssetName:      "Phone",
+			LoanStatus:     usecase.LoanStatusApproved,
+		}, nil).Once()
+		mockLoanRepo.On("HasLoanTransactions", mock.Anything, int64(1), mock.Anything).Return(false, nil).Once()
+		mockLoanRepo.On("UpdateLoanStatus", mock.Anything, repository.UpdateLoanStatusRequest{
+			ID:           1,
+			FromStatus:   usecase.LoanStatusApproved,
+			LoanStatus:   usecase.LoanStatusCancelled,
+			StatusReason: "changed mind",
+		}, mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceType == usecase.JournalReferenceLoanRelease && entry.ReferenceID == 1
+		}), mock.Anything).Return(int64(1), nil).Once()
+		mockNotificationRepo.On("CreateMerchantNotification", mock.Anything, repository.MerchantNotification{
+			MerchantID: 3,
+			LoanID:     1,
+			EventType:  repository.MerchantNotificationLoanCancelled,
+			Message:    "Loan CN-1 for Phone was cancelled: changed mind",
+		}, mock.Anything).Return(int64(1), nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.CancelLoan(context.Background(), usecase.LoanStatusRequest{LoanID: 1, Reason: "changed mind"})
+		assert.NoError(t, err)
+		mockLoanRepo.AssertExpectations(t)
+		mockNotificationRepo.AssertExpectations(t)
+	})
+
+	t.Run("financed fees are released", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(2), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(2)).Return(repository.Loan{
+			ID:              2,
+			LoanAmount:      money.New(1030),
+			AdminFeeAmount:  money.New(10),
+			InsuranceAmount: money.New(20),
+			FeePayment:      usecase.FeePaymentFinanced,
+			LoanStatus:      usecase.LoanStatusApproved,
+		}, nil).Once()
+		mockLoanRepo.On("HasLoanTransactions", mock.Anything, int64(2), mock.Anything).Return(false, nil).Once()
+		mockLoanRepo.On("UpdateLoanStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceID == 2 && assert.ObjectsAreEqual([]repository.JournalLine{
+				{AccountCode: usecase.LedgerAccountMerchantPayable, DebitAmount: money.New(1000)},
+				{AccountCode: usecase.LedgerAccountAdminFeeIncome, DebitAmount: money.New(10)},
+				{AccountCode: usecase.LedgerAccountInsurancePayable, DebitAmount: money.New(20)},
+				{AccountCode: usecase.LedgerAccountLoanReceivable, CreditAmount: money.New(1030)},
+			}, entry.Lines)
+		}), mock.Anything).Return(int64(2), nil).Once()
+		mockNotificationRepo.On("CreateMerchantNotification", mock.Anything, mock.MatchedBy(func(notification repository.MerchantNotification) bool {
+			return notification.LoanID == 2 && notification.RefundAmount.IsZero()
+		}), mock.Anything).Return(int64(2), nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.CancelLoan(context.Background(), usecase.LoanStatusRequest{LoanID: 2, Reason: "changed mind"})
+		assert.NoError(t, err)
+		mockLedgerRepo.AssertExpectations(t)
+		mockNotificationRepo.AssertExpectations(t)
+	})
+
+	t.Run("disbursed within the cooling-off period", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(3), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(3)).Return(repository.Loan{
+			ID:              3,
+			MerchantID:      3,
+			LoanAmount:      money.New(1030),
+			AdminFeeAmount:  money.New(10),
+			InsuranceAmount: money.New(20),
+			FeePayment:      usecase.FeePaymentFinanced,
+			LoanStatus:      usecase.LoanStatusDisbursed,
+			DisbursedAt:     time.Now().Add(-24 * time.Hour),
+		}, nil).Once()
+		mockLoanRepo.On("HasLoanTransactions", mock.Anything, int64(3), mock.Anything).Return(false, nil).Once()
+		mockLoanRepo.On("UpdateLoanStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceID == 3
+		}), mock.Anything).Return(int64(3), nil).Once()
+		mockNotificationRepo.On("CreateMerchantNotification", mock.Anything, mock.MatchedBy(func(notification repository.MerchantNotification) bool {
+			return notification.LoanID == 3 && notification.RefundAmount == money.New(1000)
+		}), mock.Anything).Return(int64(3), nil).Once()
+		mockLoanRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.CancelLoan(context.Background(), usecase.LoanStatusRequest{LoanID: 3, Reason: "asset returned"})
+		assert.NoError(t, err)
+		mockLoanRepo.AssertExpectations(t)
+		mockNotificationRepo.AssertExpectations(t)
+	})
+
+	t.Run("disbursed past the cooling-off period", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+			ID:          1,
+			LoanStatus:  usecase.LoanStatusDisbursed,
+			DisbursedAt: time.Now().Add(-15 * 24 * time.Hour),
+		}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.CancelLoan(context.Background(), usecase.LoanStatusRequest{LoanID: 1, Reason: "changed mind"})
+		assert.ErrorIs(t, err, usecase.ErrLoanNotCancellable)
+		assert.Contains(t, err.Error(), "the cooling-off period ended on")
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("payments were made", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+			ID:          1,
+			LoanStatus:  usecase.LoanStatusDisbursed,
+			DisbursedAt: time.Now().Add(-24 * time.Hour),
+		}, nil).Once()
+		mockLoanRepo.On("HasLoanTransactions", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.CancelLoan(context.Background(), usecase.LoanStatusRequest{LoanID: 1, Reason: "changed mind"})
+		assert.ErrorIs(t, err, usecase.ErrLoanNotCancellable)
+		assert.Equal(t, "loan cannot be cancelled: payments were made on it", err.Error())
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("already repaying", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, LoanStatus: usecase.LoanStatusOnGoing}, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.CancelLoan(context.Background(), usecase.LoanStatusRequest{LoanID: 1, Reason: "changed mind"})
+		assert.ErrorIs(t, err, usecase.ErrLoanNotCancellable)
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("loan not found", func(t *testing.T) {
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(9), mock.Anything).Return(false, nil).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.CancelLoan(context.Background(), usecase.LoanStatusRequest{LoanID: 9, Reason: "changed mind"})
+		assert.Error(t, err)
+		assert.Equal(t, "loan not found", err.Error())
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("error creating merchant notification", func(t *testing.T) {
+		expectedErr := errors.New("unexpected error")
+
+		mockLoanRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+		mockLoanRepo.On("LockLoanByID", mock.Anything, int64(4), mock.Anything).Return(true, nil).Once()
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(4)).Return(repository.Loan{ID: 4, LoanAmount: money.New(1000), LoanStatus: usecase.LoanStatusPendingReview}, nil).Once()
+		mockLoanRepo.On("HasLoanTransactions", mock.Anything, int64(4), mock.Anything).Return(false, nil).Once()
+		mockLoanRepo.On("UpdateLoanStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+			return entry.ReferenceID == 4
+		}), mock.Anything).Return(int64(4), nil).Once()
+		mockNotificationRepo.On("CreateMerchantNotification", mock.Anything, mock.MatchedBy(func(notification repository.MerchantNotification) bool {
+			return notification.LoanID == 4
+		}), mock.Anything).Return(int64(0), expectedErr).Once()
+		mockLoanRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := uc.CancelLoan(context.Background(), usecase.LoanStatusRequest{LoanID: 4, Reason: "changed mind"})
+		assert.Equal(t, expectedErr, err)
+		mockLoanRepo.AssertExpectations(t)
+	})
+}
@@ -2,9 +2,13 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
 )
 
@@ -14,11 +18,13 @@ type MerchantUsecase interface {
 	DeleteMerchant(ctx context.Context, id int64) (err error)
 	FetchMerchant(ctx context.Context, req FetchMerchantRequest) (response []GetMerchantResponse, err error)
 	CreateMerchant(ctx context.Context, request MerchantRequest) (err error)
+	GetMerchantNotifications(ctx context.Context, req FetchMerchantNotificationsRequest) (response []MerchantNotificationResponse, err error)
 }
 
 type merchantUsecase struct {
-	merchantRepo repository.MerchantRepository
-	ctxTimeout   time.Duration
+	merchantRepo     repository.MerchantRepository
+	notificationRepo repository.MerchantNotificationRepository
+	ctxTimeout       time.Duration
 }
 
 type (
@@ -38,15 +44,34 @@ type (
 		Page  int `json:"page"`
 		Limit int `json:"limit"`
 	}
+
+	FetchMerchantNotificationsRequest struct {
+		MerchantID int64 `json:"-"`
+		Page       int   `json:"page" query:"page"`
+		Limit      int   `json:"limit" query:"limit"`
+	}
+
+	// MerchantNotificationResponse is a change to a loan the merchant has to know about.
+	// RefundAmount is what the merchant has to pay back for it.
+	MerchantNotificationResponse struct {
+		ID           int64       `json:"id"`
+		LoanID       int64       `json:"loan_id"`
+		EventType    string      `json:"event_type"`
+		Message      string      `json:"message"`
+		RefundAmount money.Money `json:"refund_amount"`
+		CreatedAt    string      `json:"created_at"`
+	}
 )
 
 func NewMerchantUsecase(
 	merchantRepo repository.MerchantRepository,
+	notificationRepo repository.MerchantNotificationRepository,
 	timeout time.Duration,
 ) MerchantUsecase {
 	return &merchantUsecase{
-		merchantRepo: merchantRepo,
-		ctxTimeout:   timeout,
+		merchantRepo:     merchantRepo,
+		notificationRepo: notificationRepo,
+		ctxTimeout:       timeout,
 	}
 }
 
@@ -139,3 +164,43 @@ func (uc *merchantUsecase) CreateMerchant(ctx context.Context, request MerchantR
 
 	return nil
 }
+
+// GetMerchantNotifications returns the notifications of a merchant, latest first.
+func (uc *merchantUsecase) GetMerchantNotifications(ctx context.Context, req FetchMerchantNotificationsRequest) (response []MerchantNotificationResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	merchant, err := uc.merchantRepo.GetMerchantByID(ctx, req.MerchantID)
+	if err != nil {
+		return response, err
+	}
+	if merchant.ID == 0 {
+		return response, errors.New("merchant not found")
+	}
+
+	limit, offset := utils.ParsePagination(req.Page, req.Limit)
+
+	notifications, err := uc.notificationRepo.GetMerchantNotificationsByMerchantID(ctx, repository.FetchMerchantNotificationsRequest{
+		MerchantID: req.MerchantID,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("[MerchantUsecase][GetMerchantNotifications] while get merchant notifications, Err: %+v", err))
+		return response, err
+	}
+
+	response = make([]MerchantNotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		response = append(response, MerchantNotificationResponse{
+			ID:           notification.ID,
+			LoanID:       notification.LoanID,
+			EventType:    notification.EventType,
+			Message:      notification.Message,
+			RefundAmount: notification.RefundAmount,
+			CreatedAt:    notification.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return response, nil
+}
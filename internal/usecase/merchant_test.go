@@ -9,13 +9,14 @@ import (
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 func TestGetMerchantByID(t *testing.T) {
 	mockRepo := new(mocks.MerchantRepository)
-	uc := usecase.NewMerchantUsecase(mockRepo, time.Second*2)
+	uc := usecase.NewMerchantUsecase(mockRepo, new(mocks.MerchantNotificationRepository), time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		mockMerchant := repository.Merchant{
@@ -54,7 +55,7 @@ func TestGetMerchantByID(t *testing.T) {
 
 func TestUpdateMerchant(t *testing.T) {
 	mockRepo := new(mocks.MerchantRepository)
-	uc := usecase.NewMerchantUsecase(mockRepo, time.Second*2)
+	uc := usecase.NewMerchantUsecase(mockRepo, new(mocks.MerchantNotificationRepository), time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		mockRequest := usecase.MerchantRequest{
@@ -97,7 +98,7 @@ func TestUpdateMerchant(t *testing.T) {
 
 func TestDeleteMerchant(t *testing.T) {
 	mockRepo := new(mocks.MerchantRepository)
-	uc := usecase.NewMerchantUsecase(mockRepo, time.Second*2)
+	uc := usecase.NewMerchantUsecase(mockRepo, new(mocks.MerchantNotificationRepository), time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		mockRepo.On("DeleteMerchant", mock.Anything, int64(1)).Return(nil)
@@ -122,7 +123,7 @@ func TestDeleteMerchant(t *testing.T) {
 
 func TestFetchMerchant(t *testing.T) {
 	mockRepo := new(mocks.MerchantRepository)
-	uc := usecase.NewMerchantUsecase(mockRepo, time.Second*2)
+	uc := usecase.NewMerchantUsecase(mockRepo, new(mocks.MerchantNotificationRepository), time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		mockMerchants := []repository.Merchant{
@@ -188,7 +189,7 @@ func TestFetchMerchant(t *testing.T) {
 
 func TestCreateMerchant(t *testing.T) {
 	mockRepo := new(mocks.MerchantRepository)
-	uc := usecase.NewMerchantUsecase(mockRepo, time.Second*2)
+	uc := usecase.NewMerchantUsecase(mockRepo, new(mocks.MerchantNotificationRepository), time.Second*2)
 
 	t.Run("success", func(t *testing.T) {
 		mockRequest := usecase.MerchantRequest{
@@ -226,3 +227,61 @@ func TestCreateMerchant(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestGetMerchantNotifications(t *testing.T) {
+	mockRepo := new(mocks.MerchantRepository)
+	mockNotificationRepo := new(mocks.MerchantNotificationRepository)
+	uc := usecase.NewMerchantUsecase(mockRepo, mockNotificationRepo, time.Second*2)
+
+	t.Run("success", func(t *testing.T) {
+		createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+		mockRepo.On("GetMerchantByID", mock.Anything, int64(1)).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockNotificationRepo.On("GetMerchantNotificationsByMerchantID", mock.Anything, repository.FetchMerchantNotificationsRequest{MerchantID: 1, Limit: 5, Offset: 5}).Return([]repository.MerchantNotification{
+			{
+				ID:           3,
+				MerchantID:   1,
+				LoanID:       2,
+				EventType:    repository.MerchantNotificationLoanCancelled,
+				Message:      "Loan CN-2 for Phone was cancelled: asset returned",
+				RefundAmount: money.New(1000),
+				CreatedAt:    createdAt,
+			},
+		}, nil).Once()
+
+		response, err := uc.GetMerchantNotifications(context.Background(), usecase.FetchMerchantNotificationsRequest{MerchantID: 1, Page: 2, Limit: 5})
+		assert.NoError(t, err)
+		assert.Equal(t, []usecase.MerchantNotificationResponse{
+			{
+				ID:           3,
+				LoanID:       2,
+				EventType:    repository.MerchantNotificationLoanCancelled,
+				Message:      "Loan CN-2 for Phone was cancelled: asset returned",
+				RefundAmount: money.New(1000),
+				CreatedAt:    "2024-03-01 10:00:00",
+			},
+		}, response)
+		mockRepo.AssertExpectations(t)
+		mockNotificationRepo.AssertExpectations(t)
+	})
+
+	t.Run("merchant not found", func(t *testing.T) {
+		mockRepo.On("GetMerchantByID", mock.Anything, int64(2)).Return(repository.Merchant{}, nil).Once()
+
+		response, err := uc.GetMerchantNotifications(context.Background(), usecase.FetchMerchantNotificationsRequest{MerchantID: 2})
+		assert.Error(t, err)
+		assert.Equal(t, "merchant not found", err.Error())
+		assert.Nil(t, response)
+	})
+
+	t.Run("error fetching notifications", func(t *testing.T) {
+		expectedErr := errors.New("unexpected error")
+
+		mockRepo.On("GetMerchantByID", mock.Anything, int64(1)).Return(repository.Merchant{ID: 1}, nil).Once()
+		mockNotificationRepo.On("GetMerchantNotificationsByMerchantID", mock.Anything, repository.FetchMerchantNotificationsRequest{MerchantID: 1, Limit: 10, Offset: 0}).Return(nil, expectedErr).Once()
+
+		response, err := uc.GetMerchantNotifications(context.Background(), usecase.FetchMerchantNotificationsRequest{MerchantID: 1})
+		assert.Equal(t, expectedErr, err)
+		assert.Nil(t, response)
+	})
+}
@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/gateway"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+// ErrPaymentCallbackInProgress is returned for a callback notifying a payment that is still
+// being booked, the gateway is expected to retry it later.
+var ErrPaymentCallbackInProgress = errors.New("payment callback is still being processed")
+
+var (
+	// loanStatusPayable lists the statuses of loans that are being repaid and so can be paid
+	// into a virtual account.
+	loanStatusPayable = map[string]bool{
+		LoanStatusDisbursed: true,
+		LoanStatusOnGoing:   true,
+		LoanStatusLate:      true,
+	}
+)
+
+type PaymentUsecase interface {
+	CreateVirtualAccount(ctx context.Context, req CreateVirtualAccountRequest) (response VirtualAccountResponse, err error)
+	HandleCallback(ctx context.Context, req gateway.CallbackRequest) (response PaymentCallbackResponse, err error)
+	SimulatePayment(ctx context.Context, req SimulatePaymentRequest) (response PaymentCallbackResponse, err error)
+}
+
+type paymentUsecase struct {
+	virtualAccountRepo  repository.VirtualAccountRepository
+	paymentCallbackRepo repository.PaymentCallbackRepository
+	loanRepo            repository.LoanRepository
+	consumerRepo        repository.ConsumerRepository
+	transactionUC       TransactionUsecase
+	paymentGateway      gateway.PaymentGateway
+	ctxTimeout          time.Duration
+}
+
+type (
+	CreateVirtualAccountRequest struct {
+		LoanID int64 `json:"loan_id"`
+	}
+
+	SimulatePaymentRequest struct {
+		VirtualAccountNumber string      `json:"virtual_account_number"`
+		Amount               money.Money `json:"amount"`
+	}
+
+	VirtualAccountResponse struct {
+		ID            int64  `json:"id"`
+		LoanID        int64  `json:"loan_id"`
+		Provider      string `json:"provider"`
+		BankCode      string `json:"bank_code"`
+		AccountNumber string `json:"account_number"`
+		AccountName   string `json:"account_name"`
+	}
+
+	// PaymentCallbackResponse is the payment booked for a callback. Replayed is true when the
+	// callback was already processed and the payment was booked by an earlier notification.
+	PaymentCallbackResponse struct {
+		ExternalID  string                 `json:"external_id"`
+		Replayed    bool                   `json:"replayed"`
+		Transaction GetTransactionResponse `json:"transaction"`
+	}
+)
+
+func NewPaymentUsecase(
+	virtualAccountRepo repository.VirtualAccountRepository,
+	paymentCallbackRepo repository.PaymentCallbackRepository,
+	loanRepo repository.LoanRepository,
+	consumerRepo repository.ConsumerRepository,
+	transactionUC TransactionUsecase,
+	paymentGateway gateway.PaymentGateway,
+	timeout time.Duration,
+) PaymentUsecase {
+	return &paymentUsecase{
+		virtualAccountRepo:  virtualAccountRepo,
+		paymentCallbackRepo: paymentCallbackRepo,
+		loanRepo:            loanRepo,
+		consumerRepo:        consumerRepo,
+		transactionUC:       transactionUC,
+		paymentGateway:      paymentGateway,
+		ctxTimeout:          timeout,
+	}
+}
+
+// CreateVirtualAccount issues the virtual account of a loan at the configured gateway. A
+// loan keeps a single account per gateway, so the account issued before is returned when
+// there is one.
+func (uc *paymentUsecase) CreateVirtualAccount(ctx context.Context, req CreateVirtualAccountRequest) (response VirtualAccountResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	loan, err := uc.loanRepo.GetLoanByID(ctx, req.LoanID)
+	if err != nil {
+		return response, err
+	}
+	if loan.ID == 0 {
+		return response, errors.New("loan not found")
+	}
+	if !loanStatusPayable[loan.LoanStatus] {
+		return response, fmt.Errorf("virtual account cannot be issued for a %s loan", loan.LoanStatus)
+	}
+
+	provider := uc.paymentGateway.Provider()
+	account, err := uc.virtualAccountRepo.GetVirtualAccountByLoanID(ctx, provider, loan.ID)
+	if err != nil {
+		return response, err
+	}
+	if account.ID != 0 {
+		return toVirtualAccountResponse(account), nil
+	}
+
+	consumer, err := uc.consumerRepo.GetConsumerByID(ctx, loan.ConsumerID)
+	if err != nil {
+		return response, err
+	}
+
+	issued, err := uc.paymentGateway.CreateVirtualAccount(ctx, gateway.VirtualAccountRequest{
+		LoanID:         loan.ID,
+		ConsumerID:     loan.ConsumerID,
+		ContractNumber: loan.ContractNumber,
+		Name:           consumer.FullName,
+	})
+	if err != nil {
+		return response, err
+	}
+
+	account = repository.VirtualAccount{
+		LoanID:        loan.ID,
+		ConsumerID:    loan.ConsumerID,
+		Provider:      provider,
+		BankCode:      issued.BankCode,
+		AccountNumber: issued.AccountNumber,
+		AccountName:   issued.AccountName,
+	}
+
+	account.ID, err = uc.virtualAccountRepo.CreateVirtualAccount(ctx, account)
+	if err != nil {
+		return response, err
+	}
+
+	return toVirtualAccountResponse(account), nil
+}
+
+// HandleCallback books the payment notified by a gateway callback as a partial payment of
+// the loan the virtual account was issued for. Gateways retry a notification until it is
+// acknowledged, so a payment is booked once per external ID: a retry of a processed
+// callback returns the transaction booked before, and a retry of a failed one books it
+// again. A callback left received is in progress unless its payment was booked already, when
+// marking it processed failed, then it is marked processed and replayed.
+func (uc *paymentUsecase) HandleCallback(ctx context.Context, req gateway.CallbackRequest) (response PaymentCallbackResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	callback, err := uc.paymentGateway.ParseCallback(req, time.Now())
+	if err != nil {
+		return response, err
+	}
+	response.ExternalID = callback.ExternalID
+
+	provider := uc.paymentGateway.Provider()
+	account, err := uc.virtualAccountRepo.GetVirtualAccountByNumber(ctx, provider, callback.VirtualAccountNumber)
+	if err != nil {
+		return response, err
+	}
+	if account.ID == 0 {
+		return response, fmt.Errorf("%w: virtual account not found", gateway.ErrInvalidCallback)
+	}
+
+	created, err := uc.paymentCallbackRepo.CreatePaymentCallback(ctx, repository.PaymentCallback{
+		Provider:         provider,
+		ExternalID:       callback.ExternalID,
+		VirtualAccountID: account.ID,
+		Amount:           callback.Amount,
+		PaidAt:           callback.PaidAt,
+		Payload:          string(req.Body),
+	})
+	if err != nil {
+		return response, err
+	}
+
+	stored, err := uc.paymentCallbackRepo.GetPaymentCallbackByExternalID(ctx, provider, callback.ExternalID)
+	if err != nil {
+		return response, err
+	}
+	if stored.ID == 0 {
+		return response, errors.New("payment callback not found")
+	}
+
+	if !created {
+		if stored.VirtualAccountID != account.ID || stored.Amount != callback.Amount {
+			return response, fmt.Errorf("%w: external_id was already notified for another payment", gateway.ErrInvalidCallback)
+		}
+
+		switch stored.CallbackStatus {
+		case repository.PaymentCallbackStatusProcessed:
+			response.Replayed = true
+			response.Transaction, err = uc.transactionUC.GetTransactionByID(ctx, stored.TransactionID)
+			if err != nil {
+				return response, err
+			}
+
+			return response, nil
+		case repository.PaymentCallbackStatusFailed:
+			claimed, err := uc.paymentCallbackRepo.ClaimFailedPaymentCallback(ctx, stored.ID)
+			if err != nil {
+				return response, err
+			}
+			if !claimed {
+				return response, ErrPaymentCallbackInProgress
+			}
+		default:
+			booked, err := uc.transactionUC.FetchTransactions(ctx, FetchTransactionsRequest{
+				Channel:           TransactionChannelVirtualAccount,
+				ExternalReference: callback.ExternalID,
+				Limit:             1,
+			})
+			if err != nil {
+				return response, err
+			}
+			if len(booked) == 0 {
+				return response, ErrPaymentCallbackInProgress
+			}
+
+			err = uc.paymentCallbackRepo.UpdatePaymentCallback(ctx, repository.UpdatePaymentCallbackRequest{
+				ID:             stored.ID,
+				CallbackStatus: repository.PaymentCallbackStatusProcessed,
+				TransactionID:  booked[0].ID,
+			})
+			if err != nil {
+				return response, err
+			}
+
+			response.Replayed = true
+			response.Transaction = booked[0]
+			return response, nil
+		}
+	}
+
+	transaction, err := uc.transactionUC.CreateTransaction(ctx, TransactionRequest{
+		ConsumerID:        account.ConsumerID,
+		LoanID:            account.LoanID,
+		TramsactionType:   "partial",
+		Amount:            callback.Amount,
+		Channel:           TransactionChannelVirtualAccount,
+		ExternalReference: callback.ExternalID,
+		ValueDate:         callback.PaidAt.Format("2006-01-02"),
+	})
+	if err != nil {
+		updateErr := uc.paymentCallbackRepo.UpdatePaymentCallback(ctx, repository.UpdatePaymentCallbackRequest{
+			ID:             stored.ID,
+			CallbackStatus: repository.PaymentCallbackStatusFailed,
+			FailureReason:  truncateString(err.Error(), 255),
+		})
+		if updateErr != nil {
+			logger.Error(fmt.Sprintf("[paymentUsecase][HandleCallback] while mark callback %d failed. Err: %v", stored.ID, updateErr))
+		}
+
+		return response, err
+	}
+
+	// the payment is booked at this point, a callback left received is marked processed by
+	// its next retry and never booked twice
+	err = uc.paymentCallbackRepo.UpdatePaymentCallback(ctx, repository.UpdatePaymentCallbackRequest{
+		ID:             stored.ID,
+		CallbackStatus: repository.PaymentCallbackStatusProcessed,
+		TransactionID:  transaction.ID,
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("[paymentUsecase][HandleCallback] while mark callback %d processed. Err: %v", stored.ID, err))
+	}
+
+	response.Transaction = transaction
+	return response, nil
+}
+
+// SimulatePayment pays into a virtual account through the callback the gateway would post
+// for the transfer. Only gateways made for local development can simulate payments.
+func (uc *paymentUsecase) SimulatePayment(ctx context.Context, req SimulatePaymentRequest) (response PaymentCallbackResponse, err error) {
+	simulator, ok := uc.paymentGateway.(gateway.PaymentSimulator)
+	if !ok {
+		return response, fmt.Errorf("payment gateway %s cannot simulate payments", uc.paymentGateway.Provider())
+	}
+
+	callback, err := simulator.SimulatePayment(req.VirtualAccountNumber, req.Amount, time.Now())
+	if err != nil {
+		return response, err
+	}
+
+	return uc.HandleCallback(ctx, callback)
+}
+
+func toVirtualAccountResponse(account repository.VirtualAccount) VirtualAccountResponse {
+	return VirtualAccountResponse{
+		ID:            account.ID,
+		LoanID:        account.LoanID,
+		Provider:      account.Provider,
+		BankCode:      account.BankCode,
+		AccountNumber: account.AccountNumber,
+		AccountName:   account.AccountName,
+	}
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"sort"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+const (
+	AllocationComponentPenalty       = "penalty"
+	AllocationComponentInterest      = "interest"
+	AllocationComponentPrincipal     = "principal"
+	AllocationComponentSettlementFee = "settlement_fee"
+	AllocationComponentCredit        = "credit"
+)
+
+var defaultAllocationOrder = []string{
+	AllocationComponentPenalty,
+	AllocationComponentInterest,
+	AllocationComponentPrincipal,
+}
+
+// PaymentAllocation is the part of a payment applied to one component of an installment.
+// Settlement fees and credit do not belong to an installment and have no installment number.
+type PaymentAllocation struct {
+	InstallmentNumber int32       `json:"installment_number,omitempty"`
+	Component         string      `json:"component"`
+	Amount            money.Money `json:"amount"`
+}
+
+// PaymentAllocator splits a payment over what is due on a loan (the waterfall).
+type PaymentAllocator struct {
+	order []string
+}
+
+// NewPaymentAllocator returns an allocator paying the components of an installment in the
+// given order. Unknown components are ignored and missing ones are paid last, in the
+// default order penalty, interest, principal.
+func NewPaymentAllocator(order []string) PaymentAllocator {
+	seen := make(map[string]bool, len(defaultAllocationOrder))
+	allocationOrder := make([]string, 0, len(defaultAllocationOrder))
+	for _, component := range append(append([]string{}, order...), defaultAllocationOrder...) {
+		switch component {
+		case AllocationComponentPenalty, AllocationComponentInterest, AllocationComponentPrincipal:
+			if !seen[component] {
+				seen[component] = true
+				allocationOrder = append(allocationOrder, component)
+			}
+		}
+	}
+
+	return PaymentAllocator{order: allocationOrder}
+}
+
+// Allocate applies amount to the unpaid penalties and installments, the oldest installment
+// first and within an installment its components in the allocation order. The part of
+// amount left once everything is paid is returned as credit.
+func (a PaymentAllocator) Allocate(amount money.Money, installments []repository.LoanInstallment, penalties []repository.LoanPenalty) (allocations []PaymentAllocation, credit money.Money) {
+	due := make(map[int32]map[string]money.Money)
+	dueOf := func(installmentNumber int32) map[string]money.Money {
+		if due[installmentNumber] == nil {
+			due[installmentNumber] = make(map[string]money.Money, len(a.order))
+		}
+		return due[installmentNumber]
+	}
+
+	for _, installment := range installments {
+		components := dueOf(installment.InstallmentNumber)
+		components[AllocationComponentInterest] = money.Max(money.Zero, installment.InterestAmount.Sub(installment.PaidInterestAmount))
+		components[AllocationComponentPrincipal] = money.Max(money.Zero, installment.PrincipalAmount.Sub(installment.PaidPrincipalAmount))
+	}
+	for _, penalty := range penalties {
+		dueOf(penalty.InstallmentNumber)[AllocationComponentPenalty] = money.Max(money.Zero, penalty.PenaltyAmount.Sub(penalty.PaidPenaltyAmount))
+	}
+
+	installmentNumbers := make([]int32, 0, len(due))
+	for installmentNumber := range due {
+		installmentNumbers = append(installmentNumbers, installmentNumber)
+	}
+	sort.Slice(installmentNumbers, func(i, j int) bool { return installmentNumbers[i] < installmentNumbers[j] })
+
+	remaining := amount
+	for _, installmentNumber := range installmentNumbers {
+		for _, component := range a.order {
+			if !remaining.IsPositive() {
+				return allocations, money.Zero
+			}
+
+			allocated := money.Min(remaining, due[installmentNumber][component])
+			if !allocated.IsPositive() {
+				continue
+			}
+
+			allocations = append(allocations, PaymentAllocation{
+				InstallmentNumber: installmentNumber,
+				Component:         component,
+				Amount:            allocated,
+			})
+			remaining = remaining.Sub(allocated)
+		}
+	}
+
+	return allocations, money.Max(money.Zero, remaining)
+}
+
+// allocatedAmount adds up the allocations of one component of an installment.
+func allocatedAmount(allocations []PaymentAllocation, installmentNumber int32, component string) (amount money.Money) {
+	for _, allocation := range allocations {
+		if allocation.InstallmentNumber == installmentNumber && allocation.Component == component {
+			amount = amount.Add(allocation.Amount)
+		}
+	}
+
+	return amount
+}
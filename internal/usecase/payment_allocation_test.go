@@ -0,0 +1,71 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentAllocatorAllocate(t *testing.T) {
+	installments := []repository.LoanInstallment{
+		{InstallmentNumber: 3, PrincipalAmount: money.New(100), InterestAmount: money.New(10)},
+		{InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidInterestAmount: money.New(4)},
+	}
+	penalties := []repository.LoanPenalty{
+		{InstallmentNumber: 2, PenaltyAmount: money.New(5), PaidPenaltyAmount: money.New(2)},
+	}
+
+	tests := []struct {
+		name            string
+		order           []string
+		amount          money.Money
+		wantAllocations []usecase.PaymentAllocation
+		wantCredit      money.Money
+	}{
+		{
+			name:   "penalty, interest and principal of the oldest installment first",
+			amount: money.New(120),
+			wantAllocations: []usecase.PaymentAllocation{
+				{InstallmentNumber: 2, Component: usecase.AllocationComponentPenalty, Amount: money.New(3)},
+				{InstallmentNumber: 2, Component: usecase.AllocationComponentInterest, Amount: money.New(6)},
+				{InstallmentNumber: 2, Component: usecase.AllocationComponentPrincipal, Amount: money.New(100)},
+				{InstallmentNumber: 3, Component: usecase.AllocationComponentInterest, Amount: money.New(10)},
+				{InstallmentNumber: 3, Component: usecase.AllocationComponentPrincipal, Amount: money.New(1)},
+			},
+			wantCredit: money.Zero,
+		},
+		{
+			name:   "configured order, missing components paid last",
+			order:  []string{"principal", "unknown", "interest"},
+			amount: money.New(105),
+			wantAllocations: []usecase.PaymentAllocation{
+				{InstallmentNumber: 2, Component: usecase.AllocationComponentPrincipal, Amount: money.New(100)},
+				{InstallmentNumber: 2, Component: usecase.AllocationComponentInterest, Amount: money.New(5)},
+			},
+			wantCredit: money.Zero,
+		},
+		{
+			name:   "overpayment becomes credit",
+			amount: money.New(300),
+			wantAllocations: []usecase.PaymentAllocation{
+				{InstallmentNumber: 2, Component: usecase.AllocationComponentPenalty, Amount: money.New(3)},
+				{InstallmentNumber: 2, Component: usecase.AllocationComponentInterest, Amount: money.New(6)},
+				{InstallmentNumber: 2, Component: usecase.AllocationComponentPrincipal, Amount: money.New(100)},
+				{InstallmentNumber: 3, Component: usecase.AllocationComponentInterest, Amount: money.New(10)},
+				{InstallmentNumber: 3, Component: usecase.AllocationComponentPrincipal, Amount: money.New(100)},
+			},
+			wantCredit: money.New(81),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocations, credit := usecase.NewPaymentAllocator(tt.order).Allocate(tt.amount, installments, penalties)
+			assert.Equal(t, tt.wantAllocations, allocations)
+			assert.Equal(t, tt.wantCredit, credit)
+		})
+	}
+}
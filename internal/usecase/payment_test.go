@@ -0,0 +1,298 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/gateway"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateVirtualAccount(t *testing.T) {
+	mockVirtualAccountRepo := new(mocks.VirtualAccountRepository)
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	fake := gateway.NewFakeGateway(config.PaymentGatewayConfig{BankCode: "8808"})
+	uc := usecase.NewPaymentUsecase(mockVirtualAccountRepo, nil, mockLoanRepo, mockConsumerRepo, nil, fake, time.Second*2)
+
+	tests := []struct {
+		name    string
+		req     usecase.CreateVirtualAccountRequest
+		setup   func()
+		want    usecase.VirtualAccountResponse
+		wantErr string
+	}{
+		{
+			name: "loan not found",
+			req:  usecase.CreateVirtualAccountRequest{LoanID: 1},
+			setup: func() {
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{}, nil).Once()
+			},
+			wantErr: "loan not found",
+		},
+		{
+			name: "loan not disbursed",
+			req:  usecase.CreateVirtualAccountRequest{LoanID: 2},
+			setup: func() {
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(2)).Return(repository.Loan{ID: 2, LoanStatus: "approved"}, nil).Once()
+			},
+			wantErr: "virtual account cannot be issued for a approved loan",
+		},
+		{
+			name: "returns the account issued before",
+			req:  usecase.CreateVirtualAccountRequest{LoanID: 3},
+			setup: func() {
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(3)).Return(repository.Loan{ID: 3, ConsumerID: 1, LoanStatus: "on_going"}, nil).Once()
+				mockVirtualAccountRepo.On("GetVirtualAccountByLoanID", mock.Anything, "fake", int64(3)).Return(repository.VirtualAccount{
+					ID: 5, LoanID: 3, ConsumerID: 1, Provider: "fake", BankCode: "8808", AccountNumber: "8808000000000003", AccountName: "John Doe",
+				}, nil).Once()
+			},
+			want: usecase.VirtualAccountResponse{ID: 5, LoanID: 3, Provider: "fake", BankCode: "8808", AccountNumber: "8808000000000003", AccountName: "John Doe"},
+		},
+		{
+			name: "issues a new account",
+			req:  usecase.CreateVirtualAccountRequest{LoanID: 4},
+			setup: func() {
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(4)).Return(repository.Loan{ID: 4, ConsumerID: 1, ContractNumber: "XYZ-4", LoanStatus: "disbursed"}, nil).Once()
+				mockVirtualAccountRepo.On("GetVirtualAccountByLoanID", mock.Anything, "fake", int64(4)).Return(repository.VirtualAccount{}, nil).Once()
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1, FullName: "John Doe"}, nil).Once()
+				mockVirtualAccountRepo.On("CreateVirtualAccount", mock.Anything, repository.VirtualAccount{
+					LoanID: 4, ConsumerID: 1, Provider: "fake", BankCode: "8808", AccountNumber: "8808000000000004", AccountName: "John Doe",
+				}).Return(int64(6), nil).Once()
+			},
+			want: usecase.VirtualAccountResponse{ID: 6, LoanID: 4, Provider: "fake", BankCode: "8808", AccountNumber: "8808000000000004", AccountName: "John Doe"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, err := uc.CreateVirtualAccount(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	mockVirtualAccountRepo.AssertExpectations(t)
+	mockConsumerRepo.AssertExpectations(t)
+}
+
+func TestHandleCallback(t *testing.T) {
+	mockVirtualAccountRepo := new(mocks.VirtualAccountRepository)
+	mockPaymentCallbackRepo := new(mocks.PaymentCallbackRepository)
+	mockTransactionUC := new(mocks.TransactionUsecase)
+	fake := gateway.NewFakeGateway(config.PaymentGatewayConfig{BankCode: "8808", CallbackSecret: "secret", CallbackTolerance: 5 * time.Minute})
+	uc := usecase.NewPaymentUsecase(mockVirtualAccountRepo, mockPaymentCallbackRepo, nil, nil, mockTransactionUC, fake, time.Second*2)
+
+	account := repository.VirtualAccount{ID: 5, LoanID: 3, ConsumerID: 1, Provider: "fake", AccountNumber: "8808000000000003"}
+	transaction := usecase.GetTransactionResponse{ID: 9, ConsumerID: 1, LoanID: 3, Amount: money.New(500)}
+
+	// every case sends a new callback, as the gateway would for each notification
+	var callback gateway.CallbackRequest
+	newCallback := func(accountNumber string) gateway.CallbackRequest {
+		var err error
+		callback, err = fake.SimulatePayment(accountNumber, money.New(500), time.Now())
+		assert.NoError(t, err)
+		return callback
+	}
+	externalID := func() string {
+		parsed, err := fake.ParseCallback(callback, time.Now())
+		assert.NoError(t, err)
+		return parsed.ExternalID
+	}
+	transactionRequest := func() usecase.TransactionRequest {
+		return usecase.TransactionRequest{
+			ConsumerID:        1,
+			LoanID:            3,
+			TramsactionType:   "partial",
+			Amount:            money.New(500),
+			Channel:           "virtual_account",
+			ExternalReference: externalID(),
+			ValueDate:         time.Now().Format("2006-01-02"),
+		}
+	}
+	stored := func(status string, transactionID int64) repository.PaymentCallback {
+		return repository.PaymentCallback{ID: 7, Provider: "fake", ExternalID: externalID(), VirtualAccountID: 5, Amount: money.New(500), CallbackStatus: status, TransactionID: transactionID}
+	}
+
+	tests := []struct {
+		name    string
+		req     func() gateway.CallbackRequest
+		setup   func()
+		want    func() usecase.PaymentCallbackResponse
+		wantErr error
+		errMsg  string
+	}{
+		{
+			name: "invalid signature",
+			req: func() gateway.CallbackRequest {
+				req := newCallback("8808000000000003")
+				req.Signature = gateway.SignCallback("another secret", req.Timestamp, req.Body)
+				return req
+			},
+			setup:   func() {},
+			wantErr: gateway.ErrInvalidSignature,
+		},
+		{
+			name: "virtual account not found",
+			req:  func() gateway.CallbackRequest { return newCallback("8808000000000099") },
+			setup: func() {
+				mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000099").Return(repository.VirtualAccount{}, nil).Once()
+			},
+			wantErr: gateway.ErrInvalidCallback,
+		},
+		{
+			name: "books the payment",
+			req:  func() gateway.CallbackRequest { return newCallback("8808000000000003") },
+			setup: func() {
+				mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000003").Return(account, nil).Once()
+				mockPaymentCallbackRepo.On("CreatePaymentCallback", mock.Anything, mock.MatchedBy(func(c repository.PaymentCallback) bool {
+					return c.ExternalID == externalID() && c.VirtualAccountID == 5 && c.Amount == money.New(500) && c.Payload == string(callback.Body)
+				})).Return(true, nil).Once()
+				mockPaymentCallbackRepo.On("GetPaymentCallbackByExternalID", mock.Anything, "fake", externalID()).Return(stored("received", 0), nil).Once()
+				mockTransactionUC.On("CreateTransaction", mock.Anything, transactionRequest()).Return(transaction, nil).Once()
+				mockPaymentCallbackRepo.On("UpdatePaymentCallback", mock.Anything, repository.UpdatePaymentCallbackRequest{ID: 7, CallbackStatus: "processed", TransactionID: 9}).Return(nil).Once()
+			},
+			want: func() usecase.PaymentCallbackResponse {
+				return usecase.PaymentCallbackResponse{ExternalID: externalID(), Transaction: transaction}
+			},
+		},
+		{
+			name: "replays a processed callback",
+			req:  func() gateway.CallbackRequest { return newCallback("8808000000000003") },
+			setup: func() {
+				mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000003").Return(account, nil).Once()
+				mockPaymentCallbackRepo.On("CreatePaymentCallback", mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockPaymentCallbackRepo.On("GetPaymentCallbackByExternalID", mock.Anything, "fake", externalID()).Return(stored("processed", 9), nil).Once()
+				mockTransactionUC.On("GetTransactionByID", mock.Anything, int64(9)).Return(transaction, nil).Once()
+			},
+			want: func() usecase.PaymentCallbackResponse {
+				return usecase.PaymentCallbackResponse{ExternalID: externalID(), Replayed: true, Transaction: transaction}
+			},
+		},
+		{
+			name: "callback still being processed",
+			req:  func() gateway.CallbackRequest { return newCallback("8808000000000003") },
+			setup: func() {
+				mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000003").Return(account, nil).Once()
+				mockPaymentCallbackRepo.On("CreatePaymentCallback", mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockPaymentCallbackRepo.On("GetPaymentCallbackByExternalID", mock.Anything, "fake", externalID()).Return(stored("received", 0), nil).Once()
+				mockTransactionUC.On("FetchTransactions", mock.Anything, usecase.FetchTransactionsRequest{Channel: "virtual_account", ExternalReference: externalID(), Limit: 1}).Return([]usecase.GetTransactionResponse{}, nil).Once()
+			},
+			wantErr: usecase.ErrPaymentCallbackInProgress,
+		},
+		{
+			name: "replays a booked callback left received",
+			req:  func() gateway.CallbackRequest { return newCallback("8808000000000003") },
+			setup: func() {
+				mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000003").Return(account, nil).Once()
+				mockPaymentCallbackRepo.On("CreatePaymentCallback", mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockPaymentCallbackRepo.On("GetPaymentCallbackByExternalID", mock.Anything, "fake", externalID()).Return(stored("received", 0), nil).Once()
+				mockTransactionUC.On("FetchTransactions", mock.Anything, usecase.FetchTransactionsRequest{Channel: "virtual_account", ExternalReference: externalID(), Limit: 1}).Return([]usecase.GetTransactionResponse{transaction}, nil).Once()
+				mockPaymentCallbackRepo.On("UpdatePaymentCallback", mock.Anything, repository.UpdatePaymentCallbackRequest{ID: 7, CallbackStatus: "processed", TransactionID: 9}).Return(nil).Once()
+			},
+			want: func() usecase.PaymentCallbackResponse {
+				return usecase.PaymentCallbackResponse{ExternalID: externalID(), Replayed: true, Transaction: transaction}
+			},
+		},
+		{
+			name: "external id reused for another amount",
+			req:  func() gateway.CallbackRequest { return newCallback("8808000000000003") },
+			setup: func() {
+				mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000003").Return(account, nil).Once()
+				mockPaymentCallbackRepo.On("CreatePaymentCallback", mock.Anything, mock.Anything).Return(false, nil).Once()
+				previous := stored("processed", 9)
+				previous.Amount = money.New(100)
+				mockPaymentCallbackRepo.On("GetPaymentCallbackByExternalID", mock.Anything, "fake", externalID()).Return(previous, nil).Once()
+			},
+			wantErr: gateway.ErrInvalidCallback,
+		},
+		{
+			name: "retries a failed callback",
+			req:  func() gateway.CallbackRequest { return newCallback("8808000000000003") },
+			setup: func() {
+				mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000003").Return(account, nil).Once()
+				mockPaymentCallbackRepo.On("CreatePaymentCallback", mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockPaymentCallbackRepo.On("GetPaymentCallbackByExternalID", mock.Anything, "fake", externalID()).Return(stored("failed", 0), nil).Once()
+				mockPaymentCallbackRepo.On("ClaimFailedPaymentCallback", mock.Anything, int64(7)).Return(true, nil).Once()
+				mockTransactionUC.On("CreateTransaction", mock.Anything, transactionRequest()).Return(transaction, nil).Once()
+				mockPaymentCallbackRepo.On("UpdatePaymentCallback", mock.Anything, repository.UpdatePaymentCallbackRequest{ID: 7, CallbackStatus: "processed", TransactionID: 9}).Return(nil).Once()
+			},
+			want: func() usecase.PaymentCallbackResponse {
+				return usecase.PaymentCallbackResponse{ExternalID: externalID(), Transaction: transaction}
+			},
+		},
+		{
+			name: "failed callback claimed by another retry",
+			req:  func() gateway.CallbackRequest { return newCallback("8808000000000003") },
+			setup: func() {
+				mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000003").Return(account, nil).Once()
+				mockPaymentCallbackRepo.On("CreatePaymentCallback", mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockPaymentCallbackRepo.On("GetPaymentCallbackByExternalID", mock.Anything, "fake", externalID()).Return(stored("failed", 0), nil).Once()
+				mockPaymentCallbackRepo.On("ClaimFailedPaymentCallback", mock.Anything, int64(7)).Return(false, nil).Once()
+			},
+			wantErr: usecase.ErrPaymentCallbackInProgress,
+		},
+		{
+			name: "payment cannot be booked",
+			req:  func() gateway.CallbackRequest { return newCallback("8808000000000003") },
+			setup: func() {
+				mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000003").Return(account, nil).Once()
+				mockPaymentCallbackRepo.On("CreatePaymentCallback", mock.Anything, mock.Anything).Return(true, nil).Once()
+				mockPaymentCallbackRepo.On("GetPaymentCallbackByExternalID", mock.Anything, "fake", externalID()).Return(stored("received", 0), nil).Once()
+				mockTransactionUC.On("CreateTransaction", mock.Anything, transactionRequest()).Return(usecase.GetTransactionResponse{}, errors.New(strings.Repeat("x", 300))).Once()
+				mockPaymentCallbackRepo.On("UpdatePaymentCallback", mock.Anything, repository.UpdatePaymentCallbackRequest{ID: 7, CallbackStatus: "failed", FailureReason: strings.Repeat("x", 255)}).Return(nil).Once()
+			},
+			errMsg: strings.Repeat("x", 300),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req()
+			tt.setup()
+			got, err := uc.HandleCallback(context.Background(), req)
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.errMsg != "":
+				assert.EqualError(t, err, tt.errMsg)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want(), got)
+			}
+		})
+	}
+
+	mockVirtualAccountRepo.AssertExpectations(t)
+	mockPaymentCallbackRepo.AssertExpectations(t)
+	mockTransactionUC.AssertExpectations(t)
+}
+
+type stubPaymentGateway struct {
+	gateway.PaymentGateway
+}
+
+func (stubPaymentGateway) Provider() string {
+	return "stub"
+}
+
+func TestSimulatePayment(t *testing.T) {
+	uc := usecase.NewPaymentUsecase(nil, nil, nil, nil, nil, stubPaymentGateway{}, time.Second*2)
+
+	_, err := uc.SimulatePayment(context.Background(), usecase.SimulatePaymentRequest{VirtualAccountNumber: "8808000000000003", Amount: money.New(500)})
+	assert.EqualError(t, err, "payment gateway stub cannot simulate payments")
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+const (
+	PenaltyTypePercentage = "percentage"
+	PenaltyTypeFixed      = "fixed"
+)
+
+// PenaltyCalculator computes the late payment penalty (denda) of overdue installments.
+type PenaltyCalculator struct {
+	policy config.PenaltyConfig
+}
+
+func NewPenaltyCalculator(policy config.PenaltyConfig) PenaltyCalculator {
+	return PenaltyCalculator{policy: policy}
+}
+
+// Calculate returns the penalty accrued by the outstanding part of an installment from
+// the day after its due date until asOf, capped at MaxRate percent of the installment.
+func (c PenaltyCalculator) Calculate(installment repository.LoanInstallment, asOf time.Time) (amount money.Money, overdueDays int32) {
+	overdueDays = daysBetween(installment.DueDate, asOf)
+	if overdueDays <= 0 {
+		return money.Zero, 0
+	}
+
+	outstanding := installment.PrincipalAmount.Add(installment.InterestAmount).
+		Sub(installment.PaidPrincipalAmount).Sub(installment.PaidInterestAmount)
+	if !outstanding.IsPositive() {
+		return money.Zero, 0
+	}
+
+	switch c.policy.Type {
+	case PenaltyTypeFixed:
+		amount = c.policy.Amount.Mul(int64(overdueDays)).Round()
+	default:
+		amount = outstanding.Mul(int64(overdueDays)).Percent(c.policy.Rate)
+	}
+
+	if c.policy.MaxRate > 0 {
+		maxAmount := installment.PrincipalAmount.Add(installment.InterestAmount).Percent(c.policy.MaxRate)
+		amount = money.Min(amount, maxAmount)
+	}
+
+	return amount, overdueDays
+}
+
+// Accrue brings the stored penalties of a loan up to asOf. Penalties of installments that
+// are already paid stay as they are, outstanding overdue installments accrue further.
+func (c PenaltyCalculator) Accrue(schedule []repository.LoanInstallment, stored []repository.LoanPenalty, asOf time.Time) []repository.LoanPenalty {
+	storedByInstallment := make(map[int32]repository.LoanPenalty, len(stored))
+	for _, penalty := range stored {
+		storedByInstallment[penalty.InstallmentNumber] = penalty
+	}
+
+	var penalties []repository.LoanPenalty
+	for _, installment := range schedule {
+		penalty, ok := storedByInstallment[installment.InstallmentNumber]
+
+		if !isInstallmentClosed(installment.InstallmentStatus) {
+			amount, overdueDays := c.Calculate(installment, asOf)
+			if amount.GreaterThan(penalty.PenaltyAmount) {
+				penalty.LoanID = installment.LoanID
+				penalty.InstallmentNumber = installment.InstallmentNumber
+				penalty.OverdueDays = overdueDays
+				penalty.PenaltyAmount = amount
+				penalty.AccruedUntil = asOf
+				ok = true
+			}
+		}
+
+		if ok {
+			penalties = append(penalties, penalty)
+		}
+	}
+
+	return penalties
+}
+
+// daysBetween returns the number of calendar days from one date to another.
+func daysBetween(from time.Time, to time.Time) int32 {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int32(toDate.Sub(fromDate).Hours() / 24)
+}
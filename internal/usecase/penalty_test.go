@@ -0,0 +1,107 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPenaltyCalculatorCalculate(t *testing.T) {
+	asOf := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	installment := repository.LoanInstallment{
+		InstallmentNumber: 1,
+		DueDate:           time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+		PrincipalAmount:   money.New(900000),
+		InterestAmount:    money.New(100000),
+		InstallmentStatus: "unpaid",
+	}
+
+	tests := []struct {
+		name            string
+		policy          config.PenaltyConfig
+		installment     repository.LoanInstallment
+		wantAmount      money.Money
+		wantOverdueDays int32
+	}{
+		{
+			name:            "percentage of overdue installment per day",
+			policy:          config.PenaltyConfig{Type: usecase.PenaltyTypePercentage, Rate: 0.1},
+			installment:     installment,
+			wantAmount:      money.New(10000),
+			wantOverdueDays: 10,
+		},
+		{
+			name:   "percentage rounded to whole rupiah",
+			policy: config.PenaltyConfig{Type: usecase.PenaltyTypePercentage, Rate: 0.1},
+			installment: repository.LoanInstallment{
+				DueDate:         installment.DueDate,
+				PrincipalAmount: money.New(900005),
+				InterestAmount:  money.New(100000),
+			},
+			wantAmount:      money.New(10000),
+			wantOverdueDays: 10,
+		},
+		{
+			name:            "fixed amount per day",
+			policy:          config.PenaltyConfig{Type: usecase.PenaltyTypeFixed, Amount: money.New(5000)},
+			installment:     installment,
+			wantAmount:      money.New(50000),
+			wantOverdueDays: 10,
+		},
+		{
+			name:            "capped at max rate of installment",
+			policy:          config.PenaltyConfig{Type: usecase.PenaltyTypeFixed, Amount: money.New(5000), MaxRate: 2},
+			installment:     installment,
+			wantAmount:      money.New(20000),
+			wantOverdueDays: 10,
+		},
+		{
+			name:   "not overdue yet",
+			policy: config.PenaltyConfig{Type: usecase.PenaltyTypePercentage, Rate: 0.1},
+			installment: repository.LoanInstallment{
+				DueDate:         asOf,
+				PrincipalAmount: money.New(900000),
+				InterestAmount:  money.New(100000),
+			},
+			wantAmount:      money.Zero,
+			wantOverdueDays: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := usecase.NewPenaltyCalculator(tt.policy)
+			amount, overdueDays := calculator.Calculate(tt.installment, asOf)
+			assert.Equal(t, tt.wantAmount, amount)
+			assert.Equal(t, tt.wantOverdueDays, overdueDays)
+		})
+	}
+}
+
+func TestPenaltyCalculatorAccrue(t *testing.T) {
+	asOf := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	calculator := usecase.NewPenaltyCalculator(config.PenaltyConfig{Type: usecase.PenaltyTypeFixed, Amount: money.New(1000)})
+
+	schedule := []repository.LoanInstallment{
+		{LoanID: 1, InstallmentNumber: 1, DueDate: time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), PrincipalAmount: money.New(100), InstallmentStatus: "paid"},
+		{LoanID: 1, InstallmentNumber: 2, DueDate: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), PrincipalAmount: money.New(100), InstallmentStatus: "unpaid"},
+		{LoanID: 1, InstallmentNumber: 3, DueDate: time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC), PrincipalAmount: money.New(100), InstallmentStatus: "unpaid"},
+	}
+	stored := []repository.LoanPenalty{
+		{ID: 1, LoanID: 1, InstallmentNumber: 1, OverdueDays: 3, PenaltyAmount: money.New(3000), PaidPenaltyAmount: money.New(3000)},
+		{ID: 2, LoanID: 1, InstallmentNumber: 2, OverdueDays: 5, PenaltyAmount: money.New(5000)},
+	}
+
+	penalties := calculator.Accrue(schedule, stored, asOf)
+	assert.Len(t, penalties, 2)
+	assert.Equal(t, stored[0], penalties[0])
+	assert.Equal(t, int64(2), penalties[1].ID)
+	assert.Equal(t, int32(10), penalties[1].OverdueDays)
+	assert.Equal(t, money.New(10000), penalties[1].PenaltyAmount)
+	assert.Equal(t, asOf, penalties[1].AccruedUntil)
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/pdf"
+)
+
+type (
+	// ReceiptResponse is the receipt of a payment. The remaining amounts are what was left to
+	// pay on the loan once the payment was booked.
+	ReceiptResponse struct {
+		ReceiptNumber            string      `json:"receipt_number"`
+		TransactionID            int64       `json:"transaction_id"`
+		IssuedAt                 string      `json:"issued_at"`
+		ConsumerID               int64       `json:"consumer_id"`
+		ConsumerName             string      `json:"consumer_name"`
+		LoanID                   int64       `json:"loan_id"`
+		ContractNumber           string      `json:"contract_number"`
+		Description              string      `json:"description"`
+		Amount                   money.Money `json:"amount"`
+		PrincipalAmount          money.Money `json:"principal_amount"`
+		InterestAmount           money.Money `json:"interest_amount"`
+		PenaltyAmount            money.Money `json:"penalty_amount"`
+		SettlementFeeAmount      money.Money `json:"settlement_fee_amount"`
+		CreditAmount             money.Money `json:"credit_amount"`
+		RemainingPrincipalAmount money.Money `json:"remaining_principal_amount"`
+		RemainingInterestAmount  money.Money `json:"remaining_interest_amount"`
+		RemainingAmount          money.Money `json:"remaining_amount"`
+		ReversedAt               string      `json:"reversed_at,omitempty"`
+	}
+)
+
+var receiptTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Receipt {{.ReceiptNumber}}</title>
+</head>
+<body>
+<h1>Payment Receipt</h1>
+{{if .ReversedAt}}<p><strong>This payment was reversed on {{.ReversedAt}}.</strong></p>
+{{end}}<table>
+<tr><th>Receipt number</th><td>{{.ReceiptNumber}}</td></tr>
+<tr><th>Issued at</th><td>{{.IssuedAt}}</td></tr>
+<tr><th>Consumer</th><td>{{.ConsumerName}}</td></tr>
+<tr><th>Contract number</th><td>{{.ContractNumber}}</td></tr>
+<tr><th>Description</th><td>{{.Description}}</td></tr>
+</table>
+<h2>Payment</h2>
+<table>
+<tr><th>Principal</th><td>{{.PrincipalAmount}}</td></tr>
+<tr><th>Interest</th><td>{{.InterestAmount}}</td></tr>
+<tr><th>Penalty</th><td>{{.PenaltyAmount}}</td></tr>
+<tr><th>Settlement fee</th><td>{{.SettlementFeeAmount}}</td></tr>
+<tr><th>Credit balance</th><td>{{.CreditAmount}}</td></tr>
+<tr><th>Total paid</th><td>{{.Amount}}</td></tr>
+</table>
+<h2>Remaining balance</h2>
+<table>
+<tr><th>Principal</th><td>{{.RemainingPrincipalAmount}}</td></tr>
+<tr><th>Interest</th><td>{{.RemainingInterestAmount}}</td></tr>
+<tr><th>Total</th><td>{{.RemainingAmount}}</td></tr>
+</table>
+</body>
+</html>
+`))
+
+// issueReceipt numbers the receipt of a payment within tx and stores it. Numbers restart
+// every month, RCP-200601-000001 being the first receipt of January 2006.
+func (uc *transactionUsecase) issueReceipt(ctx context.Context, receipt repository.Receipt, tx *sql.Tx) (string, error) {
+	period := receipt.IssuedAt.Format("200601")
+	number, err := uc.receiptRepo.NextReceiptSequence(ctx, period, tx)
+	if err != nil {
+		return "", err
+	}
+
+	receipt.ReceiptNumber = fmt.Sprintf("RCP-%s-%06d", period, number)
+	_, err = uc.receiptRepo.CreateReceipt(ctx, receipt, tx)
+	if err != nil {
+		return "", err
+	}
+
+	return receipt.ReceiptNumber, nil
+}
+
+// GetTransactionReceipt returns the receipt of a payment, or an empty response when the
+// transaction does not exist or has no receipt, as reversals and payments booked before
+// receipts were issued.
+func (uc *transactionUsecase) GetTransactionReceipt(ctx context.Context, id int64) (response ReceiptResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	transaction, err := uc.transactionRepo.GetTransactionByID(ctx, id)
+	if err != nil {
+		return response, err
+	}
+	if transaction.ID == 0 {
+		return response, nil
+	}
+
+	receipt, err := uc.receiptRepo.GetReceiptByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		return response, err
+	}
+	if receipt.ID == 0 {
+		return response, nil
+	}
+
+	allocations, err := uc.transactionRepo.GetTransactionAllocationsByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		return response, err
+	}
+
+	loan, err := uc.loanRepo.GetLoanByID(ctx, transaction.LoanID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[TransactionUsecase][GetTransactionReceipt] while get loan by ID, Err: %+v", err))
+		return response, err
+	}
+
+	consumer, err := uc.consumerRepo.GetConsumerByID(ctx, transaction.ConsumerID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[TransactionUsecase][GetTransactionReceipt] while get consumer by ID, Err: %+v", err))
+		return response, err
+	}
+
+	response = ReceiptResponse{
+		ReceiptNumber:            receipt.ReceiptNumber,
+		TransactionID:            transaction.ID,
+		IssuedAt:                 formatOptionalTime(receipt.IssuedAt),
+		ConsumerID:               transaction.ConsumerID,
+		ConsumerName:             consumer.FullName,
+		LoanID:                   transaction.LoanID,
+		ContractNumber:           loan.ContractNumber,
+		Description:              transaction.Description,
+		Amount:                   transaction.Amount,
+		RemainingPrincipalAmount: receipt.RemainingPrincipalAmount,
+		RemainingInterestAmount:  receipt.RemainingInterestAmount,
+		RemainingAmount:          receipt.RemainingPrincipalAmount.Add(receipt.RemainingInterestAmount),
+		ReversedAt:               formatOptionalTime(transaction.ReversedAt),
+	}
+
+	for _, allocation := range allocations {
+		switch allocation.Component {
+		case AllocationComponentPrincipal:
+			response.PrincipalAmount = response.PrincipalAmount.Add(allocation.Amount)
+		case AllocationComponentInterest:
+			response.InterestAmount = response.InterestAmount.Add(allocation.Amount)
+		case AllocationComponentPenalty:
+			response.PenaltyAmount = response.PenaltyAmount.Add(allocation.Amount)
+		case AllocationComponentSettlementFee:
+			response.SettlementFeeAmount = response.SettlementFeeAmount.Add(allocation.Amount)
+		case AllocationComponentCredit:
+			response.CreditAmount = response.CreditAmount.Add(allocation.Amount)
+		}
+	}
+
+	return response, nil
+}
+
+// WriteHTML writes the receipt as an HTML page.
+func (r ReceiptResponse) WriteHTML(w io.Writer) error {
+	return receiptTemplate.Execute(w, r)
+}
+
+// WritePDF writes the receipt as a PDF document.
+func (r ReceiptResponse) WritePDF(w io.Writer) error {
+	var doc pdf.Document
+
+	field := func(label string, value string) {
+		doc.Line(fmt.Sprintf("%-18s : %s", label, value))
+	}
+	amount := func(label string, value money.Money) {
+		doc.Line(fmt.Sprintf("  %-16s %20s", label, value))
+	}
+
+	doc.Line("PAYMENT RECEIPT")
+	doc.Line("")
+	if r.ReversedAt != "" {
+		doc.Line(fmt.Sprintf("*** This payment was reversed on %s ***", r.ReversedAt))
+		doc.Line("")
+	}
+	field("Receipt number", r.ReceiptNumber)
+	field("Issued at", r.IssuedAt)
+	field("Consumer", r.ConsumerName)
+	field("Contract number", r.ContractNumber)
+	field("Description", r.Description)
+	doc.Line("")
+	doc.Line("Payment")
+	amount("Principal", r.PrincipalAmount)
+	amount("Interest", r.InterestAmount)
+	amount("Penalty", r.PenaltyAmount)
+	amount("Settlement fee", r.SettlementFeeAmount)
+	amount("Credit balance", r.CreditAmount)
+	doc.Line("  " + strings.Repeat("-", 37))
+	amount("Total paid", r.Amount)
+	doc.Line("")
+	doc.Line("Remaining balance")
+	amount("Principal", r.RemainingPrincipalAmount)
+	amount("Interest", r.RemainingInterestAmount)
+	doc.Line("  " + strings.Repeat("-", 37))
+	amount("Total", r.RemainingAmount)
+
+	_, err := doc.WriteTo(w)
+	return err
+}
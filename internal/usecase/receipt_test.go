@@ -0,0 +1,153 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTransactionReceipt(t *testing.T) {
+	mockTransactionRepo := new(mocks.TransactionRepository)
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockReceiptRepo := new(mocks.ReceiptRepository)
+	uc := usecase.NewTransactionUsecase(mockTransactionRepo, mockLoanRepo, nil, nil, nil, nil, mockConsumerRepo, nil, mockReceiptRepo, config.SettlementConfig{}, config.PaymentConfig{}, time.Second*2)
+
+	issuedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		id      int64
+		setup   func()
+		want    usecase.ReceiptResponse
+		wantErr string
+	}{
+		{
+			name: "transaction not found",
+			id:   1,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(1)).Return(repository.Transaction{}, nil).Once()
+			},
+			want: usecase.ReceiptResponse{},
+		},
+		{
+			name: "transaction without receipt",
+			id:   2,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(2)).Return(repository.Transaction{ID: 2, LoanID: 1, ReversalOfTransactionID: 1}, nil).Once()
+				mockReceiptRepo.On("GetReceiptByTransactionID", mock.Anything, int64(2)).Return(repository.Receipt{}, nil).Once()
+			},
+			want: usecase.ReceiptResponse{},
+		},
+		{
+			name: "success",
+			id:   3,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(3)).Return(repository.Transaction{
+					ID:          3,
+					ConsumerID:  1,
+					LoanID:      1,
+					Amount:      money.New(140),
+					Description: "Payment for loan CN-1",
+					CreatedAt:   issuedAt,
+				}, nil).Once()
+				mockReceiptRepo.On("GetReceiptByTransactionID", mock.Anything, int64(3)).Return(repository.Receipt{
+					ID:                       1,
+					ReceiptNumber:            "RCP-202401-000007",
+					TransactionID:            3,
+					LoanID:                   1,
+					RemainingPrincipalAmount: money.New(900),
+					RemainingInterestAmount:  money.New(90),
+					IssuedAt:                 issuedAt,
+				}, nil).Once()
+				mockTransactionRepo.On("GetTransactionAllocationsByTransactionID", mock.Anything, int64(3)).Return([]repository.TransactionAllocation{
+					{TransactionID: 3, InstallmentNumber: 1, Component: "penalty", Amount: money.New(5)},
+					{TransactionID: 3, InstallmentNumber: 1, Component: "interest", Amount: money.New(10)},
+					{TransactionID: 3, InstallmentNumber: 1, Component: "principal", Amount: money.New(100)},
+					{TransactionID: 3, InstallmentNumber: 2, Component: "interest", Amount: money.New(5)},
+					{TransactionID: 3, Component: "credit", Amount: money.New(20)},
+				}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ContractNumber: "CN-1"}, nil).Once()
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1, FullName: "John Doe"}, nil).Once()
+			},
+			want: usecase.ReceiptResponse{
+				ReceiptNumber:            "RCP-202401-000007",
+				TransactionID:            3,
+				IssuedAt:                 "2024-01-15 10:30:00",
+				ConsumerID:               1,
+				ConsumerName:             "John Doe",
+				LoanID:                   1,
+				ContractNumber:           "CN-1",
+				Description:              "Payment for loan CN-1",
+				Amount:                   money.New(140),
+				PrincipalAmount:          money.New(100),
+				InterestAmount:           money.New(15),
+				PenaltyAmount:            money.New(5),
+				CreditAmount:             money.New(20),
+				RemainingPrincipalAmount: money.New(900),
+				RemainingInterestAmount:  money.New(90),
+				RemainingAmount:          money.New(990),
+			},
+		},
+		{
+			name: "repository error",
+			id:   4,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(4)).Return(repository.Transaction{ID: 4}, nil).Once()
+				mockReceiptRepo.On("GetReceiptByTransactionID", mock.Anything, int64(4)).Return(repository.Receipt{}, errors.New("db down")).Once()
+			},
+			wantErr: "db down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, err := uc.GetTransactionReceipt(context.Background(), tt.id)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	mockTransactionRepo.AssertExpectations(t)
+	mockReceiptRepo.AssertExpectations(t)
+}
+
+func TestReceiptWrite(t *testing.T) {
+	receipt := usecase.ReceiptResponse{
+		ReceiptNumber:   "RCP-202401-000007",
+		ConsumerName:    "John <Doe>",
+		ContractNumber:  "CN-1",
+		Amount:          money.New(110),
+		PrincipalAmount: money.New(100),
+		InterestAmount:  money.New(10),
+		ReversedAt:      "2024-01-16 09:00:00",
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, receipt.WriteHTML(&buf))
+	assert.Contains(t, buf.String(), "<title>Receipt RCP-202401-000007</title>")
+	assert.Contains(t, buf.String(), "<td>John &lt;Doe&gt;</td>")
+	assert.Contains(t, buf.String(), "<tr><th>Total paid</th><td>110</td></tr>")
+	assert.Contains(t, buf.String(), "This payment was reversed on 2024-01-16 09:00:00.")
+
+	buf.Reset()
+	assert.NoError(t, receipt.WritePDF(&buf))
+	assert.True(t, strings.HasPrefix(buf.String(), "%PDF-1.4"))
+	assert.Contains(t, buf.String(), "(Receipt number     : RCP-202401-000007) Tj")
+}
@@ -0,0 +1,609 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/gateway"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/statement"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/logger"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
+)
+
+const (
+	StatementMatchVirtualAccount = "virtual_account"
+	StatementMatchContractNumber = "contract_number"
+	StatementMatchAmount         = "amount"
+	StatementMatchManual         = "manual"
+)
+
+// statementAmountCandidateLimit caps the loans looked up for a line matched by amount, a
+// line with more than one candidate is ambiguous anyway.
+const statementAmountCandidateLimit = 5
+
+// ErrStatementLineNotInReview is returned when matching a line that is not waiting for a
+// manual match, because it is matched, ignored or being matched by another request.
+var ErrStatementLineNotInReview = errors.New("statement line is not waiting for a match")
+
+var (
+	// statementLineInReview lists the statuses of the lines in the review queue.
+	statementLineInReview = map[string]bool{
+		repository.StatementLineStatusUnmatched: true,
+		repository.StatementLineStatusAmbiguous: true,
+	}
+)
+
+type ReconciliationUsecase interface {
+	ImportStatement(ctx context.Context, req ImportStatementRequest) (response ImportStatementResponse, err error)
+	FetchStatementLines(ctx context.Context, req FetchStatementLinesRequest) (response []StatementLineResponse, err error)
+	MatchStatementLine(ctx context.Context, req MatchStatementLineRequest) (response StatementLineResponse, err error)
+}
+
+type reconciliationUsecase struct {
+	statementRepo       repository.StatementRepository
+	virtualAccountRepo  repository.VirtualAccountRepository
+	loanRepo            repository.LoanRepository
+	loanInstallmentRepo repository.LoanInstallmentRepository
+	transactionUC       TransactionUsecase
+	paymentGateway      gateway.PaymentGateway
+	ctxTimeout          time.Duration
+}
+
+type (
+	ImportStatementRequest struct {
+		FileName string
+		Format   string `json:"format" form:"format"`
+		Content  []byte
+	}
+
+	// ImportStatementResponse sums up an import. Lines lists the lines imported, lines that
+	// were imported before are only counted as duplicates.
+	ImportStatementResponse struct {
+		ID             int64                   `json:"id"`
+		FileName       string                  `json:"file_name"`
+		Format         string                  `json:"format"`
+		TotalLines     int                     `json:"total_lines"`
+		DuplicateLines int                     `json:"duplicate_lines"`
+		MatchedLines   int                     `json:"matched_lines"`
+		UnmatchedLines int                     `json:"unmatched_lines"`
+		AmbiguousLines int                     `json:"ambiguous_lines"`
+		IgnoredLines   int                     `json:"ignored_lines"`
+		Lines          []StatementLineResponse `json:"lines"`
+	}
+
+	// FetchStatementLinesRequest filters statement lines. The review queue, the unmatched
+	// and ambiguous lines, is returned when Status is empty.
+	FetchStatementLinesRequest struct {
+		ImportID int64  `json:"import_id" query:"import_id"`
+		Status   string `json:"status" query:"status"`
+		Page     int    `json:"page" query:"page"`
+		Limit    int    `json:"limit" query:"limit"`
+	}
+
+	// MatchStatementLineRequest matches a line either with a loan, booking the line as a
+	// payment of it, or with a transaction that already booked the payment.
+	MatchStatementLineRequest struct {
+		ID            int64  `json:"-"`
+		LoanID        int64  `json:"loan_id"`
+		TransactionID int64  `json:"transaction_id"`
+		MatchedBy     string `json:"matched_by"`
+	}
+
+	StatementLineResponse struct {
+		ID                int64       `json:"id"`
+		StatementImportID int64       `json:"statement_import_id"`
+		LineNumber        int32       `json:"line_number"`
+		ValueDate         string      `json:"value_date"`
+		Amount            money.Money `json:"amount"`
+		Description       string      `json:"description"`
+		Reference         string      `json:"reference"`
+		Status            string      `json:"status"`
+		MatchMethod       string      `json:"match_method,omitempty"`
+		LoanID            int64       `json:"loan_id,omitempty"`
+		TransactionID     int64       `json:"transaction_id,omitempty"`
+		CandidateLoanIDs  []int64     `json:"candidate_loan_ids,omitempty"`
+		Note              string      `json:"note,omitempty"`
+		MatchedBy         string      `json:"matched_by,omitempty"`
+		MatchedAt         string      `json:"matched_at,omitempty"`
+		CreatedAt         string      `json:"created_at,omitempty"`
+	}
+)
+
+func NewReconciliationUsecase(
+	statementRepo repository.StatementRepository,
+	virtualAccountRepo repository.VirtualAccountRepository,
+	loanRepo repository.LoanRepository,
+	loanInstallmentRepo repository.LoanInstallmentRepository,
+	transactionUC TransactionUsecase,
+	paymentGateway gateway.PaymentGateway,
+	timeout time.Duration,
+) ReconciliationUsecase {
+	return &reconciliationUsecase{
+		statementRepo:       statementRepo,
+		virtualAccountRepo:  virtualAccountRepo,
+		loanRepo:            loanRepo,
+		loanInstallmentRepo: loanInstallmentRepo,
+		transactionUC:       transactionUC,
+		paymentGateway:      paymentGateway,
+		ctxTimeout:          timeout,
+	}
+}
+
+// ImportStatement stores the lines of a bank statement and books the payments received
+// that match a single loan, by virtual account, contract number or installment amount.
+// The other lines received are left unmatched or ambiguous for a manual match, and the
+// lines paid out are ignored. A line imported before is skipped, so an import that failed
+// half way is resumed by importing the same statement again.
+func (uc *reconciliationUsecase) ImportStatement(ctx context.Context, req ImportStatementRequest) (response ImportStatementResponse, err error) {
+	lines, err := statement.Parse(req.Format, bytes.NewReader(req.Content))
+	if err != nil {
+		return response, err
+	}
+
+	importCtx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	response.ID, err = uc.statementRepo.CreateStatementImport(importCtx, repository.StatementImport{
+		FileName:        req.FileName,
+		StatementFormat: req.Format,
+	})
+	if err != nil {
+		return response, err
+	}
+	response.FileName = req.FileName
+	response.Format = req.Format
+	response.TotalLines = len(lines)
+	response.Lines = make([]StatementLineResponse, 0, len(lines))
+
+	occurrences := make(map[string]int, len(lines))
+	for _, line := range lines {
+		// a statement may list the same transfer twice, the occurrence tells them apart
+		key := statementLineKey(line)
+		occurrences[key]++
+
+		// every line gets its own timeout, a long statement takes longer than a request
+		imported, created, err := uc.importStatementLine(ctx, response.ID, line, statementLineFingerprint(key, occurrences[key]))
+		if err != nil {
+			return response, err
+		}
+		if !created {
+			response.DuplicateLines++
+			continue
+		}
+
+		switch imported.Status {
+		case repository.StatementLineStatusMatched:
+			response.MatchedLines++
+		case repository.StatementLineStatusAmbiguous:
+			response.AmbiguousLines++
+		case repository.StatementLineStatusIgnored:
+			response.IgnoredLines++
+		default:
+			response.UnmatchedLines++
+		}
+
+		response.Lines = append(response.Lines, imported)
+	}
+
+	return response, nil
+}
+
+func (uc *reconciliationUsecase) importStatementLine(ctx context.Context, importID int64, line statement.Line, fingerprint string) (response StatementLineResponse, created bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	stored := repository.StatementLine{
+		StatementImportID: importID,
+		LineNumber:        int32(line.Number),
+		ValueDate:         line.ValueDate,
+		Amount:            line.Amount,
+		Description:       line.Description,
+		Reference:         truncateString(line.Reference, 255),
+		Fingerprint:       fingerprint,
+		LineStatus:        repository.StatementLineStatusPending,
+	}
+	if !line.Amount.IsPositive() {
+		stored.LineStatus = repository.StatementLineStatusIgnored
+		stored.Note = "not a payment received"
+	}
+
+	stored.ID, err = uc.statementRepo.CreateStatementLine(ctx, stored)
+	if err != nil {
+		return response, false, err
+	}
+	if stored.ID == 0 {
+		return response, false, nil
+	}
+	if stored.LineStatus == repository.StatementLineStatusIgnored {
+		return toStatementLineResponse(stored), true, nil
+	}
+
+	update := uc.matchStatementLine(ctx, stored)
+	err = uc.statementRepo.UpdateStatementLine(ctx, update)
+	if err != nil {
+		// the line stays pending and is not matched again, the payment may be booked
+		return response, true, err
+	}
+
+	stored.LineStatus = update.LineStatus
+	stored.MatchMethod = update.MatchMethod
+	stored.LoanID = update.LoanID
+	stored.TransactionID = update.TransactionID
+	stored.CandidateLoanIDs = update.CandidateLoanIDs
+	stored.Note = update.Note
+
+	return toStatementLineResponse(stored), true, nil
+}
+
+// matchStatementLine looks for the loan a line received pays, first by a virtual account
+// number, then by a contract number found in its description or reference, then by the
+// amount due on the next installment, and books the line as a payment of the loan when
+// there is exactly one.
+func (uc *reconciliationUsecase) matchStatementLine(ctx context.Context, line repository.StatementLine) repository.UpdateStatementLineRequest {
+	tokens := statementLineTokens(line.Description + " " + line.Reference)
+
+	provider := uc.paymentGateway.Provider()
+	for _, token := range tokens {
+		if !isDigits(token) {
+			continue
+		}
+
+		account, err := uc.virtualAccountRepo.GetVirtualAccountByNumber(ctx, provider, token)
+		if err != nil {
+			return unmatchedStatementLine(line.ID, err.Error())
+		}
+		if account.ID == 0 {
+			continue
+		}
+
+		// the transfer was most likely booked already by the callback of the gateway, which
+		// may have been sent the day before or after the value date
+		transactionID, err := uc.statementRepo.GetUnreconciledCallbackTransactionID(ctx, account.ID, line.Amount, line.ValueDate.AddDate(0, 0, -1), line.ValueDate.AddDate(0, 0, 2))
+		if err != nil {
+			return unmatchedStatementLine(line.ID, err.Error())
+		}
+		if transactionID != 0 {
+			return repository.UpdateStatementLineRequest{
+				ID:            line.ID,
+				LineStatus:    repository.StatementLineStatusMatched,
+				MatchMethod:   StatementMatchVirtualAccount,
+				LoanID:        account.LoanID,
+				TransactionID: transactionID,
+				Note:          "booked by payment callback",
+			}
+		}
+
+		return uc.bookStatementLine(ctx, line, account.LoanID, StatementMatchVirtualAccount)
+	}
+
+	loanIDs := []int64{}
+	for _, token := range tokens {
+		// contract numbers are made of the consumer ID, a code and the merchant ID joined
+		// with dashes
+		if !strings.Contains(token, "-") {
+			continue
+		}
+
+		loanID, err := uc.loanRepo.GetLoanIDByContractNumber(ctx, token)
+		if err != nil {
+			return unmatchedStatementLine(line.ID, err.Error())
+		}
+		if loanID != 0 && !containsInt64(loanIDs, loanID) {
+			loanIDs = append(loanIDs, loanID)
+		}
+	}
+	if len(loanIDs) == 1 {
+		return uc.bookStatementLine(ctx, line, loanIDs[0], StatementMatchContractNumber)
+	}
+	if len(loanIDs) > 1 {
+		return ambiguousStatementLine(line.ID, loanIDs, "more than one contract number")
+	}
+
+	payableLoanStatuses := []string{LoanStatusDisbursed, LoanStatusOnGoing, LoanStatusLate}
+	loanIDs, err := uc.loanInstallmentRepo.GetLoanIDsByNextDueAmount(ctx, line.Amount, payableLoanStatuses, statementAmountCandidateLimit)
+	if err != nil {
+		return unmatchedStatementLine(line.ID, err.Error())
+	}
+	if len(loanIDs) == 1 {
+		return uc.bookStatementLine(ctx, line, loanIDs[0], StatementMatchAmount)
+	}
+	if len(loanIDs) > 1 {
+		return ambiguousStatementLine(line.ID, loanIDs, "more than one loan has the amount due")
+	}
+
+	return unmatchedStatementLine(line.ID, "no loan matches the line")
+}
+
+// bookStatementLine books a line as a partial payment of a loan. The line is left unmatched
+// when the payment cannot be booked.
+func (uc *reconciliationUsecase) bookStatementLine(ctx context.Context, line repository.StatementLine, loanID int64, matchMethod string) repository.UpdateStatementLineRequest {
+	transaction, err := uc.bookStatementPayment(ctx, line, loanID)
+	if err != nil {
+		update := unmatchedStatementLine(line.ID, err.Error())
+		update.LoanID = loanID
+		update.MatchMethod = matchMethod
+		return update
+	}
+
+	return repository.UpdateStatementLineRequest{
+		ID:            line.ID,
+		LineStatus:    repository.StatementLineStatusMatched,
+		MatchMethod:   matchMethod,
+		LoanID:        loanID,
+		TransactionID: transaction.ID,
+	}
+}
+
+func (uc *reconciliationUsecase) bookStatementPayment(ctx context.Context, line repository.StatementLine, loanID int64) (transaction GetTransactionResponse, err error) {
+	loan, err := uc.loanRepo.GetLoanByID(ctx, loanID)
+	if err != nil {
+		return transaction, err
+	}
+	if loan.ID == 0 {
+		return transaction, errors.New("loan not found")
+	}
+	if !loanStatusPayable[loan.LoanStatus] {
+		return transaction, fmt.Errorf("loan %s is %s", loan.ContractNumber, loan.LoanStatus)
+	}
+
+	return uc.transactionUC.CreateTransaction(ctx, TransactionRequest{
+		ConsumerID:        loan.ConsumerID,
+		LoanID:            loan.ID,
+		TramsactionType:   "partial",
+		Amount:            line.Amount,
+		Channel:           TransactionChannelBankTransfer,
+		ExternalReference: line.Reference,
+		ValueDate:         line.ValueDate.Format("2006-01-02"),
+	})
+}
+
+func (uc *reconciliationUsecase) FetchStatementLines(ctx context.Context, req FetchStatementLinesRequest) (response []StatementLineResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	limit, offset := utils.ParsePagination(req.Page, req.Limit)
+	filter := repository.FetchStatementLinesRequest{
+		StatementImportID: req.ImportID,
+		LineStatuses:      []string{repository.StatementLineStatusUnmatched, repository.StatementLineStatusAmbiguous},
+		Limit:             limit,
+		Offset:            offset,
+	}
+	if req.Status != "" {
+		filter.LineStatuses = []string{req.Status}
+	}
+
+	lines, err := uc.statementRepo.FetchStatementLines(ctx, filter)
+	if err != nil {
+		return response, err
+	}
+
+	response = make([]StatementLineResponse, 0, len(lines))
+	for _, line := range lines {
+		response = append(response, toStatementLineResponse(line))
+	}
+
+	return response, nil
+}
+
+// MatchStatementLine reconciles a line of the review queue by hand, either booking it as a
+// payment of a loan or linking it to the transaction that booked it already.
+func (uc *reconciliationUsecase) MatchStatementLine(ctx context.Context, req MatchStatementLineRequest) (response StatementLineResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	line, err := uc.statementRepo.GetStatementLineByID(ctx, req.ID)
+	if err != nil {
+		return response, err
+	}
+	if line.ID == 0 {
+		return response, errors.New("statement line not found")
+	}
+	if !statementLineInReview[line.LineStatus] {
+		return response, ErrStatementLineNotInReview
+	}
+
+	update := repository.UpdateStatementLineRequest{
+		ID:          line.ID,
+		LineStatus:  repository.StatementLineStatusMatched,
+		MatchMethod: StatementMatchManual,
+		LoanID:      req.LoanID,
+		MatchedBy:   req.MatchedBy,
+	}
+
+	if req.TransactionID != 0 {
+		transaction, err := uc.transactionUC.GetTransactionByID(ctx, req.TransactionID)
+		if err != nil {
+			return response, err
+		}
+		if transaction.Amount != line.Amount {
+			return response, errors.New("transaction amount does not match the statement line")
+		}
+		if transaction.ReversedAt != "" {
+			return response, errors.New("transaction is reversed")
+		}
+
+		reconciled, err := uc.statementRepo.GetStatementLineByTransactionID(ctx, transaction.ID)
+		if err != nil {
+			return response, err
+		}
+		if reconciled.ID != 0 {
+			return response, fmt.Errorf("transaction is already matched with statement line %d", reconciled.ID)
+		}
+
+		update.LoanID = transaction.LoanID
+		update.TransactionID = transaction.ID
+	}
+
+	claimed, err := uc.statementRepo.ClaimStatementLine(ctx, line.ID)
+	if err != nil {
+		return response, err
+	}
+	if !claimed {
+		return response, ErrStatementLineNotInReview
+	}
+
+	if update.TransactionID == 0 {
+		transaction, err := uc.bookStatementPayment(ctx, line, req.LoanID)
+		if err != nil {
+			// back to the review queue as it was
+			updateErr := uc.statementRepo.UpdateStatementLine(ctx, repository.UpdateStatementLineRequest{
+				ID:               line.ID,
+				LineStatus:       line.LineStatus,
+				MatchMethod:      line.MatchMethod,
+				LoanID:           line.LoanID,
+				CandidateLoanIDs: line.CandidateLoanIDs,
+				Note:             truncateString(err.Error(), 255),
+			})
+			if updateErr != nil {
+				logger.Error(fmt.Sprintf("[reconciliationUsecase][MatchStatementLine] while release statement line %d. Err: %v", line.ID, updateErr))
+			}
+
+			return response, err
+		}
+
+		update.TransactionID = transaction.ID
+	}
+
+	// the payment is booked at this point, a line left pending is never booked twice
+	err = uc.statementRepo.UpdateStatementLine(ctx, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[reconciliationUsecase][MatchStatementLine] while mark statement line %d matched. Err: %v", line.ID, err))
+	}
+
+	line.LineStatus = update.LineStatus
+	line.MatchMethod = update.MatchMethod
+	line.LoanID = update.LoanID
+	line.TransactionID = update.TransactionID
+	line.CandidateLoanIDs = ""
+	line.Note = ""
+	line.MatchedBy = update.MatchedBy
+
+	return toStatementLineResponse(line), nil
+}
+
+func unmatchedStatementLine(id int64, note string) repository.UpdateStatementLineRequest {
+	return repository.UpdateStatementLineRequest{
+		ID:         id,
+		LineStatus: repository.StatementLineStatusUnmatched,
+		Note:       truncateString(note, 255),
+	}
+}
+
+func ambiguousStatementLine(id int64, loanIDs []int64, note string) repository.UpdateStatementLineRequest {
+	ids := make([]string, 0, len(loanIDs))
+	for _, loanID := range loanIDs {
+		ids = append(ids, strconv.FormatInt(loanID, 10))
+	}
+
+	return repository.UpdateStatementLineRequest{
+		ID:               id,
+		LineStatus:       repository.StatementLineStatusAmbiguous,
+		CandidateLoanIDs: strings.Join(ids, ","),
+		Note:             note,
+	}
+}
+
+func toStatementLineResponse(line repository.StatementLine) StatementLineResponse {
+	response := StatementLineResponse{
+		ID:                line.ID,
+		StatementImportID: line.StatementImportID,
+		LineNumber:        line.LineNumber,
+		ValueDate:         line.ValueDate.Format("2006-01-02"),
+		Amount:            line.Amount,
+		Description:       line.Description,
+		Reference:         line.Reference,
+		Status:            line.LineStatus,
+		MatchMethod:       line.MatchMethod,
+		LoanID:            line.LoanID,
+		TransactionID:     line.TransactionID,
+		Note:              line.Note,
+		MatchedBy:         line.MatchedBy,
+		MatchedAt:         formatOptionalTime(line.MatchedAt),
+		CreatedAt:         formatOptionalTime(line.CreatedAt),
+	}
+
+	if line.CandidateLoanIDs != "" {
+		for _, value := range strings.Split(line.CandidateLoanIDs, ",") {
+			loanID, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				response.CandidateLoanIDs = append(response.CandidateLoanIDs, loanID)
+			}
+		}
+	}
+
+	return response
+}
+
+// statementLineKey identifies the transfer of a line, the same transfer listed twice has
+// the same key.
+func statementLineKey(line statement.Line) string {
+	return strings.Join([]string{
+		line.ValueDate.Format("2006-01-02"),
+		line.Amount.String(),
+		line.Reference,
+		line.Description,
+	}, "|")
+}
+
+func statementLineFingerprint(key string, occurrence int) string {
+	sum := sha256.Sum256([]byte(key + "|" + strconv.Itoa(occurrence)))
+	return hex.EncodeToString(sum[:])
+}
+
+// statementLineTokens splits the text of a line into the words that may be an account or
+// contract number, in the order they appear and without repeats.
+func statementLineTokens(text string) []string {
+	tokens := []string{}
+	seen := map[string]bool{}
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	}) {
+		token := strings.Trim(field, "-")
+		if token == "" || seen[token] {
+			continue
+		}
+
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return value != ""
+}
+
+func containsInt64(values []int64, value int64) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func truncateString(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+
+	return value
+}
@@ -0,0 +1,291 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/gateway"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImportStatement(t *testing.T) {
+	mockStatementRepo := new(mocks.StatementRepository)
+	mockVirtualAccountRepo := new(mocks.VirtualAccountRepository)
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockTransactionUC := new(mocks.TransactionUsecase)
+	fake := gateway.NewFakeGateway(config.PaymentGatewayConfig{BankCode: "8808"})
+	uc := usecase.NewReconciliationUsecase(mockStatementRepo, mockVirtualAccountRepo, mockLoanRepo, mockLoanInstallmentRepo, mockTransactionUC, fake, time.Second*2)
+
+	_, err := uc.ImportStatement(context.Background(), usecase.ImportStatementRequest{FileName: "mutation.csv", Format: "csv", Content: []byte("date,amount\n")})
+	assert.EqualError(t, err, "invalid bank statement: missing description column")
+
+	content := "date,description,reference,amount\n" +
+		"2024-01-15,TRF VA 8808000000000042,,150000\n" +
+		"2024-01-15,TRF 8808000000000043,,100000\n" +
+		"2024-01-15,PAYMENT 1-ABCDEFGHIJ-2,,200000\n" +
+		"2024-01-16,CICILAN BUDI,,300000\n" +
+		"2024-01-16,CICILAN ANI,,250000\n" +
+		"2024-01-16,TRANSFER OUT,,-50000\n" +
+		"2024-01-16,TRANSFER OUT,,-50000\n" +
+		"2024-01-16,OLD TRF,,1000\n"
+	valueDate := time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)
+	payable := []string{"disbursed", "on_going", "late"}
+
+	mockStatementRepo.On("CreateStatementImport", mock.Anything, repository.StatementImport{FileName: "mutation.csv", StatementFormat: "csv"}).Return(int64(4), nil).Once()
+
+	fingerprints := map[int32]string{}
+	for number, status := range map[int32]string{1: "pending", 2: "pending", 3: "pending", 4: "pending", 5: "pending", 6: "ignored", 7: "ignored", 8: "pending"} {
+		number, status := number, status
+		id := int64(10) + int64(number)
+		if number == 8 {
+			// imported before
+			id = 0
+		}
+
+		mockStatementRepo.On("CreateStatementLine", mock.Anything, mock.MatchedBy(func(line repository.StatementLine) bool {
+			return line.StatementImportID == 4 && line.LineNumber == number && line.LineStatus == status && len(line.Fingerprint) == 64
+		})).Run(func(args mock.Arguments) {
+			fingerprints[number] = args.Get(1).(repository.StatementLine).Fingerprint
+		}).Return(id, nil).Once()
+	}
+
+	// line 1 was booked by the callback of the gateway
+	mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000042").Return(repository.VirtualAccount{ID: 3, LoanID: 42}, nil).Once()
+	mockStatementRepo.On("GetUnreconciledCallbackTransactionID", mock.Anything, int64(3), money.New(150000), valueDate.AddDate(0, 0, -1), valueDate.AddDate(0, 0, 2)).Return(int64(7), nil).Once()
+	mockStatementRepo.On("UpdateStatementLine", mock.Anything, repository.UpdateStatementLineRequest{
+		ID: 11, LineStatus: "matched", MatchMethod: "virtual_account", LoanID: 42, TransactionID: 7, Note: "booked by payment callback",
+	}).Return(nil).Once()
+
+	// line 2 is booked on the loan of the virtual account
+	mockVirtualAccountRepo.On("GetVirtualAccountByNumber", mock.Anything, "fake", "8808000000000043").Return(repository.VirtualAccount{ID: 4, LoanID: 43}, nil).Once()
+	mockStatementRepo.On("GetUnreconciledCallbackTransactionID", mock.Anything, int64(4), money.New(100000), valueDate.AddDate(0, 0, -1), valueDate.AddDate(0, 0, 2)).Return(int64(0), nil).Once()
+	mockLoanRepo.On("GetLoanByID", mock.Anything, int64(43)).Return(repository.Loan{ID: 43, ConsumerID: 2, LoanStatus: "on_going"}, nil).Once()
+	mockTransactionUC.On("CreateTransaction", mock.Anything, usecase.TransactionRequest{ConsumerID: 2, LoanID: 43, TramsactionType: "partial", Amount: money.New(100000), Channel: "bank_transfer", ValueDate: "2024-01-15"}).Return(usecase.GetTransactionResponse{ID: 8}, nil).Once()
+	mockStatementRepo.On("UpdateStatementLine", mock.Anything, repository.UpdateStatementLineRequest{
+		ID: 12, LineStatus: "matched", MatchMethod: "virtual_account", LoanID: 43, TransactionID: 8,
+	}).Return(nil).Once()
+
+	// line 3 names a contract but its payment fails to book
+	mockLoanRepo.On("GetLoanIDByContractNumber", mock.Anything, "1-ABCDEFGHIJ-2").Return(int64(5), nil).Once()
+	mockLoanRepo.On("GetLoanByID", mock.Anything, int64(5)).Return(repository.Loan{ID: 5, ConsumerID: 1, LoanStatus: "late"}, nil).Once()
+	mockTransactionUC.On("CreateTransaction", mock.Anything, usecase.TransactionRequest{ConsumerID: 1, LoanID: 5, TramsactionType: "partial", Amount: money.New(200000), Channel: "bank_transfer", ValueDate: "2024-01-15"}).Return(usecase.GetTransactionResponse{}, errors.New("db down")).Once()
+	mockStatementRepo.On("UpdateStatementLine", mock.Anything, repository.UpdateStatementLineRequest{
+		ID: 13, LineStatus: "unmatched", MatchMethod: "contract_number", LoanID: 5, Note: "db down",
+	}).Return(nil).Once()
+
+	// line 4 is the amount due of two loans
+	mockLoanInstallmentRepo.On("GetLoanIDsByNextDueAmount", mock.Anything, money.New(300000), payable, 5).Return([]int64{1, 4}, nil).Once()
+	mockStatementRepo.On("UpdateStatementLine", mock.Anything, repository.UpdateStatementLineRequest{
+		ID: 14, LineStatus: "ambiguous", CandidateLoanIDs: "1,4", Note: "more than one loan has the amount due",
+	}).Return(nil).Once()
+
+	// line 5 is the amount due of a loan that cannot be paid anymore
+	mockLoanInstallmentRepo.On("GetLoanIDsByNextDueAmount", mock.Anything, money.New(250000), payable, 5).Return([]int64{6}, nil).Once()
+	mockLoanRepo.On("GetLoanByID", mock.Anything, int64(6)).Return(repository.Loan{ID: 6, ContractNumber: "C-6", LoanStatus: "finish"}, nil).Once()
+	mockStatementRepo.On("UpdateStatementLine", mock.Anything, repository.UpdateStatementLineRequest{
+		ID: 15, LineStatus: "unmatched", MatchMethod: "amount", LoanID: 6, Note: "loan C-6 is finish",
+	}).Return(nil).Once()
+
+	got, err := uc.ImportStatement(context.Background(), usecase.ImportStatementRequest{FileName: "mutation.csv", Format: "csv", Content: []byte(content)})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), got.ID)
+	assert.Equal(t, 8, got.TotalLines)
+	assert.Equal(t, 1, got.DuplicateLines)
+	assert.Equal(t, 2, got.MatchedLines)
+	assert.Equal(t, 2, got.UnmatchedLines)
+	assert.Equal(t, 1, got.AmbiguousLines)
+	assert.Equal(t, 2, got.IgnoredLines)
+	assert.Len(t, got.Lines, 7)
+	assert.Equal(t, usecase.StatementLineResponse{
+		ID:                14,
+		StatementImportID: 4,
+		LineNumber:        4,
+		ValueDate:         "2024-01-16",
+		Amount:            money.New(300000),
+		Description:       "CICILAN BUDI",
+		Status:            "ambiguous",
+		CandidateLoanIDs:  []int64{1, 4},
+		Note:              "more than one loan has the amount due",
+	}, got.Lines[3])
+	assert.Equal(t, "not a payment received", got.Lines[5].Note)
+	// the same transfer listed twice is imported twice
+	assert.NotEqual(t, fingerprints[6], fingerprints[7])
+
+	mockStatementRepo.AssertExpectations(t)
+	mockVirtualAccountRepo.AssertExpectations(t)
+	mockLoanRepo.AssertExpectations(t)
+	mockLoanInstallmentRepo.AssertExpectations(t)
+	mockTransactionUC.AssertExpectations(t)
+}
+
+func TestFetchStatementLines(t *testing.T) {
+	mockStatementRepo := new(mocks.StatementRepository)
+	uc := usecase.NewReconciliationUsecase(mockStatementRepo, nil, nil, nil, nil, nil, time.Second*2)
+
+	valueDate := time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)
+
+	mockStatementRepo.On("FetchStatementLines", mock.Anything, repository.FetchStatementLinesRequest{
+		LineStatuses: []string{"unmatched", "ambiguous"},
+		Limit:        10,
+	}).Return([]repository.StatementLine{
+		{ID: 9, StatementImportID: 4, LineNumber: 1, ValueDate: valueDate, Amount: money.New(1000), LineStatus: "unmatched", Note: "no loan matches the line"},
+	}, nil).Once()
+	got, err := uc.FetchStatementLines(context.Background(), usecase.FetchStatementLinesRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []usecase.StatementLineResponse{
+		{ID: 9, StatementImportID: 4, LineNumber: 1, ValueDate: "2024-01-15", Amount: money.New(1000), Status: "unmatched", Note: "no loan matches the line"},
+	}, got)
+
+	mockStatementRepo.On("FetchStatementLines", mock.Anything, repository.FetchStatementLinesRequest{
+		StatementImportID: 4,
+		LineStatuses:      []string{"matched"},
+		Limit:             5,
+		Offset:            5,
+	}).Return(nil, errors.New("db down")).Once()
+	_, err = uc.FetchStatementLines(context.Background(), usecase.FetchStatementLinesRequest{ImportID: 4, Status: "matched", Page: 2, Limit: 5})
+	assert.EqualError(t, err, "db down")
+
+	mockStatementRepo.AssertExpectations(t)
+}
+
+func TestMatchStatementLine(t *testing.T) {
+	mockStatementRepo := new(mocks.StatementRepository)
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockTransactionUC := new(mocks.TransactionUsecase)
+	uc := usecase.NewReconciliationUsecase(mockStatementRepo, nil, mockLoanRepo, nil, mockTransactionUC, nil, time.Second*2)
+
+	valueDate := time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)
+	line := func(id int64, status string) repository.StatementLine {
+		return repository.StatementLine{ID: id, StatementImportID: 4, LineNumber: 1, ValueDate: valueDate, Amount: money.New(150000), LineStatus: status}
+	}
+
+	tests := []struct {
+		name    string
+		req     usecase.MatchStatementLineRequest
+		setup   func()
+		want    usecase.StatementLineResponse
+		wantErr string
+	}{
+		{
+			name: "line not found",
+			req:  usecase.MatchStatementLineRequest{ID: 1, LoanID: 1, MatchedBy: "finance"},
+			setup: func() {
+				mockStatementRepo.On("GetStatementLineByID", mock.Anything, int64(1)).Return(repository.StatementLine{}, nil).Once()
+			},
+			wantErr: "statement line not found",
+		},
+		{
+			name: "line already matched",
+			req:  usecase.MatchStatementLineRequest{ID: 2, LoanID: 1, MatchedBy: "finance"},
+			setup: func() {
+				mockStatementRepo.On("GetStatementLineByID", mock.Anything, int64(2)).Return(line(2, "matched"), nil).Once()
+			},
+			wantErr: usecase.ErrStatementLineNotInReview.Error(),
+		},
+		{
+			name: "transaction of another amount",
+			req:  usecase.MatchStatementLineRequest{ID: 3, TransactionID: 7, MatchedBy: "finance"},
+			setup: func() {
+				mockStatementRepo.On("GetStatementLineByID", mock.Anything, int64(3)).Return(line(3, "unmatched"), nil).Once()
+				mockTransactionUC.On("GetTransactionByID", mock.Anything, int64(7)).Return(usecase.GetTransactionResponse{ID: 7, LoanID: 1, Amount: money.New(100000)}, nil).Once()
+			},
+			wantErr: "transaction amount does not match the statement line",
+		},
+		{
+			name: "transaction matched with another line",
+			req:  usecase.MatchStatementLineRequest{ID: 4, TransactionID: 8, MatchedBy: "finance"},
+			setup: func() {
+				mockStatementRepo.On("GetStatementLineByID", mock.Anything, int64(4)).Return(line(4, "unmatched"), nil).Once()
+				mockTransactionUC.On("GetTransactionByID", mock.Anything, int64(8)).Return(usecase.GetTransactionResponse{ID: 8, LoanID: 1, Amount: money.New(150000)}, nil).Once()
+				mockStatementRepo.On("GetStatementLineByTransactionID", mock.Anything, int64(8)).Return(line(9, "matched"), nil).Once()
+			},
+			wantErr: "transaction is already matched with statement line 9",
+		},
+		{
+			name: "links the transaction that booked the payment",
+			req:  usecase.MatchStatementLineRequest{ID: 5, TransactionID: 10, MatchedBy: "finance"},
+			setup: func() {
+				mockStatementRepo.On("GetStatementLineByID", mock.Anything, int64(5)).Return(line(5, "unmatched"), nil).Once()
+				mockTransactionUC.On("GetTransactionByID", mock.Anything, int64(10)).Return(usecase.GetTransactionResponse{ID: 10, LoanID: 1, Amount: money.New(150000)}, nil).Once()
+				mockStatementRepo.On("GetStatementLineByTransactionID", mock.Anything, int64(10)).Return(repository.StatementLine{}, nil).Once()
+				mockStatementRepo.On("ClaimStatementLine", mock.Anything, int64(5)).Return(true, nil).Once()
+				mockStatementRepo.On("UpdateStatementLine", mock.Anything, repository.UpdateStatementLineRequest{
+					ID: 5, LineStatus: "matched", MatchMethod: "manual", LoanID: 1, TransactionID: 10, MatchedBy: "finance",
+				}).Return(nil).Once()
+			},
+			want: usecase.StatementLineResponse{
+				ID: 5, StatementImportID: 4, LineNumber: 1, ValueDate: "2024-01-15", Amount: money.New(150000),
+				Status: "matched", MatchMethod: "manual", LoanID: 1, TransactionID: 10, MatchedBy: "finance",
+			},
+		},
+		{
+			name: "claimed by another request",
+			req:  usecase.MatchStatementLineRequest{ID: 6, LoanID: 1, MatchedBy: "finance"},
+			setup: func() {
+				mockStatementRepo.On("GetStatementLineByID", mock.Anything, int64(6)).Return(line(6, "ambiguous"), nil).Once()
+				mockStatementRepo.On("ClaimStatementLine", mock.Anything, int64(6)).Return(false, nil).Once()
+			},
+			wantErr: usecase.ErrStatementLineNotInReview.Error(),
+		},
+		{
+			name: "books the line on a loan",
+			req:  usecase.MatchStatementLineRequest{ID: 7, LoanID: 1, MatchedBy: "finance"},
+			setup: func() {
+				ambiguous := line(7, "ambiguous")
+				ambiguous.CandidateLoanIDs = "1,4"
+				mockStatementRepo.On("GetStatementLineByID", mock.Anything, int64(7)).Return(ambiguous, nil).Once()
+				mockStatementRepo.On("ClaimStatementLine", mock.Anything, int64(7)).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerID: 2, LoanStatus: "on_going"}, nil).Once()
+				mockTransactionUC.On("CreateTransaction", mock.Anything, usecase.TransactionRequest{ConsumerID: 2, LoanID: 1, TramsactionType: "partial", Amount: money.New(150000), Channel: "bank_transfer", ValueDate: "2024-01-15"}).Return(usecase.GetTransactionResponse{ID: 11}, nil).Once()
+				mockStatementRepo.On("UpdateStatementLine", mock.Anything, repository.UpdateStatementLineRequest{
+					ID: 7, LineStatus: "matched", MatchMethod: "manual", LoanID: 1, TransactionID: 11, MatchedBy: "finance",
+				}).Return(nil).Once()
+			},
+			want: usecase.StatementLineResponse{
+				ID: 7, StatementImportID: 4, LineNumber: 1, ValueDate: "2024-01-15", Amount: money.New(150000),
+				Status: "matched", MatchMethod: "manual", LoanID: 1, TransactionID: 11, MatchedBy: "finance",
+			},
+		},
+		{
+			name: "payment fails to book",
+			req:  usecase.MatchStatementLineRequest{ID: 8, LoanID: 4, MatchedBy: "finance"},
+			setup: func() {
+				ambiguous := line(8, "ambiguous")
+				ambiguous.CandidateLoanIDs = "1,4"
+				mockStatementRepo.On("GetStatementLineByID", mock.Anything, int64(8)).Return(ambiguous, nil).Once()
+				mockStatementRepo.On("ClaimStatementLine", mock.Anything, int64(8)).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(4)).Return(repository.Loan{ID: 4, ContractNumber: "C-4", LoanStatus: "finish"}, nil).Once()
+				mockStatementRepo.On("UpdateStatementLine", mock.Anything, repository.UpdateStatementLineRequest{
+					ID: 8, LineStatus: "ambiguous", CandidateLoanIDs: "1,4", Note: "loan C-4 is finish",
+				}).Return(nil).Once()
+			},
+			wantErr: "loan C-4 is finish",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, err := uc.MatchStatementLine(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	mockStatementRepo.AssertExpectations(t)
+	mockLoanRepo.AssertExpectations(t)
+	mockTransactionUC.AssertExpectations(t)
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+// settleEarly returns the outstanding installments as charged when the loan is settled on
+// asOf. Installments already due keep their full interest, the running installment earns
+// interest pro rata by day and later installments earn none. The interest that is not
+// earned is returned as the rebate, each installment carries its own in
+// InterestRebateAmount.
+func settleEarly(outstanding []repository.LoanInstallment, asOf time.Time) (settled []repository.LoanInstallment, rebate money.Money) {
+	settled = make([]repository.LoanInstallment, 0, len(outstanding))
+	for _, installment := range outstanding {
+		earnedInterest := installment.InterestAmount
+		if daysBetween(installment.DueDate, asOf) < 0 {
+			periodStart := installment.DueDate.AddDate(0, -1, 0)
+			elapsedDays := daysBetween(periodStart, asOf)
+			periodDays := daysBetween(periodStart, installment.DueDate)
+
+			earnedInterest = money.Zero
+			if elapsedDays > 0 && periodDays > 0 {
+				earnedInterest = installment.InterestAmount.Mul(int64(elapsedDays)).Div(int64(periodDays))
+			}
+		}
+
+		earnedInterest = money.Max(earnedInterest, installment.PaidInterestAmount)
+		rebate = rebate.Add(installment.InterestAmount.Sub(earnedInterest))
+		installment.InterestRebateAmount = installment.InterestRebateAmount.Add(installment.InterestAmount.Sub(earnedInterest))
+		installment.InterestAmount = earnedInterest
+		settled = append(settled, installment)
+	}
+
+	return settled, rebate
+}
@@ -4,74 +4,156 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sync"
 	"time"
 
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 )
 
 type TransactionUsecase interface {
 	GetRemainingPayment(ctx context.Context, req TransactionRequest) (response RemainingPaymentResponse, err error)
 	CreateTransaction(ctx context.Context, req TransactionRequest) (response GetTransactionResponse, err error)
+	CreateSettlementQuote(ctx context.Context, req SettlementQuoteRequest) (response SettlementQuoteResponse, err error)
+	ReverseTransaction(ctx context.Context, req ReverseTransactionRequest) (response GetTransactionResponse, err error)
+	GetTransactionByID(ctx context.Context, id int64) (response GetTransactionResponse, err error)
+	GetTransactionReceipt(ctx context.Context, id int64) (response ReceiptResponse, err error)
+	FetchTransactions(ctx context.Context, req FetchTransactionsRequest) (response []GetTransactionResponse, err error)
 }
 
 type transactionUsecase struct {
-	transactionRepo   repository.TransactionRepository
-	loanRepo          repository.LoanRepository
-	consumerLimitRepo repository.ConsumerLimitRepository
-	consumerRepo      repository.ConsumerRepository
-	ctxTimeout        time.Duration
-	sync.Mutex
+	transactionRepo     repository.TransactionRepository
+	loanRepo            repository.LoanRepository
+	loanInstallmentRepo repository.LoanInstallmentRepository
+	loanPenaltyRepo     repository.LoanPenaltyRepository
+	settlementQuoteRepo repository.SettlementQuoteRepository
+	consumerLimitRepo   repository.ConsumerLimitRepository
+	consumerRepo        repository.ConsumerRepository
+	ledgerRepo          repository.LedgerRepository
+	receiptRepo         repository.ReceiptRepository
+	paymentAllocator    PaymentAllocator
+	settlementConfig    config.SettlementConfig
+	ctxTimeout          time.Duration
 }
 
 type (
+	// TransactionRequest is a payment of a loan. Channel is counter, bank_transfer or
+	// virtual_account and ExternalReference the reference of the payment on that channel,
+	// e.g. the bank transfer reference, booked once per channel. ValueDate is the date the
+	// money was received formatted as YYYY-MM-DD, today by default.
 	TransactionRequest struct {
-		ConsumerID      int64  `json:"consumer_id" query:"consumer_id"`
-		LoanID          int64  `json:"loan_id" query:"loan_id"`
-		TramsactionType string `json:"transaction_type" query:"transaction_type"`
+		ConsumerID        int64       `json:"consumer_id" query:"consumer_id"`
+		LoanID            int64       `json:"loan_id" query:"loan_id"`
+		TramsactionType   string      `json:"transaction_type" query:"transaction_type"`
+		Installment       int32       `json:"installment" query:"installment"`
+		Amount            money.Money `json:"amount" query:"amount"`
+		SettlementQuoteID int64       `json:"settlement_quote_id" query:"settlement_quote_id"`
+		Channel           string      `json:"channel" query:"channel"`
+		ExternalReference string      `json:"external_reference" query:"external_reference"`
+		ValueDate         string      `json:"value_date" query:"value_date"`
+	}
+
+	SettlementQuoteRequest struct {
+		ConsumerID int64 `json:"consumer_id"`
+		LoanID     int64 `json:"loan_id"`
 	}
 
 	RemainingPaymentResponse struct {
-		ContractNumber          string    `json:"contract_number"`
-		Installment             int32     `json:"installment,omitempty"`
-		Tenure                  int16     `json:"tenure"`
-		DueDate                 time.Time `json:"due_date"`
-		PaidLoanAmount          float64   `json:"paid_loan_amount"`
-		PaidInterestAmount      float64   `json:"paid_interest_amount"`
-		RemainingLoanAmount     float64   `json:"remaining_loan_amount"`
-		RemainingInterestAmount float64   `json:"remaining_interest_amount"`
-		TotalRemainingAmount    float64   `json:"total_remaining_amount"`
+		ContractNumber          string      `json:"contract_number"`
+		Installment             int32       `json:"installment,omitempty"`
+		Tenure                  int16       `json:"tenure"`
+		DueDate                 time.Time   `json:"due_date"`
+		PaidLoanAmount          money.Money `json:"paid_loan_amount"`
+		PaidInterestAmount      money.Money `json:"paid_interest_amount"`
+		RemainingLoanAmount     money.Money `json:"remaining_loan_amount"`
+		RemainingInterestAmount money.Money `json:"remaining_interest_amount"`
+		PenaltyAmount           money.Money `json:"penalty_amount"`
+		InterestRebateAmount    money.Money `json:"interest_rebate_amount"`
+		SettlementFeeAmount     money.Money `json:"settlement_fee_amount"`
+		TotalRemainingAmount    money.Money `json:"total_remaining_amount"`
+	}
+
+	SettlementQuoteResponse struct {
+		ID                       int64       `json:"id"`
+		LoanID                   int64       `json:"loan_id"`
+		ContractNumber           string      `json:"contract_number"`
+		RemainingPrincipalAmount money.Money `json:"remaining_principal_amount"`
+		EarnedInterestAmount     money.Money `json:"earned_interest_amount"`
+		InterestRebateAmount     money.Money `json:"interest_rebate_amount"`
+		PenaltyAmount            money.Money `json:"penalty_amount"`
+		SettlementFeeAmount      money.Money `json:"settlement_fee_amount"`
+		TotalAmount              money.Money `json:"total_amount"`
+		QuotedAt                 string      `json:"quoted_at"`
+		ExpiredAt                string      `json:"expired_at"`
 	}
 
 	GetTransactionResponse struct {
-		ID          int64   `json:"id"`
-		ConsumerID  int64   `json:"consumer_id"`
-		LoanID      int64   `json:"loan_id"`
-		Amount      float64 `json:"amount"`
-		Description string  `json:"description"`
+		ID                      int64               `json:"id"`
+		ConsumerID              int64               `json:"consumer_id"`
+		LoanID                  int64               `json:"loan_id"`
+		ReversalOfTransactionID int64               `json:"reversal_of_transaction_id,omitempty"`
+		Amount                  money.Money         `json:"amount"`
+		Description             string              `json:"description"`
+		TransactionType         string              `json:"transaction_type,omitempty"`
+		Channel                 string              `json:"channel,omitempty"`
+		ExternalReference       string              `json:"external_reference,omitempty"`
+		ValueDate               string              `json:"value_date,omitempty"`
+		CreditAmount            money.Money         `json:"credit_amount"`
+		Allocations             []PaymentAllocation `json:"allocations"`
+		ReceiptNumber           string              `json:"receipt_number,omitempty"`
+		ReversedBy              string              `json:"reversed_by,omitempty"`
+		ReversalReason          string              `json:"reversal_reason,omitempty"`
+		ReversedAt              string              `json:"reversed_at,omitempty"`
+		CreatedAt               string              `json:"created_at,omitempty"`
 	}
 )
 
+const (
+	// TransactionTypeReversal is the type of the transaction booked by a reversal, payments
+	// are booked with the type they were requested with.
+	TransactionTypeReversal = "reversal"
+
+	TransactionChannelCounter        = "counter"
+	TransactionChannelBankTransfer   = "bank_transfer"
+	TransactionChannelVirtualAccount = "virtual_account"
+)
+
 var (
 	validTransactionType = map[string]bool{
-		"installment": true,
-		"full":        true,
+		"installment":      true,
+		"full":             true,
+		"partial":          true,
+		"early_settlement": true,
 	}
 )
 
 func NewTransactionUsecase(
 	transactionRepo repository.TransactionRepository,
 	loanRepo repository.LoanRepository,
+	loanInstallmentRepo repository.LoanInstallmentRepository,
+	loanPenaltyRepo repository.LoanPenaltyRepository,
+	settlementQuoteRepo repository.SettlementQuoteRepository,
 	consumerLimitRepo repository.ConsumerLimitRepository,
 	consumerRepo repository.ConsumerRepository,
+	ledgerRepo repository.LedgerRepository,
+	receiptRepo repository.ReceiptRepository,
+	settlementConfig config.SettlementConfig,
+	paymentConfig config.PaymentConfig,
 	timeout time.Duration,
 ) TransactionUsecase {
 	return &transactionUsecase{
-		transactionRepo:   transactionRepo,
-		loanRepo:          loanRepo,
-		consumerLimitRepo: consumerLimitRepo,
-		consumerRepo:      consumerRepo,
-		ctxTimeout:        timeout,
+		transactionRepo:     transactionRepo,
+		loanRepo:            loanRepo,
+		loanInstallmentRepo: loanInstallmentRepo,
+		loanPenaltyRepo:     loanPenaltyRepo,
+		settlementQuoteRepo: settlementQuoteRepo,
+		consumerLimitRepo:   consumerLimitRepo,
+		consumerRepo:        consumerRepo,
+		ledgerRepo:          ledgerRepo,
+		receiptRepo:         receiptRepo,
+		paymentAllocator:    NewPaymentAllocator(paymentConfig.AllocationOrder),
+		settlementConfig:    settlementConfig,
+		ctxTimeout:          timeout,
 	}
 }
 
@@ -79,109 +161,120 @@ func (uc *transactionUsecase) GetRemainingPayment(ctx context.Context, req Trans
 	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
 	defer cancel()
 
-	_, ok := validTransactionType[req.TramsactionType]
-	if !ok {
-		return response, errors.New("transaction_type must be installment or full")
-	}
-
-	consumer, err := uc.consumerRepo.GetConsumerByID(ctx, req.ConsumerID)
+	detail, err := uc.calculateRemainingPayment(ctx, req, time.Now())
 	if err != nil {
 		return response, err
 	}
-	if consumer.ID == 0 {
-		return response, errors.New("consumer not found")
-	}
 
-	loan, err := uc.loanRepo.GetLoanByID(ctx, req.LoanID)
+	return detail.response, nil
+}
+
+func (uc *transactionUsecase) CreateTransaction(ctx context.Context, req TransactionRequest) (response GetTransactionResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	err = validateTransactionType(req.TramsactionType)
 	if err != nil {
 		return response, err
 	}
 
-	if loan.ID == 0 {
-		return response, errors.New("loan not found")
+	if req.TramsactionType == "partial" && !req.Amount.IsPositive() {
+		return response, errors.New("amount must be greater than 0 for a partial payment")
 	}
-
-	if loan.ConsumerID != consumer.ID {
-		return response, errors.New("loan not belong to consumer")
+	if req.TramsactionType != "partial" && !req.Amount.IsZero() {
+		return response, errors.New("amount is only accepted for a partial payment")
 	}
 
-	if loan.LoanStatus == "finish" {
-		return response, errors.New("loan already finished")
+	if req.ExternalReference != "" && req.Channel == "" {
+		return response, errors.New("channel is required for an external reference")
 	}
 
-	consumerLimit, err := uc.consumerLimitRepo.GetConsumerLimitByID(ctx, loan.ConsumerLimitID)
-	if err != nil {
-		return response, err
+	valueDate := time.Now()
+	if req.ValueDate != "" {
+		valueDate, err = time.ParseInLocation("2006-01-02", req.ValueDate, time.Local)
+		if err != nil {
+			return response, errors.New("value_date must be formatted as YYYY-MM-DD")
+		}
 	}
 
-	if consumerLimit.ID == 0 {
-		return response, errors.New("consumer limit not found")
+	// an early settlement is booked as quoted, so it is calculated as of the quote
+	asOf := time.Now()
+	var quote repository.SettlementQuote
+	if req.TramsactionType == "early_settlement" {
+		quote, err = uc.getSettlementQuote(ctx, req)
+		if err != nil {
+			return response, err
+		}
+		asOf = quote.QuotedAt
 	}
 
-	if req.TramsactionType == "full" {
-		response.ContractNumber = loan.ContractNumber
-		response.Tenure = consumerLimit.Tenure
-		response.DueDate = loan.DueDate
-		response.PaidLoanAmount = loan.PaidLoanAmount
-		response.PaidInterestAmount = loan.PaidInterestAmount
-
-		response.RemainingLoanAmount = loan.LoanAmount - loan.PaidLoanAmount
-		response.RemainingInterestAmount = loan.InterestAmount - loan.PaidInterestAmount
-		response.TotalRemainingAmount = response.RemainingLoanAmount + response.RemainingInterestAmount
-	} else {
-		loanAmount := loan.LoanAmount / float64(consumerLimit.Tenure)
-		interestAmount := loan.InterestAmount / float64(consumerLimit.Tenure)
-
-		response.ContractNumber = loan.ContractNumber
-		response.Installment = loan.Installment + 1
-		response.Tenure = consumerLimit.Tenure
-		response.DueDate = loan.DueDate
-		response.PaidLoanAmount = loan.PaidLoanAmount
-		response.PaidInterestAmount = loan.PaidInterestAmount
-
-		response.RemainingLoanAmount = loanAmount
-		response.RemainingInterestAmount = interestAmount
-		response.TotalRemainingAmount = response.RemainingLoanAmount + response.RemainingInterestAmount
+	tx, err := uc.transactionRepo.BeginTx(ctx)
+	if err != nil {
+		return response, err
 	}
 
-	return response, nil
-}
-
-func (uc *transactionUsecase) CreateTransaction(ctx context.Context, req TransactionRequest) (response GetTransactionResponse, err error) {
-	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
-	defer cancel()
-
-	uc.Lock()
-	defer uc.Unlock()
-
-	remainingPayemnt, err := uc.GetRemainingPayment(ctx, req)
+	// the loan row stays locked until the payment is committed, so payments of the same loan
+	// are booked one after the other, on every instance, while other loans are paid in
+	// parallel. The schedule is read after the lock is taken and therefore already includes
+	// the installments settled by the payment booked before.
+	found, err := uc.loanRepo.LockLoanByID(ctx, req.LoanID, tx)
 	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
 		return response, err
 	}
+	if !found {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, errors.New("loan not found")
+	}
 
-	tx, err := uc.transactionRepo.BeginTx(ctx)
+	detail, err := uc.calculateRemainingPayment(ctx, req, asOf)
 	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
 		return response, err
 	}
+	remainingPayemnt := detail.response
+
+	if quote.ID != 0 && remainingPayemnt.TotalRemainingAmount != quote.TotalAmount {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, errors.New("settlement quote no longer valid")
+	}
+
+	// a partial payment is whatever amount was paid, the other types pay exactly what is due
+	amount := remainingPayemnt.TotalRemainingAmount
+	if req.TramsactionType == "partial" {
+		amount = req.Amount
+	}
+
+	allocations, creditAmount := uc.paymentAllocator.Allocate(amount.Sub(remainingPayemnt.SettlementFeeAmount), detail.installments, detail.penalties)
+	if remainingPayemnt.SettlementFeeAmount.IsPositive() {
+		allocations = append(allocations, PaymentAllocation{Component: AllocationComponentSettlementFee, Amount: remainingPayemnt.SettlementFeeAmount})
+	}
+	if creditAmount.IsPositive() {
+		allocations = append(allocations, PaymentAllocation{Component: AllocationComponentCredit, Amount: creditAmount})
+	}
 
 	now := time.Now()
+	paidInstallments, penalties, settled := applyAllocations(detail.installments, detail.penalties, allocations, now)
+
 	loanStatus := "on_going"
-	installment := remainingPayemnt.Installment
 
 	if now.After(remainingPayemnt.DueDate) {
 		loanStatus = "late"
 	}
 
-	if req.TramsactionType == "full" || remainingPayemnt.Installment == int32(remainingPayemnt.Tenure) {
+	if detail.isLastPayment && settled {
 		loanStatus = "finish"
-		installment = int32(remainingPayemnt.Tenure)
 	}
 
 	transaction := repository.Transaction{
-		ConsumerID:  req.ConsumerID,
-		LoanID:      req.LoanID,
-		Amount:      remainingPayemnt.TotalRemainingAmount,
-		Description: fmt.Sprintf("Payment for loan %s", remainingPayemnt.ContractNumber),
+		ConsumerID:        req.ConsumerID,
+		LoanID:            req.LoanID,
+		Amount:            amount,
+		Description:       fmt.Sprintf("Payment for loan %s", remainingPayemnt.ContractNumber),
+		TransactionType:   req.TramsactionType,
+		Channel:           req.Channel,
+		ExternalReference: req.ExternalReference,
+		ValueDate:         valueDate,
 	}
 
 	transactionID, err := uc.transactionRepo.CreateTransaction(ctx, tx, transaction)
@@ -190,12 +283,85 @@ func (uc *transactionUsecase) CreateTransaction(ctx context.Context, req Transac
 		return response, err
 	}
 
+	if quote.ID != 0 {
+		used, err := uc.settlementQuoteRepo.UseSettlementQuote(ctx, quote.ID, transactionID, tx)
+		if err != nil {
+			uc.transactionRepo.RollbackTx(ctx, tx)
+			return response, err
+		}
+
+		if !used {
+			uc.transactionRepo.RollbackTx(ctx, tx)
+			return response, errors.New("settlement quote already used")
+		}
+	}
+
+	for _, penalty := range penalties {
+		err = uc.loanPenaltyRepo.UpsertLoanPenalty(ctx, penalty, tx)
+		if err != nil {
+			uc.transactionRepo.RollbackTx(ctx, tx)
+			return response, err
+		}
+	}
+
+	if len(detail.unsavedSchedule) > 0 {
+		schedule := detail.unsavedSchedule
+		for i := range schedule {
+			for _, installment := range paidInstallments {
+				if schedule[i].InstallmentNumber == installment.InstallmentNumber {
+					schedule[i].InterestAmount = installment.InterestAmount
+					schedule[i].PaidPrincipalAmount = installment.PaidPrincipalAmount
+					schedule[i].PaidInterestAmount = installment.PaidInterestAmount
+					schedule[i].InterestRebateAmount = installment.InterestRebateAmount
+					schedule[i].InstallmentStatus = installment.InstallmentStatus
+					schedule[i].PaidAt = installment.PaidAt
+				}
+			}
+		}
+
+		err = uc.loanInstallmentRepo.CreateLoanInstallments(ctx, schedule, tx)
+		if err != nil {
+			uc.transactionRepo.RollbackTx(ctx, tx)
+			return response, err
+		}
+	}
+
+	var paidPrincipalAmount, paidInterestAmount money.Money
+	for _, installment := range paidInstallments {
+		paidPrincipalAmount = paidPrincipalAmount.Add(allocatedAmount(allocations, installment.InstallmentNumber, AllocationComponentPrincipal))
+		paidInterestAmount = paidInterestAmount.Add(allocatedAmount(allocations, installment.InstallmentNumber, AllocationComponentInterest))
+
+		if installment.ID == 0 {
+			continue
+		}
+
+		var paidAt *time.Time
+		if !installment.PaidAt.IsZero() {
+			paidAt = &now
+		}
+
+		err = uc.loanInstallmentRepo.UpdateLoanInstallment(ctx, repository.UpdateLoanInstallmentRequest{
+			ID:                   installment.ID,
+			InterestAmount:       installment.InterestAmount,
+			PaidPrincipalAmount:  installment.PaidPrincipalAmount,
+			PaidInterestAmount:   installment.PaidInterestAmount,
+			InterestRebateAmount: installment.InterestRebateAmount,
+			InstallmentStatus:    installment.InstallmentStatus,
+			PaidAt:               paidAt,
+		}, tx)
+		if err != nil {
+			uc.transactionRepo.RollbackTx(ctx, tx)
+			return response, err
+		}
+	}
+
+	// the interest rebated by an early settlement is not charged anymore
 	loan := repository.UpdateLoanRequest{
 		ID:                 req.LoanID,
-		PaidLoanAmount:     remainingPayemnt.PaidLoanAmount + remainingPayemnt.RemainingLoanAmount,
-		PaidInterestAmount: remainingPayemnt.PaidInterestAmount + remainingPayemnt.RemainingInterestAmount,
+		InterestAmount:     detail.loan.InterestAmount.Sub(remainingPayemnt.InterestRebateAmount),
+		PaidLoanAmount:     remainingPayemnt.PaidLoanAmount.Add(paidPrincipalAmount),
+		PaidInterestAmount: remainingPayemnt.PaidInterestAmount.Add(paidInterestAmount),
 		LoanStatus:         loanStatus,
-		Installment:        installment,
 	}
 
 	err = uc.loanRepo.UpdateLoan(ctx, loan, tx)
@@ -204,6 +370,59 @@ func (uc *transactionUsecase) CreateTransaction(ctx context.Context, req Transac
 		return response, err
 	}
 
+	// whatever is left once the loan is paid off is kept for the consumer
+	if creditAmount.IsPositive() {
+		err = uc.consumerRepo.AddCreditBalance(ctx, req.ConsumerID, creditAmount, tx)
+		if err != nil {
+			uc.transactionRepo.RollbackTx(ctx, tx)
+			return response, err
+		}
+	}
+
+	transactionAllocations := make([]repository.TransactionAllocation, 0, len(allocations))
+	for _, allocation := range allocations {
+		transactionAllocations = append(transactionAllocations, repository.TransactionAllocation{
+			TransactionID:     transactionID,
+			InstallmentNumber: allocation.InstallmentNumber,
+			Component:         allocation.Component,
+			Amount:            allocation.Amount,
+		})
+	}
+
+	err = uc.transactionRepo.CreateTransactionAllocations(ctx, tx, transactionAllocations)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	err = postJournalEntry(ctx, uc.ledgerRepo, paymentJournalEntry(transactionID, transaction.Description, amount, allocations, now), tx)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	// what is left to pay is printed on the receipt, interest rebated by an early
+	// settlement is not owed anymore
+	remainingPrincipal := detail.loan.LoanAmount.Sub(loan.PaidLoanAmount)
+	remainingInterest := detail.loan.InterestAmount.Sub(loan.PaidInterestAmount)
+	if loanStatus == LoanStatusFinish {
+		remainingPrincipal, remainingInterest = money.Zero, money.Zero
+	}
+
+	// the receipt number is taken last, the sequence of the month stays locked until the
+	// payment is committed
+	receiptNumber, err := uc.issueReceipt(ctx, repository.Receipt{
+		TransactionID:            transactionID,
+		LoanID:                   req.LoanID,
+		RemainingPrincipalAmount: remainingPrincipal,
+		RemainingInterestAmount:  remainingInterest,
+		IssuedAt:                 now,
+	}, tx)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
 	err = uc.transactionRepo.CommitTx(ctx, tx)
 	if err != nil {
 		return response, err
@@ -214,6 +433,286 @@ func (uc *transactionUsecase) CreateTransaction(ctx context.Context, req Transac
 	response.LoanID = req.LoanID
 	response.Amount = transaction.Amount
 	response.Description = transaction.Description
+	response.TransactionType = transaction.TransactionType
+	response.Channel = transaction.Channel
+	response.ExternalReference = transaction.ExternalReference
+	response.ValueDate = formatOptionalDate(transaction.ValueDate)
+	response.CreditAmount = creditAmount
+	response.Allocations = allocations
+	response.ReceiptNumber = receiptNumber
 
 	return response, nil
 }
+
+// applyAllocations adds the allocations of a payment to the installments and penalties it
+// was allocated over. It returns the installments that received a part of the payment or
+// that an interest rebate left paid, the penalties with their new paid amounts, and reports
+// whether all of them are now paid.
+func applyAllocations(installments []repository.LoanInstallment, penalties []repository.LoanPenalty, allocations []PaymentAllocation, paidAt time.Time) (paidInstallments []repository.LoanInstallment, paidPenalties []repository.LoanPenalty, settled bool) {
+	settled = true
+
+	for _, installment := range installments {
+		principal := allocatedAmount(allocations, installment.InstallmentNumber, AllocationComponentPrincipal)
+		interest := allocatedAmount(allocations, installment.InstallmentNumber, AllocationComponentInterest)
+		installment.PaidPrincipalAmount = installment.PaidPrincipalAmount.Add(principal)
+		installment.PaidInterestAmount = installment.PaidInterestAmount.Add(interest)
+
+		isPaid := !installment.PaidPrincipalAmount.LessThan(installment.PrincipalAmount) &&
+			!installment.PaidInterestAmount.LessThan(installment.InterestAmount)
+		if !isPaid {
+			settled = false
+		}
+
+		if principal.IsZero() && interest.IsZero() && !isPaid {
+			continue
+		}
+
+		installment.InstallmentStatus = "partial"
+		if isPaid {
+			installment.InstallmentStatus = "paid"
+			installment.PaidAt = paidAt
+		}
+		paidInstallments = append(paidInstallments, installment)
+	}
+
+	for _, penalty := range penalties {
+		penalty.PaidPenaltyAmount = penalty.PaidPenaltyAmount.Add(allocatedAmount(allocations, penalty.InstallmentNumber, AllocationComponentPenalty))
+		if penalty.PaidPenaltyAmount.LessThan(penalty.PenaltyAmount) {
+			settled = false
+		}
+		paidPenalties = append(paidPenalties, penalty)
+	}
+
+	return paidInstallments, paidPenalties, settled
+}
+
+// remainingPaymentDetail is the outcome of calculateRemainingPayment. installments are
+// the installments settled by the payment, carrying the interest actually charged, and
+// isLastPayment reports whether they are the last outstanding ones. penalties are the
+// unpaid penalties accrued by the end-of-day batch. unsavedSchedule is only set for loans
+// whose schedule has not been persisted yet and must be stored together with the payment.
+// loan is the loan as read before the payment.
+type remainingPaymentDetail struct {
+	response        RemainingPaymentResponse
+	installments    []repository.LoanInstallment
+	penalties       []repository.LoanPenalty
+	unsavedSchedule []repository.LoanInstallment
+	isLastPayment   bool
+	loan            repository.Loan
+}
+
+// calculateRemainingPayment works out what a payment of req settles as of asOf.
+func (uc *transactionUsecase) calculateRemainingPayment(ctx context.Context, req TransactionRequest, asOf time.Time) (detail remainingPaymentDetail, err error) {
+	err = validateTransactionType(req.TramsactionType)
+	if err != nil {
+		return detail, err
+	}
+
+	consumer, err := uc.consumerRepo.GetConsumerByID(ctx, req.ConsumerID)
+	if err != nil {
+		return detail, err
+	}
+	if consumer.ID == 0 {
+		return detail, errors.New("consumer not found")
+	}
+
+	loan, err := uc.loanRepo.GetLoanByID(ctx, req.LoanID)
+	if err != nil {
+		return detail, err
+	}
+
+	if loan.ID == 0 {
+		return detail, errors.New("loan not found")
+	}
+
+	if loan.ConsumerID != consumer.ID {
+		return detail, errors.New("loan not belong to consumer")
+	}
+
+	switch loan.LoanStatus {
+	case LoanStatusFinish:
+		return detail, errors.New("loan already finished")
+	case LoanStatusPendingReview, LoanStatusApproved:
+		return detail, errors.New("loan not disbursed yet")
+	case LoanStatusRejected, LoanStatusCancelled:
+		return detail, fmt.Errorf("loan already %s", loan.LoanStatus)
+	}
+
+	consumerLimit, err := uc.consumerLimitRepo.GetConsumerLimitByID(ctx, loan.ConsumerLimitID)
+	if err != nil {
+		return detail, err
+	}
+
+	if consumerLimit.ID == 0 {
+		return detail, errors.New("consumer limit not found")
+	}
+
+	schedule, err := uc.loanInstallmentRepo.GetLoanInstallmentsByLoanID(ctx, loan.ID)
+	if err != nil {
+		return detail, err
+	}
+
+	if len(schedule) == 0 {
+		// loans booked before schedules were persisted have no rows yet
+		schedule, err = rebuildInstallmentSchedule(loan, consumerLimit.Tenure)
+		if err != nil {
+			return detail, err
+		}
+		detail.unsavedSchedule = schedule
+	}
+
+	var outstanding []repository.LoanInstallment
+	for _, installment := range schedule {
+		if !isInstallmentClosed(installment.InstallmentStatus) {
+			outstanding = append(outstanding, installment)
+		}
+	}
+
+	if len(outstanding) == 0 {
+		return detail, errors.New("no outstanding installment found")
+	}
+
+	// penalties are accrued by the end-of-day batch, a payment collects what was accrued so far
+	storedPenalties, err := uc.loanPenaltyRepo.GetLoanPenaltiesByLoanID(ctx, loan.ID)
+	if err != nil {
+		return detail, err
+	}
+
+	response := RemainingPaymentResponse{}
+	for _, penalty := range storedPenalties {
+		if penalty.PenaltyAmount.GreaterThan(penalty.PaidPenaltyAmount) {
+			response.PenaltyAmount = response.PenaltyAmount.Add(penalty.PenaltyAmount.Sub(penalty.PaidPenaltyAmount))
+			detail.penalties = append(detail.penalties, penalty)
+		}
+	}
+
+	installments := outstanding
+	switch req.TramsactionType {
+	case "installment":
+		installments = outstanding[:1]
+		response.Installment = installments[0].InstallmentNumber
+
+		// a retried payment of an installment that was paid meanwhile must not settle the next one
+		if req.Installment != 0 && req.Installment != response.Installment {
+			if req.Installment < response.Installment {
+				return detail, fmt.Errorf("installment %d already paid", req.Installment)
+			}
+			return detail, fmt.Errorf("installment %d must be paid first", response.Installment)
+		}
+	case "early_settlement":
+		installments, response.InterestRebateAmount = settleEarly(outstanding, asOf)
+	}
+
+	response.ContractNumber = loan.ContractNumber
+	response.Tenure = consumerLimit.Tenure
+	response.DueDate = installments[0].DueDate
+	response.PaidLoanAmount = loan.PaidLoanAmount
+	response.PaidInterestAmount = loan.PaidInterestAmount
+
+	for _, installment := range installments {
+		response.RemainingLoanAmount = response.RemainingLoanAmount.Add(installment.PrincipalAmount.Sub(installment.PaidPrincipalAmount))
+		response.RemainingInterestAmount = response.RemainingInterestAmount.Add(installment.InterestAmount.Sub(installment.PaidInterestAmount))
+	}
+
+	if req.TramsactionType == "early_settlement" {
+		response.SettlementFeeAmount = response.RemainingLoanAmount.Percent(uc.settlementConfig.FeeRate)
+	}
+
+	response.TotalRemainingAmount = money.Sum(response.PenaltyAmount, response.RemainingLoanAmount,
+		response.RemainingInterestAmount, response.SettlementFeeAmount)
+
+	detail.response = response
+	detail.installments = installments
+	detail.isLastPayment = len(installments) == len(outstanding)
+	detail.loan = loan
+
+	return detail, nil
+}
+
+// CreateSettlementQuote quotes the amount that settles a loan today, rebating the interest
+// not yet earned. The quote can be paid with an early_settlement transaction until it expires.
+func (uc *transactionUsecase) CreateSettlementQuote(ctx context.Context, req SettlementQuoteRequest) (response SettlementQuoteResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Second)
+	detail, err := uc.calculateRemainingPayment(ctx, TransactionRequest{
+		ConsumerID:      req.ConsumerID,
+		LoanID:          req.LoanID,
+		TramsactionType: "early_settlement",
+	}, now)
+	if err != nil {
+		return response, err
+	}
+
+	quote := repository.SettlementQuote{
+		LoanID:                   req.LoanID,
+		ConsumerID:               req.ConsumerID,
+		RemainingPrincipalAmount: detail.response.RemainingLoanAmount,
+		EarnedInterestAmount:     detail.response.RemainingInterestAmount,
+		InterestRebateAmount:     detail.response.InterestRebateAmount,
+		PenaltyAmount:            detail.response.PenaltyAmount,
+		SettlementFeeAmount:      detail.response.SettlementFeeAmount,
+		TotalAmount:              detail.response.TotalRemainingAmount,
+		QuotedAt:                 now,
+		ExpiredAt:                now.Add(uc.settlementConfig.QuoteValidity),
+	}
+
+	quoteID, err := uc.settlementQuoteRepo.CreateSettlementQuote(ctx, quote)
+	if err != nil {
+		return response, err
+	}
+
+	response = SettlementQuoteResponse{
+		ID:                       quoteID,
+		LoanID:                   quote.LoanID,
+		ContractNumber:           detail.response.ContractNumber,
+		RemainingPrincipalAmount: quote.RemainingPrincipalAmount,
+		EarnedInterestAmount:     quote.EarnedInterestAmount,
+		InterestRebateAmount:     quote.InterestRebateAmount,
+		PenaltyAmount:            quote.PenaltyAmount,
+		SettlementFeeAmount:      quote.SettlementFeeAmount,
+		TotalAmount:              quote.TotalAmount,
+		QuotedAt:                 quote.QuotedAt.Format("2006-01-02 15:04:05"),
+		ExpiredAt:                quote.ExpiredAt.Format("2006-01-02 15:04:05"),
+	}
+
+	return response, nil
+}
+
+func validateTransactionType(transactionType string) error {
+	if !validTransactionType[transactionType] {
+		return errors.New("transaction_type must be installment, full, partial or early_settlement")
+	}
+
+	return nil
+}
+
+func (uc *transactionUsecase) getSettlementQuote(ctx context.Context, req TransactionRequest) (quote repository.SettlementQuote, err error) {
+	if req.SettlementQuoteID == 0 {
+		return quote, errors.New("settlement_quote_id is required for early_settlement")
+	}
+
+	quote, err = uc.settlementQuoteRepo.GetSettlementQuoteByID(ctx, req.SettlementQuoteID)
+	if err != nil {
+		return quote, err
+	}
+
+	if quote.ID == 0 {
+		return quote, errors.New("settlement quote not found")
+	}
+
+	if quote.LoanID != req.LoanID || quote.ConsumerID != req.ConsumerID {
+		return quote, errors.New("settlement quote not belong to loan")
+	}
+
+	if !quote.UsedAt.IsZero() {
+		return quote, errors.New("settlement quote already used")
+	}
+
+	if time.Now().After(quote.ExpiredAt) {
+		return quote, errors.New("settlement quote expired")
+	}
+
+	return quote, nil
+}
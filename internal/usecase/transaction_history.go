@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/utils"
+)
+
+// FetchTransactionsRequest filters the transaction history. From and To bound the day the
+// transaction was booked and ValueFrom and ValueTo its value date, all formatted as YYYY-MM-DD
+// and inclusive. ExternalReference is matched exactly. SortBy is created_at or amount and
+// SortOrder asc or desc, the newest transaction comes first by default.
+type FetchTransactionsRequest struct {
+	ConsumerID        int64  `json:"consumer_id" query:"consumer_id"`
+	LoanID            int64  `json:"loan_id" query:"loan_id"`
+	TransactionType   string `json:"transaction_type" query:"transaction_type"`
+	Channel           string `json:"channel" query:"channel"`
+	ExternalReference string `json:"external_reference" query:"external_reference"`
+	From              string `json:"from" query:"from"`
+	To                string `json:"to" query:"to"`
+	ValueFrom         string `json:"value_from" query:"value_from"`
+	ValueTo           string `json:"value_to" query:"value_to"`
+	SortBy            string `json:"sort_by" query:"sort_by"`
+	SortOrder         string `json:"sort_order" query:"sort_order"`
+	Page              int    `json:"page" query:"page"`
+	Limit             int    `json:"limit" query:"limit"`
+}
+
+func (uc *transactionUsecase) GetTransactionByID(ctx context.Context, id int64) (response GetTransactionResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	transaction, err := uc.transactionRepo.GetTransactionByID(ctx, id)
+	if err != nil {
+		return response, err
+	}
+	if transaction.ID == 0 {
+		return response, errors.New("transaction not found")
+	}
+
+	allocations, err := uc.transactionRepo.GetTransactionAllocationsByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		return response, err
+	}
+
+	return toTransactionResponse(transaction, allocations), nil
+}
+
+func (uc *transactionUsecase) FetchTransactions(ctx context.Context, req FetchTransactionsRequest) (response []GetTransactionResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	limit, offset := utils.ParsePagination(req.Page, req.Limit)
+	filter := repository.FetchTransactionsRequest{
+		ConsumerID:        req.ConsumerID,
+		LoanID:            req.LoanID,
+		TransactionType:   req.TransactionType,
+		Channel:           req.Channel,
+		ExternalReference: req.ExternalReference,
+		SortBy:            req.SortBy,
+		SortOrder:         req.SortOrder,
+		Limit:             limit,
+		Offset:            offset,
+	}
+
+	if req.From != "" {
+		filter.From, err = time.ParseInLocation("2006-01-02", req.From, time.Local)
+		if err != nil {
+			return response, errors.New("from must be formatted as YYYY-MM-DD")
+		}
+	}
+	if req.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", req.To, time.Local)
+		if err != nil {
+			return response, errors.New("to must be formatted as YYYY-MM-DD")
+		}
+		// the whole last day is included
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	filter.ValueDateFrom, filter.ValueDateTo, err = parseDateRange(req.ValueFrom, req.ValueTo)
+	if err != nil {
+		return response, errors.New("value_from and value_to must be formatted as YYYY-MM-DD")
+	}
+
+	transactions, err := uc.transactionRepo.FetchTransactions(ctx, filter)
+	if err != nil {
+		return response, err
+	}
+
+	transactionIDs := make([]int64, 0, len(transactions))
+	for _, transaction := range transactions {
+		transactionIDs = append(transactionIDs, transaction.ID)
+	}
+
+	allocations, err := uc.transactionRepo.GetTransactionAllocationsByTransactionIDs(ctx, transactionIDs)
+	if err != nil {
+		return response, err
+	}
+
+	allocationsByTransaction := make(map[int64][]repository.TransactionAllocation, len(transactions))
+	for _, allocation := range allocations {
+		allocationsByTransaction[allocation.TransactionID] = append(allocationsByTransaction[allocation.TransactionID], allocation)
+	}
+
+	response = make([]GetTransactionResponse, 0, len(transactions))
+	for _, transaction := range transactions {
+		response = append(response, toTransactionResponse(transaction, allocationsByTransaction[transaction.ID]))
+	}
+
+	return response, nil
+}
+
+// toTransactionResponse turns a stored transaction and its allocations into the response of
+// the payment that booked it.
+func toTransactionResponse(transaction repository.Transaction, allocations []repository.TransactionAllocation) GetTransactionResponse {
+	response := GetTransactionResponse{
+		ID:                      transaction.ID,
+		ConsumerID:              transaction.ConsumerID,
+		LoanID:                  transaction.LoanID,
+		ReversalOfTransactionID: transaction.ReversalOfTransactionID,
+		Amount:                  transaction.Amount,
+		Description:             transaction.Description,
+		TransactionType:         transaction.TransactionType,
+		Channel:                 transaction.Channel,
+		ExternalReference:       transaction.ExternalReference,
+		ValueDate:               formatOptionalDate(transaction.ValueDate),
+		Allocations:             make([]PaymentAllocation, 0, len(allocations)),
+		ReversedBy:              transaction.ReversedBy,
+		ReversalReason:          transaction.ReversalReason,
+		ReversedAt:              formatOptionalTime(transaction.ReversedAt),
+		CreatedAt:               formatOptionalTime(transaction.CreatedAt),
+	}
+
+	for _, allocation := range allocations {
+		if allocation.Component == AllocationComponentCredit {
+			response.CreditAmount = response.CreditAmount.Add(allocation.Amount)
+		}
+
+		response.Allocations = append(response.Allocations, PaymentAllocation{
+			InstallmentNumber: allocation.InstallmentNumber,
+			Component:         allocation.Component,
+			Amount:            allocation.Amount,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,243 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTransactionByID(t *testing.T) {
+	mockTransactionRepo := new(mocks.TransactionRepository)
+	uc := usecase.NewTransactionUsecase(mockTransactionRepo, nil, nil, nil, nil, nil, nil, nil, nil, config.SettlementConfig{}, config.PaymentConfig{}, time.Second*2)
+
+	createdAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		id      int64
+		setup   func()
+		want    usecase.GetTransactionResponse
+		wantErr string
+	}{
+		{
+			name: "transaction not found",
+			id:   1,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(1)).Return(repository.Transaction{}, nil).Once()
+			},
+			wantErr: "transaction not found",
+		},
+		{
+			name: "success with payment breakdown",
+			id:   2,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(2)).Return(repository.Transaction{
+					ID:          2,
+					ConsumerID:  1,
+					LoanID:      1,
+					Amount:      money.New(130),
+					Description: "Payment for loan 123",
+					ReversedAt:  createdAt.Add(time.Hour),
+					CreatedAt:   createdAt,
+				}, nil).Once()
+				mockTransactionRepo.On("GetTransactionAllocationsByTransactionID", mock.Anything, int64(2)).Return([]repository.TransactionAllocation{
+					{ID: 1, TransactionID: 2, InstallmentNumber: 3, Component: "principal", Amount: money.New(100)},
+					{ID: 2, TransactionID: 2, Component: "credit", Amount: money.New(30)},
+				}, nil).Once()
+			},
+			want: usecase.GetTransactionResponse{
+				ID:           2,
+				ConsumerID:   1,
+				LoanID:       1,
+				Amount:       money.New(130),
+				Description:  "Payment for loan 123",
+				CreditAmount: money.New(30),
+				Allocations: []usecase.PaymentAllocation{
+					{InstallmentNumber: 3, Component: "principal", Amount: money.New(100)},
+					{Component: "credit", Amount: money.New(30)},
+				},
+				ReversedAt: "2024-01-15 11:30:00",
+				CreatedAt:  "2024-01-15 10:30:00",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, err := uc.GetTransactionByID(context.Background(), tt.id)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFetchTransactions(t *testing.T) {
+	mockTransactionRepo := new(mocks.TransactionRepository)
+	uc := usecase.NewTransactionUsecase(mockTransactionRepo, nil, nil, nil, nil, nil, nil, nil, nil, config.SettlementConfig{}, config.PaymentConfig{}, time.Second*2)
+
+	createdAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		req     usecase.FetchTransactionsRequest
+		setup   func()
+		want    []usecase.GetTransactionResponse
+		wantErr string
+	}{
+		{
+			name:    "invalid from",
+			req:     usecase.FetchTransactionsRequest{From: "15-01-2024"},
+			setup:   func() {},
+			wantErr: "from must be formatted as YYYY-MM-DD",
+		},
+		{
+			name:    "invalid to",
+			req:     usecase.FetchTransactionsRequest{To: "2024-13-01"},
+			setup:   func() {},
+			wantErr: "to must be formatted as YYYY-MM-DD",
+		},
+		{
+			name:    "invalid value_to",
+			req:     usecase.FetchTransactionsRequest{ValueTo: "31/01/2024"},
+			setup:   func() {},
+			wantErr: "value_from and value_to must be formatted as YYYY-MM-DD",
+		},
+		{
+			name: "filters date ranges including the last day",
+			req: usecase.FetchTransactionsRequest{
+				ConsumerID: 1,
+				LoanID:     1,
+				From:       "2024-01-01",
+				To:         "2024-01-31",
+				ValueFrom:  "2023-12-30",
+				ValueTo:    "2024-01-30",
+				SortBy:     "amount",
+				SortOrder:  "asc",
+				Page:       2,
+				Limit:      5,
+			},
+			setup: func() {
+				mockTransactionRepo.On("FetchTransactions", mock.Anything, repository.FetchTransactionsRequest{
+					ConsumerID:    1,
+					LoanID:        1,
+					From:          time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+					To:            time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local),
+					ValueDateFrom: time.Date(2023, 12, 30, 0, 0, 0, 0, time.Local),
+					ValueDateTo:   time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local),
+					SortBy:        "amount",
+					SortOrder:     "asc",
+					Limit:         5,
+					Offset:        5,
+				}).Return([]repository.Transaction{
+					{ID: 3, ConsumerID: 1, LoanID: 1, ReversalOfTransactionID: 2, Amount: money.New(-60), ReversedBy: "operator", ReversalReason: "bounced", CreatedAt: createdAt},
+					{ID: 2, ConsumerID: 1, LoanID: 1, Amount: money.New(60), CreatedAt: createdAt},
+					{ID: 1, ConsumerID: 1, LoanID: 1, Amount: money.New(110), CreatedAt: createdAt},
+				}, nil).Once()
+				mockTransactionRepo.On("GetTransactionAllocationsByTransactionIDs", mock.Anything, []int64{3, 2, 1}).Return([]repository.TransactionAllocation{
+					{ID: 1, TransactionID: 2, InstallmentNumber: 1, Component: "principal", Amount: money.New(60)},
+					{ID: 2, TransactionID: 3, InstallmentNumber: 1, Component: "principal", Amount: money.New(-60)},
+				}, nil).Once()
+			},
+			want: []usecase.GetTransactionResponse{
+				{
+					ID:                      3,
+					ConsumerID:              1,
+					LoanID:                  1,
+					ReversalOfTransactionID: 2,
+					Amount:                  money.New(-60),
+					Allocations:             []usecase.PaymentAllocation{{InstallmentNumber: 1, Component: "principal", Amount: money.New(-60)}},
+					ReversedBy:              "operator",
+					ReversalReason:          "bounced",
+					CreatedAt:               "2024-01-15 10:30:00",
+				},
+				{
+					ID:          2,
+					ConsumerID:  1,
+					LoanID:      1,
+					Amount:      money.New(60),
+					Allocations: []usecase.PaymentAllocation{{InstallmentNumber: 1, Component: "principal", Amount: money.New(60)}},
+					CreatedAt:   "2024-01-15 10:30:00",
+				},
+				{
+					// booked before payments were broken down
+					ID:          1,
+					ConsumerID:  1,
+					LoanID:      1,
+					Amount:      money.New(110),
+					Allocations: []usecase.PaymentAllocation{},
+					CreatedAt:   "2024-01-15 10:30:00",
+				},
+			},
+		},
+		{
+			name: "finds a payment by its channel reference",
+			req: usecase.FetchTransactionsRequest{
+				TransactionType:   "partial",
+				Channel:           "bank_transfer",
+				ExternalReference: "TRF-001",
+			},
+			setup: func() {
+				mockTransactionRepo.On("FetchTransactions", mock.Anything, repository.FetchTransactionsRequest{
+					TransactionType:   "partial",
+					Channel:           "bank_transfer",
+					ExternalReference: "TRF-001",
+					Limit:             10,
+				}).Return([]repository.Transaction{
+					{ID: 4, ConsumerID: 1, LoanID: 1, Amount: money.New(60), TransactionType: "partial", Channel: "bank_transfer", ExternalReference: "TRF-001", ValueDate: time.Date(2024, 1, 14, 0, 0, 0, 0, time.Local), CreatedAt: createdAt},
+				}, nil).Once()
+				mockTransactionRepo.On("GetTransactionAllocationsByTransactionIDs", mock.Anything, []int64{4}).Return([]repository.TransactionAllocation{}, nil).Once()
+			},
+			want: []usecase.GetTransactionResponse{
+				{
+					ID:                4,
+					ConsumerID:        1,
+					LoanID:            1,
+					Amount:            money.New(60),
+					TransactionType:   "partial",
+					Channel:           "bank_transfer",
+					ExternalReference: "TRF-001",
+					ValueDate:         "2024-01-14",
+					Allocations:       []usecase.PaymentAllocation{},
+					CreatedAt:         "2024-01-15 10:30:00",
+				},
+			},
+		},
+		{
+			name: "repository error",
+			req:  usecase.FetchTransactionsRequest{},
+			setup: func() {
+				mockTransactionRepo.On("FetchTransactions", mock.Anything, repository.FetchTransactionsRequest{Limit: 10}).Return(nil, errors.New("db down")).Once()
+			},
+			wantErr: "db down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, err := uc.FetchTransactions(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	mockTransactionRepo.AssertExpectations(t)
+}
@@ -0,0 +1,330 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+)
+
+type ReverseTransactionRequest struct {
+	TransactionID int64  `json:"-"`
+	ReversedBy    string `json:"reversed_by"`
+	Reason        string `json:"reason"`
+}
+
+// ReverseTransaction undoes a payment, e.g. a bounced transfer or one booked by mistake. It
+// books an offsetting transaction of the negated amount and takes every allocation of the
+// payment back off the installments, penalties, loan and consumer credit balance, all in one
+// DB transaction. The loan is reopened when the payment had finished it. The interest an
+// early settlement rebated is charged again, and its used settlement quote stays used, the
+// settlement has to be quoted again.
+func (uc *transactionUsecase) ReverseTransaction(ctx context.Context, req ReverseTransactionRequest) (response GetTransactionResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.ctxTimeout)
+	defer cancel()
+
+	original, err := uc.transactionRepo.GetTransactionByID(ctx, req.TransactionID)
+	if err != nil {
+		return response, err
+	}
+	if original.ID == 0 {
+		return response, errors.New("transaction not found")
+	}
+	if original.ReversalOfTransactionID != 0 {
+		return response, errors.New("a reversal cannot be reversed")
+	}
+	if !original.ReversedAt.IsZero() {
+		return response, errors.New("transaction already reversed")
+	}
+
+	allocations, err := uc.transactionRepo.GetTransactionAllocationsByTransactionID(ctx, original.ID)
+	if err != nil {
+		return response, err
+	}
+	if len(allocations) == 0 {
+		// payments booked before allocations were recorded cannot be taken apart
+		return response, errors.New("transaction has no allocations to reverse")
+	}
+
+	tx, err := uc.transactionRepo.BeginTx(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	// the same lock as a payment, so a reversal is never booked in between
+	found, err := uc.loanRepo.LockLoanByID(ctx, original.LoanID, tx)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+	if !found {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, errors.New("loan not found")
+	}
+
+	loan, err := uc.loanRepo.GetLoanByID(ctx, original.LoanID)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+	if loan.ID == 0 {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, errors.New("loan not found")
+	}
+
+	schedule, err := uc.loanInstallmentRepo.GetLoanInstallmentsByLoanID(ctx, loan.ID)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	penalties, err := uc.loanPenaltyRepo.GetLoanPenaltiesByLoanID(ctx, loan.ID)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	reversed := make([]PaymentAllocation, 0, len(allocations))
+	for _, allocation := range allocations {
+		reversed = append(reversed, PaymentAllocation{
+			InstallmentNumber: allocation.InstallmentNumber,
+			Component:         allocation.Component,
+			Amount:            money.Zero.Sub(allocation.Amount),
+		})
+	}
+
+	installments, reversedPenalties, err := reverseAllocations(schedule, penalties, reversed)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	var rebateAmount money.Money
+	if original.TransactionType == "early_settlement" {
+		installments, rebateAmount = restoreInterestRebates(schedule, installments)
+	}
+
+	var principalAmount, interestAmount, creditAmount money.Money
+	for _, allocation := range reversed {
+		switch allocation.Component {
+		case AllocationComponentPrincipal:
+			principalAmount = principalAmount.Add(allocation.Amount)
+		case AllocationComponentInterest:
+			interestAmount = interestAmount.Add(allocation.Amount)
+		case AllocationComponentCredit:
+			creditAmount = creditAmount.Add(allocation.Amount)
+		}
+	}
+
+	transaction := repository.Transaction{
+		ConsumerID:              original.ConsumerID,
+		LoanID:                  original.LoanID,
+		ReversalOfTransactionID: original.ID,
+		Amount:                  money.Zero.Sub(original.Amount),
+		Description:             fmt.Sprintf("Reversal of transaction %d for loan %s", original.ID, loan.ContractNumber),
+		TransactionType:         TransactionTypeReversal,
+		Channel:                 original.Channel,
+		ValueDate:               time.Now(),
+		ReversedBy:              req.ReversedBy,
+		ReversalReason:          req.Reason,
+	}
+
+	transactionID, err := uc.transactionRepo.CreateTransaction(ctx, tx, transaction)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	marked, err := uc.transactionRepo.MarkTransactionReversed(ctx, tx, original.ID)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+	if !marked {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, errors.New("transaction already reversed")
+	}
+
+	for _, penalty := range reversedPenalties {
+		err = uc.loanPenaltyRepo.UpsertLoanPenalty(ctx, penalty, tx)
+		if err != nil {
+			uc.transactionRepo.RollbackTx(ctx, tx)
+			return response, err
+		}
+	}
+
+	for _, installment := range installments {
+		err = uc.loanInstallmentRepo.UpdateLoanInstallment(ctx, repository.UpdateLoanInstallmentRequest{
+			ID:                   installment.ID,
+			InterestAmount:       installment.InterestAmount,
+			PaidPrincipalAmount:  installment.PaidPrincipalAmount,
+			PaidInterestAmount:   installment.PaidInterestAmount,
+			InterestRebateAmount: installment.InterestRebateAmount,
+			InstallmentStatus:    installment.InstallmentStatus,
+		}, tx)
+		if err != nil {
+			uc.transactionRepo.RollbackTx(ctx, tx)
+			return response, err
+		}
+	}
+
+	err = uc.loanRepo.UpdateLoan(ctx, repository.UpdateLoanRequest{
+		ID:                 loan.ID,
+		InterestAmount:     loan.InterestAmount.Add(rebateAmount),
+		PaidLoanAmount:     loan.PaidLoanAmount.Add(principalAmount),
+		PaidInterestAmount: loan.PaidInterestAmount.Add(interestAmount),
+		LoanStatus:         reopenedLoanStatus(loan.LoanStatus, schedule, installments, time.Now()),
+	}, tx)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	if creditAmount.IsNegative() {
+		err = uc.consumerRepo.AddCreditBalance(ctx, original.ConsumerID, creditAmount, tx)
+		if err != nil {
+			uc.transactionRepo.RollbackTx(ctx, tx)
+			return response, err
+		}
+	}
+
+	transactionAllocations := make([]repository.TransactionAllocation, 0, len(reversed))
+	for _, allocation := range reversed {
+		transactionAllocations = append(transactionAllocations, repository.TransactionAllocation{
+			TransactionID:     transactionID,
+			InstallmentNumber: allocation.InstallmentNumber,
+			Component:         allocation.Component,
+			Amount:            allocation.Amount,
+		})
+	}
+
+	err = uc.transactionRepo.CreateTransactionAllocations(ctx, tx, transactionAllocations)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	err = postJournalEntry(ctx, uc.ledgerRepo, paymentJournalEntry(transactionID, transaction.Description, transaction.Amount, reversed, time.Now()), tx)
+	if err != nil {
+		uc.transactionRepo.RollbackTx(ctx, tx)
+		return response, err
+	}
+
+	err = uc.transactionRepo.CommitTx(ctx, tx)
+	if err != nil {
+		return response, err
+	}
+
+	response.ID = transactionID
+	response.ConsumerID = transaction.ConsumerID
+	response.LoanID = transaction.LoanID
+	response.ReversalOfTransactionID = original.ID
+	response.Amount = transaction.Amount
+	response.Description = transaction.Description
+	response.TransactionType = transaction.TransactionType
+	response.Channel = transaction.Channel
+	response.ValueDate = formatOptionalDate(transaction.ValueDate)
+	response.CreditAmount = creditAmount
+	response.Allocations = reversed
+
+	return response, nil
+}
+
+// reverseAllocations applies the negated allocations of a payment to the schedule and
+// penalties of its loan. It returns the installments and penalties it changed. An
+// installment left with nothing paid is unpaid again, otherwise it is partial.
+func reverseAllocations(schedule []repository.LoanInstallment, penalties []repository.LoanPenalty, allocations []PaymentAllocation) (installments []repository.LoanInstallment, reversedPenalties []repository.LoanPenalty, err error) {
+	for _, installment := range schedule {
+		principal := allocatedAmount(allocations, installment.InstallmentNumber, AllocationComponentPrincipal)
+		interest := allocatedAmount(allocations, installment.InstallmentNumber, AllocationComponentInterest)
+		if principal.IsZero() && interest.IsZero() {
+			continue
+		}
+
+		if installment.InstallmentStatus == "restructured" {
+			return nil, nil, fmt.Errorf("installment %d was restructured after the payment", installment.InstallmentNumber)
+		}
+
+		installment.PaidPrincipalAmount = installment.PaidPrincipalAmount.Add(principal)
+		installment.PaidInterestAmount = installment.PaidInterestAmount.Add(interest)
+		if installment.PaidPrincipalAmount.IsNegative() || installment.PaidInterestAmount.IsNegative() {
+			return nil, nil, fmt.Errorf("installment %d was paid less than the payment", installment.InstallmentNumber)
+		}
+
+		installment.InstallmentStatus = "partial"
+		if installment.PaidPrincipalAmount.IsZero() && installment.PaidInterestAmount.IsZero() {
+			installment.InstallmentStatus = "unpaid"
+		}
+		installment.PaidAt = time.Time{}
+		installments = append(installments, installment)
+	}
+
+	for _, penalty := range penalties {
+		amount := allocatedAmount(allocations, penalty.InstallmentNumber, AllocationComponentPenalty)
+		if amount.IsZero() {
+			continue
+		}
+
+		penalty.PaidPenaltyAmount = penalty.PaidPenaltyAmount.Add(amount)
+		if penalty.PaidPenaltyAmount.IsNegative() {
+			return nil, nil, fmt.Errorf("penalty of installment %d was paid less than the payment", penalty.InstallmentNumber)
+		}
+		reversedPenalties = append(reversedPenalties, penalty)
+	}
+
+	return installments, reversedPenalties, nil
+}
+
+// restoreInterestRebates adds the interest rebated by an early settlement back to the
+// installments it was rebated off, once the settlement is reversed. It returns the reversed
+// installments along with the other installments that had a rebate, and the interest
+// charged again in total.
+func restoreInterestRebates(schedule []repository.LoanInstallment, reversed []repository.LoanInstallment) (installments []repository.LoanInstallment, rebate money.Money) {
+	for _, installment := range schedule {
+		changed := false
+		for _, reversedInstallment := range reversed {
+			if reversedInstallment.InstallmentNumber == installment.InstallmentNumber {
+				installment = reversedInstallment
+				changed = true
+			}
+		}
+
+		if installment.InterestRebateAmount.IsPositive() {
+			rebate = rebate.Add(installment.InterestRebateAmount)
+			installment.InterestAmount = installment.InterestAmount.Add(installment.InterestRebateAmount)
+			installment.InterestRebateAmount = money.Zero
+
+			installment.InstallmentStatus = "partial"
+			if installment.PaidPrincipalAmount.IsZero() && installment.PaidInterestAmount.IsZero() {
+				installment.InstallmentStatus = "unpaid"
+			}
+			installment.PaidAt = time.Time{}
+			changed = true
+		}
+
+		if changed {
+			installments = append(installments, installment)
+		}
+	}
+
+	return installments, rebate
+}
+
+// reopenedLoanStatus is the status of a loan once a payment is reversed. A loan that was
+// finished or on going is on_going again, or late when an installment is now past due.
+func reopenedLoanStatus(status string, schedule []repository.LoanInstallment, reversed []repository.LoanInstallment, now time.Time) string {
+	if status != LoanStatusFinish && status != LoanStatusOnGoing {
+		return status
+	}
+
+	for _, installment := range append(reversed, schedule...) {
+		if !isInstallmentClosed(installment.InstallmentStatus) && now.After(installment.DueDate) {
+			return LoanStatusLate
+		}
+	}
+
+	return LoanStatusOnGoing
+}
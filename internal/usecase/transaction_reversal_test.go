@@ -0,0 +1,356 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReverseTransaction(t *testing.T) {
+	mockTransactionRepo := new(mocks.TransactionRepository)
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanPenaltyRepo := new(mocks.LoanPenaltyRepository)
+	mockSettlementQuoteRepo := new(mocks.SettlementQuoteRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockReceiptRepo := new(mocks.ReceiptRepository)
+
+	uc := usecase.NewTransactionUsecase(mockTransactionRepo, mockLoanRepo, mockLoanInstallmentRepo, mockLoanPenaltyRepo, mockSettlementQuoteRepo, mockConsumerLimitRepo, mockConsumerRepo, mockLedgerRepo, mockReceiptRepo, config.SettlementConfig{}, config.PaymentConfig{}, time.Second*2)
+
+	req := usecase.ReverseTransactionRequest{TransactionID: 5, ReversedBy: "operator", Reason: "transfer bounced"}
+	payment := repository.Transaction{ID: 5, ConsumerID: 1, LoanID: 1, Amount: money.New(130), TransactionType: "full", Channel: "bank_transfer", ExternalReference: "TRF-001"}
+
+	tests := []struct {
+		name    string
+		req     usecase.ReverseTransactionRequest
+		setup   func()
+		want    usecase.GetTransactionResponse
+		wantErr string
+	}{
+		{
+			name: "transaction not found",
+			req:  req,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(5)).Return(repository.Transaction{}, nil).Once()
+			},
+			wantErr: "transaction not found",
+		},
+		{
+			name: "reversal of a reversal",
+			req:  req,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(5)).Return(repository.Transaction{ID: 5, ReversalOfTransactionID: 4}, nil).Once()
+			},
+			wantErr: "a reversal cannot be reversed",
+		},
+		{
+			name: "transaction already reversed",
+			req:  req,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(5)).Return(repository.Transaction{ID: 5, ReversedAt: time.Now()}, nil).Once()
+			},
+			wantErr: "transaction already reversed",
+		},
+		{
+			name: "transaction without allocations",
+			req:  req,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(5)).Return(payment, nil).Once()
+				mockTransactionRepo.On("GetTransactionAllocationsByTransactionID", mock.Anything, int64(5)).Return([]repository.TransactionAllocation{}, nil).Once()
+			},
+			wantErr: "transaction has no allocations to reverse",
+		},
+		{
+			name: "reversal of the payment finishing a loan reopens it",
+			req:  req,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(5)).Return(payment, nil).Once()
+				mockTransactionRepo.On("GetTransactionAllocationsByTransactionID", mock.Anything, int64(5)).Return([]repository.TransactionAllocation{
+					{ID: 1, TransactionID: 5, InstallmentNumber: 3, Component: "penalty", Amount: money.New(5)},
+					{ID: 2, TransactionID: 5, InstallmentNumber: 3, Component: "interest", Amount: money.New(10)},
+					{ID: 3, TransactionID: 5, InstallmentNumber: 3, Component: "principal", Amount: money.New(100)},
+					{ID: 4, TransactionID: 5, Component: "credit", Amount: money.New(15)},
+				}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:                 1,
+					ConsumerID:         1,
+					ContractNumber:     "123",
+					InterestAmount:     money.New(30),
+					PaidLoanAmount:     money.New(300),
+					PaidInterestAmount: money.New(30),
+					LoanStatus:         "finish",
+				}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid"},
+					{ID: 3, InstallmentNumber: 3, DueDate: time.Now().AddDate(0, 0, -20), PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid", PaidAt: time.Now()},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{
+					{ID: 1, LoanID: 1, InstallmentNumber: 3, PenaltyAmount: money.New(5), PaidPenaltyAmount: money.New(5)},
+				}, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.MatchedBy(func(transaction repository.Transaction) bool {
+					// the reversal goes back through the channel of the payment, under no reference of its own
+					return transaction.ConsumerID == 1 && transaction.LoanID == 1 && transaction.ReversalOfTransactionID == 5 &&
+						transaction.Amount == money.New(-130) && transaction.Description == "Reversal of transaction 5 for loan 123" &&
+						transaction.TransactionType == "reversal" && transaction.Channel == "bank_transfer" && transaction.ExternalReference == "" &&
+						!transaction.ValueDate.IsZero() && transaction.ReversedBy == "operator" && transaction.ReversalReason == "transfer bounced"
+				})).Return(int64(6), nil).Once()
+				mockTransactionRepo.On("MarkTransactionReversed", mock.Anything, mock.Anything, int64(5)).Return(true, nil).Once()
+				mockLoanPenaltyRepo.On("UpsertLoanPenalty", mock.Anything, mock.MatchedBy(func(penalty repository.LoanPenalty) bool {
+					return penalty.InstallmentNumber == 3 && penalty.PaidPenaltyAmount.IsZero()
+				}), mock.Anything).Return(nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, repository.UpdateLoanInstallmentRequest{
+					ID:                3,
+					InterestAmount:    money.New(10),
+					InstallmentStatus: "unpaid",
+				}, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateLoan", mock.Anything, repository.UpdateLoanRequest{
+					ID:                 1,
+					InterestAmount:     money.New(30),
+					PaidLoanAmount:     money.New(200),
+					PaidInterestAmount: money.New(20),
+					LoanStatus:         "late",
+				}, mock.Anything).Return(nil).Once()
+				mockConsumerRepo.On("AddCreditBalance", mock.Anything, int64(1), money.New(-15), mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, []repository.TransactionAllocation{
+					{TransactionID: 6, InstallmentNumber: 3, Component: "penalty", Amount: money.New(-5)},
+					{TransactionID: 6, InstallmentNumber: 3, Component: "interest", Amount: money.New(-10)},
+					{TransactionID: 6, InstallmentNumber: 3, Component: "principal", Amount: money.New(-100)},
+					{TransactionID: 6, Component: "credit", Amount: money.New(-15)},
+				}).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockTransactionRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			want: usecase.GetTransactionResponse{
+				ID:                      6,
+				ConsumerID:              1,
+				LoanID:                  1,
+				ReversalOfTransactionID: 5,
+				Amount:                  money.New(-130),
+				Description:             "Reversal of transaction 5 for loan 123",
+				TransactionType:         "reversal",
+				Channel:                 "bank_transfer",
+				ValueDate:               time.Now().Format("2006-01-02"),
+				CreditAmount:            money.New(-15),
+				Allocations: []usecase.PaymentAllocation{
+					{InstallmentNumber: 3, Component: "penalty", Amount: money.New(-5)},
+					{InstallmentNumber: 3, Component: "interest", Amount: money.New(-10)},
+					{InstallmentNumber: 3, Component: "principal", Amount: money.New(-100)},
+					{Component: "credit", Amount: money.New(-15)},
+				},
+			},
+		},
+		{
+			name: "reversal of a partial payment keeps later payments",
+			req:  req,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(5)).Return(repository.Transaction{ID: 5, ConsumerID: 1, LoanID: 1, Amount: money.New(60)}, nil).Once()
+				mockTransactionRepo.On("GetTransactionAllocationsByTransactionID", mock.Anything, int64(5)).Return([]repository.TransactionAllocation{
+					{ID: 1, TransactionID: 5, InstallmentNumber: 2, Component: "interest", Amount: money.New(10)},
+					{ID: 2, TransactionID: 5, InstallmentNumber: 2, Component: "principal", Amount: money.New(50)},
+				}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:                 1,
+					ConsumerID:         1,
+					ContractNumber:     "123",
+					InterestAmount:     money.New(30),
+					PaidLoanAmount:     money.New(180),
+					PaidInterestAmount: money.New(20),
+					LoanStatus:         "on_going",
+				}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, DueDate: time.Now().AddDate(0, 1, 0), PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(80), PaidInterestAmount: money.New(10), InstallmentStatus: "partial"},
+					{ID: 3, InstallmentNumber: 3, DueDate: time.Now().AddDate(0, 2, 0), PrincipalAmount: money.New(100), InterestAmount: money.New(10), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.MatchedBy(func(transaction repository.Transaction) bool {
+					return transaction.ReversalOfTransactionID == 5 && transaction.Amount == money.New(-60)
+				})).Return(int64(7), nil).Once()
+				mockTransactionRepo.On("MarkTransactionReversed", mock.Anything, mock.Anything, int64(5)).Return(true, nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, repository.UpdateLoanInstallmentRequest{
+					ID:                  2,
+					InterestAmount:      money.New(10),
+					PaidPrincipalAmount: money.New(30),
+					InstallmentStatus:   "partial",
+				}, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateLoan", mock.Anything, repository.UpdateLoanRequest{
+					ID:                 1,
+					InterestAmount:     money.New(30),
+					PaidLoanAmount:     money.New(130),
+					PaidInterestAmount: money.New(10),
+					LoanStatus:         "on_going",
+				}, mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+					return entry.ReferenceType == usecase.JournalReferenceTransaction && entry.ReferenceID == 7 && assert.ObjectsAreEqual([]repository.JournalLine{
+						{AccountCode: usecase.LedgerAccountCash, CreditAmount: money.New(60)},
+						{AccountCode: usecase.LedgerAccountInterestIncome, DebitAmount: money.New(10)},
+						{AccountCode: usecase.LedgerAccountLoanReceivable, DebitAmount: money.New(50)},
+					}, entry.Lines)
+				}), mock.Anything).Return(int64(1), nil).Once()
+				mockTransactionRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			want: usecase.GetTransactionResponse{
+				ID:                      7,
+				ConsumerID:              1,
+				LoanID:                  1,
+				ReversalOfTransactionID: 5,
+				Amount:                  money.New(-60),
+				Description:             "Reversal of transaction 5 for loan 123",
+				TransactionType:         "reversal",
+				ValueDate:               time.Now().Format("2006-01-02"),
+				Allocations: []usecase.PaymentAllocation{
+					{InstallmentNumber: 2, Component: "interest", Amount: money.New(-10)},
+					{InstallmentNumber: 2, Component: "principal", Amount: money.New(-50)},
+				},
+			},
+		},
+		{
+			name: "reversal of an early settlement charges the rebated interest again",
+			req:  req,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(5)).Return(repository.Transaction{ID: 5, ConsumerID: 1, LoanID: 1, Amount: money.New(204), TransactionType: "early_settlement"}, nil).Once()
+				mockTransactionRepo.On("GetTransactionAllocationsByTransactionID", mock.Anything, int64(5)).Return([]repository.TransactionAllocation{
+					{ID: 1, TransactionID: 5, InstallmentNumber: 2, Component: "interest", Amount: money.New(4)},
+					{ID: 2, TransactionID: 5, InstallmentNumber: 2, Component: "principal", Amount: money.New(100)},
+					{ID: 3, TransactionID: 5, InstallmentNumber: 3, Component: "principal", Amount: money.New(100)},
+				}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:                 1,
+					ConsumerID:         1,
+					ContractNumber:     "123",
+					InterestAmount:     money.New(14),
+					PaidLoanAmount:     money.New(300),
+					PaidInterestAmount: money.New(14),
+					LoanStatus:         "finish",
+				}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, DueDate: time.Now().AddDate(0, 0, 10), PrincipalAmount: money.New(100), InterestAmount: money.New(4), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(4), InterestRebateAmount: money.New(6), InstallmentStatus: "paid", PaidAt: time.Now()},
+					{ID: 3, InstallmentNumber: 3, DueDate: time.Now().AddDate(0, 1, 10), PrincipalAmount: money.New(100), PaidPrincipalAmount: money.New(100), InterestRebateAmount: money.New(10), InstallmentStatus: "paid", PaidAt: time.Now()},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.MatchedBy(func(transaction repository.Transaction) bool {
+					return transaction.ReversalOfTransactionID == 5 && transaction.Amount == money.New(-204)
+				})).Return(int64(8), nil).Once()
+				mockTransactionRepo.On("MarkTransactionReversed", mock.Anything, mock.Anything, int64(5)).Return(true, nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, repository.UpdateLoanInstallmentRequest{
+					ID:                2,
+					InterestAmount:    money.New(10),
+					InstallmentStatus: "unpaid",
+				}, mock.Anything).Return(nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, repository.UpdateLoanInstallmentRequest{
+					ID:                3,
+					InterestAmount:    money.New(10),
+					InstallmentStatus: "unpaid",
+				}, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateLoan", mock.Anything, repository.UpdateLoanRequest{
+					ID:                 1,
+					InterestAmount:     money.New(30),
+					PaidLoanAmount:     money.New(100),
+					PaidInterestAmount: money.New(10),
+					LoanStatus:         "on_going",
+				}, mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+					return entry.ReferenceType == usecase.JournalReferenceTransaction && entry.ReferenceID == 8 && assert.ObjectsAreEqual([]repository.JournalLine{
+						{AccountCode: usecase.LedgerAccountCash, CreditAmount: money.New(204)},
+						{AccountCode: usecase.LedgerAccountInterestIncome, DebitAmount: money.New(4)},
+						{AccountCode: usecase.LedgerAccountLoanReceivable, DebitAmount: money.New(200)},
+					}, entry.Lines)
+				}), mock.Anything).Return(int64(1), nil).Once()
+				mockTransactionRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			want: usecase.GetTransactionResponse{
+				ID:                      8,
+				ConsumerID:              1,
+				LoanID:                  1,
+				ReversalOfTransactionID: 5,
+				Amount:                  money.New(-204),
+				Description:             "Reversal of transaction 5 for loan 123",
+				TransactionType:         "reversal",
+				ValueDate:               time.Now().Format("2006-01-02"),
+				Allocations: []usecase.PaymentAllocation{
+					{InstallmentNumber: 2, Component: "interest", Amount: money.New(-4)},
+					{InstallmentNumber: 2, Component: "principal", Amount: money.New(-100)},
+					{InstallmentNumber: 3, Component: "principal", Amount: money.New(-100)},
+				},
+			},
+		},
+		{
+			name: "installment restructured after the payment",
+			req:  req,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(5)).Return(payment, nil).Once()
+				mockTransactionRepo.On("GetTransactionAllocationsByTransactionID", mock.Anything, int64(5)).Return([]repository.TransactionAllocation{
+					{ID: 1, TransactionID: 5, InstallmentNumber: 2, Component: "principal", Amount: money.New(50)},
+				}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerID: 1, LoanStatus: "on_going"}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(100), PaidPrincipalAmount: money.New(50), InstallmentStatus: "restructured"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockTransactionRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantErr: "installment 2 was restructured after the payment",
+		},
+		{
+			name: "transaction reversed meanwhile",
+			req:  req,
+			setup: func() {
+				mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(5)).Return(payment, nil).Once()
+				mockTransactionRepo.On("GetTransactionAllocationsByTransactionID", mock.Anything, int64(5)).Return([]repository.TransactionAllocation{
+					{ID: 1, TransactionID: 5, Component: "credit", Amount: money.New(130)},
+				}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerID: 1, LoanStatus: "finish"}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(int64(8), nil).Once()
+				mockTransactionRepo.On("MarkTransactionReversed", mock.Anything, mock.Anything, int64(5)).Return(false, nil).Once()
+				mockTransactionRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantErr: "transaction already reversed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, err := uc.ReverseTransaction(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	mockTransactionRepo.AssertExpectations(t)
+	mockLoanRepo.AssertExpectations(t)
+	mockLoanInstallmentRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockLoanPenaltyRepo.AssertExpectations(t)
+	mockConsumerRepo.AssertExpectations(t)
+}
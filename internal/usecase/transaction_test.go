@@ -2,12 +2,15 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/config"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/mocks"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/repository"
 	"github.com/AbdulRasyid-Ans/xyz-multifinance/internal/usecase"
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -15,10 +18,16 @@ import (
 func TestGetRemainingPayment(t *testing.T) {
 	mockTransactionRepo := new(mocks.TransactionRepository)
 	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanPenaltyRepo := new(mocks.LoanPenaltyRepository)
+	mockSettlementQuoteRepo := new(mocks.SettlementQuoteRepository)
 	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
 	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockReceiptRepo := new(mocks.ReceiptRepository)
+	settlementConfig := config.SettlementConfig{FeeRate: 1, QuoteValidity: time.Hour}
 
-	uc := usecase.NewTransactionUsecase(mockTransactionRepo, mockLoanRepo, mockConsumerLimitRepo, mockConsumerRepo, time.Second*2)
+	uc := usecase.NewTransactionUsecase(mockTransactionRepo, mockLoanRepo, mockLoanInstallmentRepo, mockLoanPenaltyRepo, mockSettlementQuoteRepo, mockConsumerLimitRepo, mockConsumerRepo, mockLedgerRepo, mockReceiptRepo, settlementConfig, config.PaymentConfig{}, time.Second*2)
 
 	tests := []struct {
 		name    string
@@ -88,6 +97,19 @@ func TestGetRemainingPayment(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "loan not disbursed yet",
+			req: usecase.TransactionRequest{
+				ConsumerID:      1,
+				LoanID:          1,
+				TramsactionType: "installment",
+			},
+			setup: func() {
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerID: 1, LoanStatus: "pending_review"}, nil).Once()
+			},
+			wantErr: true,
+		},
 		{
 			name: "consumer limit not found",
 			req: usecase.TransactionRequest{
@@ -102,6 +124,29 @@ func TestGetRemainingPayment(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "installment already paid",
+			req: usecase.TransactionRequest{
+				ConsumerID:      1,
+				LoanID:          1,
+				TramsactionType: "installment",
+				Installment:     1,
+			},
+			setup: func() {
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerID: 1, ConsumerLimitID: 1, LoanStatus: "on_going"}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 2, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: time.Now().AddDate(0, 1, 0), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockTransactionRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantErr: true,
+		},
 		{
 			name: "successful full payment",
 			req: usecase.TransactionRequest{
@@ -116,23 +161,29 @@ func TestGetRemainingPayment(t *testing.T) {
 					ConsumerID:         1,
 					ConsumerLimitID:    1,
 					ContractNumber:     "123",
-					LoanAmount:         1000,
-					PaidLoanAmount:     500,
-					InterestAmount:     100,
-					PaidInterestAmount: 50,
+					LoanAmount:         money.New(1000),
+					PaidLoanAmount:     money.New(500),
+					InterestAmount:     money.New(100),
+					PaidInterestAmount: money.New(50),
 					DueDate:            time.Now().AddDate(0, 1, 0),
 				}, nil).Once()
 				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 12, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(500), InterestAmount: money.New(50), PaidPrincipalAmount: money.New(500), PaidInterestAmount: money.New(50), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(250), InterestAmount: money.New(25), DueDate: time.Now().AddDate(0, 1, 0), InstallmentStatus: "unpaid"},
+					{ID: 3, InstallmentNumber: 3, PrincipalAmount: money.New(250), InterestAmount: money.New(25), DueDate: time.Now().AddDate(0, 2, 0), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
 			},
 			want: usecase.RemainingPaymentResponse{
 				ContractNumber:          "123",
 				Tenure:                  12,
 				DueDate:                 time.Now().AddDate(0, 1, 0),
-				PaidLoanAmount:          500,
-				PaidInterestAmount:      50,
-				RemainingLoanAmount:     500,
-				RemainingInterestAmount: 50,
-				TotalRemainingAmount:    550,
+				PaidLoanAmount:          money.New(500),
+				PaidInterestAmount:      money.New(50),
+				RemainingLoanAmount:     money.New(500),
+				RemainingInterestAmount: money.New(50),
+				TotalRemainingAmount:    money.New(550),
 			},
 			wantErr: false,
 		},
@@ -150,25 +201,70 @@ func TestGetRemainingPayment(t *testing.T) {
 					ConsumerID:         1,
 					ConsumerLimitID:    1,
 					ContractNumber:     "123",
-					LoanAmount:         1200,
-					PaidLoanAmount:     100,
-					InterestAmount:     120,
-					PaidInterestAmount: 10,
+					LoanAmount:         money.New(1200),
+					PaidLoanAmount:     money.New(100),
+					InterestAmount:     money.New(120),
+					PaidInterestAmount: money.New(10),
 					DueDate:            time.Now().AddDate(0, 1, 0),
 					Installment:        1,
 				}, nil).Once()
 				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 12, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: time.Now().AddDate(0, 1, 0), InstallmentStatus: "unpaid"},
+					{ID: 3, InstallmentNumber: 3, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: time.Now().AddDate(0, 2, 0), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
 			},
 			want: usecase.RemainingPaymentResponse{
 				ContractNumber:          "123",
 				Installment:             2,
 				Tenure:                  12,
 				DueDate:                 time.Now().AddDate(0, 1, 0),
-				PaidLoanAmount:          100,
-				PaidInterestAmount:      10,
-				RemainingLoanAmount:     100,
-				RemainingInterestAmount: 10,
-				TotalRemainingAmount:    110,
+				PaidLoanAmount:          money.New(100),
+				PaidInterestAmount:      money.New(10),
+				RemainingLoanAmount:     money.New(100),
+				RemainingInterestAmount: money.New(10),
+				TotalRemainingAmount:    money.New(110),
+			},
+			wantErr: false,
+		},
+		{
+			name: "successful installment payment with accrued penalties",
+			req: usecase.TransactionRequest{
+				ConsumerID:      1,
+				LoanID:          1,
+				TramsactionType: "installment",
+			},
+			setup: func() {
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:              1,
+					ConsumerID:      1,
+					ConsumerLimitID: 1,
+					ContractNumber:  "123",
+					LoanAmount:      money.New(1200),
+					InterestAmount:  money.New(120),
+				}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 12, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, LoanID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(1000), InterestAmount: money.New(100), DueDate: time.Now().AddDate(0, 0, -5), InstallmentStatus: "unpaid"},
+					{ID: 2, LoanID: 1, InstallmentNumber: 2, PrincipalAmount: money.New(1000), InterestAmount: money.New(100), DueDate: time.Now().AddDate(0, 0, -200), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{
+					{ID: 1, LoanID: 1, InstallmentNumber: 1, OverdueDays: 4, PenaltyAmount: money.New(4)},
+					{ID: 2, LoanID: 1, InstallmentNumber: 2, OverdueDays: 199, PenaltyAmount: money.New(110), PaidPenaltyAmount: money.New(10)},
+				}, nil).Once()
+			},
+			want: usecase.RemainingPaymentResponse{
+				ContractNumber:          "123",
+				Installment:             1,
+				Tenure:                  12,
+				DueDate:                 time.Now().AddDate(0, 0, -5),
+				RemainingLoanAmount:     money.New(1000),
+				RemainingInterestAmount: money.New(100),
+				PenaltyAmount:           money.New(104),
+				TotalRemainingAmount:    money.New(1204),
 			},
 			wantErr: false,
 		},
@@ -190,6 +286,7 @@ func TestGetRemainingPayment(t *testing.T) {
 				assert.Equal(t, tt.want.PaidInterestAmount, got.PaidInterestAmount)
 				assert.Equal(t, tt.want.RemainingLoanAmount, got.RemainingLoanAmount)
 				assert.Equal(t, tt.want.RemainingInterestAmount, got.RemainingInterestAmount)
+				assert.Equal(t, tt.want.PenaltyAmount, got.PenaltyAmount)
 				assert.Equal(t, tt.want.TotalRemainingAmount, got.TotalRemainingAmount)
 			}
 		})
@@ -199,10 +296,16 @@ func TestGetRemainingPayment(t *testing.T) {
 func TestCreateTransaction(t *testing.T) {
 	mockTransactionRepo := new(mocks.TransactionRepository)
 	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanPenaltyRepo := new(mocks.LoanPenaltyRepository)
+	mockSettlementQuoteRepo := new(mocks.SettlementQuoteRepository)
 	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
 	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockReceiptRepo := new(mocks.ReceiptRepository)
+	settlementConfig := config.SettlementConfig{FeeRate: 1, QuoteValidity: time.Hour}
 
-	uc := usecase.NewTransactionUsecase(mockTransactionRepo, mockLoanRepo, mockConsumerLimitRepo, mockConsumerRepo, time.Second*2)
+	uc := usecase.NewTransactionUsecase(mockTransactionRepo, mockLoanRepo, mockLoanInstallmentRepo, mockLoanPenaltyRepo, mockSettlementQuoteRepo, mockConsumerLimitRepo, mockConsumerRepo, mockLedgerRepo, mockReceiptRepo, settlementConfig, config.PaymentConfig{}, time.Second*2)
 
 	tests := []struct {
 		name    string
@@ -229,7 +332,10 @@ func TestCreateTransaction(t *testing.T) {
 				TramsactionType: "installment",
 			},
 			setup: func() {
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
 				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{}, nil).Once()
+				mockTransactionRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
 			},
 			wantErr: true,
 		},
@@ -241,8 +347,9 @@ func TestCreateTransaction(t *testing.T) {
 				TramsactionType: "installment",
 			},
 			setup: func() {
-				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
-				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(false, nil).Once()
+				mockTransactionRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
 			},
 			wantErr: true,
 		},
@@ -254,8 +361,11 @@ func TestCreateTransaction(t *testing.T) {
 				TramsactionType: "installment",
 			},
 			setup: func() {
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
 				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
 				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerID: 2}, nil).Once()
+				mockTransactionRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
 			},
 			wantErr: true,
 		},
@@ -267,8 +377,11 @@ func TestCreateTransaction(t *testing.T) {
 				TramsactionType: "installment",
 			},
 			setup: func() {
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
 				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
 				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerID: 1, LoanStatus: "finish"}, nil).Once()
+				mockTransactionRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
 			},
 			wantErr: true,
 		},
@@ -280,29 +393,41 @@ func TestCreateTransaction(t *testing.T) {
 				TramsactionType: "full",
 			},
 			setup: func() {
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
 				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
 				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
 					ID:                 1,
 					ConsumerID:         1,
 					ConsumerLimitID:    1,
 					ContractNumber:     "123",
-					LoanAmount:         1000,
-					PaidLoanAmount:     500,
-					InterestAmount:     100,
-					PaidInterestAmount: 50,
+					LoanAmount:         money.New(1000),
+					PaidLoanAmount:     money.New(500),
+					InterestAmount:     money.New(100),
+					PaidInterestAmount: money.New(50),
 					DueDate:            time.Now().AddDate(0, 1, 0),
 				}, nil).Once()
 				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 12, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(500), InterestAmount: money.New(50), PaidPrincipalAmount: money.New(500), PaidInterestAmount: money.New(50), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(250), InterestAmount: money.New(25), DueDate: time.Now().AddDate(0, 1, 0), InstallmentStatus: "unpaid"},
+					{ID: 3, InstallmentNumber: 3, PrincipalAmount: money.New(250), InterestAmount: money.New(25), DueDate: time.Now().AddDate(0, 2, 0), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
 				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
 				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(2)
 				mockLoanRepo.On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockReceiptRepo.On("NextReceiptSequence", mock.Anything, time.Now().Format("200601"), mock.Anything).Return(int64(7), nil).Once()
+				mockReceiptRepo.On("CreateReceipt", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
 				mockTransactionRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
 			},
 			want: usecase.GetTransactionResponse{
 				ID:          1,
 				ConsumerID:  1,
 				LoanID:      1,
-				Amount:      550,
+				Amount:      money.New(550),
 				Description: "Payment for loan 123",
 			},
 			wantErr: false,
@@ -315,34 +440,507 @@ func TestCreateTransaction(t *testing.T) {
 				TramsactionType: "installment",
 			},
 			setup: func() {
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
 				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
 				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
 					ID:                 1,
 					ConsumerID:         1,
 					ConsumerLimitID:    1,
 					ContractNumber:     "123",
-					LoanAmount:         1200,
-					PaidLoanAmount:     100,
-					InterestAmount:     120,
-					PaidInterestAmount: 10,
+					LoanAmount:         money.New(1200),
+					PaidLoanAmount:     money.New(100),
+					InterestAmount:     money.New(120),
+					PaidInterestAmount: money.New(10),
 					DueDate:            time.Now().AddDate(0, 1, 0),
 					Installment:        1,
 				}, nil).Once()
 				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 12, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: time.Now().AddDate(0, 1, 0), InstallmentStatus: "unpaid"},
+					{ID: 3, InstallmentNumber: 3, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: time.Now().AddDate(0, 2, 0), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
 				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
 				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(1)
 				mockLoanRepo.On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockReceiptRepo.On("NextReceiptSequence", mock.Anything, time.Now().Format("200601"), mock.Anything).Return(int64(7), nil).Once()
+				mockReceiptRepo.On("CreateReceipt", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
 				mockTransactionRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
 			},
 			want: usecase.GetTransactionResponse{
 				ID:          1,
 				ConsumerID:  1,
 				LoanID:      1,
-				Amount:      110,
+				Amount:      money.New(110),
 				Description: "Payment for loan 123",
 			},
 			wantErr: false,
 		},
+		{
+			name: "receipt number not taken rolls the payment back",
+			req: usecase.TransactionRequest{
+				ConsumerID:      1,
+				LoanID:          1,
+				TramsactionType: "installment",
+			},
+			setup: func() {
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:                 1,
+					ConsumerID:         1,
+					ConsumerLimitID:    1,
+					ContractNumber:     "123",
+					LoanAmount:         money.New(1200),
+					PaidLoanAmount:     money.New(100),
+					InterestAmount:     money.New(120),
+					PaidInterestAmount: money.New(10),
+					DueDate:            time.Now().AddDate(0, 1, 0),
+					Installment:        1,
+				}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 12, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: time.Now().AddDate(0, 1, 0), InstallmentStatus: "unpaid"},
+					{ID: 3, InstallmentNumber: 3, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: time.Now().AddDate(0, 2, 0), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(1)
+				mockLoanRepo.On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockReceiptRepo.On("NextReceiptSequence", mock.Anything, time.Now().Format("200601"), mock.Anything).Return(int64(0), errors.New("lock wait timeout")).Once()
+				mockTransactionRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name: "successful installment payment for loan without persisted schedule",
+			req: usecase.TransactionRequest{
+				ConsumerID:      1,
+				LoanID:          1,
+				TramsactionType: "installment",
+			},
+			setup: func() {
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:                 1,
+					ConsumerID:         1,
+					ConsumerLimitID:    1,
+					ContractNumber:     "123",
+					LoanAmount:         money.New(1200),
+					PaidLoanAmount:     money.New(100),
+					InterestRate:       10,
+					InterestMethod:     "flat",
+					InterestAmount:     money.New(120),
+					PaidInterestAmount: money.New(10),
+					Installment:        1,
+					CreatedAt:          time.Now(),
+				}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 12, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockLoanInstallmentRepo.On("CreateLoanInstallments", mock.Anything, mock.MatchedBy(func(installments []repository.LoanInstallment) bool {
+					return len(installments) == 12 &&
+						installments[0].InstallmentStatus == "paid" &&
+						installments[1].InstallmentStatus == "paid" &&
+						installments[2].InstallmentStatus == "unpaid"
+				}), mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateLoan", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockReceiptRepo.On("NextReceiptSequence", mock.Anything, time.Now().Format("200601"), mock.Anything).Return(int64(7), nil).Once()
+				mockReceiptRepo.On("CreateReceipt", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockTransactionRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			want: usecase.GetTransactionResponse{
+				ID:          1,
+				ConsumerID:  1,
+				LoanID:      1,
+				Amount:      money.New(110),
+				Description: "Payment for loan 123",
+			},
+			wantErr: false,
+		},
+		{
+			name: "successful installment payment collects penalty",
+			req: usecase.TransactionRequest{
+				ConsumerID:      1,
+				LoanID:          1,
+				TramsactionType: "installment",
+			},
+			setup: func() {
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:              1,
+					ConsumerID:      1,
+					ConsumerLimitID: 1,
+					ContractNumber:  "123",
+					LoanAmount:      money.New(2000),
+					InterestAmount:  money.New(200),
+				}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 2, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, LoanID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(1000), InterestAmount: money.New(100), DueDate: time.Now().AddDate(0, 0, -5), InstallmentStatus: "unpaid"},
+					{ID: 2, LoanID: 1, InstallmentNumber: 2, PrincipalAmount: money.New(1000), InterestAmount: money.New(100), DueDate: time.Now().AddDate(0, 1, -5), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{
+					{ID: 1, LoanID: 1, InstallmentNumber: 1, OverdueDays: 5, PenaltyAmount: money.New(6), AccruedUntil: time.Now().AddDate(0, 0, -1)},
+				}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockLoanPenaltyRepo.On("UpsertLoanPenalty", mock.Anything, mock.MatchedBy(func(penalty repository.LoanPenalty) bool {
+					return penalty.InstallmentNumber == 1 && penalty.PenaltyAmount == money.New(6) && penalty.PaidPenaltyAmount == money.New(6)
+				}), mock.Anything).Return(nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan repository.UpdateLoanRequest) bool {
+					return loan.LoanStatus == "late"
+				}), mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockReceiptRepo.On("NextReceiptSequence", mock.Anything, time.Now().Format("200601"), mock.Anything).Return(int64(7), nil).Once()
+				mockReceiptRepo.On("CreateReceipt", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockTransactionRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			want: usecase.GetTransactionResponse{
+				ID:          1,
+				ConsumerID:  1,
+				LoanID:      1,
+				Amount:      money.New(1106),
+				Description: "Payment for loan 123",
+			},
+			wantErr: false,
+		},
+		{
+			name: "partial payment without amount",
+			req: usecase.TransactionRequest{
+				ConsumerID:      1,
+				LoanID:          1,
+				TramsactionType: "partial",
+			},
+			setup:   func() {},
+			wantErr: true,
+		},
+		{
+			name: "amount with installment payment",
+			req: usecase.TransactionRequest{
+				ConsumerID:      1,
+				LoanID:          1,
+				TramsactionType: "installment",
+				Amount:          money.New(50),
+			},
+			setup:   func() {},
+			wantErr: true,
+		},
+		{
+			name: "successful partial payment",
+			req: usecase.TransactionRequest{
+				ConsumerID:        1,
+				LoanID:            1,
+				TramsactionType:   "partial",
+				Amount:            money.New(60),
+				Channel:           "bank_transfer",
+				ExternalReference: "TRF-001",
+				ValueDate:         "2024-01-15",
+			},
+			setup: func() {
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:                 1,
+					ConsumerID:         1,
+					ConsumerLimitID:    1,
+					ContractNumber:     "123",
+					LoanAmount:         money.New(300),
+					PaidLoanAmount:     money.New(100),
+					InterestAmount:     money.New(30),
+					PaidInterestAmount: money.New(10),
+					LoanStatus:         "on_going",
+				}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 3, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: time.Now().AddDate(0, 1, 0), InstallmentStatus: "unpaid"},
+					{ID: 3, InstallmentNumber: 3, PrincipalAmount: money.New(100), InterestAmount: money.New(10), DueDate: time.Now().AddDate(0, 2, 0), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.MatchedBy(func(transaction repository.Transaction) bool {
+					return transaction.Amount == money.New(60) && transaction.TransactionType == "partial" && transaction.Channel == "bank_transfer" &&
+						transaction.ExternalReference == "TRF-001" && transaction.ValueDate.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local))
+				})).Return(int64(1), nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, mock.MatchedBy(func(req repository.UpdateLoanInstallmentRequest) bool {
+					return req.ID == 2 && req.PaidInterestAmount == money.New(10) && req.PaidPrincipalAmount == money.New(50) &&
+						req.InstallmentStatus == "partial" && req.PaidAt == nil
+				}), mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan repository.UpdateLoanRequest) bool {
+					return loan.LoanStatus == "on_going" && loan.PaidLoanAmount == money.New(150) && loan.PaidInterestAmount == money.New(20)
+				}), mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, []repository.TransactionAllocation{
+					{TransactionID: 1, InstallmentNumber: 2, Component: "interest", Amount: money.New(10)},
+					{TransactionID: 1, InstallmentNumber: 2, Component: "principal", Amount: money.New(50)},
+				}).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockReceiptRepo.On("NextReceiptSequence", mock.Anything, time.Now().Format("200601"), mock.Anything).Return(int64(7), nil).Once()
+				mockReceiptRepo.On("CreateReceipt", mock.Anything, mock.MatchedBy(func(receipt repository.Receipt) bool {
+					return receipt.TransactionID == 1 && receipt.LoanID == 1 && receipt.ReceiptNumber == "RCP-"+time.Now().Format("200601")+"-000007" &&
+						receipt.RemainingPrincipalAmount == money.New(150) && receipt.RemainingInterestAmount == money.New(10)
+				}), mock.Anything).Return(int64(1), nil).Once()
+				mockTransactionRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			want: usecase.GetTransactionResponse{
+				ID:                1,
+				ConsumerID:        1,
+				LoanID:            1,
+				Amount:            money.New(60),
+				Description:       "Payment for loan 123",
+				TransactionType:   "partial",
+				Channel:           "bank_transfer",
+				ExternalReference: "TRF-001",
+				ValueDate:         "2024-01-15",
+				Allocations: []usecase.PaymentAllocation{
+					{InstallmentNumber: 2, Component: "interest", Amount: money.New(10)},
+					{InstallmentNumber: 2, Component: "principal", Amount: money.New(50)},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "successful partial payment with overpayment",
+			req: usecase.TransactionRequest{
+				ConsumerID:      1,
+				LoanID:          1,
+				TramsactionType: "partial",
+				Amount:          money.New(200),
+			},
+			setup: func() {
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:                 1,
+					ConsumerID:         1,
+					ConsumerLimitID:    1,
+					ContractNumber:     "123",
+					LoanAmount:         money.New(200),
+					PaidLoanAmount:     money.New(150),
+					InterestAmount:     money.New(20),
+					PaidInterestAmount: money.New(20),
+					LoanStatus:         "late",
+				}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 2, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, LoanID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(10), InstallmentStatus: "paid"},
+					{ID: 2, LoanID: 1, InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(10), PaidPrincipalAmount: money.New(50), PaidInterestAmount: money.New(10), DueDate: time.Now().AddDate(0, 0, -20), InstallmentStatus: "partial"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{
+					{ID: 1, LoanID: 1, InstallmentNumber: 2, OverdueDays: 19, PenaltyAmount: money.New(1), AccruedUntil: time.Now().AddDate(0, 0, -1)},
+				}, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(int64(2), nil).Once()
+				mockLoanPenaltyRepo.On("UpsertLoanPenalty", mock.Anything, mock.MatchedBy(func(penalty repository.LoanPenalty) bool {
+					return penalty.InstallmentNumber == 2 && penalty.PenaltyAmount == money.New(1) && penalty.PaidPenaltyAmount == money.New(1)
+				}), mock.Anything).Return(nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, mock.MatchedBy(func(req repository.UpdateLoanInstallmentRequest) bool {
+					return req.ID == 2 && req.PaidPrincipalAmount == money.New(100) && req.InstallmentStatus == "paid" && req.PaidAt != nil
+				}), mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan repository.UpdateLoanRequest) bool {
+					return loan.LoanStatus == "finish" && loan.PaidLoanAmount == money.New(200)
+				}), mock.Anything).Return(nil).Once()
+				mockConsumerRepo.On("AddCreditBalance", mock.Anything, int64(1), money.New(149), mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.MatchedBy(func(entry repository.JournalEntry) bool {
+					return entry.ReferenceType == usecase.JournalReferenceTransaction && entry.ReferenceID == 2 && assert.ObjectsAreEqual([]repository.JournalLine{
+						{AccountCode: usecase.LedgerAccountCash, DebitAmount: money.New(200)},
+						{AccountCode: usecase.LedgerAccountPenaltyIncome, CreditAmount: money.New(1)},
+						{AccountCode: usecase.LedgerAccountLoanReceivable, CreditAmount: money.New(50)},
+						{AccountCode: usecase.LedgerAccountConsumerCredit, CreditAmount: money.New(149)},
+					}, entry.Lines)
+				}), mock.Anything).Return(int64(1), nil).Once()
+				mockReceiptRepo.On("NextReceiptSequence", mock.Anything, time.Now().Format("200601"), mock.Anything).Return(int64(7), nil).Once()
+				mockReceiptRepo.On("CreateReceipt", mock.Anything, mock.MatchedBy(func(receipt repository.Receipt) bool {
+					return receipt.TransactionID == 2 && receipt.RemainingPrincipalAmount.IsZero() && receipt.RemainingInterestAmount.IsZero()
+				}), mock.Anything).Return(int64(1), nil).Once()
+				mockTransactionRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			want: usecase.GetTransactionResponse{
+				ID:           2,
+				ConsumerID:   1,
+				LoanID:       1,
+				Amount:       money.New(200),
+				Description:  "Payment for loan 123",
+				CreditAmount: money.New(149),
+				Allocations: []usecase.PaymentAllocation{
+					{InstallmentNumber: 2, Component: "penalty", Amount: money.New(1)},
+					{InstallmentNumber: 2, Component: "principal", Amount: money.New(50)},
+					{Component: "credit", Amount: money.New(149)},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "early settlement without quote",
+			req: usecase.TransactionRequest{
+				ConsumerID:      1,
+				LoanID:          1,
+				TramsactionType: "early_settlement",
+			},
+			setup:   func() {},
+			wantErr: true,
+		},
+		{
+			name: "early settlement with expired quote",
+			req: usecase.TransactionRequest{
+				ConsumerID:        1,
+				LoanID:            1,
+				TramsactionType:   "early_settlement",
+				SettlementQuoteID: 1,
+			},
+			setup: func() {
+				mockSettlementQuoteRepo.On("GetSettlementQuoteByID", mock.Anything, int64(1)).Return(repository.SettlementQuote{
+					ID:         1,
+					LoanID:     1,
+					ConsumerID: 1,
+					QuotedAt:   time.Now().Add(-2 * time.Hour),
+					ExpiredAt:  time.Now().Add(-time.Hour),
+				}, nil).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name: "early settlement with quote of another loan",
+			req: usecase.TransactionRequest{
+				ConsumerID:        1,
+				LoanID:            1,
+				TramsactionType:   "early_settlement",
+				SettlementQuoteID: 1,
+			},
+			setup: func() {
+				mockSettlementQuoteRepo.On("GetSettlementQuoteByID", mock.Anything, int64(1)).Return(repository.SettlementQuote{
+					ID:         1,
+					LoanID:     2,
+					ConsumerID: 1,
+					ExpiredAt:  time.Now().Add(time.Hour),
+				}, nil).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name: "successful early settlement",
+			req: usecase.TransactionRequest{
+				ConsumerID:        1,
+				LoanID:            1,
+				TramsactionType:   "early_settlement",
+				SettlementQuoteID: 1,
+			},
+			setup: func() {
+				quotedAt := time.Date(2024, 3, 11, 10, 0, 0, 0, time.UTC)
+				mockSettlementQuoteRepo.On("GetSettlementQuoteByID", mock.Anything, int64(1)).Return(repository.SettlementQuote{
+					ID:          1,
+					LoanID:      1,
+					ConsumerID:  1,
+					TotalAmount: money.New(221),
+					QuotedAt:    quotedAt,
+					ExpiredAt:   time.Now().Add(time.Hour),
+				}, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:                 1,
+					ConsumerID:         1,
+					ConsumerLimitID:    1,
+					ContractNumber:     "123",
+					LoanAmount:         money.New(300),
+					PaidLoanAmount:     money.New(100),
+					InterestAmount:     money.New(87),
+					PaidInterestAmount: money.New(29),
+					LoanStatus:         "on_going",
+				}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 3, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(29), PaidPrincipalAmount: money.New(100), PaidInterestAmount: money.New(29), DueDate: quotedAt.AddDate(0, 0, -19), InstallmentStatus: "paid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(29), DueDate: time.Date(2024, 3, 21, 0, 0, 0, 0, time.UTC), InstallmentStatus: "unpaid"},
+					{ID: 3, InstallmentNumber: 3, PrincipalAmount: money.New(100), InterestAmount: money.New(29), DueDate: time.Date(2024, 4, 21, 0, 0, 0, 0, time.UTC), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockSettlementQuoteRepo.On("UseSettlementQuote", mock.Anything, int64(1), int64(1), mock.Anything).Return(true, nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, mock.MatchedBy(func(req repository.UpdateLoanInstallmentRequest) bool {
+					// the interest not earned is rebated off the installment
+					return req.ID == 2 && req.InterestAmount == money.New(19) && req.PaidInterestAmount == money.New(19) &&
+						req.InterestRebateAmount == money.New(10) && req.InstallmentStatus == "paid"
+				}), mock.Anything).Return(nil).Once()
+				mockLoanInstallmentRepo.On("UpdateLoanInstallment", mock.Anything, mock.MatchedBy(func(req repository.UpdateLoanInstallmentRequest) bool {
+					return req.ID == 3 && req.InterestAmount.IsZero() && req.PaidInterestAmount.IsZero() &&
+						req.InterestRebateAmount == money.New(29) && req.InstallmentStatus == "paid"
+				}), mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan repository.UpdateLoanRequest) bool {
+					// nothing is left outstanding on the loan once the rebate is taken off its interest
+					return loan.LoanStatus == "finish" && loan.InterestAmount == money.New(48) &&
+						loan.PaidLoanAmount == money.New(300) && loan.PaidInterestAmount == money.New(48)
+				}), mock.Anything).Return(nil).Once()
+				mockTransactionRepo.On("CreateTransactionAllocations", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockLedgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockReceiptRepo.On("NextReceiptSequence", mock.Anything, time.Now().Format("200601"), mock.Anything).Return(int64(7), nil).Once()
+				mockReceiptRepo.On("CreateReceipt", mock.Anything, mock.MatchedBy(func(receipt repository.Receipt) bool {
+					// the rebated interest is not owed anymore
+					return receipt.RemainingPrincipalAmount.IsZero() && receipt.RemainingInterestAmount.IsZero()
+				}), mock.Anything).Return(int64(1), nil).Once()
+				mockTransactionRepo.On("CommitTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			want: usecase.GetTransactionResponse{
+				ID:          1,
+				ConsumerID:  1,
+				LoanID:      1,
+				Amount:      money.New(221),
+				Description: "Payment for loan 123",
+			},
+			wantErr: false,
+		},
+		{
+			name: "early settlement with quote used meanwhile",
+			req: usecase.TransactionRequest{
+				ConsumerID:        1,
+				LoanID:            1,
+				TramsactionType:   "early_settlement",
+				SettlementQuoteID: 1,
+			},
+			setup: func() {
+				quotedAt := time.Date(2024, 3, 11, 10, 0, 0, 0, time.UTC)
+				mockSettlementQuoteRepo.On("GetSettlementQuoteByID", mock.Anything, int64(1)).Return(repository.SettlementQuote{
+					ID:          1,
+					LoanID:      1,
+					ConsumerID:  1,
+					TotalAmount: money.New(202),
+					QuotedAt:    quotedAt,
+					ExpiredAt:   time.Now().Add(time.Hour),
+				}, nil).Once()
+				mockLoanRepo.On("LockLoanByID", mock.Anything, int64(1), mock.Anything).Return(true, nil).Once()
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{ID: 1, ConsumerID: 1, ConsumerLimitID: 1, LoanStatus: "on_going"}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 2, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(100), InterestAmount: money.New(29), DueDate: time.Date(2024, 4, 11, 0, 0, 0, 0, time.UTC), InstallmentStatus: "unpaid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(100), InterestAmount: money.New(29), DueDate: time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockTransactionRepo.On("BeginTx", mock.Anything).Return(nil, nil).Once()
+				mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(int64(2), nil).Once()
+				mockSettlementQuoteRepo.On("UseSettlementQuote", mock.Anything, int64(1), int64(2), mock.Anything).Return(false, nil).Once()
+				mockTransactionRepo.On("RollbackTx", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -358,6 +956,95 @@ func TestCreateTransaction(t *testing.T) {
 				assert.Equal(t, tt.want.LoanID, got.LoanID)
 				assert.Equal(t, tt.want.Amount, got.Amount)
 				assert.Equal(t, tt.want.Description, got.Description)
+				assert.Equal(t, tt.want.CreditAmount, got.CreditAmount)
+				assert.Equal(t, "RCP-"+time.Now().Format("200601")+"-000007", got.ReceiptNumber)
+				if tt.want.Allocations != nil {
+					assert.Equal(t, tt.want.Allocations, got.Allocations)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateSettlementQuote(t *testing.T) {
+	mockTransactionRepo := new(mocks.TransactionRepository)
+	mockLoanRepo := new(mocks.LoanRepository)
+	mockLoanInstallmentRepo := new(mocks.LoanInstallmentRepository)
+	mockLoanPenaltyRepo := new(mocks.LoanPenaltyRepository)
+	mockSettlementQuoteRepo := new(mocks.SettlementQuoteRepository)
+	mockConsumerLimitRepo := new(mocks.ConsumerLimitRepository)
+	mockConsumerRepo := new(mocks.ConsumerRepository)
+	mockLedgerRepo := new(mocks.LedgerRepository)
+	mockReceiptRepo := new(mocks.ReceiptRepository)
+	settlementConfig := config.SettlementConfig{FeeRate: 1, QuoteValidity: time.Hour}
+
+	uc := usecase.NewTransactionUsecase(mockTransactionRepo, mockLoanRepo, mockLoanInstallmentRepo, mockLoanPenaltyRepo, mockSettlementQuoteRepo, mockConsumerLimitRepo, mockConsumerRepo, mockLedgerRepo, mockReceiptRepo, settlementConfig, config.PaymentConfig{}, time.Second*2)
+
+	tests := []struct {
+		name    string
+		req     usecase.SettlementQuoteRequest
+		setup   func()
+		want    usecase.SettlementQuoteResponse
+		wantErr bool
+	}{
+		{
+			name: "consumer not found",
+			req:  usecase.SettlementQuoteRequest{ConsumerID: 1, LoanID: 1},
+			setup: func() {
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{}, nil).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name: "success rebates interest not yet earned",
+			req:  usecase.SettlementQuoteRequest{ConsumerID: 1, LoanID: 1},
+			setup: func() {
+				mockConsumerRepo.On("GetConsumerByID", mock.Anything, int64(1)).Return(repository.Consumer{ID: 1}, nil).Once()
+				mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1)).Return(repository.Loan{
+					ID:              1,
+					ConsumerID:      1,
+					ConsumerLimitID: 1,
+					ContractNumber:  "123",
+					LoanStatus:      "disbursed",
+				}, nil).Once()
+				mockConsumerLimitRepo.On("GetConsumerLimitByID", mock.Anything, int64(1)).Return(repository.ConsumerLimit{ID: 1, Tenure: 2, ConsumerID: 1}, nil).Once()
+				mockLoanInstallmentRepo.On("GetLoanInstallmentsByLoanID", mock.Anything, int64(1)).Return([]repository.LoanInstallment{
+					{ID: 1, InstallmentNumber: 1, PrincipalAmount: money.New(250), InterestAmount: money.New(25), DueDate: time.Now().AddDate(0, 1, 0), InstallmentStatus: "unpaid"},
+					{ID: 2, InstallmentNumber: 2, PrincipalAmount: money.New(250), InterestAmount: money.New(25), DueDate: time.Now().AddDate(0, 2, 0), InstallmentStatus: "unpaid"},
+				}, nil).Once()
+				mockLoanPenaltyRepo.On("GetLoanPenaltiesByLoanID", mock.Anything, int64(1)).Return([]repository.LoanPenalty{}, nil).Once()
+				mockSettlementQuoteRepo.On("CreateSettlementQuote", mock.Anything, mock.MatchedBy(func(quote repository.SettlementQuote) bool {
+					return quote.TotalAmount == money.New(505) && quote.ExpiredAt.Sub(quote.QuotedAt) == time.Hour
+				})).Return(int64(1), nil).Once()
+			},
+			want: usecase.SettlementQuoteResponse{
+				ID:                       1,
+				LoanID:                   1,
+				ContractNumber:           "123",
+				RemainingPrincipalAmount: money.New(500),
+				InterestRebateAmount:     money.New(50),
+				SettlementFeeAmount:      money.New(5),
+				TotalAmount:              money.New(505),
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got, err := uc.CreateSettlementQuote(context.Background(), tt.req)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want.ID, got.ID)
+				assert.Equal(t, tt.want.ContractNumber, got.ContractNumber)
+				assert.Equal(t, tt.want.RemainingPrincipalAmount, got.RemainingPrincipalAmount)
+				assert.Equal(t, tt.want.EarnedInterestAmount, got.EarnedInterestAmount)
+				assert.Equal(t, tt.want.InterestRebateAmount, got.InterestRebateAmount)
+				assert.Equal(t, tt.want.SettlementFeeAmount, got.SettlementFeeAmount)
+				assert.Equal(t, tt.want.TotalAmount, got.TotalAmount)
 			}
 		})
 	}
@@ -0,0 +1,18 @@
+-- Table loan_installments
+CREATE TABLE IF NOT EXISTS `loan_installments`(
+    `loan_installment_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `loan_id` BIGINT UNSIGNED NOT NULL,
+    `installment_number` INT NOT NULL,
+    `due_date` DATE NOT NULL,
+    `principal_amount` DECIMAL(19, 3) NOT NULL,
+    `interest_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `paid_principal_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `paid_interest_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `installment_status` ENUM('unpaid', 'partial', 'paid') NOT NULL DEFAULT 'unpaid',
+    `paid_at` TIMESTAMP NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_at` TIMESTAMP NULL,
+    UNIQUE (`loan_id`, `installment_number`),
+    FOREIGN KEY (`loan_id`) REFERENCES `loans`(`loan_id`)
+);
@@ -0,0 +1,3 @@
+-- Add interest_method to table loans
+ALTER TABLE `loans`
+    ADD COLUMN `interest_method` ENUM('flat', 'flat_monthly', 'annuity', 'declining_balance') NOT NULL DEFAULT 'flat' AFTER `interest_rate`;
@@ -0,0 +1,15 @@
+-- Table loan_penalties
+CREATE TABLE IF NOT EXISTS `loan_penalties`(
+    `loan_penalty_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `loan_id` BIGINT UNSIGNED NOT NULL,
+    `installment_number` INT NOT NULL,
+    `overdue_days` INT NOT NULL DEFAULT 0,
+    `penalty_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `paid_penalty_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `accrued_until` DATE NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_at` TIMESTAMP NULL,
+    UNIQUE (`loan_id`, `installment_number`),
+    FOREIGN KEY (`loan_id`) REFERENCES `loans`(`loan_id`)
+);
@@ -0,0 +1,3 @@
+-- Add days_past_due to table loans
+ALTER TABLE `loans`
+    ADD COLUMN `days_past_due` INT NOT NULL DEFAULT 0 AFTER `installment`;
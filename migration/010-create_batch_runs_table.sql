@@ -0,0 +1,15 @@
+-- Table batch_runs
+CREATE TABLE IF NOT EXISTS `batch_runs`(
+    `batch_run_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `job_name` VARCHAR(100) NOT NULL,
+    `run_date` DATE NOT NULL,
+    `batch_status` ENUM('running', 'success', 'failed') NOT NULL DEFAULT 'running',
+    `total_loans` INT NOT NULL DEFAULT 0,
+    `updated_loans` INT NOT NULL DEFAULT 0,
+    `error_message` TEXT NULL,
+    `started_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `finished_at` TIMESTAMP NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_at` TIMESTAMP NULL
+);
@@ -0,0 +1,15 @@
+-- Table batch_run_items
+CREATE TABLE IF NOT EXISTS `batch_run_items`(
+    `batch_run_item_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `batch_run_id` BIGINT UNSIGNED NOT NULL,
+    `loan_id` BIGINT UNSIGNED NOT NULL,
+    `previous_loan_status` ENUM('on_going', 'finish', 'late') NOT NULL,
+    `loan_status` ENUM('on_going', 'finish', 'late') NOT NULL,
+    `previous_days_past_due` INT NOT NULL DEFAULT 0,
+    `days_past_due` INT NOT NULL DEFAULT 0,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_at` TIMESTAMP NULL,
+    FOREIGN KEY (`batch_run_id`) REFERENCES `batch_runs`(`batch_run_id`),
+    FOREIGN KEY (`loan_id`) REFERENCES `loans`(`loan_id`)
+);
@@ -0,0 +1,9 @@
+-- Add loan lifecycle statuses to table loans
+ALTER TABLE `loans`
+    MODIFY COLUMN `loan_status` ENUM('pending_review', 'approved', 'rejected', 'disbursed', 'cancelled', 'on_going', 'finish', 'late') NOT NULL DEFAULT 'pending_review',
+    ADD COLUMN `status_reason` VARCHAR(255) NULL AFTER `loan_status`,
+    ADD COLUMN `disbursed_at` TIMESTAMP NULL AFTER `status_reason`;
+
+ALTER TABLE `batch_run_items`
+    MODIFY COLUMN `previous_loan_status` ENUM('pending_review', 'approved', 'rejected', 'disbursed', 'cancelled', 'on_going', 'finish', 'late') NOT NULL,
+    MODIFY COLUMN `loan_status` ENUM('pending_review', 'approved', 'rejected', 'disbursed', 'cancelled', 'on_going', 'finish', 'late') NOT NULL;
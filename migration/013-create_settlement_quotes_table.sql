@@ -0,0 +1,22 @@
+-- Table settlement_quotes
+CREATE TABLE IF NOT EXISTS `settlement_quotes`(
+    `settlement_quote_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `loan_id` BIGINT UNSIGNED NOT NULL,
+    `consumer_id` BIGINT UNSIGNED NOT NULL,
+    `remaining_principal_amount` DECIMAL(19, 3) NOT NULL,
+    `earned_interest_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `interest_rebate_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `penalty_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `settlement_fee_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `total_amount` DECIMAL(19, 3) NOT NULL,
+    `quoted_at` TIMESTAMP NOT NULL,
+    `expired_at` TIMESTAMP NOT NULL,
+    `transaction_id` BIGINT UNSIGNED NULL,
+    `used_at` TIMESTAMP NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_at` TIMESTAMP NULL,
+    FOREIGN KEY (`loan_id`) REFERENCES `loans`(`loan_id`),
+    FOREIGN KEY (`consumer_id`) REFERENCES `consumers`(`consumer_id`),
+    FOREIGN KEY (`transaction_id`) REFERENCES `transactions`(`transaction_id`)
+);
@@ -0,0 +1,28 @@
+-- Table loan_restructures
+CREATE TABLE IF NOT EXISTS `loan_restructures`(
+    `loan_restructure_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `loan_id` BIGINT UNSIGNED NOT NULL,
+    `contract_number` VARCHAR(255) NOT NULL,
+    `restructure_type` ENUM('extend_tenure', 'reduce_installment', 'capitalize_arrears') NOT NULL,
+    `previous_consumer_limit_id` BIGINT UNSIGNED NOT NULL,
+    `consumer_limit_id` BIGINT UNSIGNED NOT NULL,
+    `previous_tenure` SMALLINT NOT NULL,
+    `tenure` SMALLINT NOT NULL,
+    `previous_installment_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `installment_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `restructured_principal_amount` DECIMAL(19, 3) NOT NULL,
+    `capitalized_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `previous_loan_amount` DECIMAL(19, 3) NOT NULL,
+    `loan_amount` DECIMAL(19, 3) NOT NULL,
+    `previous_interest_amount` DECIMAL(19, 3) NOT NULL,
+    `interest_amount` DECIMAL(19, 3) NOT NULL,
+    `previous_due_date` DATE NOT NULL,
+    `due_date` DATE NOT NULL,
+    `reason` VARCHAR(255) NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_at` TIMESTAMP NULL,
+    FOREIGN KEY (`loan_id`) REFERENCES `loans`(`loan_id`),
+    FOREIGN KEY (`previous_consumer_limit_id`) REFERENCES `consumer_limits`(`consumer_limit_id`),
+    FOREIGN KEY (`consumer_limit_id`) REFERENCES `consumer_limits`(`consumer_limit_id`)
+);
@@ -0,0 +1,5 @@
+-- Add restructured installments to table loan_installments
+ALTER TABLE `loan_installments`
+    MODIFY COLUMN `installment_status` ENUM('unpaid', 'partial', 'paid', 'restructured') NOT NULL DEFAULT 'unpaid',
+    ADD COLUMN `loan_restructure_id` BIGINT UNSIGNED NULL AFTER `loan_id`,
+    ADD FOREIGN KEY (`loan_restructure_id`) REFERENCES `loan_restructures`(`loan_restructure_id`);
@@ -0,0 +1,3 @@
+-- Add credit_balance to table consumers
+ALTER TABLE `consumers`
+    ADD COLUMN `credit_balance` DECIMAL(19, 3) NOT NULL DEFAULT 0 AFTER `salary`;
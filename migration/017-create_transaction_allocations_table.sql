@@ -0,0 +1,12 @@
+-- Table transaction_allocations
+CREATE TABLE IF NOT EXISTS `transaction_allocations`(
+    `transaction_allocation_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `transaction_id` BIGINT UNSIGNED NOT NULL,
+    `installment_number` INT NULL,
+    `component` ENUM('penalty', 'interest', 'principal', 'settlement_fee', 'credit') NOT NULL,
+    `amount` DECIMAL(19, 3) NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_at` TIMESTAMP NULL,
+    FOREIGN KEY (`transaction_id`) REFERENCES `transactions`(`transaction_id`)
+);
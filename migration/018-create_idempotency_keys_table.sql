@@ -0,0 +1,13 @@
+-- Table idempotency_keys
+CREATE TABLE IF NOT EXISTS `idempotency_keys`(
+    `idempotency_key_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `idempotency_key` VARCHAR(255) NOT NULL,
+    `request_method` VARCHAR(10) NOT NULL,
+    `request_path` VARCHAR(255) NOT NULL,
+    `request_hash` CHAR(64) NOT NULL,
+    `response_status` INT NULL,
+    `response_body` MEDIUMTEXT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE (`request_method`, `request_path`, `idempotency_key`)
+);
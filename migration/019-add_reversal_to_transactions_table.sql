@@ -0,0 +1,8 @@
+-- Add reversal to table transactions
+ALTER TABLE `transactions`
+    ADD COLUMN `reversal_of_transaction_id` BIGINT UNSIGNED NULL AFTER `loan_id`,
+    ADD COLUMN `reversed_by` VARCHAR(255) NULL AFTER `amount`,
+    ADD COLUMN `reversal_reason` VARCHAR(255) NULL AFTER `reversed_by`,
+    ADD COLUMN `reversed_at` TIMESTAMP NULL AFTER `reversal_reason`,
+    ADD UNIQUE (`reversal_of_transaction_id`),
+    ADD FOREIGN KEY (`reversal_of_transaction_id`) REFERENCES `transactions`(`transaction_id`);
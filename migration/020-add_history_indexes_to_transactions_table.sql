@@ -0,0 +1,5 @@
+-- Add transaction history indexes to table transactions
+ALTER TABLE `transactions`
+    ADD INDEX (`consumer_id`, `created_at`),
+    ADD INDEX (`loan_id`, `created_at`),
+    ADD INDEX (`created_at`);
@@ -0,0 +1,17 @@
+-- Table virtual_accounts
+CREATE TABLE IF NOT EXISTS `virtual_accounts`(
+    `virtual_account_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `loan_id` BIGINT UNSIGNED NOT NULL,
+    `consumer_id` BIGINT UNSIGNED NOT NULL,
+    `provider` VARCHAR(50) NOT NULL,
+    `bank_code` VARCHAR(20) NOT NULL,
+    `account_number` VARCHAR(50) NOT NULL,
+    `account_name` VARCHAR(255) NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_at` TIMESTAMP NULL,
+    UNIQUE (`provider`, `loan_id`),
+    UNIQUE (`provider`, `account_number`),
+    FOREIGN KEY (`loan_id`) REFERENCES `loans`(`loan_id`),
+    FOREIGN KEY (`consumer_id`) REFERENCES `consumers`(`consumer_id`)
+);
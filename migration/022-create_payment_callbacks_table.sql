@@ -0,0 +1,18 @@
+-- Table payment_callbacks
+CREATE TABLE IF NOT EXISTS `payment_callbacks`(
+    `payment_callback_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `provider` VARCHAR(50) NOT NULL,
+    `external_id` VARCHAR(255) NOT NULL,
+    `virtual_account_id` BIGINT UNSIGNED NOT NULL,
+    `amount` DECIMAL(19, 3) NOT NULL,
+    `paid_at` TIMESTAMP NOT NULL,
+    `payload` TEXT NOT NULL,
+    `callback_status` ENUM('received', 'processed', 'failed') NOT NULL DEFAULT 'received',
+    `transaction_id` BIGINT UNSIGNED NULL,
+    `failure_reason` VARCHAR(255) NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE (`provider`, `external_id`),
+    FOREIGN KEY (`virtual_account_id`) REFERENCES `virtual_accounts`(`virtual_account_id`),
+    FOREIGN KEY (`transaction_id`) REFERENCES `transactions`(`transaction_id`)
+);
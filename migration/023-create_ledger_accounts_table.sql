@@ -0,0 +1,20 @@
+-- Table ledger_accounts
+CREATE TABLE IF NOT EXISTS `ledger_accounts`(
+    `ledger_account_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `account_code` VARCHAR(20) NOT NULL UNIQUE,
+    `account_name` VARCHAR(255) NOT NULL,
+    `account_type` ENUM('asset', 'liability', 'equity', 'income', 'expense') NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_at` TIMESTAMP NULL
+);
+
+-- Chart of accounts
+INSERT INTO `ledger_accounts` (`account_code`, `account_name`, `account_type`) VALUES
+    ('1100', 'Cash', 'asset'),
+    ('1200', 'Loan Receivable', 'asset'),
+    ('2100', 'Merchant Payable', 'liability'),
+    ('2200', 'Consumer Credit Balance', 'liability'),
+    ('4100', 'Interest Income', 'income'),
+    ('4200', 'Penalty Income', 'income'),
+    ('4300', 'Settlement Fee Income', 'income');
@@ -0,0 +1,12 @@
+-- Table journal_entries
+CREATE TABLE IF NOT EXISTS `journal_entries`(
+    `journal_entry_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `reference_type` ENUM('loan', 'loan_disbursement', 'loan_release', 'loan_restructure', 'transaction') NOT NULL,
+    `reference_id` BIGINT UNSIGNED NOT NULL,
+    `description` VARCHAR(255) NOT NULL,
+    `posted_at` TIMESTAMP NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE (`reference_type`, `reference_id`),
+    INDEX (`posted_at`)
+);
@@ -0,0 +1,12 @@
+-- Table journal_lines
+CREATE TABLE IF NOT EXISTS `journal_lines`(
+    `journal_line_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `journal_entry_id` BIGINT UNSIGNED NOT NULL,
+    `account_code` VARCHAR(20) NOT NULL,
+    `debit_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `credit_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (`journal_entry_id`) REFERENCES `journal_entries`(`journal_entry_id`),
+    FOREIGN KEY (`account_code`) REFERENCES `ledger_accounts`(`account_code`)
+);
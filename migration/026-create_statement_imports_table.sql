@@ -0,0 +1,8 @@
+-- Table statement_imports
+CREATE TABLE IF NOT EXISTS `statement_imports`(
+    `statement_import_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `file_name` VARCHAR(255) NOT NULL,
+    `statement_format` ENUM('csv', 'mt940') NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
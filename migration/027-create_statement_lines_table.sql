@@ -0,0 +1,25 @@
+-- Table statement_lines
+CREATE TABLE IF NOT EXISTS `statement_lines`(
+    `statement_line_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `statement_import_id` BIGINT UNSIGNED NOT NULL,
+    `line_number` INT NOT NULL,
+    `value_date` DATE NOT NULL,
+    `amount` DECIMAL(19, 3) NOT NULL,
+    `description` TEXT NOT NULL,
+    `reference` VARCHAR(255) NOT NULL DEFAULT '',
+    `fingerprint` CHAR(64) NOT NULL UNIQUE,
+    `line_status` ENUM('pending', 'matched', 'unmatched', 'ambiguous', 'ignored') NOT NULL DEFAULT 'pending',
+    `match_method` ENUM('virtual_account', 'contract_number', 'amount', 'manual') NULL,
+    `loan_id` BIGINT UNSIGNED NULL,
+    `transaction_id` BIGINT UNSIGNED NULL UNIQUE,
+    `candidate_loan_ids` VARCHAR(255) NULL,
+    `note` VARCHAR(255) NULL,
+    `matched_by` VARCHAR(255) NULL,
+    `matched_at` TIMESTAMP NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX (`line_status`, `statement_line_id`),
+    FOREIGN KEY (`statement_import_id`) REFERENCES `statement_imports`(`statement_import_id`),
+    FOREIGN KEY (`loan_id`) REFERENCES `loans`(`loan_id`),
+    FOREIGN KEY (`transaction_id`) REFERENCES `transactions`(`transaction_id`)
+);
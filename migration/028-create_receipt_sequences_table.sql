@@ -0,0 +1,7 @@
+-- Table receipt_sequences
+CREATE TABLE IF NOT EXISTS `receipt_sequences`(
+    `period` CHAR(6) NOT NULL PRIMARY KEY,
+    `last_number` INT UNSIGNED NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
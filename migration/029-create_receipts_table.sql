@@ -0,0 +1,13 @@
+-- Table receipts
+CREATE TABLE IF NOT EXISTS `receipts`(
+    `receipt_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `receipt_number` VARCHAR(32) NOT NULL UNIQUE,
+    `transaction_id` BIGINT UNSIGNED NOT NULL UNIQUE,
+    `loan_id` BIGINT UNSIGNED NOT NULL,
+    `remaining_principal_amount` DECIMAL(19, 3) NOT NULL,
+    `remaining_interest_amount` DECIMAL(19, 3) NOT NULL,
+    `issued_at` TIMESTAMP NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (`transaction_id`) REFERENCES `transactions`(`transaction_id`),
+    FOREIGN KEY (`loan_id`) REFERENCES `loans`(`loan_id`)
+);
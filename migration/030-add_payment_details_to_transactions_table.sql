@@ -0,0 +1,12 @@
+-- Add payment details to table transactions
+ALTER TABLE `transactions`
+    ADD COLUMN `transaction_type` ENUM('installment', 'full', 'partial', 'early_settlement', 'reversal') NULL AFTER `description`,
+    ADD COLUMN `channel` ENUM('counter', 'bank_transfer', 'virtual_account') NULL AFTER `transaction_type`,
+    ADD COLUMN `external_reference` VARCHAR(255) NULL AFTER `channel`,
+    ADD COLUMN `value_date` DATE NULL AFTER `external_reference`,
+    ADD UNIQUE (`channel`, `external_reference`),
+    ADD INDEX (`transaction_type`, `created_at`);
+
+-- the type of payments booked before is unknown, reversals are told by the transaction they reverse
+UPDATE `transactions` SET `transaction_type` = 'reversal' WHERE `reversal_of_transaction_id` IS NOT NULL;
+UPDATE `transactions` SET `value_date` = DATE(`created_at`);
@@ -0,0 +1,15 @@
+-- Table loan_products
+CREATE TABLE IF NOT EXISTS `loan_products`(
+    `loan_product_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `product_code` VARCHAR(50) NOT NULL UNIQUE,
+    `product_name` VARCHAR(255) NOT NULL,
+    `interest_method` ENUM('flat', 'flat_monthly', 'annuity', 'declining_balance') NOT NULL DEFAULT 'flat',
+    `admin_fee_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `min_loan_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `max_loan_amount` DECIMAL(19, 3) NULL,
+    `valid_from` DATE NOT NULL,
+    `valid_until` DATE NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_at` TIMESTAMP NULL
+);
@@ -0,0 +1,10 @@
+-- Table loan_product_tenures
+CREATE TABLE IF NOT EXISTS `loan_product_tenures`(
+    `loan_product_tenure_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `loan_product_id` BIGINT UNSIGNED NOT NULL,
+    `tenure` SMALLINT UNSIGNED NOT NULL,
+    `interest_rate` DECIMAL(5, 2) NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (`loan_product_id`, `tenure`),
+    FOREIGN KEY (`loan_product_id`) REFERENCES `loan_products`(`loan_product_id`)
+);
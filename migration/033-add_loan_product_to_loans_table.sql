@@ -0,0 +1,5 @@
+-- Add loan product to table loans
+ALTER TABLE `loans`
+    ADD COLUMN `loan_product_id` BIGINT UNSIGNED NULL AFTER `merchant_id`,
+    ADD COLUMN `admin_fee_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0 AFTER `paid_interest_amount`,
+    ADD FOREIGN KEY (`loan_product_id`) REFERENCES `loan_products`(`loan_product_id`);
@@ -0,0 +1,8 @@
+-- Tenures of table consumer_limits are the ones offered by the loan products. The ENUM is
+-- turned into a string first, converting it to a number directly would keep the index of
+-- the value instead of the value ('6' would become 4).
+ALTER TABLE `consumer_limits`
+    MODIFY COLUMN `tenure` VARCHAR(2) NOT NULL;
+
+ALTER TABLE `consumer_limits`
+    MODIFY COLUMN `tenure` SMALLINT UNSIGNED NOT NULL;
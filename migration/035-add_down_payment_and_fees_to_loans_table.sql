@@ -0,0 +1,9 @@
+-- Add asset price, down payment and fees to table loans
+ALTER TABLE `loans`
+    ADD COLUMN `asset_price` DECIMAL(19, 3) NOT NULL DEFAULT 0 AFTER `loan_product_id`,
+    ADD COLUMN `down_payment_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0 AFTER `asset_price`,
+    ADD COLUMN `insurance_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0 AFTER `admin_fee_amount`,
+    ADD COLUMN `fee_payment` ENUM('upfront', 'financed') NOT NULL DEFAULT 'upfront' AFTER `insurance_amount`;
+
+-- Loans booked before had no down payment, the asset was financed entirely
+UPDATE `loans` SET `asset_price` = `loan_amount`;
@@ -0,0 +1,3 @@
+-- Add credit insurance premium rate to table loan_products
+ALTER TABLE `loan_products`
+    ADD COLUMN `insurance_rate` DECIMAL(5, 2) NOT NULL DEFAULT 0 AFTER `admin_fee_amount`;
@@ -0,0 +1,4 @@
+-- Accounts of the fees charged on loans
+INSERT INTO `ledger_accounts` (`account_code`, `account_name`, `account_type`) VALUES
+    ('2300', 'Insurance Premium Payable', 'liability'),
+    ('4400', 'Admin Fee Income', 'income');
@@ -0,0 +1,13 @@
+-- Table merchant_notifications
+CREATE TABLE IF NOT EXISTS `merchant_notifications`(
+    `merchant_notification_id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `merchant_id` BIGINT UNSIGNED NOT NULL,
+    `loan_id` BIGINT UNSIGNED NOT NULL,
+    `event_type` ENUM('loan_cancelled') NOT NULL,
+    `message` VARCHAR(512) NOT NULL,
+    `refund_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX (`merchant_id`, `created_at`),
+    FOREIGN KEY (`merchant_id`) REFERENCES `merchants`(`merchant_id`),
+    FOREIGN KEY (`loan_id`) REFERENCES `loans`(`loan_id`)
+);
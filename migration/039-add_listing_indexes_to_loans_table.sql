@@ -0,0 +1,11 @@
+-- Add loan listing indexes to table loans, outstanding_amount is what is left to repay of
+-- the principal and the interest
+ALTER TABLE `loans`
+    ADD COLUMN `outstanding_amount` DECIMAL(19, 3) GENERATED ALWAYS AS (`loan_amount` + `interest_amount` - `paid_loan_amount` - `paid_interest_amount`) STORED AFTER `paid_interest_amount`,
+    ADD INDEX (`loan_status`, `created_at`),
+    ADD INDEX (`loan_status`, `due_date`),
+    ADD INDEX (`merchant_id`, `created_at`),
+    ADD INDEX (`consumer_id`, `created_at`),
+    ADD INDEX (`created_at`),
+    ADD INDEX (`due_date`),
+    ADD INDEX (`outstanding_amount`);
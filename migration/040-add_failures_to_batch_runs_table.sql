@@ -0,0 +1,8 @@
+-- Add failures to tables batch_runs and batch_run_items, a loan the batch failed to update
+-- is logged as a failed item and the batch goes on with the next loan
+ALTER TABLE `batch_runs`
+    ADD COLUMN `failed_loans` INT NOT NULL DEFAULT 0 AFTER `updated_loans`;
+
+ALTER TABLE `batch_run_items`
+    ADD COLUMN `item_status` ENUM('updated', 'failed') NOT NULL DEFAULT 'updated' AFTER `loan_id`,
+    ADD COLUMN `error_message` TEXT NULL AFTER `days_past_due`;
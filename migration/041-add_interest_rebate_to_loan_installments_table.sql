@@ -0,0 +1,23 @@
+-- Add interest_rebate_amount to table loan_installments, the interest an early settlement
+-- rebated off the installment. Its interest_amount is lowered by the rebate, so the rebate
+-- is what is given back when the settlement is reversed
+ALTER TABLE `loan_installments`
+    ADD COLUMN `interest_rebate_amount` DECIMAL(19, 3) NOT NULL DEFAULT 0 AFTER `paid_interest_amount`;
+
+-- Loans settled early before kept the rebated interest as owed, their installments were
+-- closed with less interest paid than charged
+UPDATE `loan_installments` li
+JOIN `settlement_quotes` sq ON sq.`loan_id` = li.`loan_id` AND sq.`used_at` IS NOT NULL
+JOIN `transactions` t ON t.`transaction_id` = sq.`transaction_id` AND t.`reversed_at` IS NULL
+SET li.`interest_rebate_amount` = li.`interest_amount` - li.`paid_interest_amount`,
+    li.`interest_amount` = li.`paid_interest_amount`
+WHERE li.`deleted_at` IS NULL
+AND li.`installment_status` = 'paid'
+AND li.`paid_interest_amount` < li.`interest_amount`;
+
+UPDATE `loans` l
+JOIN `settlement_quotes` sq ON sq.`loan_id` = l.`loan_id` AND sq.`used_at` IS NOT NULL
+JOIN `transactions` t ON t.`transaction_id` = sq.`transaction_id` AND t.`reversed_at` IS NULL
+SET l.`interest_amount` = l.`interest_amount` - sq.`interest_rebate_amount`
+WHERE l.`deleted_at` IS NULL
+AND l.`loan_status` = 'finish';
@@ -0,0 +1,36 @@
+-- Backfill table loan_installments for loans booked before schedules were persisted. Those
+-- loans were all booked with the flat interest method, their amounts are split evenly over
+-- the tenure of the consumer limit in whole rupiah with the remainder on the last installment,
+-- one installment a month from the booking date. The first `installment` installments were
+-- paid in full.
+INSERT INTO `loan_installments` (
+    `loan_id`, `installment_number`, `due_date`, `principal_amount`, `interest_amount`,
+    `paid_principal_amount`, `paid_interest_amount`, `installment_status`
+)
+WITH RECURSIVE `numbers` (`installment_number`) AS (
+    SELECT 1
+    UNION ALL
+    SELECT `installment_number` + 1 FROM `numbers`
+    WHERE `installment_number` < (SELECT MAX(`tenure`) FROM `consumer_limits`)
+)
+SELECT s.`loan_id`, s.`installment_number`, s.`due_date`, s.`principal_amount`, s.`interest_amount`,
+    IF(s.`installment_number` <= s.`installment`, s.`principal_amount`, 0),
+    IF(s.`installment_number` <= s.`installment`, s.`interest_amount`, 0),
+    IF(s.`installment_number` <= s.`installment`, 'paid', 'unpaid')
+FROM (
+    SELECT l.`loan_id`, n.`installment_number`, l.`installment`,
+        DATE(DATE_ADD(l.`created_at`, INTERVAL n.`installment_number` MONTH)) AS `due_date`,
+        IF(n.`installment_number` = cl.`tenure`,
+            l.`loan_amount` - TRUNCATE(l.`loan_amount` / cl.`tenure`, 0) * (cl.`tenure` - 1),
+            TRUNCATE(l.`loan_amount` / cl.`tenure`, 0)) AS `principal_amount`,
+        IF(n.`installment_number` = cl.`tenure`,
+            l.`interest_amount` - TRUNCATE(l.`interest_amount` / cl.`tenure`, 0) * (cl.`tenure` - 1),
+            TRUNCATE(l.`interest_amount` / cl.`tenure`, 0)) AS `interest_amount`
+    FROM `loans` l
+    JOIN `consumer_limits` cl ON cl.`consumer_limit_id` = l.`consumer_limit_id`
+    JOIN `numbers` n ON n.`installment_number` <= cl.`tenure`
+    WHERE l.`deleted_at` IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM `loan_installments` li WHERE li.`loan_id` = l.`loan_id`
+    )
+) s;
@@ -0,0 +1,26 @@
+-- Add a unique key on job_name and run_date to table batch_runs, a run claims its date by
+-- inserting its row so a job runs once a day however many instances run it. The items of
+-- runs repeating a date are moved to the first run of the date before the repeated runs are
+-- removed.
+UPDATE `batch_run_items` bri
+JOIN `batch_runs` br ON br.`batch_run_id` = bri.`batch_run_id`
+JOIN (
+    SELECT `job_name`, `run_date`, MIN(`batch_run_id`) AS `first_batch_run_id`
+    FROM `batch_runs`
+    GROUP BY `job_name`, `run_date`
+) f ON f.`job_name` = br.`job_name` AND f.`run_date` = br.`run_date`
+SET bri.`batch_run_id` = f.`first_batch_run_id`
+WHERE br.`batch_run_id` <> f.`first_batch_run_id`;
+
+DELETE br FROM `batch_runs` br
+JOIN (
+    SELECT * FROM (
+        SELECT `job_name`, `run_date`, MIN(`batch_run_id`) AS `first_batch_run_id`
+        FROM `batch_runs`
+        GROUP BY `job_name`, `run_date`
+    ) runs
+) f ON f.`job_name` = br.`job_name` AND f.`run_date` = br.`run_date`
+WHERE br.`batch_run_id` <> f.`first_batch_run_id`;
+
+ALTER TABLE `batch_runs`
+    ADD UNIQUE (`job_name`, `run_date`);
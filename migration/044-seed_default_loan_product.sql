@@ -0,0 +1,28 @@
+-- Seed the DEFAULT loan product, offering the tenures of 1, 2, 3 and 6 months consumer limits
+-- had before loan products, so limits can be set and loans applied for on a fresh database.
+-- Loans carried their own rate then, each tenure gets the rate of the latest loan booked on
+-- it, 5 when there is none. Nothing is seeded when a DEFAULT product exists already.
+INSERT INTO `loan_products` (`product_code`, `product_name`, `interest_method`, `valid_from`)
+SELECT 'DEFAULT', 'Default', 'flat', CURDATE() FROM DUAL
+WHERE NOT EXISTS (
+    SELECT 1 FROM `loan_products` WHERE `product_code` = 'DEFAULT'
+);
+
+INSERT INTO `loan_product_tenures` (`loan_product_id`, `tenure`, `interest_rate`)
+SELECT lp.`loan_product_id`, t.`tenure`, COALESCE((
+    SELECT l.`interest_rate`
+    FROM `loans` l
+    JOIN `consumer_limits` cl ON cl.`consumer_limit_id` = l.`consumer_limit_id`
+    WHERE cl.`tenure` = t.`tenure`
+    AND l.`loan_product_id` IS NULL
+    ORDER BY l.`created_at` DESC, l.`loan_id` DESC
+    LIMIT 1
+), 5)
+FROM `loan_products` lp
+CROSS JOIN (
+    SELECT 1 AS `tenure` UNION ALL SELECT 2 UNION ALL SELECT 3 UNION ALL SELECT 6
+) t
+WHERE lp.`product_code` = 'DEFAULT'
+AND NOT EXISTS (
+    SELECT 1 FROM `loan_product_tenures` lpt WHERE lpt.`loan_product_id` = lp.`loan_product_id`
+);
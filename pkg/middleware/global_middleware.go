@@ -8,6 +8,11 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderIdempotentReplayed is set on a response replayed for a retried request.
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
 func LoggerMiddleware() echo.MiddlewareFunc {
 	return middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Format: `[${time_rfc3339}] method=${method}, uri=${uri}, status=${status}, latency=${latency_human}` + "\n",
@@ -25,6 +30,6 @@ func CORSMiddleware() echo.MiddlewareFunc {
 	return middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: allowedOrigins,
 		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE},
-		AllowHeaders: []string{echo.HeaderAuthorization, echo.HeaderContentType},
+		AllowHeaders: []string{echo.HeaderAuthorization, echo.HeaderContentType, HeaderIdempotencyKey},
 	})
 }
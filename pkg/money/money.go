@@ -0,0 +1,357 @@
+// Package money implements an exact decimal amount of rupiah.
+//
+// Amounts are kept as an integer number of thousandths, the same scale as the
+// DECIMAL(19,3) amount columns, so values read from and written to the database
+// round-trip without loss and arithmetic never drifts the way float64 does.
+//
+// IDR rounding rule: every amount derived from another one (interest, penalties,
+// fees, prorated amounts) is rounded once to whole rupiah with halves rounded away
+// from zero, see Percent, Div and Round. When a total is split into installments
+// the parts are whole rupiah and the last one absorbs the remainder, see Allocate,
+// so the installments always add up to the total exactly.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	scale  = 1000
+	places = 3
+)
+
+var (
+	ErrInvalidAmount   = errors.New("money: invalid amount")
+	ErrTooManyDecimals = errors.New("money: amount has more than 3 decimal places")
+	ErrOutOfRange      = errors.New("money: amount is out of range")
+)
+
+// Money is an exact amount of rupiah. The zero value is Rp 0.
+type Money struct {
+	milli int64
+}
+
+// Zero is Rp 0.
+var Zero = Money{}
+
+// New returns a whole rupiah amount.
+func New(rupiah int64) Money {
+	return Money{milli: rupiah * scale}
+}
+
+// Parse reads a decimal amount such as "1500000", "333333.333" or "1.5e6". It fails
+// when the amount has more precision than the DECIMAL(19,3) columns can store.
+func Parse(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.Contains(value, "/") {
+		return Zero, ErrInvalidAmount
+	}
+
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return Zero, ErrInvalidAmount
+	}
+
+	r.Mul(r, big.NewRat(scale, 1))
+	if !r.IsInt() {
+		return Zero, ErrTooManyDecimals
+	}
+
+	if !r.Num().IsInt64() {
+		return Zero, ErrOutOfRange
+	}
+
+	return Money{milli: r.Num().Int64()}, nil
+}
+
+// MustParse is like Parse but panics on error. It is meant for constants and tests.
+func MustParse(value string) Money {
+	m, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+// FromFloat converts a float64, rounding it to the nearest thousandth.
+func FromFloat(value float64) Money {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'f', -1, 64))
+	if !ok {
+		return Zero
+	}
+
+	return Money{milli: roundRat(r.Mul(r, big.NewRat(scale, 1)))}
+}
+
+// Add returns m + other.
+func (m Money) Add(other Money) Money {
+	return Money{milli: m.milli + other.milli}
+}
+
+// Sub returns m - other.
+func (m Money) Sub(other Money) Money {
+	return Money{milli: m.milli - other.milli}
+}
+
+// Mul returns m multiplied by a whole number, e.g. a daily amount by a number of days.
+func (m Money) Mul(n int64) Money {
+	return Money{milli: m.milli * n}
+}
+
+// Div returns m divided by n in whole rupiah. The exact quotient is rounded once, so
+// multiply before dividing to prorate an amount, e.g. m.Mul(days).Div(periodDays).
+func (m Money) Div(n int64) Money {
+	if n == 0 {
+		return Zero
+	}
+
+	return Money{milli: divRound(m.milli, n*scale) * scale}
+}
+
+// Percent returns rate percent of m in whole rupiah. The rate is taken at its shortest
+// decimal representation, so 0.1 means exactly one tenth, and the exact product is
+// rounded once.
+func (m Money) Percent(rate float64) Money {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return Zero
+	}
+
+	r.Mul(r, new(big.Rat).SetInt64(m.milli))
+	r.Quo(r, big.NewRat(100*scale, 1))
+
+	return Money{milli: roundRat(r) * scale}
+}
+
+// Round rounds m to whole rupiah, halves away from zero.
+func (m Money) Round() Money {
+	return Money{milli: divRound(m.milli, scale) * scale}
+}
+
+// Allocate splits m into n parts. Every part but the last is m/n truncated to whole
+// rupiah and the last one absorbs the remainder, so the parts add up to m exactly and
+// the last part is never smaller than the others.
+func (m Money) Allocate(n int) []Money {
+	if n <= 0 {
+		return nil
+	}
+
+	parts := make([]Money, n)
+	part := Money{milli: m.milli / (int64(n) * scale) * scale}
+	for i := range parts {
+		parts[i] = part
+	}
+	parts[n-1] = m.Sub(part.Mul(int64(n - 1)))
+
+	return parts
+}
+
+// Cmp returns -1, 0 or +1 depending on whether m is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) int {
+	switch {
+	case m.milli < other.milli:
+		return -1
+	case m.milli > other.milli:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (m Money) LessThan(other Money) bool {
+	return m.milli < other.milli
+}
+
+func (m Money) GreaterThan(other Money) bool {
+	return m.milli > other.milli
+}
+
+func (m Money) IsZero() bool {
+	return m.milli == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.milli > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.milli < 0
+}
+
+// Float64 returns the closest float64. Use it for display or ratios only, never to
+// compute an amount that is stored.
+func (m Money) Float64() float64 {
+	return float64(m.milli) / scale
+}
+
+// String returns the shortest exact decimal representation, e.g. "1500000" or "0.5".
+func (m Money) String() string {
+	s := m.fixed()
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// fixed returns m with exactly three decimal places, the format of a DECIMAL(19,3) column.
+func (m Money) fixed() string {
+	sign := ""
+	milli := m.milli
+	if milli < 0 {
+		sign = "-"
+	}
+
+	whole := milli / scale
+	fraction := milli % scale
+	if whole < 0 {
+		whole = -whole
+	}
+	if fraction < 0 {
+		fraction = -fraction
+	}
+
+	return fmt.Sprintf("%s%d.%0*d", sign, whole, places, fraction)
+}
+
+// Min returns the smaller of a and b.
+func Min(a, b Money) Money {
+	if a.milli < b.milli {
+		return a
+	}
+
+	return b
+}
+
+// Max returns the larger of a and b.
+func Max(a, b Money) Money {
+	if a.milli > b.milli {
+		return a
+	}
+
+	return b
+}
+
+// Sum adds up amounts.
+func Sum(amounts ...Money) Money {
+	var total Money
+	for _, amount := range amounts {
+		total = total.Add(amount)
+	}
+
+	return total
+}
+
+// Scan implements sql.Scanner. NULL scans as Rp 0, use NullMoney to tell them apart.
+func (m *Money) Scan(value interface{}) (err error) {
+	switch v := value.(type) {
+	case nil:
+		*m = Zero
+	case []byte:
+		*m, err = Parse(string(v))
+	case string:
+		*m, err = Parse(v)
+	case int64:
+		*m = New(v)
+	case float64:
+		*m = FromFloat(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T", value)
+	}
+
+	return err
+}
+
+// Value implements driver.Valuer, sending the amount as an exact decimal string.
+func (m Money) Value() (driver.Value, error) {
+	return m.fixed(), nil
+}
+
+// MarshalJSON writes the amount as a JSON number without losing precision.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both a JSON number and a quoted decimal string.
+func (m *Money) UnmarshalJSON(data []byte) (err error) {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+
+	*m, err = Parse(strings.Trim(s, `"`))
+	return err
+}
+
+// UnmarshalParam reads an amount from a query or form parameter, it lets echo bind Money fields.
+func (m *Money) UnmarshalParam(param string) (err error) {
+	*m, err = Parse(param)
+	return err
+}
+
+// NullMoney is a Money that may be NULL, mirroring sql.NullFloat64.
+type NullMoney struct {
+	Money Money
+	Valid bool
+}
+
+func (n *NullMoney) Scan(value interface{}) error {
+	if value == nil {
+		n.Money, n.Valid = Zero, false
+		return nil
+	}
+
+	n.Valid = true
+	return n.Money.Scan(value)
+}
+
+func (n NullMoney) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	return n.Money.Value()
+}
+
+// divRound divides a by b rounding halves away from zero.
+func divRound(a, b int64) int64 {
+	q, r := a/b, a%b
+	if r < 0 {
+		r = -r
+	}
+
+	absB := b
+	if absB < 0 {
+		absB = -absB
+	}
+
+	if 2*r >= absB {
+		if (a < 0) != (b < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+
+	return q
+}
+
+// roundRat rounds r to an integer, halves away from zero.
+func roundRat(r *big.Rat) int64 {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return q.Int64()
+}
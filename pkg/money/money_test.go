@@ -0,0 +1,162 @@
+package money_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    money.Money
+		wantErr error
+	}{
+		{name: "whole rupiah", value: "1500000", want: money.New(1500000)},
+		{name: "decimal column", value: "333333.330", want: money.MustParse("333333.33")},
+		{name: "exponent", value: "1.5e6", want: money.New(1500000)},
+		{name: "negative", value: "-0.5", want: money.Zero.Sub(money.MustParse("0.5"))},
+		{name: "too many decimals", value: "0.0001", wantErr: money.ErrTooManyDecimals},
+		{name: "not a number", value: "abc", wantErr: money.ErrInvalidAmount},
+		{name: "fraction", value: "1/3", wantErr: money.ErrInvalidAmount},
+		{name: "empty", value: "", wantErr: money.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := money.Parse(tt.value)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	assert.Equal(t, "1500000", money.New(1500000).String())
+	assert.Equal(t, "0.5", money.MustParse("0.500").String())
+	assert.Equal(t, "-12.05", money.MustParse("-12.05").String())
+	assert.Equal(t, "0", money.Zero.String())
+
+	value, err := money.MustParse("-12.05").Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "-12.050", value)
+}
+
+func TestMoneyRounding(t *testing.T) {
+	assert.Equal(t, money.New(3), money.MustParse("2.5").Round())
+	assert.Equal(t, money.New(2), money.MustParse("2.499").Round())
+	assert.Equal(t, money.New(-3), money.MustParse("-2.5").Round())
+
+	// the exact product is rounded once: 1,000.005 rounds down, 1,000.5 rounds up
+	assert.Equal(t, money.New(1000), money.New(1000005).Percent(0.1))
+	assert.Equal(t, money.New(1001), money.New(1000500).Percent(0.1))
+	assert.Equal(t, money.New(100000), money.New(1000000).Percent(10))
+
+	assert.Equal(t, money.New(333333), money.New(1000000).Div(3))
+	assert.Equal(t, money.New(3), money.New(5).Div(2))
+	assert.Equal(t, money.Zero, money.New(5).Div(0))
+}
+
+func TestMoneyAllocate(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount money.Money
+		n      int
+		want   []money.Money
+	}{
+		{
+			name:   "last part absorbs the remainder",
+			amount: money.New(1000000),
+			n:      3,
+			want:   []money.Money{money.New(333333), money.New(333333), money.New(333334)},
+		},
+		{
+			name:   "parts are truncated so the last one is never negative",
+			amount: money.New(2),
+			n:      4,
+			want:   []money.Money{money.Zero, money.Zero, money.Zero, money.New(2)},
+		},
+		{
+			name:   "fraction of a rupiah goes to the last part",
+			amount: money.MustParse("100.5"),
+			n:      2,
+			want:   []money.Money{money.New(50), money.MustParse("50.5")},
+		},
+		{
+			name:   "no parts",
+			amount: money.New(100),
+			n:      0,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.amount.Allocate(tt.n)
+			assert.Equal(t, tt.want, got)
+			if tt.n > 0 {
+				assert.Equal(t, tt.amount, money.Sum(got...))
+			}
+		})
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  money.Money
+	}{
+		{name: "mysql decimal", value: []byte("333333.333"), want: money.MustParse("333333.333")},
+		{name: "string", value: "10.5", want: money.MustParse("10.5")},
+		{name: "int64", value: int64(25), want: money.New(25)},
+		{name: "float64", value: 0.1 + 0.2, want: money.MustParse("0.3")},
+		{name: "null", value: nil, want: money.Zero},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got money.Money
+			assert.NoError(t, got.Scan(tt.value))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	var m money.Money
+	assert.Error(t, m.Scan(true))
+
+	var n money.NullMoney
+	assert.NoError(t, n.Scan(nil))
+	assert.False(t, n.Valid)
+	assert.NoError(t, n.Scan([]byte("1.000")))
+	assert.Equal(t, money.NullMoney{Money: money.New(1), Valid: true}, n)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	type payload struct {
+		Amount money.Money `json:"amount"`
+	}
+
+	out, err := json.Marshal(payload{Amount: money.MustParse("333333.33")})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":333333.33}`, string(out))
+
+	var in payload
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":1000000}`), &in))
+	assert.Equal(t, money.New(1000000), in.Amount)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":"12.5"}`), &in))
+	assert.Equal(t, money.MustParse("12.5"), in.Amount)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":0.0001}`), &in))
+}
+
+func TestMoneyUnmarshalParam(t *testing.T) {
+	var m money.Money
+	assert.NoError(t, m.UnmarshalParam("12.5"))
+	assert.Equal(t, money.MustParse("12.5"), m)
+	assert.Error(t, m.UnmarshalParam("abc"))
+}
@@ -0,0 +1,123 @@
+// Package pdf writes plain text documents as PDF.
+//
+// A document is a list of lines typeset in Courier on A4 pages. The font is monospaced, so
+// text laid out in columns with padding stays aligned. Characters outside of ASCII are
+// written as "?" since the standard fonts are used without embedding.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 40
+	fontSize   = 9
+	leading    = 11
+
+	// LineWidth is the number of characters fitting on a line.
+	LineWidth = (pageWidth - 2*margin) * 10 / (fontSize * 6)
+
+	linesPerPage = (pageHeight - 2*margin) / leading
+)
+
+// Document is a text document. The zero value is an empty document ready to use.
+type Document struct {
+	pages [][]string
+}
+
+// Line appends a line of text, starting a new page when the current one is full. Text
+// longer than LineWidth is cut.
+func (d *Document) Line(text string) {
+	if len(d.pages) == 0 || len(d.pages[len(d.pages)-1]) == linesPerPage {
+		d.pages = append(d.pages, []string{})
+	}
+
+	if len(text) > LineWidth {
+		text = text[:LineWidth]
+	}
+
+	last := len(d.pages) - 1
+	d.pages[last] = append(d.pages[last], text)
+}
+
+// WriteTo writes the document as a PDF file. Every page is numbered at its bottom.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = [][]string{{}}
+	}
+
+	// objects 1 to 3 are the catalog, the page tree and the font, then every page is
+	// followed by its content stream
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	}
+
+	kids := make([]string, 0, len(pages))
+	for i, lines := range pages {
+		pageID := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+
+		content := pageContent(lines, fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, pageID+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, 0, len(objects))
+	for i, object := range objects {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+func pageContent(lines []string, footer string) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td", fontSize, leading, margin, pageHeight-margin-fontSize)
+	for _, line := range lines {
+		fmt.Fprintf(&content, " (%s) Tj T*", escape(line))
+	}
+	content.WriteString(" ET")
+
+	fmt.Fprintf(&content, "\nBT /F1 %d Tf %d %d Td (%s) Tj ET", fontSize, pageWidth-margin-len(footer)*fontSize*6/10, margin/2, escape(footer))
+
+	return content.String()
+}
+
+func escape(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		case r < ' ' || r > '~':
+			escaped.WriteRune('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+
+	return escaped.String()
+}
@@ -0,0 +1,57 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/AbdulRasyid-Ans/xyz-multifinance/pkg/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentWriteTo(t *testing.T) {
+	var doc pdf.Document
+	doc.Line("Statement (draft) of C:\\loans")
+	doc.Line("Nama: Budi Söderberg")
+	doc.Line(strings.Repeat("x", pdf.LineWidth+10))
+	for i := 0; i < 100; i++ {
+		doc.Line(fmt.Sprintf("line %d", i))
+	}
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	assert.NoError(t, err)
+
+	output := buf.String()
+	assert.True(t, strings.HasPrefix(output, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(output, "%%EOF\n"))
+	assert.Contains(t, output, `(Statement \(draft\) of C:\\loans) Tj`)
+	assert.Contains(t, output, "(Nama: Budi S?derberg) Tj")
+	assert.Contains(t, output, "("+strings.Repeat("x", pdf.LineWidth)+") Tj")
+	assert.Contains(t, output, "/Count 2")
+	assert.Contains(t, output, "(Page 2 of 2) Tj")
+
+	// every object starts at the offset listed in the cross-reference table
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(output)
+	assert.Len(t, xref, 2)
+	start, _ := strconv.Atoi(xref[1])
+	assert.True(t, strings.HasPrefix(output[start:], "xref\n"))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(output[start:], -1)
+	assert.Len(t, entries, 7)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		assert.True(t, strings.HasPrefix(output[offset:], fmt.Sprintf("%d 0 obj\n", i+1)))
+	}
+}
+
+func TestEmptyDocument(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := new(pdf.Document).WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "/Count 1")
+	assert.Contains(t, buf.String(), "(Page 1 of 1) Tj")
+}